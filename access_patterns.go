@@ -0,0 +1,213 @@
+package dynamorm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/accesspattern"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// AccessPattern declares one expected way of reading a model. See package accesspattern.
+type AccessPattern = accesspattern.Pattern
+
+// AccessPatterns returns the registry that holds declared access patterns for this DB.
+// Use SetMode on the returned registry to enable checking (typically ModeEnforce in
+// tests and CI, ModeReport in development).
+func (db *DB) AccessPatterns() *accesspattern.Registry {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.accessPatterns == nil {
+		db.accessPatterns = accesspattern.NewRegistry()
+	}
+	return db.accessPatterns
+}
+
+// RegisterAccessPatterns declares the access patterns a model is expected to use.
+// Key names may be given as Go field names or DynamoDB attribute names; they are
+// validated against the model's indexes and stored as attribute names.
+func (db *DB) RegisterAccessPatterns(modelValue any, patterns ...AccessPattern) error {
+	meta, err := db.metadataFor(modelValue)
+	if err != nil {
+		return err
+	}
+
+	resolved := make([]AccessPattern, 0, len(patterns))
+	for _, p := range patterns {
+		r, err := resolveAccessPattern(meta, p)
+		if err != nil {
+			return err
+		}
+		resolved = append(resolved, r)
+	}
+
+	return db.AccessPatterns().Register(meta.Type, resolved...)
+}
+
+func resolveAccessPattern(meta *model.Metadata, p AccessPattern) (AccessPattern, error) {
+	pkName, skName := meta.PrimaryKey.PartitionKey.DBName, ""
+	if meta.PrimaryKey.SortKey != nil {
+		skName = meta.PrimaryKey.SortKey.DBName
+	}
+
+	if p.Index != "" {
+		idx := findIndexSchema(meta, p.Index)
+		if idx == nil {
+			return p, fmt.Errorf("access pattern %s: index %s is not defined on %s", p.Name, p.Index, meta.Type.Name())
+		}
+		if idx.PartitionKey != nil {
+			pkName = idx.PartitionKey.DBName
+		}
+		skName = ""
+		if idx.SortKey != nil {
+			skName = idx.SortKey.DBName
+		}
+	}
+
+	if p.PartitionKey != "" {
+		name := resolveAttributeName(meta, p.PartitionKey)
+		if name != pkName {
+			return p, fmt.Errorf("access pattern %s: %s is not the partition key of %s", p.Name, p.PartitionKey, accessPatternTarget(meta, p.Index))
+		}
+		p.PartitionKey = name
+	}
+	if p.SortKey != "" {
+		name := resolveAttributeName(meta, p.SortKey)
+		if name != skName {
+			return p, fmt.Errorf("access pattern %s: %s is not the sort key of %s", p.Name, p.SortKey, accessPatternTarget(meta, p.Index))
+		}
+		p.SortKey = name
+	}
+
+	return p, nil
+}
+
+func accessPatternTarget(meta *model.Metadata, index string) string {
+	if index == "" {
+		return meta.TableName
+	}
+	return meta.TableName + "/" + index
+}
+
+func findIndexSchema(meta *model.Metadata, name string) *model.IndexSchema {
+	for i := range meta.Indexes {
+		if meta.Indexes[i].Name == name {
+			return &meta.Indexes[i]
+		}
+	}
+	return nil
+}
+
+func resolveAttributeName(meta *model.Metadata, name string) string {
+	if field, ok := meta.Fields[name]; ok {
+		return field.DBName
+	}
+	return name
+}
+
+// checkAccessPattern validates a compiled Query/Scan against the model's declared patterns.
+func (qe *queryExecutor) checkAccessPattern(input *core.CompiledQuery) error {
+	registry := qe.accessPatternRegistry()
+	if registry == nil || input == nil {
+		return nil
+	}
+
+	shape := accesspattern.Shape{
+		Operation: input.Operation,
+		Table:     input.TableName,
+		Index:     input.IndexName,
+	}
+	if input.Operation != accesspattern.OperationScan {
+		shape.PartitionKey, shape.SortKey = qe.keyShapeFromCondition(input)
+	}
+	return registry.Check(qe.metadata.Type, shape)
+}
+
+// checkGetItemAccessPattern validates a GetItem key against the model's declared patterns.
+func (qe *queryExecutor) checkGetItemAccessPattern(tableName string, key map[string]types.AttributeValue) error {
+	registry := qe.accessPatternRegistry()
+	if registry == nil {
+		return nil
+	}
+
+	shape := accesspattern.Shape{Operation: accesspattern.OperationGetItem, Table: tableName}
+	if pk := qe.metadata.PrimaryKey.PartitionKey; pk != nil {
+		if _, ok := key[pk.DBName]; ok {
+			shape.PartitionKey = pk.DBName
+		}
+	}
+	if sk := qe.metadata.PrimaryKey.SortKey; sk != nil {
+		if _, ok := key[sk.DBName]; ok {
+			shape.SortKey = sk.DBName
+		}
+	}
+	return registry.Check(qe.metadata.Type, shape)
+}
+
+func (qe *queryExecutor) accessPatternRegistry() *accesspattern.Registry {
+	if qe == nil || qe.db == nil || qe.metadata == nil {
+		return nil
+	}
+	qe.db.mu.RLock()
+	defer qe.db.mu.RUnlock()
+	return qe.db.accessPatterns
+}
+
+// keyShapeFromCondition extracts the partition and sort key attribute names referenced by
+// the compiled key condition expression.
+func (qe *queryExecutor) keyShapeFromCondition(input *core.CompiledQuery) (string, string) {
+	pkName := ""
+	if qe.metadata.PrimaryKey.PartitionKey != nil {
+		pkName = qe.metadata.PrimaryKey.PartitionKey.DBName
+	}
+	if input.IndexName != "" {
+		if idx := findIndexSchema(qe.metadata, input.IndexName); idx != nil && idx.PartitionKey != nil {
+			pkName = idx.PartitionKey.DBName
+		}
+	}
+
+	var partitionKey, sortKey string
+	for _, name := range conditionAttributeNames(input.KeyConditionExpression, input.ExpressionAttributeNames) {
+		if name == pkName {
+			partitionKey = name
+		} else if sortKey == "" {
+			sortKey = name
+		}
+	}
+	return partitionKey, sortKey
+}
+
+// conditionAttributeNames returns the attribute names referenced by #placeholders in expr,
+// in order of first appearance.
+func conditionAttributeNames(expr string, names map[string]string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for i := 0; i < len(expr); i++ {
+		if expr[i] != '#' {
+			continue
+		}
+		j := i + 1
+		for j < len(expr) && isPlaceholderChar(expr[j]) {
+			j++
+		}
+		placeholder := expr[i:j]
+		i = j - 1
+
+		name, ok := names[placeholder]
+		if !ok {
+			name = strings.TrimPrefix(placeholder, "#")
+		}
+		if name != "" && !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func isPlaceholderChar(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
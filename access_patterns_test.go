@@ -0,0 +1,129 @@
+package dynamorm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/accesspattern"
+)
+
+type accessPatternOrder struct {
+	TenantID   string `dynamorm:"pk,attr:tenantId"`
+	OrderID    string `dynamorm:"sk,attr:orderId"`
+	CustomerID string `dynamorm:"index:gsi-customer,pk,attr:customerId"`
+	PlacedAt   string `dynamorm:"index:gsi-customer,sk,attr:placedAt"`
+	Status     string `dynamorm:"attr:status"`
+}
+
+func (accessPatternOrder) TableName() string {
+	return "access_pattern_orders"
+}
+
+func newAccessPatternTestDB(t *testing.T, httpClient *capturingHTTPClient) *DB {
+	t.Helper()
+	db := newStubbedDB(t, httpClient)
+
+	require.NoError(t, db.RegisterAccessPatterns(&accessPatternOrder{},
+		AccessPattern{Name: "OrdersForTenant", PartitionKey: "TenantID", SortKey: "OrderID"},
+		AccessPattern{Name: "OrdersByCustomer", Index: "gsi-customer", PartitionKey: "CustomerID", SortKey: "PlacedAt"},
+	))
+	return db
+}
+
+func TestRegisterAccessPatterns_ValidatesKeysAgainstIndexes(t *testing.T) {
+	db := newBareDB()
+
+	err := db.RegisterAccessPatterns(&accessPatternOrder{}, AccessPattern{Name: "Missing", Index: "gsi-nope", PartitionKey: "customerId"})
+	require.ErrorContains(t, err, "index gsi-nope is not defined")
+
+	err = db.RegisterAccessPatterns(&accessPatternOrder{}, AccessPattern{Name: "WrongPK", Index: "gsi-customer", PartitionKey: "TenantID"})
+	require.ErrorContains(t, err, "is not the partition key")
+
+	err = db.RegisterAccessPatterns(&accessPatternOrder{}, AccessPattern{Name: "WrongSK", PartitionKey: "tenantId", SortKey: "status"})
+	require.ErrorContains(t, err, "is not the sort key")
+
+	require.NoError(t, db.RegisterAccessPatterns(&accessPatternOrder{}, AccessPattern{Name: "ByTenant", PartitionKey: "TenantID"}))
+	patterns := db.AccessPatterns().Patterns(reflect.TypeOf(accessPatternOrder{}))
+	require.Len(t, patterns, 1)
+	require.Equal(t, "tenantId", patterns[0].PartitionKey)
+}
+
+func TestAccessPatterns_EnforceRejectsUndeclaredShapes(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[],"Count":0,"ScannedCount":0}`,
+		"DynamoDB_20120810.Scan":  `{"Items":[],"Count":0,"ScannedCount":0}`,
+	})
+	db := newAccessPatternTestDB(t, httpClient)
+
+	var reported []accesspattern.Violation
+	db.AccessPatterns().SetMode(accesspattern.ModeEnforce, func(v accesspattern.Violation) {
+		reported = append(reported, v)
+	})
+
+	var out []accessPatternOrder
+	require.NoError(t, db.Model(&accessPatternOrder{}).
+		Index("gsi-customer").
+		Where("CustomerID", "=", "c1").
+		Where("PlacedAt", ">", "2024").
+		All(&out))
+
+	err := db.Model(&accessPatternOrder{}).Where("Status", "=", "open").Scan(&out)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, accesspattern.ErrViolation))
+	require.Equal(t, 0, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan"))
+
+	require.Len(t, reported, 1)
+	require.Equal(t, accesspattern.OperationScan, reported[0].Shape.Operation)
+	require.Equal(t, "accessPatternOrder", reported[0].Model)
+	require.Len(t, db.AccessPatterns().Violations(), 1)
+}
+
+func TestAccessPatterns_ReportModeAllowsRequests(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Scan": `{"Items":[],"Count":0,"ScannedCount":0}`,
+	})
+	db := newAccessPatternTestDB(t, httpClient)
+	db.AccessPatterns().SetMode(accesspattern.ModeReport, nil)
+
+	var out []accessPatternOrder
+	require.NoError(t, db.Model(&accessPatternOrder{}).Scan(&out))
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan"))
+
+	violations := db.AccessPatterns().Violations()
+	require.Len(t, violations, 1)
+	require.Equal(t, "access_pattern_orders", violations[0].Shape.Table)
+
+	db.AccessPatterns().Reset()
+	require.Empty(t, db.AccessPatterns().Violations())
+}
+
+func TestAccessPatterns_GetItemAndDerivedDBsShareRegistry(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"tenantId":{"S":"t1"},"orderId":{"S":"o1"}}}`,
+	})
+	db := newAccessPatternTestDB(t, httpClient)
+	db.AccessPatterns().SetMode(accesspattern.ModeEnforce, nil)
+
+	derived := mustDB(t, db.WithContext(context.Background()))
+	require.Same(t, db.AccessPatterns(), derived.AccessPatterns())
+
+	var out accessPatternOrder
+	require.NoError(t, derived.Model(&accessPatternOrder{}).
+		Where("TenantID", "=", "t1").
+		Where("OrderID", "=", "o1").
+		First(&out))
+	require.Equal(t, "o1", out.OrderID)
+	require.Empty(t, db.AccessPatterns().Violations())
+}
+
+func TestConditionAttributeNames_ResolvesPlaceholders(t *testing.T) {
+	names := conditionAttributeNames("#n1 = :v1 AND begins_with(#n2, :v2) AND #n1 <> :v3", map[string]string{
+		"#n1": "pk",
+		"#n2": "sk",
+	})
+	require.Equal(t, []string{"pk", "sk"}, names)
+}
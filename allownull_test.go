@@ -0,0 +1,47 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type nullableProfile struct {
+	Nickname *string `dynamorm:"attr:nickname,omitempty,allownull"`
+	Bio      *string `dynamorm:"attr:bio,omitempty"`
+	ID       string  `dynamorm:"pk,attr:id"`
+}
+
+func (nullableProfile) TableName() string { return "nullable_profiles" }
+
+func TestAllowNull_WritesNullAndRemovesOnUpdate(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+
+	require.NoError(t, db.Model(&nullableProfile{ID: "p1"}).Create())
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	item := put.Payload["Item"].(map[string]any)
+	require.Equal(t, map[string]any{"NULL": true}, item["nickname"])
+	require.NotContains(t, item, "bio")
+
+	require.NoError(t, db.Model(&nullableProfile{ID: "p1"}).Update("Nickname"))
+	update := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem")
+	require.NotNil(t, update)
+	require.Equal(t, "REMOVE #n1", update.Payload["UpdateExpression"])
+	require.Equal(t, map[string]any{"#n1": "nickname"}, update.Payload["ExpressionAttributeNames"])
+	require.NotContains(t, update.Payload, "ExpressionAttributeValues")
+}
+
+func TestAllowNull_ReadsNullAsNilAndLeavesMissingAttributes(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"p1"},"nickname":{"NULL":true}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	nickname, bio := "old", "kept"
+	profile := nullableProfile{Nickname: &nickname, Bio: &bio}
+	require.NoError(t, db.Model(&nullableProfile{}).Where("ID", "=", "p1").First(&profile))
+	require.Nil(t, profile.Nickname)
+	require.Equal(t, &bio, profile.Bio)
+}
@@ -0,0 +1,41 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtomicIncrement_CompilesToAddAndReturnsNewValue(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.UpdateItem": `{"Attributes":{"balance":{"N":"15"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	value, err := db.Model(&testAccount{}).Where("ID", "=", "a1").AtomicIncrement("Balance", 5)
+	require.NoError(t, err)
+	require.Equal(t, int64(15), value)
+
+	update := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem")
+	require.NotNil(t, update)
+	require.Equal(t, "ADD #n1 :v1", update.Payload["UpdateExpression"])
+	require.Equal(t, "UPDATED_NEW", update.Payload["ReturnValues"])
+	require.Equal(t, map[string]any{"#n1": "balance"}, update.Payload["ExpressionAttributeNames"])
+	require.Equal(t, map[string]any{":v1": map[string]any{"N": "5"}}, update.Payload["ExpressionAttributeValues"])
+	require.Equal(t, map[string]any{"id": map[string]any{"S": "a1"}}, update.Payload["Key"])
+}
+
+func TestAtomicDecrement_TypedQuery(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.UpdateItem": `{"Attributes":{"balance":{"N":"-2"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	value, err := ModelOf[testAccount](db).Where("ID", "=", "a1").AtomicDecrement("Balance", 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(-2), value)
+
+	update := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem")
+	require.NotNil(t, update)
+	require.Equal(t, map[string]any{":v1": map[string]any{"N": "-2"}}, update.Payload["ExpressionAttributeValues"])
+}
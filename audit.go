@@ -0,0 +1,613 @@
+package dynamorm
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/audit"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// auditing holds the options configured by WithAudit.
+type auditing struct {
+	opts audit.Options
+}
+
+// WithAudit returns a DB whose updates and deletes record the changes they make:
+//
+//	audited := db.WithAudit(audit.Options{Table: "payment-audit"})
+//
+// Each audited write reads the item with a consistent read, then commits the write in a
+// TransactWriteItems call with the Put of an audit.Record into opts.Table, conditioned on
+// the attributes it changes still holding the values read. The record holds those values
+// before and after the write, the actor set with audit.WithActor, and the time. Writes
+// that change nothing are not recorded.
+//
+// Audited updates may assign values, copy attributes, add and subtract numbers, append to
+// lists, add to and delete from sets, and remove attributes, but not change part of a map
+// or list. Batch deletes, transactions that update or delete items, and PartiQL updates
+// and deletes fail with errors.ErrInvalidOperator since they cannot carry records.
+// Passing Options with neither Table nor Publish turns auditing off.
+func (db *DB) WithAudit(opts audit.Options) core.ExtendedDB {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	newDB := db.derive()
+	newDB.audit = nil
+	if opts.Enabled() {
+		newDB.audit = &auditing{opts: opts}
+	}
+	return newDB
+}
+
+// auditConfig returns the DB's audit options, or nil when it does not audit writes.
+func (db *DB) auditConfig() *auditing {
+	if db == nil {
+		return nil
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.audit
+}
+
+// checkTransactItem rejects transaction items that update or delete items, which cannot
+// be audited.
+func (a *auditing) checkTransactItem(item *types.TransactWriteItem) error {
+	var table *string
+	switch {
+	case item.Update != nil:
+		table = item.Update.TableName
+	case item.Delete != nil:
+		table = item.Delete.TableName
+	default:
+		return nil
+	}
+	if aws.ToString(table) == a.opts.Table {
+		return nil
+	}
+	return fmt.Errorf("%w: transactions cannot write audit records; update or delete items of %s outside the transaction",
+		customerrors.ErrInvalidOperator, aws.ToString(table))
+}
+
+// checkPartiQLAudit rejects PartiQL updates and deletes while writes are audited, as the
+// items they change cannot be read first. Statements on the audit table are let through.
+func (db *DB) checkPartiQLAudit(statement string) error {
+	a := db.auditConfig()
+	if a == nil {
+		return nil
+	}
+	verb, table := partiQLTarget(statement)
+	if (verb != "UPDATE" && verb != "DELETE") || (table != "" && table == a.opts.Table) {
+		return nil
+	}
+	return fmt.Errorf("%w: PartiQL updates and deletes cannot be audited; write the items with Model",
+		customerrors.ErrInvalidOperator)
+}
+
+// auditsWrites reports whether updates and deletes of items in table are audited. Writes
+// to the audit table itself are not.
+func (qe *queryExecutor) auditsWrites(table string) bool {
+	a := qe.db.auditConfig()
+	return a != nil && table != a.opts.Table
+}
+
+// auditNow returns the time audit records are stamped with.
+func (qe *queryExecutor) auditNow() time.Time {
+	if sess := qe.session(); sess != nil && sess.Config() != nil && sess.Config().Now != nil {
+		return sess.Config().Now()
+	}
+	return time.Now()
+}
+
+// auditWrite returns the record of write, the update or delete of the item with key in
+// table that read as before, or nil when write changes nothing. It conditions write on
+// the attributes it touches still holding their values in before.
+func (qe *queryExecutor) auditWrite(operation, table string, key, before map[string]types.AttributeValue, write *types.TransactWriteItem) (*audit.Record, error) {
+	var touched []string
+	var after map[string]types.AttributeValue
+	if write.Update != nil {
+		var err error
+		if touched, after, err = applyUpdate(write.Update, key, before); err != nil {
+			return nil, err
+		}
+	} else {
+		for name := range before {
+			touched = append(touched, name)
+		}
+	}
+	sort.Strings(touched)
+
+	record := &audit.Record{
+		Timestamp: qe.auditNow(),
+		Key:       key,
+		OldValues: make(map[string]types.AttributeValue),
+		NewValues: make(map[string]types.AttributeValue),
+		Table:     table,
+		Operation: operation,
+		Actor:     audit.ActorFromContext(qe.ctxOrBackground()),
+	}
+
+	conditions := make([]string, 0, len(touched)+1)
+	names := make(map[string]string, len(touched)+1)
+	values := make(map[string]types.AttributeValue, len(touched))
+	if len(before) == 0 {
+		names["#auditKey"] = qe.metadata.PrimaryKey.PartitionKey.DBName
+		conditions = append(conditions, "attribute_not_exists(#auditKey)")
+	}
+	for i, name := range touched {
+		placeholder := fmt.Sprintf("#audit%d", i)
+		names[placeholder] = name
+		old, hadValue := before[name]
+		if hadValue {
+			value := fmt.Sprintf(":audit%d", i)
+			conditions = append(conditions, placeholder+" = "+value)
+			values[value] = old
+		} else {
+			conditions = append(conditions, "attribute_not_exists("+placeholder+")")
+		}
+
+		updated, hasValue := after[name]
+		if hadValue && hasValue && reflect.DeepEqual(old, updated) {
+			continue
+		}
+		if hadValue {
+			record.OldValues[name] = old
+		}
+		if hasValue {
+			record.NewValues[name] = updated
+		}
+	}
+	addUniqueCondition(write, strings.Join(conditions, " AND "), names, values)
+
+	if len(record.OldValues) == 0 && len(record.NewValues) == 0 {
+		return nil, nil
+	}
+	return record, nil
+}
+
+// auditPut returns the Put of record into the audit table, or false when records are
+// only published.
+func (qe *queryExecutor) auditPut(record *audit.Record) (types.TransactWriteItem, bool, error) {
+	table := qe.db.auditConfig().opts.Table
+	if table == "" {
+		return types.TransactWriteItem{}, false, nil
+	}
+	item, err := record.Item()
+	if err != nil {
+		return types.TransactWriteItem{}, false, err
+	}
+	return types.TransactWriteItem{Put: &types.Put{
+		TableName:                aws.String(table),
+		Item:                     item,
+		ConditionExpression:      aws.String("attribute_not_exists(#itemKey)"),
+		ExpressionAttributeNames: map[string]string{"#itemKey": audit.AttributeItemKey},
+	}}, true, nil
+}
+
+// publishAudit hands record, whose write has committed, to Options.Publish.
+func (qe *queryExecutor) publishAudit(record *audit.Record) error {
+	publish := qe.db.auditConfig().opts.Publish
+	if publish == nil {
+		return nil
+	}
+	if err := publish(qe.ctxOrBackground(), record); err != nil {
+		return fmt.Errorf("failed to publish audit record: %w", err)
+	}
+	return nil
+}
+
+// updateAuditedWithResult applies updateInput, the update of an audited item, and
+// returns the attributes its ReturnValues asks for, worked out from the item read before
+// the update.
+func (qe *queryExecutor) updateAuditedWithResult(updateInput *dynamodb.UpdateItemInput) (*core.UpdateResult, error) {
+	update := &types.Update{
+		TableName:                 updateInput.TableName,
+		Key:                       updateInput.Key,
+		UpdateExpression:          updateInput.UpdateExpression,
+		ConditionExpression:       updateInput.ConditionExpression,
+		ExpressionAttributeNames:  updateInput.ExpressionAttributeNames,
+		ExpressionAttributeValues: updateInput.ExpressionAttributeValues,
+	}
+	before, err := qe.writeUnique("UpdateItem", aws.ToString(updateInput.TableName), updateInput.Key, types.TransactWriteItem{Update: update},
+		func(before map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+			return before, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	touched, after, err := applyUpdate(update, updateInput.Key, before)
+	if err != nil {
+		return nil, err
+	}
+
+	var attributes map[string]types.AttributeValue
+	switch updateInput.ReturnValues {
+	case types.ReturnValueAllOld:
+		attributes = before
+	case types.ReturnValueAllNew:
+		attributes = after
+	case types.ReturnValueUpdatedOld, types.ReturnValueUpdatedNew:
+		source := before
+		if updateInput.ReturnValues == types.ReturnValueUpdatedNew {
+			source = after
+		}
+		attributes = make(map[string]types.AttributeValue, len(touched))
+		for _, name := range touched {
+			if av, ok := source[name]; ok {
+				attributes[name] = av
+			}
+		}
+	}
+	if err := qe.loadItem(attributes); err != nil {
+		return nil, err
+	}
+	return &core.UpdateResult{Attributes: attributes}, nil
+}
+
+// hasDeleteRequests reports whether a batch write deletes items.
+func hasDeleteRequests(writeRequests []types.WriteRequest) bool {
+	for _, request := range writeRequests {
+		if request.DeleteRequest != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// applyUpdate evaluates update as DynamoDB would on before, the item with key before it.
+// It returns the attributes the update assigns or removes and the item it leaves.
+func applyUpdate(update *types.Update, key, before map[string]types.AttributeValue) ([]string, map[string]types.AttributeValue, error) {
+	after := make(map[string]types.AttributeValue, len(before)+len(key))
+	for name, av := range key {
+		after[name] = av
+	}
+	for name, av := range before {
+		after[name] = av
+	}
+	names := update.ExpressionAttributeNames
+	values := update.ExpressionAttributeValues
+
+	var touched []string
+	clauses := updateClauses(aws.ToString(update.UpdateExpression))
+	for _, action := range splitTopLevel(clauses["SET"]) {
+		lhs, rhs, ok := strings.Cut(action, "=")
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid SET action %q", action)
+		}
+		name, err := updatePathName(lhs, names)
+		if err != nil {
+			return nil, nil, err
+		}
+		value, err := evalUpdateOperand(rhs, before, names, values)
+		if err != nil {
+			return nil, nil, err
+		}
+		after[name] = value
+		touched = append(touched, name)
+	}
+	for _, path := range splitTopLevel(clauses["REMOVE"]) {
+		name, err := updatePathName(path, names)
+		if err != nil {
+			return nil, nil, err
+		}
+		delete(after, name)
+		touched = append(touched, name)
+	}
+	for _, action := range []string{"ADD", "DELETE"} {
+		for _, part := range splitTopLevel(clauses[action]) {
+			fields := strings.Fields(part)
+			if len(fields) != 2 {
+				return nil, nil, fmt.Errorf("invalid %s action %q", action, part)
+			}
+			name, err := updatePathName(fields[0], names)
+			if err != nil {
+				return nil, nil, err
+			}
+			operand, ok := values[fields[1]]
+			if !ok {
+				return nil, nil, fmt.Errorf("update expression uses undefined value %s", fields[1])
+			}
+			value, err := applySetAction(action, before[name], operand)
+			if err != nil {
+				return nil, nil, fmt.Errorf("attribute %s: %w", name, err)
+			}
+			if value == nil {
+				delete(after, name)
+			} else {
+				after[name] = value
+			}
+			touched = append(touched, name)
+		}
+	}
+	return touched, after, nil
+}
+
+// updateClauses splits an update expression into the bodies of its SET, REMOVE, ADD,
+// and DELETE clauses.
+func updateClauses(expression string) map[string]string {
+	clauses := make(map[string]string)
+	action := ""
+	var body []string
+	flush := func() {
+		if action != "" {
+			clauses[action] = strings.Join(body, " ")
+		}
+		body = nil
+	}
+	for _, token := range strings.Fields(expression) {
+		switch token {
+		case "SET", "REMOVE", "ADD", "DELETE":
+			flush()
+			action = token
+		default:
+			body = append(body, token)
+		}
+	}
+	flush()
+	return clauses
+}
+
+// splitTopLevel splits s at the commas outside parentheses.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// updatePathName returns the attribute a document path of an audited update names. Only
+// whole attributes can be audited.
+func updatePathName(path string, names map[string]string) (string, error) {
+	path = strings.TrimSpace(path)
+	base, nested := path, false
+	if i := strings.IndexAny(path, ".["); i >= 0 {
+		base, nested = path[:i], true
+	}
+	name := base
+	if strings.HasPrefix(base, "#") {
+		var ok bool
+		if name, ok = names[base]; !ok {
+			return "", fmt.Errorf("update expression uses undefined name %s", base)
+		}
+	}
+	if nested {
+		return "", fmt.Errorf("%w: audited updates cannot use part of attribute %s; set the whole attribute",
+			customerrors.ErrInvalidOperator, name)
+	}
+	return name, nil
+}
+
+// evalUpdateOperand returns the value of the right-hand side of a SET action, evaluated
+// on before.
+func evalUpdateOperand(operand string, before map[string]types.AttributeValue, names map[string]string, values map[string]types.AttributeValue) (types.AttributeValue, error) {
+	operand = strings.TrimSpace(operand)
+	if left, right, subtract, ok := splitArithmetic(operand); ok {
+		a, err := evalUpdateOperand(left, before, names, values)
+		if err != nil {
+			return nil, err
+		}
+		b, err := evalUpdateOperand(right, before, names, values)
+		if err != nil {
+			return nil, err
+		}
+		return addNumbers(a, b, subtract)
+	}
+
+	if args, ok := functionArgs(operand, "if_not_exists"); ok && len(args) == 2 {
+		name, err := updatePathName(args[0], names)
+		if err != nil {
+			return nil, err
+		}
+		if current, ok := before[name]; ok {
+			return current, nil
+		}
+		return evalUpdateOperand(args[1], before, names, values)
+	}
+	if args, ok := functionArgs(operand, "list_append"); ok && len(args) == 2 {
+		a, err := evalUpdateOperand(args[0], before, names, values)
+		if err != nil {
+			return nil, err
+		}
+		b, err := evalUpdateOperand(args[1], before, names, values)
+		if err != nil {
+			return nil, err
+		}
+		first, okA := a.(*types.AttributeValueMemberL)
+		second, okB := b.(*types.AttributeValueMemberL)
+		if !okA || !okB {
+			return nil, fmt.Errorf("list_append operands must be lists")
+		}
+		return &types.AttributeValueMemberL{Value: append(append([]types.AttributeValue{}, first.Value...), second.Value...)}, nil
+	}
+	if strings.Contains(operand, "(") {
+		return nil, fmt.Errorf("%w: audited updates cannot use %s", customerrors.ErrInvalidOperator, operand)
+	}
+
+	if strings.HasPrefix(operand, ":") {
+		value, ok := values[operand]
+		if !ok {
+			return nil, fmt.Errorf("update expression uses undefined value %s", operand)
+		}
+		return value, nil
+	}
+	name, err := updatePathName(operand, names)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := before[name]
+	if !ok {
+		return nil, fmt.Errorf("update expression reads attribute %s, which the item does not have", name)
+	}
+	return value, nil
+}
+
+// splitArithmetic splits operand at a + or - outside parentheses.
+func splitArithmetic(operand string) (left, right string, subtract, ok bool) {
+	depth := 0
+	for i, r := range operand {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '+', '-':
+			if depth == 0 {
+				return operand[:i], operand[i+1:], r == '-', true
+			}
+		}
+	}
+	return "", "", false, false
+}
+
+// functionArgs returns the arguments of operand when it calls function.
+func functionArgs(operand, function string) ([]string, bool) {
+	if !strings.HasPrefix(operand, function+"(") || !strings.HasSuffix(operand, ")") {
+		return nil, false
+	}
+	return splitTopLevel(operand[len(function)+1 : len(operand)-1]), true
+}
+
+// applySetAction returns the value an ADD or DELETE action with operand leaves of
+// current, or nil when it leaves the attribute absent.
+func applySetAction(action string, current, operand types.AttributeValue) (types.AttributeValue, error) {
+	if current == nil {
+		if action == "ADD" {
+			return operand, nil
+		}
+		return nil, nil
+	}
+	remove := action == "DELETE"
+
+	switch c := current.(type) {
+	case *types.AttributeValueMemberN:
+		if remove {
+			return nil, fmt.Errorf("DELETE applies only to sets")
+		}
+		return addNumbers(c, operand, false)
+	case *types.AttributeValueMemberSS:
+		o, ok := operand.(*types.AttributeValueMemberSS)
+		if !ok {
+			return nil, fmt.Errorf("%s operand must be a string set", action)
+		}
+		return setOrNil(combineSet(c.Value, o.Value, remove, func(s string) string { return s }), func(v []string) types.AttributeValue {
+			return &types.AttributeValueMemberSS{Value: v}
+		}), nil
+	case *types.AttributeValueMemberNS:
+		o, ok := operand.(*types.AttributeValueMemberNS)
+		if !ok {
+			return nil, fmt.Errorf("%s operand must be a number set", action)
+		}
+		return setOrNil(combineSet(c.Value, o.Value, remove, normalizeNumber), func(v []string) types.AttributeValue {
+			return &types.AttributeValueMemberNS{Value: v}
+		}), nil
+	case *types.AttributeValueMemberBS:
+		o, ok := operand.(*types.AttributeValueMemberBS)
+		if !ok {
+			return nil, fmt.Errorf("%s operand must be a binary set", action)
+		}
+		return setOrNil(combineSet(c.Value, o.Value, remove, func(b []byte) string { return string(b) }), func(v [][]byte) types.AttributeValue {
+			return &types.AttributeValueMemberBS{Value: v}
+		}), nil
+	}
+	return nil, fmt.Errorf("%s applies only to numbers and sets", action)
+}
+
+// combineSet returns the union of current and operand, or current without operand's
+// elements when remove is set. Elements are compared by key.
+func combineSet[T any](current, operand []T, remove bool, key func(T) string) []T {
+	in := make(map[string]bool, len(operand))
+	for _, element := range operand {
+		in[key(element)] = true
+	}
+
+	out := make([]T, 0, len(current)+len(operand))
+	for _, element := range current {
+		if remove && in[key(element)] {
+			continue
+		}
+		delete(in, key(element))
+		out = append(out, element)
+	}
+	if !remove {
+		for _, element := range operand {
+			if in[key(element)] {
+				delete(in, key(element))
+				out = append(out, element)
+			}
+		}
+	}
+	return out
+}
+
+func setOrNil[T any](elements []T, wrap func([]T) types.AttributeValue) types.AttributeValue {
+	if len(elements) == 0 {
+		return nil
+	}
+	return wrap(elements)
+}
+
+// addNumbers returns a + b, or a - b when subtract is set, exactly.
+func addNumbers(a, b types.AttributeValue, subtract bool) (types.AttributeValue, error) {
+	x, okA := a.(*types.AttributeValueMemberN)
+	y, okB := b.(*types.AttributeValueMemberN)
+	if !okA || !okB {
+		return nil, fmt.Errorf("arithmetic operands must be numbers")
+	}
+	left, okA := new(big.Rat).SetString(x.Value)
+	right, okB := new(big.Rat).SetString(y.Value)
+	if !okA || !okB {
+		return nil, fmt.Errorf("invalid number %q or %q", x.Value, y.Value)
+	}
+	if subtract {
+		right.Neg(right)
+	}
+	return &types.AttributeValueMemberN{Value: formatDecimal(left.Add(left, right))}, nil
+}
+
+// normalizeNumber returns a canonical form of a DynamoDB number, so equal numbers
+// written differently compare equal.
+func normalizeNumber(n string) string {
+	if r, ok := new(big.Rat).SetString(n); ok {
+		return r.RatString()
+	}
+	return n
+}
+
+// formatDecimal formats r, a sum of decimal numbers, with as many fractional digits as
+// it needs.
+func formatDecimal(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	digits := 0
+	scale := big.NewInt(1)
+	ten := big.NewInt(10)
+	for rem := new(big.Int); rem.Mod(scale, r.Denom()).Sign() != 0; digits++ {
+		scale.Mul(scale, ten)
+	}
+	return r.FloatString(digits)
+}
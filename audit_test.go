@@ -0,0 +1,187 @@
+package dynamorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/audit"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/session"
+	"github.com/pay-theory/dynamorm/pkg/transaction"
+)
+
+type auditedPayment struct {
+	Metadata map[string]string `dynamorm:"attr:metadata"`
+	ID       string            `dynamorm:"pk,attr:id"`
+	Status   string            `dynamorm:"attr:status"`
+	Amount   int64             `dynamorm:"attr:amount"`
+	Version  int64             `dynamorm:"version,attr:version"`
+}
+
+func (auditedPayment) TableName() string { return "payments" }
+
+const auditedPaymentItem = `{"Item":{"id":{"S":"p1"},"status":{"S":"pending"},"amount":{"N":"100"},"version":{"N":"1"}}}`
+
+func newAuditedDB(t *testing.T, httpClient *capturingHTTPClient, opts audit.Options) core.DB {
+	t.Helper()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{Now: func() time.Time { return now }})
+	return db.WithAudit(opts).WithContext(audit.WithActor(context.Background(), "user:42"))
+}
+
+func TestAuditUpdateWritesRecordInTransaction(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{"DynamoDB_20120810.GetItem": auditedPaymentItem})
+	db := newAuditedDB(t, httpClient, audit.Options{Table: "payment-audit"})
+
+	require.NoError(t, db.Model(&auditedPayment{ID: "p1", Status: "settled", Version: 1}).Update("Status"))
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem"))
+	get := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.GetItem")
+	require.NotNil(t, get)
+	require.Equal(t, true, get.Payload["ConsistentRead"])
+	require.NotContains(t, get.Payload, "ProjectionExpression")
+
+	items := transactItems(t, httpClient)
+	require.Len(t, items, 2)
+	update := items[0]["Update"].(map[string]any)
+	require.Contains(t, update["ConditionExpression"], "#audit0 = :audit0 AND #audit1 = :audit1")
+	require.Equal(t, "status", update["ExpressionAttributeNames"].(map[string]any)["#audit0"])
+
+	put := items[1]["Put"].(map[string]any)
+	require.Equal(t, "payment-audit", put["TableName"])
+	item := put["Item"].(map[string]any)
+	require.Equal(t, map[string]any{"S": "payments#id=p1"}, item[audit.AttributeItemKey])
+	require.Regexp(t, `^2026-03-01T12:00:00\.000000000Z#[0-9a-f]{16}$`, item[audit.AttributeChangedAt].(map[string]any)["S"])
+	require.Equal(t, map[string]any{"S": "user:42"}, item[audit.AttributeActor])
+	require.Equal(t, map[string]any{"S": audit.OperationUpdate}, item[audit.AttributeOperation])
+	require.Equal(t, map[string]any{"M": map[string]any{
+		"status":  map[string]any{"S": "pending"},
+		"version": map[string]any{"N": "1"},
+	}}, item[audit.AttributeOldValues])
+	require.Equal(t, map[string]any{"M": map[string]any{
+		"status":  map[string]any{"S": "settled"},
+		"version": map[string]any{"N": "2"},
+	}}, item[audit.AttributeNewValues])
+}
+
+func TestAuditDeleteRecordsWholeItem(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{"DynamoDB_20120810.GetItem": auditedPaymentItem})
+	db := newAuditedDB(t, httpClient, audit.Options{Table: "payment-audit"})
+
+	require.NoError(t, db.Model(&auditedPayment{ID: "p1"}).Delete())
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.DeleteItem"))
+
+	items := transactItems(t, httpClient)
+	require.Len(t, items, 2)
+	record := items[1]["Put"].(map[string]any)["Item"].(map[string]any)
+	require.Equal(t, map[string]any{"S": audit.OperationDelete}, record[audit.AttributeOperation])
+	require.Len(t, record[audit.AttributeOldValues].(map[string]any)["M"], 4)
+	require.Equal(t, map[string]any{"M": map[string]any{}}, record[audit.AttributeNewValues])
+}
+
+func TestAuditPublishesRecords(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{"DynamoDB_20120810.GetItem": auditedPaymentItem})
+	var published []*audit.Record
+	db := newAuditedDB(t, httpClient, audit.Options{Publish: func(_ context.Context, record *audit.Record) error {
+		published = append(published, record)
+		return nil
+	}})
+
+	var result auditedPayment
+	err := db.Model(&auditedPayment{}).Where("ID", "=", "p1").UpdateBuilder().
+		Add("Amount", 25).
+		ReturnValues("ALL_NEW").
+		ExecuteWithResult(&result)
+	require.NoError(t, err)
+	require.Equal(t, auditedPayment{ID: "p1", Status: "pending", Amount: 125, Version: 1}, result)
+
+	require.Len(t, transactItems(t, httpClient), 1)
+	require.Len(t, published, 1)
+	require.Equal(t, "user:42", published[0].Actor)
+	require.Equal(t, map[string]types.AttributeValue{"amount": &types.AttributeValueMemberN{Value: "100"}}, published[0].OldValues)
+	require.Equal(t, map[string]types.AttributeValue{"amount": &types.AttributeValueMemberN{Value: "125"}}, published[0].NewValues)
+}
+
+func TestAuditRejectsUnauditableWrites(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{"DynamoDB_20120810.GetItem": auditedPaymentItem})
+	db := newAuditedDB(t, httpClient, audit.Options{Table: "payment-audit"})
+
+	err := db.Model(&auditedPayment{}).Where("ID", "=", "p1").UpdateBuilder().SetMapKey("Metadata", "note", "x").Execute()
+	require.ErrorIs(t, err, customerrors.ErrInvalidOperator)
+
+	err = db.Model(&auditedPayment{}).BatchDelete([]any{&auditedPayment{ID: "p1"}})
+	require.ErrorIs(t, err, customerrors.ErrInvalidOperator)
+
+	err = db.(*DB).TransactWrite(context.Background(), func(tx core.TransactionBuilder) error {
+		tx.Update(&auditedPayment{ID: "p1", Status: "settled"}, []string{"Status"})
+		return nil
+	})
+	require.ErrorIs(t, err, customerrors.ErrInvalidOperator)
+
+	audited := db.(*DB)
+	for _, op := range []string{"update", "delete"} {
+		err = audited.TransactionFunc(func(tx any) error {
+			txn := tx.(*transaction.Transaction)
+			return map[string]func(any) error{"update": txn.Update, "delete": txn.Delete}[op](&auditedPayment{ID: "p1", Status: "settled"})
+		})
+		require.ErrorIs(t, err, customerrors.ErrInvalidOperator, op)
+	}
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.TransactWriteItems"))
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.BatchWriteItem"))
+
+	err = audited.PartiQL(`UPDATE payments SET status = ? WHERE id = ?`, "settled", "p1").Exec()
+	require.ErrorIs(t, err, customerrors.ErrInvalidOperator)
+	_, err = audited.BatchPartiQL(PartiQLStatement{Statement: `DELETE FROM "payments" WHERE id = ?`, Params: []any{"p1"}})
+	require.ErrorIs(t, err, customerrors.ErrInvalidOperator)
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.ExecuteStatement"))
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.BatchExecuteStatement"))
+	require.NoError(t, audited.PartiQL(`INSERT INTO payments VALUE {'id': ?}`, "p2").Exec())
+}
+
+func TestApplyUpdate(t *testing.T) {
+	before := map[string]types.AttributeValue{
+		"balance": &types.AttributeValueMemberN{Value: "10.25"},
+		"tags":    &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+		"events":  &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "created"}}},
+		"note":    &types.AttributeValueMemberS{Value: "x"},
+	}
+	update := &types.Update{
+		UpdateExpression: aws.String("SET #balance = #balance - :fee, #events = list_append(#events, :event), #count = if_not_exists(#count, :zero) + :one " +
+			"REMOVE #note ADD #total :fee DELETE #tags :tag"),
+		ExpressionAttributeNames: map[string]string{
+			"#balance": "balance", "#events": "events", "#count": "count", "#note": "note", "#total": "total", "#tags": "tags",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":fee":   &types.AttributeValueMemberN{Value: "0.5"},
+			":event": &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "charged"}}},
+			":zero":  &types.AttributeValueMemberN{Value: "0"},
+			":one":   &types.AttributeValueMemberN{Value: "1"},
+			":tag":   &types.AttributeValueMemberSS{Value: []string{"a"}},
+		},
+	}
+	key := map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "p1"}}
+
+	touched, after, err := applyUpdate(update, key, before)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"balance", "events", "count", "note", "total", "tags"}, touched)
+	require.Equal(t, map[string]types.AttributeValue{
+		"id":      &types.AttributeValueMemberS{Value: "p1"},
+		"balance": &types.AttributeValueMemberN{Value: "9.75"},
+		"events": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "created"},
+			&types.AttributeValueMemberS{Value: "charged"},
+		}},
+		"count": &types.AttributeValueMemberN{Value: "1"},
+		"total": &types.AttributeValueMemberN{Value: "0.5"},
+		"tags":  &types.AttributeValueMemberSS{Value: []string{"b"}},
+	}, after)
+
+	update.UpdateExpression = aws.String("SET #events[0] = :event")
+	_, _, err = applyUpdate(update, key, before)
+	require.ErrorIs(t, err, customerrors.ErrInvalidOperator)
+}
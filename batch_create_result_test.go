@@ -0,0 +1,170 @@
+package dynamorm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/throttle"
+)
+
+func fastBatchCreateOptions(retries int) *core.BatchCreateOptions {
+	return &core.BatchCreateOptions{
+		RetryPolicy: &core.RetryPolicy{MaxRetries: retries, InitialDelay: time.Millisecond, BackoffFactor: 1},
+	}
+}
+
+func TestBatchCreateWithOptions_RetriesUnprocessedItems(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.BatchWriteItem", []stubbedResponse{
+		{body: `{"UnprocessedItems":{"test_accounts":[{"PutRequest":{"Item":{"id":{"S":"a2"},"balance":{"N":"2"},"version":{"N":"0"}}}}]}}`},
+		{body: `{"UnprocessedItems":{}}`},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	accounts := []testAccount{{ID: "a1", Balance: 1}, {ID: "a2", Balance: 2}}
+	result, err := db.Model(&testAccount{}).BatchCreateWithOptions(accounts, fastBatchCreateOptions(2))
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Succeeded)
+	require.Empty(t, result.Unwritten())
+	require.Equal(t, 2, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.BatchWriteItem"))
+}
+
+func TestBatchCreateWithOptions_ReportsThrottledItems(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.BatchWriteItem": `{"UnprocessedItems":{"test_accounts":[{"PutRequest":{"Item":{"id":{"S":"a2"},"balance":{"N":"2"},"version":{"N":"0"}}}}]}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	accounts := []testAccount{{ID: "a1", Balance: 1}, {ID: "a2", Balance: 2}}
+	result, err := db.Model(&testAccount{}).BatchCreateWithOptions(accounts, fastBatchCreateOptions(1))
+	require.ErrorIs(t, err, customerrors.ErrBatchOperationFailed)
+	var batchErr *customerrors.BatchWriteError
+	require.ErrorAs(t, err, &batchErr)
+	require.Equal(t, 1, batchErr.Succeeded)
+	require.Equal(t, 1, batchErr.Throttled)
+
+	require.Equal(t, core.BatchItemSucceeded, result.Items[0].Status)
+	require.Equal(t, core.BatchItemThrottled, result.Items[1].Status)
+	require.Equal(t, []int{1}, result.Unwritten())
+	require.Equal(t, 2, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.BatchWriteItem"))
+}
+
+func TestBatchCreateWithOptions_ConditionalPerItem(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.PutItem", []stubbedResponse{
+		{body: `{}`},
+		{
+			status: http.StatusBadRequest,
+			body:   `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"Conditional request failed"}`,
+			headers: map[string]string{
+				"x-amzn-errortype": "ConditionalCheckFailedException",
+			},
+		},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	accounts := []testAccount{{ID: "a1"}, {ID: "a2"}}
+	result, err := db.Model(&testAccount{}).IfNotExists().BatchCreateWithOptions(accounts, nil)
+	require.ErrorIs(t, err, customerrors.ErrBatchOperationFailed)
+	require.Equal(t, core.BatchItemSucceeded, result.Items[0].Status)
+	require.Equal(t, core.BatchItemFailed, result.Items[1].Status)
+	require.ErrorIs(t, result.Items[1].Err, customerrors.ErrConditionFailed)
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.BatchWriteItem"))
+
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	require.Equal(t, "attribute_not_exists(#n1)", put.Payload["ConditionExpression"])
+}
+
+func TestBatchCreateWithOptions_FailsDuplicateKeysInChunk(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.BatchWriteItem": `{"UnprocessedItems":{}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	accounts := []testAccount{{ID: "a1", Balance: 1}, {ID: "a2"}, {ID: "a1", Balance: 3}}
+	result, err := db.Model(&testAccount{}).BatchCreateWithOptions(accounts, fastBatchCreateOptions(0))
+	require.ErrorIs(t, err, customerrors.ErrBatchOperationFailed)
+	require.Equal(t, core.BatchItemSucceeded, result.Items[0].Status)
+	require.Equal(t, core.BatchItemSucceeded, result.Items[1].Status)
+	require.Equal(t, core.BatchItemFailed, result.Items[2].Status)
+	require.ErrorContains(t, result.Items[2].Err, "same primary key as item 0")
+
+	write := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.BatchWriteItem")
+	require.NotNil(t, write)
+	requestItems, ok := write.Payload["RequestItems"].(map[string]any)
+	require.True(t, ok)
+	require.Len(t, requestItems, 1)
+	for _, writes := range requestItems {
+		require.Len(t, writes, 2)
+	}
+}
+
+func TestBatchCreateWithOptions_BackoffStopsWhenContextEnds(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.BatchWriteItem": `{"UnprocessedItems":{"test_accounts":[{"PutRequest":{"Item":{"id":{"S":"a2"},"balance":{"N":"2"},"version":{"N":"0"}}}}]}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	opts := &core.BatchCreateOptions{RetryPolicy: &core.RetryPolicy{MaxRetries: 3, InitialDelay: time.Minute, BackoffFactor: 1}}
+	accounts := []testAccount{{ID: "a1", Balance: 1}, {ID: "a2", Balance: 2}}
+
+	start := time.Now()
+	result, err := db.WithContext(ctx).Model(&testAccount{}).BatchCreateWithOptions(accounts, opts)
+	require.Less(t, time.Since(start), 10*time.Second)
+	require.ErrorIs(t, err, customerrors.ErrBatchOperationFailed)
+	require.Equal(t, core.BatchItemSucceeded, result.Items[0].Status)
+	require.Equal(t, core.BatchItemThrottled, result.Items[1].Status)
+	require.ErrorIs(t, result.Items[1].Err, context.Canceled)
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.BatchWriteItem"))
+}
+
+func TestBatchCreateWithOptions_FeedbackShrinksChunksAfterUnprocessedItems(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.BatchWriteItem", []stubbedResponse{
+		{body: `{"UnprocessedItems":{"test_accounts":[{"PutRequest":{"Item":{"id":{"S":"a2"},"balance":{"N":"2"},"version":{"N":"0"}}}}]}}`},
+		{body: `{"UnprocessedItems":{}}`},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	feedback := throttle.NewController(throttle.Options{})
+	opts := fastBatchCreateOptions(2)
+	opts.ChunkSize = 4
+	opts.Feedback = feedback
+
+	accounts := make([]testAccount, 8)
+	for i := range accounts {
+		accounts[i] = testAccount{ID: fmt.Sprintf("a%d", i+1), Balance: int64(i + 1)}
+	}
+	result, err := db.Model(&testAccount{}).BatchCreateWithOptions(accounts, opts)
+	require.NoError(t, err)
+	require.Equal(t, 8, result.Succeeded)
+
+	var sizes []int
+	for _, req := range httpClient.Requests() {
+		if req.Target != "DynamoDB_20120810.BatchWriteItem" {
+			continue
+		}
+		for _, writes := range req.Payload["RequestItems"].(map[string]any) {
+			sizes = append(sizes, len(writes.([]any)))
+		}
+	}
+	// The first chunk leaves one of four items unprocessed, halving the chunks after it.
+	require.Equal(t, []int{4, 1, 2, 2}, sizes)
+
+	stats := feedback.Stats()
+	require.Len(t, stats, 1)
+	require.Equal(t, 0.5, stats[0].Scale)
+	require.Equal(t, int64(1), stats[0].Throttled)
+}
@@ -0,0 +1,80 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+func TestBatchGet_OnMissingReportsKeysWithoutItems(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.BatchGetItem": unorderedBatchGetResponse,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var missing []any
+	var accounts []testAccount
+	err := db.Model(&testAccount{}).BatchGetWithOptions([]any{"a1", "gone", "a2", "also-gone"}, &accounts, &core.BatchGetOptions{
+		OnMissing: func(keys []any) { missing = keys },
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	require.Equal(t, []any{"gone", "also-gone"}, missing)
+}
+
+func TestBatchGet_ProjectionKeepsKeysForMatching(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.BatchGetItem": unorderedBatchGetResponse,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	called := false
+	var accounts []testAccount
+	err := db.Model(&testAccount{}).BatchGetBuilder().
+		Keys([]any{"a1", "a2"}).
+		Select("Balance").
+		OnMissing(func([]any) { called = true }).
+		Execute(&accounts)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	require.False(t, called, "OnMissing is not called when every key matched")
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.BatchGetItem")
+	table := req.Payload["RequestItems"].(map[string]any)["testAccounts"].(map[string]any)
+	require.Equal(t, "#n1, #n2", table["ProjectionExpression"])
+	require.ElementsMatch(t, []any{"balance", "id"}, valuesOf(table["ExpressionAttributeNames"].(map[string]any)))
+}
+
+func TestBatchGet_OnMissingSkipsSwallowedChunks(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.BatchGetItem", []stubbedResponse{
+		{body: unorderedBatchGetResponse},
+		{
+			status:  400,
+			headers: map[string]string{"X-Amzn-ErrorType": "ValidationException"},
+			body:    `{"__type":"com.amazon.coral.validate#ValidationException","message":"bad chunk"}`,
+		},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var missing []any
+	var accounts []testAccount
+	err := db.Model(&testAccount{}).BatchGetWithOptions([]any{"a1", "gone", "x1", "x2"}, &accounts, &core.BatchGetOptions{
+		ChunkSize:    2,
+		OnChunkError: func([]any, error) error { return nil },
+		OnMissing:    func(keys []any) { missing = keys },
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, []any{"gone"}, missing)
+}
+
+func valuesOf(m map[string]any) []any {
+	out := make([]any, 0, len(m))
+	for _, v := range m {
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+const unorderedBatchGetResponse = `{"Responses":{"testAccounts":[` +
+	`{"id":{"S":"a2"},"balance":{"N":"20"}},` +
+	`{"id":{"S":"a1"},"balance":{"N":"10"}}` +
+	`]},"UnprocessedKeys":{}}`
+
+func TestBatchGetOrdered_AlignsResultsWithKeys(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.BatchGetItem": unorderedBatchGetResponse,
+	})
+	db := newStubbedDB(t, httpClient)
+	keys := []any{"a1", "missing", "a2"}
+
+	var pointers []*testAccount
+	require.NoError(t, db.Model(&testAccount{}).BatchGetOrdered(keys, &pointers))
+	require.Len(t, pointers, 3)
+	require.Equal(t, "a1", pointers[0].ID)
+	require.Nil(t, pointers[1])
+	require.Equal(t, int64(20), pointers[2].Balance)
+
+	var values []testAccount
+	require.NoError(t, db.Model(&testAccount{}).BatchGetOrdered(keys, &values))
+	require.Equal(t, []testAccount{{ID: "a1", Balance: 10}, {}, {ID: "a2", Balance: 20}}, values)
+
+	var raw []map[string]types.AttributeValue
+	require.NoError(t, db.Model(&testAccount{}).BatchGetOrdered(keys, &raw))
+	require.Len(t, raw, 3)
+	require.Nil(t, raw[1])
+
+	typed, err := ModelOf[testAccount](db).BatchGetOrdered(keys...)
+	require.NoError(t, err)
+	require.Nil(t, typed[1])
+	require.Equal(t, "a2", typed[2].ID)
+}
+
+func TestBatchGetMap_KeysResultsByRequestedKey(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.BatchGetItem": unorderedBatchGetResponse,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var byID map[string]*testAccount
+	require.NoError(t, db.Model(&testAccount{}).BatchGetMap([]any{"a1", "missing", "a2"}, &byID))
+	require.Len(t, byID, 2)
+	require.Equal(t, int64(10), byID["a1"].Balance)
+	require.Equal(t, int64(20), byID["a2"].Balance)
+	require.NotContains(t, byID, "missing")
+
+	typed, err := BatchGetMap(ModelOf[testAccount](db), "a1", "a2")
+	require.NoError(t, err)
+	require.Equal(t, testAccount{ID: "a1", Balance: 10}, typed["a1"])
+}
+
+func TestBatchGetMap_RejectsIncompatibleKeys(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+
+	var byNumber map[int]testAccount
+	err := db.Model(&testAccount{}).BatchGetMap([]any{"a1"}, &byNumber)
+	require.ErrorContains(t, err, "cannot be used as a int map key")
+
+	var notMap []testAccount
+	require.ErrorContains(t, db.Model(&testAccount{}).BatchGetMap([]any{"a1"}, &notMap), "pointer to map")
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.BatchGetItem"))
+}
@@ -0,0 +1,44 @@
+package dynamorm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type ledgerEntry struct {
+	ID      string   `dynamorm:"pk,attr:id"`
+	Balance *big.Int `dynamorm:"attr:balance"`
+	Rate    big.Rat  `dynamorm:"attr:rate"`
+}
+
+func (ledgerEntry) TableName() string { return "ledger_entries" }
+
+func TestBigNumbers_StoredAsExactNumbers(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"l1"},"balance":{"N":"98765432109876543210987654321"},"rate":{"N":"0.0425"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	balance, _ := new(big.Int).SetString("12345678901234567890123456789", 10)
+	entry := &ledgerEntry{ID: "l1", Balance: balance}
+	entry.Rate.SetFrac64(17, 400)
+	require.NoError(t, db.Model(entry).Create())
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	item := put.Payload["Item"].(map[string]any)
+	require.Equal(t, map[string]any{"N": "12345678901234567890123456789"}, item["balance"])
+	require.Equal(t, map[string]any{"N": "0.0425"}, item["rate"])
+
+	var out ledgerEntry
+	require.NoError(t, db.Model(&ledgerEntry{}).Where("ID", "=", "l1").First(&out))
+	require.Equal(t, "98765432109876543210987654321", out.Balance.String())
+	require.Equal(t, "17/400", out.Rate.RatString())
+
+	require.NoError(t, db.Model(&ledgerEntry{}).Where("ID", "=", "l1").
+		UpdateBuilder().Set("Balance", big.NewInt(0).Add(balance, big.NewInt(1))).Execute())
+	update := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem")
+	require.NotNil(t, update)
+	require.Contains(t, expressionValues(update.Payload), map[string]any{"N": "12345678901234567890123456790"})
+}
@@ -0,0 +1,69 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type blobItem struct {
+	ID       string   `dynamorm:"pk,attr:id"`
+	Data     []byte   `dynamorm:"attr:data"`
+	Hashes   [][]byte `dynamorm:"set,attr:hashes"`
+	Parts    [][]byte `dynamorm:"attr:parts"`
+	Checksum []byte   `dynamorm:"attr:checksum,omitempty"`
+}
+
+func (blobItem) TableName() string { return "blobs" }
+
+func TestBinaryAttributes(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"b1"},"data":{"B":"AQID"},"hashes":{"BS":["AQ==","Ag=="]},"parts":{"L":[{"B":"CQ=="}]}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	item := &blobItem{ID: "b1", Data: []byte{1, 2, 3}, Hashes: [][]byte{{1}, {2}}, Parts: [][]byte{{9}}}
+	require.NoError(t, db.Model(item).Create())
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	stored := put.Payload["Item"].(map[string]any)
+	require.Equal(t, map[string]any{"B": "AQID"}, stored["data"])
+	require.Equal(t, map[string]any{"BS": []any{"AQ==", "Ag=="}}, stored["hashes"])
+	require.Equal(t, map[string]any{"L": []any{map[string]any{"B": "CQ=="}}}, stored["parts"])
+	require.NotContains(t, stored, "checksum")
+
+	var out blobItem
+	require.NoError(t, db.Model(&blobItem{}).Where("ID", "=", "b1").First(&out))
+	require.Equal(t, blobItem{ID: "b1", Data: []byte{1, 2, 3}, Hashes: [][]byte{{1}, {2}}, Parts: [][]byte{{9}}}, out)
+
+	require.NoError(t, db.Model(&blobItem{}).Where("ID", "=", "b1").UpdateBuilder().
+		Add("Hashes", [][]byte{{3}, {4}}).
+		Delete("Hashes", []byte{1}).
+		Execute())
+	update := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem")
+	require.NotNil(t, update)
+	// Clause order is not fixed, so check each clause.
+	require.Contains(t, update.Payload["UpdateExpression"], "ADD #n1 :v1")
+	require.Contains(t, update.Payload["UpdateExpression"], "DELETE #n1 :v2")
+	require.Equal(t, map[string]any{
+		":v1": map[string]any{"BS": []any{"Aw==", "BA=="}},
+		":v2": map[string]any{"BS": []any{"AQ=="}},
+	}, update.Payload["ExpressionAttributeValues"])
+}
+
+func TestUpdateBuilderAddToSets(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+
+	require.NoError(t, db.Model(&blobItem{}).Where("ID", "=", "b1").UpdateBuilder().
+		Add("Hashes", []byte{7}).
+		Add("labels", []string{"archived"}).
+		Add("Version", 1).
+		Execute())
+	update := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem")
+	require.NotNil(t, update)
+	values := expressionValues(update.Payload)
+	require.Contains(t, values, map[string]any{"BS": []any{"Bw=="}}, "a []byte is one binary element")
+	require.Contains(t, values, map[string]any{"SS": []any{"archived"}})
+	require.Contains(t, values, map[string]any{"N": "1"})
+}
@@ -0,0 +1,81 @@
+package dynamorm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/internal/encryption"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+type blindIndexPatient struct {
+	ID      string `dynamorm:"pk,attr:id"`
+	SSN     string `dynamorm:"encrypted,attr:ssn"`
+	SSNHash string `dynamorm:"blindindex:SSN,index:gsi-ssn,pk,attr:ssnHash"`
+}
+
+func (blindIndexPatient) TableName() string { return "patients" }
+
+func newBlindIndexDB(t *testing.T, httpClient *capturingHTTPClient, key []byte) *DB {
+	t.Helper()
+	dataKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0x01}, 32))
+	httpClient.SetResponseSequence("TrentService.GenerateDataKey", []stubbedResponse{{
+		headers: map[string]string{"Content-Type": "application/x-amz-json-1.1"},
+		body:    `{"Plaintext":"` + dataKey + `","CiphertextBlob":"ZWRr","KeyId":"arn:aws:kms:us-east-1:111111111111:key/test"}`,
+	}})
+	return newStubbedDBWithConfig(t, httpClient, session.Config{
+		KMSKeyARN:     "arn:aws:kms:us-east-1:111111111111:key/test",
+		BlindIndexKey: key,
+	})
+}
+
+func TestBlindIndexWrittenAndQueried(t *testing.T) {
+	key := bytes.Repeat([]byte{0x02}, 32)
+	want, err := encryption.BlindIndex(key, "ssn", &types.AttributeValueMemberS{Value: "123-45-6789"})
+	require.NoError(t, err)
+
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[],"Count":0,"ScannedCount":0}`,
+	})
+	db := newBlindIndexDB(t, httpClient, key)
+
+	require.NoError(t, db.Model(&blindIndexPatient{ID: "p1", SSN: "123-45-6789"}).Create())
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	item := put.Payload["Item"].(map[string]any)
+	require.Equal(t, map[string]any{"S": want}, item["ssnHash"])
+	require.Contains(t, item["ssn"], "M")
+
+	require.NoError(t, db.Model(&blindIndexPatient{ID: "p1", SSN: "123-45-6789"}).Update("SSN"))
+	update := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem")
+	require.NotNil(t, update)
+	require.Equal(t, map[string]any{"S": want}, update.Payload["ExpressionAttributeValues"].(map[string]any)[":v2"])
+
+	var patients []blindIndexPatient
+	require.NoError(t, db.Model(&blindIndexPatient{}).Where("SSN", "=", "123-45-6789").All(&patients))
+	query := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, query)
+	require.Equal(t, "gsi-ssn", query.Payload["IndexName"])
+	require.Equal(t, map[string]any{":v1": map[string]any{"S": want}}, query.Payload["ExpressionAttributeValues"])
+
+	err = db.Model(&blindIndexPatient{}).Where("SSN", "BEGINS_WITH", "123").All(&patients)
+	require.ErrorIs(t, err, customerrors.ErrEncryptedFieldNotQueryable)
+}
+
+func TestBlindIndexFailsClosedWithoutKey(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newBlindIndexDB(t, httpClient, []byte("short"))
+
+	err := db.Model(&blindIndexPatient{ID: "p1", SSN: "123-45-6789"}).Create()
+	require.ErrorIs(t, err, customerrors.ErrEncryptionNotConfigured)
+
+	var patients []blindIndexPatient
+	err = db.Model(&blindIndexPatient{}).Where("SSN", "=", "123-45-6789").All(&patients)
+	require.ErrorIs(t, err, customerrors.ErrEncryptionNotConfigured)
+	require.Empty(t, httpClient.Requests())
+}
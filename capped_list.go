@@ -0,0 +1,111 @@
+package dynamorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/pay-theory/dynamorm/internal/deadline"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// AppendToCappedList appends values to the list field of the item identified by item's
+// primary key (which must already be set), keeping at most maxLen elements.
+//
+// While the list has room this is a single UpdateItem built with AppendToListWithLimit.
+// Once it is full the item is read with a consistent read, the oldest elements are dropped
+// to make room, and the whole list is written back conditioned on it still holding what
+// was read. A concurrent writer causes the read and rotation to be retried, up to the
+// configured number of attempts, after which ErrReadModifyWriteConflict is returned.
+//
+// values must have the same type as the list field. item itself is not modified.
+func AppendToCappedList[T any](db core.DB, item *T, field string, values any, maxLen int, opts ...ReadModifyWriteOption) error {
+	if db == nil {
+		return fmt.Errorf("db cannot be nil")
+	}
+	if item == nil {
+		return fmt.Errorf("item cannot be nil")
+	}
+	if maxLen < 1 {
+		return fmt.Errorf("maxLen must be at least 1")
+	}
+
+	cfg := readModifyWriteConfig{
+		maxAttempts: defaultReadModifyWriteAttempts,
+		backoff:     defaultReadModifyWriteBackoff,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	meta, err := metadataForDB(db, item)
+	if err != nil {
+		return err
+	}
+	listField := lookupField(meta, field)
+	if listField == nil {
+		return fmt.Errorf("%w: field %s not found on %s", customerrors.ErrInvalidModel, field, meta.Type.Name())
+	}
+	if listField.Type.Kind() != reflect.Slice {
+		return fmt.Errorf("%w: field %s is not a list", customerrors.ErrInvalidModel, field)
+	}
+	appended := reflect.ValueOf(values)
+	if !appended.IsValid() || appended.Type() != listField.Type {
+		return fmt.Errorf("values must be of type %s, got %T", listField.Type, values)
+	}
+	if appended.Len() > maxLen {
+		return fmt.Errorf("cannot append %d values to a list capped at %d", appended.Len(), maxLen)
+	}
+
+	keyConditions := primaryKeyConditions(meta, reflect.ValueOf(item).Elem())
+	keyed := func(q core.Query) core.Query {
+		for _, cond := range keyConditions {
+			q = q.Where(cond.field, "=", cond.value)
+		}
+		return q
+	}
+
+	err = keyed(db.Model(new(T))).UpdateBuilder().AppendToListWithLimit(listField.Name, values, maxLen).Execute()
+	if !errors.Is(err, customerrors.ErrConditionFailed) {
+		return err
+	}
+
+	ctx := contextForDB(db)
+	delay := cfg.backoff
+	for attempt := 1; attempt <= cfg.maxAttempts; attempt++ {
+		current := new(T)
+		if err := keyed(db.Model(current).ConsistentRead()).First(current); err != nil {
+			return fmt.Errorf("failed to load item: %w", err)
+		}
+		existing := reflect.ValueOf(current).Elem().FieldByIndex(listField.IndexPath)
+
+		rotated := reflect.AppendSlice(reflect.MakeSlice(listField.Type, 0, existing.Len()+appended.Len()), existing)
+		rotated = reflect.AppendSlice(rotated, appended)
+		if rotated.Len() > maxLen {
+			rotated = rotated.Slice(rotated.Len()-maxLen, rotated.Len())
+		}
+
+		err := keyed(db.Model(new(T))).UpdateBuilder().
+			Set(listField.Name, rotated.Interface()).
+			Condition(listField.Name, "=", existing.Interface()).
+			Execute()
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, customerrors.ErrConditionFailed) {
+			return fmt.Errorf("failed to write item: %w", err)
+		}
+
+		if attempt < cfg.maxAttempts && delay > 0 {
+			if err := deadline.Sleep(ctx, delay); err != nil {
+				return err
+			}
+			delay *= 2
+		}
+	}
+
+	return ErrReadModifyWriteConflict
+}
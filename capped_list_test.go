@@ -0,0 +1,103 @@
+package dynamorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+type cappedFeed struct {
+	ID     string   `dynamorm:"pk,attr:id"`
+	Events []string `dynamorm:"attr:events"`
+}
+
+func TestAppendToCappedList_AppendsWhileUnderLimit(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.UpdateItem": `{}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	require.NoError(t, AppendToCappedList(db, &cappedFeed{ID: "f1"}, "Events", []string{"login"}, 3))
+
+	reqs := httpClient.Requests()
+	require.Equal(t, 1, countRequestsByTarget(reqs, "DynamoDB_20120810.UpdateItem"))
+	require.Zero(t, countRequestsByTarget(reqs, "DynamoDB_20120810.GetItem"))
+
+	update := findRequestByTarget(reqs, "DynamoDB_20120810.UpdateItem").Payload
+	require.Contains(t, update["UpdateExpression"], "list_append(if_not_exists(")
+	require.Contains(t, update["ConditionExpression"], "size(")
+}
+
+func TestAppendToCappedList_RotatesWhenFull(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"f1"},"events":{"L":[{"S":"a"},{"S":"b"},{"S":"c"}]}}}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.UpdateItem", []stubbedResponse{
+		conditionalCheckFailedResponse,
+		{body: `{}`},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	require.NoError(t, AppendToCappedList(db, &cappedFeed{ID: "f1"}, "Events", []string{"d"}, 3))
+
+	var updates []map[string]any
+	for _, req := range httpClient.Requests() {
+		if req.Target == "DynamoDB_20120810.UpdateItem" {
+			updates = append(updates, req.Payload)
+		}
+	}
+	require.Len(t, updates, 2)
+
+	rotate := updates[1]
+	require.Contains(t, rotate["ConditionExpression"], "=")
+	values := rotate["ExpressionAttributeValues"].(map[string]any)
+	require.Contains(t, values, ":v1")
+	require.Equal(t, map[string]any{"L": []any{
+		map[string]any{"S": "b"}, map[string]any{"S": "c"}, map[string]any{"S": "d"},
+	}}, values[":v1"])
+}
+
+func TestAppendToCappedList_GivesUpAfterConflicts(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"f1"},"events":{"L":[{"S":"a"}]}}}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.UpdateItem", []stubbedResponse{conditionalCheckFailedResponse})
+	db := newStubbedDB(t, httpClient)
+
+	err := AppendToCappedList(db, &cappedFeed{ID: "f1"}, "Events", []string{"b"}, 1,
+		WithReadModifyWriteAttempts(2), WithReadModifyWriteBackoff(0))
+	require.ErrorIs(t, err, ErrReadModifyWriteConflict)
+	require.Equal(t, 3, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem"))
+}
+
+func TestAppendToCappedList_StopsWaitingWhenContextEnds(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"f1"},"events":{"L":[{"S":"a"}]}}}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.UpdateItem", []stubbedResponse{conditionalCheckFailedResponse})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	db := newStubbedDB(t, httpClient).WithContext(ctx)
+
+	err := AppendToCappedList(db, &cappedFeed{ID: "f1"}, "Events", []string{"b"}, 1,
+		WithReadModifyWriteAttempts(2), WithReadModifyWriteBackoff(time.Hour))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, 2, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem"))
+}
+
+func TestAppendToCappedList_Validation(t *testing.T) {
+	db := newBareDB()
+
+	err := AppendToCappedList(db, &cappedFeed{ID: "f1"}, "ID", "x", 3)
+	require.ErrorIs(t, err, customerrors.ErrInvalidModel)
+
+	err = AppendToCappedList(db, &cappedFeed{ID: "f1"}, "Events", []any{"x"}, 3)
+	require.ErrorContains(t, err, "values must be of type []string")
+
+	err = AppendToCappedList(db, &cappedFeed{ID: "f1"}, "Events", []string{"a", "b"}, 1)
+	require.ErrorContains(t, err, "capped at 1")
+}
@@ -0,0 +1,81 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestCreateWithClientToken_RetriedCreateMatchingTokenSucceeds(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"p1"},"clientToken":{"S":"tok-1"},"amount":{"N":"100"}}}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.PutItem", []stubbedResponse{conditionalCheckFailedResponse})
+	db := newStubbedDB(t, httpClient)
+
+	payment := &testPayment{ID: "p1", Amount: 100}
+	require.NoError(t, db.Model(payment).IfNotExists().WithClientToken("ClientToken", "tok-1").Create())
+	require.Equal(t, "tok-1", payment.ClientToken)
+
+	reqs := httpClient.Requests()
+	put := findRequestByTarget(reqs, "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	require.Equal(t, map[string]any{"S": "tok-1"}, put.Payload["Item"].(map[string]any)["clientToken"])
+	get := findRequestByTarget(reqs, "DynamoDB_20120810.GetItem")
+	require.NotNil(t, get)
+	require.Equal(t, true, get.Payload["ConsistentRead"])
+}
+
+func TestCreateWithClientToken_DifferentTokenIsDuplicate(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"p1"},"clientToken":{"S":"someone-else"},"amount":{"N":"100"}}}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.PutItem", []stubbedResponse{conditionalCheckFailedResponse})
+	db := newStubbedDB(t, httpClient)
+
+	err := db.Model(&testPayment{ID: "p1", Amount: 100}).IfNotExists().WithClientToken("clientToken", "tok-1").Create()
+	require.ErrorIs(t, err, customerrors.ErrConditionFailed)
+	require.ErrorContains(t, err, "already exists")
+}
+
+func TestCreateWithClientToken_TimeoutResolvedByRead(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"p1"},"clientToken":{"S":"tok-1"},"amount":{"N":"100"}}}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.PutItem", []stubbedResponse{{err: timeoutError{}}})
+	db := newStubbedDB(t, httpClient)
+
+	require.NoError(t, db.Model(&testPayment{ID: "p1", Amount: 100}).WithClientToken("ClientToken", "tok-1").Create())
+}
+
+func TestCreateWithClientToken_TimeoutWithoutItemReturnsError(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.PutItem", []stubbedResponse{{err: timeoutError{}}})
+	db := newStubbedDB(t, httpClient)
+
+	err := db.Model(&testPayment{ID: "p1", Amount: 100}).WithClientToken("ClientToken", "tok-1").Create()
+	require.ErrorContains(t, err, "i/o timeout")
+}
+
+func TestCreateWithClientToken_Validation(t *testing.T) {
+	db := newBareDB()
+
+	err := db.Model(&testPayment{ID: "p1"}).WithClientToken("Missing", "tok").Create()
+	require.ErrorIs(t, err, customerrors.ErrInvalidModel)
+
+	err = db.Model(&testPayment{ID: "p1"}).WithClientToken("Amount", "tok").Create()
+	require.ErrorContains(t, err, "must be a string")
+
+	err = db.Model(&testPayment{ID: "p1"}).WithClientToken("ClientToken", "").Create()
+	require.ErrorContains(t, err, "client token cannot be empty")
+}
@@ -0,0 +1,39 @@
+package dynamorm
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// OnConsumedCapacity calls fn after every operation for which DynamoDB reported consumed
+// capacity. Operations that have not asked for capacity, through Query.ReturnConsumedCapacity
+// or an earlier middleware, ask for TOTAL. fn receives the request context and the
+// operation, whose Table, Index, Tags, and ConsumedCapacity attribute the cost, for
+// example to the tenant in a request tag:
+//
+//	db.OnConsumedCapacity(func(ctx context.Context, op *dynamorm.Operation) {
+//		for _, c := range op.ConsumedCapacity {
+//			meter.Add(op.Tags["tenant"], aws.ToFloat64(c.CapacityUnits))
+//		}
+//	})
+//
+// The callback is registered as middleware, so it covers the operations Use covers and is
+// inherited the same way.
+func (db *DB) OnConsumedCapacity(fn func(ctx context.Context, op *Operation)) {
+	if fn == nil {
+		return
+	}
+	db.Use(func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) error {
+			if op.ReturnConsumedCapacity == "" {
+				op.ReturnConsumedCapacity = types.ReturnConsumedCapacityTotal
+			}
+			err := next(ctx, op)
+			if len(op.ConsumedCapacity) > 0 {
+				fn(ctx, op)
+			}
+			return err
+		}
+	})
+}
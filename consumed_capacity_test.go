@@ -0,0 +1,88 @@
+package dynamorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnConsumedCapacity_ReportsCapacityWithTags(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.PutItem":    `{"ConsumedCapacity":{"TableName":"tenantOrders","CapacityUnits":2}}`,
+		"DynamoDB_20120810.UpdateItem": `{"ConsumedCapacity":{"TableName":"tenantOrders","CapacityUnits":1}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	usage := make(map[string]float64)
+	var ops []string
+	db.OnConsumedCapacity(func(_ context.Context, op *Operation) {
+		ops = append(ops, op.Type)
+		for _, c := range op.ConsumedCapacity {
+			usage[op.Tags["tenant"]] += aws.ToFloat64(c.CapacityUnits)
+		}
+	})
+
+	ctx := WithRequestTags(context.Background(), map[string]string{"tenant": "t1"})
+	order := &tenantOrder{TenantKey: "TENANT#t1", ID: "o1", Status: "open"}
+	require.NoError(t, db.WithContext(ctx).Model(order).Create())
+	require.NoError(t, db.WithContext(ctx).Model(&tenantOrder{}).
+		Where("TenantKey", "=", "TENANT#t1").
+		Where("ID", "=", "o1").
+		UpdateBuilder().
+		Set("Status", "paid").
+		Execute())
+
+	require.Equal(t, []string{OperationPutItem, OperationUpdateItem}, ops)
+	require.Equal(t, map[string]float64{"t1": 3}, usage)
+
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	require.Equal(t, "TOTAL", put.Payload["ReturnConsumedCapacity"])
+}
+
+func TestReturnConsumedCapacity_PaginatedResult(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[],"Count":0,"ScannedCount":0,"ConsumedCapacity":{"TableName":"tenantOrders","CapacityUnits":0.5,"GlobalSecondaryIndexes":{}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var orders []tenantOrder
+	result, err := db.Model(&tenantOrder{}).
+		Where("TenantKey", "=", "TENANT#t1").
+		ReturnConsumedCapacity("INDEXES").
+		AllPaginated(&orders)
+	require.NoError(t, err)
+	require.Len(t, result.ConsumedCapacity, 1)
+	require.Equal(t, 0.5, aws.ToFloat64(result.ConsumedCapacity[0].CapacityUnits))
+
+	query := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, query)
+	require.Equal(t, string(types.ReturnConsumedCapacityIndexes), query.Payload["ReturnConsumedCapacity"])
+}
+
+func TestReturnConsumedCapacity_NotRequestedByDefault(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[],"Count":0,"ScannedCount":0}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var orders []tenantOrder
+	result, err := db.Model(&tenantOrder{}).Where("TenantKey", "=", "TENANT#t1").AllPaginated(&orders)
+	require.NoError(t, err)
+	require.Empty(t, result.ConsumedCapacity)
+
+	query := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, query)
+	require.NotContains(t, query.Payload, "ReturnConsumedCapacity")
+}
+
+func TestReturnConsumedCapacity_RejectsUnknownMode(t *testing.T) {
+	db := newStubbedDB(t, newCapturingHTTPClient(nil))
+
+	var orders []tenantOrder
+	err := db.Model(&tenantOrder{}).Where("TenantKey", "=", "TENANT#t1").ReturnConsumedCapacity("ALL").All(&orders)
+	require.ErrorContains(t, err, `invalid ReturnConsumedCapacity mode "ALL"`)
+}
@@ -0,0 +1,48 @@
+package dynamorm
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/contention"
+)
+
+// Contention returns the tracker that counts conditional-check failures and
+// transaction conflicts per table and partition key. Use Report to find hot keys and
+// SetHook to forward each event to a metrics system:
+//
+//	db.Contention().SetHook(func(e contention.Event) {
+//		metrics.Incr("dynamodb.conflict", "table:"+e.Table, "kind:"+string(e.Kind))
+//	})
+//	for _, hot := range db.Contention().Report(10) { ... }
+func (db *DB) Contention() *contention.Tracker {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.contention == nil {
+		db.contention = contention.NewTracker()
+	}
+	return db.contention
+}
+
+func (db *DB) contentionTracker() *contention.Tracker {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.contention
+}
+
+// recordConditionFailure counts a failed condition for the item identified by key (a
+// key map or full item).
+func (qe *queryExecutor) recordConditionFailure(operation, tableName string, key map[string]types.AttributeValue) {
+	if qe == nil || qe.db == nil {
+		return
+	}
+	tracker := qe.db.contentionTracker()
+	if tracker == nil {
+		return
+	}
+
+	var partitionKey types.AttributeValue
+	if qe.metadata != nil && qe.metadata.PrimaryKey != nil && qe.metadata.PrimaryKey.PartitionKey != nil {
+		partitionKey = key[qe.metadata.PrimaryKey.PartitionKey.DBName]
+	}
+	tracker.Record(contention.KindConditionFailed, operation, tableName, partitionKey)
+}
@@ -0,0 +1,45 @@
+package dynamorm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/contention"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+func TestContention_RecordsConditionFailures(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.PutItem", []stubbedResponse{
+		conditionalCheckFailedResponse,
+		conditionalCheckFailedResponse,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var events []contention.Event
+	db.Contention().SetHook(func(e contention.Event) { events = append(events, e) })
+
+	for i := 0; i < 2; i++ {
+		err := db.Model(&testOrderModel{TenantID: "counter", CreatedAt: "global"}).Create()
+		require.True(t, errors.Is(err, customerrors.ErrConditionFailed))
+	}
+
+	report := db.Contention().Report(10)
+	require.Len(t, report, 1)
+	require.Equal(t, "orders_test", report[0].Table)
+	require.Equal(t, "counter", report[0].KeyPrefix)
+	require.Equal(t, int64(2), report[0].ConditionFailures)
+
+	require.Len(t, events, 2)
+	require.Equal(t, "PutItem", events[0].Operation)
+	require.Equal(t, contention.KindConditionFailed, events[0].Kind)
+}
+
+func TestContention_SharedWithDerivedDB(t *testing.T) {
+	db := newBareDB()
+	tracker := db.Contention()
+	require.Same(t, tracker, db.Contention())
+	require.Same(t, tracker, db.derive().contention)
+}
@@ -0,0 +1,40 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery_CountWithResult_GSIReportsScannedCount(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.Query", []stubbedResponse{
+		{body: `{"Count":2,"ScannedCount":10,"LastEvaluatedKey":{"customerId":{"S":"c1"}}}`},
+		{body: `{"Count":1,"ScannedCount":5}`},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	result, err := db.Model(&accessPatternOrder{}).
+		Index("gsi-customer").
+		Where("CustomerID", "=", "c1").
+		Filter("Status", "=", "open").
+		Select("OrderID", "Status").
+		CountWithResult()
+	require.NoError(t, err)
+	require.Equal(t, int64(3), result.Count)
+	require.Equal(t, int64(15), result.ScannedCount)
+	require.InDelta(t, 0.2, result.FilterEfficiency(), 1e-9)
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, req)
+	require.Equal(t, "COUNT", req.Payload["Select"])
+	require.Equal(t, "gsi-customer", req.Payload["IndexName"])
+	require.NotEmpty(t, req.Payload["KeyConditionExpression"])
+	require.NotEmpty(t, req.Payload["FilterExpression"])
+	require.NotContains(t, req.Payload, "ProjectionExpression")
+	require.Equal(t, map[string]any{
+		"#n2":     "customerId",
+		"#STATUS": "status",
+	}, req.Payload["ExpressionAttributeNames"])
+	require.Equal(t, 2, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Query"))
+}
@@ -0,0 +1,130 @@
+package dynamorm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+
+	"github.com/pay-theory/dynamorm/pkg/assumerole"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/leadingkeys"
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+// WithCredentialsProvider returns a DB whose requests are signed with provider instead of
+// the session's credentials, such as credentials issued for one request's caller. It
+// shares the session's configuration and HTTP connections. See session.WithCredentials
+// for the clients it affects. DynamORM cannot tell whom provider's credentials belong to,
+// so the DB bypasses the item cache and read de-duplication rather than share items with
+// other principals; WithAssumedRole keeps both.
+func (db *DB) WithCredentialsProvider(provider aws.CredentialsProvider) core.ExtendedDB {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	newDB := db.derive()
+	newDB.session = db.session.WithCredentials(provider)
+	newDB.principal = ""
+	newDB.unknownPrincipal = true
+	newDB.roles = newRoleSessions(newDB.session)
+	return newDB
+}
+
+// WithAssumedRole returns a DB whose requests are signed with temporary credentials of
+// roleARN, assumed with externalID when it is not empty:
+//
+//	scoped := db.WithTenant(ctx, tenantID).
+//		WithAssumedRole(roleARN, "", assumerole.WithTenantTag("TenantID"))
+//
+// The role is assumed with the DB's credentials, including those of an earlier
+// WithCredentialsProvider or WithAssumedRole, on the first request. DBs derived from the
+// same New call with the same credentials share the credentials of identical role
+// sessions and refresh them before they expire, so a role session per tenant costs one
+// STS call per tenant, not per request. WithTenantTag tags the session with the tenant
+// from the DB's context; the role is not assumed, and requests fail, when there is none.
+// Items the DB caches and reads it de-duplicates are kept apart from those of other role
+// sessions.
+func (db *DB) WithAssumedRole(roleARN, externalID string, opts ...assumerole.Option) core.ExtendedDB {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var tenant string
+	if db.ctx != nil {
+		tenant, _ = leadingkeys.TenantFromContext(db.ctx)
+	}
+
+	var provider aws.CredentialsProvider
+	key, err := assumerole.SessionKey(roleARN, externalID, tenant, opts...)
+	if err == nil {
+		provider, err = db.roles.cache().Provider(roleARN, externalID, tenant, opts...)
+	}
+	if err != nil {
+		provider = aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{}, err
+		})
+	}
+
+	newDB := db.derive()
+	newDB.session = db.session.WithCredentials(provider)
+	newDB.principal = principalID("role", key)
+	newDB.unknownPrincipal = false
+	newDB.roles = db.roles.assumed(key, newDB.session)
+	return newDB
+}
+
+// principalID identifies the principal described by parts in the keys of the item cache
+// and read de-duplication, without exposing the parts themselves.
+func principalID(kind string, parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return kind + ":" + hex.EncodeToString(sum[:16])
+}
+
+// roleSessions holds the role sessions assumed with one set of credentials, through an
+// STS client signing with them that is built on first use.
+type roleSessions struct {
+	session *session.Session
+	roles   *assumerole.Cache
+	chained map[string]*roleSessions
+	once    sync.Once
+	mu      sync.Mutex
+}
+
+func newRoleSessions(sess *session.Session) *roleSessions {
+	return &roleSessions{session: sess}
+}
+
+// cache returns the role sessions, or nil when r is nil.
+func (r *roleSessions) cache() *assumerole.Cache {
+	if r == nil {
+		return nil
+	}
+	r.once.Do(func() {
+		if r.roles == nil && r.session != nil {
+			r.roles = assumerole.NewCache(sts.NewFromConfig(r.session.AWSConfig()), 0)
+		}
+	})
+	return r.roles
+}
+
+// assumed returns the role sessions assumed with the credentials of the role session
+// with key, which sign sess's requests, shared by every DB assuming roles with them.
+func (r *roleSessions) assumed(key string, sess *session.Session) *roleSessions {
+	if r == nil || key == "" {
+		return newRoleSessions(sess)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if chained, ok := r.chained[key]; ok {
+		return chained
+	}
+	if r.chained == nil || len(r.chained) >= assumerole.DefaultMaxRoles {
+		r.chained = make(map[string]*roleSessions)
+	}
+	chained := newRoleSessions(sess)
+	r.chained[key] = chained
+	return chained
+}
@@ -0,0 +1,152 @@
+package dynamorm
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/assumerole"
+	"github.com/pay-theory/dynamorm/pkg/cache"
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+type credentialsNote struct {
+	ID   string `dynamorm:"pk,attr:id"`
+	Body string `dynamorm:"attr:body"`
+}
+
+func (credentialsNote) TableName() string { return "notes" }
+
+// stubAssumeRole issues credentials lasting ttl (an hour when zero), or fails with err.
+type stubAssumeRole struct {
+	err   error
+	calls []*sts.AssumeRoleInput
+	ttl   time.Duration
+}
+
+func (s *stubAssumeRole) AssumeRole(_ context.Context, in *sts.AssumeRoleInput, _ ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	s.calls = append(s.calls, in)
+	if s.err != nil {
+		return nil, s.err
+	}
+	ttl := s.ttl
+	if ttl == 0 {
+		ttl = time.Hour
+	}
+	return &sts.AssumeRoleOutput{Credentials: &ststypes.Credentials{
+		AccessKeyId:     aws.String("ASIATENANT"),
+		SecretAccessKey: aws.String("secret"),
+		SessionToken:    aws.String("token"),
+		Expiration:      aws.Time(time.Now().Add(ttl)),
+	}}, nil
+}
+
+func TestWithCredentialsProvider(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+
+	var retrieved atomic.Int32
+	provider := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		retrieved.Add(1)
+		return aws.Credentials{AccessKeyID: "SCOPED", SecretAccessKey: "secret"}, nil
+	})
+
+	require.NoError(t, db.WithCredentialsProvider(provider).Model(&credentialsNote{ID: "n1"}).Create())
+	require.Equal(t, int32(1), retrieved.Load())
+
+	require.NoError(t, db.Model(&credentialsNote{ID: "n2"}).Create())
+	require.Equal(t, int32(1), retrieved.Load())
+	require.Equal(t, 2, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem"))
+}
+
+func TestWithAssumedRole(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+	stub := &stubAssumeRole{}
+	db.roles = &roleSessions{roles: assumerole.NewCache(stub, 0)}
+
+	scoped := db.WithTenant(context.Background(), "t1")
+	for _, id := range []string{"o1", "o2"} {
+		err := scoped.WithAssumedRole("arn:aws:iam::123:role/tenant", "ext", assumerole.WithTenantTag("TenantID")).
+			Model(&tenantShipment{ID: id}).Create()
+		require.NoError(t, err)
+	}
+	require.Len(t, stub.calls, 1)
+	require.Equal(t, "ext", aws.ToString(stub.calls[0].ExternalId))
+	require.Equal(t, []ststypes.Tag{{Key: aws.String("TenantID"), Value: aws.String("t1")}}, stub.calls[0].Tags)
+	require.Equal(t, 2, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem"))
+
+	err := db.WithAssumedRole("arn:aws:iam::123:role/tenant", "", assumerole.WithTenantTag("TenantID")).
+		Model(&credentialsNote{ID: "n3"}).Create()
+	require.ErrorContains(t, err, "session tag TenantID needs a tenant")
+	require.Equal(t, 2, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem"))
+}
+
+func TestAssumedRolesDoNotShareCachedReads(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": cachedAccountResponse,
+	})
+	db := newStubbedDB(t, httpClient)
+	db.roles = &roleSessions{roles: assumerole.NewCache(&stubAssumeRole{}, 0)}
+	cached := db.WithItemCache(cache.Options{Store: cache.NewLRU(16)})
+	read := func(scoped core.DB) {
+		t.Helper()
+		var account testAccount
+		require.NoError(t, scoped.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+	}
+	roleA := cached.WithAssumedRole("arn:aws:iam::123:role/a", "")
+	roleB := cached.WithAssumedRole("arn:aws:iam::123:role/b", "")
+
+	read(roleA)
+	read(roleA)
+	read(cached.WithAssumedRole("arn:aws:iam::123:role/a", ""))
+	require.Equal(t, 1, getItemCalls(httpClient))
+	read(roleB)
+	require.Equal(t, 2, getItemCalls(httpClient), "roles do not share cached items")
+	read(cached)
+	require.Equal(t, 3, getItemCalls(httpClient))
+
+	require.NoError(t, roleA.Model(&testAccount{ID: "a1", Balance: 20}).CreateOrUpdate())
+	read(roleB)
+	require.Equal(t, 4, getItemCalls(httpClient), "a role's write invalidates other roles' copies")
+
+	provided := cached.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("SCOPED", "secret", ""))
+	read(provided)
+	read(provided)
+	require.Equal(t, 6, getItemCalls(httpClient), "credentials of unknown principals bypass the cache")
+
+	ctx := WithReadDedup(context.Background())
+	read(db.WithAssumedRole("arn:aws:iam::123:role/a", "").WithContext(ctx))
+	read(db.WithAssumedRole("arn:aws:iam::123:role/a", "").WithContext(ctx))
+	require.Equal(t, 7, getItemCalls(httpClient))
+	read(db.WithAssumedRole("arn:aws:iam::123:role/b", "").WithContext(ctx))
+	require.Equal(t, 8, getItemCalls(httpClient), "roles do not share de-duplicated reads")
+	read(db.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("SCOPED", "secret", "")).WithContext(ctx))
+	require.Equal(t, 9, getItemCalls(httpClient))
+}
+
+func TestWithAssumedRoleUsesCurrentCredentials(t *testing.T) {
+	db := newStubbedDB(t, newCapturingHTTPClient(nil))
+	provided := db.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("SCOPED", "secret", "")).(*DB)
+	require.NotSame(t, db.roles, provided.roles)
+
+	roles := provided.roles.cache()
+	require.NotNil(t, roles)
+	require.NotSame(t, db.roles.cache(), roles)
+	creds, err := provided.roles.session.AWSConfig().Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "SCOPED", creds.AccessKeyID, "roles are assumed with the DB's credentials")
+
+	first := provided.WithAssumedRole("arn:aws:iam::123:role/a", "").(*DB)
+	second := provided.WithTenant(context.Background(), "t1").WithAssumedRole("arn:aws:iam::123:role/a", "").(*DB)
+	require.Same(t, roles, provided.WithTenant(context.Background(), "t1").(*DB).roles.cache(), "derived DBs share the role sessions")
+	require.Same(t, first.roles, second.roles, "chained roles share the role sessions of their credentials")
+	require.Equal(t, first.principal, second.principal)
+}
@@ -0,0 +1,80 @@
+package dynamorm
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+// money is stored as a decimal number of currency units rather than as cents.
+type money int64
+
+func (m money) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return &types.AttributeValueMemberN{Value: strconv.FormatFloat(float64(m)/100, 'f', 2, 64)}, nil
+}
+
+func (m *money) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	n, ok := av.(*types.AttributeValueMemberN)
+	if !ok {
+		return fmt.Errorf("money must be a number, got %T", av)
+	}
+	units, err := strconv.ParseFloat(n.Value, 64)
+	*m = money(math.Round(units * 100))
+	return err
+}
+
+var _ Marshaler = money(0)
+var _ Unmarshaler = (*money)(nil)
+
+type pricedItem struct {
+	ID       string `dynamorm:"pk,attr:id"`
+	Price    money  `dynamorm:"attr:price"`
+	Discount *money `dynamorm:"attr:discount,omitempty"`
+}
+
+func (pricedItem) TableName() string { return "priced_items" }
+
+func TestCustomMarshaler_UsedForWritesConditionsAndReads(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"p1"},"price":{"N":"12.34"},"discount":{"N":"0.5"}}}`,
+		"DynamoDB_20120810.Query":   `{"Items":[],"Count":0,"ScannedCount":0}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	require.NoError(t, db.Model(&pricedItem{ID: "p1", Price: 1234}).Create())
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	item := put.Payload["Item"].(map[string]any)
+	require.Equal(t, map[string]any{"N": "12.34"}, item["price"])
+	require.NotContains(t, item, "discount")
+
+	var matches []pricedItem
+	require.NoError(t, db.Model(&pricedItem{}).Where("ID", "=", "p1").Filter("Price", ">=", money(500)).All(&matches))
+	query := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, query)
+	require.Contains(t, expressionValues(query.Payload), map[string]any{"N": "5.00"})
+
+	require.NoError(t, db.Model(&pricedItem{}).Where("ID", "=", "p1").UpdateBuilder().Set("Price", money(999)).Execute())
+	update := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem")
+	require.NotNil(t, update)
+	require.Contains(t, expressionValues(update.Payload), map[string]any{"N": "9.99"})
+
+	var out pricedItem
+	require.NoError(t, db.Model(&pricedItem{}).Where("ID", "=", "p1").First(&out))
+	require.Equal(t, money(1234), out.Price)
+	require.NotNil(t, out.Discount)
+	require.Equal(t, money(50), *out.Discount)
+}
+
+func expressionValues(payload map[string]any) []any {
+	values, _ := payload["ExpressionAttributeValues"].(map[string]any)
+	out := make([]any, 0, len(values))
+	for _, v := range values {
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,55 @@
+package dynamorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+func TestDAX_RoutesReadsAndWritesThroughDAX(t *testing.T) {
+	dynamoHTTP := newCapturingHTTPClient(nil)
+	daxHTTP := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"a1"},"balance":{"N":"10"},"version":{"N":"1"}}}`,
+		"DynamoDB_20120810.Query":   `{"Items":[{"id":{"S":"a1"},"balance":{"N":"10"},"version":{"N":"1"}}],"Count":1}`,
+	})
+
+	stubSessionConfigLoad(t, func(context.Context, ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return minimalAWSConfig(dynamoHTTP), nil
+	})
+	dbAny, err := New(session.Config{
+		Region:       "us-east-1",
+		EnableDAX:    true,
+		DAXEndpoints: []string{"dax://cluster.example.com"},
+		DAXClientFactory: func(aws.Config, []string) (session.DAXClient, error) {
+			return dynamodb.NewFromConfig(minimalAWSConfig(daxHTTP)), nil
+		},
+	})
+	require.NoError(t, err)
+	db := mustDB(t, dbAny)
+
+	var got testAccount
+	require.NoError(t, db.Model(&testAccount{}).Where("ID", "=", "a1").First(&got))
+	require.Equal(t, int64(10), got.Balance)
+
+	var all []testAccount
+	require.NoError(t, db.Model(&testAccount{}).Where("ID", "=", "a1").All(&all))
+	require.Len(t, all, 1)
+
+	require.NoError(t, db.Model(&testAccount{ID: "a2", Balance: 5}).Create())
+	require.NoError(t, db.Model(&testAccount{ID: "a2", Balance: 6}).Update("Balance"))
+	require.NoError(t, db.Model(&testAccount{ID: "a2"}).Delete())
+
+	daxReqs := daxHTTP.Requests()
+	require.Equal(t, 1, countRequestsByTarget(daxReqs, "DynamoDB_20120810.GetItem"))
+	require.Equal(t, 1, countRequestsByTarget(daxReqs, "DynamoDB_20120810.Query"))
+	require.Equal(t, 1, countRequestsByTarget(daxReqs, "DynamoDB_20120810.PutItem"))
+	require.Equal(t, 1, countRequestsByTarget(daxReqs, "DynamoDB_20120810.UpdateItem"))
+	require.Equal(t, 1, countRequestsByTarget(daxReqs, "DynamoDB_20120810.DeleteItem"))
+	require.Empty(t, dynamoHTTP.Requests())
+}
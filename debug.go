@@ -0,0 +1,269 @@
+package dynamorm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pay-theory/dynamorm/pkg/accesspattern"
+	"github.com/pay-theory/dynamorm/pkg/contention"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/model"
+	"github.com/pay-theory/dynamorm/pkg/slowquery"
+)
+
+// debugHotKeyLimit caps how many contended keys Stats reports.
+const debugHotKeyLimit = 10
+
+// Stats is a point-in-time snapshot of a DB's registry, caches, and recent problems.
+type Stats struct {
+	LambdaDeadline          *time.Time        `json:"lambdaDeadline,omitempty"`
+	Circuit                 CircuitStats      `json:"circuit"`
+	AccessPatternMode       string            `json:"accessPatternMode"`
+	Models                  []ModelStats      `json:"models"`
+	HotKeys                 []HotKeyStats     `json:"hotKeys"`
+	SlowQueries             []slowquery.Entry `json:"slowQueries"`
+	SlowQueryThreshold      time.Duration     `json:"slowQueryThreshold"`
+	MetadataCacheEntries    int               `json:"metadataCacheEntries"`
+	TransactionTokens       int               `json:"transactionTokens"`
+	AccessPatternViolations int               `json:"accessPatternViolations"`
+	ItemSizeValidation      bool              `json:"itemSizeValidation"`
+	IndexSelection          bool              `json:"indexSelection"`
+}
+
+// Circuit states reported in CircuitStats.State.
+const (
+	// CircuitClosed means the DB accepts new operations.
+	CircuitClosed = "closed"
+	// CircuitDraining means Shutdown has been called and operations are still in flight.
+	CircuitDraining = "draining"
+	// CircuitOpen means Shutdown has finished draining; new operations fail with
+	// ErrShuttingDown.
+	CircuitOpen = "open"
+)
+
+// CircuitStats reports whether the DB is accepting operations and where they are sent.
+type CircuitStats struct {
+	State    string `json:"state"`
+	InFlight int    `json:"inFlight"`
+	DAX      bool   `json:"dax"`
+}
+
+// ModelStats describes one registered model.
+type ModelStats struct {
+	Type         string       `json:"type"`
+	Table        string       `json:"table"`
+	PartitionKey string       `json:"partitionKey"`
+	SortKey      string       `json:"sortKey,omitempty"`
+	Indexes      []IndexStats `json:"indexes,omitempty"`
+	Fields       int          `json:"fields"`
+}
+
+// IndexStats describes one secondary index of a registered model.
+type IndexStats struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	PartitionKey string `json:"partitionKey,omitempty"`
+	SortKey      string `json:"sortKey,omitempty"`
+}
+
+// HotKeyStats is a contention.HotKey as reported by Stats.
+type HotKeyStats struct {
+	LastSeen             time.Time `json:"lastSeen"`
+	Table                string    `json:"table"`
+	KeyPrefix            string    `json:"keyPrefix"`
+	ConditionFailures    int64     `json:"conditionFailures"`
+	TransactionConflicts int64     `json:"transactionConflicts"`
+}
+
+// SlowQueries returns the log of operations that took at least its threshold (one second
+// by default). Use SetThreshold to tune it, or a threshold of zero to disable it.
+func (db *DB) SlowQueries() *slowquery.Log {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.slowQueries == nil {
+		db.slowQueries = slowquery.NewLog()
+	}
+	return db.slowQueries
+}
+
+// Stats returns a snapshot of the registered models, cache sizes, contended keys, and
+// recent slow operations.
+func (db *DB) Stats() Stats {
+	db.mu.RLock()
+	registry := db.registry
+	patterns := db.accessPatterns
+	tracker := db.contention
+	slow := db.slowQueries
+	tokens := db.txTokens
+	sess := db.session
+	stats := Stats{
+		ItemSizeValidation: db.validateItemSize,
+		IndexSelection:     !db.noIndexSelection,
+		AccessPatternMode:  accessPatternModeName(accesspattern.ModeOff),
+		Models:             []ModelStats{},
+		HotKeys:            []HotKeyStats{},
+		SlowQueries:        []slowquery.Entry{},
+	}
+	if !db.lambdaDeadline.IsZero() {
+		deadline := db.lambdaDeadline
+		stats.LambdaDeadline = &deadline
+	}
+	db.mu.RUnlock()
+
+	db.metadataCache.Range(func(any, any) bool {
+		stats.MetadataCacheEntries++
+		return true
+	})
+	stats.TransactionTokens = tokens.Len()
+	stats.Circuit = db.lifecycleState().circuit()
+	stats.Circuit.DAX = sess.DAXEnabled()
+
+	if registry != nil {
+		for _, meta := range registry.Models() {
+			stats.Models = append(stats.Models, modelStats(meta))
+		}
+	}
+	if patterns != nil {
+		stats.AccessPatternMode = accessPatternModeName(patterns.Mode())
+		stats.AccessPatternViolations = len(patterns.Violations())
+	}
+	for _, hot := range tracker.Report(debugHotKeyLimit) {
+		stats.HotKeys = append(stats.HotKeys, hotKeyStats(hot))
+	}
+	if slow != nil {
+		stats.SlowQueryThreshold = slow.Threshold()
+		stats.SlowQueries = append(stats.SlowQueries, slow.Recent()...)
+	}
+	return stats
+}
+
+// DebugExposeKeys makes DebugHandler report contended partition key values as stored.
+// Without it each HotKeyStats.KeyPrefix is replaced by a short SHA-256 digest, which
+// still groups repeated conflicts but does not reveal customer identifiers.
+func DebugExposeKeys() DebugOption {
+	return func(opts *core.DebugOptions) {
+		opts.ExposeKeys = true
+	}
+}
+
+// DebugOption configures DebugHandler.
+type DebugOption = core.DebugOption
+
+// DebugHandler returns an http.Handler that serves Stats as JSON: the circuit state,
+// registered models with their table, key, and index attribute names, cache sizes,
+// contended keys (hashed unless DebugExposeKeys is passed), access pattern state, and
+// recent slow operations. Mount it only on a private ops listener.
+func (db *DB) DebugHandler(opts ...DebugOption) http.Handler {
+	var options core.DebugOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		stats := db.Stats()
+		if !options.ExposeKeys {
+			for i := range stats.HotKeys {
+				stats.HotKeys[i].KeyPrefix = redactKey(stats.HotKeys[i].KeyPrefix)
+			}
+		}
+
+		body, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(body)
+		}
+	})
+}
+
+func modelStats(meta *model.Metadata) ModelStats {
+	stats := ModelStats{
+		Type:   meta.Type.String(),
+		Table:  meta.TableName,
+		Fields: len(meta.Fields),
+	}
+	if meta.PrimaryKey != nil {
+		stats.PartitionKey = fieldDBName(meta.PrimaryKey.PartitionKey)
+		stats.SortKey = fieldDBName(meta.PrimaryKey.SortKey)
+	}
+	for _, index := range meta.Indexes {
+		stats.Indexes = append(stats.Indexes, IndexStats{
+			Name:         index.Name,
+			Type:         string(index.Type),
+			PartitionKey: fieldDBName(index.PartitionKey),
+			SortKey:      fieldDBName(index.SortKey),
+		})
+	}
+	return stats
+}
+
+func fieldDBName(field *model.FieldMetadata) string {
+	if field == nil {
+		return ""
+	}
+	return field.DBName
+}
+
+func hotKeyStats(hot contention.HotKey) HotKeyStats {
+	return HotKeyStats{
+		LastSeen:             hot.LastSeen,
+		Table:                hot.Table,
+		KeyPrefix:            hot.KeyPrefix,
+		ConditionFailures:    hot.ConditionFailures,
+		TransactionConflicts: hot.TransactionConflicts,
+	}
+}
+
+// redactKey replaces a key value with a short digest. The overflow bucket keeps its name.
+func redactKey(key string) string {
+	if key == "" || key == contention.OverflowKey {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:6])
+}
+
+func accessPatternModeName(mode accesspattern.Mode) string {
+	switch mode {
+	case accesspattern.ModeReport:
+		return "report"
+	case accesspattern.ModeEnforce:
+		return "enforce"
+	default:
+		return "off"
+	}
+}
+
+// observeCompiled records the latency of a single-table operation in the slow query log.
+func (qe *queryExecutor) observeCompiled(operation string, input *core.CompiledQuery, start time.Time) {
+	if input == nil {
+		return
+	}
+	qe.observeLatency(operation, input.TableName, input.IndexName, start)
+}
+
+func (qe *queryExecutor) observeLatency(operation, table, index string, start time.Time) {
+	if qe == nil || qe.db == nil {
+		return
+	}
+	qe.db.mu.RLock()
+	log := qe.db.slowQueries
+	qe.db.mu.RUnlock()
+	log.ObserveWithTags(operation, table, index, qe.requestTags(), time.Since(start))
+}
@@ -0,0 +1,88 @@
+package dynamorm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/contention"
+)
+
+func TestDebugHandler_ServesStats(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"a1"},"balance":{"N":"10"},"version":{"N":"1"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+	db.SlowQueries().SetThreshold(time.Nanosecond)
+
+	var account testAccount
+	require.NoError(t, db.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+	db.Contention().Record(contention.KindConditionFailed, "UpdateItem", "test_accounts", nil)
+
+	rec := httptest.NewRecorder()
+	db.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/dynamorm", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var stats Stats
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+	require.Len(t, stats.Models, 1)
+	require.Equal(t, "id", stats.Models[0].PartitionKey)
+	require.Equal(t, "off", stats.AccessPatternMode)
+	require.Len(t, stats.HotKeys, 1)
+	require.Equal(t, CircuitStats{State: CircuitClosed}, stats.Circuit)
+	require.NotEmpty(t, stats.SlowQueries)
+	require.Equal(t, "GetItem", stats.SlowQueries[0].Operation)
+	require.Equal(t, stats.Models[0].Table, stats.SlowQueries[0].Table)
+}
+
+func TestDebugHandler_RejectsWrites(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newBareDB().DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/dynamorm", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	require.Equal(t, "GET, HEAD", rec.Header().Get("Allow"))
+}
+
+func TestDebugHandler_HashesHotKeysUnlessExposed(t *testing.T) {
+	db := newBareDB()
+	db.Contention().Record(contention.KindConditionFailed, "UpdateItem", "accounts", &types.AttributeValueMemberS{Value: "CUSTOMER#42"})
+
+	serve := func(opts ...DebugOption) Stats {
+		rec := httptest.NewRecorder()
+		db.DebugHandler(opts...).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/dynamorm", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		var stats Stats
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+		require.Len(t, stats.HotKeys, 1)
+		return stats
+	}
+
+	hashed := serve()
+	require.NotContains(t, hashed.HotKeys[0].KeyPrefix, "CUSTOMER")
+	require.True(t, strings.HasPrefix(hashed.HotKeys[0].KeyPrefix, "sha256:"))
+	require.Equal(t, hashed.HotKeys[0].KeyPrefix, serve().HotKeys[0].KeyPrefix)
+
+	require.Equal(t, "CUSTOMER#42", serve(DebugExposeKeys()).HotKeys[0].KeyPrefix)
+}
+
+func TestStats_ReportsCircuitState(t *testing.T) {
+	db := newBareDB()
+	l := db.lifecycleState()
+	require.NoError(t, l.begin())
+	require.Equal(t, CircuitStats{State: CircuitClosed, InFlight: 1}, db.Stats().Circuit)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Error(t, db.Shutdown(ctx))
+	require.Equal(t, CircuitStats{State: CircuitDraining, InFlight: 1}, db.Stats().Circuit)
+
+	l.end()
+	require.Equal(t, CircuitStats{State: CircuitOpen}, db.Stats().Circuit)
+}
@@ -11,12 +11,18 @@ import (
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
+	"github.com/pay-theory/dynamorm/pkg/accesspattern"
+	"github.com/pay-theory/dynamorm/pkg/contention"
 	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/fieldaccess"
+	"github.com/pay-theory/dynamorm/pkg/leadingkeys"
+	"github.com/pay-theory/dynamorm/pkg/maintenance"
 	"github.com/pay-theory/dynamorm/pkg/marshal"
 	"github.com/pay-theory/dynamorm/pkg/model"
 	queryPkg "github.com/pay-theory/dynamorm/pkg/query"
 	"github.com/pay-theory/dynamorm/pkg/schema"
 	"github.com/pay-theory/dynamorm/pkg/session"
+	"github.com/pay-theory/dynamorm/pkg/slowquery"
 	"github.com/pay-theory/dynamorm/pkg/transaction"
 	pkgTypes "github.com/pay-theory/dynamorm/pkg/types"
 )
@@ -29,9 +35,28 @@ type DB struct {
 	registry            *model.Registry
 	converter           *pkgTypes.Converter
 	marshaler           marshal.MarshalerInterface
+	accessPatterns      *accesspattern.Registry
+	leadingKeys         leadingkeys.Checker
+	middleware          []Middleware
+	requestTags         map[string]string
+	contention          *contention.Tracker
+	fieldAccess         *fieldaccess.Sampler
+	maintenance         *maintenance.Registry
+	slowQueries         *slowquery.Log
+	lifecycle           *lifecycle
+	txTokens            *transaction.TokenCache
+	itemCache           *itemCache
+	strictReads         *strictReads
+	audit               *auditing
+	tenant              *tenancy
+	roles               *roleSessions
+	principal           string
 	metadataCache       sync.Map
 	lambdaTimeoutBuffer time.Duration
 	mu                  sync.RWMutex
+	validateItemSize    bool
+	noIndexSelection    bool
+	unknownPrincipal    bool
 }
 
 // UnmarshalItem unmarshals a DynamoDB AttributeValue map into a Go struct.
@@ -146,11 +171,18 @@ func New(config session.Config) (core.ExtendedDB, error) {
 	}
 
 	return &DB{
-		session:   sess,
-		registry:  model.NewRegistry(),
-		converter: converter,
-		marshaler: marshalerInstance,
-		ctx:       context.Background(),
+		session:        sess,
+		registry:       model.NewRegistry(model.WithNamingStrategy(config.TableNamingStrategy()), model.WithFieldPolicy(config.FieldPolicy)),
+		converter:      converter,
+		marshaler:      marshalerInstance,
+		accessPatterns: accesspattern.NewRegistry(),
+		contention:     contention.NewTracker(),
+		maintenance:    maintenance.NewRegistry(),
+		slowQueries:    slowquery.NewLog(),
+		lifecycle:      newLifecycle(),
+		txTokens:       transaction.NewTokenCache(),
+		roles:          newRoleSessions(sess),
+		ctx:            context.Background(),
 	}, nil
 }
 
@@ -160,6 +192,38 @@ func NewBasic(config session.Config) (core.DB, error) {
 	return New(config)
 }
 
+// SetNumberPolicy controls how stored numbers that do not fit their destination field are
+// unmarshaled. The default, types.NumberBestEffort, wraps integers and rounds floats;
+// types.NumberStrict returns an error wrapping errors.ErrNumberOutOfRange instead, and
+// types.NumberSaturate clamps to the field's range. The policy is shared by every DB
+// derived from the same New call.
+func (db *DB) SetNumberPolicy(policy pkgTypes.NumberPolicy) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.converter.SetNumberPolicy(policy)
+}
+
+// SetTimePolicy controls how time.Time values are stored and compared. The default,
+// types.TimeAsGiven, formats values as RFC3339Nano in their own zone, as earlier releases
+// did; types.TimeUTC converts them to UTC first, so the same instant always has the same
+// stored form. Switching an existing table to TimeUTC needs a backfill of its time
+// attributes (see docs/struct-definition-guide.md). The policy is shared by every DB
+// derived from the same New call.
+func (db *DB) SetTimePolicy(policy pkgTypes.TimePolicy) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.converter.SetTimePolicy(policy)
+}
+
+// Marshaler is implemented by field types that choose their own AttributeValue; see
+// types.Marshaler. Types written for the AWS SDK's attributevalue.Marshaler already
+// implement it.
+type Marshaler = pkgTypes.Marshaler
+
+// Unmarshaler is implemented by field types that decode their own AttributeValue; see
+// types.Unmarshaler.
+type Unmarshaler = pkgTypes.Unmarshaler
+
 // RegisterTypeConverter registers a custom converter for a specific Go type. This allows
 // callers to control how values are marshaled to and unmarshaled from DynamoDB without
 // forking the internal marshaler. Registering a converter clears any cached marshalers
@@ -187,6 +251,39 @@ func (db *DB) RegisterTypeConverter(typ reflect.Type, converter pkgTypes.CustomC
 	return nil
 }
 
+// InvalidateModelCache drops everything the DB has cached about model's type and parses
+// its metadata again, for long-running processes whose model definitions change, such
+// as models loaded from plugins. Queries started afterwards use the new metadata and
+// rebuilt marshalers; queries already running finish with the old. The registry and
+// marshaler are shared with DBs derived from this one, so they see the change too. A
+// model that fails to parse returns the error and keeps its previous metadata.
+func (db *DB) InvalidateModelCache(model any) error {
+	if model == nil {
+		return fmt.Errorf("model cannot be nil")
+	}
+	typ := reflect.TypeOf(model)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.registry.Reparse(model); err != nil {
+		return fmt.Errorf("failed to reparse model %T: %w", model, err)
+	}
+	db.metadataCache.Delete(typ)
+	if db.marshaler != nil {
+		type typeInvalidator interface {
+			InvalidateType(reflect.Type)
+		}
+		if invalidator, ok := db.marshaler.(typeInvalidator); ok && invalidator != nil {
+			invalidator.InvalidateType(typ)
+		}
+	}
+	return nil
+}
+
 // Model returns a new query builder for the given model
 func (db *DB) Model(model any) core.Query {
 	// Ensure model is registered
@@ -236,35 +333,117 @@ func (db *DB) Model(model any) core.Query {
 
 	q := queryPkg.New(model, adapter, executor).
 		WithConverter(db.converter).
-		WithMarshaler(db.marshaler)
+		WithMarshaler(db.marshaler).
+		WithIndexSelection(!db.noIndexSelection)
+	if db.session != nil && db.session.Config() != nil {
+		q.WithBlindIndexKey(db.session.Config().BlindIndexKey)
+	}
 	q.WithContext(ctx)
 	return q
 }
 
-// Transaction executes a function within a database transaction
+// metadataFor registers modelValue if needed and returns its metadata.
+func (db *DB) metadataFor(modelValue any) (*model.Metadata, error) {
+	if err := db.registry.Register(modelValue); err != nil {
+		return nil, fmt.Errorf("failed to register model %T: %w", modelValue, err)
+	}
+	meta, err := db.registry.GetMetadata(modelValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata for model %T: %w", modelValue, err)
+	}
+	return meta, nil
+}
+
+// TableName returns the name of the model's table, with the DB's naming strategy
+// applied.
+func (db *DB) TableName(model any) (string, error) {
+	meta, err := db.metadataFor(model)
+	if err != nil {
+		return "", err
+	}
+	return meta.TableName, nil
+}
+
+// Transaction runs fn with a Tx whose writes are applied immediately, one request per
+// call. Use AtomicTransaction to commit the writes together.
 func (db *DB) Transaction(fn func(tx *core.Tx) error) error {
-	// For now, we'll use a simple wrapper that doesn't support full transaction features
-	// Users should use TransactionFunc for full transaction support
+	l := db.lifecycleState()
+	if err := l.begin(); err != nil {
+		return err
+	}
+	defer l.end()
+
 	tx := &core.Tx{}
-	// Set the db field to avoid nil pointer panic
 	tx.SetDB(db)
 	return fn(tx)
 }
 
+// AtomicTransaction runs fn with a Tx that queues writes across any number of models and
+// commits them in one TransactWriteItems call once fn returns nil. Returning an error from
+// fn discards the queued writes. Each item may be written only once, and reads made through
+// tx.Model are not isolated from the queued writes. Cancellations surface as
+// *errors.TransactionError carrying the per-item reasons.
+func (db *DB) AtomicTransaction(fn func(tx *core.Tx) error) error {
+	l := db.lifecycleState()
+	if err := l.begin(); err != nil {
+		return err
+	}
+	defer l.end()
+
+	tx := core.NewTx(db, db.Transact())
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 // Transact returns a fluent transaction builder for composing TransactWriteItems requests.
 func (db *DB) Transact() core.TransactionBuilder {
 	builder := transaction.NewBuilder(db.session, db.registry, db.converter)
+	builder.WithContentionTracker(db.contentionTracker())
+	builder.WithTokenCache(db.transactionTokens())
+	builder.WithWriteObserver(db.observeTransactWrite)
+	builder.WithTableGuard(db.checkMaintenance)
+	builder.WithItemGuard(db.transactItemGuard())
 	if db.ctx != nil {
 		builder.WithContext(db.ctx)
 	}
 	return builder
 }
 
+// transactItemGuard returns the check of the DB's transaction writes: scoping them to its
+// tenant, or rejecting writes of tenant-scoped models without one, and rejecting those
+// its audit mode cannot record.
+func (db *DB) transactItemGuard() func(item *types.TransactWriteItem, metadata *model.Metadata) error {
+	audit, tenant := db.auditConfig(), db.tenantConfig()
+	return func(item *types.TransactWriteItem, metadata *model.Metadata) error {
+		if err := tenant.scopeTransactItem(item, metadata); err != nil {
+			return err
+		}
+		if audit != nil {
+			return audit.checkTransactItem(item)
+		}
+		return nil
+	}
+}
+
+// transactionTokens returns the cache of committed transaction client request tokens.
+func (db *DB) transactionTokens() *transaction.TokenCache {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.txTokens
+}
+
 // TransactWrite executes the supplied function with a transaction builder and automatically commits it.
 func (db *DB) TransactWrite(ctx context.Context, fn func(core.TransactionBuilder) error) error {
 	if fn == nil {
 		return fmt.Errorf("transaction function cannot be nil")
 	}
+	l := db.lifecycleState()
+	if err := l.begin(); err != nil {
+		return err
+	}
+	defer l.end()
 
 	builder := db.Transact()
 	if ctx != nil {
@@ -372,6 +551,57 @@ func (db *DB) EnsureTable(model any) error {
 	return nil
 }
 
+// SyncIndexes creates the global secondary indexes declared on the model that are missing
+// from its existing table, one at a time, waiting for each to become ACTIVE. See
+// schema.Manager.SyncIndexes for the available options.
+func (db *DB) SyncIndexes(model any, opts ...schema.IndexSyncOption) (*schema.GSIUpdatePlan, error) {
+	if err := db.registry.Register(model); err != nil {
+		return nil, fmt.Errorf("failed to register model %T: %w", model, err)
+	}
+
+	manager := schema.NewManager(db.session, db.registry)
+	return manager.SyncIndexes(model, opts...)
+}
+
+// DiffIndexes reports the global secondary indexes SyncIndexes would create or could
+// delete for the model's table, without changing it. See schema.Manager.DiffIndexes.
+func (db *DB) DiffIndexes(model any) (*schema.GSIUpdatePlan, error) {
+	if err := db.registry.Register(model); err != nil {
+		return nil, fmt.Errorf("failed to register model %T: %w", model, err)
+	}
+
+	manager := schema.NewManager(db.session, db.registry)
+	return manager.DiffIndexes(model)
+}
+
+// CreateBackup creates an on-demand backup of the model's table. See
+// schema.Manager.CreateBackup.
+func (db *DB) CreateBackup(model any, name string, opts ...schema.BackupOption) (*types.BackupDetails, error) {
+	if err := db.registry.Register(model); err != nil {
+		return nil, fmt.Errorf("failed to register model %T: %w", model, err)
+	}
+
+	manager := schema.NewManager(db.session, db.registry)
+	return manager.CreateBackup(model, name, opts...)
+}
+
+// RestoreBackup restores a backup into a new table. See schema.Manager.RestoreBackup.
+func (db *DB) RestoreBackup(backupARN, newTable string, opts ...schema.BackupOption) (*types.TableDescription, error) {
+	manager := schema.NewManager(db.session, db.registry)
+	return manager.RestoreBackup(backupARN, newTable, opts...)
+}
+
+// ExportToS3 exports the model's table to S3 with the native point-in-time export. See
+// schema.Manager.ExportToS3.
+func (db *DB) ExportToS3(model any, bucket, prefix string, opts ...schema.BackupOption) (*types.ExportDescription, error) {
+	if err := db.registry.Register(model); err != nil {
+		return nil, fmt.Errorf("failed to register model %T: %w", model, err)
+	}
+
+	manager := schema.NewManager(db.session, db.registry)
+	return manager.ExportToS3(model, bucket, prefix, opts...)
+}
+
 // DeleteTable deletes the DynamoDB table for the given model
 func (db *DB) DeleteTable(model any) error {
 	if tableName, ok := model.(string); ok {
@@ -422,14 +652,42 @@ func (db *DB) WithContext(ctx context.Context) core.DB {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
+	newDB := db.derive()
+	newDB.ctx = newDB.tenant.context(ctx)
+
+	return newDB
+}
+
+// derive returns a copy of db that shares its session, registries, and settings.
+// Callers must hold db.mu for reading.
+func (db *DB) derive() *DB {
 	newDB := &DB{
 		session:             db.session,
 		registry:            db.registry,
 		converter:           db.converter,
 		marshaler:           db.marshaler,
-		ctx:                 ctx,
+		accessPatterns:      db.accessPatterns,
+		leadingKeys:         db.leadingKeys,
+		middleware:          append([]Middleware(nil), db.middleware...),
+		requestTags:         db.requestTags,
+		contention:          db.contention,
+		fieldAccess:         db.fieldAccess,
+		maintenance:         db.maintenance,
+		slowQueries:         db.slowQueries,
+		lifecycle:           db.lifecycle,
+		txTokens:            db.txTokens,
+		itemCache:           db.itemCache,
+		strictReads:         db.strictReads,
+		audit:               db.audit,
+		tenant:              db.tenant,
+		roles:               db.roles,
+		principal:           db.principal,
+		ctx:                 db.ctx,
 		lambdaDeadline:      db.lambdaDeadline,
 		lambdaTimeoutBuffer: db.lambdaTimeoutBuffer,
+		validateItemSize:    db.validateItemSize,
+		noIndexSelection:    db.noIndexSelection,
+		unknownPrincipal:    db.unknownPrincipal,
 	}
 
 	// Copy metadata cache
@@ -458,22 +716,37 @@ func (db *DB) WithLambdaTimeout(ctx context.Context) core.DB {
 	db.mu.RLock()
 	defer db.mu.RUnlock()
 
-	newDB := &DB{
-		session:             db.session,
-		registry:            db.registry,
-		converter:           db.converter,
-		marshaler:           db.marshaler,
-		ctx:                 ctx,
-		lambdaDeadline:      adjustedDeadline,
-		lambdaTimeoutBuffer: db.lambdaTimeoutBuffer,
-	}
+	newDB := db.derive()
+	newDB.ctx = ctx
+	newDB.lambdaDeadline = adjustedDeadline
 
-	// Copy metadata cache
-	db.metadataCache.Range(func(key, value any) bool {
-		newDB.metadataCache.Store(key, value)
-		return true
-	})
+	return newDB
+}
+
+// WithItemSizeValidation returns a DB that checks writes against DynamoDB's 400KB item
+// limit before sending them, failing with an *errors.ItemSizeError (wrapping
+// ErrItemTooLarge) that lists the largest attributes. PutItem checks the full item.
+// UpdateItem checks the key plus the expression values it sends, because the stored item
+// is not known without reading it.
+func (db *DB) WithItemSizeValidation(enabled bool) core.ExtendedDB {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
 
+	newDB := db.derive()
+	newDB.validateItemSize = enabled
+	return newDB
+}
+
+// WithIndexSelection returns a DB whose queries without Index() choose the table or
+// secondary index whose keys their Where conditions use, or, when disabled, always use
+// the table's own key and scan when its partition key has no equality condition. It is
+// enabled by default.
+func (db *DB) WithIndexSelection(enabled bool) core.ExtendedDB {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	newDB := db.derive()
+	newDB.noIndexSelection = !enabled
 	return newDB
 }
 
@@ -483,21 +756,8 @@ func (db *DB) WithLambdaTimeoutBuffer(buffer time.Duration) core.DB {
 	defer db.mu.RUnlock()
 
 	// Create new instance instead of modifying existing one to avoid race conditions
-	newDB := &DB{
-		session:             db.session,
-		registry:            db.registry,
-		converter:           db.converter,
-		marshaler:           db.marshaler,
-		ctx:                 db.ctx,
-		lambdaDeadline:      db.lambdaDeadline,
-		lambdaTimeoutBuffer: buffer, // Set the new buffer value
-	}
-
-	// Copy metadata cache
-	db.metadataCache.Range(func(key, value any) bool {
-		newDB.metadataCache.Store(key, value)
-		return true
-	})
+	newDB := db.derive()
+	newDB.lambdaTimeoutBuffer = buffer // Set the new buffer value
 
 	return newDB
 }
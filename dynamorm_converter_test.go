@@ -8,6 +8,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/stretchr/testify/require"
 
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
 	"github.com/pay-theory/dynamorm/pkg/marshal"
 	"github.com/pay-theory/dynamorm/pkg/model"
 	pkgTypes "github.com/pay-theory/dynamorm/pkg/types"
@@ -138,3 +139,33 @@ func TestRegisterTypeConverter_ValidatesInput(t *testing.T) {
 	err = db.RegisterTypeConverter(reflect.TypeOf(customContextValue{}), nil)
 	require.Error(t, err)
 }
+
+func TestSetNumberPolicy_StrictRejectsOverflowOnRead(t *testing.T) {
+	type counter struct {
+		ID    string `dynamorm:"pk"`
+		Count int16  `dynamorm:"attr:count"`
+	}
+
+	db := newBareDB()
+	require.NoError(t, db.registry.Register(&counter{}))
+	metadata, err := db.registry.GetMetadata(&counter{})
+	require.NoError(t, err)
+	executor := &queryExecutor{db: db, metadata: metadata}
+	item := map[string]types.AttributeValue{
+		"id":    &types.AttributeValueMemberS{Value: "c-1"},
+		"count": &types.AttributeValueMemberN{Value: "70000"},
+	}
+
+	var wrapped counter
+	require.NoError(t, executor.unmarshalItem(item, &wrapped))
+
+	db.SetNumberPolicy(pkgTypes.NumberStrict)
+	var strict counter
+	err = executor.unmarshalItem(item, &strict)
+	require.ErrorIs(t, err, customerrors.ErrNumberOutOfRange)
+
+	db.SetNumberPolicy(pkgTypes.NumberSaturate)
+	var saturated counter
+	require.NoError(t, executor.unmarshalItem(item, &saturated))
+	require.Equal(t, int16(32767), saturated.Count)
+}
@@ -104,6 +104,7 @@ func TestErrorBatchGetBuilder_FluentMethodsReturnSelf_COV5(t *testing.T) {
 	require.Same(t, b, b.Select("ID"))
 	require.Same(t, b, b.OnProgress(nil))
 	require.Same(t, b, b.OnError(nil))
+	require.Same(t, b, b.OnMissing(nil))
 	require.ErrorIs(t, b.Execute(nil), errBoom)
 }
 
@@ -42,6 +42,14 @@ func TestEncryptedTag_RejectsEncryptedFieldConditions(t *testing.T) {
 		require.ErrorIs(t, err, customerrors.ErrEncryptedFieldNotQueryable)
 	})
 
+	t.Run("Filter nested path", func(t *testing.T) {
+		var out []encryptedTagGatingModel
+		err := db.Model(&encryptedTagGatingModel{}).
+			Filter("Secret.tier", "=", "gold").
+			All(&out)
+		require.ErrorIs(t, err, customerrors.ErrEncryptedFieldNotQueryable)
+	})
+
 	t.Run("WithCondition", func(t *testing.T) {
 		err := db.Model(&encryptedTagGatingModel{
 			PK: "pk1",
@@ -0,0 +1,117 @@
+package dynamorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// QueryEntities reads every item stored under partition key pk and decodes each into
+// the model whose entity type it carries, for single-table designs where models are
+// declared with a blank field tagged `dynamorm:"entity:ORDER"`. Writes of such models
+// store the entity type in the entityType attribute (entity_type for snake_case models),
+// and their queries and scans only return items of their own type.
+//
+// The result holds a pointer to a new model value per item, in sort key order:
+//
+//	items, err := db.QueryEntities("CUSTOMER#42", &Customer{}, &Order{})
+//	for _, item := range items {
+//		switch v := item.(type) {
+//		case *Customer:
+//		case *Order:
+//		}
+//	}
+//
+// Without models, every registered model with an entity type is used; they must all
+// live in one table. The models must share the table and partition key attribute.
+// Items whose entity type matches none of the models are skipped.
+func (db *DB) QueryEntities(pk any, models ...any) ([]any, error) {
+	if pk == nil {
+		return nil, errors.New("query entities partition key cannot be nil")
+	}
+
+	entities, err := db.entityModels(models)
+	if err != nil {
+		return nil, err
+	}
+	anchor := entities[0]
+	byType := make(map[string]*model.Metadata, len(entities))
+	for _, meta := range entities {
+		if meta.EntityType == "" {
+			return nil, fmt.Errorf("model %s has no entity type; declare one with a blank field tagged dynamorm:\"entity:NAME\"", meta.Type.Name())
+		}
+		if meta.TableName != anchor.TableName {
+			return nil, fmt.Errorf("model %s uses table %s, query entities reads %s", meta.Type.Name(), meta.TableName, anchor.TableName)
+		}
+		if meta.PrimaryKey.PartitionKey.DBName != anchor.PrimaryKey.PartitionKey.DBName {
+			return nil, fmt.Errorf("model %s partition key %s does not match %s", meta.Type.Name(), meta.PrimaryKey.PartitionKey.DBName, anchor.PrimaryKey.PartitionKey.DBName)
+		}
+		if meta.EntityAttribute != anchor.EntityAttribute {
+			return nil, fmt.Errorf("model %s stores its entity type in %s, %s in %s", meta.Type.Name(), meta.EntityAttribute, anchor.Type.Name(), anchor.EntityAttribute)
+		}
+		if existing, ok := byType[meta.EntityType]; ok && existing.Type != meta.Type {
+			return nil, fmt.Errorf("models %s and %s share entity type %s", existing.Type.Name(), meta.Type.Name(), meta.EntityType)
+		}
+		byType[meta.EntityType] = meta
+	}
+
+	items, err := db.readPartition(anchor, pk, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entities: %w", err)
+	}
+
+	results := make([]any, 0, len(items))
+	for _, item := range items {
+		meta := byType[entityTypeOf(item, anchor.EntityAttribute)]
+		if meta == nil {
+			continue
+		}
+		qe := &queryExecutor{db: db, metadata: meta, ctx: db.ctx}
+		if err := qe.loadItem(item); err != nil {
+			return nil, err
+		}
+		dest := reflect.New(meta.Type).Interface()
+		if err := qe.unmarshalItem(item, dest); err != nil {
+			return nil, fmt.Errorf("failed to decode %s item: %w", meta.EntityType, err)
+		}
+		results = append(results, dest)
+	}
+	return results, nil
+}
+
+// entityModels returns the metadata of models, or of every registered model with an
+// entity type when models is empty.
+func (db *DB) entityModels(models []any) ([]*model.Metadata, error) {
+	var entities []*model.Metadata
+	if len(models) == 0 {
+		for _, meta := range db.registry.Models() {
+			if meta.EntityType != "" {
+				entities = append(entities, meta)
+			}
+		}
+		if len(entities) == 0 {
+			return nil, errors.New("query entities found no registered models with an entity type")
+		}
+		return entities, nil
+	}
+
+	for _, modelValue := range models {
+		meta, err := db.metadataFor(modelValue)
+		if err != nil {
+			return nil, err
+		}
+		entities = append(entities, meta)
+	}
+	return entities, nil
+}
+
+func entityTypeOf(item map[string]types.AttributeValue, attribute string) string {
+	if value, ok := item[attribute].(*types.AttributeValueMemberS); ok {
+		return value.Value
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package dynamorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+type entityCustomer struct {
+	_    struct{} `dynamorm:"entity:CUSTOMER"`
+	PK   string   `dynamorm:"pk,attr:PK"`
+	SK   string   `dynamorm:"sk,attr:SK"`
+	Name string   `dynamorm:"attr:name"`
+}
+
+func (entityCustomer) TableName() string { return "entity_table" }
+
+type entityOrder struct {
+	_     struct{} `dynamorm:"entity:ORDER"`
+	PK    string   `dynamorm:"pk,attr:PK"`
+	SK    string   `dynamorm:"sk,attr:SK"`
+	Total int      `dynamorm:"attr:total"`
+}
+
+func (entityOrder) TableName() string { return "entity_table" }
+
+func TestEntityType_StampedOnWrites(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.PutItem":            `{}`,
+		"DynamoDB_20120810.TransactWriteItems": `{}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	require.NoError(t, db.Model(&entityOrder{PK: "CUST#1", SK: "ORDER#1", Total: 5}).Create())
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	item := put.Payload["Item"].(map[string]any)
+	require.Equal(t, map[string]any{"S": "ORDER"}, item["entityType"])
+
+	require.NoError(t, db.TransactWrite(context.Background(), func(tx core.TransactionBuilder) error {
+		tx.Put(&entityCustomer{PK: "CUST#1", SK: "PROFILE", Name: "Ada"})
+		return nil
+	}))
+	transact := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.TransactWriteItems")
+	require.NotNil(t, transact)
+	transactPut := transact.Payload["TransactItems"].([]any)[0].(map[string]any)["Put"].(map[string]any)
+	require.Equal(t, map[string]any{"S": "CUSTOMER"}, transactPut["Item"].(map[string]any)["entityType"])
+}
+
+func TestEntityType_FiltersReads(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[],"Count":0,"ScannedCount":0}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var orders []entityOrder
+	require.NoError(t, db.Model(&entityOrder{}).Where("PK", "=", "CUST#1").All(&orders))
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, req)
+	filter, _ := req.Payload["FilterExpression"].(string)
+	require.NotEmpty(t, filter)
+	names := req.Payload["ExpressionAttributeNames"].(map[string]any)
+	values := req.Payload["ExpressionAttributeValues"].(map[string]any)
+	var found bool
+	for placeholder, name := range names {
+		if name == "entityType" {
+			require.Contains(t, filter, placeholder+" = ")
+			found = true
+		}
+	}
+	require.True(t, found, "filter %q does not name entityType", filter)
+	require.Contains(t, values, ":v2")
+	require.Equal(t, map[string]any{"S": "ORDER"}, values[":v2"])
+}
+
+func TestQueryEntities_DecodesEachItemIntoItsModel(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[
+			{"PK":{"S":"CUST#1"},"SK":{"S":"ORDER#1"},"total":{"N":"12"},"entityType":{"S":"ORDER"}},
+			{"PK":{"S":"CUST#1"},"SK":{"S":"ORDER#2"},"total":{"N":"30"},"entityType":{"S":"ORDER"}},
+			{"PK":{"S":"CUST#1"},"SK":{"S":"PROFILE"},"name":{"S":"Ada"},"entityType":{"S":"CUSTOMER"}},
+			{"PK":{"S":"CUST#1"},"SK":{"S":"ZZZ"},"entityType":{"S":"UNKNOWN"}}
+		],"Count":4,"ScannedCount":4}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	items, err := db.QueryEntities("CUST#1", &entityCustomer{}, &entityOrder{})
+	require.NoError(t, err)
+	require.Equal(t, []any{
+		&entityOrder{PK: "CUST#1", SK: "ORDER#1", Total: 12},
+		&entityOrder{PK: "CUST#1", SK: "ORDER#2", Total: 30},
+		&entityCustomer{PK: "CUST#1", SK: "PROFILE", Name: "Ada"},
+	}, items)
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, req)
+	require.Equal(t, "entity_table", req.Payload["TableName"])
+	require.Empty(t, req.Payload["FilterExpression"])
+
+	// The models registered above are used when none are passed.
+	items, err = db.QueryEntities("CUST#1")
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+}
+
+func TestQueryEntities_ValidatesModels(t *testing.T) {
+	db := newBareDB()
+
+	_, err := db.QueryEntities("CUST#1")
+	require.ErrorContains(t, err, "no registered models")
+
+	_, err = db.QueryEntities(nil, &entityOrder{})
+	require.ErrorContains(t, err, "cannot be nil")
+
+	_, err = db.QueryEntities("CUST#1", &entityOrder{}, &collectionCustomer{})
+	require.ErrorContains(t, err, "has no entity type")
+}
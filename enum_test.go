@@ -0,0 +1,70 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+type paymentStatus int
+
+const (
+	paymentPending paymentStatus = iota
+	paymentPaid
+	paymentFailed
+)
+
+type enumPayment struct {
+	ID     string        `dynamorm:"pk,attr:id"`
+	Status paymentStatus `dynamorm:"enum:pending|paid|failed,attr:status"`
+}
+
+func (enumPayment) TableName() string { return "payments" }
+
+func TestEnumWritesNames(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+
+	require.NoError(t, db.Model(&enumPayment{ID: "p1", Status: paymentPaid}).Create())
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	require.Equal(t, map[string]any{"S": "paid"}, put.Payload["Item"].(map[string]any)["status"])
+
+	require.NoError(t, db.Model(&enumPayment{ID: "p1", Status: paymentFailed}).Update("Status"))
+	update := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem")
+	require.NotNil(t, update)
+	require.Equal(t, map[string]any{":v1": map[string]any{"S": "failed"}}, update.Payload["ExpressionAttributeValues"])
+
+	err := db.Model(&enumPayment{ID: "p2", Status: 7}).Create()
+	require.ErrorIs(t, err, customerrors.ErrValidationFailed)
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem"))
+}
+
+func TestEnumReadsNamesAndNumbers(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Scan": `{"Items":[{"id":{"S":"p1"},"status":{"S":"failed"}},{"id":{"S":"p2"},"status":{"N":"1"}}],"Count":2,"ScannedCount":2}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var payments []enumPayment
+	require.NoError(t, db.Model(&enumPayment{}).Where("Status", "IN", []paymentStatus{paymentPaid, paymentFailed}).All(&payments))
+	require.Equal(t, []enumPayment{{ID: "p1", Status: paymentFailed}, {ID: "p2", Status: paymentPaid}}, payments)
+
+	scan := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan")
+	require.NotNil(t, scan)
+	values := scan.Payload["ExpressionAttributeValues"].(map[string]any)
+	require.ElementsMatch(t, []any{map[string]any{"S": "paid"}, map[string]any{"S": "failed"}}, []any{values[":v1"], values[":v2"]})
+}
+
+func TestEnumRejectsUnknownStoredName(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"p1"},"status":{"S":"refunded"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var payment enumPayment
+	err := db.Model(&enumPayment{ID: "p1"}).First(&payment)
+	require.ErrorIs(t, err, customerrors.ErrInvalidEnumValue)
+}
@@ -50,7 +50,10 @@ func init() {
 		}
 
 		var err error
-		db, err = dynamorm.NewMultiAccount(accounts)
+		db, err = dynamorm.NewMultiAccount(accounts,
+			dynamorm.WithAssumeRoleObserver(func(r dynamorm.AssumeRoleResult) {
+				log.Printf("AssumeRole: partner=%s duration=%v ok=%t", r.PartnerID, r.Duration, r.Err == nil)
+			}))
 		if err != nil {
 			log.Fatalf("Failed to initialize DynamORM: %v", err)
 		}
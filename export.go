@@ -0,0 +1,353 @@
+package dynamorm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/cond"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/model"
+	queryPkg "github.com/pay-theory/dynamorm/pkg/query"
+)
+
+// ExportFormat selects how Export encodes items.
+type ExportFormat string
+
+const (
+	// ExportJSONL writes one JSON object per line. Numbers keep their exact DynamoDB
+	// representation and binary values are base64 encoded.
+	ExportJSONL ExportFormat = "jsonl"
+	// ExportCSV writes a header row of attribute names followed by one row per item.
+	// Lists, maps, and sets are written as JSON.
+	ExportCSV ExportFormat = "csv"
+)
+
+// ExportOptions configures Export.
+type ExportOptions struct {
+	// Filter, when set, exports only the items matching the conditions it builds.
+	Filter func(b *cond.Builder)
+	// Format defaults to ExportJSONL.
+	Format ExportFormat
+	// Fields limits the export to the named fields, given as Go field or attribute names,
+	// in the order CSV columns are written. By default JSON Lines exports every attribute
+	// and CSV every model field, in declaration order.
+	Fields []string
+	// MaskOptions configure the masking of fields tagged `dynamorm:"mask:..."`, which
+	// Export always applies.
+	MaskOptions []MaskOption
+	// Parallelism is the number of scan segments read at once. Items from different
+	// segments interleave in the output. Zero or less scans sequentially.
+	Parallelism int
+}
+
+// ExportReport is the result of Export.
+type ExportReport struct {
+	// Items counts the items written and Scanned the items DynamoDB evaluated.
+	Items   int
+	Scanned int
+	Pages   int
+}
+
+// Export scans modelValue's table and streams its items into w, a page at a time, so
+// tables larger than memory can be extracted. Masked fields are scrubbed before they are
+// written. On error the report counts what was written before the failure.
+func (db *DB) Export(modelValue any, w io.Writer, opts ExportOptions) (*ExportReport, error) {
+	if w == nil {
+		return nil, errors.New("export writer cannot be nil")
+	}
+	format := opts.Format
+	if format == "" {
+		format = ExportJSONL
+	}
+	if format != ExportJSONL && format != ExportCSV {
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+
+	meta, err := db.metadataFor(modelValue)
+	if err != nil {
+		return nil, err
+	}
+
+	q := db.Model(modelValue)
+	if opts.Filter != nil {
+		q = q.FilterFunc(opts.Filter)
+	}
+	if len(opts.Fields) > 0 {
+		q = q.Select(opts.Fields...)
+	}
+	var compiled *core.CompiledQuery
+	switch built := q.(type) {
+	case *queryPkg.Query:
+		compiled, err = built.Compile()
+	case *errorQuery:
+		err = built.err
+	default:
+		err = fmt.Errorf("unexpected query type %T", q)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile export scan: %w", err)
+	}
+
+	var maskCfg maskConfig
+	for _, opt := range opts.MaskOptions {
+		if opt != nil {
+			opt(&maskCfg)
+		}
+	}
+
+	out := &exportWriter{w: w, format: format, meta: meta, mask: maskCfg}
+	if format == ExportCSV {
+		out.columns = exportColumns(meta, opts.Fields)
+		if err := out.writeHeader(); err != nil {
+			return nil, err
+		}
+	}
+
+	parent := db.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	qe := &queryExecutor{db: db, metadata: meta, ctx: ctx}
+
+	segments := int32(max(opts.Parallelism, 1))
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for segment := int32(0); segment < segments; segment++ {
+		wg.Add(1)
+		go func(segment int32) {
+			defer wg.Done()
+			if err := out.exportSegment(ctx, qe, compiled, segment, segments); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(segment)
+	}
+	wg.Wait()
+
+	return &out.report, firstErr
+}
+
+// exportWriter encodes pages of raw items into the export's writer. Pages are encoded
+// outside the lock and written whole, so concurrent segments never interleave within a
+// page.
+type exportWriter struct {
+	w       io.Writer
+	meta    *model.Metadata
+	format  ExportFormat
+	columns []string
+	mask    maskConfig
+	report  ExportReport
+	mu      sync.Mutex
+}
+
+// exportSegment scans one segment of compiled to the end, writing each page.
+func (e *exportWriter) exportSegment(ctx context.Context, qe *queryExecutor, compiled *core.CompiledQuery, segment, totalSegments int32) error {
+	var startKey map[string]types.AttributeValue
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		input := *compiled
+		if totalSegments > 1 {
+			input.Segment = &segment
+			input.TotalSegments = &totalSegments
+		}
+		input.ExclusiveStartKey = startKey
+
+		var page []map[string]types.AttributeValue
+		result, err := qe.ExecuteScanWithPagination(&input, &page)
+		if err != nil {
+			return fmt.Errorf("failed to scan %s for export: %w", compiled.TableName, err)
+		}
+		if err := e.writePage(page, int(result.ScannedCount)); err != nil {
+			return err
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		startKey = result.LastEvaluatedKey
+	}
+}
+
+func (e *exportWriter) writeHeader() error {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(e.columns); err != nil {
+		return fmt.Errorf("failed to encode export header: %w", err)
+	}
+	writer.Flush()
+	if _, err := e.w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
+
+func (e *exportWriter) writePage(items []map[string]types.AttributeValue, scanned int) error {
+	var buf bytes.Buffer
+	if err := e.encodePage(&buf, items); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if buf.Len() > 0 {
+		if _, err := e.w.Write(buf.Bytes()); err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+	}
+	e.report.Items += len(items)
+	e.report.Scanned += scanned
+	e.report.Pages++
+	return nil
+}
+
+func (e *exportWriter) encodePage(buf *bytes.Buffer, items []map[string]types.AttributeValue) error {
+	if e.format == ExportCSV {
+		writer := csv.NewWriter(buf)
+		row := make([]string, len(e.columns))
+		for _, item := range items {
+			item = maskItem(e.meta, item, e.mask)
+			for i, column := range e.columns {
+				cell, err := exportCell(item[column])
+				if err != nil {
+					return fmt.Errorf("failed to encode %s for export: %w", column, err)
+				}
+				row[i] = cell
+			}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("failed to encode export row: %w", err)
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	for _, item := range items {
+		item = maskItem(e.meta, item, e.mask)
+		object := make(map[string]any, len(item))
+		for name, value := range item {
+			object[name] = exportValue(value)
+		}
+		if err := encoder.Encode(object); err != nil {
+			return fmt.Errorf("failed to encode export item: %w", err)
+		}
+	}
+	return nil
+}
+
+// exportColumns returns the CSV columns: the attribute names of fields, or of every
+// model field in declaration order. Fields masked with mask:drop are left out.
+func exportColumns(meta *model.Metadata, fields []string) []string {
+	var selected []*model.FieldMetadata
+	if len(fields) == 0 {
+		seen := make(map[*model.FieldMetadata]bool, len(meta.FieldsByDBName))
+		for _, field := range meta.FieldsByDBName {
+			if !seen[field] {
+				seen[field] = true
+				selected = append(selected, field)
+			}
+		}
+		sort.Slice(selected, func(i, j int) bool {
+			return slices.Compare(selected[i].IndexPath, selected[j].IndexPath) < 0
+		})
+	}
+
+	columns := make([]string, 0, max(len(fields), len(selected)))
+	for _, name := range fields {
+		name = resolveAttributeName(meta, name)
+		if field := meta.FieldsByDBName[name]; field != nil && field.Mask() == model.MaskDrop {
+			continue
+		}
+		columns = append(columns, name)
+	}
+	for _, field := range selected {
+		if field.Mask() != model.MaskDrop {
+			columns = append(columns, field.DBName)
+		}
+	}
+	return columns
+}
+
+// exportValue converts an attribute value into the value JSON encodes for it.
+func exportValue(value types.AttributeValue) any {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value
+	case *types.AttributeValueMemberN:
+		return json.Number(v.Value)
+	case *types.AttributeValueMemberBOOL:
+		return v.Value
+	case *types.AttributeValueMemberB:
+		return v.Value
+	case *types.AttributeValueMemberSS:
+		return v.Value
+	case *types.AttributeValueMemberNS:
+		numbers := make([]json.Number, len(v.Value))
+		for i, n := range v.Value {
+			numbers[i] = json.Number(n)
+		}
+		return numbers
+	case *types.AttributeValueMemberBS:
+		return v.Value
+	case *types.AttributeValueMemberL:
+		list := make([]any, len(v.Value))
+		for i, element := range v.Value {
+			list[i] = exportValue(element)
+		}
+		return list
+	case *types.AttributeValueMemberM:
+		object := make(map[string]any, len(v.Value))
+		for name, element := range v.Value {
+			object[name] = exportValue(element)
+		}
+		return object
+	default:
+		return nil
+	}
+}
+
+// exportCell converts an attribute value into a CSV cell. Missing and NULL values are
+// empty cells.
+func exportCell(value types.AttributeValue) (string, error) {
+	switch v := value.(type) {
+	case nil, *types.AttributeValueMemberNULL:
+		return "", nil
+	case *types.AttributeValueMemberS:
+		return v.Value, nil
+	case *types.AttributeValueMemberN:
+		return v.Value, nil
+	case *types.AttributeValueMemberBOOL:
+		return strconv.FormatBool(v.Value), nil
+	case *types.AttributeValueMemberB:
+		return base64.StdEncoding.EncodeToString(v.Value), nil
+	default:
+		data, err := json.Marshal(exportValue(value))
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
@@ -0,0 +1,93 @@
+package dynamorm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/cond"
+)
+
+const (
+	exportFirstPage  = `{"Items":[{"id":{"S":"c1"},"email":{"S":"a@example.com"},"card":{"S":"4111111111111111"},"notes":{"S":"vip"},"tier":{"S":"gold"},"score":{"N":"12345678901234567890.5"}}],"Count":1,"ScannedCount":3,"LastEvaluatedKey":{"id":{"S":"c1"}}}`
+	exportSecondPage = `{"Items":[{"id":{"S":"c2"},"tier":{"S":"silver, \"new\""},"tags":{"SS":["x"]}}],"Count":1,"ScannedCount":1}`
+)
+
+func TestExport_WritesJSONLinesAcrossPages(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.Scan", []stubbedResponse{
+		{body: exportFirstPage},
+		{body: exportSecondPage},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var out bytes.Buffer
+	report, err := db.Export(&maskedCustomer{}, &out, ExportOptions{
+		Filter: func(b *cond.Builder) { b.And(cond.Ne("Tier", "bronze")) },
+	})
+	require.NoError(t, err)
+	require.Equal(t, &ExportReport{Items: 2, Scanned: 4, Pages: 2}, report)
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, `{"card":"************1111","email":"`+maskedEmailHash("a@example.com")+`","id":"c1","score":12345678901234567890.5,"tier":"gold"}`, lines[0])
+	require.Equal(t, `{"id":"c2","tags":["x"],"tier":"silver, \"new\""}`, lines[1])
+
+	reqs := httpClient.Requests()
+	require.Equal(t, 2, countRequestsByTarget(reqs, "DynamoDB_20120810.Scan"))
+	require.NotEmpty(t, reqs[0].Payload["FilterExpression"])
+	require.Nil(t, reqs[0].Payload["Segment"])
+	require.Equal(t, map[string]any{"id": map[string]any{"S": "c1"}}, reqs[1].Payload["ExclusiveStartKey"])
+}
+
+func TestExport_WritesCSVWithSelectedFields(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.Scan", []stubbedResponse{
+		{body: exportFirstPage},
+		{body: exportSecondPage},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var out bytes.Buffer
+	_, err := db.Export(&maskedCustomer{}, &out, ExportOptions{Format: ExportCSV, Fields: []string{"ID", "Tier", "Notes", "Card"}})
+	require.NoError(t, err)
+	require.Equal(t, "id,tier,card\nc1,gold,************1111\nc2,\"silver, \"\"new\"\"\",\n", out.String())
+
+	scan := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan")
+	require.NotEmpty(t, scan.Payload["ProjectionExpression"])
+}
+
+func TestExport_CSVDefaultsToModelFieldsAndScansSegments(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Scan": exportSecondPage,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var out bytes.Buffer
+	report, err := db.Export(&maskedCustomer{}, &out, ExportOptions{Format: ExportCSV, Parallelism: 3})
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Items)
+	require.Equal(t, "id,email,card,tier\n"+strings.Repeat("c2,,,\"silver, \"\"new\"\"\"\n", 3), out.String())
+
+	segments := make(map[float64]bool)
+	for _, req := range httpClient.Requests() {
+		require.Equal(t, float64(3), req.Payload["TotalSegments"])
+		segments[req.Payload["Segment"].(float64)] = true
+	}
+	require.Equal(t, map[float64]bool{0: true, 1: true, 2: true}, segments)
+}
+
+func TestExport_RejectsUnknownFormat(t *testing.T) {
+	db := newStubbedDB(t, newCapturingHTTPClient(nil))
+
+	_, err := db.Export(&maskedCustomer{}, &bytes.Buffer{}, ExportOptions{Format: "xml"})
+	require.ErrorContains(t, err, `unsupported export format "xml"`)
+}
+
+func maskedEmailHash(email string) string {
+	masked := maskHash(&types.AttributeValueMemberS{Value: email}, nil).(*types.AttributeValueMemberS)
+	return masked.Value
+}
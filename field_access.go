@@ -0,0 +1,115 @@
+package dynamorm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/fieldaccess"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// SampleFieldAccess starts recording which attributes reads request from each table and
+// index, for rate of the Query, Scan, and GetItem operations (0.01 samples one in a
+// hundred). A read with a projection, from Select or Profile, requests the attributes it
+// names; one without requests every attribute. Calling it again changes the rate.
+//
+// Sampling runs as middleware, so reads made through transactions and PartiQL are not
+// seen. FieldAccessReport turns the samples into projection suggestions.
+func (db *DB) SampleFieldAccess(rate float64) *fieldaccess.Sampler {
+	db.mu.Lock()
+	sampler := db.fieldAccess
+	if sampler != nil {
+		db.mu.Unlock()
+		sampler.SetRate(rate)
+		return sampler
+	}
+	sampler = fieldaccess.NewSampler(rate)
+	db.fieldAccess = sampler
+	db.mu.Unlock()
+
+	db.Use(func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) error {
+			err := next(ctx, op)
+			if err == nil && op.Input != nil {
+				switch op.Type {
+				case OperationQuery, OperationScan, OperationGetItem:
+					sampler.Record(op.Table, op.Index, projectedAttributes(op.Input.ProjectionExpression, op.Input.ExpressionAttributeNames))
+				}
+			}
+			return err
+		}
+	})
+	return sampler
+}
+
+// FieldAccessReport suggests projection changes for the secondary indexes of registered
+// models from the reads SampleFieldAccess has recorded: INCLUDE with only the attributes
+// read, in place of ALL, for example. Indexes with fewer than minReads sampled reads are
+// left out. It returns nil when sampling was never started.
+func (db *DB) FieldAccessReport(minReads int64) []fieldaccess.Suggestion {
+	db.mu.RLock()
+	sampler := db.fieldAccess
+	db.mu.RUnlock()
+	if sampler == nil {
+		return nil
+	}
+
+	var projections []fieldaccess.Projection
+	seen := make(map[string]bool)
+	for _, meta := range db.registry.Models() {
+		for _, index := range meta.Indexes {
+			key := meta.TableName + "/" + index.Name
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			projections = append(projections, fieldaccess.Projection{
+				Table:            meta.TableName,
+				Index:            index.Name,
+				Type:             types.ProjectionType(index.ProjectionType),
+				Keys:             indexKeyAttributes(meta, index),
+				NonKeyAttributes: index.ProjectedFields,
+			})
+		}
+	}
+	return fieldaccess.Suggest(sampler.Usage(), projections, minReads)
+}
+
+// indexKeyAttributes returns the table and index key attributes, which every index
+// projects.
+func indexKeyAttributes(meta *model.Metadata, index model.IndexSchema) []string {
+	var keys []string
+	for _, field := range []*model.FieldMetadata{meta.PrimaryKey.PartitionKey, meta.PrimaryKey.SortKey, index.PartitionKey, index.SortKey} {
+		if field != nil {
+			keys = append(keys, field.DBName)
+		}
+	}
+	return keys
+}
+
+// projectedAttributes returns the top-level attributes a projection expression names, or
+// nil when there is no projection. Document paths such as #a.#b[0] count as their first
+// attribute.
+func projectedAttributes(projection string, names map[string]string) []string {
+	if strings.TrimSpace(projection) == "" {
+		return nil
+	}
+	attributes := []string{}
+	seen := make(map[string]bool)
+	for _, path := range strings.Split(projection, ",") {
+		name := strings.TrimSpace(path)
+		if i := strings.IndexAny(name, ".["); i >= 0 {
+			name = name[:i]
+		}
+		if resolved, ok := names[name]; ok {
+			name = resolved
+		}
+		if name != "" && !seen[name] {
+			seen[name] = true
+			attributes = append(attributes, name)
+		}
+	}
+	return attributes
+}
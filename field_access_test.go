@@ -0,0 +1,37 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldAccessReportSuggestsInclude(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[],"Count":0}`,
+	})
+	db := newStubbedDB(t, httpClient)
+	require.Nil(t, db.FieldAccessReport(0))
+	db.SampleFieldAccess(1)
+
+	var users []inferredIndexUser
+	for i := 0; i < 3; i++ {
+		require.NoError(t, db.Model(&inferredIndexUser{Tenant: "t1"}).Index("tenant-index").Select("ID", "Email").All(&users))
+	}
+
+	suggestions := db.FieldAccessReport(3)
+	require.Len(t, suggestions, 1)
+	require.Equal(t, "tenant-index", suggestions[0].Index)
+	require.Equal(t, types.ProjectionTypeInclude, suggestions[0].Suggested)
+	require.Equal(t, []string{"email"}, suggestions[0].NonKeyAttributes)
+
+	require.Empty(t, db.FieldAccessReport(4))
+}
+
+func TestProjectedAttributes(t *testing.T) {
+	require.Nil(t, projectedAttributes("", nil))
+	require.Equal(t,
+		[]string{"id", "profile", "tags"},
+		projectedAttributes("#p0, #p1.#p2, tags[0], #p0", map[string]string{"#p0": "id", "#p1": "profile", "#p2": "name"}))
+}
@@ -0,0 +1,68 @@
+package dynamorm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/model"
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+type eventDetails struct {
+	Source string `dynamorm:"attr:source"`
+}
+
+type loggedEvent struct {
+	eventDetails
+	Payload any    `dynamorm:"attr:payload"`
+	ID      string `dynamorm:"pk,attr:id"`
+	note    string
+}
+
+func (loggedEvent) TableName() string { return "logged_events" }
+
+func TestFieldPolicy_AppliesToWritesAndReads(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"e1"},"source":{"S":"api"},"payload":{"M":{"amount":{"N":"42"},"tags":{"L":[{"S":"a"}]}}}}}`,
+	})
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{
+		FieldPolicy: model.FieldPolicy{
+			Resolve: func(_ reflect.Type, field reflect.StructField, kind model.FieldKind) (model.FieldAction, error) {
+				if kind == model.UnexportedField && field.Anonymous {
+					return model.FieldStore, nil
+				}
+				return model.FieldDefault, nil
+			},
+		},
+	})
+
+	event := &loggedEvent{ID: "e1", Payload: map[string]any{"amount": 42}, note: "not stored"}
+	event.Source = "api"
+	require.NoError(t, db.Model(event).Create())
+
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	item := put.Payload["Item"].(map[string]any)
+	require.Equal(t, map[string]any{"S": "api"}, item["source"])
+	require.Equal(t, map[string]any{"M": map[string]any{"amount": map[string]any{"N": "42"}}}, item["payload"])
+	require.NotContains(t, item, "note")
+
+	var loaded loggedEvent
+	require.NoError(t, db.Model(&loggedEvent{}).Where("ID", "=", "e1").First(&loaded))
+	require.Equal(t, "api", loaded.Source)
+	require.Equal(t, map[string]any{"amount": int64(42), "tags": []any{"a"}}, loaded.Payload)
+}
+
+func TestFieldPolicy_RejectedModelFailsBeforeRequests(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{
+		FieldPolicy: model.FieldPolicy{Interfaces: model.FieldReject},
+	})
+
+	err := db.Model(&loggedEvent{ID: "e1"}).Create()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "loggedEvent.Payload")
+	require.Empty(t, httpClient.Requests())
+}
@@ -0,0 +1,481 @@
+package dynamorm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/model"
+	queryPkg "github.com/pay-theory/dynamorm/pkg/query"
+	"github.com/pay-theory/dynamorm/pkg/schema"
+)
+
+// ImportFormat selects how Import parses its input. Every format holds one item per
+// line; blank lines are skipped.
+type ImportFormat string
+
+const (
+	// ImportJSONL reads plain JSON objects, as Export writes them. Values are typed by
+	// the model: strings become binary for []byte fields, and arrays become sets for
+	// fields tagged `set`.
+	ImportJSONL ImportFormat = "jsonl"
+	// ImportDynamoDBJSON reads items in DynamoDB JSON, such as {"id":{"S":"a1"}}.
+	ImportDynamoDBJSON ImportFormat = "dynamodb-json"
+	// ImportS3Export reads the data files of a DynamoDB export to S3 in DYNAMODB_JSON
+	// format, where each line wraps an item as {"Item":{...}}. Export files are gzipped;
+	// wrap the reader with gzip.NewReader.
+	ImportS3Export ImportFormat = "s3-export"
+)
+
+// ImportOptions configures Import.
+type ImportOptions struct {
+	// RetryPolicy bounds retries of throttled batches and unprocessed items. Nil uses
+	// core.DefaultRetryPolicy.
+	RetryPolicy *core.RetryPolicy
+	// Format defaults to ImportJSONL.
+	Format ImportFormat
+	// BatchSize is the number of items per BatchWriteItem call, at most and by default 25.
+	BatchSize int
+	// ItemsPerSecond caps the write rate so an import can leave capacity for live
+	// traffic. Zero means no limit.
+	ItemsPerSecond float64
+	// SkipInvalid records lines that fail to parse or validate in the report and
+	// continues, instead of stopping at the first one.
+	SkipInvalid bool
+}
+
+// ImportReport is the result of Import.
+type ImportReport struct {
+	// Invalid lists the skipped lines when ImportOptions.SkipInvalid is set.
+	Invalid []*ImportLineError
+	// Lines counts the non-blank lines read and Items the items written.
+	Lines int
+	Items int
+}
+
+// ImportLineError reports an input line Import could not parse or validate.
+type ImportLineError struct {
+	Err  error
+	Line int
+}
+
+func (e *ImportLineError) Error() string {
+	return fmt.Sprintf("import line %d: %v", e.Line, e.Err)
+}
+
+func (e *ImportLineError) Unwrap() error {
+	return e.Err
+}
+
+// Import reads items for modelValue's table from r and writes them with BatchWriteItem,
+// replacing any existing items with the same keys. Each item must carry the model's
+// primary key and unmarshal into the model; other attributes are kept as they are, and
+// encrypted fields are encrypted before they are written. Items are written in batches as
+// they are read, so an error can leave earlier items written; the report counts them.
+//
+// To load an S3 export into a table that does not exist yet, ImportTable is cheaper.
+func (db *DB) Import(modelValue any, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	if r == nil {
+		return nil, errors.New("import reader cannot be nil")
+	}
+	format := opts.Format
+	if format == "" {
+		format = ImportJSONL
+	}
+	if format != ImportJSONL && format != ImportDynamoDBJSON && format != ImportS3Export {
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+
+	meta, err := db.metadataFor(modelValue)
+	if err != nil {
+		return nil, err
+	}
+	if meta.PrimaryKey == nil || meta.PrimaryKey.PartitionKey == nil {
+		return nil, fmt.Errorf("model %T has no primary key", modelValue)
+	}
+
+	policy := opts.RetryPolicy
+	if policy == nil {
+		policy = core.DefaultRetryPolicy()
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 || batchSize > 25 {
+		batchSize = 25
+	}
+	ctx := db.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	w := &importWriter{
+		qe:             &queryExecutor{db: db, metadata: meta, ctx: ctx},
+		meta:           meta,
+		policy:         policy,
+		ctx:            ctx,
+		start:          time.Now(),
+		itemsPerSecond: opts.ItemsPerSecond,
+		keys:           make(map[string]bool, batchSize),
+	}
+	report := &ImportReport{}
+	reader := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		data, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return report, fmt.Errorf("failed to read import line %d: %w", line, readErr)
+		}
+
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			report.Lines++
+			item, key, err := parseImportItem(w.qe, meta, format, data)
+			if err != nil {
+				lineErr := &ImportLineError{Line: line, Err: err}
+				if !opts.SkipInvalid {
+					return report, lineErr
+				}
+				report.Invalid = append(report.Invalid, lineErr)
+			} else {
+				if w.keys[key] || len(w.batch) == batchSize {
+					if err := w.flush(report); err != nil {
+						return report, err
+					}
+				}
+				w.batch = append(w.batch, item)
+				w.keys[key] = true
+			}
+		}
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	if err := w.flush(report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// ImportTable starts a native DynamoDB import of an S3 export into a new table for
+// model. See schema.Manager.ImportTable.
+func (db *DB) ImportTable(model any, source schema.ImportSource, opts ...schema.TableOption) (*types.ImportTableDescription, error) {
+	if err := db.registry.Register(model); err != nil {
+		return nil, fmt.Errorf("failed to register model %T: %w", model, err)
+	}
+
+	manager := schema.NewManager(db.session, db.registry)
+	return manager.ImportTable(model, source, opts...)
+}
+
+// parseImportItem parses one input line into an item, validates it against meta, and
+// returns it with its primary key encoded for duplicate detection.
+func parseImportItem(qe *queryExecutor, meta *model.Metadata, format ImportFormat, data []byte) (map[string]types.AttributeValue, string, error) {
+	var (
+		item map[string]types.AttributeValue
+		err  error
+	)
+	switch format {
+	case ImportDynamoDBJSON:
+		item, err = queryPkg.UnmarshalItemJSON(data)
+	case ImportS3Export:
+		var wrapped struct {
+			Item json.RawMessage
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, "", fmt.Errorf("failed to unmarshal item: %w", err)
+		}
+		if len(wrapped.Item) == 0 {
+			return nil, "", errors.New(`line has no "Item"`)
+		}
+		item, err = queryPkg.UnmarshalItemJSON(wrapped.Item)
+	default:
+		item, err = importJSONItem(meta, data)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := make(map[string]types.AttributeValue, 2)
+	for _, field := range []*model.FieldMetadata{meta.PrimaryKey.PartitionKey, meta.PrimaryKey.SortKey} {
+		if field == nil {
+			continue
+		}
+		value, ok := item[field.DBName]
+		if !ok {
+			return nil, "", fmt.Errorf("item is missing key attribute %s", field.DBName)
+		}
+		key[field.DBName] = value
+	}
+	if err := qe.unmarshalItem(item, reflect.New(meta.Type).Interface()); err != nil {
+		return nil, "", fmt.Errorf("item does not match %s: %w", meta.Type.Name(), err)
+	}
+	if err := meta.ApplyEnums(item); err != nil {
+		return nil, "", err
+	}
+	if meta.EntityType != "" {
+		item[meta.EntityAttribute] = &types.AttributeValueMemberS{Value: meta.EntityType}
+	}
+
+	encodedKey, err := queryPkg.MarshalItemJSON(key)
+	if err != nil {
+		return nil, "", err
+	}
+	return item, string(encodedKey), nil
+}
+
+// importJSONItem converts a plain JSON object into an item, typing each value by the
+// model field it belongs to.
+func importJSONItem(meta *model.Metadata, data []byte) (map[string]types.AttributeValue, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var object map[string]any
+	if err := decoder.Decode(&object); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal item: %w", err)
+	}
+
+	item := make(map[string]types.AttributeValue, len(object))
+	for name, value := range object {
+		av, err := importValue(meta.FieldsByDBName[name], value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert attribute %s: %w", name, err)
+		}
+		if av != nil {
+			item[name] = av
+		}
+	}
+	return item, nil
+}
+
+// importValue converts a decoded JSON value into an attribute value. field, when set,
+// decides between string and binary and between list and set.
+func importValue(field *model.FieldMetadata, value any) (types.AttributeValue, error) {
+	var fieldType reflect.Type
+	if field != nil {
+		fieldType = field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	case bool:
+		return &types.AttributeValueMemberBOOL{Value: v}, nil
+	case json.Number:
+		return &types.AttributeValueMemberN{Value: v.String()}, nil
+	case string:
+		if fieldType != nil && isByteSlice(fieldType) {
+			decoded, err := base64.StdEncoding.DecodeString(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid base64 binary value: %w", err)
+			}
+			return &types.AttributeValueMemberB{Value: decoded}, nil
+		}
+		return &types.AttributeValueMemberS{Value: v}, nil
+	case []any:
+		if field != nil && field.IsSet {
+			return importSet(fieldType, v)
+		}
+		list := make([]types.AttributeValue, len(v))
+		for i, element := range v {
+			av, err := importValue(nil, element)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = av
+		}
+		return &types.AttributeValueMemberL{Value: list}, nil
+	case map[string]any:
+		m := make(map[string]types.AttributeValue, len(v))
+		for name, element := range v {
+			av, err := importValue(nil, element)
+			if err != nil {
+				return nil, err
+			}
+			m[name] = av
+		}
+		return &types.AttributeValueMemberM{Value: m}, nil
+	default:
+		return nil, fmt.Errorf("unsupported JSON value %T", value)
+	}
+}
+
+// importSet converts a JSON array for a set field of setType. DynamoDB has no empty
+// sets, so an empty array converts to nil and the attribute is left out.
+func importSet(setType reflect.Type, values []any) (types.AttributeValue, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	var element reflect.Type
+	if setType.Kind() == reflect.Slice || setType.Kind() == reflect.Array {
+		element = setType.Elem()
+	}
+	switch {
+	case element != nil && isByteSlice(element):
+		set := make([][]byte, len(values))
+		for i, value := range values {
+			s, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("binary set element is %T, not a base64 string", value)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid base64 binary value: %w", err)
+			}
+			set[i] = decoded
+		}
+		return &types.AttributeValueMemberBS{Value: set}, nil
+	case element != nil && element.Kind() == reflect.String:
+		set := make([]string, len(values))
+		for i, value := range values {
+			s, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("string set element is %T", value)
+			}
+			set[i] = s
+		}
+		return &types.AttributeValueMemberSS{Value: set}, nil
+	default:
+		set := make([]string, len(values))
+		for i, value := range values {
+			n, ok := value.(json.Number)
+			if !ok {
+				return nil, fmt.Errorf("number set element is %T", value)
+			}
+			set[i] = n.String()
+		}
+		return &types.AttributeValueMemberNS{Value: set}, nil
+	}
+}
+
+func isByteSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// importWriter batches validated items into BatchWriteItem calls, retrying throttled
+// batches and unprocessed items and pacing writes to the import's rate limit.
+type importWriter struct {
+	ctx            context.Context
+	start          time.Time
+	qe             *queryExecutor
+	meta           *model.Metadata
+	policy         *core.RetryPolicy
+	keys           map[string]bool
+	batch          []map[string]types.AttributeValue
+	itemsPerSecond float64
+	written        int
+}
+
+func (w *importWriter) flush(report *ImportReport) error {
+	if len(w.batch) == 0 {
+		return nil
+	}
+	if err := w.pace(); err != nil {
+		return err
+	}
+
+	pending := w.batch
+	for attempt := 0; ; attempt++ {
+		// The executor encrypts items in place, so every attempt sends fresh copies of
+		// the plaintext items.
+		requests := make([]types.WriteRequest, len(pending))
+		for i, item := range pending {
+			requests[i] = types.WriteRequest{PutRequest: &types.PutRequest{Item: maps.Clone(item)}}
+		}
+
+		result, err := w.qe.ExecuteBatchWriteItem(w.meta.TableName, requests)
+		if err != nil && !isThrottle(err) {
+			return fmt.Errorf("failed to import items: %w", err)
+		}
+
+		var unprocessed []map[string]types.AttributeValue
+		if err != nil {
+			unprocessed = pending
+		} else if result != nil {
+			unprocessed = w.unprocessed(pending, result.UnprocessedItems[w.meta.TableName])
+		}
+		report.Items += len(pending) - len(unprocessed)
+		w.written += len(pending) - len(unprocessed)
+		if len(unprocessed) == 0 {
+			break
+		}
+		if attempt >= w.policy.MaxRetries {
+			return fmt.Errorf("%d items still unprocessed after %d attempts", len(unprocessed), attempt+1)
+		}
+
+		if err := w.sleep(calculateBatchRetryDelay(w.policy, attempt)); err != nil {
+			return err
+		}
+		pending = unprocessed
+	}
+
+	w.batch = w.batch[:0]
+	clear(w.keys)
+	return nil
+}
+
+// unprocessed matches the unprocessed writes DynamoDB returned back to the plaintext
+// items in pending by primary key.
+func (w *importWriter) unprocessed(pending []map[string]types.AttributeValue, writes []types.WriteRequest) []map[string]types.AttributeValue {
+	if len(writes) == 0 {
+		return nil
+	}
+	left := make(map[string]bool, len(writes))
+	for _, write := range writes {
+		if write.PutRequest != nil {
+			left[w.itemKey(write.PutRequest.Item)] = true
+		}
+	}
+	var out []map[string]types.AttributeValue
+	for _, item := range pending {
+		if left[w.itemKey(item)] {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func (w *importWriter) itemKey(item map[string]types.AttributeValue) string {
+	key := map[string]types.AttributeValue{}
+	for _, field := range []*model.FieldMetadata{w.meta.PrimaryKey.PartitionKey, w.meta.PrimaryKey.SortKey} {
+		if field != nil {
+			key[field.DBName] = item[field.DBName]
+		}
+	}
+	encoded, _ := queryPkg.MarshalItemJSON(key)
+	return string(encoded)
+}
+
+// pace waits until the items written so far are within the import's rate limit.
+func (w *importWriter) pace() error {
+	if w.itemsPerSecond <= 0 {
+		return nil
+	}
+	due := time.Duration(float64(w.written) / w.itemsPerSecond * float64(time.Second))
+	return w.sleep(due - time.Since(w.start))
+}
+
+func (w *importWriter) sleep(delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-w.ctx.Done():
+		return w.ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
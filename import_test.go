@@ -0,0 +1,128 @@
+package dynamorm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+type importRecord struct {
+	ID      string   `dynamorm:"pk,attr:id"`
+	Note    string   `dynamorm:"attr:note"`
+	Payload []byte   `dynamorm:"attr:payload"`
+	Tags    []string `dynamorm:"set,attr:tags"`
+	Count   int      `dynamorm:"attr:count"`
+}
+
+func (importRecord) TableName() string { return "import_records" }
+
+func importedItems(t *testing.T, req capturedRequest) []any {
+	t.Helper()
+	items := req.Payload["RequestItems"].(map[string]any)["import_records"].([]any)
+	out := make([]any, len(items))
+	for i, write := range items {
+		out[i] = write.(map[string]any)["PutRequest"].(map[string]any)["Item"]
+	}
+	return out
+}
+
+func TestImport_JSONLinesTypesValuesByModel(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.BatchWriteItem": `{"UnprocessedItems":{}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	input := strings.Join([]string{
+		`{"id":"a1","count":42,"payload":"aGk=","tags":["x","y"],"legacy":{"n":1.5,"ok":true,"gone":null}}`,
+		``,
+		`{"id":"a2","note":"second"}`,
+		`{"id":"a3","tags":[]}`,
+	}, "\n")
+	report, err := db.Import(&importRecord{}, strings.NewReader(input), ImportOptions{BatchSize: 2})
+	require.NoError(t, err)
+	require.Equal(t, &ImportReport{Lines: 3, Items: 3}, report)
+
+	var writes []capturedRequest
+	for _, req := range httpClient.Requests() {
+		if req.Target == "DynamoDB_20120810.BatchWriteItem" {
+			writes = append(writes, req)
+		}
+	}
+	require.Len(t, writes, 2)
+	first := importedItems(t, writes[0])
+	require.Len(t, first, 2)
+	require.Equal(t, map[string]any{
+		"id":      map[string]any{"S": "a1"},
+		"count":   map[string]any{"N": "42"},
+		"payload": map[string]any{"B": "aGk="},
+		"tags":    map[string]any{"SS": []any{"x", "y"}},
+		"legacy": map[string]any{"M": map[string]any{
+			"n":    map[string]any{"N": "1.5"},
+			"ok":   map[string]any{"BOOL": true},
+			"gone": map[string]any{"NULL": true},
+		}},
+	}, first[0])
+	require.Equal(t, []any{map[string]any{"id": map[string]any{"S": "a3"}}}, importedItems(t, writes[1]), "empty sets are left out")
+}
+
+func TestImport_RetriesUnprocessedItems(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.BatchWriteItem", []stubbedResponse{
+		{body: `{"UnprocessedItems":{"import_records":[{"PutRequest":{"Item":{"id":{"S":"a2"},"note":{"S":"n"}}}}]}}`},
+		{body: `{"UnprocessedItems":{}}`},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	input := `{"Item":{"id":{"S":"a1"}}}` + "\n" + `{"Item":{"id":{"S":"a2"},"note":{"S":"n"}}}`
+	report, err := db.Import(&importRecord{}, strings.NewReader(input), ImportOptions{
+		Format:      ImportS3Export,
+		RetryPolicy: &core.RetryPolicy{MaxRetries: 1, InitialDelay: 1},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, report.Items)
+
+	reqs := httpClient.Requests()
+	require.Equal(t, 2, countRequestsByTarget(reqs, "DynamoDB_20120810.BatchWriteItem"))
+	retry := findRequestByTarget(reqs, "DynamoDB_20120810.BatchWriteItem")
+	require.Equal(t, []any{map[string]any{"id": map[string]any{"S": "a2"}, "note": map[string]any{"S": "n"}}}, importedItems(t, *retry))
+}
+
+func TestImport_ValidatesLines(t *testing.T) {
+	input := strings.Join([]string{
+		`{"id":{"S":"a1"}}`,
+		`{"count":{"N":"2"}}`,
+		`{"id":{"S":"a3"},"count":{"S":"many"}}`,
+		`not json`,
+	}, "\n")
+
+	t.Run("stops at the first invalid line", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(nil)
+		db := newStubbedDB(t, httpClient)
+
+		_, err := db.Import(&importRecord{}, strings.NewReader(input), ImportOptions{Format: ImportDynamoDBJSON})
+		var lineErr *ImportLineError
+		require.True(t, errors.As(err, &lineErr))
+		require.Equal(t, 2, lineErr.Line)
+		require.ErrorContains(t, err, "missing key attribute id")
+		require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.BatchWriteItem"))
+	})
+
+	t.Run("skips invalid lines when asked", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(map[string]string{
+			"DynamoDB_20120810.BatchWriteItem": `{"UnprocessedItems":{}}`,
+		})
+		db := newStubbedDB(t, httpClient)
+
+		report, err := db.Import(&importRecord{}, strings.NewReader(input), ImportOptions{Format: ImportDynamoDBJSON, SkipInvalid: true})
+		require.NoError(t, err)
+		require.Equal(t, 4, report.Lines)
+		require.Equal(t, 1, report.Items)
+		require.Len(t, report.Invalid, 3)
+		require.Equal(t, []int{2, 3, 4}, []int{report.Invalid[0].Line, report.Invalid[1].Line, report.Invalid[2].Line})
+		require.ErrorContains(t, report.Invalid[1], "does not match importRecord")
+	})
+}
@@ -0,0 +1,54 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type inferredIndexUser struct {
+	ID     string `dynamorm:"pk,attr:id"`
+	Email  string `dynamorm:"index:email-index,pk,attr:email"`
+	Tenant string `dynamorm:"index:tenant-index,pk,attr:tenant"`
+	Joined int64  `dynamorm:"index:tenant-index,sk,attr:joined"`
+}
+
+func (inferredIndexUser) TableName() string { return "inferred_index_users" }
+
+func TestIndexInfersKeyConditionsFromModel(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[{"id":{"S":"u1"},"email":{"S":"a@example.com"}}],"Count":1}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var user inferredIndexUser
+	require.NoError(t, db.Model(&inferredIndexUser{Email: "a@example.com"}).Index("email-index").First(&user))
+	require.Equal(t, "u1", user.ID)
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, req)
+	require.Equal(t, "email-index", req.Payload["IndexName"])
+	require.Equal(t, "#n1 = :v1", req.Payload["KeyConditionExpression"])
+	require.Equal(t, map[string]any{"#n1": "email"}, req.Payload["ExpressionAttributeNames"])
+	require.Equal(t, map[string]any{":v1": map[string]any{"S": "a@example.com"}}, req.Payload["ExpressionAttributeValues"])
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan"))
+}
+
+func TestIndexInfersSortKeyOnlyWithoutCondition(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[],"Count":0}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var users []inferredIndexUser
+	require.NoError(t, db.Model(&inferredIndexUser{Tenant: "t1", Joined: 100}).
+		Index("tenant-index").
+		Where("Joined", ">=", 50).
+		All(&users))
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, req)
+	require.Equal(t, "tenant-index", req.Payload["IndexName"])
+	require.Equal(t, "#n1 >= :v1 AND #n2 = :v2", req.Payload["KeyConditionExpression"])
+	require.Equal(t, map[string]any{"#n1": "joined", "#n2": "tenant"}, req.Payload["ExpressionAttributeNames"])
+}
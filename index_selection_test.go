@@ -0,0 +1,40 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuerySelectsIndexFromAnyCondition(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+
+	var users []inferredIndexUser
+	require.NoError(t, db.Model(&inferredIndexUser{}).
+		Where("Joined", ">", 5).
+		Where("Tenant", "=", "t1").
+		Where("Email", "<>", "a@example.com").
+		All(&users))
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, req)
+	require.Equal(t, "tenant-index", req.Payload["IndexName"])
+	require.Equal(t, "#n1 > :v1 AND #n2 = :v2", req.Payload["KeyConditionExpression"])
+	require.Equal(t, "#n3 <> :v3", req.Payload["FilterExpression"])
+}
+
+func TestWithIndexSelectionDisabledScans(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := mustDB(t, newStubbedDB(t, httpClient).WithIndexSelection(false))
+
+	var users []inferredIndexUser
+	require.NoError(t, db.Model(&inferredIndexUser{}).Where("Tenant", "=", "t1").All(&users))
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Query"))
+	require.NotNil(t, findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan"))
+	require.False(t, db.Stats().IndexSelection)
+
+	// The table's own key is still used.
+	require.NoError(t, db.Model(&inferredIndexUser{}).Where("ID", "=", "u1").All(&users))
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Query"))
+}
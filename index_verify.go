@@ -0,0 +1,286 @@
+package dynamorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// defaultVerifyMaxItems caps how many index items VerifyIndex scans by default.
+const defaultVerifyMaxItems = 1000
+
+type indexVerifyOptions struct {
+	attributes []string
+	sampleRate float64
+	maxItems   int
+	repair     bool
+}
+
+// IndexVerifyOption configures VerifyIndex.
+type IndexVerifyOption func(*indexVerifyOptions)
+
+// WithVerifySampleRate checks only the given fraction (0 < rate <= 1) of the scanned
+// index items. The default checks every item.
+func WithVerifySampleRate(rate float64) IndexVerifyOption {
+	return func(o *indexVerifyOptions) {
+		if rate > 0 && rate <= 1 {
+			o.sampleRate = rate
+		}
+	}
+}
+
+// WithVerifyAttributes compares only the named fields, given as Go field or attribute
+// names. By default every non-key attribute projected into the index is compared.
+func WithVerifyAttributes(fields ...string) IndexVerifyOption {
+	return func(o *indexVerifyOptions) {
+		o.attributes = append(o.attributes, fields...)
+	}
+}
+
+// WithVerifyMaxItems caps how many index items are scanned (1000 by default).
+func WithVerifyMaxItems(n int) IndexVerifyOption {
+	return func(o *indexVerifyOptions) {
+		if n > 0 {
+			o.maxItems = n
+		}
+	}
+}
+
+// WithVerifyRepair rewrites the diverged attributes on the base item with their current
+// values, so DynamoDB propagates them to the index again. Index items whose base item no
+// longer exists are reported but never repaired.
+func WithVerifyRepair(enabled bool) IndexVerifyOption {
+	return func(o *indexVerifyOptions) {
+		o.repair = enabled
+	}
+}
+
+// IndexVerifyReport is the result of VerifyIndex.
+type IndexVerifyReport struct {
+	Table       string
+	Index       string
+	Divergences []IndexDivergence
+	Scanned     int
+	Checked     int
+	Repaired    int
+}
+
+// IndexDivergence describes one index item that disagrees with its base item.
+type IndexDivergence struct {
+	// Key is the base table primary key of the item.
+	Key map[string]types.AttributeValue
+	// Attributes lists the attribute names whose index and base values differ.
+	Attributes []string
+	// Missing is set when the base item no longer exists.
+	Missing  bool
+	Repaired bool
+}
+
+// VerifyIndex scans a global secondary index of model, reads the base item behind each
+// (optionally sampled) index item with a strongly consistent GetItem, and reports items
+// whose projected attributes differ from the base table, as past partial failures can
+// leave behind. With WithVerifyRepair, diverged attributes are rewritten on the base item.
+// Every checked item costs a base table read, so bound large indexes with
+// WithVerifyMaxItems or WithVerifySampleRate.
+func (db *DB) VerifyIndex(modelValue any, indexName string, opts ...IndexVerifyOption) (*IndexVerifyReport, error) {
+	options := &indexVerifyOptions{sampleRate: 1, maxItems: defaultVerifyMaxItems}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+
+	meta, err := db.metadataFor(modelValue)
+	if err != nil {
+		return nil, err
+	}
+	if meta.PrimaryKey == nil || meta.PrimaryKey.PartitionKey == nil {
+		return nil, fmt.Errorf("model %T has no primary key", modelValue)
+	}
+	if !hasGlobalIndex(meta, indexName) {
+		return nil, fmt.Errorf("model %T has no global secondary index %q", modelValue, indexName)
+	}
+
+	compare := make([]string, 0, len(options.attributes))
+	for _, name := range options.attributes {
+		compare = append(compare, resolveAttributeName(meta, name))
+	}
+
+	l := db.lifecycleState()
+	if err := l.begin(); err != nil {
+		return nil, err
+	}
+	defer l.end()
+
+	client, err := db.session.Client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client for index verification: %w", err)
+	}
+	ctx := db.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	report := &IndexVerifyReport{Table: meta.TableName, Index: indexName}
+	var startKey map[string]types.AttributeValue
+	for report.Scanned < options.maxItems {
+		output, err := client.Scan(ctx, &dynamodb.ScanInput{
+			TableName:         aws.String(meta.TableName),
+			IndexName:         aws.String(indexName),
+			Limit:             aws.Int32(int32(options.maxItems - report.Scanned)),
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return report, fmt.Errorf("failed to scan index %s: %w", indexName, err)
+		}
+
+		for _, item := range output.Items {
+			if report.Scanned >= options.maxItems {
+				break
+			}
+			report.Scanned++
+			if options.sampleRate < 1 && rand.Float64() >= options.sampleRate {
+				continue
+			}
+			report.Checked++
+
+			divergence, err := verifyIndexItem(ctx, client, meta, item, compare, options.repair)
+			if err != nil {
+				return report, err
+			}
+			if divergence == nil {
+				continue
+			}
+			if divergence.Repaired {
+				report.Repaired++
+			}
+			report.Divergences = append(report.Divergences, *divergence)
+		}
+
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = output.LastEvaluatedKey
+	}
+
+	return report, nil
+}
+
+func hasGlobalIndex(meta *model.Metadata, indexName string) bool {
+	for _, index := range meta.Indexes {
+		if index.Name == indexName && index.Type == model.GlobalSecondaryIndex {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyIndexItem compares one index item with its base item and returns nil when they agree.
+func verifyIndexItem(ctx context.Context, client *dynamodb.Client, meta *model.Metadata, item map[string]types.AttributeValue, compare []string, repair bool) (*IndexDivergence, error) {
+	key := make(map[string]types.AttributeValue, 2)
+	for _, field := range []*model.FieldMetadata{meta.PrimaryKey.PartitionKey, meta.PrimaryKey.SortKey} {
+		if field == nil {
+			continue
+		}
+		value, ok := item[field.DBName]
+		if !ok {
+			return nil, fmt.Errorf("index item is missing base key attribute %s", field.DBName)
+		}
+		key[field.DBName] = value
+	}
+
+	output, err := client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(meta.TableName),
+		Key:            key,
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read base item from %s: %w", meta.TableName, err)
+	}
+	if len(output.Item) == 0 {
+		return &IndexDivergence{Key: key, Missing: true}, nil
+	}
+
+	attributes := compare
+	if len(attributes) == 0 {
+		for name := range item {
+			if _, isKey := key[name]; !isKey {
+				attributes = append(attributes, name)
+			}
+		}
+		sort.Strings(attributes)
+	}
+
+	var diverged []string
+	for _, name := range attributes {
+		indexValue, inIndex := item[name]
+		baseValue, inBase := output.Item[name]
+		if inIndex != inBase || !reflect.DeepEqual(indexValue, baseValue) {
+			diverged = append(diverged, name)
+		}
+	}
+	if len(diverged) == 0 {
+		return nil, nil
+	}
+
+	divergence := &IndexDivergence{Key: key, Attributes: diverged}
+	if repair {
+		repaired, err := repairBaseItem(ctx, client, meta, key, output.Item, diverged)
+		if err != nil {
+			return nil, err
+		}
+		divergence.Repaired = repaired
+	}
+	return divergence, nil
+}
+
+// repairBaseItem rewrites the named attributes of the base item with their current values.
+// Attributes absent from the base item cannot be rewritten and are skipped.
+func repairBaseItem(ctx context.Context, client *dynamodb.Client, meta *model.Metadata, key, base map[string]types.AttributeValue, attributes []string) (bool, error) {
+	names := make(map[string]string)
+	values := make(map[string]types.AttributeValue)
+	expression := ""
+	for _, name := range attributes {
+		value, ok := base[name]
+		if !ok {
+			continue
+		}
+		placeholder := fmt.Sprintf("%d", len(names)+1)
+		names["#r"+placeholder] = name
+		values[":r"+placeholder] = value
+		if expression != "" {
+			expression += ", "
+		}
+		expression += "#r" + placeholder + " = :r" + placeholder
+	}
+	if expression == "" {
+		return false, nil
+	}
+
+	names["#pk"] = meta.PrimaryKey.PartitionKey.DBName
+	_, err := client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(meta.TableName),
+		Key:                       key,
+		UpdateExpression:          aws.String("SET " + expression),
+		ConditionExpression:       aws.String("attribute_exists(#pk)"),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	})
+	if err != nil {
+		var conditionFailed *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to repair base item in %s: %w", meta.TableName, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,72 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+type verifyOrder struct {
+	ID       string `dynamorm:"pk,attr:id"`
+	Customer string `dynamorm:"index:gsi-customer,pk,attr:customer"`
+	State    string `dynamorm:"attr:state"`
+}
+
+func TestVerifyIndex_ReportsAndRepairsDivergence(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Scan": `{"Items":[` +
+			`{"id":{"S":"o1"},"customer":{"S":"c1"},"state":{"S":"open"}},` +
+			`{"id":{"S":"o2"},"customer":{"S":"c1"},"state":{"S":"open"}},` +
+			`{"id":{"S":"o3"},"customer":{"S":"c1"},"state":{"S":"open"}}]}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.GetItem", []stubbedResponse{
+		{body: `{"Item":{"id":{"S":"o1"},"customer":{"S":"c1"},"state":{"S":"open"}}}`},
+		{body: `{"Item":{"id":{"S":"o2"},"customer":{"S":"c1"},"state":{"S":"closed"}}}`},
+		{body: `{}`},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	report, err := db.VerifyIndex(&verifyOrder{}, "gsi-customer", WithVerifyRepair(true))
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Scanned)
+	require.Equal(t, 3, report.Checked)
+	require.Equal(t, 1, report.Repaired)
+	require.Len(t, report.Divergences, 2)
+
+	require.Equal(t, []string{"state"}, report.Divergences[0].Attributes)
+	require.True(t, report.Divergences[0].Repaired)
+	require.Equal(t, &types.AttributeValueMemberS{Value: "o2"}, report.Divergences[0].Key["id"])
+	require.True(t, report.Divergences[1].Missing)
+	require.False(t, report.Divergences[1].Repaired)
+
+	get := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.GetItem")
+	require.NotNil(t, get)
+	require.Equal(t, true, get.Payload["ConsistentRead"])
+
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem"))
+	update := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem")
+	require.Equal(t, "SET #r1 = :r1", update.Payload["UpdateExpression"])
+	require.Equal(t, map[string]any{"S": "closed"}, update.Payload["ExpressionAttributeValues"].(map[string]any)[":r1"])
+}
+
+func TestVerifyIndex_SelectedAttributesWithoutRepair(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Scan":    `{"Items":[{"id":{"S":"o1"},"customer":{"S":"c1"},"state":{"S":"open"}}]}`,
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"o1"},"customer":{"S":"c2"},"state":{"S":"closed"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	report, err := db.VerifyIndex(&verifyOrder{}, "gsi-customer", WithVerifyAttributes("Customer"))
+	require.NoError(t, err)
+	require.Len(t, report.Divergences, 1)
+	require.Equal(t, []string{"customer"}, report.Divergences[0].Attributes)
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem"))
+}
+
+func TestVerifyIndex_UnknownIndex(t *testing.T) {
+	db := newStubbedDB(t, newCapturingHTTPClient(nil))
+
+	_, err := db.VerifyIndex(&verifyOrder{}, "gsi-missing")
+	require.ErrorContains(t, err, "no global secondary index")
+}
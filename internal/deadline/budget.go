@@ -0,0 +1,129 @@
+// Package deadline budgets the time left before a context deadline across the
+// individual DynamoDB calls of a multi-call operation.
+package deadline
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// DefaultReserve is held back from a context deadline so callers have time to
+	// handle partial results. Extra deadlines passed to New, such as a Lambda deadline
+	// that already carries its own timeout buffer, are not reduced by it.
+	DefaultReserve = 100 * time.Millisecond
+
+	// minCallEstimate is the assumed cost of a call before any call has been observed.
+	minCallEstimate = 25 * time.Millisecond
+)
+
+// Budget tracks the remaining time before a deadline and the observed cost of calls.
+// The zero value (and a Budget built from a context without a deadline) never runs out.
+// A Budget is not safe for concurrent use.
+type Budget struct {
+	deadline time.Time
+	now      func() time.Time
+	longest  time.Duration
+	calls    int
+}
+
+// New creates a budget that ends at the earliest of ctx's deadline less reserve and
+// the extra deadlines supplied. Extra deadlines are used as given, since callers pass
+// deadlines that are already buffered (zero values are ignored).
+func New(ctx context.Context, reserve time.Duration, extra ...time.Time) *Budget {
+	b := &Budget{now: time.Now}
+
+	var deadline time.Time
+	if ctx != nil {
+		if d, ok := ctx.Deadline(); ok {
+			deadline = d.Add(-reserve)
+		}
+	}
+	for _, d := range extra {
+		if d.IsZero() {
+			continue
+		}
+		if deadline.IsZero() || d.Before(deadline) {
+			deadline = d
+		}
+	}
+	b.deadline = deadline
+	return b
+}
+
+// Deadline returns the budget's deadline and whether one is set.
+func (b *Budget) Deadline() (time.Time, bool) {
+	if b == nil || b.deadline.IsZero() {
+		return time.Time{}, false
+	}
+	return b.deadline, true
+}
+
+// Remaining returns the time left in the budget. Budgets without a deadline report
+// the maximum duration.
+func (b *Budget) Remaining() time.Duration {
+	if b == nil || b.deadline.IsZero() {
+		return time.Duration(1<<63 - 1)
+	}
+	return b.deadline.Sub(b.clock())
+}
+
+// Estimate returns the expected cost of the next call: the longest call observed so
+// far, or a small floor when nothing has been observed yet.
+func (b *Budget) Estimate() time.Duration {
+	if b == nil || b.longest < minCallEstimate {
+		return minCallEstimate
+	}
+	return b.longest
+}
+
+// CanStart reports whether another call is expected to finish before the deadline.
+func (b *Budget) CanStart() bool {
+	return b.CanAfford(0)
+}
+
+// CanAfford reports whether waiting for extra and then making another call is
+// expected to finish before the deadline.
+func (b *Budget) CanAfford(extra time.Duration) bool {
+	if b == nil || b.deadline.IsZero() {
+		return true
+	}
+	return b.Remaining() > extra+b.Estimate()
+}
+
+// Start marks the beginning of a call; invoke the returned function when it ends.
+func (b *Budget) Start() func() {
+	if b == nil {
+		return func() {}
+	}
+	started := b.clock()
+	return func() {
+		b.Observe(b.clock().Sub(started))
+	}
+}
+
+// Observe records the duration of a completed call.
+func (b *Budget) Observe(d time.Duration) {
+	if b == nil {
+		return
+	}
+	b.calls++
+	if d > b.longest {
+		b.longest = d
+	}
+}
+
+// Calls returns the number of observed calls.
+func (b *Budget) Calls() int {
+	if b == nil {
+		return 0
+	}
+	return b.calls
+}
+
+func (b *Budget) clock() time.Time {
+	if b.now == nil {
+		return time.Now()
+	}
+	return b.now()
+}
@@ -0,0 +1,61 @@
+package deadline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudget_NoDeadlineNeverRunsOut(t *testing.T) {
+	b := New(context.Background(), DefaultReserve)
+	_, ok := b.Deadline()
+	require.False(t, ok)
+	require.True(t, b.CanStart())
+	require.True(t, b.CanAfford(time.Hour))
+
+	var nilBudget *Budget
+	require.True(t, nilBudget.CanStart())
+	nilBudget.Start()()
+	require.Equal(t, 0, nilBudget.Calls())
+}
+
+func TestBudget_UsesEarliestDeadline(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithDeadline(context.Background(), base.Add(10*time.Second))
+	defer cancel()
+
+	b := New(ctx, time.Second, time.Time{}, base.Add(5*time.Second))
+	d, ok := b.Deadline()
+	require.True(t, ok)
+	require.Equal(t, base.Add(5*time.Second), d, "extra deadlines are already buffered")
+
+	b = New(ctx, time.Second, base.Add(9500*time.Millisecond))
+	d, ok = b.Deadline()
+	require.True(t, ok)
+	require.Equal(t, base.Add(9*time.Second), d, "the reserve applies to the context deadline")
+}
+
+func TestBudget_EstimatesFromLongestObservedCall(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := New(context.Background(), 0, now.Add(time.Second))
+	b.now = func() time.Time { return now }
+
+	require.Equal(t, minCallEstimate, b.Estimate())
+	require.True(t, b.CanStart())
+
+	done := b.Start()
+	now = now.Add(400 * time.Millisecond)
+	done()
+	b.Observe(100 * time.Millisecond)
+
+	require.Equal(t, 2, b.Calls())
+	require.Equal(t, 400*time.Millisecond, b.Estimate())
+	require.Equal(t, 600*time.Millisecond, b.Remaining())
+	require.True(t, b.CanStart())
+	require.False(t, b.CanAfford(300*time.Millisecond))
+
+	now = now.Add(250 * time.Millisecond)
+	require.False(t, b.CanStart())
+}
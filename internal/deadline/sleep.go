@@ -0,0 +1,22 @@
+package deadline
+
+import (
+	"context"
+	"time"
+)
+
+// Sleep waits for d, or until ctx is done, in which case it returns ctx's error. A nil
+// ctx never ends the wait early.
+func Sleep(ctx context.Context, d time.Duration) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,20 @@
+package deadline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSleep(t *testing.T) {
+	require.NoError(t, Sleep(context.Background(), time.Millisecond))
+	require.NoError(t, Sleep(nil, time.Millisecond)) //nolint:staticcheck // nil contexts are accepted
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	require.ErrorIs(t, Sleep(ctx, time.Hour), context.Canceled)
+	require.Less(t, time.Since(start), time.Second)
+}
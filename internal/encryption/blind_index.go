@@ -0,0 +1,83 @@
+package encryption
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+// minBlindIndexKeyLen is the shortest BlindIndexKey accepted, the HMAC-SHA256 block
+// strength.
+const minBlindIndexKeyLen = 32
+
+// BlindIndexKey returns the session's blind index key. It fails closed when metadata
+// has blind index fields and the key is missing or too short.
+func BlindIndexKey(sess *session.Session, metadata *model.Metadata) ([]byte, error) {
+	if metadata == nil || len(metadata.BlindIndexes) == 0 {
+		return nil, nil
+	}
+	var key []byte
+	if sess != nil && sess.Config() != nil {
+		key = sess.Config().BlindIndexKey
+	}
+	if err := checkBlindIndexKey(key); err != nil {
+		return nil, fmt.Errorf("%w (model %s)", err, metadata.Type.Name())
+	}
+	return key, nil
+}
+
+func checkBlindIndexKey(key []byte) error {
+	if len(key) < minBlindIndexKeyLen {
+		return fmt.Errorf("%w: session.Config.BlindIndexKey must be at least %d bytes for dynamorm:\"blindindex\" fields", customerrors.ErrEncryptionNotConfigured, minBlindIndexKeyLen)
+	}
+	return nil
+}
+
+// BlindIndex returns the blind index of av, a plaintext value of the encrypted attribute
+// attributeName: its HMAC-SHA256 under key, base64url encoded. Equal values have equal
+// blind indexes, so they can be compared and indexed, while without the key the index
+// reveals nothing but equality. The attribute name is part of the hash, so equal values
+// of different attributes do not match.
+func BlindIndex(key []byte, attributeName string, av types.AttributeValue) (string, error) {
+	if err := checkBlindIndexKey(key); err != nil {
+		return "", err
+	}
+	plaintext, err := encodeAttributeValue(av)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(fmt.Sprintf("dynamorm:blindindex:v1|attr=%s|", attributeName)))
+	mac.Write(plaintext)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// ApplyBlindIndexes sets the blind index attributes of item, a plaintext item about to be
+// encrypted and written, from the encrypted attributes they index. Blind indexes of
+// attributes the item leaves out or writes as NULL are removed.
+func ApplyBlindIndexes(key []byte, metadata *model.Metadata, item map[string]types.AttributeValue) error {
+	if metadata == nil || len(metadata.BlindIndexes) == 0 {
+		return nil
+	}
+	for _, field := range metadata.BlindIndexes {
+		source := metadata.Fields[field.BlindIndexOf]
+		av, ok := item[source.DBName]
+		if _, isNull := av.(*types.AttributeValueMemberNULL); !ok || isNull {
+			delete(item, field.DBName)
+			continue
+		}
+		index, err := BlindIndex(key, source.DBName, av)
+		if err != nil {
+			return err
+		}
+		item[field.DBName] = &types.AttributeValueMemberS{Value: index}
+	}
+	return nil
+}
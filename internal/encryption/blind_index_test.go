@@ -0,0 +1,60 @@
+package encryption
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	dynamormErrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+func TestBlindIndex(t *testing.T) {
+	key := bytes.Repeat([]byte{0x07}, 32)
+	ssn := &types.AttributeValueMemberS{Value: "123-45-6789"}
+
+	first, err := BlindIndex(key, "ssn", ssn)
+	require.NoError(t, err)
+	second, err := BlindIndex(key, "ssn", &types.AttributeValueMemberS{Value: "123-45-6789"})
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	other, err := BlindIndex(key, "taxId", ssn)
+	require.NoError(t, err)
+	require.NotEqual(t, first, other)
+
+	otherKey, err := BlindIndex(bytes.Repeat([]byte{0x08}, 32), "ssn", ssn)
+	require.NoError(t, err)
+	require.NotEqual(t, first, otherKey)
+
+	_, err = BlindIndex(key[:16], "ssn", ssn)
+	require.ErrorIs(t, err, dynamormErrors.ErrEncryptionNotConfigured)
+}
+
+func TestApplyBlindIndexes(t *testing.T) {
+	type patient struct {
+		ID      string  `dynamorm:"pk"`
+		SSN     *string `dynamorm:"encrypted,attr:ssn"`
+		SSNHash string  `dynamorm:"blindindex:SSN,attr:ssnHash"`
+	}
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&patient{}))
+	metadata, err := registry.GetMetadata(&patient{})
+	require.NoError(t, err)
+	key := bytes.Repeat([]byte{0x07}, 32)
+
+	item := map[string]types.AttributeValue{"ssn": &types.AttributeValueMemberS{Value: "123-45-6789"}}
+	require.NoError(t, ApplyBlindIndexes(key, metadata, item))
+	want, err := BlindIndex(key, "ssn", item["ssn"])
+	require.NoError(t, err)
+	require.Equal(t, &types.AttributeValueMemberS{Value: want}, item["ssnHash"])
+
+	item = map[string]types.AttributeValue{
+		"ssn":     &types.AttributeValueMemberNULL{Value: true},
+		"ssnHash": &types.AttributeValueMemberS{Value: "stale"},
+	}
+	require.NoError(t, ApplyBlindIndexes(key, metadata, item))
+	require.NotContains(t, item, "ssnHash")
+}
@@ -0,0 +1,119 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// Crypto actions for each attribute of an item encryptor's configuration, named as the
+// AWS Database Encryption SDK for DynamoDB names them.
+const (
+	ActionEncryptAndSign = "ENCRYPT_AND_SIGN"
+	ActionSignOnly       = "SIGN_ONLY"
+	ActionDoNothing      = "DO_NOTHING"
+)
+
+// AttributeActions returns the crypto action of each attribute of metadata's items, for
+// configuring an item encryptor that matches DynamORM's model: encrypted fields are
+// encrypted and signed, primary key attributes signed, and everything else left alone so
+// it can be updated in place. DO_NOTHING attributes must also be allowed as unsigned
+// attributes.
+func AttributeActions(metadata *model.Metadata) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	actions := make(map[string]string, len(metadata.Fields))
+	for _, field := range metadata.Fields {
+		switch {
+		case field.IsEncrypted:
+			actions[field.DBName] = ActionEncryptAndSign
+		case field.IsPK || field.IsSK:
+			actions[field.DBName] = ActionSignOnly
+		default:
+			actions[field.DBName] = ActionDoNothing
+		}
+	}
+	return actions
+}
+
+// EncryptItem encrypts the encrypted fields of item, a plaintext item of metadata about
+// to be written, in place: with the session's item encryptor when it has one, and
+// otherwise attribute by attribute.
+func (s *Service) EncryptItem(ctx context.Context, metadata *model.Metadata, item map[string]types.AttributeValue) error {
+	if s != nil && s.items != nil {
+		encrypted, err := s.items.EncryptItem(ctx, metadata.TableName, item)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt item: %w", err)
+		}
+		replaceItem(item, encrypted)
+		return nil
+	}
+
+	for _, fieldMeta := range metadata.Fields {
+		if fieldMeta == nil || !fieldMeta.IsEncrypted {
+			continue
+		}
+		av, ok := item[fieldMeta.DBName]
+		if !ok {
+			continue
+		}
+
+		encryptedAV, err := s.EncryptAttributeValue(ctx, fieldMeta.DBName, av)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt field %s: %w", fieldMeta.DBName, err)
+		}
+		item[fieldMeta.DBName] = encryptedAV
+	}
+	return nil
+}
+
+// DecryptItem decrypts the encrypted fields of item, an item of metadata as read, in
+// place.
+func (s *Service) DecryptItem(ctx context.Context, metadata *model.Metadata, item map[string]types.AttributeValue) error {
+	if s != nil && s.items != nil {
+		decrypted, err := s.items.DecryptItem(ctx, metadata.TableName, item)
+		if err != nil {
+			return &customerrors.EncryptedFieldError{Operation: "decrypt", Err: err}
+		}
+		replaceItem(item, decrypted)
+		return nil
+	}
+
+	for attrName, attrValue := range item {
+		fieldMeta, ok := metadata.FieldsByDBName[attrName]
+		if !ok || fieldMeta == nil || !fieldMeta.IsEncrypted {
+			continue
+		}
+
+		decrypted, err := s.DecryptAttributeValue(ctx, fieldMeta.DBName, attrValue)
+		if err != nil {
+			return &customerrors.EncryptedFieldError{
+				Operation: "decrypt",
+				Field:     fieldMeta.Name,
+				Err:       err,
+			}
+		}
+		item[attrName] = decrypted
+	}
+	return nil
+}
+
+// itemEncryptorAttributeError reports an attempt to encrypt or decrypt one attribute
+// when the session's item encryptor encrypts and signs whole items.
+func itemEncryptorAttributeError(attributeName string) error {
+	return fmt.Errorf("encrypted field %s cannot be encrypted on its own with session.Config.ItemEncryptor; write the whole item", attributeName)
+}
+
+// replaceItem makes item hold the attributes of replacement, so callers holding item see
+// the result.
+func replaceItem(item, replacement map[string]types.AttributeValue) {
+	clear(item)
+	for name, av := range replacement {
+		item[name] = av
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"slices"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
@@ -15,6 +16,7 @@ import (
 	kmsTypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
 
 	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/session"
 )
 
 const (
@@ -24,6 +26,9 @@ const (
 	envelopeKeyEDK        = "edk"
 	envelopeKeyNonce      = "nonce"
 	envelopeKeyCiphertext = "ct"
+	// envelopeKeyKeyID names the KMS key the data key was encrypted under. Envelopes
+	// written before it was added lack it.
+	envelopeKeyKeyID = "kid"
 )
 
 type kmsAPI interface {
@@ -33,10 +38,17 @@ type kmsAPI interface {
 
 // Service implements envelope encryption for DynamoDB attribute values using AWS KMS.
 type Service struct {
-	kms  kmsAPI
-	rand io.Reader
+	kms   kmsAPI
+	rand  io.Reader
+	cache *session.DataKeyCache
+	items session.ItemEncryptor
 
-	keyARN string
+	// credentials sign the KMS calls, when the KMS client is built from the session's
+	// AWS config, and select the data keys of cache the service reuses.
+	credentials aws.CredentialsProvider
+
+	keyARN          string
+	previousKeyARNs []string
 }
 
 func NewService(keyARN string, kmsClient kmsAPI) *Service {
@@ -62,10 +74,41 @@ func NewServiceFromAWSConfigWithRand(keyARN string, cfg aws.Config, rng io.Reade
 	return NewServiceWithRand(keyARN, kms.NewFromConfig(cfg), rng)
 }
 
+// NewServiceForSession returns the service for the session's encrypted fields: it writes
+// under Config.KMSKeyARN, reads under it and Config.KMSPreviousKeyARNs, and shares the
+// session's data key cache. With Config.ItemEncryptor, it encrypts whole items with that
+// instead.
+func NewServiceForSession(sess *session.Session) (*Service, error) {
+	if sess == nil || sess.Config() == nil {
+		return nil, fmt.Errorf("%w: session is nil", customerrors.ErrEncryptionNotConfigured)
+	}
+	cfg := sess.Config()
+	if cfg.ItemEncryptor != nil {
+		return &Service{items: cfg.ItemEncryptor}, nil
+	}
+	if cfg.KMSKeyARN == "" {
+		return nil, fmt.Errorf("%w: session.Config.KMSKeyARN is empty", customerrors.ErrEncryptionNotConfigured)
+	}
+
+	var svc *Service
+	if cfg.KMSClient != nil {
+		svc = NewServiceWithRand(cfg.KMSKeyARN, cfg.KMSClient, cfg.EncryptionRand)
+	} else {
+		svc = NewServiceFromAWSConfigWithRand(cfg.KMSKeyARN, sess.AWSConfig(), cfg.EncryptionRand)
+		svc.credentials = sess.AWSConfig().Credentials
+	}
+	svc.previousKeyARNs = cfg.KMSPreviousKeyARNs
+	svc.cache = sess.DataKeyCache()
+	return svc, nil
+}
+
 func (s *Service) EncryptAttributeValue(ctx context.Context, attributeName string, av types.AttributeValue) (types.AttributeValue, error) {
 	if s == nil {
 		return nil, fmt.Errorf("encryption service is nil")
 	}
+	if s.items != nil {
+		return nil, itemEncryptorAttributeError(attributeName)
+	}
 	if s.kms == nil {
 		return nil, fmt.Errorf("kms client is nil")
 	}
@@ -81,18 +124,9 @@ func (s *Service) EncryptAttributeValue(ctx context.Context, attributeName strin
 		return nil, err
 	}
 
-	dataKey, err := s.kms.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
-		KeyId:   aws.String(s.keyARN),
-		KeySpec: kmsTypes.DataKeySpecAes256,
-	})
+	dataKey, err := s.encryptionDataKey(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("kms GenerateDataKey failed: %w", err)
-	}
-	if len(dataKey.Plaintext) != 32 {
-		return nil, fmt.Errorf("unexpected data key plaintext length: %d", len(dataKey.Plaintext))
-	}
-	if len(dataKey.CiphertextBlob) == 0 {
-		return nil, fmt.Errorf("kms returned empty ciphertext data key")
+		return nil, err
 	}
 
 	block, err := aes.NewCipher(dataKey.Plaintext)
@@ -118,10 +152,53 @@ func (s *Service) EncryptAttributeValue(ctx context.Context, attributeName strin
 			envelopeKeyEDK:        &types.AttributeValueMemberB{Value: dataKey.CiphertextBlob},
 			envelopeKeyNonce:      &types.AttributeValueMemberB{Value: nonce},
 			envelopeKeyCiphertext: &types.AttributeValueMemberB{Value: ct},
+			envelopeKeyKeyID:      &types.AttributeValueMemberS{Value: s.keyARN},
 		},
 	}, nil
 }
 
+// encryptionDataKey returns the data key to encrypt a value with: a cached one while the
+// cache allows, or else a new one from KMS.
+func (s *Service) encryptionDataKey(ctx context.Context) (session.DataKey, error) {
+	cache, err := s.dataKeys(ctx)
+	if err != nil {
+		return session.DataKey{}, err
+	}
+	if cached, ok := cache.EncryptionKey(s.keyARN); ok {
+		return cached, nil
+	}
+
+	dataKey, err := s.kms.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:   aws.String(s.keyARN),
+		KeySpec: kmsTypes.DataKeySpecAes256,
+	})
+	if err != nil {
+		return session.DataKey{}, fmt.Errorf("kms GenerateDataKey failed: %w", err)
+	}
+	if len(dataKey.Plaintext) != 32 {
+		return session.DataKey{}, fmt.Errorf("unexpected data key plaintext length: %d", len(dataKey.Plaintext))
+	}
+	if len(dataKey.CiphertextBlob) == 0 {
+		return session.DataKey{}, fmt.Errorf("kms returned empty ciphertext data key")
+	}
+	key := session.DataKey{Plaintext: dataKey.Plaintext, CiphertextBlob: dataKey.CiphertextBlob}
+	cache.PutEncryptionKey(s.keyARN, key)
+	return key, nil
+}
+
+// dataKeys returns the data keys the service may reuse: those KMS returned to the
+// credentials its calls are signed with.
+func (s *Service) dataKeys(ctx context.Context) (*session.DataKeyCache, error) {
+	if s.cache == nil || s.credentials == nil {
+		return s.cache, nil
+	}
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve credentials for KMS: %w", err)
+	}
+	return s.cache.ForCredentials(creds.AccessKeyID), nil
+}
+
 func (s *Service) DecryptAttributeValue(ctx context.Context, attributeName string, envelope types.AttributeValue) (types.AttributeValue, error) {
 	if err := s.validateDecryptInputs(attributeName); err != nil {
 		return nil, err
@@ -132,7 +209,7 @@ func (s *Service) DecryptAttributeValue(ctx context.Context, attributeName strin
 		return nil, err
 	}
 
-	dataKey, err := s.decryptDataKey(ctx, parts.edk)
+	dataKey, err := s.decryptDataKey(ctx, parts)
 	if err != nil {
 		return nil, err
 	}
@@ -156,6 +233,7 @@ func aadForAttribute(attributeName string) []byte {
 }
 
 type encryptedEnvelopeParts struct {
+	keyID      string
 	edk        []byte
 	nonce      []byte
 	ciphertext []byte
@@ -165,6 +243,9 @@ func (s *Service) validateDecryptInputs(attributeName string) error {
 	if s == nil {
 		return fmt.Errorf("encryption service is nil")
 	}
+	if s.items != nil {
+		return itemEncryptorAttributeError(attributeName)
+	}
 	if s.kms == nil {
 		return fmt.Errorf("kms client is nil")
 	}
@@ -202,7 +283,17 @@ func parseEncryptedEnvelope(envelope types.AttributeValue) (encryptedEnvelopePar
 		return encryptedEnvelopeParts{}, fmt.Errorf("%w: missing ciphertext", customerrors.ErrInvalidEncryptedEnvelope)
 	}
 
+	var keyID string
+	if kidAV, ok := env.Value[envelopeKeyKeyID]; ok {
+		kid, isString := kidAV.(*types.AttributeValueMemberS)
+		if !isString || kid.Value == "" {
+			return encryptedEnvelopeParts{}, fmt.Errorf("%w: invalid key id", customerrors.ErrInvalidEncryptedEnvelope)
+		}
+		keyID = kid.Value
+	}
+
 	return encryptedEnvelopeParts{
+		keyID:      keyID,
 		edk:        edkAV.Value,
 		nonce:      nonceAV.Value,
 		ciphertext: ctAV.Value,
@@ -217,18 +308,42 @@ func validateEncryptedEnvelopeVersion(values map[string]types.AttributeValue) er
 	return nil
 }
 
-func (s *Service) decryptDataKey(ctx context.Context, edk []byte) ([]byte, error) {
-	dec, err := s.kms.Decrypt(ctx, &kms.DecryptInput{
-		CiphertextBlob: edk,
-		KeyId:          aws.String(s.keyARN),
-	})
-	if err != nil {
-		return nil, fmt.Errorf("kms Decrypt failed: %w", err)
+// decryptDataKey decrypts the envelope's data key under the key its key id names, which
+// must be KMSKeyARN or one of the previous keys. Envelopes without a key id are tried
+// under each of those keys in turn.
+func (s *Service) decryptDataKey(ctx context.Context, parts encryptedEnvelopeParts) ([]byte, error) {
+	keyARNs := append([]string{s.keyARN}, s.previousKeyARNs...)
+	if parts.keyID != "" {
+		if !slices.Contains(keyARNs, parts.keyID) {
+			return nil, fmt.Errorf("%w: data key was encrypted under %s, which is not KMSKeyARN or one of KMSPreviousKeyARNs", customerrors.ErrInvalidEncryptedEnvelope, parts.keyID)
+		}
+		keyARNs = []string{parts.keyID}
 	}
-	if len(dec.Plaintext) != 32 {
-		return nil, fmt.Errorf("unexpected data key plaintext length: %d", len(dec.Plaintext))
+
+	cache, err := s.dataKeys(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return dec.Plaintext, nil
+	for _, keyARN := range keyARNs {
+		if plaintext, ok := cache.DecryptedKey(keyARN, parts.edk); ok {
+			return plaintext, nil
+		}
+		var dec *kms.DecryptOutput
+		dec, err = s.kms.Decrypt(ctx, &kms.DecryptInput{
+			CiphertextBlob: parts.edk,
+			KeyId:          aws.String(keyARN),
+		})
+		if err != nil {
+			err = fmt.Errorf("kms Decrypt failed: %w", err)
+			continue
+		}
+		if len(dec.Plaintext) != 32 {
+			return nil, fmt.Errorf("unexpected data key plaintext length: %d", len(dec.Plaintext))
+		}
+		cache.PutDecryptedKey(keyARN, parts.edk, dec.Plaintext)
+		return dec.Plaintext, nil
+	}
+	return nil, err
 }
 
 func newGCM(key []byte) (cipher.AEAD, error) {
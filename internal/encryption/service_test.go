@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"maps"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
@@ -13,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	dynamormErrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/session"
 )
 
 type fakeKMS struct {
@@ -297,7 +300,7 @@ func TestService_DecryptAttributeValue_Errors(t *testing.T) {
 			edk:       []byte("edk"),
 		}
 		svc := NewService(fake.keyARN, fake)
-		_, err := svc.decryptDataKey(ctx, []byte("edk"))
+		_, err := svc.decryptDataKey(ctx, encryptedEnvelopeParts{edk: []byte("edk")})
 		require.Error(t, err)
 		require.ErrorContains(t, err, "unexpected data key plaintext length")
 	})
@@ -313,3 +316,104 @@ func TestService_DecryptAttributeValue_Errors(t *testing.T) {
 		require.ErrorContains(t, err, "failed to decode attribute value")
 	})
 }
+
+// keyringKMS is a fake KMS holding several keys, each with one data key, that counts
+// its calls.
+type keyringKMS struct {
+	keys      map[string][]byte // key ARN to data key ciphertext
+	plaintext []byte
+	generates int
+	decrypts  int
+}
+
+func (f *keyringKMS) GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error) {
+	f.generates++
+	edk, ok := f.keys[aws.ToString(params.KeyId)]
+	if !ok {
+		return nil, errors.New("unknown key")
+	}
+	return &kms.GenerateDataKeyOutput{Plaintext: append([]byte(nil), f.plaintext...), CiphertextBlob: edk}, nil
+}
+
+func (f *keyringKMS) Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	f.decrypts++
+	if !bytes.Equal(f.keys[aws.ToString(params.KeyId)], params.CiphertextBlob) {
+		return nil, errors.New("IncorrectKeyException")
+	}
+	return &kms.DecryptOutput{Plaintext: append([]byte(nil), f.plaintext...)}, nil
+}
+
+func TestService_DataKeyCacheAndRotation(t *testing.T) {
+	ctx := context.Background()
+	const oldKey, newKey = "arn:aws:kms:us-east-1:123456789012:key/old", "arn:aws:kms:us-east-1:123456789012:key/new"
+	fake := &keyringKMS{
+		keys:      map[string][]byte{oldKey: []byte("edk-old"), newKey: []byte("edk-new")},
+		plaintext: bytes.Repeat([]byte{0x11}, 32),
+	}
+	cache := session.NewDataKeyCache(session.DataKeyCachePolicy{TTL: time.Minute, MaxUses: 2}, nil)
+	plaintext := &types.AttributeValueMemberS{Value: "hello"}
+
+	before := NewService(oldKey, fake)
+	before.cache = cache
+	oldEnvelope, err := before.EncryptAttributeValue(ctx, "secret", plaintext)
+	require.NoError(t, err)
+	require.Equal(t, &types.AttributeValueMemberS{Value: oldKey}, oldEnvelope.(*types.AttributeValueMemberM).Value[envelopeKeyKeyID])
+	_, err = before.EncryptAttributeValue(ctx, "secret", plaintext)
+	require.NoError(t, err)
+	_, err = before.EncryptAttributeValue(ctx, "secret", plaintext)
+	require.NoError(t, err)
+	require.Equal(t, 2, fake.generates, "a data key encrypts MaxUses values")
+
+	after := NewService(newKey, fake)
+	after.previousKeyARNs = []string{oldKey}
+	after.cache = cache
+	for i := 0; i < 2; i++ {
+		decrypted, err := after.DecryptAttributeValue(ctx, "secret", oldEnvelope)
+		require.NoError(t, err)
+		require.Equal(t, plaintext, decrypted)
+	}
+	require.Equal(t, 1, fake.decrypts, "decrypted data keys are cached")
+
+	legacy := oldEnvelope.(*types.AttributeValueMemberM)
+	legacy = &types.AttributeValueMemberM{Value: maps.Clone(legacy.Value)}
+	delete(legacy.Value, envelopeKeyKeyID)
+	after.cache = nil
+	decrypted, err := after.DecryptAttributeValue(ctx, "secret", legacy)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, decrypted)
+	require.Equal(t, 3, fake.decrypts, "envelopes without a key id try each key")
+
+	rotatedOut := NewService(newKey, fake)
+	_, err = rotatedOut.DecryptAttributeValue(ctx, "secret", oldEnvelope)
+	require.ErrorIs(t, err, dynamormErrors.ErrInvalidEncryptedEnvelope)
+}
+
+func TestService_DataKeysPerCredentials(t *testing.T) {
+	ctx := context.Background()
+	const keyARN = "arn:aws:kms:us-east-1:123456789012:key/k"
+	fake := &keyringKMS{keys: map[string][]byte{keyARN: []byte("edk")}, plaintext: bytes.Repeat([]byte{0x11}, 32)}
+	cache := session.NewDataKeyCache(session.DataKeyCachePolicy{TTL: time.Minute}, nil)
+	serviceFor := func(accessKeyID string) *Service {
+		svc := NewService(keyARN, fake)
+		svc.cache = cache
+		svc.credentials = aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: accessKeyID}, nil
+		})
+		return svc
+	}
+	plaintext := &types.AttributeValueMemberS{Value: "hello"}
+
+	for _, accessKeyID := range []string{"AKIA1", "AKIA1", "AKIA2"} {
+		_, err := serviceFor(accessKeyID).EncryptAttributeValue(ctx, "secret", plaintext)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, fake.generates, "credentials reuse only the data keys KMS returned to them")
+
+	failing := NewService(keyARN, fake)
+	failing.cache = cache
+	failing.credentials = aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{}, errors.New("no credentials")
+	})
+	_, err := failing.EncryptAttributeValue(ctx, "secret", plaintext)
+	require.ErrorContains(t, err, "no credentials")
+}
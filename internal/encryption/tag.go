@@ -33,6 +33,9 @@ func FailClosedIfEncryptedWithoutKMSKeyARN(sess *session.Session, metadata *mode
 
 	keyARN := ""
 	if sess != nil && sess.Config() != nil {
+		if sess.Config().ItemEncryptor != nil {
+			return nil
+		}
 		keyARN = sess.Config().KMSKeyARN
 	}
 	if keyARN != "" {
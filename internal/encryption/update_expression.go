@@ -55,7 +55,12 @@ func EncryptUpdateExpressionValues(
 		return err
 	}
 
-	return rejectEncryptedAddDeleteUpdateExpressionSections(sections, encrypted, exprAttrNames)
+	actions := []string{"ADD", "DELETE"}
+	if svc != nil && svc.items != nil {
+		// Removing a signed attribute would leave the item failing verification.
+		actions = append(actions, "REMOVE")
+	}
+	return rejectEncryptedUpdateExpressionSections(sections, actions, encrypted, exprAttrNames)
 }
 
 func encryptSetUpdateExpressionSection(
@@ -124,12 +129,13 @@ func encryptSetAssignment(
 	return fmt.Errorf("unsupported update expression for encrypted field %s", attrName)
 }
 
-func rejectEncryptedAddDeleteUpdateExpressionSections(
+func rejectEncryptedUpdateExpressionSections(
 	sections map[string]string,
+	actions []string,
 	encrypted map[string]struct{},
 	exprAttrNames map[string]string,
 ) error {
-	for _, action := range []string{"ADD", "DELETE"} {
+	for _, action := range actions {
 		segment := strings.TrimSpace(sections[action])
 		if segment == "" {
 			continue
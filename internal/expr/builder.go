@@ -204,6 +204,24 @@ func (b *Builder) AddFilterCondition(logicalOp, field, operator string, value an
 	return nil
 }
 
+// AddRequiredFilterCondition adds a condition every result must meet. It is joined with
+// AND to the whole filter built so far, so OR conditions added earlier cannot bypass it.
+func (b *Builder) AddRequiredFilterCondition(field, operator string, value any) error {
+	expr, err := b.buildCondition(field, operator, value)
+	if err != nil {
+		return err
+	}
+	if len(b.filterConditions) > 1 {
+		b.filterConditions = []string{"(" + b.joinFilterConditions() + ")"}
+		b.filterOperators = nil
+	}
+	b.filterConditions = append(b.filterConditions, expr)
+	if len(b.filterConditions) > 1 {
+		b.filterOperators = append(b.filterOperators, "AND")
+	}
+	return nil
+}
+
 // AddGroupFilter adds a grouped filter expression
 func (b *Builder) AddGroupFilter(logicalOp string, components ExpressionComponents) {
 	for ph, name := range components.ExpressionAttributeNames {
@@ -222,6 +240,33 @@ func (b *Builder) AddGroupFilter(logicalOp string, components ExpressionComponen
 	}
 }
 
+// NewGroup returns an empty builder for a grouped filter whose placeholders continue from
+// b's, so AddGroup can merge it back without reusing a placeholder b already assigned.
+func (b *Builder) NewGroup() *Builder {
+	group := NewBuilderWithConverter(b.converter)
+	for placeholder, name := range b.names {
+		group.names[placeholder] = name
+	}
+	group.nameCounter = b.nameCounter
+	group.valueCounter = b.valueCounter
+	return group
+}
+
+// AddGroup adds the filter conditions of a builder returned by NewGroup as one grouped
+// filter expression.
+func (b *Builder) AddGroup(logicalOp string, group *Builder) {
+	if group == nil {
+		return
+	}
+	b.AddGroupFilter(logicalOp, group.Build())
+	if group.nameCounter > b.nameCounter {
+		b.nameCounter = group.nameCounter
+	}
+	if group.valueCounter > b.valueCounter {
+		b.valueCounter = group.valueCounter
+	}
+}
+
 // AddProjection adds fields to the projection expression
 func (b *Builder) AddProjection(fields ...string) {
 	for _, field := range fields {
@@ -309,14 +354,21 @@ func (b *Builder) AddUpdateSet(field string, value any) error {
 	return nil
 }
 
-// AddUpdateAdd adds an ADD update expression (for numeric increment)
+// AddUpdateAdd adds an ADD update expression: a number to add to a numeric attribute, or
+// a slice of elements to add to a string, number, or binary set.
 func (b *Builder) AddUpdateAdd(field string, value any) error {
 	if err := validation.ValidateFieldName(field); err != nil {
 		return fmt.Errorf("invalid field name: %w", err)
 	}
 
 	nameRef := b.addNameSecure(field)
-	valueRef, err := b.addValueSecure(value)
+	var valueRef string
+	var err error
+	if b.isSetOperand(value) {
+		valueRef, err = b.addValueAsSet(value)
+	} else {
+		valueRef, err = b.addValueSecure(value)
+	}
 	if err != nil {
 		return err
 	}
@@ -325,6 +377,16 @@ func (b *Builder) AddUpdateAdd(field string, value any) error {
 	return nil
 }
 
+// isSetOperand reports whether value is a slice of set elements, rather than binary data
+// or a type a custom converter decides the representation of.
+func (b *Builder) isSetOperand(value any) bool {
+	typ := reflect.TypeOf(value)
+	if typ == nil || typ.Kind() != reflect.Slice || typ.Elem().Kind() == reflect.Uint8 {
+		return false
+	}
+	return b.converter == nil || !b.converter.HasCustomConverter(typ)
+}
+
 // AddUpdateRemove adds a REMOVE update expression
 func (b *Builder) AddUpdateRemove(field string) error {
 	if err := validation.ValidateFieldName(field); err != nil {
@@ -396,14 +458,7 @@ func (b *Builder) Build() ExpressionComponents {
 
 	// Build filter expression
 	if len(b.filterConditions) > 0 {
-		var builtExpr strings.Builder
-		builtExpr.WriteString(b.filterConditions[0])
-		for i := 1; i < len(b.filterConditions); i++ {
-			// The operator at i-1 links condition i-1 and condition i
-			builtExpr.WriteString(" " + b.filterOperators[i-1] + " ")
-			builtExpr.WriteString(b.filterConditions[i])
-		}
-		components.FilterExpression = builtExpr.String()
+		components.FilterExpression = b.joinFilterConditions()
 	}
 
 	// Build projection expression
@@ -441,6 +496,18 @@ func (b *Builder) Build() ExpressionComponents {
 	return components
 }
 
+// joinFilterConditions links the filter conditions with their logical operators.
+func (b *Builder) joinFilterConditions() string {
+	var builtExpr strings.Builder
+	builtExpr.WriteString(b.filterConditions[0])
+	for i := 1; i < len(b.filterConditions); i++ {
+		// The operator at i-1 links condition i-1 and condition i
+		builtExpr.WriteString(" " + b.filterOperators[i-1] + " ")
+		builtExpr.WriteString(b.filterConditions[i])
+	}
+	return builtExpr.String()
+}
+
 // ResetConditions clears the conditions list (used for splitting logic)
 func (b *Builder) ResetConditions() {
 	b.conditions = nil
@@ -620,7 +687,9 @@ func (b *Builder) buildCondition(field string, operator string, value any) (stri
 	}
 }
 
-// addNameSecure adds an attribute name with security validation
+// addNameSecure adds an attribute name with security validation. Document paths such as
+// "Address.City" or "Items[0].SKU" get a placeholder per attribute name, with list
+// indexes kept as literals.
 func (b *Builder) addNameSecure(name string) string {
 	// Additional security check
 	if err := validation.ValidateFieldName(name); err != nil {
@@ -628,57 +697,70 @@ func (b *Builder) addNameSecure(name string) string {
 		return "#invalid"
 	}
 
-	// Check if already added
-	for placeholder, attrName := range b.names {
-		if attrName == name {
-			return placeholder
-		}
+	if !strings.ContainsAny(name, ".[") {
+		return b.namePlaceholder(name)
 	}
 
-	// For nested attributes, process each part securely
-	if strings.Contains(name, ".") {
-		parts := strings.Split(name, ".")
-		processedParts := make([]string, len(parts))
-
-		for i, part := range parts {
-			// Validate each part
-			if err := validation.ValidateFieldName(part); err != nil {
-				// SECURITY: Return safe placeholder without logging details
-				return "#invalid"
-			}
-
-			if b.isReservedWord(part) {
-				b.nameCounter++
-				placeholder := fmt.Sprintf("#%s", strings.ToUpper(part))
-				b.names[placeholder] = part
-				processedParts[i] = placeholder
-			} else {
-				// Use placeholder for consistency and security
-				b.nameCounter++
-				placeholder := fmt.Sprintf("#n%d", b.nameCounter)
-				b.names[placeholder] = part
-				processedParts[i] = placeholder
-			}
+	parts := strings.Split(name, ".")
+	processedParts := make([]string, len(parts))
+	for i, part := range parts {
+		attrName, indexes, ok := splitDocumentPathPart(part)
+		// Later segments may be map keys such as "gift-wrap"; the whole path was validated above.
+		if !ok || (i == 0 && validation.ValidateFieldName(attrName) != nil) {
+			// SECURITY: Return safe placeholder without logging details
+			return "#invalid"
 		}
-
-		return strings.Join(processedParts, ".")
+		processedParts[i] = b.namePlaceholder(attrName) + indexes
 	}
 
-	// Check if it's a reserved word
-	if b.isReservedWord(name) {
-		b.nameCounter++
-		placeholder := fmt.Sprintf("#%s", strings.ToUpper(name))
-		b.names[placeholder] = name
-		return placeholder
+	return strings.Join(processedParts, ".")
+}
+
+// namePlaceholder returns the placeholder for a single attribute name, reusing the one
+// already assigned to it.
+func (b *Builder) namePlaceholder(name string) string {
+	for placeholder, attrName := range b.names {
+		if attrName == name {
+			return placeholder
+		}
 	}
 
-	// Generate new placeholder for non-reserved words (for consistency)
 	b.nameCounter++
 	placeholder := fmt.Sprintf("#n%d", b.nameCounter)
+	if b.isReservedWord(name) {
+		placeholder = fmt.Sprintf("#%s", strings.ToUpper(name))
+	}
 	b.names[placeholder] = name
 	return placeholder
 }
 
+// splitDocumentPathPart splits one dot-separated path element such as "Items[0][2]" into
+// the attribute name and its list index suffix.
+func splitDocumentPathPart(part string) (name string, indexes string, ok bool) {
+	open := strings.IndexByte(part, '[')
+	if open < 0 {
+		return part, "", part != ""
+	}
+	if open == 0 {
+		return "", "", false
+	}
+
+	name, indexes = part[:open], part[open:]
+	for rest := indexes; rest != ""; {
+		closing := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || closing < 2 {
+			return "", "", false
+		}
+		for _, r := range rest[1:closing] {
+			if r < '0' || r > '9' {
+				return "", "", false
+			}
+		}
+		rest = rest[closing+1:]
+	}
+	return name, indexes, true
+}
+
 // isReservedWord checks if a word is reserved in DynamoDB
 func (b *Builder) isReservedWord(word string) bool {
 	return reservedWords[strings.ToUpper(word)]
@@ -819,6 +901,42 @@ func (b *Builder) AddAdvancedFunction(function string, field string, args ...any
 	}
 }
 
+// AddCappedListAppend appends values to the list attribute field, creating the list when
+// it is absent, and adds a condition that the list holds at most limit elements before the
+// append: SET #f = list_append(if_not_exists(#f, :empty), :v) guarded by
+// (attribute_not_exists(#f) OR size(#f) <= :limit).
+func (b *Builder) AddCappedListAppend(field string, values any, limit int) error {
+	if err := validation.ValidateFieldName(field); err != nil {
+		return fmt.Errorf("invalid field name: %w", err)
+	}
+	if limit < 0 {
+		return errors.New("list limit cannot be negative")
+	}
+
+	nameRef := b.addNameSecure(field)
+	emptyRef, err := b.addValueSecure([]any{})
+	if err != nil {
+		return err
+	}
+	valueRef, err := b.addValueSecure(values)
+	if err != nil {
+		return err
+	}
+	limitRef, err := b.addValueSecure(limit)
+	if err != nil {
+		return err
+	}
+
+	expr := fmt.Sprintf("%s = list_append(if_not_exists(%s, %s), %s)", nameRef, nameRef, emptyRef, valueRef)
+	b.updateExpressions["SET"] = append(b.updateExpressions["SET"], expr)
+
+	b.conditions = append(b.conditions, fmt.Sprintf("(attribute_not_exists(%s) OR size(%s) <= %s)", nameRef, nameRef, limitRef))
+	if len(b.conditions) > 1 {
+		b.conditionOperators = append(b.conditionOperators, "AND")
+	}
+	return nil
+}
+
 // AddUpdateFunction adds a function-based update expression (e.g., list_append)
 func (b *Builder) AddUpdateFunction(field string, function string, args ...any) error {
 	nameRef := b.addNameSecure(field)
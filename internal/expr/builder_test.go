@@ -5,6 +5,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -153,6 +154,18 @@ func TestAddFilterCondition(t *testing.T) {
 	}
 }
 
+func TestAddRequiredFilterCondition(t *testing.T) {
+	builder := expr.NewBuilder()
+	require.NoError(t, builder.AddFilterCondition("AND", "status", "=", "active"))
+	require.NoError(t, builder.AddFilterCondition("OR", "status", "=", "pending"))
+	require.NoError(t, builder.AddRequiredFilterCondition("entityType", "=", "ORDER"))
+	assert.Equal(t, "(#STATUS = :v1 OR #STATUS = :v2) AND #n2 = :v3", builder.Build().FilterExpression)
+
+	builder = expr.NewBuilder()
+	require.NoError(t, builder.AddRequiredFilterCondition("entityType", "=", "ORDER"))
+	assert.Equal(t, "#n1 = :v1", builder.Build().FilterExpression)
+}
+
 func TestReservedWords(t *testing.T) {
 	builder := expr.NewBuilder()
 
@@ -191,6 +204,44 @@ func TestAddProjection(t *testing.T) {
 	assert.Contains(t, components.ExpressionAttributeNames, "#STATUS")
 }
 
+func TestAddProjection_DocumentPaths(t *testing.T) {
+	builder := expr.NewBuilder()
+
+	builder.AddProjection("address.city", "items[0].sku", "items[1]", "address.zip")
+
+	components := builder.Build()
+	// items is a reserved word; each path segment gets its own placeholder, reused across paths
+	assert.Equal(t, "#n1.#n2, #ITEMS[0].#n4, #ITEMS[1], #n1.#n5", components.ProjectionExpression)
+	assert.Equal(t, map[string]string{
+		"#n1":    "address",
+		"#n2":    "city",
+		"#ITEMS": "items",
+		"#n4":    "sku",
+		"#n5":    "zip",
+	}, components.ExpressionAttributeNames)
+
+	invalid := expr.NewBuilder()
+	invalid.AddProjection("items[x].sku", "[0]")
+	assert.Equal(t, "#invalid, #invalid", invalid.Build().ProjectionExpression)
+}
+
+func TestAddFilterCondition_MapKeys(t *testing.T) {
+	builder := expr.NewBuilder()
+
+	require.NoError(t, builder.AddFilterCondition("AND", "metadata.tier", "=", "gold"))
+	require.NoError(t, builder.AddFilterCondition("AND", "metadata.gift-wrap", "=", true))
+
+	components := builder.Build()
+	assert.Equal(t, "#n1.#n2 = :v1 AND #n1.#n3 = :v2", components.FilterExpression)
+	assert.Equal(t, map[string]string{
+		"#n1": "metadata",
+		"#n2": "tier",
+		"#n3": "gift-wrap",
+	}, components.ExpressionAttributeNames)
+
+	require.Error(t, expr.NewBuilder().AddFilterCondition("AND", "gift-wrap.tier", "=", "gold"))
+}
+
 func TestUpdateExpressions(t *testing.T) {
 	t.Run("SET expressions", func(t *testing.T) {
 		builder := expr.NewBuilder()
@@ -316,6 +367,21 @@ func TestAddConditionExpression(t *testing.T) {
 	assert.Contains(t, components.ConditionExpression, "#STATUS <> :v2")
 }
 
+func TestAddCappedListAppend(t *testing.T) {
+	builder := expr.NewBuilder()
+
+	require.NoError(t, builder.AddCappedListAppend("events", []string{"login"}, 9))
+	require.NoError(t, builder.AddConditionExpression("ownerId", "=", "u1"))
+
+	components := builder.Build()
+
+	assert.Equal(t, "SET #n1 = list_append(if_not_exists(#n1, :v1), :v2)", components.UpdateExpression)
+	assert.Equal(t, "(attribute_not_exists(#n1) OR size(#n1) <= :v3) AND #n2 = :v4", components.ConditionExpression)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "9"}, components.ExpressionAttributeValues[":v3"])
+
+	assert.Error(t, expr.NewBuilder().AddCappedListAppend("events", []string{"x"}, -1))
+}
+
 func TestComplexExpressions(t *testing.T) {
 	t.Run("nested attributes", func(t *testing.T) {
 		builder := expr.NewBuilder()
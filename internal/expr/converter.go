@@ -8,17 +8,15 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	dynamormtypes "github.com/pay-theory/dynamorm/pkg/types"
 )
 
 // Marshaler interface for custom marshaling
-type Marshaler interface {
-	MarshalDynamoDBAttributeValue() (types.AttributeValue, error)
-}
+type Marshaler = dynamormtypes.Marshaler
 
 // Unmarshaler interface for custom unmarshaling
-type Unmarshaler interface {
-	UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error
-}
+type Unmarshaler = dynamormtypes.Unmarshaler
 
 // ConvertToAttributeValue converts a Go value to a DynamoDB AttributeValue
 func ConvertToAttributeValue(value any) (types.AttributeValue, error) {
@@ -30,6 +28,9 @@ func ConvertToAttributeValue(value any) (types.AttributeValue, error) {
 	if marshaler, ok := value.(Marshaler); ok {
 		return marshaler.MarshalDynamoDBAttributeValue()
 	}
+	if av, ok, err := dynamormtypes.MarshalBigNumber(value); ok {
+		return av, err
+	}
 
 	v := reflect.ValueOf(value)
 
@@ -206,6 +207,9 @@ func ConvertFromAttributeValue(av types.AttributeValue, target any) error {
 	if unmarshaler, ok := target.(Unmarshaler); ok {
 		return unmarshaler.UnmarshalDynamoDBAttributeValue(av)
 	}
+	if ok, err := dynamormtypes.UnmarshalBigNumber(av, target); ok {
+		return err
+	}
 
 	targetElem := targetValue.Elem()
 	return unmarshalAttributeValue(av, targetElem)
@@ -0,0 +1,106 @@
+// Package itemsize computes DynamoDB item sizes using the service's sizing rules.
+package itemsize
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// MaxItemBytes is DynamoDB's maximum item size, including attribute names.
+const MaxItemBytes = 400 * 1024
+
+// Attribute is the size of one top-level attribute, name included.
+type Attribute struct {
+	Name  string
+	Bytes int
+}
+
+// Of returns the size of item in bytes.
+func Of(item map[string]types.AttributeValue) int {
+	total := 0
+	for name, value := range item {
+		total += len(name) + Value(value)
+	}
+	return total
+}
+
+// Largest returns the top-level attributes of item ordered from largest to smallest,
+// truncated to at most n entries.
+func Largest(item map[string]types.AttributeValue, n int) []Attribute {
+	attrs := make([]Attribute, 0, len(item))
+	for name, value := range item {
+		attrs = append(attrs, Attribute{Name: name, Bytes: len(name) + Value(value)})
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].Bytes != attrs[j].Bytes {
+			return attrs[i].Bytes > attrs[j].Bytes
+		}
+		return attrs[i].Name < attrs[j].Name
+	})
+	if n >= 0 && len(attrs) > n {
+		attrs = attrs[:n]
+	}
+	return attrs
+}
+
+// Value returns the size of a single attribute value in bytes. Lists and maps carry three
+// bytes of overhead plus one byte per element; numbers take roughly one byte per two
+// significant digits plus one.
+func Value(value types.AttributeValue) int {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return len(v.Value)
+	case *types.AttributeValueMemberN:
+		return numberSize(v.Value)
+	case *types.AttributeValueMemberB:
+		return len(v.Value)
+	case *types.AttributeValueMemberBOOL, *types.AttributeValueMemberNULL:
+		return 1
+	case *types.AttributeValueMemberSS:
+		total := 0
+		for _, s := range v.Value {
+			total += len(s)
+		}
+		return total
+	case *types.AttributeValueMemberNS:
+		total := 0
+		for _, n := range v.Value {
+			total += numberSize(n)
+		}
+		return total
+	case *types.AttributeValueMemberBS:
+		total := 0
+		for _, b := range v.Value {
+			total += len(b)
+		}
+		return total
+	case *types.AttributeValueMemberL:
+		total := 3
+		for _, elem := range v.Value {
+			total += 1 + Value(elem)
+		}
+		return total
+	case *types.AttributeValueMemberM:
+		total := 3
+		for name, elem := range v.Value {
+			total += 1 + len(name) + Value(elem)
+		}
+		return total
+	default:
+		return 0
+	}
+}
+
+func numberSize(n string) int {
+	digits := strings.TrimLeft(strings.TrimLeft(n, "-+"), "0.")
+	if i := strings.IndexAny(digits, "eE"); i >= 0 {
+		digits = digits[:i]
+	}
+	digits = strings.TrimRight(strings.ReplaceAll(digits, ".", ""), "0")
+	if digits == "" {
+		return 1
+	}
+	return (len(digits)+1)/2 + 1
+}
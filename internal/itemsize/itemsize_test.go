@@ -0,0 +1,43 @@
+package itemsize_test
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/internal/itemsize"
+)
+
+func TestValue(t *testing.T) {
+	require.Equal(t, 5, itemsize.Value(&types.AttributeValueMemberS{Value: "hello"}))
+	require.Equal(t, 3, itemsize.Value(&types.AttributeValueMemberB{Value: []byte{1, 2, 3}}))
+	require.Equal(t, 1, itemsize.Value(&types.AttributeValueMemberBOOL{Value: true}))
+	require.Equal(t, 1, itemsize.Value(&types.AttributeValueMemberNULL{Value: true}))
+
+	require.Equal(t, 1, itemsize.Value(&types.AttributeValueMemberN{Value: "0"}))
+	require.Equal(t, 2, itemsize.Value(&types.AttributeValueMemberN{Value: "100"}))
+	require.Equal(t, 3, itemsize.Value(&types.AttributeValueMemberN{Value: "-12.34"}))
+	require.Equal(t, 2, itemsize.Value(&types.AttributeValueMemberN{Value: "0.005"}))
+
+	require.Equal(t, 6, itemsize.Value(&types.AttributeValueMemberSS{Value: []string{"ab", "cdef"}}))
+	require.Equal(t, 3+1+2+1+1, itemsize.Value(&types.AttributeValueMemberL{Value: []types.AttributeValue{
+		&types.AttributeValueMemberS{Value: "ab"},
+		&types.AttributeValueMemberBOOL{Value: false},
+	}}))
+	require.Equal(t, 3+1+4+2, itemsize.Value(&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"name": &types.AttributeValueMemberS{Value: "jo"},
+	}}))
+}
+
+func TestOfAndLargest(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"id":   &types.AttributeValueMemberS{Value: "abc"},
+		"body": &types.AttributeValueMemberS{Value: "0123456789"},
+		"n":    &types.AttributeValueMemberN{Value: "7"},
+	}
+
+	require.Equal(t, 5+14+3, itemsize.Of(item))
+	require.Equal(t, []itemsize.Attribute{{Name: "body", Bytes: 14}, {Name: "id", Bytes: 5}}, itemsize.Largest(item, 2))
+	require.Len(t, itemsize.Largest(item, -1), 3)
+}
@@ -0,0 +1,191 @@
+package overflow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultThreshold is the value size, in bytes, above which attributes are offloaded
+	// when no threshold is configured.
+	DefaultThreshold = 64 * 1024
+
+	pointerMarker = "dynamormS3Overflow"
+
+	pointerKeyVersion = "v"
+	pointerKeyBucket  = "bucket"
+	pointerKeyKey     = "key"
+	pointerKeyKind    = "kind"
+	pointerKeySize    = "size"
+
+	pointerVersionV1 = "1"
+
+	kindString = "S"
+	kindBinary = "B"
+)
+
+type s3API interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
+// Service stores oversized string and binary attribute values as S3 objects and replaces
+// them with a small pointer map in the item.
+type Service struct {
+	s3        s3API
+	bucket    string
+	prefix    string
+	threshold int
+}
+
+func NewService(bucket, prefix string, threshold int, client s3API) *Service {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	return &Service{
+		s3:        client,
+		bucket:    bucket,
+		prefix:    prefix,
+		threshold: threshold,
+	}
+}
+
+// Offload uploads av to S3 and returns a pointer to it when av is a string or binary value
+// larger than the threshold. Any other value is returned unchanged.
+func (s *Service) Offload(ctx context.Context, tableName, attributeName string, av types.AttributeValue) (types.AttributeValue, error) {
+	var (
+		payload []byte
+		kind    string
+	)
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		payload, kind = []byte(v.Value), kindString
+	case *types.AttributeValueMemberB:
+		payload, kind = v.Value, kindBinary
+	default:
+		return av, nil
+	}
+	if len(payload) <= s.threshold {
+		return av, nil
+	}
+
+	key := path.Join(s.prefix, tableName, attributeName, uuid.NewString())
+	if _, err := s.s3.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(payload),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to upload %s to s3: %w", attributeName, err)
+	}
+
+	return &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		pointerMarker: &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			pointerKeyVersion: &types.AttributeValueMemberS{Value: pointerVersionV1},
+			pointerKeyBucket:  &types.AttributeValueMemberS{Value: s.bucket},
+			pointerKeyKey:     &types.AttributeValueMemberS{Value: key},
+			pointerKeyKind:    &types.AttributeValueMemberS{Value: kind},
+			pointerKeySize:    &types.AttributeValueMemberN{Value: strconv.Itoa(len(payload))},
+		}},
+	}}, nil
+}
+
+// Rehydrate downloads the object referenced by a pointer written by Offload. Values that
+// are not pointers are returned unchanged, so attributes stored inline read as before.
+func (s *Service) Rehydrate(ctx context.Context, av types.AttributeValue) (types.AttributeValue, error) {
+	ptr, ok := pointerFields(av)
+	if !ok {
+		return av, nil
+	}
+	if version := stringField(ptr, pointerKeyVersion); version != pointerVersionV1 {
+		return nil, fmt.Errorf("unsupported s3 overflow pointer version %q", version)
+	}
+
+	out, err := s.s3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(stringField(ptr, pointerKeyBucket)),
+		Key:    aws.String(stringField(ptr, pointerKeyKey)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download s3 overflow object %s: %w", stringField(ptr, pointerKeyKey), err)
+	}
+	defer out.Body.Close()
+
+	payload, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read s3 overflow object %s: %w", stringField(ptr, pointerKeyKey), err)
+	}
+
+	switch kind := stringField(ptr, pointerKeyKind); kind {
+	case kindString:
+		return &types.AttributeValueMemberS{Value: string(payload)}, nil
+	case kindBinary:
+		return &types.AttributeValueMemberB{Value: payload}, nil
+	default:
+		return nil, fmt.Errorf("unsupported s3 overflow value kind %q", kind)
+	}
+}
+
+// Delete removes the object referenced by a pointer written by Offload and returns its
+// bucket and key. Values that are not pointers are ignored.
+func (s *Service) Delete(ctx context.Context, av types.AttributeValue) (bucket, key string, err error) {
+	ptr, ok := pointerFields(av)
+	if !ok {
+		return "", "", nil
+	}
+	bucket, key = stringField(ptr, pointerKeyBucket), stringField(ptr, pointerKeyKey)
+	if _, err := s.s3.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		return bucket, key, fmt.Errorf("failed to delete s3 overflow object %s: %w", key, err)
+	}
+	return bucket, key, nil
+}
+
+// SameObject reports whether a and b are pointers to the same object.
+func SameObject(a, b types.AttributeValue) bool {
+	pa, ok := pointerFields(a)
+	if !ok {
+		return false
+	}
+	pb, ok := pointerFields(b)
+	if !ok {
+		return false
+	}
+	return stringField(pa, pointerKeyBucket) == stringField(pb, pointerKeyBucket) &&
+		stringField(pa, pointerKeyKey) == stringField(pb, pointerKeyKey)
+}
+
+// IsPointer reports whether av is a pointer written by Offload.
+func IsPointer(av types.AttributeValue) bool {
+	_, ok := pointerFields(av)
+	return ok
+}
+
+func pointerFields(av types.AttributeValue) (map[string]types.AttributeValue, bool) {
+	m, ok := av.(*types.AttributeValueMemberM)
+	if !ok || len(m.Value) != 1 {
+		return nil, false
+	}
+	inner, ok := m.Value[pointerMarker].(*types.AttributeValueMemberM)
+	if !ok {
+		return nil, false
+	}
+	return inner.Value, true
+}
+
+func stringField(values map[string]types.AttributeValue, name string) string {
+	if s, ok := values[name].(*types.AttributeValueMemberS); ok {
+		return s.Value
+	}
+	return ""
+}
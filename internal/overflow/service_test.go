@@ -0,0 +1,109 @@
+package overflow
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeS3 struct {
+	objects map[string][]byte
+}
+
+func (f *fakeS3) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	if f.objects == nil {
+		f.objects = make(map[string][]byte)
+	}
+	f.objects[aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key)] = body
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	body := f.objects[aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key)]
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(body))}, nil
+}
+
+func (f *fakeS3) DeleteObject(_ context.Context, params *s3.DeleteObjectInput, _ ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	delete(f.objects, aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func TestService_DeleteRemovesPointedObject(t *testing.T) {
+	client := &fakeS3{}
+	svc := NewService("blobs", "", 4, client)
+
+	ptr, err := svc.Offload(context.Background(), "documents", "body", &types.AttributeValueMemberS{Value: "payload"})
+	require.NoError(t, err)
+	other, err := svc.Offload(context.Background(), "documents", "body", &types.AttributeValueMemberS{Value: "payload"})
+	require.NoError(t, err)
+	require.True(t, SameObject(ptr, ptr))
+	require.False(t, SameObject(ptr, other))
+	require.Len(t, client.objects, 2)
+
+	bucket, key, err := svc.Delete(context.Background(), ptr)
+	require.NoError(t, err)
+	require.Equal(t, "blobs", bucket)
+	require.NotEmpty(t, key)
+	require.Len(t, client.objects, 1)
+
+	bucket, key, err = svc.Delete(context.Background(), &types.AttributeValueMemberS{Value: "inline"})
+	require.NoError(t, err)
+	require.Empty(t, bucket)
+	require.Empty(t, key)
+	require.Len(t, client.objects, 1)
+}
+
+func TestService_OffloadAndRehydrate(t *testing.T) {
+	client := &fakeS3{}
+	svc := NewService("blobs", "docs", 8, client)
+
+	small := &types.AttributeValueMemberS{Value: "tiny"}
+	out, err := svc.Offload(context.Background(), "documents", "body", small)
+	require.NoError(t, err)
+	require.Same(t, small, out)
+	require.Empty(t, client.objects)
+
+	large := &types.AttributeValueMemberS{Value: strings.Repeat("x", 32)}
+	ptr, err := svc.Offload(context.Background(), "documents", "body", large)
+	require.NoError(t, err)
+	require.True(t, IsPointer(ptr))
+	require.Len(t, client.objects, 1)
+	for key := range client.objects {
+		require.True(t, strings.HasPrefix(key, "blobs/docs/documents/body/"), key)
+	}
+
+	restored, err := svc.Rehydrate(context.Background(), ptr)
+	require.NoError(t, err)
+	require.Equal(t, large, restored)
+
+	binary := &types.AttributeValueMemberB{Value: bytes.Repeat([]byte{1}, 16)}
+	ptr, err = svc.Offload(context.Background(), "documents", "raw", binary)
+	require.NoError(t, err)
+	restored, err = svc.Rehydrate(context.Background(), ptr)
+	require.NoError(t, err)
+	require.Equal(t, binary, restored)
+}
+
+func TestService_RehydrateLeavesInlineValues(t *testing.T) {
+	svc := NewService("blobs", "", 0, &fakeS3{})
+	require.Equal(t, DefaultThreshold, svc.threshold)
+
+	inline := &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"other": &types.AttributeValueMemberS{Value: "v"},
+	}}
+	out, err := svc.Rehydrate(context.Background(), inline)
+	require.NoError(t, err)
+	require.Same(t, inline, out)
+	require.False(t, IsPointer(inline))
+}
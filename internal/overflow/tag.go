@@ -0,0 +1,40 @@
+package overflow
+
+import (
+	"fmt"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+func MetadataHasOverflowFields(metadata *model.Metadata) bool {
+	if metadata == nil {
+		return false
+	}
+	for _, fieldMeta := range metadata.Fields {
+		if fieldMeta != nil && fieldMeta.IsS3Overflow() {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceForSession returns the overflow service configured on sess, failing when the model
+// uses dynamorm:"s3overflow" fields and no bucket is set. The S3 client is the session's,
+// so it is built once per session rather than per call.
+func ServiceForSession(sess *session.Session, metadata *model.Metadata) (*Service, error) {
+	var cfg *session.Config
+	if sess != nil {
+		cfg = sess.Config()
+	}
+	if cfg == nil || cfg.S3OverflowBucket == "" {
+		name := ""
+		if metadata != nil && metadata.Type != nil {
+			name = metadata.Type.Name()
+		}
+		return nil, fmt.Errorf("%w: model %s contains dynamorm:\"s3overflow\" fields but session.Config.S3OverflowBucket is empty", customerrors.ErrOverflowNotConfigured, name)
+	}
+
+	return NewService(cfg.S3OverflowBucket, cfg.S3OverflowPrefix, cfg.S3OverflowThreshold, sess.S3Client()), nil
+}
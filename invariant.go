@@ -0,0 +1,243 @@
+package dynamorm
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// Invariant declares a rule over a group of items, such as "a project's allocations sum
+// to at most its quota", that a transaction must preserve:
+//
+//	allocations := dynamorm.Invariant[Allocation]{
+//		Name: "allocations within quota",
+//		Group: func(q *dynamorm.Query[Allocation]) *dynamorm.Query[Allocation] {
+//			return q.Where("ProjectID", "=", project.ID)
+//		},
+//		Rule:   dynamorm.SumAtMost[Allocation]("Amount", project.Quota),
+//		Anchor: &project,
+//	}
+//
+//	err := db.AtomicTransaction(func(tx *core.Tx) error {
+//		if err := tx.Create(&allocation); err != nil {
+//			return err
+//		}
+//		return dynamorm.EnforceInvariant(tx, allocations, &allocation)
+//	})
+type Invariant[T any] struct {
+	// Group narrows the query selecting the items the rule covers.
+	Group func(q *Query[T]) *Query[T]
+	// Anchor, when set, is a pointer to an item read before the transaction, such as
+	// the parent project, whose version field the transaction bumps. Concurrent
+	// transactions adding items to the group then conflict on the anchor; without it,
+	// only changes to items already in the group are detected.
+	Anchor any
+	// Name identifies the invariant in errors.
+	Name string
+	// Rule checks the group as it will be after the transaction.
+	Rule InvariantRule[T]
+}
+
+// InvariantRule checks a group of items. Field names the field the rule reads, pinned
+// on models without a version field; it may be empty for rules that only count items.
+type InvariantRule[T any] struct {
+	Check func(items []T) error
+	Field string
+}
+
+// SumAtMost is a rule that the numeric field summed over the group is at most limit.
+func SumAtMost[T any](field string, limit float64) InvariantRule[T] {
+	return InvariantRule[T]{
+		Field: field,
+		Check: func(items []T) error {
+			var sum float64
+			for i := range items {
+				value, err := numericField(reflect.ValueOf(&items[i]).Elem(), field)
+				if err != nil {
+					return err
+				}
+				sum += value
+			}
+			if sum > limit {
+				return fmt.Errorf("sum of %s is %v, over the limit of %v", field, sum, limit)
+			}
+			return nil
+		},
+	}
+}
+
+// CountAtMost is a rule that the group has at most limit items.
+func CountAtMost[T any](limit int) InvariantRule[T] {
+	return InvariantRule[T]{
+		Check: func(items []T) error {
+			if len(items) > limit {
+				return fmt.Errorf("group has %d items, over the limit of %d", len(items), limit)
+			}
+			return nil
+		},
+	}
+}
+
+func numericField(v reflect.Value, field string) (float64, error) {
+	f := v.FieldByName(field)
+	if !f.IsValid() {
+		return 0, fmt.Errorf("%s has no field %s", v.Type(), field)
+	}
+	for f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return 0, nil
+		}
+		f = f.Elem()
+	}
+	switch f.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(f.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(f.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return f.Float(), nil
+	default:
+		return 0, fmt.Errorf("field %s is %s, not numeric", field, f.Type())
+	}
+}
+
+// EnforceInvariant reads the items inv covers, replaces those in changes by primary key
+// and adds the rest, and checks inv.Rule against the result. It returns an error
+// wrapping errors.ErrInvariantViolated when the rule fails. Otherwise it queues a
+// ConditionCheck on tx for every item read that changes does not write, pinning its
+// version field (or the rule's field), so a concurrent change to the group cancels the
+// transaction instead of committing a write skew.
+//
+// changes are the items tx puts or updates in the group; their own writes should carry
+// AtVersion conditions. Deletes only loosen SumAtMost and CountAtMost and need not be
+// passed. tx must come from AtomicTransaction.
+func EnforceInvariant[T any](tx *core.Tx, inv Invariant[T], changes ...*T) error {
+	if inv.Rule.Check == nil {
+		return fmt.Errorf("invariant %q has no rule", inv.Name)
+	}
+
+	registry := model.NewRegistry()
+	if err := registry.Register(new(T)); err != nil {
+		return err
+	}
+	metadata, err := registry.GetMetadata(new(T))
+	if err != nil {
+		return err
+	}
+
+	q := &Query[T]{q: tx.Model(new(T))}
+	if inv.Group != nil {
+		q = inv.Group(q)
+	}
+	current, err := q.All()
+	if err != nil {
+		return fmt.Errorf("invariant %q: failed to read group: %w", inv.Name, err)
+	}
+
+	changed := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		if change != nil {
+			changed[invariantItemKey(metadata, reflect.ValueOf(change).Elem())] = true
+		}
+	}
+	after := make([]T, 0, len(current)+len(changes))
+	var unchanged []T
+	for _, item := range current {
+		if !changed[invariantItemKey(metadata, reflect.ValueOf(&item).Elem())] {
+			after = append(after, item)
+			unchanged = append(unchanged, item)
+		}
+	}
+	for _, change := range changes {
+		if change != nil {
+			after = append(after, *change)
+		}
+	}
+
+	if err := inv.Rule.Check(after); err != nil {
+		return fmt.Errorf("%w: %s: %w", customerrors.ErrInvariantViolated, inv.Name, err)
+	}
+
+	for i := range unchanged {
+		pin, err := invariantPin(metadata, reflect.ValueOf(&unchanged[i]).Elem(), inv.Rule.Field)
+		if err != nil {
+			return fmt.Errorf("invariant %q: %w", inv.Name, err)
+		}
+		if err := tx.ConditionCheck(&unchanged[i], pin...); err != nil {
+			return err
+		}
+	}
+	if inv.Anchor != nil {
+		return bumpAnchorVersion(tx, registry, inv.Anchor)
+	}
+	return nil
+}
+
+// invariantItemKey identifies an item by its primary key values.
+func invariantItemKey(metadata *model.Metadata, v reflect.Value) string {
+	if metadata.PrimaryKey == nil || metadata.PrimaryKey.PartitionKey == nil {
+		return ""
+	}
+	key := fmt.Sprintf("%v", v.FieldByIndex(metadata.PrimaryKey.PartitionKey.IndexPath).Interface())
+	if sk := metadata.PrimaryKey.SortKey; sk != nil {
+		key += "\x00" + fmt.Sprintf("%v", v.FieldByIndex(sk.IndexPath).Interface())
+	}
+	return key
+}
+
+// invariantPin returns the conditions holding an item at the state it was read in.
+func invariantPin(metadata *model.Metadata, v reflect.Value, field string) ([]core.TransactCondition, error) {
+	if metadata.VersionField != nil {
+		version := v.FieldByIndex(metadata.VersionField.IndexPath)
+		return []core.TransactCondition{AtVersion(reflectInt(version))}, nil
+	}
+	if field == "" {
+		return []core.TransactCondition{IfExists()}, nil
+	}
+	f := v.FieldByName(field)
+	if !f.IsValid() {
+		return nil, fmt.Errorf("%s has no field %s", v.Type(), field)
+	}
+	return []core.TransactCondition{Condition(field, "=", f.Interface())}, nil
+}
+
+// bumpAnchorVersion queues an update incrementing the anchor's version, conditioned on
+// the version it was read at.
+func bumpAnchorVersion(tx *core.Tx, registry *model.Registry, anchor any) error {
+	v := reflect.ValueOf(anchor)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("invariant anchor must be a pointer to a struct, got %T", anchor)
+	}
+	if err := registry.Register(anchor); err != nil {
+		return err
+	}
+	metadata, err := registry.GetMetadata(anchor)
+	if err != nil {
+		return err
+	}
+	if metadata.VersionField == nil {
+		return fmt.Errorf("invariant anchor %T must have a version field", anchor)
+	}
+
+	field := v.Elem().FieldByIndex(metadata.VersionField.IndexPath)
+	read := reflectInt(field)
+	switch field.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(read + 1))
+	default:
+		field.SetInt(read + 1)
+	}
+	return tx.UpdateWithConditions(anchor, []string{metadata.VersionField.Name}, AtVersion(read))
+}
+
+func reflectInt(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	default:
+		return v.Int()
+	}
+}
@@ -0,0 +1,121 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+type invariantProject struct {
+	ID      string `dynamorm:"pk,attr:id"`
+	Quota   int64  `dynamorm:"attr:quota"`
+	Version int64  `dynamorm:"version,attr:version"`
+}
+
+type invariantAllocation struct {
+	ProjectID string `dynamorm:"pk,attr:projectId"`
+	ID        string `dynamorm:"sk,attr:id"`
+	Amount    int64  `dynamorm:"attr:amount"`
+	Version   int64  `dynamorm:"version,attr:version"`
+}
+
+const allocationsResponse = `{"Items":[` +
+	`{"projectId":{"S":"p1"},"id":{"S":"a1"},"amount":{"N":"40"},"version":{"N":"3"}},` +
+	`{"projectId":{"S":"p1"},"id":{"S":"a2"},"amount":{"N":"30"},"version":{"N":"1"}}` +
+	`],"Count":2,"ScannedCount":2}`
+
+func allocationInvariant(project *invariantProject) Invariant[invariantAllocation] {
+	return Invariant[invariantAllocation]{
+		Name: "allocations within quota",
+		Group: func(q *Query[invariantAllocation]) *Query[invariantAllocation] {
+			return q.Where("ProjectID", "=", project.ID)
+		},
+		Rule:   SumAtMost[invariantAllocation]("Amount", float64(project.Quota)),
+		Anchor: project,
+	}
+}
+
+func transactItems(t *testing.T, httpClient *capturingHTTPClient) []map[string]any {
+	t.Helper()
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.TransactWriteItems")
+	require.NotNil(t, req)
+	raw, ok := req.Payload["TransactItems"].([]any)
+	require.True(t, ok)
+	items := make([]map[string]any, len(raw))
+	for i, item := range raw {
+		items[i], ok = item.(map[string]any)
+		require.True(t, ok)
+	}
+	return items
+}
+
+func TestEnforceInvariant_PinsGroupAndAnchor(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": allocationsResponse,
+	})
+	db := newStubbedDB(t, httpClient)
+	project := &invariantProject{ID: "p1", Quota: 100, Version: 7}
+
+	allocation := &invariantAllocation{ProjectID: "p1", ID: "a3", Amount: 30}
+	require.NoError(t, db.AtomicTransaction(func(tx *core.Tx) error {
+		if err := tx.Create(allocation); err != nil {
+			return err
+		}
+		return EnforceInvariant(tx, allocationInvariant(project), allocation)
+	}))
+
+	items := transactItems(t, httpClient)
+	require.Len(t, items, 4)
+	require.Contains(t, items[0], "Put")
+	for _, item := range items[1:3] {
+		check, ok := item["ConditionCheck"].(map[string]any)
+		require.True(t, ok)
+		require.Equal(t, map[string]any{"#n1": "version"}, check["ExpressionAttributeNames"])
+	}
+	update, ok := items[3]["Update"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"id": map[string]any{"S": "p1"}}, update["Key"])
+	require.Equal(t, int64(8), project.Version)
+}
+
+func TestEnforceInvariant_RejectsViolationAndReplacesChangedItems(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": allocationsResponse,
+	})
+	db := newStubbedDB(t, httpClient)
+	project := &invariantProject{ID: "p1", Quota: 100, Version: 7}
+
+	err := db.AtomicTransaction(func(tx *core.Tx) error {
+		allocation := &invariantAllocation{ProjectID: "p1", ID: "a3", Amount: 31}
+		if err := tx.Create(allocation); err != nil {
+			return err
+		}
+		return EnforceInvariant(tx, allocationInvariant(project), allocation)
+	})
+	require.ErrorIs(t, err, customerrors.ErrInvariantViolated)
+	require.ErrorContains(t, err, "sum of Amount is 101")
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.TransactWriteItems"))
+
+	// Raising a1 from 40 to 70 replaces it in the group: 70 + 30 fits the quota.
+	inv := allocationInvariant(project)
+	inv.Anchor = nil
+	require.NoError(t, db.AtomicTransaction(func(tx *core.Tx) error {
+		raised := &invariantAllocation{ProjectID: "p1", ID: "a1", Amount: 70, Version: 4}
+		if err := tx.UpdateWithConditions(raised, []string{"Amount"}, AtVersion(3)); err != nil {
+			return err
+		}
+		return EnforceInvariant(tx, inv, raised)
+	}))
+	items := transactItems(t, httpClient)
+	require.Len(t, items, 2, "the update plus one check for a2")
+	require.Contains(t, items[1], "ConditionCheck")
+}
+
+func TestCountAtMost(t *testing.T) {
+	rule := CountAtMost[invariantAllocation](2)
+	require.NoError(t, rule.Check(make([]invariantAllocation, 2)))
+	require.ErrorContains(t, rule.Check(make([]invariantAllocation, 3)), "3 items")
+}
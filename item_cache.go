@@ -0,0 +1,345 @@
+package dynamorm
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/internal/encryption"
+	"github.com/pay-theory/dynamorm/pkg/cache"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/query"
+)
+
+// itemCacheKeyPrefix namespaces DynamORM's entries in a shared cache.
+const itemCacheKeyPrefix = "dynamorm:item:"
+
+// itemCacheGenerationPrefix namespaces the generation stamps of items cached per tenant or
+// per assumed role. Writes delete an item's stamp, which retires every scoped copy at once.
+const itemCacheGenerationPrefix = "dynamorm:gen:"
+
+// itemCacheMiss is stored in place of an item a read did not find, under a model's
+// negative caching policy. Stored items are DynamoDB JSON objects, so it cannot clash.
+var itemCacheMiss = []byte("null")
+
+// itemCache is the read-through cache configured by WithItemCache.
+type itemCache struct {
+	opts cache.Options
+}
+
+// WithItemCache returns a DB whose GetItem reads go through a read-through cache:
+//
+//	cached := db.WithItemCache(cache.Options{Store: cache.NewLRU(10_000), TTL: time.Minute})
+//
+// Items are served from opts.Store when cached and stored after a miss for the table's
+// TTL, or as the model's cache policy tag declares. Puts, updates, deletes, batch writes, and transactions made through the returned
+// DB delete the items they touch. ConsistentRead requests skip the lookup but refresh the
+// cached item; projected reads and models with encrypted fields are never cached.
+// Passing Options without a Store turns the cache off.
+func (db *DB) WithItemCache(opts cache.Options) core.ExtendedDB {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	newDB := db.derive()
+	newDB.itemCache = nil
+	if opts.Store != nil {
+		newDB.itemCache = &itemCache{opts: opts}
+	}
+	return newDB
+}
+
+// itemCacheConfig returns the DB's item cache, or nil when it has none.
+func (db *DB) itemCacheConfig() *itemCache {
+	if db == nil {
+		return nil
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.itemCache
+}
+
+func (c *itemCache) report(err error) {
+	if err != nil && c.opts.OnError != nil {
+		c.opts.OnError(err)
+	}
+}
+
+// itemCacheKey returns the cache key for the item under key in table, as seen by
+// tenant when the table's cache is tenant-scoped. Keys hold only scalar attributes; any
+// other value makes the item uncacheable.
+func itemCacheKey(table, tenant, principal string, key map[string]types.AttributeValue) (string, bool) {
+	if len(key) == 0 {
+		return "", false
+	}
+	names := make([]string, 0, len(key))
+	for name := range key {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, 5+3*len(names))
+	parts = append(parts, table)
+	if tenant != "" {
+		// Two parts keep the count off a multiple of three, apart from unscoped keys.
+		parts = append(parts, "tenant", tenant)
+	}
+	if principal != "" {
+		parts = append(parts, "principal", principal)
+	}
+	for _, name := range names {
+		switch v := key[name].(type) {
+		case *types.AttributeValueMemberS:
+			parts = append(parts, name, "S", v.Value)
+		case *types.AttributeValueMemberN:
+			parts = append(parts, name, "N", v.Value)
+		case *types.AttributeValueMemberB:
+			parts = append(parts, name, "B", base64.StdEncoding.EncodeToString(v.Value))
+		default:
+			return "", false
+		}
+	}
+	encoded, err := json.Marshal(parts)
+	if err != nil {
+		return "", false
+	}
+	return itemCacheKeyPrefix + string(encoded), true
+}
+
+// itemCacheGenerationKey returns the key of the generation stamp of the item under key
+// in table, shared by the copies of every tenant and principal.
+func itemCacheGenerationKey(table string, key map[string]types.AttributeValue) (string, bool) {
+	cacheKey, ok := itemCacheKey(table, "", "", key)
+	if !ok {
+		return "", false
+	}
+	return itemCacheGenerationPrefix + strings.TrimPrefix(cacheKey, itemCacheKeyPrefix), true
+}
+
+// generation returns the generation stamp stored under genKey, starting a new one when
+// there is none. Scoped copies are stored with the stamp current when they
+// were read and only served while it still is.
+func (c *itemCache) generation(ctx context.Context, genKey string, ttl time.Duration) (string, error) {
+	data, found, err := c.opts.Store.Get(ctx, genKey)
+	if err != nil {
+		return "", err
+	}
+	if found && len(data) > 0 {
+		return string(data), nil
+	}
+
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	generation := hex.EncodeToString(b[:])
+	return generation, c.opts.Store.Set(ctx, genKey, []byte(generation), ttl)
+}
+
+// stampCacheEntry prefixes data with generation, when the entry has one.
+func stampCacheEntry(generation string, data []byte) []byte {
+	if generation == "" {
+		return data
+	}
+	return append([]byte(generation+":"), data...)
+}
+
+// unstampCacheEntry returns the data of an entry stamped with generation, reporting false
+// for entries of another generation.
+func unstampCacheEntry(generation string, entry []byte) ([]byte, bool) {
+	if generation == "" {
+		return entry, true
+	}
+	return bytes.CutPrefix(entry, []byte(generation+":"))
+}
+
+// cachedGetItem returns the item under key, from the item cache when it holds it and
+// from fetch otherwise, storing what fetch finds. Cache failures are reported and fall
+// back to fetch.
+func (qe *queryExecutor) cachedGetItem(input *core.CompiledQuery, key map[string]types.AttributeValue, fetch func() (map[string]types.AttributeValue, error)) (map[string]types.AttributeValue, error) {
+	c := qe.db.itemCacheConfig()
+	if c == nil || qe.db.unknownPrincipal || input.ProjectionExpression != "" || encryption.MetadataHasEncryptedFields(qe.metadata) {
+		return fetch()
+	}
+	ctx := qe.ctxOrBackground()
+	var policy *cache.Policy
+	if qe.metadata != nil {
+		policy = qe.metadata.CachePolicy
+	}
+	ttl := c.opts.PolicyTTL(input.TableName, policy)
+	tenant, scoped := cacheTenant(ctx, policy)
+	cacheKey, ok := itemCacheKey(input.TableName, tenant, qe.db.principal, key)
+	if ttl <= 0 || !scoped || !ok {
+		return fetch()
+	}
+	var generation string
+	if tenant != "" || qe.db.principal != "" {
+		genKey, _ := itemCacheGenerationKey(input.TableName, key)
+		gen, err := c.generation(ctx, genKey, ttl)
+		if err != nil {
+			c.report(err)
+			return fetch()
+		}
+		generation = gen
+	}
+
+	if !aws.ToBool(input.ConsistentRead) {
+		data, found, err := c.opts.Store.Get(ctx, cacheKey)
+		c.report(err)
+		if found {
+			data, found = unstampCacheEntry(generation, data)
+		}
+		if err == nil && found {
+			if bytes.Equal(data, itemCacheMiss) {
+				qe.recordCache(core.CacheHit)
+				return nil, nil
+			}
+			item, err := query.UnmarshalItemJSON(data)
+			if err == nil {
+				qe.recordCache(core.CacheHit)
+				return item, nil
+			}
+			c.report(err)
+		}
+		qe.recordCache(core.CacheMiss)
+	}
+
+	item, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		if policy != nil && policy.NegativeTTL > 0 {
+			c.report(c.opts.Store.Set(ctx, cacheKey, stampCacheEntry(generation, itemCacheMiss), min(policy.NegativeTTL, ttl)))
+		}
+		return nil, nil
+	}
+	data, err := query.MarshalItemJSON(item)
+	if err == nil {
+		err = c.opts.Store.Set(ctx, cacheKey, stampCacheEntry(generation, data), ttl)
+	}
+	c.report(err)
+	return item, nil
+}
+
+// recordCache notes the item cache's outcome on the operation middleware sees.
+func (qe *queryExecutor) recordCache(outcome string) {
+	if qe.op != nil {
+		qe.op.Cache = outcome
+	}
+}
+
+// cacheTenant returns the tenant whose copies of items a tenant-scoped policy reads
+// and writes, reporting false when the policy needs a tenant and ctx has none.
+func cacheTenant(ctx context.Context, policy *cache.Policy) (string, bool) {
+	if policy == nil || policy.Scope != cache.ScopeTenant {
+		return "", true
+	}
+	tenant := cache.TenantFromContext(ctx)
+	return tenant, tenant != ""
+}
+
+// invalidateItems deletes the cached copies of items, which may be whole items or keys,
+// from table. Copies cached per tenant or assumed role are retired with their generation
+// stamp, so a write made for one tenant or role, or for none, invalidates every copy.
+func (db *DB) invalidateItems(ctx context.Context, table string, items ...map[string]types.AttributeValue) {
+	c := db.itemCacheConfig()
+	if c == nil || len(items) == 0 {
+		return
+	}
+
+	var partitionKey, sortKey string
+	if metadata, err := db.registry.GetMetadataByTable(table); err == nil && metadata.PrimaryKey != nil {
+		if metadata.PrimaryKey.PartitionKey != nil {
+			partitionKey = metadata.PrimaryKey.PartitionKey.DBName
+		}
+		if metadata.PrimaryKey.SortKey != nil {
+			sortKey = metadata.PrimaryKey.SortKey.DBName
+		}
+	}
+
+	keys := make([]string, 0, 2*len(items))
+	for _, item := range items {
+		key := item
+		if partitionKey != "" {
+			key = map[string]types.AttributeValue{partitionKey: item[partitionKey]}
+			if sortKey != "" {
+				key[sortKey] = item[sortKey]
+			}
+		}
+		if cacheKey, ok := itemCacheKey(table, "", "", key); ok {
+			keys = append(keys, cacheKey)
+		}
+		if genKey, ok := itemCacheGenerationKey(table, key); ok {
+			keys = append(keys, genKey)
+		}
+	}
+	if len(keys) > 0 {
+		c.report(c.opts.Store.Delete(ctx, keys...))
+	}
+}
+
+// invalidateTransactItems deletes the cached copies of the items a transaction wrote.
+func (db *DB) invalidateTransactItems(ctx context.Context, items []types.TransactWriteItem) {
+	for _, item := range items {
+		switch {
+		case item.Put != nil:
+			db.invalidateItems(ctx, aws.ToString(item.Put.TableName), item.Put.Item)
+		case item.Update != nil:
+			db.invalidateItems(ctx, aws.ToString(item.Update.TableName), item.Update.Key)
+		case item.Delete != nil:
+			db.invalidateItems(ctx, aws.ToString(item.Delete.TableName), item.Delete.Key)
+		}
+	}
+}
+
+// invalidatingWriteClient deletes cached copies of the items it writes once DynamoDB
+// responds, whether or not the write succeeded.
+type invalidatingWriteClient struct {
+	client writeClient
+	db     *DB
+}
+
+func (c *invalidatingWriteClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	out, err := c.client.PutItem(ctx, params, optFns...)
+	c.db.invalidateItems(ctx, aws.ToString(params.TableName), params.Item)
+	return out, err
+}
+
+func (c *invalidatingWriteClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	out, err := c.client.UpdateItem(ctx, params, optFns...)
+	c.db.invalidateItems(ctx, aws.ToString(params.TableName), params.Key)
+	return out, err
+}
+
+func (c *invalidatingWriteClient) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	out, err := c.client.DeleteItem(ctx, params, optFns...)
+	c.db.invalidateItems(ctx, aws.ToString(params.TableName), params.Key)
+	return out, err
+}
+
+func (c *invalidatingWriteClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	out, err := c.client.BatchWriteItem(ctx, params, optFns...)
+	for table, requests := range params.RequestItems {
+		items := make([]map[string]types.AttributeValue, 0, len(requests))
+		for _, req := range requests {
+			switch {
+			case req.PutRequest != nil:
+				items = append(items, req.PutRequest.Item)
+			case req.DeleteRequest != nil:
+				items = append(items, req.DeleteRequest.Key)
+			}
+		}
+		c.db.invalidateItems(ctx, table, items...)
+	}
+	return out, err
+}
@@ -0,0 +1,222 @@
+package dynamorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/cache"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+const cachedAccountResponse = `{"Item":{"id":{"S":"a1"},"balance":{"N":"10"},"version":{"N":"1"}}}`
+
+func getItemCalls(httpClient *capturingHTTPClient) int {
+	return countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.GetItem")
+}
+
+func TestItemCache_ServesRepeatReadsFromStore(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": cachedAccountResponse,
+	})
+	db := newStubbedDB(t, httpClient)
+	store := cache.NewLRU(16)
+	cached := db.WithItemCache(cache.Options{Store: store, TTL: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		var account testAccount
+		require.NoError(t, cached.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+		require.Equal(t, testAccount{ID: "a1", Balance: 10, Version: 1}, account)
+	}
+	require.Equal(t, 1, getItemCalls(httpClient))
+	require.Equal(t, 1, store.Len())
+
+	var account testAccount
+	require.NoError(t, db.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+	require.Equal(t, 2, getItemCalls(httpClient), "the DB without a cache reads DynamoDB")
+}
+
+func TestItemCache_RecordsOutcomeForMiddleware(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": cachedAccountResponse,
+	})
+	db := newStubbedDB(t, httpClient)
+	var outcomes []string
+	db.Use(func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) error {
+			err := next(ctx, op)
+			outcomes = append(outcomes, op.Cache)
+			return err
+		}
+	})
+	cached := db.WithItemCache(cache.Options{Store: cache.NewLRU(16)})
+
+	var account testAccount
+	require.NoError(t, cached.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+	require.NoError(t, cached.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+	require.NoError(t, cached.Model(&testAccount{}).Where("ID", "=", "a1").ConsistentRead().First(&account))
+	require.NoError(t, db.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+	require.Equal(t, []string{CacheMiss, CacheHit, "", ""}, outcomes)
+}
+
+func TestItemCache_ConsistentReadSkipsLookup(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": cachedAccountResponse,
+	})
+	db := newStubbedDB(t, httpClient)
+	cached := db.WithItemCache(cache.Options{Store: cache.NewLRU(16)})
+
+	var account testAccount
+	require.NoError(t, cached.Model(&testAccount{}).Where("ID", "=", "a1").ConsistentRead().First(&account))
+	require.NoError(t, cached.Model(&testAccount{}).Where("ID", "=", "a1").ConsistentRead().First(&account))
+	require.Equal(t, 2, getItemCalls(httpClient))
+
+	require.NoError(t, cached.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+	require.Equal(t, 2, getItemCalls(httpClient), "consistent reads refresh the cache")
+}
+
+func TestItemCache_WritesInvalidate(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": cachedAccountResponse,
+	})
+	db := newStubbedDB(t, httpClient)
+	cached := db.WithItemCache(cache.Options{Store: cache.NewLRU(16)})
+	read := func() {
+		t.Helper()
+		var account testAccount
+		require.NoError(t, cached.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+	}
+
+	read()
+	require.NoError(t, cached.Model(&testAccount{ID: "a1", Balance: 20}).CreateOrUpdate())
+	read()
+	require.Equal(t, 2, getItemCalls(httpClient), "PutItem invalidates")
+
+	read()
+	require.NoError(t, cached.Model(&testAccount{}).Where("ID", "=", "a1").Delete())
+	read()
+	require.Equal(t, 3, getItemCalls(httpClient), "DeleteItem invalidates")
+
+	require.NoError(t, cached.TransactWrite(context.Background(), func(tx core.TransactionBuilder) error {
+		tx.Delete(&testAccount{ID: "a1", Version: 1})
+		return nil
+	}))
+	read()
+	require.Equal(t, 4, getItemCalls(httpClient), "transactions invalidate")
+}
+
+func TestItemCache_TableTTLAndStoreErrors(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": cachedAccountResponse,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	disabled := db.WithItemCache(cache.Options{
+		Store:     cache.NewLRU(16),
+		TableTTLs: map[string]time.Duration{"testAccounts": 0},
+	})
+	var account testAccount
+	require.NoError(t, disabled.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+	require.NoError(t, disabled.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+	require.Equal(t, 2, getItemCalls(httpClient))
+
+	var reported []error
+	failing := db.WithItemCache(cache.Options{
+		Store:   failingStore{},
+		OnError: func(err error) { reported = append(reported, err) },
+	})
+	require.NoError(t, failing.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+	require.Equal(t, int64(10), account.Balance)
+	require.Equal(t, 3, getItemCalls(httpClient))
+	require.Len(t, reported, 2, "the failed lookup and the failed store are reported")
+}
+
+type tenantCachedAccount struct {
+	_       struct{} `dynamorm:"cache:ttl=30s,scope=tenant,negative=1m"`
+	ID      string   `dynamorm:"pk,attr:id"`
+	Balance int64    `dynamorm:"attr:balance"`
+}
+
+func (tenantCachedAccount) TableName() string { return "tenantAccounts" }
+
+func TestItemCache_ModelPolicyScopesAndCachesMisses(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.GetItem", []stubbedResponse{
+		{body: `{}`},
+		{body: `{"Item":{"id":{"S":"a1"},"balance":{"N":"10"}}}`},
+	})
+	db := newStubbedDB(t, httpClient)
+	store := cache.NewLRU(16)
+	cached := db.WithItemCache(cache.Options{Store: store})
+	read := func(ctx context.Context) error {
+		t.Helper()
+		var account tenantCachedAccount
+		return cached.WithContext(ctx).Model(&tenantCachedAccount{}).Where("ID", "=", "a1").First(&account)
+	}
+	tenantA := cache.WithTenant(context.Background(), "tenant-a")
+
+	require.ErrorIs(t, read(tenantA), customerrors.ErrItemNotFound)
+	require.ErrorIs(t, read(tenantA), customerrors.ErrItemNotFound)
+	require.Equal(t, 1, getItemCalls(httpClient), "the miss is cached")
+
+	require.NoError(t, cached.WithContext(tenantA).Model(&tenantCachedAccount{ID: "a1", Balance: 10}).CreateOrUpdate())
+	require.NoError(t, read(tenantA))
+	require.NoError(t, read(tenantA))
+	require.Equal(t, 2, getItemCalls(httpClient), "the write invalidates the cached miss")
+
+	require.NoError(t, read(cache.WithTenant(context.Background(), "tenant-b")))
+	require.Equal(t, 3, getItemCalls(httpClient), "tenants do not share items")
+	require.Equal(t, 3, store.Len(), "each tenant's copy and the item's generation stamp")
+
+	require.NoError(t, read(context.Background()))
+	require.NoError(t, read(context.Background()))
+	require.Equal(t, 5, getItemCalls(httpClient), "reads without a tenant bypass the cache")
+}
+
+func TestItemCache_WritesInvalidateEveryTenant(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"a1"},"balance":{"N":"10"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+	cached := db.WithItemCache(cache.Options{Store: cache.NewLRU(16)})
+	tenantA := cache.WithTenant(context.Background(), "tenant-a")
+	tenantB := cache.WithTenant(context.Background(), "tenant-b")
+	read := func(ctx context.Context) {
+		t.Helper()
+		var account tenantCachedAccount
+		require.NoError(t, cached.WithContext(ctx).Model(&tenantCachedAccount{}).Where("ID", "=", "a1").First(&account))
+	}
+
+	read(tenantB)
+	read(tenantB)
+	require.Equal(t, 1, getItemCalls(httpClient))
+
+	require.NoError(t, cached.WithContext(tenantA).Model(&tenantCachedAccount{ID: "a1", Balance: 20}).CreateOrUpdate())
+	read(tenantB)
+	require.Equal(t, 2, getItemCalls(httpClient), "another tenant's write invalidates")
+
+	require.NoError(t, cached.Model(&tenantCachedAccount{ID: "a1", Balance: 30}).CreateOrUpdate())
+	read(tenantB)
+	read(tenantA)
+	require.Equal(t, 4, getItemCalls(httpClient), "a write without a tenant invalidates")
+	read(tenantA)
+	require.Equal(t, 4, getItemCalls(httpClient))
+}
+
+type failingStore struct{}
+
+var errStoreDown = errors.New("cache unavailable")
+
+func (failingStore) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errStoreDown
+}
+
+func (failingStore) Set(context.Context, string, []byte, time.Duration) error {
+	return errStoreDown
+}
+
+func (failingStore) Delete(context.Context, ...string) error { return errStoreDown }
@@ -0,0 +1,263 @@
+package dynamorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// itemCollectionQuery implements core.ItemCollectionQuery. Entities are told apart by a
+// sort key prefix or a discriminator attribute; the first registered entity that matches
+// an item claims it.
+type itemCollectionQuery struct {
+	db             *DB
+	partitionKey   any
+	err            error
+	entities       []*collectionEntity
+	consistentRead bool
+}
+
+type collectionEntity struct {
+	metadata *model.Metadata
+	match    func(map[string]types.AttributeValue) bool
+}
+
+// ItemCollection starts a read of the item collection stored under partition key value pk.
+// Register at least one entity with Entity or EntityByAttribute before calling All; all
+// entities must live in the same table and share the partition key attribute.
+//
+//	coll, err := db.ItemCollection("CUSTOMER#42").
+//		Entity(&Customer{}, "PROFILE").
+//		Entity(&Order{}, "ORDER#").
+//		Entity(&Payment{}, "PAYMENT#").
+//		All()
+//	var orders []Order
+//	err = coll.Load(&orders)
+func (db *DB) ItemCollection(pk any) core.ItemCollectionQuery {
+	q := &itemCollectionQuery{db: db, partitionKey: pk}
+	if pk == nil {
+		q.err = fmt.Errorf("item collection partition key cannot be nil")
+	}
+	return q
+}
+
+// Entity registers a model whose items are identified by a sort key beginning with prefix.
+func (q *itemCollectionQuery) Entity(modelValue any, sortKeyPrefix string) core.ItemCollectionQuery {
+	meta, ok := q.addEntity(modelValue)
+	if !ok {
+		return q
+	}
+	if meta.PrimaryKey.SortKey == nil {
+		q.err = fmt.Errorf("model %T has no sort key to match prefix %q", modelValue, sortKeyPrefix)
+		return q
+	}
+
+	skName := meta.PrimaryKey.SortKey.DBName
+	q.entities = append(q.entities, &collectionEntity{
+		metadata: meta,
+		match: func(item map[string]types.AttributeValue) bool {
+			sk, ok := item[skName].(*types.AttributeValueMemberS)
+			return ok && strings.HasPrefix(sk.Value, sortKeyPrefix)
+		},
+	})
+	return q
+}
+
+// EntityByAttribute registers a model whose items carry attribute set to the string value.
+// attribute may be a Go field name or a DynamoDB attribute name.
+func (q *itemCollectionQuery) EntityByAttribute(modelValue any, attribute string, value string) core.ItemCollectionQuery {
+	meta, ok := q.addEntity(modelValue)
+	if !ok {
+		return q
+	}
+
+	attrName := resolveAttributeName(meta, attribute)
+	q.entities = append(q.entities, &collectionEntity{
+		metadata: meta,
+		match: func(item map[string]types.AttributeValue) bool {
+			attr, ok := item[attrName].(*types.AttributeValueMemberS)
+			return ok && attr.Value == value
+		},
+	})
+	return q
+}
+
+// ConsistentRead requests a strongly consistent read of the partition.
+func (q *itemCollectionQuery) ConsistentRead() core.ItemCollectionQuery {
+	q.consistentRead = true
+	return q
+}
+
+func (q *itemCollectionQuery) addEntity(modelValue any) (*model.Metadata, bool) {
+	if q.err != nil {
+		return nil, false
+	}
+
+	meta, err := q.db.metadataFor(modelValue)
+	if err != nil {
+		q.err = err
+		return nil, false
+	}
+
+	if len(q.entities) > 0 {
+		anchor := q.entities[0].metadata
+		if meta.TableName != anchor.TableName {
+			q.err = fmt.Errorf("model %T uses table %s, item collection reads %s", modelValue, meta.TableName, anchor.TableName)
+			return nil, false
+		}
+		if meta.PrimaryKey.PartitionKey.DBName != anchor.PrimaryKey.PartitionKey.DBName {
+			q.err = fmt.Errorf("model %T partition key %s does not match %s", modelValue, meta.PrimaryKey.PartitionKey.DBName, anchor.PrimaryKey.PartitionKey.DBName)
+			return nil, false
+		}
+	}
+	for _, existing := range q.entities {
+		if existing.metadata.Type == meta.Type {
+			q.err = fmt.Errorf("model %T is already registered in this item collection", modelValue)
+			return nil, false
+		}
+	}
+
+	return meta, true
+}
+
+// All queries the whole partition and sorts items into their registered entities.
+func (q *itemCollectionQuery) All() (core.ItemCollection, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	if len(q.entities) == 0 {
+		return nil, errors.New("item collection requires at least one entity")
+	}
+
+	items, err := q.db.readPartition(q.entities[0].metadata, q.partitionKey, q.consistentRead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query item collection: %w", err)
+	}
+
+	coll := &itemCollection{
+		partitionKey: q.partitionKey,
+		entries:      make(map[reflect.Type]*collectionEntries, len(q.entities)),
+	}
+	scopes := make(map[reflect.Type]*tenantScope, len(q.entities))
+	for _, entity := range q.entities {
+		executor := &queryExecutor{db: q.db, metadata: entity.metadata, ctx: q.db.ctx}
+		scope, err := executor.tenantScope()
+		if err != nil {
+			return nil, err
+		}
+		scopes[entity.metadata.Type] = scope
+		coll.entries[entity.metadata.Type] = &collectionEntries{executor: executor}
+	}
+
+	for _, item := range items {
+		matched := false
+		for _, entity := range q.entities {
+			if !entity.match(item) {
+				continue
+			}
+			matched = true
+			if scope := scopes[entity.metadata.Type]; scope != nil && !scope.owns(item) {
+				break
+			}
+			entries := coll.entries[entity.metadata.Type]
+			if err := entries.executor.loadItem(item); err != nil {
+				return nil, err
+			}
+			entries.items = append(entries.items, item)
+			break
+		}
+		if !matched {
+			coll.unmatched = append(coll.unmatched, item)
+		}
+	}
+
+	return coll, nil
+}
+
+// readPartition queries every item stored under partition key pk in the table of anchor.
+// The read runs without model metadata; callers decrypt each item with its own model's.
+func (db *DB) readPartition(anchor *model.Metadata, pk any, consistentRead bool) ([]map[string]types.AttributeValue, error) {
+	pkValue, err := db.converter.ToAttributeValue(pk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert partition key: %w", err)
+	}
+
+	compiled := &core.CompiledQuery{
+		Operation:                 "Query",
+		TableName:                 anchor.TableName,
+		KeyConditionExpression:    "#pk = :pk",
+		ExpressionAttributeNames:  map[string]string{"#pk": anchor.PrimaryKey.PartitionKey.DBName},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":pk": pkValue},
+	}
+	if consistentRead {
+		compiled.ConsistentRead = aws.Bool(true)
+	}
+
+	reader := &queryExecutor{db: db, ctx: db.ctx}
+	var items []map[string]types.AttributeValue
+	if err := reader.ExecuteQuery(compiled, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// itemCollection implements core.ItemCollection.
+type itemCollection struct {
+	partitionKey any
+	entries      map[reflect.Type]*collectionEntries
+	unmatched    []map[string]types.AttributeValue
+}
+
+type collectionEntries struct {
+	executor *queryExecutor
+	items    []map[string]types.AttributeValue
+}
+
+// PartitionKey returns the partition key value the collection was read with.
+func (c *itemCollection) PartitionKey() any {
+	return c.partitionKey
+}
+
+// Unmatched returns the raw items that no registered entity claimed.
+func (c *itemCollection) Unmatched() []map[string]types.AttributeValue {
+	return c.unmatched
+}
+
+// Load unmarshals the items of one entity into dest, which must be a pointer to a slice
+// of a registered model (or of pointers to it).
+func (c *itemCollection) Load(dest any) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.IsNil() || destValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("destination must be a pointer to slice")
+	}
+
+	elemType := destValue.Elem().Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	entries, ok := c.entries[elemType]
+	if !ok {
+		return fmt.Errorf("model %s is not registered in this item collection", elemType.Name())
+	}
+	return entries.executor.unmarshalItems(entries.items, dest)
+}
+
+// Count returns how many items were read for the given model.
+func (c *itemCollection) Count(modelValue any) int {
+	typ := reflect.TypeOf(modelValue)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if entries, ok := c.entries[typ]; ok {
+		return len(entries.items)
+	}
+	return 0
+}
@@ -0,0 +1,123 @@
+package dynamorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+type collectionCustomer struct {
+	PK   string `dynamorm:"pk,attr:PK"`
+	SK   string `dynamorm:"sk,attr:SK"`
+	Name string `dynamorm:"attr:name"`
+}
+
+func (collectionCustomer) TableName() string { return "single_table" }
+
+type collectionOrder struct {
+	PK     string `dynamorm:"pk,attr:PK"`
+	SK     string `dynamorm:"sk,attr:SK"`
+	Total  int    `dynamorm:"attr:total"`
+	Status string `dynamorm:"attr:status"`
+}
+
+func (collectionOrder) TableName() string { return "single_table" }
+
+type collectionNote struct {
+	PK   string `dynamorm:"pk,attr:PK"`
+	SK   string `dynamorm:"sk,attr:SK"`
+	Kind string `dynamorm:"attr:kind"`
+	Body string `dynamorm:"attr:body"`
+}
+
+func (collectionNote) TableName() string { return "single_table" }
+
+type collectionOtherTable struct {
+	PK string `dynamorm:"pk,attr:PK"`
+	SK string `dynamorm:"sk,attr:SK"`
+}
+
+func TestItemCollection_GroupsPartitionByEntity(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.Query", []stubbedResponse{
+		{body: `{"Items":[
+			{"PK":{"S":"CUST#1"},"SK":{"S":"PROFILE"},"name":{"S":"Ada"}},
+			{"PK":{"S":"CUST#1"},"SK":{"S":"ORDER#001"},"total":{"N":"12"},"status":{"S":"open"}}
+		],"Count":2,"ScannedCount":2,"LastEvaluatedKey":{"PK":{"S":"CUST#1"},"SK":{"S":"ORDER#001"}}}`},
+		{body: `{"Items":[
+			{"PK":{"S":"CUST#1"},"SK":{"S":"ORDER#002"},"total":{"N":"30"},"status":{"S":"paid"}},
+			{"PK":{"S":"CUST#1"},"SK":{"S":"X#1"},"kind":{"S":"note"},"body":{"S":"call back"}},
+			{"PK":{"S":"CUST#1"},"SK":{"S":"ZZZ"}}
+		],"Count":3,"ScannedCount":3}`},
+	})
+	stubSessionConfigLoad(t, func(context.Context, ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return minimalAWSConfig(httpClient), nil
+	})
+
+	dbAny, err := New(session.Config{Region: "us-east-1"})
+	require.NoError(t, err)
+	db := mustDB(t, dbAny)
+
+	coll, err := db.ItemCollection("CUST#1").
+		Entity(&collectionCustomer{}, "PROFILE").
+		Entity(&collectionOrder{}, "ORDER#").
+		EntityByAttribute(&collectionNote{}, "Kind", "note").
+		ConsistentRead().
+		All()
+	require.NoError(t, err)
+
+	var customers []collectionCustomer
+	require.NoError(t, coll.Load(&customers))
+	require.Len(t, customers, 1)
+	require.Equal(t, "Ada", customers[0].Name)
+
+	var orders []*collectionOrder
+	require.NoError(t, coll.Load(&orders))
+	require.Len(t, orders, 2)
+	require.Equal(t, 30, orders[1].Total)
+	require.Equal(t, 2, coll.Count(&collectionOrder{}))
+
+	var notes []collectionNote
+	require.NoError(t, coll.Load(&notes))
+	require.Equal(t, "call back", notes[0].Body)
+
+	require.Len(t, coll.Unmatched(), 1)
+	require.Equal(t, 0, coll.Count(&collectionOtherTable{}))
+
+	var unknown []collectionOtherTable
+	require.ErrorContains(t, coll.Load(&unknown), "not registered")
+
+	require.Equal(t, 2, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Query"))
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, req)
+	require.Equal(t, "single_table", req.Payload["TableName"])
+	require.Equal(t, "#pk = :pk", req.Payload["KeyConditionExpression"])
+	require.Equal(t, true, req.Payload["ConsistentRead"])
+}
+
+func TestItemCollection_ValidatesEntities(t *testing.T) {
+	db := newBareDB()
+
+	_, err := db.ItemCollection("CUST#1").All()
+	require.ErrorContains(t, err, "at least one entity")
+
+	_, err = db.ItemCollection(nil).Entity(&collectionOrder{}, "ORDER#").All()
+	require.ErrorContains(t, err, "cannot be nil")
+
+	_, err = db.ItemCollection("CUST#1").
+		Entity(&collectionOrder{}, "ORDER#").
+		Entity(&collectionOtherTable{}, "OTHER#").
+		All()
+	require.ErrorContains(t, err, "uses table")
+
+	_, err = db.ItemCollection("CUST#1").
+		Entity(&collectionOrder{}, "ORDER#").
+		Entity(&collectionOrder{}, "ORDER2#").
+		All()
+	require.ErrorContains(t, err, "already registered")
+}
@@ -0,0 +1,31 @@
+package dynamorm
+
+import (
+	"fmt"
+
+	"github.com/pay-theory/dynamorm/internal/encryption"
+)
+
+// Crypto actions returned by EncryptionAttributeActions, named as the AWS Database
+// Encryption SDK for DynamoDB names them.
+const (
+	CryptoActionEncryptAndSign = encryption.ActionEncryptAndSign
+	CryptoActionSignOnly       = encryption.ActionSignOnly
+	CryptoActionDoNothing      = encryption.ActionDoNothing
+)
+
+// EncryptionAttributeActions returns the crypto action of each attribute of the model's
+// items, for configuring the encryptor supplied as session.Config.ItemEncryptor to match
+// the model: ENCRYPT_AND_SIGN for dynamorm:"encrypted" fields, SIGN_ONLY for the primary
+// key, and DO_NOTHING for the rest, which the encryptor must leave unsigned so they can
+// still be updated. DynamORM only reports the actions; it implements no item format.
+func (db *DB) EncryptionAttributeActions(model any) (map[string]string, error) {
+	metadata, err := db.metadataFor(model)
+	if err != nil {
+		return nil, err
+	}
+	if !encryption.MetadataHasEncryptedFields(metadata) {
+		return nil, fmt.Errorf("model %T has no dynamorm:\"encrypted\" fields", model)
+	}
+	return encryption.AttributeActions(metadata), nil
+}
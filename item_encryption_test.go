@@ -0,0 +1,92 @@
+package dynamorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+type itemEncryptedCard struct {
+	ID     string `dynamorm:"pk,attr:id"`
+	Number string `dynamorm:"encrypted,attr:number"`
+	Label  string `dynamorm:"attr:label"`
+}
+
+func (itemEncryptedCard) TableName() string { return "cards" }
+
+// reversingItemEncryptor stands in for a user-supplied item encryptor: it reverses the
+// number attribute and adds a header attribute.
+type reversingItemEncryptor struct {
+	tables []string
+}
+
+func (e *reversingItemEncryptor) EncryptItem(_ context.Context, table string, item map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+	e.tables = append(e.tables, table)
+	out := make(map[string]types.AttributeValue, len(item)+1)
+	for name, av := range item {
+		out[name] = av
+	}
+	out["number"] = &types.AttributeValueMemberB{Value: []byte(reverse(item["number"].(*types.AttributeValueMemberS).Value))}
+	out["aws_dbe_head"] = &types.AttributeValueMemberB{Value: []byte("header")}
+	return out, nil
+}
+
+func (e *reversingItemEncryptor) DecryptItem(_ context.Context, table string, item map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+	e.tables = append(e.tables, table)
+	if _, ok := item["aws_dbe_head"]; !ok {
+		return nil, errors.New("missing header")
+	}
+	out := make(map[string]types.AttributeValue, len(item))
+	for name, av := range item {
+		out[name] = av
+	}
+	delete(out, "aws_dbe_head")
+	out["number"] = &types.AttributeValueMemberS{Value: reverse(string(item["number"].(*types.AttributeValueMemberB).Value))}
+	return out, nil
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+func TestItemEncryptorEncryptsWholeItems(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"c1"},"number":{"B":"MTExNDQ="},"label":{"S":"work"},"aws_dbe_head":{"B":"aGVhZGVy"}}}`,
+	})
+	encryptor := &reversingItemEncryptor{}
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{ItemEncryptor: encryptor})
+
+	require.NoError(t, db.Model(&itemEncryptedCard{ID: "c1", Number: "44111", Label: "work"}).Create())
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	item := put.Payload["Item"].(map[string]any)
+	require.Equal(t, map[string]any{"B": "MTExNDQ="}, item["number"])
+	require.Contains(t, item, "aws_dbe_head")
+
+	var card itemEncryptedCard
+	require.NoError(t, db.Model(&itemEncryptedCard{ID: "c1"}).First(&card))
+	require.Equal(t, itemEncryptedCard{ID: "c1", Number: "44111", Label: "work"}, card)
+	require.Equal(t, []string{"cards", "cards"}, encryptor.tables)
+
+	require.NoError(t, db.Model(&itemEncryptedCard{ID: "c1", Label: "home"}).Update("Label"))
+	err := db.Model(&itemEncryptedCard{ID: "c1", Number: "55"}).Update("Number")
+	require.ErrorContains(t, err, "write the whole item")
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem"))
+
+	actions, err := db.EncryptionAttributeActions(&itemEncryptedCard{})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"id":     CryptoActionSignOnly,
+		"number": CryptoActionEncryptAndSign,
+		"label":  CryptoActionDoNothing,
+	}, actions)
+}
@@ -0,0 +1,72 @@
+package dynamorm
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/internal/itemsize"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// largestAttributesReported caps how many attributes an ItemSizeError lists.
+const largestAttributesReported = 5
+
+// checkItemSize enforces the 400KB item limit when the DB has item size validation enabled.
+func (qe *queryExecutor) checkItemSize(operation string, item map[string]types.AttributeValue) error {
+	if qe == nil || qe.db == nil || !qe.db.validateItemSize {
+		return nil
+	}
+
+	size := itemsize.Of(item)
+	if size <= itemsize.MaxItemBytes {
+		return nil
+	}
+
+	largest := itemsize.Largest(item, largestAttributesReported)
+	attrs := make([]customerrors.AttributeSize, len(largest))
+	for i, attr := range largest {
+		attrs[i] = customerrors.AttributeSize{Name: attr.Name, Bytes: attr.Bytes}
+	}
+	return &customerrors.ItemSizeError{
+		Operation: operation,
+		Size:      size,
+		Limit:     itemsize.MaxItemBytes,
+		Largest:   attrs,
+	}
+}
+
+var assignmentPattern = regexp.MustCompile(`(#\w+(?:\.#\w+)*)\s*=\s*(:\w+)`)
+
+// updateSizeItem approximates what an UpdateItem writes: the key attributes plus every
+// expression value. Values assigned directly (SET #a = :v) are named after their
+// attribute; any others keep their placeholder.
+func updateSizeItem(key map[string]types.AttributeValue, input *dynamodb.UpdateItemInput) map[string]types.AttributeValue {
+	item := make(map[string]types.AttributeValue, len(key)+len(input.ExpressionAttributeValues))
+	for name, value := range key {
+		item[name] = value
+	}
+
+	names := make(map[string]string)
+	for _, match := range assignmentPattern.FindAllStringSubmatch(aws.ToString(input.UpdateExpression), -1) {
+		parts := strings.Split(match[1], ".")
+		for i, part := range parts {
+			if resolved, ok := input.ExpressionAttributeNames[part]; ok {
+				parts[i] = resolved
+			}
+		}
+		names[match[2]] = strings.Join(parts, ".")
+	}
+
+	for placeholder, value := range input.ExpressionAttributeValues {
+		name := placeholder
+		if resolved, ok := names[placeholder]; ok {
+			name = resolved
+		}
+		item[name] = value
+	}
+	return item
+}
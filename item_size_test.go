@@ -0,0 +1,60 @@
+package dynamorm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+func TestItemSizeValidation_RejectsOversizedPut(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.PutItem": `{}`,
+	})
+	db := newStubbedDB(t, httpClient).WithItemSizeValidation(true)
+
+	err := db.Model(&testPayment{ID: "p1", ClientToken: strings.Repeat("x", 410*1024)}).Create()
+	require.ErrorIs(t, err, customerrors.ErrItemTooLarge)
+
+	var sizeErr *customerrors.ItemSizeError
+	require.ErrorAs(t, err, &sizeErr)
+	require.Equal(t, "PutItem", sizeErr.Operation)
+	require.Equal(t, 400*1024, sizeErr.Limit)
+	require.Equal(t, "clientToken", sizeErr.Largest[0].Name)
+	require.Contains(t, err.Error(), "largest attributes: clientToken (")
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem"))
+
+	require.NoError(t, db.Model(&testPayment{ID: "p2", ClientToken: "small"}).Create())
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem"))
+}
+
+func TestItemSizeValidation_RejectsOversizedUpdate(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.UpdateItem": `{}`,
+	})
+	db := newStubbedDB(t, httpClient).WithItemSizeValidation(true)
+
+	events := make([]string, 45)
+	for i := range events {
+		events[i] = strings.Repeat("x", 10000)
+	}
+	err := db.Model(&cappedFeed{}).Where("ID", "=", "f1").UpdateBuilder().
+		Set("Events", events).
+		Execute()
+	require.ErrorIs(t, err, customerrors.ErrItemTooLarge)
+	require.Contains(t, err.Error(), "UpdateItem item is")
+	require.Contains(t, err.Error(), "largest attributes: events (")
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem"))
+}
+
+func TestItemSizeValidation_DisabledByDefault(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.PutItem": `{}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	require.NoError(t, db.Model(&testPayment{ID: "p1", ClientToken: strings.Repeat("x", 410*1024)}).Create())
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem"))
+}
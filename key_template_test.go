@@ -0,0 +1,76 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+type keyTicket struct {
+	TenantID string `dynamorm:"pk,attr:PK,prefix:TENANT#"`
+	SK       string `dynamorm:"sk,attr:SK,composite:Status,Number"`
+	Status   string `dynamorm:"attr:status"`
+	Number   int    `dynamorm:"attr:number"`
+	Title    string `dynamorm:"attr:title"`
+}
+
+func (keyTicket) TableName() string { return "key_tickets" }
+
+func TestKeyTemplates_ComposeKeysOnWrite(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.PutItem": `{}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	require.NoError(t, db.Model(&keyTicket{TenantID: "t1", Status: "open", Number: 7, Title: "Printer"}).Create())
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	item := put.Payload["Item"].(map[string]any)
+	require.Equal(t, map[string]any{"S": "TENANT#t1"}, item["PK"])
+	require.Equal(t, map[string]any{"S": "open#7"}, item["SK"])
+
+	err := db.Model(&keyTicket{TenantID: "t1", Status: "open"}).Create()
+	require.ErrorIs(t, err, customerrors.ErrInvalidPrimaryKey)
+}
+
+func TestKeyTemplates_GetParsesComponents(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"PK":{"S":"TENANT#t1"},"SK":{"S":"closed#9"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var ticket keyTicket
+	require.NoError(t, db.Model(&keyTicket{TenantID: "t1", Status: "closed", Number: 9}).First(&ticket))
+
+	get := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.GetItem")
+	require.NotNil(t, get)
+	key := get.Payload["Key"].(map[string]any)
+	require.Equal(t, map[string]any{"S": "TENANT#t1"}, key["PK"])
+	require.Equal(t, map[string]any{"S": "closed#9"}, key["SK"])
+
+	require.Equal(t, "t1", ticket.TenantID)
+	require.Equal(t, "closed#9", ticket.SK)
+	require.Equal(t, "closed", ticket.Status)
+	require.Equal(t, 9, ticket.Number)
+}
+
+func TestKeyTemplates_PrefixQueryConditions(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[],"Count":0,"ScannedCount":0}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var tickets []keyTicket
+	require.NoError(t, db.Model(&keyTicket{}).
+		Where("TenantID", "=", "t1").
+		Where("SK", "begins_with", "open#").
+		All(&tickets))
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, req)
+	values := req.Payload["ExpressionAttributeValues"].(map[string]any)
+	require.Contains(t, values, ":v1")
+	require.Equal(t, map[string]any{"S": "TENANT#t1"}, values[":v1"])
+}
@@ -203,14 +203,11 @@ func (ldb *LambdaDB) WithLambdaTimeout(ctx context.Context) *LambdaDB {
 	// Leave 1 second buffer for Lambda cleanup
 	adjustedDeadline := deadline.Add(-1 * time.Second)
 
-	newDB := &DB{
-		session:        ldb.db.session,
-		registry:       ldb.db.registry,
-		converter:      ldb.db.converter,
-		marshaler:      ldb.db.marshaler,
-		ctx:            ctx,
-		lambdaDeadline: adjustedDeadline,
-	}
+	ldb.db.mu.RLock()
+	newDB := ldb.db.derive()
+	ldb.db.mu.RUnlock()
+	newDB.ctx = ctx
+	newDB.lambdaDeadline = adjustedDeadline
 
 	return &LambdaDB{
 		ExtendedDB:     newDB,
@@ -127,6 +127,8 @@ func TestLambdaDB_WithLambdaTimeout_SetsAdjustedDeadline_COV6(t *testing.T) {
 	dbAny, err := New(session.Config{Region: "us-east-1"})
 	require.NoError(t, err)
 	db := mustDB(t, dbAny)
+	db = mustDB(t, db.WithLambdaTimeoutBuffer(250*time.Millisecond))
+	db.metadataCache.Store("cached", true)
 
 	ldb := &LambdaDB{
 		ExtendedDB: db,
@@ -144,6 +146,9 @@ func TestLambdaDB_WithLambdaTimeout_SetsAdjustedDeadline_COV6(t *testing.T) {
 	require.NotNil(t, newDB.db)
 	require.Equal(t, ctx, newDB.db.ctx)
 	require.WithinDuration(t, deadline.Add(-1*time.Second), newDB.db.lambdaDeadline, 25*time.Millisecond)
+	require.Equal(t, 250*time.Millisecond, newDB.db.lambdaTimeoutBuffer)
+	_, cached := newDB.db.metadataCache.Load("cached")
+	require.True(t, cached)
 }
 
 func TestGetLambdaMemoryMB_HandlesEmptyAndInvalidValues_COV6(t *testing.T) {
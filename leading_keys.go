@@ -0,0 +1,128 @@
+package dynamorm
+
+import (
+	"regexp"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/leadingkeys"
+)
+
+// WithLeadingKeys returns a DB that checks every request against checker before sending
+// it, passing the partition key values the request touches and the tenant stored in its
+// context with leadingkeys.WithTenant. Use a leadingkeys.Rule to require keys built from
+// the tenant, and a leadingkeys.Simulator in tests to verify requests against the IAM
+// policy deployed alongside the service. Rejected requests fail with a
+// *leadingkeys.DeniedError. A nil checker turns checking off.
+func (db *DB) WithLeadingKeys(checker leadingkeys.Checker) core.ExtendedDB {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	newDB := db.derive()
+	newDB.leadingKeys = checker
+	return newDB
+}
+
+var keyEqualityPattern = regexp.MustCompile(`(#\w+)\s*=\s*(:\w+)`)
+
+// checkLeadingKeys runs the DB's leading key checker, if any, for one request.
+func (qe *queryExecutor) checkLeadingKeys(operation, table, index string, leadingKeys []string) error {
+	if qe == nil || qe.db == nil || qe.db.leadingKeys == nil {
+		return nil
+	}
+
+	req := leadingkeys.Request{
+		Action:      "dynamodb:" + operation,
+		Table:       table,
+		Index:       index,
+		LeadingKeys: leadingKeys,
+	}
+	req.Tenant, _ = leadingkeys.TenantFromContext(qe.ctxOrBackground())
+	return qe.db.leadingKeys.Check(req)
+}
+
+// checkItemLeadingKeys checks a request on the base table that touches the given keys or items.
+func (qe *queryExecutor) checkItemLeadingKeys(operation, table string, items ...map[string]types.AttributeValue) error {
+	if qe == nil || qe.db == nil || qe.db.leadingKeys == nil {
+		return nil
+	}
+
+	var leadingKeys []string
+	if pk := qe.partitionKeyName(""); pk != "" {
+		for _, item := range items {
+			if value, ok := leadingKeyValue(item[pk]); ok {
+				leadingKeys = append(leadingKeys, value)
+			}
+		}
+	}
+	return qe.checkLeadingKeys(operation, table, "", leadingKeys)
+}
+
+// checkBatchWriteLeadingKeys checks the items and keys of a BatchWriteItem request.
+func (qe *queryExecutor) checkBatchWriteLeadingKeys(table string, writeRequests []types.WriteRequest) error {
+	if qe == nil || qe.db == nil || qe.db.leadingKeys == nil {
+		return nil
+	}
+	items := make([]map[string]types.AttributeValue, 0, len(writeRequests))
+	for _, write := range writeRequests {
+		switch {
+		case write.PutRequest != nil:
+			items = append(items, write.PutRequest.Item)
+		case write.DeleteRequest != nil:
+			items = append(items, write.DeleteRequest.Key)
+		}
+	}
+	return qe.checkItemLeadingKeys("BatchWriteItem", table, items...)
+}
+
+// checkQueryLeadingKeys checks a compiled Query or Scan. The leading key of a Query is
+// the value its key condition compares the table's or index's partition key with.
+func (qe *queryExecutor) checkQueryLeadingKeys(input *core.CompiledQuery) error {
+	if qe == nil || qe.db == nil || qe.db.leadingKeys == nil || input == nil {
+		return nil
+	}
+
+	var leadingKeys []string
+	if pk := qe.partitionKeyName(input.IndexName); pk != "" && input.Operation == "Query" {
+		for _, match := range keyEqualityPattern.FindAllStringSubmatch(input.KeyConditionExpression, -1) {
+			if input.ExpressionAttributeNames[match[1]] != pk {
+				continue
+			}
+			if value, ok := leadingKeyValue(input.ExpressionAttributeValues[match[2]]); ok {
+				leadingKeys = append(leadingKeys, value)
+			}
+			break
+		}
+	}
+	return qe.checkLeadingKeys(input.Operation, input.TableName, input.IndexName, leadingKeys)
+}
+
+// partitionKeyName returns the partition key attribute of the base table or the named index.
+func (qe *queryExecutor) partitionKeyName(index string) string {
+	if qe.metadata == nil {
+		return ""
+	}
+	if index != "" {
+		if idx := findIndexSchema(qe.metadata, index); idx != nil && idx.PartitionKey != nil {
+			return idx.PartitionKey.DBName
+		}
+	}
+	if qe.metadata.PrimaryKey == nil || qe.metadata.PrimaryKey.PartitionKey == nil {
+		return ""
+	}
+	return qe.metadata.PrimaryKey.PartitionKey.DBName
+}
+
+func leadingKeyValue(av types.AttributeValue) (string, bool) {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value, true
+	case *types.AttributeValueMemberN:
+		return v.Value, true
+	case *types.AttributeValueMemberB:
+		return string(v.Value), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,71 @@
+package dynamorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/leadingkeys"
+)
+
+type tenantOrder struct {
+	TenantKey string `dynamorm:"pk,attr:tenantKey"`
+	ID        string `dynamorm:"sk,attr:id"`
+	Status    string `dynamorm:"index:gsi-status,pk,attr:status"`
+}
+
+func TestWithLeadingKeys_RuleChecksEveryRequestShape(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"tenantKey":{"S":"TENANT#t1"},"id":{"S":"o1"}}}`,
+		"DynamoDB_20120810.Query":   `{"Items":[],"Count":0}`,
+	})
+	db := newStubbedDB(t, httpClient).WithLeadingKeys(leadingkeys.Rule{Template: "TENANT#{tenant}"})
+	ctx := leadingkeys.WithTenant(context.Background(), "t1")
+
+	var order tenantOrder
+	require.NoError(t, db.WithContext(ctx).Model(&tenantOrder{}).
+		Where("TenantKey", "=", "TENANT#t1").Where("ID", "=", "o1").First(&order))
+
+	var orders []tenantOrder
+	require.NoError(t, db.WithContext(ctx).Model(&tenantOrder{}).Where("TenantKey", "=", "TENANT#t1").All(&orders))
+
+	err := db.WithContext(ctx).Model(&tenantOrder{}).
+		Where("TenantKey", "=", "TENANT#t2").Where("ID", "=", "o1").First(&order)
+	require.ErrorIs(t, err, leadingkeys.ErrDenied)
+
+	err = db.WithContext(ctx).Model(&tenantOrder{TenantKey: "TENANT#t2", ID: "o2"}).Create()
+	require.ErrorIs(t, err, leadingkeys.ErrDenied)
+
+	err = db.WithContext(ctx).Model(&tenantOrder{}).Scan(&orders)
+	require.ErrorIs(t, err, leadingkeys.ErrDenied)
+
+	err = db.Model(&tenantOrder{}).Where("TenantKey", "=", "TENANT#t1").All(&orders)
+	require.ErrorContains(t, err, "no tenant in request context")
+
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.GetItem"))
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Query"))
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem"))
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan"))
+}
+
+func TestWithLeadingKeys_SimulatorUsesIndexPartitionKey(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[],"Count":0}`,
+	})
+	sim, err := leadingkeys.NewSimulator([]byte(`{"Statement":[{
+		"Effect": "Allow",
+		"Action": "dynamodb:Query",
+		"Resource": "arn:aws:dynamodb:*:*:table/tenantOrders/index/*",
+		"Condition": {"ForAllValues:StringEquals": {"dynamodb:LeadingKeys": ["open"]}}
+	}]}`))
+	require.NoError(t, err)
+	db := newStubbedDB(t, httpClient).WithLeadingKeys(sim)
+
+	var orders []tenantOrder
+	require.NoError(t, db.Model(&tenantOrder{}).Index("gsi-status").Where("Status", "=", "open").All(&orders))
+
+	err = db.Model(&tenantOrder{}).Index("gsi-status").Where("Status", "=", "closed").All(&orders)
+	require.ErrorIs(t, err, leadingkeys.ErrDenied)
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Query"))
+}
@@ -0,0 +1,65 @@
+package dynamorm
+
+import (
+	"fmt"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+// ListResponse is a JSON-ready envelope for one page of results, suitable for returning
+// directly from an HTTP handler. Items is always encoded as an array (never null), and
+// NextCursor is omitted on the last page.
+type ListResponse[T any] struct {
+	Items        []T    `json:"items"`
+	NextCursor   string `json:"nextCursor,omitempty"`
+	Count        int    `json:"count"`
+	ScannedCount int    `json:"scannedCount,omitempty"`
+	HasMore      bool   `json:"hasMore"`
+}
+
+// NewListResponse builds a ListResponse from the result of AllPaginated. The result's
+// Items must be the *[]T (or []T) destination that was passed to AllPaginated.
+func NewListResponse[T any](result *core.PaginatedResult) (*ListResponse[T], error) {
+	if result == nil {
+		return nil, fmt.Errorf("paginated result cannot be nil")
+	}
+
+	var items []T
+	switch v := result.Items.(type) {
+	case *[]T:
+		if v != nil {
+			items = *v
+		}
+	case []T:
+		items = v
+	case nil:
+	default:
+		var zero T
+		return nil, fmt.Errorf("paginated result items are %T, expected *[]%T", result.Items, zero)
+	}
+	if items == nil {
+		items = []T{}
+	}
+
+	return &ListResponse[T]{
+		Items:        items,
+		NextCursor:   result.NextCursor,
+		Count:        result.Count,
+		ScannedCount: result.ScannedCount,
+		HasMore:      result.HasMore || result.NextCursor != "",
+	}, nil
+}
+
+// ListPage runs q with AllPaginated and wraps the page in a ListResponse:
+//
+//	page, err := dynamorm.ListPage[Order](db.Model(&Order{}).Where("CustomerID", "=", id).Limit(25).Cursor(cursor))
+//	if err != nil { ... }
+//	return json.NewEncoder(w).Encode(page)
+func ListPage[T any](q core.Query) (*ListResponse[T], error) {
+	var items []T
+	result, err := q.AllPaginated(&items)
+	if err != nil {
+		return nil, err
+	}
+	return NewListResponse[T](result)
+}
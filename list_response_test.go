@@ -0,0 +1,59 @@
+package dynamorm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+func TestNewListResponse_JSONShape(t *testing.T) {
+	items := []testOrderModel{{TenantID: "t1", CreatedAt: "a"}}
+	resp, err := NewListResponse[testOrderModel](&core.PaginatedResult{
+		Items:      &items,
+		NextCursor: "cursor-1",
+		Count:      1,
+		HasMore:    true,
+	})
+	require.NoError(t, err)
+
+	encoded, err := json.Marshal(resp)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"items":[{"TenantID":"t1","CreatedAt":"a","Status":""}],"nextCursor":"cursor-1","count":1,"hasMore":true}`, string(encoded))
+}
+
+func TestNewListResponse_EmptyPageEncodesEmptyArray(t *testing.T) {
+	resp, err := NewListResponse[testOrderModel](&core.PaginatedResult{})
+	require.NoError(t, err)
+
+	encoded, err := json.Marshal(resp)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"items":[],"count":0,"hasMore":false}`, string(encoded))
+}
+
+func TestNewListResponse_RejectsMismatchedItems(t *testing.T) {
+	_, err := NewListResponse[testOrderModel](&core.PaginatedResult{Items: &[]string{"x"}})
+	require.ErrorContains(t, err, "expected *[]dynamorm.testOrderModel")
+
+	_, err = NewListResponse[testOrderModel](nil)
+	require.Error(t, err)
+}
+
+func TestListPage_WrapsPaginatedQuery(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[{"tenantId":{"S":"t1"},"createdAt":{"S":"a"},"status":{"S":"open"}}],"Count":1,"ScannedCount":2,` +
+			`"LastEvaluatedKey":{"tenantId":{"S":"t1"},"createdAt":{"S":"a"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	page, err := ListPage[testOrderModel](db.Model(&testOrderModel{}).Where("TenantID", "=", "t1").Limit(1))
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	require.Equal(t, "open", page.Items[0].Status)
+	require.Equal(t, 1, page.Count)
+	require.Equal(t, 2, page.ScannedCount)
+	require.True(t, page.HasMore)
+	require.NotEmpty(t, page.NextCursor)
+}
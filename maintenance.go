@@ -0,0 +1,84 @@
+package dynamorm
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pay-theory/dynamorm/pkg/maintenance"
+)
+
+// Maintenance returns the registry of tables frozen for maintenance. Requests to a
+// ReadOnly table's writes, or to anything on an Offline table, fail with
+// errors.ErrMaintenanceMode without being sent. The registry is shared by every DB
+// derived from the same New call:
+//
+//	db.Maintenance().Set("orders", maintenance.ReadOnly)
+//	http.Handle("/internal/maintenance", db.Maintenance().Handler())
+func (db *DB) Maintenance() *maintenance.Registry {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.maintenance == nil {
+		db.maintenance = maintenance.NewRegistry()
+	}
+	return db.maintenance
+}
+
+// SetMaintenanceMode sets the maintenance mode of the model's table.
+func (db *DB) SetMaintenanceMode(model any, mode maintenance.Mode) error {
+	table, err := db.TableName(model)
+	if err != nil {
+		return err
+	}
+	db.Maintenance().Set(table, mode)
+	return nil
+}
+
+func (db *DB) maintenanceRegistry() *maintenance.Registry {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.maintenance
+}
+
+// checkMaintenance rejects a request to table that its maintenance mode does not allow.
+func (db *DB) checkMaintenance(table string, write bool) error {
+	if db == nil {
+		return nil
+	}
+	registry := db.maintenanceRegistry()
+	if registry == nil {
+		return nil
+	}
+	return registry.Check(table, write)
+}
+
+// partiQLTable matches the table a PartiQL statement reads or writes: the name after
+// FROM, INTO, or UPDATE, quoted or not.
+var partiQLTable = regexp.MustCompile(`(?i)\b(?:FROM|INTO|UPDATE)\s+(?:"([^"]+)"|([A-Za-z0-9_-]+))`)
+
+// partiQLTarget returns the verb of a PartiQL statement, upper-cased, and the table it
+// reads or writes, empty when it cannot be found.
+func partiQLTarget(statement string) (verb, table string) {
+	if fields := strings.Fields(statement); len(fields) > 0 {
+		verb = strings.ToUpper(fields[0])
+	}
+	match := partiQLTable.FindStringSubmatch(statement)
+	if match == nil {
+		return verb, ""
+	}
+	table = match[1]
+	if table == "" {
+		table = match[2]
+	}
+	return verb, table
+}
+
+// checkPartiQLMaintenance applies the maintenance check to a PartiQL statement. Only
+// SELECT statements count as reads. Statements whose table cannot be found are let
+// through.
+func (db *DB) checkPartiQLMaintenance(statement string) error {
+	verb, table := partiQLTarget(statement)
+	if table == "" {
+		return nil
+	}
+	return db.checkMaintenance(table, verb != "SELECT")
+}
@@ -0,0 +1,66 @@
+package dynamorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/maintenance"
+)
+
+func TestMaintenanceMode_ReadOnlyRejectsWrites(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"a1"},"balance":{"N":"5"},"version":{"N":"1"}}}`,
+		"DynamoDB_20120810.PutItem": `{}`,
+	})
+	db := newStubbedDB(t, httpClient)
+	table, err := db.TableName(&testAccount{})
+	require.NoError(t, err)
+	require.NoError(t, db.SetMaintenanceMode(&testAccount{}, maintenance.ReadOnly))
+	require.Equal(t, maintenance.ReadOnly, db.Maintenance().Mode(table))
+
+	err = db.Model(&testAccount{ID: "a1", Balance: 5}).Create()
+	require.ErrorIs(t, err, customerrors.ErrMaintenanceMode)
+	err = db.TransactWrite(context.Background(), func(tx core.TransactionBuilder) error {
+		tx.Put(&testAccount{ID: "a2"})
+		return nil
+	})
+	require.ErrorIs(t, err, customerrors.ErrMaintenanceMode)
+	err = db.PartiQL(`INSERT INTO "`+table+`" VALUE {'id': ?}`, "a3").Exec()
+	require.ErrorIs(t, err, customerrors.ErrMaintenanceMode)
+	require.Empty(t, httpClient.Requests(), "rejected requests are not sent")
+
+	var account testAccount
+	require.NoError(t, db.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+	require.Equal(t, int64(5), account.Balance)
+
+	// A derived DB shares the modes, and clearing the mode lets writes through again.
+	scoped := db.WithContext(context.Background())
+	require.NoError(t, db.SetMaintenanceMode(&testAccount{}, maintenance.Normal))
+	require.NoError(t, scoped.Model(&testAccount{ID: "a1", Balance: 5}).Create())
+}
+
+func TestMaintenanceMode_OfflineRejectsReads(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+	table, err := db.TableName(&testAccount{})
+	require.NoError(t, err)
+	db.Maintenance().Set(table, maintenance.Offline)
+
+	var account testAccount
+	err = db.Model(&testAccount{}).Where("ID", "=", "a1").First(&account)
+	require.ErrorIs(t, err, customerrors.ErrMaintenanceMode)
+	require.ErrorContains(t, err, "table "+table+" is offline")
+
+	var accounts []testAccount
+	err = db.Model(&testAccount{}).Scan(&accounts)
+	require.ErrorIs(t, err, customerrors.ErrMaintenanceMode)
+	_, err = db.PartiQL(`SELECT * FROM "` + table + `"`).Page(&accounts)
+	require.ErrorIs(t, err, customerrors.ErrMaintenanceMode)
+	require.Empty(t, httpClient.Requests())
+
+	require.NoError(t, db.Model(&unversionedAccount{}).Scan(&accounts), "other tables are unaffected")
+}
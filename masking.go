@@ -0,0 +1,108 @@
+package dynamorm
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// partialMaskVisible is how many trailing characters mask:partial leaves readable.
+const partialMaskVisible = 4
+
+type maskConfig struct {
+	hashKey []byte
+}
+
+// MaskOption configures MaskItem.
+type MaskOption func(*maskConfig)
+
+// WithMaskHashKey makes mask:hash tokens an HMAC-SHA256 under key instead of a plain
+// SHA-256. Use a secret key when the masked values are guessable, such as SSNs or emails.
+func WithMaskHashKey(key []byte) MaskOption {
+	return func(cfg *maskConfig) {
+		cfg.hashKey = key
+	}
+}
+
+// MaskItem returns a copy of item, a raw item of modelValue's type, with every field tagged
+// `dynamorm:"mask:hash|partial|drop"` scrubbed for export. item is not modified.
+func MaskItem(db core.DB, modelValue any, item map[string]types.AttributeValue, opts ...MaskOption) (map[string]types.AttributeValue, error) {
+	meta, err := metadataForDB(db, modelValue)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg maskConfig
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	return maskItem(meta, item, cfg), nil
+}
+
+func maskItem(meta *model.Metadata, item map[string]types.AttributeValue, cfg maskConfig) map[string]types.AttributeValue {
+	masked := make(map[string]types.AttributeValue, len(item))
+	for name, value := range item {
+		field := meta.FieldsByDBName[name]
+		if field == nil {
+			masked[name] = value
+			continue
+		}
+
+		switch field.Mask() {
+		case model.MaskDrop:
+			continue
+		case model.MaskHash:
+			masked[name] = maskHash(value, cfg.hashKey)
+		case model.MaskPartial:
+			masked[name] = maskPartial(value)
+		default:
+			masked[name] = value
+		}
+	}
+	return masked
+}
+
+func maskHash(value types.AttributeValue, key []byte) types.AttributeValue {
+	var raw []byte
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		raw = []byte(v.Value)
+	case *types.AttributeValueMemberN:
+		raw = []byte(v.Value)
+	case *types.AttributeValueMemberB:
+		raw = v.Value
+	default:
+		return value
+	}
+
+	var h hash.Hash
+	if len(key) > 0 {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+	h.Write(raw)
+	return &types.AttributeValueMemberS{Value: hex.EncodeToString(h.Sum(nil))}
+}
+
+func maskPartial(value types.AttributeValue) types.AttributeValue {
+	s, ok := value.(*types.AttributeValueMemberS)
+	if !ok {
+		return value
+	}
+	runes := []rune(s.Value)
+	hidden := len(runes) - partialMaskVisible
+	if hidden < len(runes)/2 {
+		hidden = len(runes) - len(runes)/2
+	}
+	return &types.AttributeValueMemberS{Value: strings.Repeat("*", hidden) + string(runes[hidden:])}
+}
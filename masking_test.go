@@ -0,0 +1,59 @@
+package dynamorm
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+type maskedCustomer struct {
+	ID    string `dynamorm:"pk,attr:id"`
+	Email string `dynamorm:"attr:email,mask:hash"`
+	Card  string `dynamorm:"attr:card,mask:partial"`
+	Notes string `dynamorm:"attr:notes,mask:drop"`
+	Tier  string `dynamorm:"attr:tier"`
+}
+
+func TestMaskItem_AppliesTaggedStrategies(t *testing.T) {
+	db := newBareDB()
+	item := map[string]types.AttributeValue{
+		"id":    &types.AttributeValueMemberS{Value: "c1"},
+		"email": &types.AttributeValueMemberS{Value: "a@example.com"},
+		"card":  &types.AttributeValueMemberS{Value: "4111111111111111"},
+		"notes": &types.AttributeValueMemberS{Value: "called about refund"},
+		"tier":  &types.AttributeValueMemberS{Value: "gold"},
+	}
+
+	masked, err := MaskItem(db, &maskedCustomer{}, item)
+	require.NoError(t, err)
+
+	sum := sha256.Sum256([]byte("a@example.com"))
+	require.Equal(t, &types.AttributeValueMemberS{Value: hex.EncodeToString(sum[:])}, masked["email"])
+	require.Equal(t, &types.AttributeValueMemberS{Value: "************1111"}, masked["card"])
+	require.NotContains(t, masked, "notes")
+	require.Equal(t, item["id"], masked["id"])
+	require.Equal(t, item["tier"], masked["tier"])
+
+	require.Contains(t, item, "notes", "input item must not be modified")
+	require.Equal(t, &types.AttributeValueMemberS{Value: "4111111111111111"}, item["card"])
+}
+
+func TestMaskItem_HashKeyAndShortValues(t *testing.T) {
+	db := newBareDB()
+	item := map[string]types.AttributeValue{
+		"email": &types.AttributeValueMemberS{Value: "a@example.com"},
+		"card":  &types.AttributeValueMemberS{Value: "1234"},
+	}
+
+	masked, err := MaskItem(db, &maskedCustomer{}, item, WithMaskHashKey([]byte("secret")))
+	require.NoError(t, err)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("a@example.com"))
+	require.Equal(t, &types.AttributeValueMemberS{Value: hex.EncodeToString(mac.Sum(nil))}, masked["email"])
+	require.Equal(t, &types.AttributeValueMemberS{Value: "**34"}, masked["card"])
+}
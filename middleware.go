@@ -0,0 +1,104 @@
+package dynamorm
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// Operation types passed to middleware.
+const (
+	OperationQuery          = core.OperationQuery
+	OperationScan           = core.OperationScan
+	OperationGetItem        = core.OperationGetItem
+	OperationPutItem        = core.OperationPutItem
+	OperationUpdateItem     = core.OperationUpdateItem
+	OperationDeleteItem     = core.OperationDeleteItem
+	OperationBatchGetItem   = core.OperationBatchGetItem
+	OperationBatchWriteItem = core.OperationBatchWriteItem
+)
+
+// Item cache outcomes recorded in Operation.Cache.
+const (
+	CacheHit  = core.CacheHit
+	CacheMiss = core.CacheMiss
+)
+
+// Operation describes one compiled request on its way to DynamoDB; see core.Operation.
+type Operation = core.Operation
+
+// Handler sends an operation, or hands it to the next middleware.
+type Handler = core.Handler
+
+// Middleware wraps the handler for every operation; see core.Middleware.
+type Middleware = core.Middleware
+
+// Use appends middleware to the chain that every Query, Scan, GetItem, PutItem,
+// UpdateItem, DeleteItem, BatchGetItem, and BatchWriteItem request passes through. The
+// first middleware registered is the outermost. DBs derived afterwards, for example with
+// WithContext, inherit the chain; transactions and PartiQL statements do not pass through
+// it.
+func (db *DB) Use(middleware ...Middleware) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, mw := range middleware {
+		if mw != nil {
+			db.middleware = append(db.middleware, mw)
+		}
+	}
+}
+
+// intercept runs op through the DB's middleware chain, ending in execute. The context a
+// middleware passes on becomes the context of the request. Errors execute returns carry
+// remediation hints, when known, by the time middleware sees them.
+func (qe *queryExecutor) intercept(op *Operation, execute func(qe *queryExecutor, op *Operation) error) error {
+	var chain []Middleware
+	if qe != nil && qe.db != nil {
+		qe.db.mu.RLock()
+		chain = qe.db.middleware
+		qe.db.mu.RUnlock()
+		if err := qe.db.checkMaintenance(op.Table, isWriteOperation(op.Type)); err != nil {
+			return err
+		}
+	}
+	op.Tags = qe.requestTags()
+
+	handler := Handler(func(ctx context.Context, op *Operation) error {
+		inner := *qe
+		inner.ctx = ctx
+		inner.op = op
+		return customerrors.Remediate(execute(&inner, op))
+	})
+	for i := len(chain) - 1; i >= 0; i-- {
+		handler = chain[i](handler)
+	}
+	ctx := qe.ctxOrBackground()
+	err := handler(ctx, op)
+	if isWriteOperation(op.Type) {
+		forgetDedupedReads(ctx)
+	}
+	return err
+}
+
+func isWriteOperation(operation string) bool {
+	switch operation {
+	case OperationPutItem, OperationUpdateItem, OperationDeleteItem, OperationBatchWriteItem:
+		return true
+	default:
+		return false
+	}
+}
+
+func compiledOperation(operation string, input *core.CompiledQuery) *Operation {
+	op := &Operation{Type: operation, Input: input}
+	if input != nil {
+		op.Table = input.TableName
+		op.Index = input.IndexName
+		op.ReturnConsumedCapacity = types.ReturnConsumedCapacity(input.ReturnConsumedCapacity)
+	}
+	return op
+}
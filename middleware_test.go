@@ -0,0 +1,215 @@
+package dynamorm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/leadingkeys"
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+func TestUse_WrapsOperationsInRegistrationOrder(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query":   `{"Items":[],"Count":0}`,
+		"DynamoDB_20120810.PutItem": `{}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, op *Operation) error {
+				calls = append(calls, name+">"+op.Type+":"+op.Table+"/"+op.Index)
+				err := next(ctx, op)
+				calls = append(calls, name+"<")
+				return err
+			}
+		}
+	}
+	db.Use(record("outer"), nil, record("inner"))
+
+	var orders []tenantOrder
+	require.NoError(t, db.Model(&tenantOrder{}).Index("gsi-status").Where("Status", "=", "open").All(&orders))
+	require.NoError(t, db.Model(&tenantOrder{TenantKey: "TENANT#t1", ID: "o1"}).Create())
+
+	require.Equal(t, []string{
+		"outer>Query:tenantOrders/gsi-status", "inner>Query:tenantOrders/gsi-status", "inner<", "outer<",
+		"outer>PutItem:tenantOrders/", "inner>PutItem:tenantOrders/", "inner<", "outer<",
+	}, calls)
+}
+
+func TestUse_ShortCircuitsAndMutatesRequests(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.PutItem": `{}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	errBlocked := errors.New("blocked")
+	db.Use(func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) error {
+			switch op.Type {
+			case OperationGetItem:
+				// Serve the read without calling DynamoDB.
+				dest, ok := op.Dest.(*tenantOrder)
+				require.True(t, ok)
+				dest.Status = "cached"
+				return nil
+			case OperationDeleteItem:
+				return errBlocked
+			case OperationPutItem:
+				op.Item["source"] = &types.AttributeValueMemberS{Value: "middleware"}
+			}
+			return next(ctx, op)
+		}
+	})
+
+	var order tenantOrder
+	require.NoError(t, db.Model(&tenantOrder{}).Where("TenantKey", "=", "TENANT#t1").Where("ID", "=", "o1").First(&order))
+	require.Equal(t, "cached", order.Status)
+
+	err := db.Model(&tenantOrder{TenantKey: "TENANT#t1", ID: "o1"}).Delete()
+	require.ErrorIs(t, err, errBlocked)
+
+	require.NoError(t, db.Model(&tenantOrder{TenantKey: "TENANT#t1", ID: "o2"}).Create())
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	item, ok := put.Payload["Item"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"S": "middleware"}, item["source"])
+
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.GetItem"))
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.DeleteItem"))
+}
+
+func TestUse_ContextFlowsToRequest(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[],"Count":0}`,
+	})
+	db := newStubbedDB(t, httpClient).WithLeadingKeys(leadingkeys.Rule{Template: "TENANT#{tenant}"})
+	db.Use(func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) error {
+			return next(leadingkeys.WithTenant(ctx, "t1"), op)
+		}
+	})
+
+	var orders []tenantOrder
+	require.NoError(t, db.Model(&tenantOrder{}).Where("TenantKey", "=", "TENANT#t1").All(&orders))
+	err := db.Model(&tenantOrder{}).Where("TenantKey", "=", "TENANT#t2").All(&orders)
+	require.ErrorIs(t, err, leadingkeys.ErrDenied)
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Query"))
+}
+
+func TestUse_DerivedDBsInheritChain(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[],"Count":0}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var parent, child int
+	db.Use(func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) error {
+			parent++
+			return next(ctx, op)
+		}
+	})
+	derived, ok := db.WithContext(context.Background()).(*DB)
+	require.True(t, ok)
+	derived.Use(func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) error {
+			child++
+			return next(ctx, op)
+		}
+	})
+
+	var orders []tenantOrder
+	require.NoError(t, db.Model(&tenantOrder{}).Where("TenantKey", "=", "TENANT#t1").All(&orders))
+	require.NoError(t, derived.Model(&tenantOrder{}).Where("TenantKey", "=", "TENANT#t1").All(&orders))
+	require.Equal(t, 2, parent)
+	require.Equal(t, 1, child)
+}
+
+func TestUse_RecordsItemCountsAndConsumedCapacity(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.PutItem": `{"ConsumedCapacity":{"TableName":"tenantOrders","CapacityUnits":1}}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.Query", []stubbedResponse{
+		{body: `{"Items":[{"tenantKey":{"S":"TENANT#t1"},"id":{"S":"o1"}}],"Count":1,"ScannedCount":3,` +
+			`"ConsumedCapacity":{"TableName":"tenantOrders","CapacityUnits":0.5},` +
+			`"LastEvaluatedKey":{"tenantKey":{"S":"TENANT#t1"},"id":{"S":"o1"}}}`},
+		{body: `{"Items":[{"tenantKey":{"S":"TENANT#t1"},"id":{"S":"o2"}}],"Count":1,"ScannedCount":1,` +
+			`"ConsumedCapacity":{"TableName":"tenantOrders","CapacityUnits":0.5}}`},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var ops []*Operation
+	db.Use(func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) error {
+			op.ReturnConsumedCapacity = types.ReturnConsumedCapacityTotal
+			ops = append(ops, op)
+			return next(ctx, op)
+		}
+	})
+
+	var orders []tenantOrder
+	require.NoError(t, db.Model(&tenantOrder{}).Where("TenantKey", "=", "TENANT#t1").All(&orders))
+	require.NoError(t, db.Model(&tenantOrder{TenantKey: "TENANT#t1", ID: "o3"}).Create())
+
+	require.Len(t, ops, 2)
+	require.Equal(t, 2, ops[0].ItemCount)
+	require.Equal(t, 4, ops[0].ScannedCount)
+	require.Len(t, ops[0].ConsumedCapacity, 2)
+	require.Equal(t, 0.5, *ops[0].ConsumedCapacity[1].CapacityUnits)
+	require.Equal(t, 1, ops[1].ItemCount)
+	require.Len(t, ops[1].ConsumedCapacity, 1)
+
+	query := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, query)
+	require.Equal(t, "TOTAL", query.Payload["ReturnConsumedCapacity"])
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	require.Equal(t, "TOTAL", put.Payload["ReturnConsumedCapacity"])
+}
+
+func TestUse_RecordsAttemptsAndThrottles(t *testing.T) {
+	throttled := stubbedResponse{
+		status: http.StatusBadRequest,
+		body:   `{"__type":"com.amazonaws.dynamodb.v20120810#ProvisionedThroughputExceededException","message":"slow down"}`,
+	}
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.PutItem", []stubbedResponse{throttled, {body: `{}`}, throttled, throttled})
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{
+		DynamoDBOptions: []func(*dynamodb.Options){func(o *dynamodb.Options) {
+			o.Retryer = retry.NewStandard(func(so *retry.StandardOptions) {
+				so.MaxAttempts = 2
+				so.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+				so.RateLimiter = ratelimit.None
+			})
+		}},
+	})
+
+	var ops []*Operation
+	db.Use(func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) error {
+			ops = append(ops, op)
+			return next(ctx, op)
+		}
+	})
+
+	require.NoError(t, db.Model(&tenantOrder{TenantKey: "TENANT#t1", ID: "o1"}).Create())
+	require.Error(t, db.Model(&tenantOrder{TenantKey: "TENANT#t1", ID: "o2"}).Create())
+
+	require.Len(t, ops, 2)
+	require.Equal(t, 2, ops[0].Attempts)
+	require.Equal(t, 1, ops[0].Throttles)
+	require.Equal(t, 2, ops[1].Attempts)
+	require.Equal(t, 1, ops[1].Throttles)
+}
@@ -0,0 +1,73 @@
+package dynamorm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+type reloadableEvent struct {
+	Detail any    `dynamorm:"attr:detail"`
+	ID     string `dynamorm:"pk,attr:id"`
+}
+
+func (reloadableEvent) TableName() string { return "reloadable_events" }
+
+func TestInvalidateModelCache_RebuildsMetadataAndMarshalers(t *testing.T) {
+	action := model.FieldSkip
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{
+		FieldPolicy: model.FieldPolicy{
+			Resolve: func(reflect.Type, reflect.StructField, model.FieldKind) (model.FieldAction, error) {
+				return action, nil
+			},
+		},
+	})
+	derived := db.WithRequestTags(map[string]string{"team": "payments"})
+
+	event := &reloadableEvent{ID: "e1", Detail: "created"}
+	require.NoError(t, db.Model(event).Create())
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	require.NotContains(t, put.Payload["Item"], "detail")
+
+	action = model.FieldStore
+	require.NoError(t, db.InvalidateModelCache(&reloadableEvent{}))
+
+	require.NoError(t, db.Model(event).Create())
+	require.NoError(t, derived.Model(event).Create())
+	var puts []map[string]any
+	for _, req := range httpClient.Requests() {
+		if req.Target == "DynamoDB_20120810.PutItem" {
+			puts = append(puts, req.Payload["Item"].(map[string]any))
+		}
+	}
+	require.Len(t, puts, 3)
+	require.Equal(t, map[string]any{"S": "created"}, puts[1]["detail"])
+	require.Equal(t, map[string]any{"S": "created"}, puts[2]["detail"])
+}
+
+func TestInvalidateModelCache_KeepsMetadataWhenReparseFails(t *testing.T) {
+	action := model.FieldSkip
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{
+		FieldPolicy: model.FieldPolicy{
+			Resolve: func(reflect.Type, reflect.StructField, model.FieldKind) (model.FieldAction, error) {
+				return action, nil
+			},
+		},
+	})
+	require.NoError(t, db.Model(&reloadableEvent{ID: "e1"}).Create())
+
+	action = model.FieldReject
+	require.ErrorIs(t, db.InvalidateModelCache(&reloadableEvent{}), customerrors.ErrUnsupportedType)
+	require.ErrorContains(t, db.InvalidateModelCache(nil), "model cannot be nil")
+
+	require.NoError(t, db.Model(&reloadableEvent{ID: "e2"}).Create())
+	require.Equal(t, 2, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem"))
+}
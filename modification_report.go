@@ -0,0 +1,315 @@
+package dynamorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/contention"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// defaultConcurrentWindow is how close together writes by different writers must be
+// for ModificationReport to flag them, unless ModificationReportOptions.Window is set.
+const defaultConcurrentWindow = time.Second
+
+// ItemChange is one recorded write of an item: a stream record, or an entry of an audit
+// table's version history.
+type ItemChange struct {
+	Time time.Time
+	// ID identifies the change in its source, such as a stream sequence number. Changes
+	// recorded at the same time are ordered by ID.
+	ID string
+	// Event is INSERT, MODIFY, or REMOVE.
+	Event string
+	// Writer names who made the change, when the source records it. Stream records only
+	// name the writer of Time to Live deletes; ModificationReportOptions.WriterField reads
+	// it from an attribute the application writes instead.
+	Writer   string
+	OldImage map[string]types.AttributeValue
+	NewImage map[string]types.AttributeValue
+}
+
+// ChangeFromStreamRecord converts a DynamoDB stream record delivered to Lambda.
+func ChangeFromStreamRecord(record events.DynamoDBEventRecord) ItemChange {
+	change := ItemChange{
+		Time:     record.Change.ApproximateCreationDateTime.Time,
+		ID:       record.Change.SequenceNumber,
+		Event:    record.EventName,
+		OldImage: convertLambdaImage(record.Change.OldImage),
+		NewImage: convertLambdaImage(record.Change.NewImage),
+	}
+	if IsTTLExpiry(record) {
+		change.Writer = "ttl"
+	} else if record.UserIdentity != nil {
+		change.Writer = record.UserIdentity.PrincipalID
+	}
+	return change
+}
+
+func convertLambdaImage(image map[string]events.DynamoDBAttributeValue) map[string]types.AttributeValue {
+	if len(image) == 0 {
+		return nil
+	}
+	item := make(map[string]types.AttributeValue, len(image))
+	for name, value := range image {
+		item[name] = convertLambdaAttributeValue(value)
+	}
+	return item
+}
+
+// ChangeSource returns the recorded changes of the item with key in table. The changes
+// may include other items' and be in any order; ModificationReport filters and sorts
+// them.
+type ChangeSource func(ctx context.Context, table string, key map[string]types.AttributeValue) ([]ItemChange, error)
+
+// StreamRecords returns a ChangeSource over stream records already read, such as the
+// batches a stream Lambda keeps for debugging or a tail of the stream dumped to a file.
+func StreamRecords(records ...events.DynamoDBEventRecord) ChangeSource {
+	return func(_ context.Context, table string, _ map[string]types.AttributeValue) ([]ItemChange, error) {
+		changes := make([]ItemChange, 0, len(records))
+		for _, record := range records {
+			if arnTable := streamTableName(record.EventSourceArn); arnTable != "" && arnTable != table {
+				continue
+			}
+			changes = append(changes, ChangeFromStreamRecord(record))
+		}
+		return changes, nil
+	}
+}
+
+// ModificationReportOptions configures ModificationReport.
+type ModificationReportOptions struct {
+	// WriterField is the model field, such as "UpdatedBy", that names the writer of each
+	// version of the item. Changes whose source did not record a writer take it from the
+	// field in their new image, or old image for deletes.
+	WriterField string
+	// Since omits older changes.
+	Since time.Time
+	// Window flags writes by different writers less than Window apart. Defaults to one
+	// second.
+	Window time.Duration
+}
+
+// ModificationReport is the history of writes to one item, oldest first, with the
+// writes that suggest a lost update flagged.
+type ModificationReport struct {
+	Table   string
+	Key     string
+	Changes []ModificationEntry
+}
+
+// ModificationEntry is one change in a ModificationReport.
+type ModificationEntry struct {
+	ItemChange
+	// OldVersion and NewVersion are the model's version field before and after the
+	// change, or empty when the image does not have it.
+	OldVersion string
+	NewVersion string
+	// Changed lists the attributes the change added, modified, or removed.
+	Changed []string
+	// Flags explains why the change looks like a concurrent modification.
+	Flags []string
+}
+
+// Flagged returns the changes that have flags.
+func (r *ModificationReport) Flagged() []ModificationEntry {
+	var flagged []ModificationEntry
+	for _, entry := range r.Changes {
+		if len(entry.Flags) > 0 {
+			flagged = append(flagged, entry)
+		}
+	}
+	return flagged
+}
+
+// String renders the report as a table, one change per line, with each flag on its
+// own line below the change.
+func (r *ModificationReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s: %d changes, %d flagged\n", r.Table, r.Key, len(r.Changes), len(r.Flagged()))
+
+	w := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	for _, entry := range r.Changes {
+		writer := entry.Writer
+		if writer == "" {
+			writer = "-"
+		}
+		version := ""
+		if entry.OldVersion != "" || entry.NewVersion != "" {
+			version = fmt.Sprintf("v%s→%s", valueOr(entry.OldVersion, "-"), valueOr(entry.NewVersion, "-"))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.Time.UTC().Format(time.RFC3339Nano), entry.ID, entry.Event, writer, version, strings.Join(entry.Changed, ","))
+		for _, flag := range entry.Flags {
+			fmt.Fprintf(w, "\t\t! %s\n", flag)
+		}
+	}
+	_ = w.Flush()
+	return b.String()
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// ModificationReport collects the recorded changes of the item whose key fields are set
+// on model and lays out who wrote what, in order, to diagnose "who overwrote this item"
+// incidents. A change is flagged when:
+//
+//   - its old image differs from the previous change's new image, so a write is
+//     missing from the source or was made without reading the item first;
+//   - it kept or lowered the version of a versioned model, so it skipped the
+//     optimistic lock;
+//   - it came from a different writer less than Window after the previous change.
+//
+// Stream records are the usual source; StreamRecords wraps records already read:
+//
+//	report, err := db.ModificationReport(ctx, &Account{ID: "acct-1"},
+//		dynamorm.StreamRecords(records...),
+//		dynamorm.ModificationReportOptions{WriterField: "UpdatedBy"})
+//	fmt.Print(report)
+func (db *DB) ModificationReport(ctx context.Context, modelValue any, source ChangeSource, opts ModificationReportOptions) (*ModificationReport, error) {
+	if source == nil {
+		return nil, errors.New("modification report requires a change source")
+	}
+	v := reflect.ValueOf(modelValue)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("modification report: model must be a non-nil pointer to a struct, got %T", modelValue)
+	}
+	meta, err := db.metadataFor(modelValue)
+	if err != nil {
+		return nil, err
+	}
+	key, err := db.snapshotKey(meta, v.Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	var writerField *model.FieldMetadata
+	if opts.WriterField != "" {
+		if writerField = meta.Fields[opts.WriterField]; writerField == nil {
+			return nil, fmt.Errorf("model %T has no field %s", modelValue, opts.WriterField)
+		}
+	}
+	window := opts.Window
+	if window <= 0 {
+		window = defaultConcurrentWindow
+	}
+
+	changes, err := source(ctx, meta.TableName, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changes of %s: %w", meta.TableName, err)
+	}
+
+	report := &ModificationReport{Table: meta.TableName, Key: describeItemKey(meta, key)}
+	for _, change := range changes {
+		if !changeHasKey(change, key) || change.Time.Before(opts.Since) {
+			continue
+		}
+		if change.Writer == "" && writerField != nil {
+			change.Writer = imageString(latestImage(change), writerField.DBName)
+		}
+		entry := ModificationEntry{ItemChange: change, Changed: changedAttributes(change.OldImage, change.NewImage)}
+		if meta.VersionField != nil {
+			entry.OldVersion = imageString(change.OldImage, meta.VersionField.DBName)
+			entry.NewVersion = imageString(change.NewImage, meta.VersionField.DBName)
+		}
+		report.Changes = append(report.Changes, entry)
+	}
+	sort.SliceStable(report.Changes, func(i, j int) bool {
+		a, b := report.Changes[i], report.Changes[j]
+		if !a.Time.Equal(b.Time) {
+			return a.Time.Before(b.Time)
+		}
+		return lessSequence(a.ID, b.ID)
+	})
+
+	for i := range report.Changes {
+		entry := &report.Changes[i]
+		if entry.Event == string(events.DynamoDBOperationTypeModify) && entry.OldVersion != "" && !versionAdvanced(entry.OldVersion, entry.NewVersion) {
+			entry.Flags = append(entry.Flags, fmt.Sprintf("version went from %s to %s; the write skipped the version check", entry.OldVersion, valueOr(entry.NewVersion, "none")))
+		}
+		if i == 0 {
+			continue
+		}
+		previous := report.Changes[i-1]
+		if previous.NewImage != nil && entry.OldImage != nil && !reflect.DeepEqual(previous.NewImage, entry.OldImage) {
+			entry.Flags = append(entry.Flags, fmt.Sprintf("old image differs from the result of %s in %s", valueOr(previous.ID, "the previous change"), strings.Join(changedAttributes(previous.NewImage, entry.OldImage), ",")))
+		}
+		if entry.Writer != "" && previous.Writer != "" && entry.Writer != previous.Writer && entry.Time.Sub(previous.Time) < window {
+			entry.Flags = append(entry.Flags, fmt.Sprintf("written by %s %s after %s's write", entry.Writer, entry.Time.Sub(previous.Time), previous.Writer))
+		}
+	}
+	return report, nil
+}
+
+// changeHasKey reports whether change is a write of the item with key.
+func changeHasKey(change ItemChange, key map[string]types.AttributeValue) bool {
+	image := latestImage(change)
+	for name, value := range key {
+		if !reflect.DeepEqual(image[name], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// latestImage returns the item as the change left it, or as it was before a delete.
+func latestImage(change ItemChange) map[string]types.AttributeValue {
+	if change.NewImage != nil {
+		return change.NewImage
+	}
+	return change.OldImage
+}
+
+// changedAttributes returns the sorted names of the attributes that differ between the
+// images.
+func changedAttributes(before, after map[string]types.AttributeValue) []string {
+	var names []string
+	for name, value := range after {
+		if previous, ok := before[name]; !ok || !reflect.DeepEqual(previous, value) {
+			names = append(names, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func imageString(image map[string]types.AttributeValue, name string) string {
+	if value, ok := image[name]; ok {
+		return contention.KeyString(value)
+	}
+	return ""
+}
+
+// versionAdvanced reports whether the numeric version increased.
+func versionAdvanced(before, after string) bool {
+	return after != "" && lessSequence(before, after)
+}
+
+// lessSequence compares decimal strings, such as stream sequence numbers and versions,
+// numerically.
+func lessSequence(a, b string) bool {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
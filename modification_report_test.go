@@ -0,0 +1,118 @@
+package dynamorm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/require"
+)
+
+type auditedAccount struct {
+	ID        string `dynamorm:"pk,attr:id"`
+	UpdatedBy string `dynamorm:"attr:updatedBy"`
+	Balance   int64  `dynamorm:"attr:balance"`
+	Version   int64  `dynamorm:"version,attr:version"`
+}
+
+func accountImage(id, writer, balance, version string) map[string]events.DynamoDBAttributeValue {
+	return map[string]events.DynamoDBAttributeValue{
+		"id":        events.NewStringAttribute(id),
+		"updatedBy": events.NewStringAttribute(writer),
+		"balance":   events.NewNumberAttribute(balance),
+		"version":   events.NewNumberAttribute(version),
+	}
+}
+
+func accountRecord(table, sequence, event string, at time.Time, oldImage, newImage map[string]events.DynamoDBAttributeValue) events.DynamoDBEventRecord {
+	return events.DynamoDBEventRecord{
+		EventName:      event,
+		EventSourceArn: "arn:aws:dynamodb:us-east-1:123456789012:table/" + table + "/stream/2026-01-01T00:00:00.000",
+		Change: events.DynamoDBStreamRecord{
+			ApproximateCreationDateTime: events.SecondsEpochTime{Time: at},
+			SequenceNumber:              sequence,
+			OldImage:                    oldImage,
+			NewImage:                    newImage,
+		},
+	}
+}
+
+func TestModificationReport_FlagsOverwrites(t *testing.T) {
+	db := newStubbedDB(t, newCapturingHTTPClient(nil))
+	table, err := db.TableName(&auditedAccount{})
+	require.NoError(t, err)
+
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	created := accountImage("a1", "api", "100", "1")
+	debited := accountImage("a1", "api", "70", "2")
+	overwritten := accountImage("a1", "batch-job", "120", "2")
+	records := []events.DynamoDBEventRecord{
+		// Delivered out of order, and mixed with another item's record.
+		accountRecord(table, "300", "MODIFY", start.Add(2*time.Second), debited, overwritten),
+		accountRecord(table, "100", "INSERT", start, nil, created),
+		accountRecord(table, "150", "INSERT", start, nil, accountImage("a2", "api", "5", "1")),
+		accountRecord(table, "200", "MODIFY", start.Add(1500*time.Millisecond), created, debited),
+	}
+
+	report, err := db.ModificationReport(context.Background(), &auditedAccount{ID: "a1"}, StreamRecords(records...),
+		ModificationReportOptions{WriterField: "UpdatedBy"})
+	require.NoError(t, err)
+
+	require.Equal(t, table, report.Table)
+	require.Equal(t, "id=a1", report.Key)
+	require.Len(t, report.Changes, 3)
+	require.Equal(t, []string{"100", "200", "300"}, []string{report.Changes[0].ID, report.Changes[1].ID, report.Changes[2].ID})
+	require.Equal(t, []string{"balance", "id", "updatedBy", "version"}, report.Changes[0].Changed)
+	require.Equal(t, []string{"balance", "version"}, report.Changes[1].Changed)
+
+	flagged := report.Flagged()
+	require.Len(t, flagged, 1)
+	overwrite := flagged[0]
+	require.Equal(t, "batch-job", overwrite.Writer)
+	require.Equal(t, "2", overwrite.OldVersion)
+	require.Equal(t, "2", overwrite.NewVersion)
+	require.Len(t, overwrite.Flags, 2)
+	require.Contains(t, overwrite.Flags[0], "skipped the version check")
+	require.Contains(t, overwrite.Flags[1], "written by batch-job 500ms after api's write")
+
+	rendered := report.String()
+	require.True(t, strings.HasPrefix(rendered, table+" id=a1: 3 changes, 1 flagged\n"), rendered)
+	require.Contains(t, rendered, "v2→2")
+	require.Contains(t, rendered, "! version went from 2 to 2")
+}
+
+func TestModificationReport_FlagsMissingChanges(t *testing.T) {
+	db := newStubbedDB(t, newCapturingHTTPClient(nil))
+	table, err := db.TableName(&auditedAccount{})
+	require.NoError(t, err)
+
+	start := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	records := []events.DynamoDBEventRecord{
+		accountRecord(table, "100", "INSERT", start, nil, accountImage("a1", "api", "100", "1")),
+		accountRecord(table, "300", "MODIFY", start.Add(time.Minute), accountImage("a1", "api", "90", "2"), accountImage("a1", "api", "80", "3")),
+	}
+
+	report, err := db.ModificationReport(context.Background(), &auditedAccount{ID: "a1"}, StreamRecords(records...), ModificationReportOptions{})
+	require.NoError(t, err)
+	require.Len(t, report.Flagged(), 1)
+	require.Equal(t, []string{"old image differs from the result of 100 in balance,version"}, report.Changes[1].Flags)
+
+	report, err = db.ModificationReport(context.Background(), &auditedAccount{ID: "a1"}, StreamRecords(records...),
+		ModificationReportOptions{Since: start.Add(time.Second)})
+	require.NoError(t, err)
+	require.Len(t, report.Changes, 1)
+	require.Empty(t, report.Flagged())
+}
+
+func TestModificationReport_RequiresKey(t *testing.T) {
+	db := newStubbedDB(t, newCapturingHTTPClient(nil))
+
+	_, err := db.ModificationReport(context.Background(), &auditedAccount{}, StreamRecords(), ModificationReportOptions{})
+	require.Error(t, err)
+	_, err = db.ModificationReport(context.Background(), &auditedAccount{ID: "a1"}, StreamRecords(), ModificationReportOptions{WriterField: "Missing"})
+	require.Error(t, err)
+	_, err = db.ModificationReport(context.Background(), &auditedAccount{ID: "a1"}, nil, ModificationReportOptions{})
+	require.Error(t, err)
+}
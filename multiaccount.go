@@ -7,7 +7,6 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log"
-	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -20,13 +19,23 @@ import (
 	"github.com/pay-theory/dynamorm/pkg/session"
 )
 
-// MultiAccountDB manages DynamoDB connections across multiple AWS accounts
+// credentialRefreshWindow is how long before they expire partner credentials are
+// refreshed, by the refresh routine or by the first request inside the window.
+const credentialRefreshWindow = 10 * time.Minute
+
+// MultiAccountDB manages DynamoDB connections across multiple AWS accounts. Each partner's
+// DB is created once and cached. Partner DBs share the base DB's HTTP connections and
+// sign requests with the partner role's credentials, which are assumed on the first
+// request and refreshed in the background before they expire.
 type MultiAccountDB struct {
 	baseDB        *LambdaDB
 	accounts      map[string]AccountConfig
 	cache         *sync.Map
 	refreshTicker *time.Ticker
 	refreshStop   chan struct{}
+	stsClient     stscreds.AssumeRoleAPIClient
+	httpClient    aws.HTTPClient
+	observe       func(AssumeRoleResult)
 	baseConfig    aws.Config
 	mu            sync.RWMutex
 }
@@ -40,8 +49,45 @@ type AccountConfig struct {
 	SessionDuration time.Duration
 }
 
+// AssumeRoleResult describes one call to STS AssumeRole for a partner, for metrics.
+type AssumeRoleResult struct {
+	Expires   time.Time
+	Err       error
+	PartnerID string
+	RoleARN   string
+	Duration  time.Duration
+}
+
+// MultiAccountOption configures NewMultiAccount.
+type MultiAccountOption func(*MultiAccountDB)
+
+// WithAssumeRoleObserver calls fn after every AssumeRole call made for a partner, with
+// its latency and the expiry of the credentials it returned:
+//
+//	dynamorm.WithAssumeRoleObserver(func(r dynamorm.AssumeRoleResult) {
+//		metrics.Histogram("dynamorm.assume_role.latency", r.Duration, "partner:"+r.PartnerID)
+//	})
+//
+// fn is called from the goroutine refreshing the credentials and must be safe for
+// concurrent use.
+func WithAssumeRoleObserver(fn func(AssumeRoleResult)) MultiAccountOption {
+	return func(mdb *MultiAccountDB) {
+		mdb.observe = fn
+	}
+}
+
+// WithPartnerHTTPClient sets the HTTP client partner DBs share. The default is the base
+// DB's client.
+func WithPartnerHTTPClient(client aws.HTTPClient) MultiAccountOption {
+	return func(mdb *MultiAccountDB) {
+		if client != nil {
+			mdb.httpClient = client
+		}
+	}
+}
+
 // NewMultiAccount creates a multi-account aware DB
-func NewMultiAccount(accounts map[string]AccountConfig) (*MultiAccountDB, error) {
+func NewMultiAccount(accounts map[string]AccountConfig, opts ...MultiAccountOption) (*MultiAccountDB, error) {
 	baseDB, err := NewLambdaOptimized()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create base Lambda DB: %w", err)
@@ -58,8 +104,17 @@ func NewMultiAccount(accounts map[string]AccountConfig) (*MultiAccountDB, error)
 		accounts:    accounts,
 		cache:       &sync.Map{},
 		baseConfig:  baseConfig,
+		stsClient:   sts.NewFromConfig(baseConfig),
 		refreshStop: make(chan struct{}),
 	}
+	if baseDB.db != nil && baseDB.db.session != nil {
+		mdb.httpClient = baseDB.db.session.AWSConfig().HTTPClient
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(mdb)
+		}
+	}
 
 	// Start credential refresh routine
 	mdb.startCredentialRefresh()
@@ -67,7 +122,9 @@ func NewMultiAccount(accounts map[string]AccountConfig) (*MultiAccountDB, error)
 	return mdb, nil
 }
 
-// Partner returns a DB instance for the specified partner account
+// Partner returns a DB instance for the specified partner account. The partner's DB is
+// cached; once its credentials are due for refresh they are refreshed before it is returned,
+// and Partner fails if STS cannot refresh them.
 func (mdb *MultiAccountDB) Partner(partnerID string) (*LambdaDB, error) {
 	// Empty partner ID returns base DB
 	if partnerID == "" {
@@ -76,8 +133,15 @@ func (mdb *MultiAccountDB) Partner(partnerID string) (*LambdaDB, error) {
 
 	// Check cache first
 	if cached, ok := mdb.cache.Load(partnerID); ok {
-		if entry, ok := cached.(*cacheEntry); ok && !entry.isExpired() {
-			return entry.db, nil
+		if entry, ok := cached.(*cacheEntry); ok && entry != nil {
+			if !entry.needsRefresh(time.Now()) {
+				return entry.db, nil
+			}
+			db, err := mdb.refreshPartner(context.Background(), partnerID, entry)
+			if err != nil {
+				return nil, fmt.Errorf("credentials of partner %s expired and could not be refreshed: %w", sanitizePartnerID(partnerID), err)
+			}
+			return db, nil
 		}
 	}
 
@@ -94,11 +158,14 @@ func (mdb *MultiAccountDB) Partner(partnerID string) (*LambdaDB, error) {
 	return mdb.createPartnerDB(partnerID, account)
 }
 
-// AddPartner dynamically adds a new partner configuration
+// AddPartner dynamically adds a new partner configuration, replacing the cached
+// connection of a partner added before
 func (mdb *MultiAccountDB) AddPartner(partnerID string, config AccountConfig) {
 	mdb.mu.Lock()
-	defer mdb.mu.Unlock()
 	mdb.accounts[partnerID] = config
+	mdb.mu.Unlock()
+
+	mdb.cache.Delete(partnerID)
 }
 
 // RemovePartner removes a partner and clears its cached connection
@@ -112,8 +179,15 @@ func (mdb *MultiAccountDB) RemovePartner(partnerID string) {
 
 // createPartnerDB creates a new DB instance for a partner account
 func (mdb *MultiAccountDB) createPartnerDB(partnerID string, account AccountConfig) (*LambdaDB, error) {
-	// Create STS client
-	stsClient := sts.NewFromConfig(mdb.baseConfig)
+	mdb.mu.RLock()
+	stsClient, httpClient, observe := mdb.stsClient, mdb.httpClient, mdb.observe
+	mdb.mu.RUnlock()
+	if stsClient == nil {
+		stsClient = sts.NewFromConfig(mdb.baseConfig)
+	}
+	if observe != nil {
+		stsClient = observedAssumeRole{client: stsClient, observe: observe, partnerID: partnerID}
+	}
 
 	// Set session duration (default to 1 hour)
 	sessionDuration := account.SessionDuration
@@ -121,12 +195,23 @@ func (mdb *MultiAccountDB) createPartnerDB(partnerID string, account AccountConf
 		sessionDuration = time.Hour
 	}
 
-	// Create credentials provider for assume role
-	creds := stscreds.NewAssumeRoleProvider(stsClient, account.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+	// Cache the role's credentials so requests share them until they need refreshing,
+	// and record when that is whenever the role is assumed
+	assumeRole := stscreds.NewAssumeRoleProvider(stsClient, account.RoleARN, func(o *stscreds.AssumeRoleOptions) {
 		o.ExternalID = &account.ExternalID
 		o.RoleSessionName = fmt.Sprintf("dynamorm-%s", partnerID)
 		o.Duration = sessionDuration
 	})
+	var creds *aws.CredentialsCache
+	creds = aws.NewCredentialsCache(aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+		retrieved, err := assumeRole.Retrieve(ctx)
+		if err == nil {
+			mdb.recordRefresh(partnerID, creds, retrieved)
+		}
+		return retrieved, err
+	}), func(o *aws.CredentialsCacheOptions) {
+		o.ExpiryWindow = credentialRefreshWindow
+	})
 
 	// Create new config with assumed role
 	awsConfigOptions := []func(*config.LoadOptions) error{
@@ -134,19 +219,14 @@ func (mdb *MultiAccountDB) createPartnerDB(partnerID string, account AccountConf
 		config.WithCredentialsProvider(creds),
 	}
 
+	// Share one connection pool across partners
+	if httpClient != nil {
+		awsConfigOptions = append(awsConfigOptions, config.WithHTTPClient(httpClient))
+	}
+
 	// Add Lambda optimizations if in Lambda environment
 	if IsLambdaEnvironment() {
-		httpClient := &http.Client{
-			Timeout: 5 * time.Second,
-			Transport: &http.Transport{
-				MaxIdleConns:        10,
-				MaxIdleConnsPerHost: 10,
-				IdleConnTimeout:     90 * time.Second,
-				DisableKeepAlives:   false,
-			},
-		}
 		awsConfigOptions = append(awsConfigOptions,
-			config.WithHTTPClient(httpClient),
 			config.WithRetryMode(aws.RetryModeAdaptive),
 			config.WithRetryMaxAttempts(3),
 		)
@@ -175,6 +255,9 @@ func (mdb *MultiAccountDB) createPartnerDB(partnerID string, account AccountConf
 		return nil, fmt.Errorf("failed to get concrete DB implementation for partner %s", partnerID)
 	}
 
+	// Keep cached items and de-duplicated reads of partners apart
+	concreteDB.principal = principalID("partner", account.RoleARN, account.ExternalID)
+
 	lambdaDB := &LambdaDB{
 		ExtendedDB:     db,
 		db:             concreteDB,
@@ -184,18 +267,57 @@ func (mdb *MultiAccountDB) createPartnerDB(partnerID string, account AccountConf
 		xrayEnabled:    EnableXRayTracing(),
 	}
 
-	// Cache with expiration
+	// Credentials are assumed on the first request, which records when they need refreshing
 	entry := &cacheEntry{
-		db:         lambdaDB,
-		expiry:     time.Now().Add(sessionDuration - 5*time.Minute), // Refresh 5 minutes before expiry
-		partnerID:  partnerID,
-		accountCfg: account,
+		db:          lambdaDB,
+		credentials: creds,
+		partnerID:   partnerID,
+		accountCfg:  account,
 	}
 	mdb.cache.Store(partnerID, entry)
 
 	return lambdaDB, nil
 }
 
+// refreshPartner refreshes the credentials of a cached partner DB that expire within
+// credentialRefreshWindow and records when they need refreshing next. Entries without
+// credentials of their own are rebuilt.
+func (mdb *MultiAccountDB) refreshPartner(ctx context.Context, partnerID string, entry *cacheEntry) (*LambdaDB, error) {
+	if entry.credentials == nil {
+		return mdb.createPartnerDB(partnerID, entry.accountCfg)
+	}
+
+	// Assuming the role again records when the new credentials need refreshing
+	if _, err := entry.credentials.Retrieve(ctx); err != nil {
+		return nil, err
+	}
+	return entry.db, nil
+}
+
+// recordRefresh replaces the cached entry of partnerID holding credentials with one due
+// for refresh credentialRefreshWindow before creds, just assumed, expire. An entry that
+// was removed or replaced meanwhile is left alone.
+func (mdb *MultiAccountDB) recordRefresh(partnerID string, credentials *aws.CredentialsCache, creds aws.Credentials) {
+	if !creds.CanExpire {
+		return
+	}
+	for {
+		value, ok := mdb.cache.Load(partnerID)
+		if !ok {
+			return
+		}
+		entry, ok := value.(*cacheEntry)
+		if !ok || entry == nil || entry.credentials != credentials {
+			return
+		}
+		refreshed := *entry
+		refreshed.refreshAt = creds.Expires.Add(-credentialRefreshWindow)
+		if mdb.cache.CompareAndSwap(partnerID, entry, &refreshed) {
+			return
+		}
+	}
+}
+
 // startCredentialRefresh starts a background routine to refresh credentials
 func (mdb *MultiAccountDB) startCredentialRefresh() {
 	mdb.refreshTicker = time.NewTicker(5 * time.Minute)
@@ -228,10 +350,10 @@ func (mdb *MultiAccountDB) refreshExpiredCredentials() {
 		}
 
 		// Check if credentials are about to expire
-		if now.After(entry.expiry.Add(-10 * time.Minute)) {
+		if entry.needsRefresh(now) {
 			// Refresh in background
 			go func() {
-				_, err := mdb.createPartnerDB(partnerID, entry.accountCfg)
+				_, err := mdb.refreshPartner(context.Background(), partnerID, entry)
 				if err != nil {
 					// SECURITY: Log without exposing sensitive credential details
 					// Generate operation ID for correlation
@@ -256,6 +378,9 @@ func (mdb *MultiAccountDB) Close() error {
 		mdb.refreshTicker.Stop()
 	}
 	close(mdb.refreshStop)
+	if client, ok := mdb.httpClient.(interface{ CloseIdleConnections() }); ok {
+		client.CloseIdleConnections()
+	}
 	return mdb.baseDB.Close()
 }
 
@@ -266,6 +391,9 @@ func (mdb *MultiAccountDB) WithContext(ctx context.Context) *MultiAccountDB {
 		baseDB:        mdb.baseDB.WithLambdaTimeout(ctx),
 		accounts:      mdb.accounts,
 		baseConfig:    mdb.baseConfig,
+		stsClient:     mdb.stsClient,
+		httpClient:    mdb.httpClient,
+		observe:       mdb.observe,
 		refreshTicker: mdb.refreshTicker,
 		refreshStop:   mdb.refreshStop,
 	}
@@ -274,17 +402,46 @@ func (mdb *MultiAccountDB) WithContext(ctx context.Context) *MultiAccountDB {
 	return newMDB
 }
 
-// cacheEntry holds a cached DB connection with expiration
+// cacheEntry holds a cached DB connection with the time its credentials need refreshing,
+// credentialRefreshWindow before they expire. refreshAt is zero until the credentials
+// are first assumed. Entries are replaced, not modified, when their credentials are
+// refreshed.
 type cacheEntry struct {
-	db         *LambdaDB
-	expiry     time.Time
-	partnerID  string
-	accountCfg AccountConfig
+	db          *LambdaDB
+	credentials *aws.CredentialsCache
+	refreshAt   time.Time
+	partnerID   string
+	accountCfg  AccountConfig
+}
+
+// observedAssumeRole reports the latency of a partner's AssumeRole calls.
+type observedAssumeRole struct {
+	client    stscreds.AssumeRoleAPIClient
+	observe   func(AssumeRoleResult)
+	partnerID string
+}
+
+func (o observedAssumeRole) AssumeRole(ctx context.Context, params *sts.AssumeRoleInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	start := time.Now()
+	out, err := o.client.AssumeRole(ctx, params, optFns...)
+
+	result := AssumeRoleResult{
+		Err:       err,
+		PartnerID: o.partnerID,
+		RoleARN:   aws.ToString(params.RoleArn),
+		Duration:  time.Since(start),
+	}
+	if out != nil && out.Credentials != nil {
+		result.Expires = aws.ToTime(out.Credentials.Expiration)
+	}
+	o.observe(result)
+	return out, err
 }
 
-// isExpired checks if the cache entry has expired
-func (e *cacheEntry) isExpired() bool {
-	return time.Now().After(e.expiry)
+// needsRefresh reports whether the entry's credentials are due for refresh at now.
+// Credentials not assumed yet are not.
+func (e *cacheEntry) needsRefresh(now time.Time) bool {
+	return !e.refreshAt.IsZero() && now.After(e.refreshAt)
 }
 
 // PartnerContext adds partner information to context for tracing
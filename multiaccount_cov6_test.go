@@ -43,8 +43,8 @@ func TestMultiAccountDB_refreshExpiredCredentials_RefreshesAndSkipsInvalidEntrie
 
 	partnerID := "partner"
 	mdb.cache.Store(partnerID, &cacheEntry{
-		db:     &LambdaDB{},
-		expiry: time.Now().Add(-time.Hour),
+		db:        &LambdaDB{},
+		refreshAt: time.Now().Add(-time.Hour),
 		accountCfg: AccountConfig{
 			RoleARN:         "arn:aws:iam::123456789012:role/PartnerRole",
 			ExternalID:      "ext",
@@ -78,7 +78,7 @@ func TestMultiAccountDB_refreshExpiredCredentials_RefreshesAndSkipsInvalidEntrie
 		if !ok || entry == nil {
 			return false
 		}
-		return entry.expiry.After(time.Now())
+		return entry.credentials != nil && !entry.needsRefresh(time.Now())
 	}, 500*time.Millisecond, 10*time.Millisecond)
 }
 
@@ -90,8 +90,8 @@ func TestMultiAccountDB_refreshExpiredCredentials_LogsOnRefreshError_COV6(t *tes
 
 	partnerID := "123456789012"
 	mdb.cache.Store(partnerID, &cacheEntry{
-		db:     &LambdaDB{},
-		expiry: time.Now().Add(-time.Hour),
+		db:        &LambdaDB{},
+		refreshAt: time.Now().Add(-time.Hour),
 		accountCfg: AccountConfig{
 			RoleARN:         "arn:aws:iam::123456789012:role/PartnerRole",
 			ExternalID:      "ext",
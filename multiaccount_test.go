@@ -0,0 +1,146 @@
+package dynamorm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/stretchr/testify/require"
+)
+
+// newStubbedMultiAccount returns a MultiAccountDB whose partners assume roles with stub and
+// send requests to httpClient, and the HTTP clients partner configs were loaded with.
+func newStubbedMultiAccount(t *testing.T, httpClient *capturingHTTPClient, stub *stubAssumeRole, opts ...MultiAccountOption) (*MultiAccountDB, *[]config.HTTPClient) {
+	t.Helper()
+	globalLambdaDB = nil
+	lambdaOnce = sync.Once{}
+	t.Cleanup(func() {
+		globalLambdaDB = nil
+		lambdaOnce = sync.Once{}
+	})
+
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	var loaded []config.HTTPClient
+	stubSessionConfigLoad(t, func(_ context.Context, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+		var options config.LoadOptions
+		for _, fn := range optFns {
+			require.NoError(t, fn(&options))
+		}
+		cfg := minimalAWSConfig(httpClient)
+		if options.Credentials != nil {
+			cfg.Credentials = options.Credentials
+			loaded = append(loaded, options.HTTPClient)
+		}
+		return cfg, nil
+	})
+
+	accounts := map[string]AccountConfig{
+		"partner1": {RoleARN: "arn:aws:iam::111111111111:role/DynamORM", ExternalID: "ext-1", Region: "us-east-1"},
+		"partner2": {RoleARN: "arn:aws:iam::222222222222:role/DynamORM", ExternalID: "ext-2", Region: "us-east-1"},
+	}
+	mdb, err := NewMultiAccount(accounts, opts...)
+	require.NoError(t, err)
+	mdb.stsClient = stub
+	t.Cleanup(func() {
+		require.NoError(t, mdb.Close())
+	})
+	return mdb, &loaded
+}
+
+func TestMultiAccountSharesConnectionsAndCredentials(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	stub := &stubAssumeRole{}
+	var results []AssumeRoleResult
+	mdb, loaded := newStubbedMultiAccount(t, httpClient, stub, WithAssumeRoleObserver(func(r AssumeRoleResult) {
+		results = append(results, r)
+	}))
+
+	p1, err := mdb.Partner("partner1")
+	require.NoError(t, err)
+	p2, err := mdb.Partner("partner2")
+	require.NoError(t, err)
+	require.Empty(t, stub.calls)
+	value, _ := mdb.cache.Load("partner1")
+	require.True(t, value.(*cacheEntry).refreshAt.IsZero(), "nothing to refresh before the role is assumed")
+
+	for _, id := range []string{"n1", "n2"} {
+		require.NoError(t, p1.Model(&credentialsNote{ID: id}).Create())
+	}
+	require.NoError(t, p2.Model(&credentialsNote{ID: "n3"}).Create())
+	require.Equal(t, 3, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem"))
+
+	require.Len(t, stub.calls, 2)
+	require.Equal(t, "arn:aws:iam::111111111111:role/DynamORM", aws.ToString(stub.calls[0].RoleArn))
+	require.Equal(t, "ext-1", aws.ToString(stub.calls[0].ExternalId))
+	require.Equal(t, "dynamorm-partner1", aws.ToString(stub.calls[0].RoleSessionName))
+
+	require.Len(t, results, 2)
+	require.Equal(t, "partner1", results[0].PartnerID)
+	require.Equal(t, "arn:aws:iam::111111111111:role/DynamORM", results[0].RoleARN)
+	require.NoError(t, results[0].Err)
+	require.GreaterOrEqual(t, results[0].Duration, time.Duration(0))
+	require.WithinDuration(t, time.Now().Add(time.Hour), results[0].Expires, time.Minute)
+	value, _ = mdb.cache.Load("partner1")
+	require.Equal(t, results[0].Expires.Add(-credentialRefreshWindow), value.(*cacheEntry).refreshAt)
+
+	require.Len(t, *loaded, 2)
+	require.Same(t, httpClient, (*loaded)[0])
+	require.Same(t, httpClient, (*loaded)[1])
+}
+
+func TestMultiAccountRefreshesExpiredCredentials(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	stub := &stubAssumeRole{}
+	var results []AssumeRoleResult
+	mdb, _ := newStubbedMultiAccount(t, httpClient, stub, WithAssumeRoleObserver(func(r AssumeRoleResult) {
+		results = append(results, r)
+	}))
+
+	p1, err := mdb.Partner("partner1")
+	require.NoError(t, err)
+	require.NoError(t, p1.Model(&credentialsNote{ID: "n1"}).Create())
+	require.Len(t, stub.calls, 1)
+
+	expire := func() {
+		value, ok := mdb.cache.Load("partner1")
+		require.True(t, ok)
+		expired := *value.(*cacheEntry)
+		expired.refreshAt = time.Now().Add(-time.Second)
+		mdb.cache.Store("partner1", &expired)
+		expired.credentials.Invalidate()
+	}
+	expire()
+
+	stub.err = errors.New("sts unavailable")
+	_, err = mdb.Partner("partner1")
+	require.ErrorContains(t, err, "credentials of partner partner1 expired")
+	require.ErrorContains(t, err, "sts unavailable")
+	require.Len(t, results, 2)
+	require.Error(t, results[1].Err)
+
+	stub.err = nil
+	refreshed, err := mdb.Partner("partner1")
+	require.NoError(t, err)
+	require.Same(t, p1, refreshed)
+	require.Len(t, stub.calls, 3)
+
+	value, _ := mdb.cache.Load("partner1")
+	require.WithinDuration(t, results[2].Expires.Add(-credentialRefreshWindow), value.(*cacheEntry).refreshAt, time.Second)
+
+	again, err := mdb.Partner("partner1")
+	require.NoError(t, err)
+	require.Same(t, p1, again)
+	require.Len(t, stub.calls, 3)
+
+	mdb.AddPartner("partner1", AccountConfig{RoleARN: "arn:aws:iam::333333333333:role/DynamORM", Region: "us-east-1"})
+	replaced, err := mdb.Partner("partner1")
+	require.NoError(t, err)
+	require.NotSame(t, p1, replaced)
+}
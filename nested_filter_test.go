@@ -0,0 +1,109 @@
+package dynamorm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter_NestedDocumentPaths(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Scan": `{"Items":[],"Count":0,"ScannedCount":0}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var orders []projectionOrder
+	err := db.Model(&projectionOrder{}).
+		Filter("Labels.tier", "=", "gold").
+		Filter("Labels.gift-wrap", "=", "yes").
+		Filter("Address.Zip", "=", "78701").
+		All(&orders)
+	require.NoError(t, err)
+
+	scan := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan")
+	require.NotNil(t, scan)
+	require.Equal(t, "#n1.#n2 = :v1 AND #n1.#n3 = :v2 AND #n4.#n5 = :v3", scan.Payload["FilterExpression"])
+	require.Equal(t, map[string]any{
+		"#n1": "labels",
+		"#n2": "tier",
+		"#n3": "gift-wrap",
+		"#n4": "shipTo",
+		"#n5": "Zip",
+	}, scan.Payload["ExpressionAttributeNames"])
+}
+
+func TestNestedDocumentPaths_MatchCreatedItem(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{"DynamoDB_20120810.PutItem": `{}`})
+	db := newStubbedDB(t, httpClient)
+
+	created := projectionOrder{
+		ID:      "o1",
+		Address: projectionAddress{City: "Austin", Zip: "78701"},
+		Lines:   []projectionLine{{SKU: "sku-1", Quantity: 2}},
+	}
+	require.NoError(t, db.Model(&created).Create())
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	stored, err := json.Marshal(put.Payload["Item"])
+	require.NoError(t, err)
+	shipTo := put.Payload["Item"].(map[string]any)["shipTo"].(map[string]any)["M"].(map[string]any)
+
+	httpClient.SetResponseSequence("DynamoDB_20120810.Scan", []stubbedResponse{
+		{body: `{"Items":[` + string(stored) + `],"Count":1,"ScannedCount":1}`},
+	})
+	var orders []projectionOrder
+	require.NoError(t, db.Model(&projectionOrder{}).
+		Filter("Address.Zip", "=", "78701").
+		Select("ID", "Address.City", "Address.Zip", "Lines[0].SKU").
+		All(&orders))
+
+	scan := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan")
+	require.NotNil(t, scan)
+	var names []any
+	for _, name := range scan.Payload["ExpressionAttributeNames"].(map[string]any) {
+		names = append(names, name)
+	}
+	for storedName := range shipTo {
+		require.Contains(t, names, storedName, "paths must use the nested names Create stored")
+	}
+
+	require.Len(t, orders, 1)
+	require.Equal(t, created.Address, orders[0].Address)
+	require.Equal(t, "sku-1", orders[0].Lines[0].SKU)
+}
+
+func TestFilter_NestedDocumentPathRejectsUnsafeMapKeys(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+
+	for _, field := range []string{"Labels.gift wrap", "Labels.tier;", "Labels.a--b", "gift-wrap.Labels"} {
+		var orders []projectionOrder
+		err := db.Model(&projectionOrder{}).Filter(field, "=", "x").All(&orders)
+		require.Error(t, err, field)
+	}
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan"))
+}
+
+func TestUpdateBuilder_SetNestedMapKey(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.UpdateItem": `{}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	err := db.Model(&projectionOrder{}).
+		Where("ID", "=", "o1").
+		UpdateBuilder().
+		Set("Labels.gift-wrap", "yes").
+		Execute()
+	require.NoError(t, err)
+
+	update := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem")
+	require.NotNil(t, update)
+	names, ok := update.Payload["ExpressionAttributeNames"].(map[string]any)
+	require.True(t, ok)
+	require.Contains(t, names, "#n1")
+	require.Equal(t, "labels", names["#n1"])
+	require.Equal(t, "gift-wrap", names["#n2"])
+	require.Contains(t, update.Payload["UpdateExpression"], "#n1.#n2 = :")
+}
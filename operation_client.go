@@ -0,0 +1,232 @@
+package dynamorm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/middleware"
+
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+// writeClient is the part of session.WriteClient the executor writes through.
+type writeClient interface {
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+}
+
+// sessionReadClient returns the session's read client, recording into the current
+// operation when there is one.
+func (qe *queryExecutor) sessionReadClient() (session.ReadClient, error) {
+	client, err := qe.session().ReadClient()
+	if err != nil || qe.op == nil {
+		return client, err
+	}
+	return &recordingReadClient{client: client, op: qe.op}, nil
+}
+
+// sessionWriteClient returns the session's write client (DAX when enabled), invalidating
+// the item cache when there is one and recording into the current operation.
+func (qe *queryExecutor) sessionWriteClient() (writeClient, error) {
+	sessionClient, err := qe.session().WriteClient()
+	if err != nil {
+		return nil, err
+	}
+	var client writeClient = sessionClient
+	if qe.db.itemCacheConfig() != nil {
+		client = &invalidatingWriteClient{client: client, db: qe.db}
+	}
+	if qe.op == nil {
+		return client, nil
+	}
+	return &recordingWriteClient{client: client, op: qe.op}, nil
+}
+
+// recordOperation adds the outcome of one DynamoDB call to the operation.
+func recordOperation(op *Operation, items int32, capacity ...*types.ConsumedCapacity) {
+	op.ItemCount += int(items)
+	for _, c := range capacity {
+		if c != nil {
+			op.ConsumedCapacity = append(op.ConsumedCapacity, *c)
+		}
+	}
+}
+
+// recordAttempts adds the attempts the SDK's retryer reports for a successful call to the
+// operation. Clients without a retryer, such as DAX, count as one attempt.
+func recordAttempts(op *Operation, metadata middleware.Metadata) {
+	results, ok := retry.GetAttemptResults(metadata)
+	if !ok || len(results.Results) == 0 {
+		op.Attempts++
+		return
+	}
+	op.Attempts += len(results.Results)
+	for _, attempt := range results.Results {
+		if isThrottle(attempt.Err) {
+			op.Throttles++
+		}
+	}
+}
+
+// recordFailure adds the attempts of a failed call to the operation.
+func recordFailure(op *Operation, err error) {
+	if err == nil {
+		return
+	}
+	attempts := 1
+	var exhausted *retry.MaxAttemptsError
+	if errors.As(err, &exhausted) && exhausted.Attempt > 0 {
+		attempts = exhausted.Attempt
+	}
+	op.Attempts += attempts
+	if isThrottle(err) {
+		op.Throttles++
+	}
+}
+
+func isThrottle(err error) bool {
+	return err != nil && retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) == aws.TrueTernary
+}
+
+type recordingReadClient struct {
+	client session.ReadClient
+	op     *Operation
+}
+
+func (c *recordingReadClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	if c.op.ReturnConsumedCapacity != "" {
+		params.ReturnConsumedCapacity = c.op.ReturnConsumedCapacity
+	}
+	out, err := c.client.GetItem(ctx, params, optFns...)
+	recordFailure(c.op, err)
+	if err == nil && out != nil {
+		recordAttempts(c.op, out.ResultMetadata)
+		var found int32
+		if out.Item != nil {
+			found = 1
+		}
+		recordOperation(c.op, found, out.ConsumedCapacity)
+	}
+	return out, err
+}
+
+func (c *recordingReadClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	if c.op.ReturnConsumedCapacity != "" {
+		params.ReturnConsumedCapacity = c.op.ReturnConsumedCapacity
+	}
+	out, err := c.client.Query(ctx, params, optFns...)
+	recordFailure(c.op, err)
+	if err == nil && out != nil {
+		recordAttempts(c.op, out.ResultMetadata)
+		c.op.ScannedCount += int(out.ScannedCount)
+		recordOperation(c.op, out.Count, out.ConsumedCapacity)
+	}
+	return out, err
+}
+
+func (c *recordingReadClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	if c.op.ReturnConsumedCapacity != "" {
+		params.ReturnConsumedCapacity = c.op.ReturnConsumedCapacity
+	}
+	out, err := c.client.Scan(ctx, params, optFns...)
+	recordFailure(c.op, err)
+	if err == nil && out != nil {
+		recordAttempts(c.op, out.ResultMetadata)
+		c.op.ScannedCount += int(out.ScannedCount)
+		recordOperation(c.op, out.Count, out.ConsumedCapacity)
+	}
+	return out, err
+}
+
+func (c *recordingReadClient) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	if c.op.ReturnConsumedCapacity != "" {
+		params.ReturnConsumedCapacity = c.op.ReturnConsumedCapacity
+	}
+	out, err := c.client.BatchGetItem(ctx, params, optFns...)
+	recordFailure(c.op, err)
+	if err == nil && out != nil {
+		recordAttempts(c.op, out.ResultMetadata)
+		var found int32
+		for _, items := range out.Responses {
+			found += int32(len(items))
+		}
+		for i := range out.ConsumedCapacity {
+			recordOperation(c.op, 0, &out.ConsumedCapacity[i])
+		}
+		recordOperation(c.op, found)
+	}
+	return out, err
+}
+
+type recordingWriteClient struct {
+	client writeClient
+	op     *Operation
+}
+
+func (c *recordingWriteClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if c.op.ReturnConsumedCapacity != "" {
+		params.ReturnConsumedCapacity = c.op.ReturnConsumedCapacity
+	}
+	out, err := c.client.PutItem(ctx, params, optFns...)
+	recordFailure(c.op, err)
+	if err == nil && out != nil {
+		recordAttempts(c.op, out.ResultMetadata)
+		recordOperation(c.op, 1, out.ConsumedCapacity)
+	}
+	return out, err
+}
+
+func (c *recordingWriteClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	if c.op.ReturnConsumedCapacity != "" {
+		params.ReturnConsumedCapacity = c.op.ReturnConsumedCapacity
+	}
+	out, err := c.client.UpdateItem(ctx, params, optFns...)
+	recordFailure(c.op, err)
+	if err == nil && out != nil {
+		recordAttempts(c.op, out.ResultMetadata)
+		recordOperation(c.op, 1, out.ConsumedCapacity)
+	}
+	return out, err
+}
+
+func (c *recordingWriteClient) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	if c.op.ReturnConsumedCapacity != "" {
+		params.ReturnConsumedCapacity = c.op.ReturnConsumedCapacity
+	}
+	out, err := c.client.DeleteItem(ctx, params, optFns...)
+	recordFailure(c.op, err)
+	if err == nil && out != nil {
+		recordAttempts(c.op, out.ResultMetadata)
+		recordOperation(c.op, 1, out.ConsumedCapacity)
+	}
+	return out, err
+}
+
+func (c *recordingWriteClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	if c.op.ReturnConsumedCapacity != "" {
+		params.ReturnConsumedCapacity = c.op.ReturnConsumedCapacity
+	}
+	out, err := c.client.BatchWriteItem(ctx, params, optFns...)
+	recordFailure(c.op, err)
+	if err == nil && out != nil {
+		recordAttempts(c.op, out.ResultMetadata)
+		written := 0
+		for _, writes := range params.RequestItems {
+			written += len(writes)
+		}
+		for _, writes := range out.UnprocessedItems {
+			written -= len(writes)
+		}
+		for i := range out.ConsumedCapacity {
+			recordOperation(c.op, 0, &out.ConsumedCapacity[i])
+		}
+		recordOperation(c.op, int32(written))
+	}
+	return out, err
+}
@@ -0,0 +1,85 @@
+package dynamorm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+func TestUpdateWithOptimisticRetry_ReloadsAndReappliesMutation(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"a1"},"balance":{"N":"15"},"version":{"N":"2"}}}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.UpdateItem", []stubbedResponse{
+		conditionalCheckFailedResponse,
+		{body: `{}`},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	account := &testAccount{ID: "a1", Balance: 10, Version: 1}
+	calls := 0
+	err := db.Model(account).UpdateWithOptimisticRetry(3, func(model any) error {
+		calls++
+		model.(*testAccount).Balance += 5
+		return nil
+	}, "Balance")
+	require.NoError(t, err)
+
+	require.Equal(t, 2, calls)
+	require.Equal(t, int64(20), account.Balance)
+	require.Equal(t, int64(3), account.Version)
+
+	reqs := httpClient.Requests()
+	require.Equal(t, 1, countRequestsByTarget(reqs, "DynamoDB_20120810.GetItem"), "first attempt uses the in-memory item")
+	require.Equal(t, true, findRequestByTarget(reqs, "DynamoDB_20120810.GetItem").Payload["ConsistentRead"])
+	require.Equal(t, 2, countRequestsByTarget(reqs, "DynamoDB_20120810.UpdateItem"))
+}
+
+func TestUpdateWithOptimisticRetry_RefreshKeepsStoredVersion(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.UpdateItem": `{"Attributes":{"id":{"S":"a1"},"balance":{"N":"15"},"version":{"N":"2"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	account := &testAccount{ID: "a1", Balance: 10, Version: 1}
+	err := db.Model(account).Refresh().UpdateWithOptimisticRetry(3, func(model any) error {
+		model.(*testAccount).Balance += 5
+		return nil
+	}, "Balance")
+	require.NoError(t, err)
+
+	require.Equal(t, int64(15), account.Balance)
+	require.Equal(t, int64(2), account.Version, "the refreshed version is not bumped again")
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem"))
+}
+
+func TestUpdateWithOptimisticRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"a1"},"balance":{"N":"15"},"version":{"N":"2"}}}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.UpdateItem", []stubbedResponse{conditionalCheckFailedResponse})
+	db := newStubbedDB(t, httpClient)
+
+	account := &testAccount{ID: "a1", Balance: 10, Version: 1}
+	err := db.Model(account).UpdateWithOptimisticRetry(2, func(model any) error {
+		model.(*testAccount).Balance++
+		return nil
+	})
+	require.True(t, errors.Is(err, customerrors.ErrConditionFailed))
+	require.ErrorContains(t, err, "after 2 attempts")
+	require.Equal(t, 2, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem"))
+}
+
+func TestUpdateWithOptimisticRetry_RequiresVersionField(t *testing.T) {
+	db := newBareDB()
+
+	err := db.Model(&unversionedAccount{ID: "a1"}).UpdateWithOptimisticRetry(3, func(any) error { return nil })
+	require.ErrorIs(t, err, customerrors.ErrInvalidModel)
+
+	mutateErr := errors.New("insufficient funds")
+	err = db.Model(&testAccount{ID: "a1", Version: 1}).UpdateWithOptimisticRetry(3, func(any) error { return mutateErr })
+	require.ErrorIs(t, err, mutateErr)
+}
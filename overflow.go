@@ -0,0 +1,176 @@
+package dynamorm
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/internal/overflow"
+)
+
+// offloadItem replaces oversized dynamorm:"s3overflow" attribute values with pointers to S3
+// objects. PutItem, UpdateItem, and DeleteItem remove the objects they replace or orphan
+// (see cleanupOverflow); batch writes and transactions cannot see the old item, so the
+// bucket should still have a lifecycle rule for stale objects.
+func (qe *queryExecutor) offloadItem(tableName string, item map[string]types.AttributeValue) error {
+	if len(item) == 0 || qe == nil || qe.metadata == nil || !overflow.MetadataHasOverflowFields(qe.metadata) {
+		return nil
+	}
+
+	svc, err := overflow.ServiceForSession(qe.session(), qe.metadata)
+	if err != nil {
+		return err
+	}
+
+	for _, fieldMeta := range qe.metadata.Fields {
+		if fieldMeta == nil || !fieldMeta.IsS3Overflow() {
+			continue
+		}
+		av, ok := item[fieldMeta.DBName]
+		if !ok {
+			continue
+		}
+		offloaded, err := svc.Offload(qe.ctxOrBackground(), tableName, fieldMeta.DBName, av)
+		if err != nil {
+			return err
+		}
+		item[fieldMeta.DBName] = offloaded
+	}
+	return nil
+}
+
+// offloadUpdateValues offloads expression values assigned directly to dynamorm:"s3overflow"
+// attributes (SET #a = :v).
+func (qe *queryExecutor) offloadUpdateValues(input *dynamodb.UpdateItemInput) error {
+	if qe == nil || qe.metadata == nil || len(input.ExpressionAttributeValues) == 0 || !overflow.MetadataHasOverflowFields(qe.metadata) {
+		return nil
+	}
+
+	svc, err := overflow.ServiceForSession(qe.session(), qe.metadata)
+	if err != nil {
+		return err
+	}
+
+	for _, match := range assignmentPattern.FindAllStringSubmatch(aws.ToString(input.UpdateExpression), -1) {
+		if strings.Contains(match[1], ".") {
+			continue
+		}
+		fieldMeta := qe.metadata.FieldsByDBName[input.ExpressionAttributeNames[match[1]]]
+		if fieldMeta == nil || !fieldMeta.IsS3Overflow() {
+			continue
+		}
+		av, ok := input.ExpressionAttributeValues[match[2]]
+		if !ok {
+			continue
+		}
+		offloaded, err := svc.Offload(qe.ctxOrBackground(), aws.ToString(input.TableName), fieldMeta.DBName, av)
+		if err != nil {
+			return err
+		}
+		input.ExpressionAttributeValues[match[2]] = offloaded
+	}
+	return nil
+}
+
+// rehydrateItem replaces S3 overflow pointers with the stored values.
+func (qe *queryExecutor) rehydrateItem(item map[string]types.AttributeValue) error {
+	if len(item) == 0 || qe == nil || qe.metadata == nil || !overflow.MetadataHasOverflowFields(qe.metadata) {
+		return nil
+	}
+
+	var svc *overflow.Service
+	for attrName, attrValue := range item {
+		fieldMeta, ok := qe.metadata.FieldsByDBName[attrName]
+		if !ok || fieldMeta == nil || !fieldMeta.IsS3Overflow() || !overflow.IsPointer(attrValue) {
+			continue
+		}
+		if svc == nil {
+			var err error
+			if svc, err = overflow.ServiceForSession(qe.session(), qe.metadata); err != nil {
+				return err
+			}
+		}
+		value, err := svc.Rehydrate(qe.ctxOrBackground(), attrValue)
+		if err != nil {
+			return err
+		}
+		item[attrName] = value
+	}
+	return nil
+}
+
+// loadItem prepares an item read from DynamoDB for unmarshaling.
+func (qe *queryExecutor) loadItem(item map[string]types.AttributeValue) error {
+	if err := qe.decryptItem(item); err != nil {
+		return err
+	}
+	return qe.rehydrateItem(item)
+}
+
+// tracksOverflow reports whether writes for the executor's model should ask DynamoDB for
+// the old item, so that overflow objects it referenced can be cleaned up.
+func (qe *queryExecutor) tracksOverflow() bool {
+	return qe != nil && qe.metadata != nil && overflow.MetadataHasOverflowFields(qe.metadata)
+}
+
+// assignedOverflowValues returns the values an update expression assigns directly to
+// dynamorm:"s3overflow" attributes, keyed by attribute name. Attributes set any other
+// way, such as with if_not_exists, are left out because they may keep their old value.
+func (qe *queryExecutor) assignedOverflowValues(input *dynamodb.UpdateItemInput) map[string]types.AttributeValue {
+	if !qe.tracksOverflow() {
+		return nil
+	}
+	assigned := make(map[string]types.AttributeValue)
+	for _, match := range assignmentPattern.FindAllStringSubmatch(aws.ToString(input.UpdateExpression), -1) {
+		if strings.Contains(match[1], ".") {
+			continue
+		}
+		fieldMeta := qe.metadata.FieldsByDBName[input.ExpressionAttributeNames[match[1]]]
+		if fieldMeta == nil || !fieldMeta.IsS3Overflow() {
+			continue
+		}
+		if av, ok := input.ExpressionAttributeValues[match[2]]; ok {
+			assigned[fieldMeta.DBName] = av
+		}
+	}
+	return assigned
+}
+
+// cleanupOverflow deletes the objects that old's dynamorm:"s3overflow" attributes point
+// to unless current still points to the same object. Only attributes present in old are
+// considered. The write has already succeeded, so failures go to
+// session.Config.S3OverflowCleanupError instead of the caller.
+func (qe *queryExecutor) cleanupOverflow(old, current map[string]types.AttributeValue) {
+	if len(old) == 0 || !qe.tracksOverflow() {
+		return
+	}
+
+	var svc *overflow.Service
+	for _, fieldMeta := range qe.metadata.Fields {
+		if fieldMeta == nil || !fieldMeta.IsS3Overflow() {
+			continue
+		}
+		previous, ok := old[fieldMeta.DBName]
+		if !ok || !overflow.IsPointer(previous) || overflow.SameObject(previous, current[fieldMeta.DBName]) {
+			continue
+		}
+		if svc == nil {
+			var err error
+			if svc, err = overflow.ServiceForSession(qe.session(), qe.metadata); err != nil {
+				qe.reportOverflowCleanupError("", "", err)
+				return
+			}
+		}
+		if bucket, key, err := svc.Delete(qe.ctxOrBackground(), previous); err != nil {
+			qe.reportOverflowCleanupError(bucket, key, err)
+		}
+	}
+}
+
+func (qe *queryExecutor) reportOverflowCleanupError(bucket, key string, err error) {
+	if sess := qe.session(); sess != nil && sess.Config() != nil && sess.Config().S3OverflowCleanupError != nil {
+		sess.Config().S3OverflowCleanupError(bucket, key, err)
+	}
+}
@@ -0,0 +1,210 @@
+package dynamorm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+type overflowDocument struct {
+	ID   string `dynamorm:"pk,attr:id"`
+	Body string `dynamorm:"s3overflow,attr:body"`
+}
+
+type fakeOverflowS3 struct {
+	objects   map[string][]byte
+	deleteErr error
+}
+
+func (f *fakeOverflowS3) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.objects[aws.ToString(params.Key)] = body
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeOverflowS3) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(f.objects[aws.ToString(params.Key)]))}, nil
+}
+
+func (f *fakeOverflowS3) DeleteObject(_ context.Context, params *s3.DeleteObjectInput, _ ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	if f.deleteErr != nil {
+		return nil, f.deleteErr
+	}
+	delete(f.objects, aws.ToString(params.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+// overflowPointerJSON renders the stored form of an overflow pointer to key.
+func overflowPointerJSON(key string) string {
+	return `{"M":{"dynamormS3Overflow":{"M":{"v":{"S":"1"},"bucket":{"S":"documents"},"key":{"S":"` + key + `"},"kind":{"S":"S"},"size":{"N":"64"}}}}}`
+}
+
+func TestS3Overflow_OffloadsLargeValuesAndRehydrates(t *testing.T) {
+	s3Client := &fakeOverflowS3{objects: map[string][]byte{}}
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{
+		S3OverflowBucket:    "documents",
+		S3OverflowThreshold: 16,
+		S3Client:            s3Client,
+	})
+
+	body := strings.Repeat("b", 64)
+	require.NoError(t, db.Model(&overflowDocument{ID: "d1", Body: body}).Create())
+	require.Len(t, s3Client.objects, 1)
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, req)
+	item := req.Payload["Item"].(map[string]any)
+	stored := item["body"].(map[string]any)
+	require.Contains(t, stored, "M")
+	require.NotContains(t, stored, "S")
+
+	var key string
+	for k := range s3Client.objects {
+		key = k
+	}
+	httpClient.SetResponseSequence("DynamoDB_20120810.GetItem", []stubbedResponse{
+		{body: `{"Item":{"id":{"S":"d1"},"body":{"M":{"dynamormS3Overflow":{"M":{"v":{"S":"1"},"bucket":{"S":"documents"},"key":{"S":"` + key + `"},"kind":{"S":"S"},"size":{"N":"64"}}}}}}}`},
+	})
+
+	var loaded overflowDocument
+	require.NoError(t, db.Model(&overflowDocument{}).Where("ID", "=", "d1").First(&loaded))
+	require.Equal(t, body, loaded.Body)
+}
+
+func TestS3Overflow_SmallValuesStayInline(t *testing.T) {
+	s3Client := &fakeOverflowS3{objects: map[string][]byte{}}
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{
+		S3OverflowBucket: "documents",
+		S3Client:         s3Client,
+	})
+
+	require.NoError(t, db.Model(&overflowDocument{ID: "d1", Body: "short"}).Create())
+	require.Empty(t, s3Client.objects)
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, req)
+	item := req.Payload["Item"].(map[string]any)
+	require.Equal(t, map[string]any{"S": "short"}, item["body"])
+}
+
+func TestS3Overflow_OffloadsUpdateAssignments(t *testing.T) {
+	s3Client := &fakeOverflowS3{objects: map[string][]byte{}}
+	httpClient := newCapturingHTTPClient(map[string]string{"DynamoDB_20120810.UpdateItem": `{}`})
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{
+		S3OverflowBucket:    "documents",
+		S3OverflowThreshold: 16,
+		S3Client:            s3Client,
+	})
+
+	err := db.Model(&overflowDocument{}).Where("ID", "=", "d1").UpdateBuilder().
+		Set("Body", strings.Repeat("u", 64)).
+		Execute()
+	require.NoError(t, err)
+	require.Len(t, s3Client.objects, 1)
+}
+
+func TestS3Overflow_RequiresBucket(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{})
+
+	err := db.Model(&overflowDocument{ID: "d1", Body: "short"}).Create()
+	require.ErrorIs(t, err, customerrors.ErrOverflowNotConfigured)
+}
+
+func TestS3Overflow_DeleteRemovesObject(t *testing.T) {
+	s3Client := &fakeOverflowS3{objects: map[string][]byte{"old": []byte("stale")}}
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.DeleteItem": `{"Attributes":{"id":{"S":"d1"},"body":` + overflowPointerJSON("old") + `}}`,
+	})
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{
+		S3OverflowBucket: "documents",
+		S3Client:         s3Client,
+	})
+
+	require.NoError(t, db.Model(&overflowDocument{ID: "d1"}).Delete())
+	require.Empty(t, s3Client.objects)
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.DeleteItem")
+	require.NotNil(t, req)
+	require.Equal(t, "ALL_OLD", req.Payload["ReturnValues"])
+}
+
+func TestS3Overflow_OverwriteRemovesReplacedObject(t *testing.T) {
+	s3Client := &fakeOverflowS3{objects: map[string][]byte{"old": []byte("stale")}}
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.PutItem": `{"Attributes":{"id":{"S":"d1"},"body":` + overflowPointerJSON("old") + `}}`,
+	})
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{
+		S3OverflowBucket:    "documents",
+		S3OverflowThreshold: 16,
+		S3Client:            s3Client,
+	})
+
+	require.NoError(t, db.Model(&overflowDocument{ID: "d1", Body: strings.Repeat("n", 64)}).CreateOrUpdate())
+	require.Len(t, s3Client.objects, 1)
+	require.NotContains(t, s3Client.objects, "old")
+}
+
+func TestS3Overflow_UpdateAssignmentRemovesReplacedObject(t *testing.T) {
+	s3Client := &fakeOverflowS3{objects: map[string][]byte{"old": []byte("stale")}}
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.UpdateItem": `{"Attributes":{"body":` + overflowPointerJSON("old") + `}}`,
+	})
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{
+		S3OverflowBucket: "documents",
+		S3Client:         s3Client,
+	})
+
+	err := db.Model(&overflowDocument{}).Where("ID", "=", "d1").UpdateBuilder().
+		Set("Body", "inline now").
+		Execute()
+	require.NoError(t, err)
+	require.Empty(t, s3Client.objects)
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem")
+	require.NotNil(t, req)
+	require.Equal(t, "UPDATED_OLD", req.Payload["ReturnValues"])
+}
+
+func TestS3Overflow_CleanupFailureGoesToHook(t *testing.T) {
+	s3Client := &fakeOverflowS3{objects: map[string][]byte{"old": []byte("stale")}, deleteErr: errors.New("access denied")}
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.DeleteItem": `{"Attributes":{"id":{"S":"d1"},"body":` + overflowPointerJSON("old") + `}}`,
+	})
+	var failedKey string
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{
+		S3OverflowBucket: "documents",
+		S3Client:         s3Client,
+		S3OverflowCleanupError: func(bucket, key string, err error) {
+			require.Equal(t, "documents", bucket)
+			require.ErrorContains(t, err, "access denied")
+			failedKey = key
+		},
+	})
+
+	require.NoError(t, db.Model(&overflowDocument{ID: "d1"}).Delete())
+	require.Equal(t, "old", failedKey)
+	require.Contains(t, s3Client.objects, "old")
+}
+
+func TestSession_S3ClientBuiltOnce(t *testing.T) {
+	db := newStubbedDB(t, newCapturingHTTPClient(nil))
+	first := db.session.S3Client()
+	require.NotNil(t, first)
+	require.Same(t, first, db.session.S3Client())
+}
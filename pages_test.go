@@ -0,0 +1,106 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+const (
+	pagesFirstPage  = `{"Items":[{"id":{"S":"a1"},"balance":{"N":"1"}},{"id":{"S":"a1"},"balance":{"N":"2"}}],"Count":2,"ScannedCount":2,"LastEvaluatedKey":{"id":{"S":"a1"}}}`
+	pagesSecondPage = `{"Items":[{"id":{"S":"a1"},"balance":{"N":"3"}}],"Count":1,"ScannedCount":1}`
+)
+
+func TestQueryPages_FollowsLastEvaluatedKey(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.Query", []stubbedResponse{
+		{body: pagesFirstPage},
+		{body: pagesSecondPage},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var (
+		page     []testAccount
+		balances []int64
+		cursors  []string
+	)
+	err := db.Model(&testAccount{}).Where("ID", "=", "a1").Pages(&page, func(p *core.PaginatedResult) bool {
+		for _, account := range page {
+			balances = append(balances, account.Balance)
+		}
+		cursors = append(cursors, p.NextCursor)
+		require.Equal(t, len(page), p.Count)
+		return true
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []int64{1, 2, 3}, balances)
+	require.Len(t, cursors, 2)
+	require.NotEmpty(t, cursors[0])
+	require.Empty(t, cursors[1])
+
+	reqs := httpClient.Requests()
+	require.Equal(t, 2, countRequestsByTarget(reqs, "DynamoDB_20120810.Query"))
+	require.Nil(t, reqs[0].Payload["ExclusiveStartKey"])
+	require.Equal(t, map[string]any{"id": map[string]any{"S": "a1"}}, reqs[1].Payload["ExclusiveStartKey"])
+}
+
+func TestQueryPages_LimitIsTotalBudget(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.Query", []stubbedResponse{
+		{body: pagesFirstPage},
+		{body: `{"Items":[{"id":{"S":"a1"},"balance":{"N":"3"}}],"Count":1,"LastEvaluatedKey":{"id":{"S":"a1"}}}`},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var (
+		page []testAccount
+		last *core.PaginatedResult
+	)
+	total := 0
+	err := db.Model(&testAccount{}).Where("ID", "=", "a1").Limit(3).Pages(&page, func(p *core.PaginatedResult) bool {
+		total += len(page)
+		last = p
+		return true
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, total)
+	require.True(t, last.HasMore, "the final page's cursor lets the caller resume")
+
+	reqs := httpClient.Requests()
+	require.Equal(t, 2, countRequestsByTarget(reqs, "DynamoDB_20120810.Query"))
+	require.Equal(t, float64(3), reqs[0].Payload["Limit"])
+	require.Equal(t, float64(1), reqs[1].Payload["Limit"])
+}
+
+func TestQueryPages_StopsWhenCallbackReturnsFalse(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.Query", []stubbedResponse{
+		{body: pagesFirstPage},
+		{body: pagesSecondPage},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var page []testAccount
+	var cursor string
+	err := db.Model(&testAccount{}).Where("ID", "=", "a1").Pages(&page, func(p *core.PaginatedResult) bool {
+		cursor = p.NextCursor
+		return false
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Query"))
+
+	var resumed []testAccount
+	require.NoError(t, db.Model(&testAccount{}).Where("ID", "=", "a1").Cursor(cursor).All(&resumed))
+	require.Equal(t, map[string]any{"id": map[string]any{"S": "a1"}}, httpClient.Requests()[1].Payload["ExclusiveStartKey"])
+}
+
+func TestQueryPages_RequiresSliceDestination(t *testing.T) {
+	db := newBareDB()
+
+	var single testAccount
+	err := db.Model(&testAccount{}).Where("ID", "=", "a1").Pages(&single, func(*core.PaginatedResult) bool { return true })
+	require.ErrorContains(t, err, "pointer to a slice")
+}
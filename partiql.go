@@ -0,0 +1,335 @@
+package dynamorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/internal/numutil"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// maxBatchPartiQLStatements is the DynamoDB limit for BatchExecuteStatement.
+const maxBatchPartiQLStatements = 25
+
+// PartiQLQuery executes a single PartiQL statement through ExecuteStatement.
+type PartiQLQuery struct {
+	db             *DB
+	err            error
+	limit          *int32
+	nextToken      *string
+	statement      string
+	params         []any
+	consistentRead bool
+}
+
+// PartiQL prepares a PartiQL statement. Parameters bind to `?` placeholders in order and
+// are converted with the DB's type converter, so custom converters apply. Results are
+// decrypted for models with dynamorm:"encrypted" fields, but parameters are sent as-is:
+// write encrypted models through the model API instead.
+//
+//	var orders []Order
+//	err := db.PartiQL(`SELECT * FROM "orders" WHERE "tenantId" = ? AND "status" = ?`, tenantID, "open").All(&orders)
+func (db *DB) PartiQL(statement string, params ...any) *PartiQLQuery {
+	q := &PartiQLQuery{db: db, statement: strings.TrimSpace(statement), params: params}
+	if q.statement == "" {
+		q.err = fmt.Errorf("partiql statement cannot be empty")
+	}
+	return q
+}
+
+// ConsistentRead requests a strongly consistent read for SELECT statements.
+func (q *PartiQLQuery) ConsistentRead() *PartiQLQuery {
+	q.consistentRead = true
+	return q
+}
+
+// Limit caps the number of items DynamoDB evaluates per request.
+func (q *PartiQLQuery) Limit(limit int) *PartiQLQuery {
+	if limit <= 0 {
+		q.err = fmt.Errorf("partiql limit must be positive")
+		return q
+	}
+	q.limit = aws.Int32(numutil.ClampIntToInt32(limit))
+	return q
+}
+
+// Cursor resumes a previous Page call from its next token.
+func (q *PartiQLQuery) Cursor(nextToken string) *PartiQLQuery {
+	if nextToken != "" {
+		q.nextToken = aws.String(nextToken)
+	}
+	return q
+}
+
+// Exec runs a statement that returns no items (INSERT, UPDATE, DELETE). Failed
+// conditions surface as ErrConditionFailed.
+func (q *PartiQLQuery) Exec() error {
+	_, err := q.execute(nil)
+	return err
+}
+
+// All runs a SELECT and follows next tokens until the result set is exhausted,
+// unmarshaling every item into dest (a pointer to a slice of models or raw item maps).
+func (q *PartiQLQuery) All(dest any) error {
+	var items []map[string]types.AttributeValue
+	token := q.nextToken
+	for {
+		out, err := q.execute(token)
+		if err != nil {
+			return err
+		}
+		items = append(items, out.Items...)
+		if out.NextToken == nil || *out.NextToken == "" {
+			break
+		}
+		token = out.NextToken
+	}
+	return q.db.writePartiQLItems(items, dest)
+}
+
+// Page runs a single ExecuteStatement request and returns the token for the next page,
+// or an empty string when there are no more results.
+func (q *PartiQLQuery) Page(dest any) (string, error) {
+	out, err := q.execute(q.nextToken)
+	if err != nil {
+		return "", err
+	}
+	if err := q.db.writePartiQLItems(out.Items, dest); err != nil {
+		return "", err
+	}
+	return aws.ToString(out.NextToken), nil
+}
+
+// checkPartiQL rejects a statement the DB's maintenance modes, tenant, unique fields, or
+// audit mode do not allow.
+func (db *DB) checkPartiQL(statement string) error {
+	if err := db.checkPartiQLMaintenance(statement); err != nil {
+		return err
+	}
+	if err := db.checkPartiQLTenant(statement); err != nil {
+		return err
+	}
+	if err := db.checkPartiQLUnique(statement); err != nil {
+		return err
+	}
+	return db.checkPartiQLAudit(statement)
+}
+
+func (q *PartiQLQuery) execute(nextToken *string) (*dynamodb.ExecuteStatementOutput, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	if err := q.db.checkPartiQL(q.statement); err != nil {
+		return nil, err
+	}
+	params, err := q.db.partiQLParameters(q.params)
+	if err != nil {
+		return nil, err
+	}
+
+	qe := &queryExecutor{db: q.db, ctx: q.db.ctx}
+	if err := qe.checkLambdaTimeout(); err != nil {
+		return nil, err
+	}
+	client, err := qe.session().Client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client for partiql: %w", err)
+	}
+
+	input := &dynamodb.ExecuteStatementInput{
+		Statement:  aws.String(q.statement),
+		Parameters: params,
+		Limit:      q.limit,
+		NextToken:  nextToken,
+	}
+	if q.consistentRead {
+		input.ConsistentRead = aws.Bool(true)
+	}
+
+	out, err := client.ExecuteStatement(qe.ctxOrBackground(), input)
+	if err != nil {
+		if isConditionalCheckFailedException(err) {
+			return nil, customerrors.ErrConditionFailed
+		}
+		return nil, customerrors.Remediate(fmt.Errorf("failed to execute partiql statement: %w", err))
+	}
+	return out, nil
+}
+
+// PartiQLStatement is one entry of a BatchPartiQL call.
+type PartiQLStatement struct {
+	Statement      string
+	Params         []any
+	ConsistentRead bool
+}
+
+// PartiQLBatchResult is the outcome of one statement in a BatchPartiQL call. Err is
+// nil on success; Item is set for SELECT statements that found an item.
+type PartiQLBatchResult struct {
+	Err   error
+	Item  map[string]types.AttributeValue
+	db    *DB
+	Table string
+}
+
+// Unmarshal decodes the result item into dest (a pointer to a model or raw item map).
+// It returns the statement's error, or ErrItemNotFound when no item was produced.
+func (r PartiQLBatchResult) Unmarshal(dest any) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	if len(r.Item) == 0 {
+		return customerrors.ErrItemNotFound
+	}
+	if r.db == nil {
+		return UnmarshalItem(r.Item, dest)
+	}
+
+	var meta *model.Metadata
+	if typ := reflect.TypeOf(dest); typ != nil && typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct {
+		m, err := r.db.metadataFor(dest)
+		if err != nil {
+			return err
+		}
+		meta = m
+	}
+
+	qe := &queryExecutor{db: r.db, metadata: meta, ctx: r.db.ctx}
+	if err := qe.loadItem(r.Item); err != nil {
+		return err
+	}
+	return qe.unmarshalItem(r.Item, dest)
+}
+
+// BatchPartiQL executes up to 25 statements with BatchExecuteStatement. Statements
+// either all read or all write. Per-statement failures are reported in the matching
+// result rather than as the returned error.
+func (db *DB) BatchPartiQL(statements ...PartiQLStatement) ([]PartiQLBatchResult, error) {
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("batch partiql requires at least one statement")
+	}
+	if len(statements) > maxBatchPartiQLStatements {
+		return nil, fmt.Errorf("batch partiql supports at most %d statements, got %d", maxBatchPartiQLStatements, len(statements))
+	}
+
+	requests := make([]types.BatchStatementRequest, 0, len(statements))
+	for i, stmt := range statements {
+		text := strings.TrimSpace(stmt.Statement)
+		if text == "" {
+			return nil, fmt.Errorf("partiql statement %d cannot be empty", i)
+		}
+		if err := db.checkPartiQL(text); err != nil {
+			return nil, fmt.Errorf("partiql statement %d: %w", i, err)
+		}
+		params, err := db.partiQLParameters(stmt.Params)
+		if err != nil {
+			return nil, fmt.Errorf("partiql statement %d: %w", i, err)
+		}
+		req := types.BatchStatementRequest{Statement: aws.String(text), Parameters: params}
+		if stmt.ConsistentRead {
+			req.ConsistentRead = aws.Bool(true)
+		}
+		requests = append(requests, req)
+	}
+
+	qe := &queryExecutor{db: db, ctx: db.ctx}
+	if err := qe.checkLambdaTimeout(); err != nil {
+		return nil, err
+	}
+	client, err := qe.session().Client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client for batch partiql: %w", err)
+	}
+
+	out, err := client.BatchExecuteStatement(qe.ctxOrBackground(), &dynamodb.BatchExecuteStatementInput{
+		Statements: requests,
+	})
+	if err != nil {
+		return nil, customerrors.Remediate(fmt.Errorf("failed to execute partiql batch: %w", err))
+	}
+
+	results := make([]PartiQLBatchResult, len(statements))
+	for i, resp := range out.Responses {
+		if i >= len(results) {
+			break
+		}
+		results[i] = PartiQLBatchResult{Item: resp.Item, Table: aws.ToString(resp.TableName), db: db}
+		if resp.Error != nil {
+			results[i].Err = partiQLStatementError(resp.Error)
+		}
+	}
+	return results, nil
+}
+
+func partiQLStatementError(stmtErr *types.BatchStatementError) error {
+	message := aws.ToString(stmtErr.Message)
+	if stmtErr.Code == types.BatchStatementErrorCodeEnumConditionalCheckFailed {
+		if message == "" {
+			return customerrors.ErrConditionFailed
+		}
+		return fmt.Errorf("%w: %s", customerrors.ErrConditionFailed, message)
+	}
+	if message == "" {
+		return errors.New(string(stmtErr.Code))
+	}
+	return fmt.Errorf("%s: %s", stmtErr.Code, message)
+}
+
+func (db *DB) partiQLParameters(params []any) ([]types.AttributeValue, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	out := make([]types.AttributeValue, 0, len(params))
+	for i, param := range params {
+		if av, ok := param.(types.AttributeValue); ok {
+			out = append(out, av)
+			continue
+		}
+		av, err := db.converter.ToAttributeValue(param)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert partiql parameter %d: %w", i, err)
+		}
+		out = append(out, av)
+	}
+	return out, nil
+}
+
+// writePartiQLItems decrypts and unmarshals items using the metadata of dest's element
+// type when it is a registered model.
+func (db *DB) writePartiQLItems(items []map[string]types.AttributeValue, dest any) error {
+	if dest == nil {
+		return nil
+	}
+
+	var meta *model.Metadata
+	if elem, ok := sliceElemStruct(dest); ok {
+		m, err := db.metadataFor(reflect.New(elem).Interface())
+		if err != nil {
+			return err
+		}
+		meta = m
+	}
+
+	qe := &queryExecutor{db: db, metadata: meta, ctx: db.ctx}
+	return qe.writeItemsToDest(items, dest)
+}
+
+func sliceElemStruct(dest any) (reflect.Type, bool) {
+	typ := reflect.TypeOf(dest)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Slice {
+		return nil, false
+	}
+	elem := typ.Elem().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem, elem.Kind() == reflect.Struct
+}
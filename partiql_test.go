@@ -0,0 +1,104 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+func TestPartiQL_AllFollowsNextTokenAndBindsParameters(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.ExecuteStatement", []stubbedResponse{
+		{body: `{"Items":[{"tenantId":{"S":"t1"},"createdAt":{"S":"a"},"status":{"S":"open"}}],"NextToken":"tok-1"}`},
+		{body: `{"Items":[{"tenantId":{"S":"t1"},"createdAt":{"S":"b"},"status":{"S":"open"}}]}`},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var orders []testOrderModel
+	err := db.PartiQL(`SELECT * FROM "orders_test" WHERE "tenantId" = ? AND "status" = ?`, "t1", "open").
+		ConsistentRead().
+		Limit(10).
+		All(&orders)
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	require.Equal(t, "b", orders[1].CreatedAt)
+
+	reqs := httpClient.Requests()
+	require.Equal(t, 2, countRequestsByTarget(reqs, "DynamoDB_20120810.ExecuteStatement"))
+	last := findRequestByTarget(reqs, "DynamoDB_20120810.ExecuteStatement")
+	require.Equal(t, "tok-1", last.Payload["NextToken"])
+	require.Equal(t, true, last.Payload["ConsistentRead"])
+	require.EqualValues(t, 10, last.Payload["Limit"])
+	require.Equal(t, []any{
+		map[string]any{"S": "t1"},
+		map[string]any{"S": "open"},
+	}, last.Payload["Parameters"])
+}
+
+func TestPartiQL_PageReturnsNextToken(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.ExecuteStatement": `{"Items":[{"tenantId":{"S":"t1"},"createdAt":{"S":"a"}}],"NextToken":"tok-2"}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var page []testOrderModel
+	next, err := db.PartiQL(`SELECT * FROM "orders_test"`).Cursor("tok-1").Page(&page)
+	require.NoError(t, err)
+	require.Equal(t, "tok-2", next)
+	require.Len(t, page, 1)
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.ExecuteStatement")
+	require.Equal(t, "tok-1", req.Payload["NextToken"])
+}
+
+func TestPartiQL_ExecMapsConditionalFailure(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.ExecuteStatement", []stubbedResponse{conditionalCheckFailedResponse})
+	db := newStubbedDB(t, httpClient)
+
+	err := db.PartiQL(`UPDATE "orders_test" SET "status" = ? WHERE "tenantId" = ? AND "createdAt" = ?`, "closed", "t1", "a").Exec()
+	require.ErrorIs(t, err, customerrors.ErrConditionFailed)
+}
+
+func TestBatchPartiQL_ReportsPerStatementResults(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.BatchExecuteStatement": `{"Responses":[
+			{"TableName":"orders_test","Item":{"tenantId":{"S":"t1"},"createdAt":{"S":"a"},"status":{"S":"open"}}},
+			{"TableName":"orders_test","Error":{"Code":"ConditionalCheckFailed","Message":"nope"}},
+			{"TableName":"orders_test"}
+		]}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	results, err := db.BatchPartiQL(
+		PartiQLStatement{Statement: `SELECT * FROM "orders_test" WHERE "tenantId" = ? AND "createdAt" = ?`, Params: []any{"t1", "a"}},
+		PartiQLStatement{Statement: `SELECT * FROM "orders_test" WHERE "tenantId" = ? AND "createdAt" = ?`, Params: []any{"t1", "b"}},
+		PartiQLStatement{Statement: `SELECT * FROM "orders_test" WHERE "tenantId" = ? AND "createdAt" = ?`, Params: []any{"t1", "c"}, ConsistentRead: true},
+	)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	var order testOrderModel
+	require.NoError(t, results[0].Unmarshal(&order))
+	require.Equal(t, "open", order.Status)
+	require.ErrorIs(t, results[1].Unmarshal(&order), customerrors.ErrConditionFailed)
+	require.ErrorIs(t, results[2].Unmarshal(&order), customerrors.ErrItemNotFound)
+}
+
+func TestPartiQL_ValidatesInputs(t *testing.T) {
+	db := newBareDB()
+
+	require.ErrorContains(t, db.PartiQL("  ").Exec(), "cannot be empty")
+	require.ErrorContains(t, db.PartiQL("SELECT 1").Limit(0).Exec(), "limit must be positive")
+
+	_, err := db.BatchPartiQL()
+	require.ErrorContains(t, err, "at least one statement")
+
+	_, err = db.BatchPartiQL(make([]PartiQLStatement, 26)...)
+	require.ErrorContains(t, err, "at most 25")
+
+	_, err = db.BatchPartiQL(PartiQLStatement{Statement: ""})
+	require.ErrorContains(t, err, "cannot be empty")
+}
@@ -0,0 +1,148 @@
+package dynamorm
+
+import (
+	"context"
+	"time"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// Stage is one step of a Pipeline. It receives the items produced by the previous stage
+// (nil for the first) and returns the items for the next.
+type Stage[T any] func(ctx context.Context, items []T) ([]T, error)
+
+// StageResult describes one stage run, for metrics.
+type StageResult struct {
+	Err      error
+	Pipeline string
+	Stage    string
+	Items    int
+	Duration time.Duration
+}
+
+// Pipeline runs a fixed sequence of named stages, such as an index query, a batch
+// hydrate from the base table, a client-side transform, and a cache write, with one
+// context, one metrics hook, and one error shape. Declare it once and Run it per request:
+//
+//	recent := dynamorm.NewPipeline[Order]("recent-orders").
+//		Stage("query", dynamorm.FromQuery(func(ctx context.Context) *dynamorm.Query[Order] {
+//			return dynamorm.ModelOf[Order](db).Index("gsi-customer").Where("CustomerID", "=", cid)
+//		})).
+//		Stage("hydrate", dynamorm.Hydrate[Order](db)).
+//		Stage("cache", dynamorm.Tap(cache.PutOrders))
+//	orders, err := recent.Run(ctx, nil)
+//
+// A Pipeline is safe for concurrent Runs once its stages are declared.
+type Pipeline[T any] struct {
+	observe func(StageResult)
+	name    string
+	stages  []namedStage[T]
+}
+
+type namedStage[T any] struct {
+	run  Stage[T]
+	name string
+}
+
+// NewPipeline creates an empty pipeline. name identifies it in errors and metrics.
+func NewPipeline[T any](name string) *Pipeline[T] {
+	return &Pipeline[T]{name: name}
+}
+
+// Stage appends a stage. Stages run in the order they are added.
+func (p *Pipeline[T]) Stage(name string, stage Stage[T]) *Pipeline[T] {
+	if stage != nil {
+		p.stages = append(p.stages, namedStage[T]{name: name, run: stage})
+	}
+	return p
+}
+
+// Observe calls fn after every stage with its duration, output size, and error.
+func (p *Pipeline[T]) Observe(fn func(StageResult)) *Pipeline[T] {
+	p.observe = fn
+	return p
+}
+
+// Run executes the stages in order, passing each stage's output to the next, and returns
+// the last stage's output. It stops at the first failing stage, or when ctx ends between
+// stages, returning a *errors.PipelineError naming the stage.
+func (p *Pipeline[T]) Run(ctx context.Context, input []T) ([]T, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	items := input
+	for _, stage := range p.stages {
+		if err := ctx.Err(); err != nil {
+			return nil, &customerrors.PipelineError{Pipeline: p.name, Stage: stage.name, Err: err}
+		}
+
+		start := time.Now()
+		out, err := stage.run(ctx, items)
+		if p.observe != nil {
+			p.observe(StageResult{
+				Pipeline: p.name,
+				Stage:    stage.name,
+				Items:    len(out),
+				Duration: time.Since(start),
+				Err:      err,
+			})
+		}
+		if err != nil {
+			return nil, &customerrors.PipelineError{Pipeline: p.name, Stage: stage.name, Err: err}
+		}
+		items = out
+	}
+	return items, nil
+}
+
+// FromQuery returns a stage that replaces its input with every item matched by the query
+// that build returns. The query runs with the pipeline's context.
+func FromQuery[T any](build func(ctx context.Context) *Query[T]) Stage[T] {
+	return func(ctx context.Context, _ []T) ([]T, error) {
+		return build(ctx).WithContext(ctx).All()
+	}
+}
+
+// Hydrate returns a stage that reloads its input items from the base table with
+// BatchGet, for index queries whose projection leaves out attributes. Order is preserved
+// and items no longer in the table are dropped.
+func Hydrate[T any](db core.DB) Stage[T] {
+	return func(ctx context.Context, items []T) ([]T, error) {
+		if len(items) == 0 {
+			return items, nil
+		}
+		keys := make([]any, len(items))
+		for i := range items {
+			keys[i] = &items[i]
+		}
+		return ModelOf[T](db).WithContext(ctx).BatchGet(keys...)
+	}
+}
+
+// Map returns a stage that applies fn to each item, such as a client-side transform.
+func Map[T any](fn func(T) (T, error)) Stage[T] {
+	return func(_ context.Context, items []T) ([]T, error) {
+		out := make([]T, len(items))
+		for i, item := range items {
+			mapped, err := fn(item)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = mapped
+		}
+		return out, nil
+	}
+}
+
+// Tap returns a stage that passes its input through unchanged after calling fn, such as
+// a cache write.
+func Tap[T any](fn func(ctx context.Context, items []T) error) Stage[T] {
+	return func(ctx context.Context, items []T) ([]T, error) {
+		if err := fn(ctx, items); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+}
@@ -0,0 +1,101 @@
+package dynamorm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+type pipelineOrder struct {
+	ID       string `dynamorm:"pk,attr:id"`
+	Customer string `dynamorm:"index:gsi-customer,pk,attr:customer"`
+	Note     string `dynamorm:"attr:note"`
+}
+
+func (pipelineOrder) TableName() string { return "pipeline_orders" }
+
+func TestPipeline_QueryHydrateTransformTap(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[{"id":{"S":"o2"},"customer":{"S":"c1"}},{"id":{"S":"o1"},"customer":{"S":"c1"}}],"Count":2}`,
+		"DynamoDB_20120810.BatchGetItem": `{"Responses":{"pipeline_orders":[` +
+			`{"id":{"S":"o1"},"customer":{"S":"c1"},"note":{"S":"first"}},` +
+			`{"id":{"S":"o2"},"customer":{"S":"c1"},"note":{"S":"second"}}]},"UnprocessedKeys":{}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var cached []pipelineOrder
+	var results []StageResult
+	pipeline := NewPipeline[pipelineOrder]("customer-orders").
+		Stage("query", FromQuery(func(context.Context) *Query[pipelineOrder] {
+			return ModelOf[pipelineOrder](db).Index("gsi-customer").Where("Customer", "=", "c1")
+		})).
+		Stage("hydrate", Hydrate[pipelineOrder](db)).
+		Stage("transform", Map(func(order pipelineOrder) (pipelineOrder, error) {
+			order.Note = strings.ToUpper(order.Note)
+			return order, nil
+		})).
+		Stage("cache", Tap(func(_ context.Context, items []pipelineOrder) error {
+			cached = items
+			return nil
+		})).
+		Observe(func(result StageResult) {
+			results = append(results, result)
+		})
+
+	orders, err := pipeline.Run(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, []pipelineOrder{
+		{ID: "o2", Customer: "c1", Note: "SECOND"},
+		{ID: "o1", Customer: "c1", Note: "FIRST"},
+	}, orders)
+	require.Equal(t, orders, cached)
+
+	require.Len(t, results, 4)
+	for i, name := range []string{"query", "hydrate", "transform", "cache"} {
+		require.Equal(t, "customer-orders", results[i].Pipeline)
+		require.Equal(t, name, results[i].Stage)
+		require.Equal(t, 2, results[i].Items)
+		require.NoError(t, results[i].Err)
+	}
+}
+
+func TestPipeline_StopsAtFailingStage(t *testing.T) {
+	boom := errors.New("boom")
+	ran := false
+	pipeline := NewPipeline[int]("numbers").
+		Stage("double", Map(func(n int) (int, error) { return n * 2, nil })).
+		Stage("fail", Tap(func(context.Context, []int) error { return boom })).
+		Stage("after", Tap(func(context.Context, []int) error {
+			ran = true
+			return nil
+		}))
+
+	_, err := pipeline.Run(context.Background(), []int{1, 2})
+	require.ErrorIs(t, err, boom)
+	var pipelineErr *customerrors.PipelineError
+	require.ErrorAs(t, err, &pipelineErr)
+	require.Equal(t, "numbers", pipelineErr.Pipeline)
+	require.Equal(t, "fail", pipelineErr.Stage)
+	require.False(t, ran)
+}
+
+func TestPipeline_StopsWhenContextEnds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pipeline := NewPipeline[int]("numbers").
+		Stage("cancel", Tap(func(context.Context, []int) error {
+			cancel()
+			return nil
+		})).
+		Stage("after", Map(func(n int) (int, error) { return n, nil }))
+
+	_, err := pipeline.Run(ctx, []int{1})
+	require.ErrorIs(t, err, context.Canceled)
+	var pipelineErr *customerrors.PipelineError
+	require.ErrorAs(t, err, &pipelineErr)
+	require.Equal(t, "after", pipelineErr.Stage)
+}
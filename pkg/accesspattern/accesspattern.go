@@ -0,0 +1,265 @@
+// Package accesspattern lets models declare the DynamoDB access patterns they are
+// expected to use and checks executed requests against those declarations.
+//
+// Single-table designs stay healthy only when every read maps to a known
+// partition/sort key shape. Declaring patterns next to the model and running the
+// registry in ModeEnforce during tests turns an accidental scan or an unplanned
+// GSI query into a test failure instead of a production surprise.
+package accesspattern
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// ErrViolation is returned (wrapped in a *Violation) when ModeEnforce rejects a request.
+var ErrViolation = errors.New("access pattern violation")
+
+// Operation names reported in Shape.Operation.
+const (
+	OperationQuery   = "Query"
+	OperationScan    = "Scan"
+	OperationGetItem = "GetItem"
+)
+
+// Mode controls what the registry does with requests that match no declared pattern.
+type Mode int
+
+const (
+	// ModeOff disables checking entirely. This is the default.
+	ModeOff Mode = iota
+	// ModeReport records violations and invokes the handler but lets requests run.
+	ModeReport
+	// ModeEnforce records violations, invokes the handler, and fails the request.
+	ModeEnforce
+)
+
+// Pattern describes one expected way of reading a model.
+type Pattern struct {
+	// Name identifies the pattern in reports (e.g. "OrdersByCustomer").
+	Name string
+	// Index is the GSI/LSI name, or empty for the base table.
+	Index string
+	// PartitionKey is the DynamoDB attribute name used as the partition key condition.
+	PartitionKey string
+	// SortKey is the attribute name the pattern may constrain. Requests that omit the
+	// sort key condition still match; requests that constrain a different attribute do not.
+	SortKey string
+	// Scan marks the pattern as an intentional scan of Index (or the base table).
+	Scan bool
+}
+
+// Shape is the key shape of an executed request.
+type Shape struct {
+	Operation    string
+	Table        string
+	Index        string
+	PartitionKey string
+	SortKey      string
+}
+
+func (s Shape) String() string {
+	target := s.Table
+	if s.Index != "" {
+		target += "/" + s.Index
+	}
+	switch s.Operation {
+	case OperationScan:
+		return fmt.Sprintf("Scan %s", target)
+	default:
+		parts := make([]string, 0, 2)
+		if s.PartitionKey != "" {
+			parts = append(parts, s.PartitionKey)
+		}
+		if s.SortKey != "" {
+			parts = append(parts, s.SortKey)
+		}
+		return fmt.Sprintf("%s %s (%s)", s.Operation, target, strings.Join(parts, ", "))
+	}
+}
+
+// Violation describes a request that matched none of a model's declared patterns.
+type Violation struct {
+	Model string
+	Shape Shape
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s: %s on model %s matches no declared pattern", ErrViolation.Error(), v.Shape, v.Model)
+}
+
+// Unwrap returns ErrViolation so callers can use errors.Is.
+func (v *Violation) Unwrap() error {
+	return ErrViolation
+}
+
+// Remediation suggests how to bring the request in line with the declared patterns.
+func (v *Violation) Remediation() customerrors.Remediation {
+	details := map[string]string{"model": v.Model, "table": v.Shape.Table}
+	if v.Shape.Index != "" {
+		details["index"] = v.Shape.Index
+	}
+	if v.Shape.Operation == OperationScan {
+		return customerrors.Remediation{
+			Code:    customerrors.RemediationScanFallback,
+			Hint:    "the read has no key condition, so it would scan; add a Where on the partition key of the table or of an Index, or declare the scan with Pattern{Scan: true}",
+			Details: details,
+		}
+	}
+	details["partitionKey"] = v.Shape.PartitionKey
+	if v.Shape.SortKey != "" {
+		details["sortKey"] = v.Shape.SortKey
+	}
+	return customerrors.Remediation{
+		Code:    customerrors.RemediationUndeclaredAccessPattern,
+		Hint:    "no declared pattern reads " + v.Shape.String() + "; query a declared index and key, or declare this pattern",
+		Details: details,
+	}
+}
+
+// Matches reports whether shape satisfies the pattern.
+func (p Pattern) Matches(shape Shape) bool {
+	if p.Index != shape.Index {
+		return false
+	}
+	if shape.Operation == OperationScan {
+		return p.Scan
+	}
+	if p.Scan {
+		return false
+	}
+	if p.PartitionKey != shape.PartitionKey {
+		return false
+	}
+	return shape.SortKey == "" || shape.SortKey == p.SortKey
+}
+
+// Registry stores declared patterns per model type and evaluates requests against them.
+// A Registry is safe for concurrent use.
+type Registry struct {
+	patterns   map[reflect.Type][]Pattern
+	handler    func(Violation)
+	violations []Violation
+	mode       Mode
+	mu         sync.RWMutex
+}
+
+// NewRegistry creates an empty registry in ModeOff.
+func NewRegistry() *Registry {
+	return &Registry{
+		patterns: make(map[reflect.Type][]Pattern),
+	}
+}
+
+// Register appends patterns for the given model type.
+func (r *Registry) Register(modelType reflect.Type, patterns ...Pattern) error {
+	if modelType == nil {
+		return fmt.Errorf("model type cannot be nil")
+	}
+	for modelType.Kind() == reflect.Pointer {
+		modelType = modelType.Elem()
+	}
+	for i, p := range patterns {
+		if p.Name == "" {
+			return fmt.Errorf("access pattern %d for %s: name is required", i, modelType.Name())
+		}
+		if !p.Scan && p.PartitionKey == "" {
+			return fmt.Errorf("access pattern %s for %s: partition key is required", p.Name, modelType.Name())
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.patterns[modelType] = append(r.patterns[modelType], patterns...)
+	return nil
+}
+
+// Patterns returns a copy of the patterns declared for the given model type.
+func (r *Registry) Patterns(modelType reflect.Type) []Pattern {
+	for modelType != nil && modelType.Kind() == reflect.Pointer {
+		modelType = modelType.Elem()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	declared := r.patterns[modelType]
+	out := make([]Pattern, len(declared))
+	copy(out, declared)
+	return out
+}
+
+// SetMode changes the checking mode and the optional violation handler.
+func (r *Registry) SetMode(mode Mode, handler func(Violation)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mode = mode
+	r.handler = handler
+}
+
+// Mode returns the current checking mode.
+func (r *Registry) Mode() Mode {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.mode
+}
+
+// Violations returns the violations recorded since the last Reset.
+func (r *Registry) Violations() []Violation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]Violation, len(r.violations))
+	copy(out, r.violations)
+	return out
+}
+
+// Reset clears recorded violations.
+func (r *Registry) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.violations = nil
+}
+
+// Check evaluates shape for the given model type. Models without declared patterns are
+// not checked. In ModeEnforce a non-matching request returns a *Violation.
+func (r *Registry) Check(modelType reflect.Type, shape Shape) error {
+	if r == nil || modelType == nil {
+		return nil
+	}
+	for modelType.Kind() == reflect.Pointer {
+		modelType = modelType.Elem()
+	}
+
+	r.mu.RLock()
+	mode := r.mode
+	declared := r.patterns[modelType]
+	r.mu.RUnlock()
+
+	if mode == ModeOff || len(declared) == 0 {
+		return nil
+	}
+	for _, p := range declared {
+		if p.Matches(shape) {
+			return nil
+		}
+	}
+
+	violation := Violation{Model: modelType.Name(), Shape: shape}
+
+	r.mu.Lock()
+	r.violations = append(r.violations, violation)
+	handler := r.handler
+	r.mu.Unlock()
+
+	if handler != nil {
+		handler(violation)
+	}
+	if mode == ModeEnforce {
+		return &violation
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package accesspattern
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+type order struct{}
+
+func TestPatternMatches(t *testing.T) {
+	byCustomer := Pattern{Name: "ByCustomer", Index: "gsi-customer", PartitionKey: "customerId", SortKey: "placedAt"}
+
+	require.True(t, byCustomer.Matches(Shape{Operation: OperationQuery, Index: "gsi-customer", PartitionKey: "customerId"}))
+	require.True(t, byCustomer.Matches(Shape{Operation: OperationQuery, Index: "gsi-customer", PartitionKey: "customerId", SortKey: "placedAt"}))
+	require.False(t, byCustomer.Matches(Shape{Operation: OperationQuery, Index: "gsi-customer", PartitionKey: "customerId", SortKey: "status"}))
+	require.False(t, byCustomer.Matches(Shape{Operation: OperationQuery, PartitionKey: "customerId"}))
+	require.False(t, byCustomer.Matches(Shape{Operation: OperationScan, Index: "gsi-customer"}))
+
+	scan := Pattern{Name: "Backfill", Scan: true}
+	require.True(t, scan.Matches(Shape{Operation: OperationScan}))
+	require.False(t, scan.Matches(Shape{Operation: OperationQuery, PartitionKey: "pk"}))
+}
+
+func TestRegistryRegisterValidates(t *testing.T) {
+	r := NewRegistry()
+	require.Error(t, r.Register(nil, Pattern{Name: "x", PartitionKey: "pk"}))
+	require.ErrorContains(t, r.Register(reflect.TypeOf(order{}), Pattern{PartitionKey: "pk"}), "name is required")
+	require.ErrorContains(t, r.Register(reflect.TypeOf(order{}), Pattern{Name: "x"}), "partition key is required")
+
+	require.NoError(t, r.Register(reflect.TypeOf(&order{}), Pattern{Name: "x", PartitionKey: "pk"}))
+	require.Len(t, r.Patterns(reflect.TypeOf(order{})), 1)
+}
+
+func TestRegistryCheckModes(t *testing.T) {
+	r := NewRegistry()
+	typ := reflect.TypeOf(order{})
+	require.NoError(t, r.Register(typ, Pattern{Name: "ByTenant", PartitionKey: "pk", SortKey: "sk"}))
+
+	scan := Shape{Operation: OperationScan, Table: "orders"}
+
+	require.Equal(t, ModeOff, r.Mode())
+	require.NoError(t, r.Check(typ, scan))
+	require.Empty(t, r.Violations())
+
+	var handled int
+	r.SetMode(ModeReport, func(Violation) { handled++ })
+	require.NoError(t, r.Check(typ, scan))
+	require.NoError(t, r.Check(typ, Shape{Operation: OperationGetItem, Table: "orders", PartitionKey: "pk", SortKey: "sk"}))
+	require.Equal(t, 1, handled)
+
+	r.SetMode(ModeEnforce, nil)
+	err := r.Check(typ, scan)
+	require.True(t, errors.Is(err, ErrViolation))
+	var violation *Violation
+	require.True(t, errors.As(err, &violation))
+	require.Equal(t, "order", violation.Model)
+	require.Contains(t, err.Error(), "Scan orders")
+	require.Len(t, r.Violations(), 2)
+
+	// Models without declarations are never flagged.
+	require.NoError(t, r.Check(reflect.TypeOf(struct{ ID string }{}), scan))
+
+	r.Reset()
+	require.Empty(t, r.Violations())
+}
+
+func TestShapeString(t *testing.T) {
+	require.Equal(t, "Query orders/gsi1 (customerId, placedAt)",
+		Shape{Operation: OperationQuery, Table: "orders", Index: "gsi1", PartitionKey: "customerId", SortKey: "placedAt"}.String())
+	require.Equal(t, "Scan orders", Shape{Operation: OperationScan, Table: "orders"}.String())
+}
+
+func TestViolationRemediation(t *testing.T) {
+	scan := &Violation{Model: "order", Shape: Shape{Operation: OperationScan, Table: "orders"}}
+	r, ok := customerrors.GetRemediation(scan)
+	require.True(t, ok)
+	require.Equal(t, customerrors.RemediationScanFallback, r.Code)
+	require.Equal(t, map[string]string{"model": "order", "table": "orders"}, r.Details)
+
+	query := &Violation{Model: "order", Shape: Shape{Operation: OperationQuery, Table: "orders", Index: "gsi-status", PartitionKey: "status"}}
+	r = query.Remediation()
+	require.Equal(t, customerrors.RemediationUndeclaredAccessPattern, r.Code)
+	require.Equal(t, "gsi-status", r.Details["index"])
+	require.Equal(t, "status", r.Details["partitionKey"])
+	require.Contains(t, r.Hint, "Query orders/gsi-status (status)")
+}
@@ -0,0 +1,205 @@
+// Package assumerole provides the STS credentials behind DB.WithAssumedRole. A DB scoped
+// to an assumed role signs its requests with the role's temporary credentials, so IAM
+// conditions on the role's session, such as dynamodb:LeadingKeys compared with a session
+// tag, are enforced by DynamoDB itself:
+//
+//	scoped := db.WithTenant(ctx, tenantID).
+//		WithAssumedRole(roleARN, externalID, assumerole.WithTenantTag("TenantID"))
+//
+// With a role policy allowing dynamodb:LeadingKeys equal to ${aws:PrincipalTag/TenantID},
+// requests of scoped for another tenant's partition are denied by DynamoDB.
+package assumerole
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+)
+
+// DefaultSessionName is the role session name used without WithSessionName.
+const DefaultSessionName = "dynamorm"
+
+// DefaultMaxRoles is how many role sessions a Cache made with a zero maximum holds.
+const DefaultMaxRoles = 1024
+
+// expiryWindow is how long before they expire cached credentials are refreshed.
+const expiryWindow = 5 * time.Minute
+
+// Option configures the role session of WithAssumedRole.
+type Option func(*settings)
+
+type settings struct {
+	tags        map[string]string
+	sessionName string
+	tenantTag   string
+	policy      string
+	duration    time.Duration
+}
+
+// WithSessionName sets the role session name, which CloudTrail records with each request.
+func WithSessionName(name string) Option {
+	return func(s *settings) {
+		s.sessionName = name
+	}
+}
+
+// WithSessionTags passes tags to the role session, for IAM conditions on
+// aws:PrincipalTag.
+func WithSessionTags(tags map[string]string) Option {
+	return func(s *settings) {
+		if s.tags == nil {
+			s.tags = make(map[string]string, len(tags))
+		}
+		for k, v := range tags {
+			s.tags[k] = v
+		}
+	}
+}
+
+// WithTenantTag passes the tenant of the DB, set with WithTenant or
+// leadingkeys.WithTenant, as the session tag key. Without a tenant the role is not
+// assumed and requests fail.
+func WithTenantTag(key string) Option {
+	return func(s *settings) {
+		s.tenantTag = key
+	}
+}
+
+// WithPolicy passes an inline session policy, which narrows the role's permissions for
+// the session, such as to one tenant's leading keys.
+func WithPolicy(policy string) Option {
+	return func(s *settings) {
+		s.policy = policy
+	}
+}
+
+// WithDuration sets how long the role's credentials last. STS defaults to one hour.
+func WithDuration(d time.Duration) Option {
+	return func(s *settings) {
+		s.duration = d
+	}
+}
+
+// Cache holds the credentials of the roles DBs assume, so DBs scoped to the same role
+// session share one set, fetched from STS once and refreshed before it expires. The
+// oldest sessions are dropped once it holds its maximum.
+type Cache struct {
+	client    stscreds.AssumeRoleAPIClient
+	providers map[string]*aws.CredentialsCache
+	order     []string
+	max       int
+	mu        sync.Mutex
+}
+
+// NewCache returns a Cache assuming roles with client. maxRoles caps the role sessions
+// it holds, DefaultMaxRoles when zero.
+func NewCache(client stscreds.AssumeRoleAPIClient, maxRoles int) *Cache {
+	if maxRoles <= 0 {
+		maxRoles = DefaultMaxRoles
+	}
+	return &Cache{
+		client:    client,
+		providers: make(map[string]*aws.CredentialsCache),
+		max:       maxRoles,
+	}
+}
+
+// Provider returns the credentials of roleARN's session configured by opts. tenant is
+// the value of the WithTenantTag tag.
+func (c *Cache) Provider(roleARN, externalID, tenant string, opts ...Option) (aws.CredentialsProvider, error) {
+	if c == nil || c.client == nil {
+		return nil, errors.New("assumed role cache has no STS client")
+	}
+	s, key, err := resolve(roleARN, externalID, tenant, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if provider, ok := c.providers[key]; ok {
+		return provider, nil
+	}
+
+	provider := aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(c.client, roleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = s.sessionName
+		o.Duration = s.duration
+		if externalID != "" {
+			o.ExternalID = aws.String(externalID)
+		}
+		if s.policy != "" {
+			o.Policy = aws.String(s.policy)
+		}
+		for _, name := range sortedKeys(s.tags) {
+			o.Tags = append(o.Tags, types.Tag{Key: aws.String(name), Value: aws.String(s.tags[name])})
+		}
+	}), func(o *aws.CredentialsCacheOptions) {
+		o.ExpiryWindow = expiryWindow
+	})
+
+	if len(c.order) >= c.max {
+		delete(c.providers, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.providers[key] = provider
+	c.order = append(c.order, key)
+	return provider, nil
+}
+
+// SessionKey returns the key identifying the role session Provider returns the credentials
+// of for the same arguments. Sessions with equal keys act as the same principal.
+func SessionKey(roleARN, externalID, tenant string, opts ...Option) (string, error) {
+	_, key, err := resolve(roleARN, externalID, tenant, opts)
+	return key, err
+}
+
+func resolve(roleARN, externalID, tenant string, opts []Option) (settings, string, error) {
+	if roleARN == "" {
+		return settings{}, "", errors.New("role ARN cannot be empty")
+	}
+
+	s := settings{sessionName: DefaultSessionName}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&s)
+		}
+	}
+	if s.tenantTag != "" {
+		if tenant == "" {
+			return settings{}, "", fmt.Errorf("session tag %s needs a tenant; use DB.WithTenant", s.tenantTag)
+		}
+		WithSessionTags(map[string]string{s.tenantTag: tenant})(&s)
+	}
+	return s, cacheKey(roleARN, externalID, s), nil
+}
+
+// Len returns the number of role sessions held.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.providers)
+}
+
+func cacheKey(roleARN, externalID string, s settings) string {
+	parts := []string{roleARN, externalID, s.sessionName, s.policy, s.duration.String()}
+	for _, name := range sortedKeys(s.tags) {
+		parts = append(parts, name+"="+s.tags[name])
+	}
+	return strings.Join(parts, "\x00")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,108 @@
+package assumerole
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSTS struct {
+	mu    sync.Mutex
+	calls []*sts.AssumeRoleInput
+}
+
+func (f *fakeSTS) AssumeRole(_ context.Context, in *sts.AssumeRoleInput, _ ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, in)
+	return &sts.AssumeRoleOutput{Credentials: &types.Credentials{
+		AccessKeyId:     aws.String("ASIA" + aws.ToString(in.RoleSessionName)),
+		SecretAccessKey: aws.String("secret"),
+		SessionToken:    aws.String("token"),
+		Expiration:      aws.Time(time.Now().Add(time.Hour)),
+	}}, nil
+}
+
+func TestCacheProvider(t *testing.T) {
+	client := &fakeSTS{}
+	cache := NewCache(client, 0)
+
+	provider, err := cache.Provider("arn:aws:iam::123:role/tenant", "ext", "t1",
+		WithTenantTag("TenantID"), WithSessionName("api"), WithSessionTags(map[string]string{"App": "billing"}))
+	require.NoError(t, err)
+	for range 3 {
+		creds, err := provider.Retrieve(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "ASIAapi", creds.AccessKeyID)
+	}
+
+	require.Len(t, client.calls, 1)
+	in := client.calls[0]
+	require.Equal(t, "arn:aws:iam::123:role/tenant", aws.ToString(in.RoleArn))
+	require.Equal(t, "ext", aws.ToString(in.ExternalId))
+	require.Equal(t, "api", aws.ToString(in.RoleSessionName))
+	require.Equal(t, []types.Tag{
+		{Key: aws.String("App"), Value: aws.String("billing")},
+		{Key: aws.String("TenantID"), Value: aws.String("t1")},
+	}, in.Tags)
+
+	again, err := cache.Provider("arn:aws:iam::123:role/tenant", "ext", "t1",
+		WithSessionTags(map[string]string{"App": "billing"}), WithSessionName("api"), WithTenantTag("TenantID"))
+	require.NoError(t, err)
+	require.Same(t, provider, again)
+
+	other, err := cache.Provider("arn:aws:iam::123:role/tenant", "ext", "t2",
+		WithTenantTag("TenantID"), WithSessionName("api"), WithSessionTags(map[string]string{"App": "billing"}))
+	require.NoError(t, err)
+	require.NotSame(t, provider, other)
+	require.Equal(t, 2, cache.Len())
+}
+
+func TestCacheProviderErrors(t *testing.T) {
+	cache := NewCache(&fakeSTS{}, 0)
+
+	_, err := cache.Provider("", "", "t1")
+	require.ErrorContains(t, err, "role ARN cannot be empty")
+	_, err = cache.Provider("arn:aws:iam::123:role/tenant", "", "", WithTenantTag("TenantID"))
+	require.ErrorContains(t, err, "session tag TenantID needs a tenant")
+	_, err = NewCache(nil, 0).Provider("arn:aws:iam::123:role/tenant", "", "")
+	require.ErrorContains(t, err, "no STS client")
+	require.Zero(t, cache.Len())
+}
+
+func TestCacheEvictsOldest(t *testing.T) {
+	cache := NewCache(&fakeSTS{}, 2)
+
+	first, err := cache.Provider("arn:aws:iam::123:role/a", "", "")
+	require.NoError(t, err)
+	_, err = cache.Provider("arn:aws:iam::123:role/b", "", "")
+	require.NoError(t, err)
+	_, err = cache.Provider("arn:aws:iam::123:role/c", "", "")
+	require.NoError(t, err)
+	require.Equal(t, 2, cache.Len())
+
+	again, err := cache.Provider("arn:aws:iam::123:role/a", "", "")
+	require.NoError(t, err)
+	require.NotSame(t, first, again)
+}
+
+func TestSessionKey(t *testing.T) {
+	key, err := SessionKey("arn:aws:iam::123:role/tenant", "ext", "t1", WithTenantTag("TenantID"), WithSessionName("api"))
+	require.NoError(t, err)
+	same, err := SessionKey("arn:aws:iam::123:role/tenant", "ext", "t1", WithSessionName("api"), WithTenantTag("TenantID"))
+	require.NoError(t, err)
+	require.Equal(t, key, same)
+
+	other, err := SessionKey("arn:aws:iam::123:role/tenant", "ext", "t2", WithTenantTag("TenantID"), WithSessionName("api"))
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+
+	_, err = SessionKey("arn:aws:iam::123:role/tenant", "", "", WithTenantTag("TenantID"))
+	require.ErrorContains(t, err, "needs a tenant")
+}
@@ -0,0 +1,197 @@
+// Package audit provides the change records of DynamORM's audit mode. Enable it with
+// DB.WithAudit:
+//
+//	audited := db.WithAudit(audit.Options{Table: "payment-audit"})
+//	ctx = audit.WithActor(ctx, "user:42")
+//	err := audited.WithContext(ctx).Model(&payment).Update("Status")
+//
+// Every update and delete made through the returned DB reads the item, then commits the
+// write together with a Record of the attributes it changes: their values before and
+// after, the actor from the context, and the time. The write is conditioned on the
+// attributes still holding the values read, so the record cannot miss a concurrent
+// change; such a race fails the write with errors.ErrConditionFailed.
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Attributes of the items Options.Table holds, one per Record.
+const (
+	// AttributeItemKey is the string partition key: the audited table and item key, as
+	// in "payments#id=p1", so one Query returns the history of an item.
+	AttributeItemKey = "itemKey"
+	// AttributeChangedAt is the string sort key: the UTC time of the change to the
+	// nanosecond, then a random suffix, so records sort in the order they were made.
+	AttributeChangedAt = "changedAt"
+	AttributeTable     = "table"
+	AttributeKey       = "key"
+	AttributeOperation = "operation"
+	AttributeActor     = "actor"
+	AttributeOldValues = "oldValues"
+	AttributeNewValues = "newValues"
+)
+
+// Operations a Record describes.
+const (
+	OperationUpdate = "UpdateItem"
+	OperationDelete = "DeleteItem"
+)
+
+// timestampLayout formats AttributeChangedAt with fixed-width fractions, which
+// time.RFC3339Nano trims, so the sort key orders records by time.
+const timestampLayout = "2006-01-02T15:04:05.000000000Z"
+
+// Options configures audit mode. At least one of Table and Publish must be set.
+type Options struct {
+	// Publish receives each record once the write it describes commits, to send it to a
+	// stream such as Kinesis Data Streams or EventBridge. The write is not undone when
+	// Publish fails; its error is returned from the write.
+	Publish func(ctx context.Context, record *Record) error
+	// Table is the DynamoDB table records are written to, in the same TransactWriteItems
+	// call as the write they describe. Its key is AttributeItemKey and
+	// AttributeChangedAt, both strings. Enable a DynamoDB stream on it to feed the
+	// records elsewhere.
+	Table string
+}
+
+// Enabled reports whether o turns audit mode on.
+func (o Options) Enabled() bool {
+	return o.Table != "" || o.Publish != nil
+}
+
+// Record is the change one update or delete makes to an item. Values are as stored, so
+// dynamorm:"encrypted" fields stay encrypted.
+type Record struct {
+	// Timestamp is when the write was made.
+	Timestamp time.Time
+	// Key is the primary key of the item.
+	Key map[string]types.AttributeValue
+	// OldValues holds the changed attributes the item had before the write.
+	OldValues map[string]types.AttributeValue
+	// NewValues holds the changed attributes the item has after the write. Removed
+	// attributes, and all of a deleted item's, are only in OldValues.
+	NewValues map[string]types.AttributeValue
+	// Table is the table of the item.
+	Table string
+	// Operation is OperationUpdate or OperationDelete.
+	Operation string
+	// Actor is who made the write, from WithActor, or empty when the context had none.
+	Actor string
+}
+
+type actorKey struct{}
+
+// WithActor returns a context whose audited writes record actor as the one who made
+// them, such as a user ID or service name.
+func WithActor(ctx context.Context, actor string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored by WithActor.
+func ActorFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// ItemKey returns the AttributeItemKey value of records of the item with key in table.
+func ItemKey(table string, key map[string]types.AttributeValue) string {
+	names := make([]string, 0, len(key))
+	for name := range key {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		var value any
+		switch v := key[name].(type) {
+		case *types.AttributeValueMemberS:
+			value = v.Value
+		case *types.AttributeValueMemberN:
+			value = v.Value
+		case *types.AttributeValueMemberB:
+			value = hex.EncodeToString(v.Value)
+		}
+		parts[i] = fmt.Sprintf("%s=%v", name, value)
+	}
+	return table + "#" + strings.Join(parts, ",")
+}
+
+// Item returns r as an item of Options.Table.
+func (r *Record) Item() (map[string]types.AttributeValue, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("failed to generate audit record ID: %w", err)
+	}
+
+	item := map[string]types.AttributeValue{
+		AttributeItemKey:   &types.AttributeValueMemberS{Value: ItemKey(r.Table, r.Key)},
+		AttributeChangedAt: &types.AttributeValueMemberS{Value: r.Timestamp.UTC().Format(timestampLayout) + "#" + hex.EncodeToString(suffix)},
+		AttributeTable:     &types.AttributeValueMemberS{Value: r.Table},
+		AttributeKey:       &types.AttributeValueMemberM{Value: r.Key},
+		AttributeOperation: &types.AttributeValueMemberS{Value: r.Operation},
+		AttributeOldValues: &types.AttributeValueMemberM{Value: nonNil(r.OldValues)},
+		AttributeNewValues: &types.AttributeValueMemberM{Value: nonNil(r.NewValues)},
+	}
+	if r.Actor != "" {
+		item[AttributeActor] = &types.AttributeValueMemberS{Value: r.Actor}
+	}
+	return item, nil
+}
+
+// RecordFromItem returns the Record an item of Options.Table holds, as read from the
+// table or a stream on it.
+func RecordFromItem(item map[string]types.AttributeValue) (*Record, error) {
+	changedAt := stringAttribute(item, AttributeChangedAt)
+	timestamp, _, _ := strings.Cut(changedAt, "#")
+	parsed, err := time.Parse(timestampLayout, timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit record %s %q: %w", AttributeChangedAt, changedAt, err)
+	}
+
+	return &Record{
+		Timestamp: parsed,
+		Key:       mapAttribute(item, AttributeKey),
+		OldValues: mapAttribute(item, AttributeOldValues),
+		NewValues: mapAttribute(item, AttributeNewValues),
+		Table:     stringAttribute(item, AttributeTable),
+		Operation: stringAttribute(item, AttributeOperation),
+		Actor:     stringAttribute(item, AttributeActor),
+	}, nil
+}
+
+func stringAttribute(item map[string]types.AttributeValue, name string) string {
+	if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
+
+func mapAttribute(item map[string]types.AttributeValue, name string) map[string]types.AttributeValue {
+	if v, ok := item[name].(*types.AttributeValueMemberM); ok {
+		return v.Value
+	}
+	return nil
+}
+
+func nonNil(values map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if values == nil {
+		return map[string]types.AttributeValue{}
+	}
+	return values
+}
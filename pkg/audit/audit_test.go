@@ -0,0 +1,55 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordItemRoundTrip(t *testing.T) {
+	record := &Record{
+		Timestamp: time.Date(2026, 3, 1, 12, 0, 0, 5, time.UTC),
+		Key: map[string]types.AttributeValue{
+			"pk": &types.AttributeValueMemberS{Value: "p1"},
+			"sk": &types.AttributeValueMemberN{Value: "7"},
+		},
+		OldValues: map[string]types.AttributeValue{"status": &types.AttributeValueMemberS{Value: "pending"}},
+		NewValues: map[string]types.AttributeValue{"status": &types.AttributeValueMemberS{Value: "settled"}},
+		Table:     "payments",
+		Operation: OperationUpdate,
+		Actor:     "user:42",
+	}
+
+	item, err := record.Item()
+	require.NoError(t, err)
+	require.Equal(t, &types.AttributeValueMemberS{Value: "payments#pk=p1,sk=7"}, item[AttributeItemKey])
+	require.Regexp(t, `^2026-03-01T12:00:00\.000000005Z#[0-9a-f]{16}$`, item[AttributeChangedAt].(*types.AttributeValueMemberS).Value)
+
+	again, err := record.Item()
+	require.NoError(t, err)
+	require.NotEqual(t, item[AttributeChangedAt], again[AttributeChangedAt])
+
+	decoded, err := RecordFromItem(item)
+	require.NoError(t, err)
+	require.Equal(t, record, decoded)
+
+	_, err = RecordFromItem(map[string]types.AttributeValue{})
+	require.Error(t, err)
+}
+
+func TestRecordItemOmitsEmptyActor(t *testing.T) {
+	item, err := (&Record{Table: "payments", Operation: OperationDelete}).Item()
+	require.NoError(t, err)
+	require.NotContains(t, item, AttributeActor)
+	require.Equal(t, &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{}}, item[AttributeNewValues])
+}
+
+func TestActorFromContext(t *testing.T) {
+	require.Empty(t, ActorFromContext(context.Background()))
+	require.Equal(t, "svc:billing", ActorFromContext(WithActor(context.Background(), "svc:billing")))
+	require.False(t, Options{}.Enabled())
+	require.True(t, Options{Table: "audit"}.Enabled())
+}
@@ -0,0 +1,325 @@
+// Package backfill populates a derived attribute on every existing item of a model,
+// typically the key of a new GSI such as a composite "status#date".
+//
+// Run scans the table page by page, calls a derive function on each item, and writes the
+// fields it changed with a conditional UpdateItem, pacing writes to a rate limit. After
+// each page it saves a checkpoint, so an interrupted backfill resumes where it stopped
+// and a finished one is not run again:
+//
+//	report, err := backfill.Run(ctx, db, func(o *Order) (bool, error) {
+//		key := o.Status + "#" + o.PlacedAt.Format("2006-01-02")
+//		if o.StatusDate == key {
+//			return false, nil
+//		}
+//		o.StatusDate = key
+//		return true, nil
+//	}, backfill.Options{
+//		Fields:          []string{"StatusDate"},
+//		WritesPerSecond: 50,
+//		Checkpoint:      backfill.FileCheckpoint("orders-status-date.cursor"),
+//		Progress:        func(r backfill.Report) { log.Printf("%+v", r) },
+//	})
+package backfill
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// DefaultBatchSize is the number of items scanned per page when Options.BatchSize is zero.
+const DefaultBatchSize = 100
+
+// Checkpoint is the saved position of a backfill.
+type Checkpoint struct {
+	// Cursor is the position of the next page; "" with Done unset starts from the beginning.
+	Cursor string `json:"cursor,omitempty"`
+	// Done records that the scan reached the end of the table.
+	Done bool `json:"done,omitempty"`
+}
+
+// Checkpointer persists the scan position between pages.
+type Checkpointer interface {
+	// Load returns the saved checkpoint, or the zero Checkpoint if none was saved.
+	Load(ctx context.Context) (Checkpoint, error)
+	// Save records the checkpoint after a page.
+	Save(ctx context.Context, checkpoint Checkpoint) error
+}
+
+// Options configures Run.
+type Options struct {
+	// Checkpoint, when set, is loaded before the scan starts and saved after each page. A
+	// checkpoint saved by a finished backfill makes Run return without scanning; remove it
+	// to run the backfill again.
+	Checkpoint Checkpointer
+	// Progress, when set, is called after each page with the running totals.
+	Progress func(Report)
+	// Fields lists the model fields the derive function sets. Only these are written.
+	Fields []string
+	// BatchSize is the number of items scanned per page. Zero uses DefaultBatchSize.
+	BatchSize int
+	// Feedback, when set, lowers the write rate below WritesPerSecond while the table is
+	// throttled. A write that fails throttled is reported to it and retried with backoff
+	// instead of ending the run.
+	Feedback core.ThrottleFeedback
+	// WritesPerSecond caps the write rate. Zero means unlimited.
+	WritesPerSecond float64
+	// DryRun derives values without writing them.
+	DryRun bool
+}
+
+// Report summarizes a backfill.
+type Report struct {
+	// Cursor is the position of the next page; it is empty once the scan is complete.
+	Cursor string
+	// Scanned counts the items read.
+	Scanned int
+	// Derived counts the items the derive function changed.
+	Derived int
+	// Written counts the items updated.
+	Written int
+	// Skipped counts changed items that were deleted before they could be written.
+	Skipped int
+	// Throttled counts writes that were throttled and retried, with Options.Feedback set.
+	Throttled int
+	// Pages counts the pages processed.
+	Pages int
+	// Elapsed is the time spent since Run started.
+	Elapsed time.Duration
+	// Done reports that every item has been processed, in this run or an earlier one.
+	Done bool
+}
+
+// Run backfills every item of model T. derive updates the item in place and reports
+// whether it changed anything; unchanged items are not written. Each changed item is
+// written with Update(opts.Fields...) under an attribute_exists condition, so items
+// deleted since the scan are skipped rather than recreated.
+//
+// Run stops at the first derive or write error, returning it with the report so far;
+// the checkpoint then points at the failed page, which is scanned again on the next run.
+func Run[T any](ctx context.Context, db core.DB, derive func(item *T) (bool, error), opts Options) (*Report, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if db == nil {
+		return nil, errors.New("backfill: db is required")
+	}
+	if derive == nil {
+		return nil, errors.New("backfill: derive function is required")
+	}
+	if len(opts.Fields) == 0 {
+		return nil, errors.New("backfill: at least one field is required")
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	report := &Report{}
+	start := time.Now()
+	if opts.Checkpoint != nil {
+		checkpoint, err := opts.Checkpoint.Load(ctx)
+		if err != nil {
+			return report, fmt.Errorf("backfill: load checkpoint: %w", err)
+		}
+		if checkpoint.Done {
+			report.Done = true
+			return report, nil
+		}
+		report.Cursor = checkpoint.Cursor
+	}
+
+	table := ""
+	if opts.Feedback != nil {
+		if namer, ok := db.(tableNamer); ok {
+			if name, err := namer.TableName(new(T)); err == nil {
+				table = name
+			}
+		}
+	}
+
+	db = db.WithContext(ctx)
+	pacer := newPacer(opts.WritesPerSecond)
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		var page []T
+		query := db.Model(new(T)).Limit(batchSize)
+		if report.Cursor != "" {
+			query = query.Cursor(report.Cursor)
+		}
+		result, err := query.AllPaginated(&page)
+		if err != nil {
+			return report, fmt.Errorf("backfill: scan: %w", err)
+		}
+
+		for i := range page {
+			report.Scanned++
+			changed, err := derive(&page[i])
+			if err != nil {
+				return report, fmt.Errorf("backfill: derive: %w", err)
+			}
+			if !changed {
+				continue
+			}
+			report.Derived++
+			if opts.DryRun {
+				continue
+			}
+
+			err = write(ctx, db, &page[i], opts, table, pacer, report)
+			switch {
+			case err == nil:
+				report.Written++
+			case errors.Is(err, customerrors.ErrConditionFailed):
+				report.Skipped++
+			default:
+				return report, fmt.Errorf("backfill: write: %w", err)
+			}
+		}
+
+		report.Pages++
+		report.Cursor = ""
+		if result != nil && result.HasMore {
+			report.Cursor = result.NextCursor
+		}
+		report.Done = report.Cursor == ""
+		report.Elapsed = time.Since(start)
+
+		if opts.Checkpoint != nil {
+			checkpoint := Checkpoint{Cursor: report.Cursor, Done: report.Done}
+			if err := opts.Checkpoint.Save(ctx, checkpoint); err != nil {
+				return report, fmt.Errorf("backfill: save checkpoint: %w", err)
+			}
+		}
+		if opts.Progress != nil {
+			opts.Progress(*report)
+		}
+		if report.Done {
+			return report, nil
+		}
+	}
+}
+
+// tableNamer is implemented by DBs that resolve a model's table name, which Feedback
+// is keyed by.
+type tableNamer interface {
+	TableName(model any) (string, error)
+}
+
+// Backoff between retries of a throttled write, doubling from throttleBaseDelay.
+const (
+	throttleBaseDelay = 100 * time.Millisecond
+	throttleMaxDelay  = 5 * time.Second
+)
+
+var throttleCheck = retry.IsErrorThrottles(retry.DefaultThrottles)
+
+// write updates one changed item, pacing it to the write rate scaled by opts.Feedback and,
+// with Feedback, retrying it while it is throttled.
+func write[T any](ctx context.Context, db core.DB, item *T, opts Options, table string, pacer *pacer, report *Report) error {
+	delay := throttleBaseDelay
+	for {
+		scale := 1.0
+		if opts.Feedback != nil {
+			scale = opts.Feedback.Scale(table)
+		}
+		if err := pacer.wait(ctx, scale); err != nil {
+			return err
+		}
+		err := db.Model(item).IfExists().Update(opts.Fields...)
+		if opts.Feedback == nil || !throttleCheck.IsErrorThrottle(err).Bool() {
+			return err
+		}
+
+		opts.Feedback.Observe(table, 1, 1)
+		report.Throttled++
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+		delay = min(2*delay, throttleMaxDelay)
+	}
+}
+
+// pacer spaces writes evenly to stay under a rate.
+type pacer struct {
+	next     time.Time
+	interval time.Duration
+}
+
+func newPacer(perSecond float64) *pacer {
+	if perSecond <= 0 {
+		return &pacer{}
+	}
+	return &pacer{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until the next write is due at scale times the pacer's rate.
+func (p *pacer) wait(ctx context.Context, scale float64) error {
+	if p.interval <= 0 {
+		return nil
+	}
+	interval := p.interval
+	if scale > 0 && scale < 1 {
+		interval = time.Duration(float64(interval) / scale)
+	}
+	now := time.Now()
+	if p.next.After(now) {
+		if err := sleep(ctx, p.next.Sub(now)); err != nil {
+			return err
+		}
+		now = p.next
+	}
+	p.next = now.Add(interval)
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// FileCheckpoint stores the checkpoint as JSON in a local file, for backfills run from a
+// workstation or a long-lived task.
+type FileCheckpoint string
+
+// Load implements Checkpointer. A missing file starts from the beginning.
+func (f FileCheckpoint) Load(context.Context) (Checkpoint, error) {
+	var checkpoint Checkpoint
+	data, err := os.ReadFile(string(f))
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return checkpoint, err
+	}
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return checkpoint, fmt.Errorf("read %s: %w", string(f), err)
+	}
+	return checkpoint, nil
+}
+
+// Save implements Checkpointer.
+func (f FileCheckpoint) Save(_ context.Context, checkpoint Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(string(f), data, 0o600)
+}
@@ -0,0 +1,224 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/mocks"
+	"github.com/pay-theory/dynamorm/pkg/throttle"
+)
+
+type order struct {
+	ID         string
+	Status     string
+	Date       string
+	StatusDate string
+}
+
+// backfillDB hands out the scan query for new models and the write query for scanned items.
+type backfillDB struct {
+	scan  *mocks.MockQuery
+	write *mocks.MockQuery
+}
+
+func (d backfillDB) Model(model any) core.Query {
+	if o, ok := model.(*order); ok && o.ID != "" {
+		return d.write
+	}
+	return d.scan
+}
+func (d backfillDB) Transaction(func(tx *core.Tx) error) error { return nil }
+func (d backfillDB) Migrate() error                            { return nil }
+func (d backfillDB) AutoMigrate(...any) error                  { return nil }
+func (d backfillDB) Close() error                              { return nil }
+func (d backfillDB) WithContext(context.Context) core.DB       { return d }
+
+func deriveStatusDate(o *order) (bool, error) {
+	key := o.Status + "#" + o.Date
+	if o.StatusDate == key {
+		return false, nil
+	}
+	o.StatusDate = key
+	return true, nil
+}
+
+// expectPage answers the scan of the page at cursor with items and next.
+func expectPage(q *mocks.MockQuery, cursor string, items []order, next string) {
+	if cursor != "" {
+		q.On("Cursor", cursor).Return(q).Once()
+	}
+	q.On("AllPaginated", mock.Anything).Run(func(args mock.Arguments) {
+		dest, ok := args.Get(0).(*[]order)
+		if ok {
+			*dest = items
+		}
+	}).Return(&core.PaginatedResult{NextCursor: next, HasMore: next != ""}, nil).Once()
+}
+
+func newBackfillDB() backfillDB {
+	db := backfillDB{scan: new(mocks.MockQuery), write: new(mocks.MockQuery)}
+	db.scan.On("Limit", 2).Return(db.scan)
+	db.write.On("IfExists").Return(db.write)
+	return db
+}
+
+func TestRunDerivesAndWritesChangedItems(t *testing.T) {
+	db := newBackfillDB()
+	expectPage(db.scan, "", []order{
+		{ID: "o1", Status: "paid", Date: "2026-01-02"},
+		{ID: "o2", Status: "open", Date: "2026-01-03", StatusDate: "open#2026-01-03"},
+	}, "page-2")
+	expectPage(db.scan, "page-2", []order{{ID: "o3", Status: "void", Date: "2026-01-04"}}, "")
+	db.write.On("Update", []string{"StatusDate"}).Return(nil).Once()
+	db.write.On("Update", []string{"StatusDate"}).Return(customerrors.ErrConditionFailed).Once()
+
+	checkpoint := FileCheckpoint(filepath.Join(t.TempDir(), "orders.cursor"))
+	var progress []Report
+	report, err := Run(context.Background(), db, deriveStatusDate, Options{
+		Fields:     []string{"StatusDate"},
+		BatchSize:  2,
+		Checkpoint: checkpoint,
+		Progress:   func(r Report) { progress = append(progress, r) },
+	})
+	require.NoError(t, err)
+	require.True(t, report.Done)
+	require.Equal(t, 3, report.Scanned)
+	require.Equal(t, 2, report.Derived)
+	require.Equal(t, 1, report.Written)
+	require.Equal(t, 1, report.Skipped)
+	require.Equal(t, 2, report.Pages)
+
+	require.Len(t, progress, 2)
+	require.Equal(t, "page-2", progress[0].Cursor)
+	require.False(t, progress[0].Done)
+	require.True(t, progress[1].Done)
+
+	saved, err := checkpoint.Load(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Checkpoint{Done: true}, saved)
+	db.scan.AssertExpectations(t)
+	db.write.AssertExpectations(t)
+}
+
+func TestRunRetriesThrottledWritesWithFeedback(t *testing.T) {
+	db := newBackfillDB()
+	expectPage(db.scan, "", []order{{ID: "o1", Status: "paid", Date: "2026-01-02"}}, "")
+	throttled := &smithy.GenericAPIError{Code: "ProvisionedThroughputExceededException", Message: "slow down"}
+	db.write.On("Update", []string{"StatusDate"}).Return(throttled).Once()
+	db.write.On("Update", []string{"StatusDate"}).Return(nil).Once()
+
+	feedback := throttle.NewController(throttle.Options{})
+	report, err := Run(context.Background(), db, deriveStatusDate, Options{
+		Fields:          []string{"StatusDate"},
+		BatchSize:       2,
+		WritesPerSecond: 1000,
+		Feedback:        feedback,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Written)
+	require.Equal(t, 1, report.Throttled)
+	require.Equal(t, 0.5, feedback.Scale(""))
+	db.write.AssertExpectations(t)
+
+	// Without feedback a throttled write ends the run, as any other write error does.
+	db = newBackfillDB()
+	expectPage(db.scan, "", []order{{ID: "o1", Status: "paid", Date: "2026-01-02"}}, "")
+	db.write.On("Update", []string{"StatusDate"}).Return(throttled).Once()
+	_, err = Run(context.Background(), db, deriveStatusDate, Options{Fields: []string{"StatusDate"}, BatchSize: 2})
+	require.ErrorAs(t, err, new(*smithy.GenericAPIError))
+}
+
+func TestRunSkipsFinishedBackfill(t *testing.T) {
+	db := newBackfillDB()
+	checkpoint := FileCheckpoint(filepath.Join(t.TempDir(), "orders.cursor"))
+	require.NoError(t, checkpoint.Save(context.Background(), Checkpoint{Done: true}))
+
+	report, err := Run(context.Background(), db, deriveStatusDate, Options{
+		Fields:     []string{"StatusDate"},
+		BatchSize:  2,
+		Checkpoint: checkpoint,
+	})
+	require.NoError(t, err)
+	require.True(t, report.Done)
+	require.Zero(t, report.Scanned)
+	db.scan.AssertNotCalled(t, "AllPaginated", mock.Anything)
+}
+
+func TestRunResumesFromCheckpointAfterError(t *testing.T) {
+	checkpoint := FileCheckpoint(filepath.Join(t.TempDir(), "orders.cursor"))
+	opts := Options{Fields: []string{"StatusDate"}, BatchSize: 2, Checkpoint: checkpoint}
+
+	first := newBackfillDB()
+	expectPage(first.scan, "", []order{{ID: "o1", Status: "paid", Date: "d1"}}, "page-2")
+	expectPage(first.scan, "page-2", []order{{ID: "o2", Status: "paid", Date: "d2"}}, "")
+	first.write.On("Update", []string{"StatusDate"}).Return(nil).Once()
+	first.write.On("Update", []string{"StatusDate"}).Return(errors.New("throttled")).Once()
+
+	report, err := Run(context.Background(), first, deriveStatusDate, opts)
+	require.ErrorContains(t, err, "backfill: write: throttled")
+	require.Equal(t, 1, report.Written)
+
+	saved, err := checkpoint.Load(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, Checkpoint{Cursor: "page-2"}, saved)
+
+	second := newBackfillDB()
+	expectPage(second.scan, "page-2", []order{{ID: "o2", Status: "paid", Date: "d2"}}, "")
+	second.write.On("Update", []string{"StatusDate"}).Return(nil).Once()
+
+	report, err = Run(context.Background(), second, deriveStatusDate, opts)
+	require.NoError(t, err)
+	require.True(t, report.Done)
+	require.Equal(t, 1, report.Written)
+	second.scan.AssertExpectations(t)
+}
+
+func TestRunDryRunDoesNotWrite(t *testing.T) {
+	db := newBackfillDB()
+	expectPage(db.scan, "", []order{{ID: "o1", Status: "paid", Date: "d1"}}, "")
+
+	report, err := Run(context.Background(), db, deriveStatusDate, Options{
+		Fields:    []string{"StatusDate"},
+		BatchSize: 2,
+		DryRun:    true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Derived)
+	require.Zero(t, report.Written)
+	db.write.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestRunValidatesArguments(t *testing.T) {
+	_, err := Run[order](context.Background(), nil, deriveStatusDate, Options{Fields: []string{"StatusDate"}})
+	require.ErrorContains(t, err, "db is required")
+
+	_, err = Run[order](context.Background(), newBackfillDB(), nil, Options{Fields: []string{"StatusDate"}})
+	require.ErrorContains(t, err, "derive function is required")
+
+	_, err = Run(context.Background(), newBackfillDB(), deriveStatusDate, Options{})
+	require.ErrorContains(t, err, "at least one field")
+}
+
+func TestPacerSpacesWrites(t *testing.T) {
+	p := newPacer(100)
+	require.Equal(t, 10*time.Millisecond, p.interval)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, p.wait(context.Background(), 1))
+	}
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, p.wait(ctx, 1), context.Canceled)
+}
@@ -0,0 +1,156 @@
+// Package cache provides the backends for DynamORM's read-through item cache. Enable the
+// cache with DB.WithItemCache:
+//
+//	cached := db.WithItemCache(cache.Options{
+//		Store: cache.NewLRU(10_000),
+//		TTL:   time.Minute,
+//	})
+//
+// GetItem reads through the returned DB are served from Store when the item is cached
+// and stored after a miss. Writes made through any DB sharing the Store delete the
+// items they touch, so the next read goes to DynamoDB. ConsistentRead requests skip
+// the lookup but still refresh the cached item.
+//
+// Store is small enough to adapt any shared cache, so several processes see each
+// other's invalidations; the separate github.com/pay-theory/dynamorm/pkg/cache/redis
+// module implements it over Redis and ElastiCache. Items are stored in DynamoDB JSON.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how long items stay cached when Options.TTL is zero.
+const DefaultTTL = 5 * time.Minute
+
+// Store is a cache backend. Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the value stored under key, reporting false when it is missing or has
+	// expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores value under key for ttl.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes keys. Missing keys are not an error.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Options configures the item cache.
+type Options struct {
+	// Store holds the cached items. It is required.
+	Store Store
+	// OnError is called when Store fails. The read or write falls back to DynamoDB
+	// either way; without OnError the error is dropped.
+	OnError func(err error)
+	// TableTTLs overrides TTL for the named tables. A zero or negative TTL turns the
+	// cache off for the table.
+	TableTTLs map[string]time.Duration
+	// TTL is how long items stay cached. Zero means DefaultTTL.
+	TTL time.Duration
+}
+
+// TTLFor returns how long items of table stay cached, or zero when they are not cached.
+func (o Options) TTLFor(table string) time.Duration {
+	if ttl, ok := o.TableTTLs[table]; ok {
+		if ttl < 0 {
+			return 0
+		}
+		return ttl
+	}
+	if o.TTL <= 0 {
+		return DefaultTTL
+	}
+	return o.TTL
+}
+
+// LRU is an in-memory Store holding at most a fixed number of entries, evicting the
+// least recently used entry first.
+type LRU struct {
+	now      func() time.Time
+	entries  map[string]*list.Element
+	order    *list.List
+	capacity int
+	mu       sync.Mutex
+}
+
+type lruEntry struct {
+	expires time.Time
+	key     string
+	value   []byte
+}
+
+// NewLRU returns an LRU holding up to capacity entries. A capacity below one holds one.
+func NewLRU(capacity int) *LRU {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRU{
+		now:      time.Now,
+		entries:  make(map[string]*list.Element, capacity),
+		order:    list.New(),
+		capacity: capacity,
+	}
+}
+
+// Get implements Store.
+func (c *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*lruEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(elem)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(elem)
+	return append([]byte(nil), entry.value...), true, nil
+}
+
+// Set implements Store.
+func (c *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &lruEntry{key: key, value: append([]byte(nil), value...), expires: c.now().Add(ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// Delete implements Store.
+func (c *LRU) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := c.entries[key]; ok {
+			c.remove(elem)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of entries held, including expired ones not yet evicted.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*lruEntry).key)
+}
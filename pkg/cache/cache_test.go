@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	lru := NewLRU(4)
+	lru.now = func() time.Time { return now }
+
+	require.NoError(t, lru.Set(ctx, "a", []byte("1"), time.Minute))
+	value, ok, err := lru.Get(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []byte("1"), value)
+
+	value[0] = 'x'
+	value, _, _ = lru.Get(ctx, "a")
+	require.Equal(t, []byte("1"), value, "callers must not share the stored bytes")
+
+	now = now.Add(time.Minute)
+	_, ok, err = lru.Get(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.Zero(t, lru.Len())
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	lru := NewLRU(2)
+
+	require.NoError(t, lru.Set(ctx, "a", []byte("1"), time.Minute))
+	require.NoError(t, lru.Set(ctx, "b", []byte("2"), time.Minute))
+	_, _, _ = lru.Get(ctx, "a")
+	require.NoError(t, lru.Set(ctx, "c", []byte("3"), time.Minute))
+
+	_, ok, _ := lru.Get(ctx, "b")
+	require.False(t, ok)
+	_, ok, _ = lru.Get(ctx, "a")
+	require.True(t, ok)
+
+	require.NoError(t, lru.Delete(ctx, "a", "missing"))
+	_, ok, _ = lru.Get(ctx, "a")
+	require.False(t, ok)
+	require.Equal(t, 1, lru.Len())
+}
+
+func TestOptionsTTLFor(t *testing.T) {
+	opts := Options{
+		TTL:       time.Minute,
+		TableTTLs: map[string]time.Duration{"sessions": 5 * time.Second, "ledger": -1},
+	}
+	require.Equal(t, time.Minute, opts.TTLFor("orders"))
+	require.Equal(t, 5*time.Second, opts.TTLFor("sessions"))
+	require.Zero(t, opts.TTLFor("ledger"))
+	require.Equal(t, DefaultTTL, Options{}.TTLFor("orders"))
+}
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy("ttl=30s, scope=tenant,negative=5s")
+	require.NoError(t, err)
+	require.Equal(t, &Policy{Scope: ScopeTenant, TTL: 30 * time.Second, NegativeTTL: 5 * time.Second}, policy)
+
+	policy, err = ParsePolicy("off")
+	require.NoError(t, err)
+	require.True(t, policy.Disabled)
+
+	for _, spec := range []string{"ttl", "ttl=soon", "ttl=-1s", "scope=global", "size=10"} {
+		_, err := ParsePolicy(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestOptionsPolicyTTL(t *testing.T) {
+	opts := Options{TTL: time.Minute, TableTTLs: map[string]time.Duration{"ledger": 0}}
+	require.Equal(t, time.Minute, opts.PolicyTTL("orders", nil))
+	require.Equal(t, time.Minute, opts.PolicyTTL("orders", &Policy{}))
+	require.Equal(t, 30*time.Second, opts.PolicyTTL("orders", &Policy{TTL: 30 * time.Second}))
+	require.Zero(t, opts.PolicyTTL("orders", &Policy{TTL: 30 * time.Second, Disabled: true}))
+	require.Zero(t, opts.PolicyTTL("ledger", &Policy{TTL: 30 * time.Second}), "table TTLs override the policy")
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Scope decides which callers share a cached item.
+type Scope string
+
+const (
+	// ScopeTable shares cached items between every caller reading the table.
+	ScopeTable Scope = "table"
+	// ScopeTenant keeps a separate copy of each item per tenant, as set with
+	// WithTenant. Reads without a tenant bypass the cache; writes invalidate every
+	// tenant's copy.
+	ScopeTenant Scope = "tenant"
+)
+
+// Policy is a model's caching behavior, declared on a blank field next to its schema:
+//
+//	type Order struct {
+//		_ struct{} `dynamorm:"cache:ttl=30s,scope=tenant,negative=5s"`
+//		...
+//	}
+//
+// The options are ttl (how long items stay cached), scope (table or tenant), negative
+// (how long a missing item is remembered), and off, which turns the cache off for the
+// model.
+type Policy struct {
+	Scope Scope
+	// TTL is how long items stay cached. Zero means the Options' TTL.
+	TTL time.Duration
+	// NegativeTTL is how long a read that found no item is remembered. Zero caches
+	// only items that exist.
+	NegativeTTL time.Duration
+	// Disabled turns the cache off for the model.
+	Disabled bool
+}
+
+// ParsePolicy parses the options of a cache tag, such as "ttl=30s,scope=tenant".
+func ParsePolicy(spec string) (*Policy, error) {
+	policy := &Policy{Scope: ScopeTable}
+	for _, option := range strings.Split(spec, ",") {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+		if option == "off" {
+			policy.Disabled = true
+			continue
+		}
+
+		name, value, ok := strings.Cut(option, "=")
+		if !ok {
+			return nil, fmt.Errorf("cache option %q needs a value", option)
+		}
+		switch name {
+		case "ttl", "negative":
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("cache option %s=%s is not a positive duration", name, value)
+			}
+			if name == "ttl" {
+				policy.TTL = d
+			} else {
+				policy.NegativeTTL = d
+			}
+		case "scope":
+			switch Scope(value) {
+			case ScopeTable, ScopeTenant:
+				policy.Scope = Scope(value)
+			default:
+				return nil, fmt.Errorf("cache scope %q is not table or tenant", value)
+			}
+		default:
+			return nil, fmt.Errorf("unknown cache option %q", name)
+		}
+	}
+	return policy, nil
+}
+
+// PolicyTTL returns how long items of table stay cached under policy, or zero when
+// they are not cached. Options.TableTTLs still override the policy, so operators can
+// turn off or shorten caching for a table without a code change.
+func (o Options) PolicyTTL(table string, policy *Policy) time.Duration {
+	if policy == nil {
+		return o.TTLFor(table)
+	}
+	if policy.Disabled {
+		return 0
+	}
+	if _, ok := o.TableTTLs[table]; ok || policy.TTL <= 0 {
+		return o.TTLFor(table)
+	}
+	return policy.TTL
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a context whose reads and writes use tenant's copies of items
+// in models with a tenant-scoped cache policy.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant set with WithTenant.
+func TenantFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
@@ -0,0 +1,91 @@
+// Package redis is a cache.Store backed by Redis, so DynamORM's item cache and its
+// invalidations are shared across processes such as Lambda instances.
+//
+// It is a separate module so applications that cache in memory do not pull in a Redis
+// client. Pass it to DB.WithItemCache:
+//
+//	client := goredis.NewClient(&goredis.Options{Addr: "cache.example.com:6379"})
+//	cached := db.WithItemCache(cache.Options{
+//		Store: redis.New(client, redis.WithKeyPrefix("orders-api:")),
+//		TTL:   time.Minute,
+//	})
+//
+// Any go-redis UniversalClient works, including cluster clients for ElastiCache in
+// cluster mode.
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/pay-theory/dynamorm/pkg/cache"
+)
+
+// Store is a cache.Store keeping entries in Redis with Redis-managed expiry.
+type Store struct {
+	client goredis.UniversalClient
+	prefix string
+}
+
+var _ cache.Store = (*Store)(nil)
+
+// Option configures New.
+type Option func(*Store)
+
+// WithKeyPrefix prefixes every key the Store reads and writes, so several applications
+// can share a Redis database.
+func WithKeyPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// New returns a Store sending commands with client. The Store does not own the client
+// and never closes it.
+func New(client goredis.UniversalClient, opts ...Option) *Store {
+	s := &Store{client: client}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+	return s
+}
+
+// Get implements cache.Store.
+func (s *Store) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set implements cache.Store. A ttl of zero or less stores nothing and deletes key, since
+// Redis would otherwise keep the entry forever.
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		return s.Delete(ctx, key)
+	}
+	return s.client.Set(ctx, s.prefix+key, value, ttl).Err()
+}
+
+// Delete implements cache.Store. Keys are deleted one command each in a pipeline, so keys
+// in different cluster slots can be deleted together.
+func (s *Store) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	pipe := s.client.Pipeline()
+	for _, key := range keys {
+		pipe.Del(ctx, s.prefix+key)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T, opts ...Option) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { require.NoError(t, client.Close()) })
+	return New(client, opts...), server
+}
+
+func TestStoreGetSetDelete(t *testing.T) {
+	ctx := context.Background()
+	store, server := newTestStore(t)
+
+	_, found, err := store.Get(ctx, "item")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	require.NoError(t, store.Set(ctx, "item", []byte(`{"id":{"S":"a1"}}`), time.Minute))
+	value, found, err := store.Get(ctx, "item")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, `{"id":{"S":"a1"}}`, string(value))
+	require.Equal(t, time.Minute, server.TTL("item"))
+
+	require.NoError(t, store.Set(ctx, "other", []byte("x"), time.Minute))
+	require.NoError(t, store.Delete(ctx, "item", "other", "missing"))
+	require.NoError(t, store.Delete(ctx))
+	require.False(t, server.Exists("item"))
+	require.False(t, server.Exists("other"))
+}
+
+func TestStoreExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	store, server := newTestStore(t)
+
+	require.NoError(t, store.Set(ctx, "item", []byte("x"), time.Second))
+	server.FastForward(2 * time.Second)
+	_, found, err := store.Get(ctx, "item")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	require.NoError(t, store.Set(ctx, "item", []byte("x"), time.Minute))
+	require.NoError(t, store.Set(ctx, "item", []byte("y"), 0))
+	require.False(t, server.Exists("item"), "a zero TTL stores nothing")
+}
+
+func TestStoreKeyPrefix(t *testing.T) {
+	ctx := context.Background()
+	store, server := newTestStore(t, WithKeyPrefix("orders:"))
+
+	require.NoError(t, store.Set(ctx, "item", []byte("x"), time.Minute))
+	require.True(t, server.Exists("orders:item"))
+	_, found, err := store.Get(ctx, "item")
+	require.NoError(t, err)
+	require.True(t, found)
+
+	require.NoError(t, store.Delete(ctx, "item"))
+	require.False(t, server.Exists("orders:item"))
+}
+
+func TestStoreReportsErrors(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { require.NoError(t, client.Close()) })
+	store := New(client)
+	server.Close()
+
+	_, _, err := store.Get(ctx, "item")
+	require.Error(t, err)
+	require.Error(t, store.Set(ctx, "item", []byte("x"), time.Minute))
+	require.Error(t, store.Delete(ctx, "item"))
+}
@@ -0,0 +1,276 @@
+// Package canary runs registered lightweight queries on a schedule and reports each run,
+// so throttling on a backfilling GSI or an IAM regression shows up on a dashboard before
+// it shows up in customer traffic.
+//
+// A Probe is any function that talks to DynamoDB, typically a Limit(1) query against the
+// index or access pattern to watch:
+//
+//	runner := canary.NewRunner(time.Minute)
+//	runner.Register(canary.Probe{
+//		Name:       "orders-by-customer",
+//		LatencySLO: 200 * time.Millisecond,
+//		Check: func(ctx context.Context) error {
+//			var out []Order
+//			return db.WithContext(ctx).Model(&Order{}).Index("gsi-customer").
+//				Where("CustomerID", "=", "canary").Limit(1).All(&out)
+//		},
+//	})
+//	runner.SetHook(func(r canary.Result) { metrics.Record(r) })
+//	runner.Start(ctx)
+//	db.OnShutdown("canary", func(context.Context) error { runner.Stop(); return nil })
+package canary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds a single probe run when the probe does not set its own.
+const DefaultTimeout = 5 * time.Second
+
+// Outcome classifies a probe run.
+type Outcome string
+
+const (
+	// OutcomeOK is a run that succeeded within its latency objective.
+	OutcomeOK Outcome = "ok"
+	// OutcomeSlow is a run that succeeded but took longer than its LatencySLO.
+	OutcomeSlow Outcome = "slow"
+	// OutcomeThrottled is a run rejected by DynamoDB throttling, as happens while a new
+	// GSI backfills or a table runs out of capacity.
+	OutcomeThrottled Outcome = "throttled"
+	// OutcomeAccessDenied is a run rejected by IAM or a leading key check.
+	OutcomeAccessDenied Outcome = "access_denied"
+	// OutcomeTimeout is a run that did not finish within its timeout.
+	OutcomeTimeout Outcome = "timeout"
+	// OutcomeError is any other failure.
+	OutcomeError Outcome = "error"
+)
+
+// Probe is one registered canary query.
+type Probe struct {
+	// Check performs the query. It should read little and must honor ctx.
+	Check func(ctx context.Context) error
+	Name  string
+	// LatencySLO is the latency above which a successful run is reported as slow.
+	// Zero disables the latency objective.
+	LatencySLO time.Duration
+	// Timeout bounds each run; zero uses DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Result describes one probe run. It is passed to the hook set with SetHook.
+type Result struct {
+	Time    time.Time
+	Err     error
+	Probe   string
+	Outcome Outcome
+	Latency time.Duration
+}
+
+// Breached reports whether the run missed its latency or error objective.
+func (r Result) Breached() bool {
+	return r.Outcome != OutcomeOK
+}
+
+// Runner runs probes. It is safe for concurrent use.
+type Runner struct {
+	now      func() time.Time
+	hook     func(Result)
+	last     map[string]Result
+	cancel   context.CancelFunc
+	done     chan struct{}
+	probes   []Probe
+	interval time.Duration
+	mu       sync.Mutex
+}
+
+// NewRunner creates a runner that, once started, runs every probe each interval.
+func NewRunner(interval time.Duration) *Runner {
+	return &Runner{
+		now:      time.Now,
+		interval: interval,
+		last:     make(map[string]Result),
+	}
+}
+
+// Register adds probes. Names must be unique and non-empty.
+func (r *Runner) Register(probes ...Probe) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range probes {
+		if p.Name == "" {
+			return errors.New("canary: probe name is required")
+		}
+		if p.Check == nil {
+			return fmt.Errorf("canary: probe %s has no check", p.Name)
+		}
+		for _, existing := range r.probes {
+			if existing.Name == p.Name {
+				return fmt.Errorf("canary: probe %s is already registered", p.Name)
+			}
+		}
+		r.probes = append(r.probes, p)
+	}
+	return nil
+}
+
+// SetHook registers a function called with every result, e.g. to emit a metric or page
+// on breaches.
+func (r *Runner) SetHook(hook func(Result)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hook = hook
+}
+
+// RunOnce runs every probe once, in registration order, and returns their results.
+func (r *Runner) RunOnce(ctx context.Context) []Result {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	r.mu.Lock()
+	probes := append([]Probe(nil), r.probes...)
+	r.mu.Unlock()
+
+	results := make([]Result, 0, len(probes))
+	for _, p := range probes {
+		if ctx.Err() != nil {
+			break
+		}
+		result := r.run(ctx, p)
+		if ctx.Err() != nil {
+			// The runner was stopped mid-run; the probe did not fail on its own.
+			break
+		}
+
+		r.mu.Lock()
+		r.last[p.Name] = result
+		hook := r.hook
+		r.mu.Unlock()
+
+		if hook != nil {
+			hook(result)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func (r *Runner) run(ctx context.Context, p Probe) Result {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := r.now()
+	err := p.Check(runCtx)
+	latency := r.now().Sub(start)
+
+	result := Result{
+		Time:    start,
+		Probe:   p.Name,
+		Latency: latency,
+		Err:     err,
+		Outcome: classify(err),
+	}
+	if err != nil && runCtx.Err() == context.DeadlineExceeded {
+		result.Outcome = OutcomeTimeout
+	}
+	if result.Outcome == OutcomeOK && p.LatencySLO > 0 && latency > p.LatencySLO {
+		result.Outcome = OutcomeSlow
+	}
+	return result
+}
+
+// classify maps a probe error to an outcome by the DynamoDB error code it carries.
+func classify(err error) Outcome {
+	if err == nil {
+		return OutcomeOK
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return OutcomeTimeout
+	}
+	msg := err.Error()
+	for _, code := range []string{"ProvisionedThroughputExceededException", "ThrottlingException", "RequestLimitExceeded"} {
+		if strings.Contains(msg, code) {
+			return OutcomeThrottled
+		}
+	}
+	for _, code := range []string{"AccessDeniedException", "UnrecognizedClientException", "leading key access denied"} {
+		if strings.Contains(msg, code) {
+			return OutcomeAccessDenied
+		}
+	}
+	return OutcomeError
+}
+
+// Last returns the most recent result of each probe, sorted by probe name.
+func (r *Runner) Last() []Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]Result, 0, len(r.last))
+	for _, result := range r.last {
+		out = append(out, result)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Probe < out[j].Probe })
+	return out
+}
+
+// Start runs the probes immediately and then every interval in the background until ctx
+// ends or Stop is called. Starting a running runner is an error.
+func (r *Runner) Start(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.interval <= 0 {
+		return errors.New("canary: interval must be positive")
+	}
+	if r.done != nil {
+		return errors.New("canary: runner already started")
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+	go r.loop(ctx, r.done)
+	return nil
+}
+
+func (r *Runner) loop(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.RunOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops a started runner and waits for an in-progress run to finish.
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
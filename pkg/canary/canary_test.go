@@ -0,0 +1,122 @@
+package canary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunOnceClassifiesOutcomes(t *testing.T) {
+	r := NewRunner(time.Minute)
+	var clock time.Time
+	r.now = func() time.Time { return clock }
+
+	slow := func(d time.Duration, err error) func(context.Context) error {
+		return func(context.Context) error {
+			clock = clock.Add(d)
+			return err
+		}
+	}
+	require.NoError(t, r.Register(
+		Probe{Name: "ok", LatencySLO: 100 * time.Millisecond, Check: slow(50*time.Millisecond, nil)},
+		Probe{Name: "slow", LatencySLO: 100 * time.Millisecond, Check: slow(150*time.Millisecond, nil)},
+		Probe{Name: "no-slo", Check: slow(time.Hour, nil)},
+		Probe{Name: "throttled", Check: slow(0, fmt.Errorf("query failed: %w",
+			errors.New("api error ThrottlingException: Throughput exceeds the current capacity")))},
+		Probe{Name: "denied", Check: slow(0, errors.New("api error AccessDeniedException: not authorized"))},
+		Probe{Name: "broken", Check: slow(0, errors.New("boom"))},
+	))
+
+	var hooked []string
+	r.SetHook(func(res Result) { hooked = append(hooked, res.Probe) })
+
+	results := r.RunOnce(context.Background())
+	outcomes := make(map[string]Outcome, len(results))
+	for _, res := range results {
+		outcomes[res.Probe] = res.Outcome
+	}
+	require.Equal(t, map[string]Outcome{
+		"ok":        OutcomeOK,
+		"slow":      OutcomeSlow,
+		"no-slo":    OutcomeOK,
+		"throttled": OutcomeThrottled,
+		"denied":    OutcomeAccessDenied,
+		"broken":    OutcomeError,
+	}, outcomes)
+	require.Equal(t, []string{"ok", "slow", "no-slo", "throttled", "denied", "broken"}, hooked)
+	require.Equal(t, 150*time.Millisecond, results[1].Latency)
+	require.True(t, results[1].Breached())
+	require.False(t, results[0].Breached())
+
+	last := r.Last()
+	require.Len(t, last, 6)
+	require.Equal(t, "broken", last[0].Probe)
+}
+
+func TestRunOnceReportsTimeouts(t *testing.T) {
+	r := NewRunner(time.Minute)
+	require.NoError(t, r.Register(Probe{
+		Name:    "hangs",
+		Timeout: 10 * time.Millisecond,
+		Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return fmt.Errorf("request canceled: %w", ctx.Err())
+		},
+	}))
+
+	results := r.RunOnce(context.Background())
+	require.Len(t, results, 1)
+	require.Equal(t, OutcomeTimeout, results[0].Outcome)
+}
+
+func TestRegisterRejectsInvalidProbes(t *testing.T) {
+	r := NewRunner(time.Minute)
+	check := func(context.Context) error { return nil }
+
+	require.Error(t, r.Register(Probe{Check: check}))
+	require.Error(t, r.Register(Probe{Name: "nil-check"}))
+	require.NoError(t, r.Register(Probe{Name: "p", Check: check}))
+	require.Error(t, r.Register(Probe{Name: "p", Check: check}))
+}
+
+func TestStartRunsOnScheduleUntilStopped(t *testing.T) {
+	r := NewRunner(5 * time.Millisecond)
+	var mu sync.Mutex
+	runs := 0
+	require.NoError(t, r.Register(Probe{Name: "p", Check: func(context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		runs++
+		return nil
+	}}))
+
+	require.NoError(t, r.Start(context.Background()))
+	require.Error(t, r.Start(context.Background()))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return runs >= 3
+	}, time.Second, time.Millisecond)
+
+	r.Stop()
+	mu.Lock()
+	stopped := runs
+	mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	require.Equal(t, stopped, runs)
+	mu.Unlock()
+
+	r.Stop()
+	require.NoError(t, r.Start(context.Background()))
+	r.Stop()
+}
+
+func TestStartRequiresPositiveInterval(t *testing.T) {
+	require.Error(t, NewRunner(0).Start(context.Background()))
+}
@@ -0,0 +1,265 @@
+// Package cli implements the dynamorm command, which runs operational tasks against the
+// models an application registers: creating tables, diffing indexes, backfills, exports,
+// imports, seeding, and describing tables. Go cannot load models at run time, so the
+// command is built from a small main package that serves as its configuration:
+//
+//	package main
+//
+//	func main() {
+//		os.Exit(cli.Main(cli.Config{
+//			Models: map[string]any{"orders": &Order{}, "users": &User{}},
+//			Backfills: map[string]cli.Backfill{
+//				"order-status-date": cli.BackfillOf(deriveStatusDate, "StatusDate"),
+//			},
+//			Seeds: map[string]cli.Seed{"demo": seedDemo},
+//		}))
+//	}
+//
+// and run with, for example, go run ./cmd/dynamorm diff orders. Run it without
+// arguments to list the commands and the registered names.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/pay-theory/dynamorm"
+	"github.com/pay-theory/dynamorm/pkg/backfill"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+var (
+	// ErrUsage is returned by Run for unknown commands or names and invalid flags.
+	ErrUsage = errors.New("invalid usage")
+	// ErrDrift is returned by diff -exit-code when a table is missing or its indexes
+	// differ from its model.
+	ErrDrift = errors.New("tables differ from their models")
+)
+
+// Config registers what the commands operate on. Names are used on the command line.
+type Config struct {
+	// Models maps names to pointers to model structs.
+	Models map[string]any
+	// Backfills maps names to backfills, usually built with BackfillOf.
+	Backfills map[string]Backfill
+	// Seeds maps names to functions that write fixture data.
+	Seeds map[string]Seed
+	// Open returns the DB the commands run against, given the session configuration
+	// built from the -region and -endpoint flags. Nil uses dynamorm.New.
+	Open func(cfg session.Config) (*dynamorm.DB, error)
+}
+
+// Backfill runs a backfill with the options built from the backfill command's flags.
+type Backfill func(ctx context.Context, db core.DB, opts backfill.Options) (*backfill.Report, error)
+
+// BackfillOf returns a Backfill that runs backfill.Run on every item of model T, writing
+// the named fields derive sets.
+func BackfillOf[T any](derive func(item *T) (bool, error), fields ...string) Backfill {
+	return func(ctx context.Context, db core.DB, opts backfill.Options) (*backfill.Report, error) {
+		opts.Fields = fields
+		return backfill.Run(ctx, db, derive, opts)
+	}
+}
+
+// Seed writes fixture data, such as the items a development table starts with.
+type Seed func(ctx context.Context, db core.ExtendedDB) error
+
+// command is one subcommand of the dynamorm command. run defines its flags on fs and
+// parses args with it.
+type command struct {
+	run     func(r *runner, fs *flag.FlagSet, args []string) error
+	name    string
+	args    string
+	summary string
+}
+
+var commands = []command{
+	{name: "create-table", args: "[model...]", summary: "create the tables of the models that do not have one", run: (*runner).createTable},
+	{name: "diff", args: "[-exit-code] [model...]", summary: "compare the tables' indexes with the models", run: (*runner).diff},
+	{name: "backfill", args: "[flags] name", summary: "run a registered backfill", run: (*runner).backfill},
+	{name: "export", args: "[flags] model", summary: "write a model's items as JSON Lines or CSV", run: (*runner).export},
+	{name: "import", args: "[flags] model", summary: "write items read from a file into a model's table", run: (*runner).importItems},
+	{name: "seed", args: "[name...]", summary: "run registered seeds, all of them by default", run: (*runner).seed},
+	{name: "describe", args: "[model...]", summary: "show the tables' keys, indexes, and size", run: (*runner).describe},
+}
+
+// runner holds the state of one Run.
+type runner struct {
+	ctx    context.Context
+	cfg    Config
+	db     *dynamorm.DB
+	stdout io.Writer
+	stderr io.Writer
+	sess   session.Config
+}
+
+// Main runs the command named by os.Args, stopping on SIGINT or SIGTERM, and returns the
+// exit status: 0 on success, 2 for usage errors, and 1 otherwise.
+func Main(cfg Config) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := Run(ctx, cfg, os.Args[1:], os.Stdout, os.Stderr)
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, ErrUsage):
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	default:
+		fmt.Fprintln(os.Stderr, "dynamorm:", err)
+		return 1
+	}
+}
+
+// Run runs the command in args, which excludes the program name. Output goes to stdout,
+// and progress and usage to stderr.
+func Run(ctx context.Context, cfg Config, args []string, stdout, stderr io.Writer) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	r := &runner{ctx: ctx, cfg: cfg, stdout: stdout, stderr: stderr}
+
+	global := flag.NewFlagSet("dynamorm", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	global.StringVar(&r.sess.Region, "region", os.Getenv("AWS_REGION"), "AWS region")
+	global.StringVar(&r.sess.Endpoint, "endpoint", "", "DynamoDB endpoint, such as http://localhost:8000 for DynamoDB Local")
+	global.Usage = func() { r.usage(global) }
+	if err := global.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if global.NArg() == 0 {
+		r.usage(global)
+		return fmt.Errorf("%w: no command given", ErrUsage)
+	}
+
+	name := global.Arg(0)
+	if name == "help" {
+		r.usage(global)
+		return nil
+	}
+	for _, cmd := range commands {
+		if cmd.name == name {
+			fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+			fs.SetOutput(stderr)
+			fs.Usage = func() {
+				fmt.Fprintf(stderr, "usage: dynamorm %s %s\n", cmd.name, cmd.args)
+				fs.PrintDefaults()
+			}
+			return cmd.run(r, fs, global.Args()[1:])
+		}
+	}
+	r.usage(global)
+	return fmt.Errorf("%w: unknown command %q", ErrUsage, name)
+}
+
+func (r *runner) usage(global *flag.FlagSet) {
+	fmt.Fprintln(r.stderr, "usage: dynamorm [-region region] [-endpoint url] command [arguments]")
+	fmt.Fprintln(r.stderr, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(r.stderr, "  %-13s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(r.stderr, "\nflags:")
+	global.PrintDefaults()
+	for _, list := range []struct {
+		title string
+		names []string
+	}{
+		{"models", sortedKeys(r.cfg.Models)},
+		{"backfills", sortedKeys(r.cfg.Backfills)},
+		{"seeds", sortedKeys(r.cfg.Seeds)},
+	} {
+		if len(list.names) > 0 {
+			fmt.Fprintf(r.stderr, "\n%s: %s\n", list.title, strings.Join(list.names, ", "))
+		}
+	}
+}
+
+// open returns the DB, opening it on first use.
+func (r *runner) open() (*dynamorm.DB, error) {
+	if r.db != nil {
+		return r.db, nil
+	}
+	if r.cfg.Open != nil {
+		db, err := r.cfg.Open(r.sess)
+		if err != nil {
+			return nil, err
+		}
+		r.db = db
+		return db, nil
+	}
+
+	opened, err := dynamorm.New(r.sess)
+	if err != nil {
+		return nil, err
+	}
+	db, ok := opened.(*dynamorm.DB)
+	if !ok {
+		return nil, fmt.Errorf("dynamorm.New returned %T", opened)
+	}
+	r.db = db
+	return db, nil
+}
+
+// namedModel is a registered model with its name.
+type namedModel struct {
+	model any
+	name  string
+}
+
+// models returns the models named, or all of them sorted by name when names is empty.
+func (r *runner) models(names []string) ([]namedModel, error) {
+	if len(names) == 0 {
+		names = sortedKeys(r.cfg.Models)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("%w: no models are registered", ErrUsage)
+		}
+	}
+	out := make([]namedModel, 0, len(names))
+	for _, name := range names {
+		model, ok := r.cfg.Models[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown model %q", ErrUsage, name)
+		}
+		out = append(out, namedModel{name: name, model: model})
+	}
+	return out, nil
+}
+
+// oneModel returns the model named by the single argument of a command.
+func (r *runner) oneModel(fs *flag.FlagSet) (namedModel, error) {
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return namedModel{}, fmt.Errorf("%w: %s takes one model", ErrUsage, fs.Name())
+	}
+	models, err := r.models(fs.Args())
+	if err != nil {
+		return namedModel{}, err
+	}
+	return models[0], nil
+}
+
+func usageError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrUsage, err)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
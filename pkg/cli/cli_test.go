@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+type order struct {
+	ID     string `dynamorm:"pk,attr:id"`
+	Status string `dynamorm:"index:gsi-status,pk,attr:status"`
+}
+
+func (order) TableName() string { return "orders" }
+
+type stubResponse struct {
+	headers map[string]string
+	body    string
+	status  int
+}
+
+// stubClient answers DynamoDB requests by X-Amz-Target and records their payloads.
+type stubClient struct {
+	responses map[string]stubResponse
+	requests  map[string][]map[string]any
+	mu        sync.Mutex
+}
+
+func newStubClient(bodies map[string]string) *stubClient {
+	c := &stubClient{responses: make(map[string]stubResponse), requests: make(map[string][]map[string]any)}
+	for target, body := range bodies {
+		c.responses["DynamoDB_20120810."+target] = stubResponse{body: body}
+	}
+	return c
+}
+
+func (c *stubClient) Do(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	payload := make(map[string]any)
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, err
+		}
+	}
+
+	target := req.Header.Get("X-Amz-Target")
+	c.mu.Lock()
+	c.requests[target] = append(c.requests[target], payload)
+	stub := c.responses[target]
+	c.mu.Unlock()
+
+	status := stub.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if stub.body == "" {
+		stub.body = "{}"
+	}
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(stub.body)),
+		Request:    req,
+	}
+	resp.Header.Set("Content-Type", "application/x-amz-json-1.0")
+	for k, v := range stub.headers {
+		resp.Header.Set(k, v)
+	}
+	return resp, nil
+}
+
+func (c *stubClient) payloads(target string) []map[string]any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.requests["DynamoDB_20120810."+target]
+}
+
+func run(t *testing.T, client *stubClient, cfg Config, args ...string) (string, string, error) {
+	t.Helper()
+	if cfg.Models == nil {
+		cfg.Models = map[string]any{"orders": &order{}}
+	}
+	cfg.Open = func(sess session.Config) (*dynamorm.DB, error) {
+		require.Equal(t, "us-east-1", sess.Region)
+		sess.CredentialsProvider = credentials.NewStaticCredentialsProvider("test", "secret", "token")
+		sess.AWSConfigOptions = []func(*config.LoadOptions) error{
+			config.WithHTTPClient(client),
+			config.WithRetryer(func() aws.Retryer { return aws.NopRetryer{} }),
+		}
+		db, err := dynamorm.New(sess)
+		if err != nil {
+			return nil, err
+		}
+		return db.(*dynamorm.DB), nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := Run(context.Background(), cfg, append([]string{"-region", "us-east-1"}, args...), &stdout, &stderr)
+	return stdout.String(), stderr.String(), err
+}
+
+func TestRunRejectsUnknownCommandsAndNames(t *testing.T) {
+	client := newStubClient(nil)
+
+	_, stderr, err := run(t, client, Config{}, "migrate")
+	require.ErrorIs(t, err, ErrUsage)
+	require.Contains(t, stderr, "create-table")
+	require.Contains(t, stderr, "models: orders")
+
+	_, _, err = run(t, client, Config{}, "describe", "users")
+	require.ErrorIs(t, err, ErrUsage)
+
+	_, _, err = run(t, client, Config{}, "export")
+	require.ErrorIs(t, err, ErrUsage)
+	require.Empty(t, client.requests)
+}
+
+func TestDiffReportsMissingIndexes(t *testing.T) {
+	client := newStubClient(map[string]string{
+		"DescribeTable": `{"Table":{"TableName":"orders","TableStatus":"ACTIVE"}}`,
+	})
+
+	stdout, _, err := run(t, client, Config{}, "diff", "-exit-code")
+	require.ErrorIs(t, err, ErrDrift)
+	require.Equal(t, "orders (orders):\n  + index gsi-status\n", stdout)
+	require.Empty(t, client.payloads("UpdateTable"))
+
+	stdout, _, err = run(t, client, Config{}, "diff")
+	require.NoError(t, err)
+	require.Contains(t, stdout, "+ index gsi-status")
+}
+
+func TestDiffReportsMissingTable(t *testing.T) {
+	client := newStubClient(nil)
+	client.responses["DynamoDB_20120810.DescribeTable"] = stubResponse{
+		status:  http.StatusBadRequest,
+		body:    `{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"Requested resource not found"}`,
+		headers: map[string]string{"x-amzn-errortype": "ResourceNotFoundException"},
+	}
+
+	stdout, _, err := run(t, client, Config{}, "diff", "-exit-code", "orders")
+	require.ErrorIs(t, err, ErrDrift)
+	require.Equal(t, "orders (orders): table does not exist\n", stdout)
+}
+
+func TestExportAndImport(t *testing.T) {
+	client := newStubClient(map[string]string{
+		"Scan":           `{"Items":[{"id":{"S":"o1"},"status":{"S":"open"}}],"Count":1,"ScannedCount":1}`,
+		"BatchWriteItem": `{"UnprocessedItems":{}}`,
+	})
+
+	stdout, stderr, err := run(t, client, Config{}, "export", "orders")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":"o1","status":"open"}`, strings.TrimSpace(stdout))
+	require.Contains(t, stderr, "exported 1 items")
+
+	path := filepath.Join(t.TempDir(), "orders.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(stdout+"not json\n"), 0o600))
+
+	stdout, stderr, err = run(t, client, Config{}, "import", "-skip-invalid", "-i", path, "orders")
+	require.NoError(t, err)
+	require.Equal(t, "orders: imported 1 items from 2 lines, 1 invalid\n", stdout)
+	require.Contains(t, stderr, "import line 2")
+	require.Len(t, client.payloads("BatchWriteItem"), 1)
+}
+
+func TestSeedRunsRegisteredSeedsInOrder(t *testing.T) {
+	client := newStubClient(nil)
+	var ran []string
+	cfg := Config{Seeds: map[string]Seed{
+		"b": func(ctx context.Context, db core.ExtendedDB) error {
+			ran = append(ran, "b")
+			return db.Model(&order{ID: "o1", Status: "open"}).Create()
+		},
+		"a": func(context.Context, core.ExtendedDB) error {
+			ran = append(ran, "a")
+			return nil
+		},
+	}}
+
+	stdout, _, err := run(t, client, cfg, "seed")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, ran)
+	require.Equal(t, "seed a: done\nseed b: done\n", stdout)
+	require.Len(t, client.payloads("PutItem"), 1)
+
+	_, _, err = run(t, client, cfg, "seed", "c")
+	require.ErrorIs(t, err, ErrUsage)
+}
+
+func TestDescribePrintsKeysAndIndexes(t *testing.T) {
+	client := newStubClient(map[string]string{
+		"DescribeTable": `{"Table":{"TableName":"orders","TableStatus":"ACTIVE","ItemCount":3,"TableSizeBytes":120,
+			"KeySchema":[{"AttributeName":"id","KeyType":"HASH"}],
+			"BillingModeSummary":{"BillingMode":"PAY_PER_REQUEST"},
+			"GlobalSecondaryIndexes":[{"IndexName":"gsi-status","IndexStatus":"ACTIVE",
+				"KeySchema":[{"AttributeName":"status","KeyType":"HASH"}],"Projection":{"ProjectionType":"KEYS_ONLY"}}]}}`,
+	})
+
+	stdout, _, err := run(t, client, Config{}, "describe")
+	require.NoError(t, err)
+	require.Equal(t, "orders: table orders (ACTIVE)\n"+
+		"  keys:    id (HASH)\n"+
+		"  billing: PAY_PER_REQUEST\n"+
+		"  items:   3 (120 bytes)\n"+
+		"  gsi gsi-status: status (HASH), KEYS_ONLY, ACTIVE\n", stdout)
+}
@@ -0,0 +1,334 @@
+package cli
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm"
+	"github.com/pay-theory/dynamorm/pkg/backfill"
+)
+
+func (r *runner) createTable(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	models, err := r.models(fs.Args())
+	if err != nil {
+		return err
+	}
+	db, err := r.open()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range models {
+		table, err := db.TableName(m.model)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+		if err := db.EnsureTable(m.model); err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+		fmt.Fprintf(r.stdout, "%s: table %s is ready\n", m.name, table)
+	}
+	return nil
+}
+
+func (r *runner) diff(fs *flag.FlagSet, args []string) error {
+	exitCode := fs.Bool("exit-code", false, "exit with status 1 when a table is missing or its indexes differ")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	models, err := r.models(fs.Args())
+	if err != nil {
+		return err
+	}
+	db, err := r.open()
+	if err != nil {
+		return err
+	}
+
+	drift := false
+	for _, m := range models {
+		table, err := db.TableName(m.model)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+		plan, err := db.DiffIndexes(m.model)
+		var notFound *types.ResourceNotFoundException
+		switch {
+		case errors.As(err, &notFound):
+			fmt.Fprintf(r.stdout, "%s (%s): table does not exist\n", m.name, table)
+			drift = true
+			continue
+		case err != nil:
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+
+		if len(plan.ToCreate) == 0 && len(plan.ToDelete) == 0 {
+			fmt.Fprintf(r.stdout, "%s (%s): up to date\n", m.name, table)
+			continue
+		}
+		drift = true
+		fmt.Fprintf(r.stdout, "%s (%s):\n", m.name, table)
+		for _, index := range plan.ToCreate {
+			fmt.Fprintf(r.stdout, "  + index %s\n", aws.ToString(index.IndexName))
+		}
+		for _, name := range plan.ToDelete {
+			fmt.Fprintf(r.stdout, "  - index %s\n", name)
+		}
+	}
+
+	if drift && *exitCode {
+		return ErrDrift
+	}
+	return nil
+}
+
+func (r *runner) backfill(fs *flag.FlagSet, args []string) error {
+	var opts backfill.Options
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "derive values without writing them")
+	fs.Float64Var(&opts.WritesPerSecond, "rate", 0, "maximum writes per second; 0 is unlimited")
+	fs.IntVar(&opts.BatchSize, "batch-size", backfill.DefaultBatchSize, "items scanned per page")
+	checkpoint := fs.String("checkpoint", "", "file to save the scan position in, so an interrupted run resumes")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("%w: backfill takes one name", ErrUsage)
+	}
+	name := fs.Arg(0)
+	run, ok := r.cfg.Backfills[name]
+	if !ok {
+		return fmt.Errorf("%w: unknown backfill %q", ErrUsage, name)
+	}
+	db, err := r.open()
+	if err != nil {
+		return err
+	}
+
+	if *checkpoint != "" {
+		opts.Checkpoint = backfill.FileCheckpoint(*checkpoint)
+	}
+	opts.Progress = func(report backfill.Report) {
+		fmt.Fprintf(r.stderr, "%s: %d pages, %d scanned, %d derived, %d written\n",
+			name, report.Pages, report.Scanned, report.Derived, report.Written)
+	}
+
+	report, err := run(r.ctx, db, opts)
+	if report != nil {
+		status := "incomplete"
+		if report.Done {
+			status = "done"
+		}
+		fmt.Fprintf(r.stdout, "%s: %s: %d scanned, %d derived, %d written, %d skipped in %s\n",
+			name, status, report.Scanned, report.Derived, report.Written, report.Skipped, report.Elapsed.Round(time.Millisecond))
+	}
+	return err
+}
+
+func (r *runner) export(fs *flag.FlagSet, args []string) error {
+	format := fs.String("format", string(dynamorm.ExportJSONL), "output format: jsonl or csv")
+	output := fs.String("o", "", "file to write; standard output by default")
+	fields := fs.String("fields", "", "comma-separated fields to export; all by default")
+	parallelism := fs.Int("parallelism", 0, "scan segments to read at once")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	m, err := r.oneModel(fs)
+	if err != nil {
+		return err
+	}
+	db, err := r.open()
+	if err != nil {
+		return err
+	}
+
+	opts := dynamorm.ExportOptions{
+		Format:      dynamorm.ExportFormat(*format),
+		Parallelism: *parallelism,
+	}
+	if *fields != "" {
+		opts.Fields = strings.Split(*fields, ",")
+	}
+
+	w := r.stdout
+	var file *os.File
+	if *output != "" {
+		file, err = os.Create(*output)
+		if err != nil {
+			return err
+		}
+		w = file
+	}
+	buffered := bufio.NewWriter(w)
+
+	report, err := db.Export(m.model, buffered, opts)
+	if flushErr := buffered.Flush(); err == nil {
+		err = flushErr
+	}
+	if file != nil {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if report != nil {
+		fmt.Fprintf(r.stderr, "%s: exported %d items (%d scanned, %d pages)\n", m.name, report.Items, report.Scanned, report.Pages)
+	}
+	return err
+}
+
+func (r *runner) importItems(fs *flag.FlagSet, args []string) error {
+	var opts dynamorm.ImportOptions
+	format := fs.String("format", string(dynamorm.ImportJSONL), "input format: jsonl, dynamodb-json, or s3-export")
+	input := fs.String("i", "", "file to read, decompressed when it ends in .gz; standard input by default")
+	fs.Float64Var(&opts.ItemsPerSecond, "rate", 0, "maximum items written per second; 0 is unlimited")
+	fs.IntVar(&opts.BatchSize, "batch-size", 25, "items per BatchWriteItem call")
+	fs.BoolVar(&opts.SkipInvalid, "skip-invalid", false, "report invalid lines and continue instead of stopping")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	m, err := r.oneModel(fs)
+	if err != nil {
+		return err
+	}
+	opts.Format = dynamorm.ImportFormat(*format)
+
+	var in io.Reader = os.Stdin
+	if *input != "" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+		if strings.HasSuffix(*input, ".gz") {
+			gz, err := gzip.NewReader(file)
+			if err != nil {
+				return fmt.Errorf("%s: %w", *input, err)
+			}
+			defer gz.Close()
+			in = gz
+		}
+	}
+	db, err := r.open()
+	if err != nil {
+		return err
+	}
+
+	report, err := db.Import(m.model, in, opts)
+	if report != nil {
+		for _, invalid := range report.Invalid {
+			fmt.Fprintln(r.stderr, invalid)
+		}
+		fmt.Fprintf(r.stdout, "%s: imported %d items from %d lines, %d invalid\n", m.name, report.Items, report.Lines, len(report.Invalid))
+	}
+	return err
+}
+
+func (r *runner) seed(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	names := fs.Args()
+	if len(names) == 0 {
+		names = sortedKeys(r.cfg.Seeds)
+		if len(names) == 0 {
+			return fmt.Errorf("%w: no seeds are registered", ErrUsage)
+		}
+	}
+	for _, name := range names {
+		if _, ok := r.cfg.Seeds[name]; !ok {
+			return fmt.Errorf("%w: unknown seed %q", ErrUsage, name)
+		}
+	}
+	db, err := r.open()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := r.cfg.Seeds[name](r.ctx, db); err != nil {
+			return fmt.Errorf("seed %s: %w", name, err)
+		}
+		fmt.Fprintf(r.stdout, "seed %s: done\n", name)
+	}
+	return nil
+}
+
+func (r *runner) describe(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return usageError(err)
+	}
+	models, err := r.models(fs.Args())
+	if err != nil {
+		return err
+	}
+	db, err := r.open()
+	if err != nil {
+		return err
+	}
+
+	for i, m := range models {
+		described, err := db.DescribeTable(m.model)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.name, err)
+		}
+		table, ok := described.(*types.TableDescription)
+		if !ok || table == nil {
+			return fmt.Errorf("%s: unexpected table description %T", m.name, described)
+		}
+		if i > 0 {
+			fmt.Fprintln(r.stdout)
+		}
+		r.printTable(m.name, table)
+	}
+	return nil
+}
+
+func (r *runner) printTable(name string, table *types.TableDescription) {
+	w := r.stdout
+	fmt.Fprintf(w, "%s: table %s (%s)\n", name, aws.ToString(table.TableName), table.TableStatus)
+	fmt.Fprintf(w, "  keys:    %s\n", formatKeys(table.KeySchema))
+	billing := types.BillingModeProvisioned
+	if table.BillingModeSummary != nil {
+		billing = table.BillingModeSummary.BillingMode
+	}
+	fmt.Fprintf(w, "  billing: %s\n", billing)
+	fmt.Fprintf(w, "  items:   %d (%d bytes)\n", aws.ToInt64(table.ItemCount), aws.ToInt64(table.TableSizeBytes))
+	for _, gsi := range table.GlobalSecondaryIndexes {
+		fmt.Fprintf(w, "  gsi %s: %s, %s, %s\n", aws.ToString(gsi.IndexName), formatKeys(gsi.KeySchema), formatProjection(gsi.Projection), gsi.IndexStatus)
+	}
+	for _, lsi := range table.LocalSecondaryIndexes {
+		fmt.Fprintf(w, "  lsi %s: %s, %s\n", aws.ToString(lsi.IndexName), formatKeys(lsi.KeySchema), formatProjection(lsi.Projection))
+	}
+}
+
+func formatKeys(keys []types.KeySchemaElement) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s (%s)", aws.ToString(key.AttributeName), key.KeyType))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatProjection(projection *types.Projection) string {
+	if projection == nil {
+		return string(types.ProjectionTypeAll)
+	}
+	if projection.ProjectionType == types.ProjectionTypeInclude {
+		return fmt.Sprintf("%s (%s)", projection.ProjectionType, strings.Join(projection.NonKeyAttributes, ", "))
+	}
+	return string(projection.ProjectionType)
+}
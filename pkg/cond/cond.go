@@ -0,0 +1,178 @@
+// Package cond builds DynamoDB filter conditions as values, so boolean trees with nested
+// AND/OR groups can be assembled programmatically instead of through nested FilterGroup
+// closures. Each helper produces an Expr for one comparison or function:
+//
+//	q.FilterFunc(func(b *cond.Builder) {
+//		b.And(cond.Eq("Status", "active"))
+//		b.And(cond.Or(
+//			cond.And(cond.Gte("Total", 100), cond.BeginsWith("Region", "us-")),
+//			cond.Exists("PriorityFlag"),
+//		))
+//	})
+//
+// Conditions are pushed down to DynamoDB as a FilterExpression, so only the comparisons
+// and functions DynamoDB evaluates are offered.
+package cond
+
+// Logical operators joining conditions.
+const (
+	JoinAnd = "AND"
+	JoinOr  = "OR"
+)
+
+// Expr is a single condition or a group of conditions. The zero Expr is empty and is
+// ignored wherever it is used.
+type Expr struct {
+	value    any
+	field    string
+	op       string
+	join     string
+	children []Expr
+}
+
+func compare(field, op string, value any) Expr {
+	return Expr{field: field, op: op, value: value}
+}
+
+// Eq matches items whose field equals value.
+func Eq(field string, value any) Expr { return compare(field, "=", value) }
+
+// Ne matches items whose field does not equal value.
+func Ne(field string, value any) Expr { return compare(field, "<>", value) }
+
+// Lt matches items whose field is less than value.
+func Lt(field string, value any) Expr { return compare(field, "<", value) }
+
+// Lte matches items whose field is less than or equal to value.
+func Lte(field string, value any) Expr { return compare(field, "<=", value) }
+
+// Gt matches items whose field is greater than value.
+func Gt(field string, value any) Expr { return compare(field, ">", value) }
+
+// Gte matches items whose field is greater than or equal to value.
+func Gte(field string, value any) Expr { return compare(field, ">=", value) }
+
+// Between matches items whose field is between low and high, inclusive.
+func Between(field string, low, high any) Expr {
+	return compare(field, "BETWEEN", []any{low, high})
+}
+
+// In matches items whose field equals one of values. DynamoDB accepts at most 100 values.
+func In(field string, values ...any) Expr { return compare(field, "IN", values) }
+
+// BeginsWith matches items whose string field starts with prefix.
+func BeginsWith(field, prefix string) Expr { return compare(field, "BEGINS_WITH", prefix) }
+
+// Contains matches items whose string field contains value as a substring, or whose set
+// or list field contains value as an element.
+func Contains(field string, value any) Expr { return compare(field, "CONTAINS", value) }
+
+// Exists matches items that have field.
+func Exists(field string) Expr { return compare(field, "ATTRIBUTE_EXISTS", nil) }
+
+// NotExists matches items that do not have field.
+func NotExists(field string) Expr { return compare(field, "ATTRIBUTE_NOT_EXISTS", nil) }
+
+// And groups exprs so that all of them must match.
+func And(exprs ...Expr) Expr { return group(JoinAnd, exprs) }
+
+// Or groups exprs so that at least one of them must match.
+func Or(exprs ...Expr) Expr { return group(JoinOr, exprs) }
+
+func group(join string, exprs []Expr) Expr {
+	children := make([]Expr, 0, len(exprs))
+	for _, e := range exprs {
+		if !e.IsEmpty() {
+			children = append(children, e)
+		}
+	}
+	if len(children) == 1 {
+		return children[0]
+	}
+	return Expr{join: join, children: children}
+}
+
+// IsEmpty reports whether e holds no conditions.
+func (e Expr) IsEmpty() bool {
+	return e.op == "" && len(e.children) == 0
+}
+
+// Target receives the conditions of a Builder. Query implementations adapt it to their
+// filter methods: join is JoinAnd or JoinOr and says how the condition or group combines
+// with the conditions before it.
+type Target interface {
+	Condition(join, field, op string, value any)
+	Group(join string, build func(Target))
+}
+
+// Builder accumulates top-level conditions for a FilterFunc callback.
+type Builder struct {
+	entries []entry
+}
+
+type entry struct {
+	join string
+	expr Expr
+}
+
+// And adds exprs, each combined with the conditions before it using AND.
+func (b *Builder) And(exprs ...Expr) *Builder {
+	return b.add(JoinAnd, exprs)
+}
+
+// Or adds exprs, each combined with the conditions before it using OR. As in DynamoDB,
+// AND binds tighter than OR; wrap conditions in And or Or to group them explicitly.
+func (b *Builder) Or(exprs ...Expr) *Builder {
+	return b.add(JoinOr, exprs)
+}
+
+func (b *Builder) add(join string, exprs []Expr) *Builder {
+	for _, e := range exprs {
+		if !e.IsEmpty() {
+			b.entries = append(b.entries, entry{join: join, expr: e})
+		}
+	}
+	return b
+}
+
+// IsEmpty reports whether no conditions have been added.
+func (b *Builder) IsEmpty() bool {
+	return b.Len() == 0
+}
+
+// Len returns the number of top-level conditions and groups added.
+func (b *Builder) Len() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.entries)
+}
+
+// Apply sends the accumulated conditions to t in order. The first is sent with JoinAnd,
+// since Or only relates a condition to the ones added before it in the same Builder.
+func (b *Builder) Apply(t Target) {
+	if b == nil {
+		return
+	}
+	for i, e := range b.entries {
+		join := e.join
+		if i == 0 {
+			join = JoinAnd
+		}
+		e.expr.apply(t, join)
+	}
+}
+
+func (e Expr) apply(t Target, join string) {
+	if len(e.children) == 0 {
+		if e.op != "" {
+			t.Condition(join, e.field, e.op, e.value)
+		}
+		return
+	}
+	t.Group(join, func(sub Target) {
+		for _, child := range e.children {
+			child.apply(sub, e.join)
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package cond
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// printTarget renders conditions the way the filter builder joins them.
+type printTarget struct {
+	parts *[]string
+}
+
+func (t printTarget) add(join, s string) {
+	if len(*t.parts) > 0 {
+		*t.parts = append(*t.parts, join)
+	}
+	*t.parts = append(*t.parts, s)
+}
+
+func (t printTarget) Condition(join, field, op string, value any) {
+	t.add(join, fmt.Sprintf("%s %s %v", field, op, value))
+}
+
+func (t printTarget) Group(join string, build func(Target)) {
+	var parts []string
+	build(printTarget{parts: &parts})
+	t.add(join, "("+strings.Join(parts, " ")+")")
+}
+
+func render(b *Builder) string {
+	var parts []string
+	b.Apply(printTarget{parts: &parts})
+	return strings.Join(parts, " ")
+}
+
+func TestBuilderAppliesNestedGroups(t *testing.T) {
+	b := new(Builder)
+	b.And(Eq("Status", "active")).
+		And(Or(And(Gt("Total", 10), Lte("Total", 20)), NotExists("Closed"))).
+		Or(Between("Age", 1, 2))
+
+	require.Equal(t,
+		"Status = active AND ((Total > 10 AND Total <= 20) OR Closed ATTRIBUTE_NOT_EXISTS <nil>) OR Age BETWEEN [1 2]",
+		render(b))
+}
+
+func TestBuilderSendsFirstConditionWithAnd(t *testing.T) {
+	var joins []string
+	b := new(Builder).Or(Eq("A", 1), Eq("B", 2))
+	b.Apply(joinTarget{joins: &joins})
+	require.Equal(t, []string{JoinAnd, JoinOr}, joins)
+}
+
+type joinTarget struct {
+	joins *[]string
+}
+
+func (t joinTarget) Condition(join, _, _ string, _ any) { *t.joins = append(*t.joins, join) }
+
+func (t joinTarget) Group(join string, build func(Target)) {
+	*t.joins = append(*t.joins, join)
+	build(t)
+}
+
+func TestGroupsDropEmptyExprs(t *testing.T) {
+	require.True(t, And().IsEmpty())
+	require.True(t, Or(Expr{}, And()).IsEmpty())
+	require.Equal(t, Eq("A", 1), Or(Expr{}, Eq("A", 1)))
+
+	b := new(Builder)
+	require.True(t, b.And(Expr{}, Or()).IsEmpty())
+	require.Empty(t, render(b))
+
+	var nilBuilder *Builder
+	require.True(t, nilBuilder.IsEmpty())
+	nilBuilder.Apply(printTarget{parts: new([]string)})
+}
+
+func TestHelpersUseFilterOperators(t *testing.T) {
+	cases := map[string]Expr{
+		"A = 1":                    Eq("A", 1),
+		"A <> 1":                   Ne("A", 1),
+		"A < 1":                    Lt("A", 1),
+		"A >= 1":                   Gte("A", 1),
+		"A IN [1 2]":               In("A", 1, 2),
+		"A BEGINS_WITH x":          BeginsWith("A", "x"),
+		"A CONTAINS x":             Contains("A", "x"),
+		"A ATTRIBUTE_EXISTS <nil>": Exists("A"),
+	}
+	for want, expr := range cases {
+		require.Equal(t, want, render(new(Builder).And(expr)))
+	}
+}
@@ -0,0 +1,206 @@
+// Package contention tracks conditional-check failures and transaction conflicts per
+// table and partition key so contended items (global counters, shared aggregates) can
+// be spotted before they become an outage.
+package contention
+
+import (
+	"encoding/base64"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Kind classifies a contention event.
+type Kind string
+
+const (
+	// KindConditionFailed is a write rejected by its condition expression (including
+	// optimistic-lock version checks).
+	KindConditionFailed Kind = "condition_failed"
+	// KindTransactionConflict is a transaction canceled because another request was
+	// modifying the same item.
+	KindTransactionConflict Kind = "transaction_conflict"
+)
+
+const (
+	// DefaultMaxKeys bounds the number of distinct table/key entries tracked. Events for
+	// new keys beyond the bound are counted under OverflowKey.
+	DefaultMaxKeys = 1000
+
+	// OverflowKey is the KeyPrefix used once MaxKeys distinct keys are tracked.
+	OverflowKey = "*"
+)
+
+// Event describes one observed conflict. It is passed to the hook set with SetHook.
+type Event struct {
+	Time      time.Time
+	Table     string
+	KeyPrefix string
+	Operation string
+	Kind      Kind
+}
+
+// HotKey aggregates the conflicts observed for one table and key prefix.
+type HotKey struct {
+	LastSeen             time.Time
+	Table                string
+	KeyPrefix            string
+	ConditionFailures    int64
+	TransactionConflicts int64
+}
+
+// Total returns the number of conflicts of any kind.
+func (h HotKey) Total() int64 {
+	return h.ConditionFailures + h.TransactionConflicts
+}
+
+type entryKey struct {
+	table  string
+	prefix string
+}
+
+// Tracker aggregates contention events. It is safe for concurrent use.
+type Tracker struct {
+	now       func() time.Time
+	hook      func(Event)
+	entries   map[entryKey]*HotKey
+	prefixLen int
+	maxKeys   int
+	mu        sync.Mutex
+}
+
+// NewTracker creates a tracker that groups by the full partition key value.
+func NewTracker() *Tracker {
+	return &Tracker{
+		now:     time.Now,
+		entries: make(map[entryKey]*HotKey),
+		maxKeys: DefaultMaxKeys,
+	}
+}
+
+// SetHook registers a function called for every event, e.g. to emit a metric. The
+// hook runs synchronously on the request path and must not block.
+func (t *Tracker) SetHook(hook func(Event)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hook = hook
+}
+
+// SetPrefixLength groups keys by their first n characters, so high-cardinality keys
+// such as "counter#2024-06-01" and "counter#2024-06-02" aggregate together. Zero (the
+// default) uses the full value.
+func (t *Tracker) SetPrefixLength(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n < 0 {
+		n = 0
+	}
+	t.prefixLen = n
+}
+
+// SetMaxKeys changes the bound on distinct tracked keys.
+func (t *Tracker) SetMaxKeys(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n <= 0 {
+		n = DefaultMaxKeys
+	}
+	t.maxKeys = n
+}
+
+// Record counts one event for the item in table whose partition key is partitionKey.
+// A nil partitionKey is recorded with an empty prefix.
+func (t *Tracker) Record(kind Kind, operation, table string, partitionKey types.AttributeValue) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	prefix := KeyString(partitionKey)
+	if t.prefixLen > 0 && len(prefix) > t.prefixLen {
+		prefix = prefix[:t.prefixLen]
+	}
+
+	k := entryKey{table: table, prefix: prefix}
+	entry, ok := t.entries[k]
+	if !ok {
+		if len(t.entries) >= t.maxKeys {
+			k.prefix = OverflowKey
+			entry = t.entries[k]
+		}
+		if entry == nil {
+			entry = &HotKey{Table: table, KeyPrefix: k.prefix}
+			t.entries[k] = entry
+		}
+	}
+
+	now := t.now()
+	entry.LastSeen = now
+	switch kind {
+	case KindTransactionConflict:
+		entry.TransactionConflicts++
+	default:
+		entry.ConditionFailures++
+	}
+
+	hook := t.hook
+	event := Event{Time: now, Table: table, KeyPrefix: entry.KeyPrefix, Operation: operation, Kind: kind}
+	t.mu.Unlock()
+
+	if hook != nil {
+		hook(event)
+	}
+}
+
+// Report returns up to limit tracked keys ordered by total conflicts, most contended
+// first. A limit of zero or less returns every key.
+func (t *Tracker) Report(limit int) []HotKey {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	out := make([]HotKey, 0, len(t.entries))
+	for _, entry := range t.entries {
+		out = append(out, *entry)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Total() != out[j].Total() {
+			return out[i].Total() > out[j].Total()
+		}
+		if out[i].Table != out[j].Table {
+			return out[i].Table < out[j].Table
+		}
+		return out[i].KeyPrefix < out[j].KeyPrefix
+	})
+
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// Reset discards all tracked counts.
+func (t *Tracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = make(map[entryKey]*HotKey)
+}
+
+// KeyString renders a key attribute value for reporting. Binary keys are base64 encoded.
+func KeyString(av types.AttributeValue) string {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value
+	case *types.AttributeValueMemberN:
+		return v.Value
+	case *types.AttributeValueMemberB:
+		return base64.StdEncoding.EncodeToString(v.Value)
+	default:
+		return ""
+	}
+}
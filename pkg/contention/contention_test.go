@@ -0,0 +1,82 @@
+package contention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+func s(v string) types.AttributeValue { return &types.AttributeValueMemberS{Value: v} }
+
+func TestTrackerReportOrdersByTotal(t *testing.T) {
+	tr := NewTracker()
+	fixed := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return fixed }
+
+	tr.Record(KindConditionFailed, "UpdateItem", "counters", s("global"))
+	tr.Record(KindTransactionConflict, "TransactWriteItems", "counters", s("global"))
+	tr.Record(KindConditionFailed, "UpdateItem", "counters", s("global"))
+	tr.Record(KindConditionFailed, "PutItem", "orders", s("t1"))
+
+	report := tr.Report(0)
+	require.Len(t, report, 2)
+	require.Equal(t, HotKey{
+		LastSeen:             fixed,
+		Table:                "counters",
+		KeyPrefix:            "global",
+		ConditionFailures:    2,
+		TransactionConflicts: 1,
+	}, report[0])
+	require.Equal(t, int64(3), report[0].Total())
+	require.Equal(t, "orders", report[1].Table)
+
+	require.Len(t, tr.Report(1), 1)
+
+	tr.Reset()
+	require.Empty(t, tr.Report(0))
+}
+
+func TestTrackerPrefixLengthAndOverflow(t *testing.T) {
+	tr := NewTracker()
+	tr.SetPrefixLength(8)
+	tr.Record(KindConditionFailed, "UpdateItem", "counters", s("counter#2024-06-01"))
+	tr.Record(KindConditionFailed, "UpdateItem", "counters", s("counter#2024-06-02"))
+
+	report := tr.Report(0)
+	require.Len(t, report, 1)
+	require.Equal(t, "counter#", report[0].KeyPrefix)
+	require.Equal(t, int64(2), report[0].ConditionFailures)
+
+	tr.Reset()
+	tr.SetPrefixLength(0)
+	tr.SetMaxKeys(1)
+	tr.Record(KindConditionFailed, "PutItem", "orders", s("a"))
+	tr.Record(KindConditionFailed, "PutItem", "orders", s("b"))
+	tr.Record(KindConditionFailed, "PutItem", "orders", s("c"))
+
+	report = tr.Report(0)
+	require.Len(t, report, 2)
+	require.Equal(t, OverflowKey, report[0].KeyPrefix)
+	require.Equal(t, int64(2), report[0].ConditionFailures)
+}
+
+func TestTrackerHookAndKeyString(t *testing.T) {
+	tr := NewTracker()
+	var events []Event
+	tr.SetHook(func(e Event) { events = append(events, e) })
+
+	tr.Record(KindTransactionConflict, "TransactWriteItems", "orders", &types.AttributeValueMemberN{Value: "42"})
+	require.Len(t, events, 1)
+	require.Equal(t, KindTransactionConflict, events[0].Kind)
+	require.Equal(t, "42", events[0].KeyPrefix)
+	require.Equal(t, "TransactWriteItems", events[0].Operation)
+
+	require.Equal(t, "AQI=", KeyString(&types.AttributeValueMemberB{Value: []byte{1, 2}}))
+	require.Equal(t, "", KeyString(nil))
+
+	var nilTracker *Tracker
+	nilTracker.Record(KindConditionFailed, "PutItem", "orders", s("x"))
+	require.Nil(t, nilTracker.Report(0))
+}
@@ -0,0 +1,64 @@
+package core
+
+// BatchItemStatus is the outcome of writing one item in a batch.
+type BatchItemStatus string
+
+const (
+	// BatchItemSucceeded means the item was written.
+	BatchItemSucceeded BatchItemStatus = "succeeded"
+	// BatchItemFailed means the item was rejected, for example by a failed condition or
+	// a marshaling error, and retrying it unchanged will not help.
+	BatchItemFailed BatchItemStatus = "failed"
+	// BatchItemThrottled means the item was still unprocessed or throttled when the retry
+	// policy ran out, and can be retried later.
+	BatchItemThrottled BatchItemStatus = "throttled"
+)
+
+// BatchItemOutcome is the result for the item at Index in the input slice.
+type BatchItemOutcome struct {
+	Err    error
+	Status BatchItemStatus
+	Index  int
+}
+
+// BatchCreateResult reports the outcome of every item passed to BatchCreateWithOptions.
+type BatchCreateResult struct {
+	Items     []BatchItemOutcome
+	Succeeded int
+	Failed    int
+	Throttled int
+}
+
+// Unwritten returns the input indexes of the items that were not written, in order, so
+// callers can resume with just those items.
+func (r *BatchCreateResult) Unwritten() []int {
+	if r == nil {
+		return nil
+	}
+	var indexes []int
+	for _, outcome := range r.Items {
+		if outcome.Status != BatchItemSucceeded {
+			indexes = append(indexes, outcome.Index)
+		}
+	}
+	return indexes
+}
+
+// BatchCreateOptions tune BatchCreateWithOptions.
+type BatchCreateOptions struct {
+	// RetryPolicy bounds retries of unprocessed and throttled items.
+	RetryPolicy *RetryPolicy
+	// Feedback, when set, shrinks each BatchWriteItem call below ChunkSize while the
+	// table is throttled, and is told how many items each call left unprocessed.
+	Feedback ThrottleFeedback
+	// ChunkSize is the number of items per BatchWriteItem call (at most 25).
+	ChunkSize int
+}
+
+// DefaultBatchCreateOptions returns the options used when none are given.
+func DefaultBatchCreateOptions() *BatchCreateOptions {
+	return &BatchCreateOptions{
+		RetryPolicy: DefaultRetryPolicy(),
+		ChunkSize:   25,
+	}
+}
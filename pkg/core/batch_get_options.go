@@ -6,15 +6,23 @@ type BatchProgressCallback func(retrieved, total int)
 // BatchChunkErrorHandler can intercept per-chunk failures. Return nil to swallow the error and continue.
 type BatchChunkErrorHandler func(chunk []any, err error) error
 
+// BatchMissingKeysHandler receives the keys, as passed to the batch get and in their order,
+// that matched no item.
+type BatchMissingKeysHandler func(missing []any)
+
 // BatchGetOptions tune the behavior of BatchGet operations.
 type BatchGetOptions struct {
 	RetryPolicy      *RetryPolicy
 	ProgressCallback BatchProgressCallback
 	OnChunkError     BatchChunkErrorHandler
-	ChunkSize        int
-	MaxConcurrency   int
-	ConsistentRead   bool
-	Parallel         bool
+	// OnMissing, when set, is called once the batch completes with the keys that have no
+	// item. Keys in chunks whose errors OnChunkError swallowed are not reported, since
+	// their items are unknown. It is not called when every key matched.
+	OnMissing      BatchMissingKeysHandler
+	ChunkSize      int
+	MaxConcurrency int
+	ConsistentRead bool
+	Parallel       bool
 }
 
 // DefaultBatchGetOptions returns a sensible baseline configuration.
@@ -53,6 +61,7 @@ type BatchGetBuilder interface {
 	Select(fields ...string) BatchGetBuilder
 	OnProgress(callback BatchProgressCallback) BatchGetBuilder
 	OnError(handler BatchChunkErrorHandler) BatchGetBuilder
+	OnMissing(handler BatchMissingKeysHandler) BatchGetBuilder
 	Execute(dest any) error
 }
 
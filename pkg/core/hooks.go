@@ -0,0 +1,51 @@
+package core
+
+import "context"
+
+// Lifecycle hooks are optional interfaces a model can implement (usually on its pointer
+// receiver) to run code around writes issued through the query layer: Create,
+// CreateOrUpdate, BatchCreate, Update, UpdateBuilder().Execute, and Delete.
+//
+// A Before hook runs before the request is built, so changes it makes to the model are
+// written; returning an error aborts the write. An After hook runs only once the write
+// has succeeded; its error is returned to the caller, but the write is not undone.
+// Transactions do not invoke hooks.
+
+// BeforeCreateHook runs before Create, CreateOrUpdate, and each BatchCreate item.
+type BeforeCreateHook interface {
+	BeforeCreate(ctx context.Context) error
+}
+
+// AfterCreateHook runs after a successful Create, CreateOrUpdate, or BatchCreate.
+type AfterCreateHook interface {
+	AfterCreate(ctx context.Context) error
+}
+
+// BeforeUpdateHook runs before Update and UpdateBuilder().Execute.
+type BeforeUpdateHook interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// AfterUpdateHook runs after a successful Update or UpdateBuilder().Execute.
+type AfterUpdateHook interface {
+	AfterUpdate(ctx context.Context) error
+}
+
+// BeforeDeleteHook runs before Delete.
+type BeforeDeleteHook interface {
+	BeforeDelete(ctx context.Context) error
+}
+
+// AfterDeleteHook runs after a successful Delete.
+type AfterDeleteHook interface {
+	AfterDelete(ctx context.Context) error
+}
+
+// Validator is implemented by models, or by the types of their fields, that check
+// themselves before Create, CreateOrUpdate, each BatchCreate item, and Update, after
+// the Before hooks and the model's validation tags. A model's Validate may return an
+// *errors.ValidationError to report several fields; its failures are merged with those
+// of the tags.
+type Validator interface {
+	Validate(ctx context.Context) error
+}
@@ -3,11 +3,20 @@ package core
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"reflect"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
+	"github.com/pay-theory/dynamorm/pkg/assumerole"
+	"github.com/pay-theory/dynamorm/pkg/audit"
+	"github.com/pay-theory/dynamorm/pkg/cache"
+	"github.com/pay-theory/dynamorm/pkg/cond"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/leadingkeys"
 	pkgTypes "github.com/pay-theory/dynamorm/pkg/types"
 )
 
@@ -45,6 +54,10 @@ type ExtendedDB interface {
 	// callers to override how values are marshaled to and unmarshaled from DynamoDB.
 	RegisterTypeConverter(typ reflect.Type, converter pkgTypes.CustomConverter) error
 
+	// InvalidateModelCache drops cached metadata and marshalers for model's type and
+	// parses its metadata again.
+	InvalidateModelCache(model any) error
+
 	// CreateTable creates a DynamoDB table for the given model
 	// opts should be of type schema.TableOption
 	CreateTable(model any, opts ...any) error
@@ -69,12 +82,80 @@ type ExtendedDB interface {
 	// tx should be of type *transaction.Transaction
 	TransactionFunc(fn func(tx any) error) error
 
+	// AtomicTransaction runs fn with a Tx that queues its writes and commits them in a
+	// single TransactWriteItems call when fn returns nil
+	AtomicTransaction(fn func(tx *Tx) error) error
+
 	// Transact returns a fluent transaction builder for composing TransactWriteItems
 	Transact() TransactionBuilder
 
 	// TransactWrite executes the provided function within a transaction builder context
 	// and automatically commits the accumulated operations.
 	TransactWrite(ctx context.Context, fn func(TransactionBuilder) error) error
+
+	// WithItemSizeValidation returns a DB that rejects items over DynamoDB's 400KB limit
+	// before sending them
+	WithItemSizeValidation(enabled bool) ExtendedDB
+
+	// WithIndexSelection returns a DB whose queries without Index() do or do not choose
+	// a secondary index from their conditions
+	WithIndexSelection(enabled bool) ExtendedDB
+
+	// WithRequestTags returns a DB whose operations carry tags for cost attribution
+	WithRequestTags(tags map[string]string) ExtendedDB
+
+	// WithLeadingKeys returns a DB that checks every request against checker before sending it
+	WithLeadingKeys(checker leadingkeys.Checker) ExtendedDB
+
+	// WithItemCache returns a DB whose GetItem reads go through a read-through cache
+	// that its writes invalidate
+	WithItemCache(opts cache.Options) ExtendedDB
+
+	// WithStrictReads returns a DB whose reads report items missing dynamorm:"required"
+	// fields to onMissing, or fail with the error when onMissing is nil
+	WithStrictReads(onMissing func(ctx context.Context, missing *customerrors.MissingFieldsError) error) ExtendedDB
+
+	// WithAudit returns a DB whose updates and deletes commit an audit record of the
+	// attributes they change in the same transaction
+	WithAudit(opts audit.Options) ExtendedDB
+
+	// WithTenant returns a DB bound to ctx that confines reads and writes of models with a
+	// dynamorm:"tenant" field to the items of tenantID
+	WithTenant(ctx context.Context, tenantID string) ExtendedDB
+
+	// WithCredentialsProvider returns a DB whose requests are signed with provider
+	WithCredentialsProvider(provider aws.CredentialsProvider) ExtendedDB
+
+	// WithAssumedRole returns a DB whose requests are signed with temporary credentials
+	// of roleARN, shared with other DBs assuming the same role session
+	WithAssumedRole(roleARN, externalID string, opts ...assumerole.Option) ExtendedDB
+
+	// Use appends middleware to the chain every request passes through
+	Use(middleware ...Middleware)
+
+	// OnConsumedCapacity calls fn after every operation that reports consumed capacity
+	OnConsumedCapacity(fn func(ctx context.Context, op *Operation))
+
+	// OnShutdown registers a drain function that Shutdown runs
+	OnShutdown(name string, drain func(ctx context.Context) error)
+
+	// Shutdown stops accepting new operations, waits for in-flight ones, and runs the
+	// registered drain functions
+	Shutdown(ctx context.Context) error
+
+	// DebugHandler returns an http.Handler that reports registered models, indexes, and
+	// runtime statistics
+	DebugHandler(opts ...DebugOption) http.Handler
+
+	// QueryString parses a SQL-like query string into a query
+	QueryString(query string, args ...any) Query
+
+	// ItemCollection starts a read of every item stored under partition key value pk
+	ItemCollection(pk any) ItemCollectionQuery
+
+	// ConsistentSnapshot fills models, identified by their key fields, from one
+	// TransactGetItems call so they reflect a single point in time
+	ConsistentSnapshot(models ...any) error
 }
 
 // TransactionBuilder defines the fluent DSL for composing DynamoDB transactions
@@ -93,6 +174,9 @@ type TransactionBuilder interface {
 	ConditionCheck(model any, conditions ...TransactCondition) TransactionBuilder
 	// WithContext sets the context used for DynamoDB calls
 	WithContext(ctx context.Context) TransactionBuilder
+	// WithClientRequestToken makes the next Execute idempotent for DynamoDB's
+	// ten-minute window: replays with the same token do not apply the writes twice
+	WithClientRequestToken(token string) TransactionBuilder
 	// Execute commits the transaction using the currently configured context
 	Execute() error
 	// ExecuteWithContext commits the transaction with an explicit context override
@@ -129,11 +213,19 @@ type TransactCondition struct {
 type Query interface {
 	// Query construction
 	Where(field string, op string, value any) Query
+	// WhereBeginsWith adds a begins_with key condition on field, which must be the sort
+	// key of the table or of the index the query uses
+	WhereBeginsWith(field string, prefix string) Query
+	// WhereBetween adds a BETWEEN key condition on field, inclusive of both bounds, which
+	// must be the sort key of the table or of the index the query uses
+	WhereBetween(field string, lo, hi any) Query
 	Index(indexName string) Query
 	Filter(field string, op string, value any) Query
 	OrFilter(field string, op string, value any) Query
 	FilterGroup(func(Query)) Query
 	OrFilterGroup(func(Query)) Query
+	// FilterFunc adds the filter conditions built by fn, joined to existing filters with AND
+	FilterFunc(fn func(b *cond.Builder)) Query
 	// IfNotExists ensures the target item does not already exist before a write
 	IfNotExists() Query
 	// IfExists ensures the target item exists before executing a write
@@ -142,6 +234,12 @@ type Query interface {
 	WithCondition(field, operator string, value any) Query
 	// WithConditionExpression adds a raw condition expression with placeholder values
 	WithConditionExpression(expr string, values map[string]any) Query
+	// WithClientToken stores token in field on Create and, when the write fails
+	// ambiguously, reads the item back to confirm whether it already succeeded
+	WithClientToken(field, token string) Query
+	// Preload loads the named hasmany/belongsto relationships into the results of
+	// First and All
+	Preload(relations ...string) Query
 	OrderBy(field string, order string) Query
 	Limit(limit int) Query
 
@@ -151,10 +249,22 @@ type Query interface {
 	// Select specifies which fields to retrieve
 	Select(fields ...string) Query
 
+	// Profile retrieves only the fields included in the named serialization profile
+	// (fields tagged `dynamorm:"exclude:<profile>"` are left out)
+	Profile(name string) Query
+
 	// ConsistentRead enables strongly consistent reads for Query operations
 	// Note: This only works on main table queries, not GSI queries
 	ConsistentRead() Query
 
+	// Refresh makes Create, CreateOrUpdate, and Update write the stored item back into the
+	// model afterwards, picking up server-side changes such as incremented versions
+	Refresh() Query
+
+	// ReturnConsumedCapacity asks DynamoDB to report the capacity the operation consumes
+	// ("TOTAL", "INDEXES", or "NONE"); paginated results carry it as ConsumedCapacity
+	ReturnConsumedCapacity(mode string) Query
+
 	// WithRetry configures retry behavior for eventually consistent reads
 	// Useful for GSI queries where you need read-after-write consistency
 	WithRetry(maxRetries int, initialDelay time.Duration) Query
@@ -168,9 +278,18 @@ type Query interface {
 	// AllPaginated retrieves all matching items with pagination metadata
 	AllPaginated(dest any) (*PaginatedResult, error)
 
+	// Pages walks every page of results, following LastEvaluatedKey. dest (a pointer
+	// to a slice) holds the current page when fn runs; returning false stops early.
+	// Limit, when set, caps the total number of items across all pages.
+	Pages(dest any, fn func(page *PaginatedResult) bool) error
+
 	// Count returns the number of matching items
 	Count() (int64, error)
 
+	// CountWithResult counts matching items like Count and also reports how many items
+	// DynamoDB evaluated before applying the filter
+	CountWithResult() (*CountResult, error)
+
 	// Create creates a new item
 	Create() error
 
@@ -183,6 +302,17 @@ type Query interface {
 	// UpdateBuilder returns a builder for complex update operations
 	UpdateBuilder() UpdateBuilder
 
+	// AtomicIncrement adds delta to a numeric field with an ADD update expression and
+	// returns the new value; a missing field starts at zero
+	AtomicIncrement(field string, delta int64) (int64, error)
+
+	// AtomicDecrement subtracts delta from a numeric field and returns the new value
+	AtomicDecrement(field string, delta int64) (int64, error)
+
+	// UpdateWithOptimisticRetry applies mutate to the model and updates it, reloading the
+	// item and reapplying mutate when the version check fails, up to maxAttempts times
+	UpdateWithOptimisticRetry(maxAttempts int, mutate func(model any) error, fields ...string) error
+
 	// Delete deletes the matching items
 	Delete() error
 
@@ -195,6 +325,10 @@ type Query interface {
 	// ScanAllSegments performs parallel scan across all segments automatically
 	ScanAllSegments(dest any, totalSegments int32) error
 
+	// ScanAllSegmentsWithOptions performs a parallel scan with a bounded worker pool,
+	// per-segment rate limits, and progress callbacks.
+	ScanAllSegmentsWithOptions(dest any, totalSegments int32, opts *ScanOptions) error
+
 	// BatchGet retrieves multiple items by their primary keys.
 	// Keys may be primitives, structs matching the model schema, or core.KeyPair values.
 	BatchGet(keys []any, dest any) error
@@ -202,12 +336,24 @@ type Query interface {
 	// BatchGetWithOptions retrieves items with fine-grained control over chunking, retries, and callbacks.
 	BatchGetWithOptions(keys []any, dest any, opts *BatchGetOptions) error
 
+	// BatchGetOrdered retrieves items into a slice aligned with keys, leaving the zero
+	// value (nil for pointer elements) where a key has no item.
+	BatchGetOrdered(keys []any, dest any) error
+
+	// BatchGetMap retrieves items into a map keyed by the values in keys, omitting keys
+	// that have no item.
+	BatchGetMap(keys []any, dest any) error
+
 	// BatchGetBuilder returns a fluent builder for complex batch get workflows.
 	BatchGetBuilder() BatchGetBuilder
 
 	// BatchCreate creates multiple items
 	BatchCreate(items any) error
 
+	// BatchCreateWithOptions creates multiple items and reports the outcome of each one,
+	// returning *errors.BatchWriteError alongside the result when some were not written
+	BatchCreateWithOptions(items any, opts *BatchCreateOptions) (*BatchCreateResult, error)
+
 	// BatchDelete deletes multiple items by their primary keys
 	BatchDelete(keys []any) error
 
@@ -262,6 +408,16 @@ type UpdateBuilder interface {
 	// SetListElement sets a specific element in a list
 	SetListElement(field string, index int, value any) UpdateBuilder
 
+	// AppendToListWithLimit appends values only if the list will then hold at most maxLen
+	// elements; otherwise the update fails with ErrConditionFailed
+	AppendToListWithLimit(field string, values any, maxLen int) UpdateBuilder
+
+	// SetMapKey sets a single key of a map attribute without rewriting the whole map
+	SetMapKey(field string, key string, value any) UpdateBuilder
+
+	// RemoveMapKey removes a single key from a map attribute
+	RemoveMapKey(field string, key string) UpdateBuilder
+
 	// Condition adds a condition that must be met for the update to succeed
 	Condition(field string, operator string, value any) UpdateBuilder
 
@@ -292,14 +448,42 @@ type PaginatedResult struct {
 	Items            any
 	LastEvaluatedKey map[string]types.AttributeValue
 	NextCursor       string
+	// ConsumedCapacity is the capacity DynamoDB reported for the page, when requested.
+	ConsumedCapacity []types.ConsumedCapacity
 	Count            int
 	ScannedCount     int
 	HasMore          bool
 }
 
-// Tx represents a database transaction
+// CountResult reports a Select=COUNT read. Count is the number of items that matched the
+// filter; ScannedCount is the number DynamoDB read to find them.
+type CountResult struct {
+	Count        int64
+	ScannedCount int64
+}
+
+// FilterEfficiency returns Count/ScannedCount, the share of evaluated items that passed the
+// filter, or 1 when nothing was scanned.
+func (r *CountResult) FilterEfficiency() float64 {
+	if r == nil || r.ScannedCount == 0 {
+		return 1
+	}
+	return float64(r.Count) / float64(r.ScannedCount)
+}
+
+// Tx represents a database transaction. A Tx from AtomicTransaction queues writes on a
+// TransactionBuilder and commits them together as a single TransactWriteItems call when
+// the transaction function returns; a Tx without a builder, as used by Transaction,
+// applies each write immediately. Reads through Model are never isolated.
 type Tx struct {
-	db DB
+	db      DB
+	builder TransactionBuilder
+	pending int
+}
+
+// NewTx creates a transaction that queues its writes on builder
+func NewTx(db DB, builder TransactionBuilder) *Tx {
+	return &Tx{db: db, builder: builder}
 }
 
 // SetDB sets the database reference for the transaction
@@ -307,24 +491,81 @@ func (tx *Tx) SetDB(db DB) {
 	tx.db = db
 }
 
-// Model returns a new query builder for the given model within the transaction
+// Model returns a new query builder for the given model. Reads made through it are not
+// part of the transaction.
 func (tx *Tx) Model(model any) Query {
 	return tx.db.Model(model)
 }
 
-// Create creates a new item within the transaction
-func (tx *Tx) Create(model any) error {
-	return tx.db.Model(model).Create()
+// Create queues a put that fails if the item already exists
+func (tx *Tx) Create(model any, conditions ...TransactCondition) error {
+	if tx.builder == nil {
+		return tx.db.Model(model).Create()
+	}
+	tx.builder.Create(model, conditions...)
+	tx.pending++
+	return nil
+}
+
+// Put queues an unconditional put (upsert)
+func (tx *Tx) Put(model any, conditions ...TransactCondition) error {
+	if tx.builder == nil {
+		return tx.db.Model(model).CreateOrUpdate()
+	}
+	tx.builder.Put(model, conditions...)
+	tx.pending++
+	return nil
 }
 
-// Update updates an item within the transaction
+// Update queues an update of the given fields
 func (tx *Tx) Update(model any, fields ...string) error {
-	return tx.db.Model(model).Update(fields...)
+	return tx.UpdateWithConditions(model, fields)
+}
+
+// UpdateWithConditions queues an update of the given fields guarded by conditions
+func (tx *Tx) UpdateWithConditions(model any, fields []string, conditions ...TransactCondition) error {
+	if tx.builder == nil {
+		if len(conditions) > 0 {
+			return fmt.Errorf("conditional updates require a transactional Tx")
+		}
+		return tx.db.Model(model).Update(fields...)
+	}
+	tx.builder.Update(model, fields, conditions...)
+	tx.pending++
+	return nil
+}
+
+// Delete queues a delete of the item's primary key
+func (tx *Tx) Delete(model any, conditions ...TransactCondition) error {
+	if tx.builder == nil {
+		if len(conditions) > 0 {
+			return fmt.Errorf("conditional deletes require a transactional Tx")
+		}
+		return tx.db.Model(model).Delete()
+	}
+	tx.builder.Delete(model, conditions...)
+	tx.pending++
+	return nil
+}
+
+// ConditionCheck queues a check that must hold for the transaction to commit
+func (tx *Tx) ConditionCheck(model any, conditions ...TransactCondition) error {
+	if tx.builder == nil {
+		return fmt.Errorf("ConditionCheck requires a transactional Tx")
+	}
+	tx.builder.ConditionCheck(model, conditions...)
+	tx.pending++
+	return nil
 }
 
-// Delete deletes an item within the transaction
-func (tx *Tx) Delete(model any) error {
-	return tx.db.Model(model).Delete()
+// Commit executes the queued writes. DB.AtomicTransaction calls it after the transaction
+// function returns successfully; a Tx with nothing queued commits as a no-op.
+func (tx *Tx) Commit() error {
+	if tx.builder == nil || tx.pending == 0 {
+		return nil
+	}
+	tx.pending = 0
+	return tx.builder.Execute()
 }
 
 // Param represents a parameter for expressions
@@ -351,6 +592,7 @@ type CompiledQuery struct {
 	Select                    string
 	ConditionExpression       string
 	ReturnValues              string
+	ReturnConsumedCapacity    string
 	UpdateExpression          string
 	FilterExpression          string
 	IndexName                 string
@@ -388,3 +630,36 @@ type AttributeMetadata struct {
 	Type         string
 	DynamoDBName string
 }
+
+// ItemCollectionQuery reads every item that shares a partition key, across entity types
+type ItemCollectionQuery interface {
+	// Entity registers a model whose items are identified by a sort key beginning with prefix
+	Entity(model any, sortKeyPrefix string) ItemCollectionQuery
+	// EntityByAttribute registers a model whose items carry attribute set to value
+	EntityByAttribute(model any, attribute string, value string) ItemCollectionQuery
+	// ConsistentRead requests a strongly consistent read of the partition
+	ConsistentRead() ItemCollectionQuery
+	// All queries the whole partition and sorts items into their registered entities
+	All() (ItemCollection, error)
+}
+
+// ItemCollection holds the items of one partition grouped by entity type
+type ItemCollection interface {
+	// PartitionKey returns the partition key value the collection was read with
+	PartitionKey() any
+	// Load unmarshals the items of one entity into dest, a pointer to a slice of a registered model
+	Load(dest any) error
+	// Count returns how many items were read for the given model
+	Count(model any) int
+	// Unmatched returns the raw items that no registered entity claimed
+	Unmatched() []map[string]types.AttributeValue
+}
+
+// DebugOptions controls what DebugHandler reveals
+type DebugOptions struct {
+	// ExposeKeys reports contended partition key values as stored instead of hashed
+	ExposeKeys bool
+}
+
+// DebugOption configures DebugHandler
+type DebugOption func(*DebugOptions)
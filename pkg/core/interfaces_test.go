@@ -9,6 +9,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+
+	"github.com/pay-theory/dynamorm/pkg/cond"
 )
 
 func mustQuery(v any) Query {
@@ -171,6 +173,11 @@ func (m *MockQuery) OrFilterGroup(fn func(Query)) Query {
 	return mustQuery(args.Get(0))
 }
 
+func (m *MockQuery) FilterFunc(fn func(*cond.Builder)) Query {
+	args := m.Called(fn)
+	return mustQuery(args.Get(0))
+}
+
 func (m *MockQuery) IfNotExists() Query {
 	args := m.Called()
 	return mustQuery(args.Get(0))
@@ -191,6 +198,36 @@ func (m *MockQuery) WithConditionExpression(expr string, values map[string]any)
 	return mustQuery(args.Get(0))
 }
 
+func (m *MockQuery) Pages(dest any, fn func(page *PaginatedResult) bool) error {
+	args := m.Called(dest, fn)
+	return args.Error(0)
+}
+
+func (m *MockQuery) WithClientToken(field, token string) Query {
+	args := m.Called(field, token)
+	return mustQuery(args.Get(0))
+}
+
+func (m *MockQuery) WhereBeginsWith(field string, prefix string) Query {
+	args := m.Called(field, prefix)
+	return mustQuery(args.Get(0))
+}
+
+func (m *MockQuery) WhereBetween(field string, lo, hi any) Query {
+	args := m.Called(field, lo, hi)
+	return mustQuery(args.Get(0))
+}
+
+func (m *MockQuery) Profile(name string) Query {
+	args := m.Called(name)
+	return mustQuery(args.Get(0))
+}
+
+func (m *MockQuery) Preload(relations ...string) Query {
+	args := m.Called(relations)
+	return mustQuery(args.Get(0))
+}
+
 func (m *MockQuery) OrderBy(field string, order string) Query {
 	args := m.Called(field, order)
 	return mustQuery(args.Get(0))
@@ -216,6 +253,26 @@ func (m *MockQuery) ConsistentRead() Query {
 	return mustQuery(args.Get(0))
 }
 
+func (m *MockQuery) Refresh() Query {
+	args := m.Called()
+	return mustQuery(args.Get(0))
+}
+
+func (m *MockQuery) ReturnConsumedCapacity(mode string) Query {
+	args := m.Called(mode)
+	return mustQuery(args.Get(0))
+}
+
+func (m *MockQuery) AtomicIncrement(field string, delta int64) (int64, error) {
+	args := m.Called(field, delta)
+	return mustInt64(args.Get(0)), args.Error(1)
+}
+
+func (m *MockQuery) AtomicDecrement(field string, delta int64) (int64, error) {
+	args := m.Called(field, delta)
+	return mustInt64(args.Get(0)), args.Error(1)
+}
+
 func (m *MockQuery) WithRetry(maxRetries int, initialDelay time.Duration) Query {
 	args := m.Called(maxRetries, initialDelay)
 	return mustQuery(args.Get(0))
@@ -241,6 +298,12 @@ func (m *MockQuery) Count() (int64, error) {
 	return mustInt64(args.Get(0)), args.Error(1)
 }
 
+func (m *MockQuery) CountWithResult() (*CountResult, error) {
+	args := m.Called()
+	result, _ := args.Get(0).(*CountResult)
+	return result, args.Error(1)
+}
+
 func (m *MockQuery) Create() error {
 	args := m.Called()
 	return args.Error(0)
@@ -261,6 +324,11 @@ func (m *MockQuery) UpdateBuilder() UpdateBuilder {
 	return mustUpdateBuilder(args.Get(0))
 }
 
+func (m *MockQuery) UpdateWithOptimisticRetry(maxAttempts int, mutate func(model any) error, fields ...string) error {
+	args := m.Called(maxAttempts, mutate, fields)
+	return args.Error(0)
+}
+
 func (m *MockQuery) Delete() error {
 	args := m.Called()
 	return args.Error(0)
@@ -281,6 +349,11 @@ func (m *MockQuery) ScanAllSegments(dest any, totalSegments int32) error {
 	return args.Error(0)
 }
 
+func (m *MockQuery) ScanAllSegmentsWithOptions(dest any, totalSegments int32, opts *ScanOptions) error {
+	args := m.Called(dest, totalSegments, opts)
+	return args.Error(0)
+}
+
 func (m *MockQuery) BatchGet(keys []any, dest any) error {
 	args := m.Called(keys, dest)
 	return args.Error(0)
@@ -291,6 +364,16 @@ func (m *MockQuery) BatchGetWithOptions(keys []any, dest any, opts *BatchGetOpti
 	return args.Error(0)
 }
 
+func (m *MockQuery) BatchGetOrdered(keys []any, dest any) error {
+	args := m.Called(keys, dest)
+	return args.Error(0)
+}
+
+func (m *MockQuery) BatchGetMap(keys []any, dest any) error {
+	args := m.Called(keys, dest)
+	return args.Error(0)
+}
+
 func (m *MockQuery) BatchGetBuilder() BatchGetBuilder {
 	args := m.Called()
 	return mustBatchGetBuilder(args.Get(0))
@@ -301,6 +384,12 @@ func (m *MockQuery) BatchCreate(items any) error {
 	return args.Error(0)
 }
 
+func (m *MockQuery) BatchCreateWithOptions(items any, opts *BatchCreateOptions) (*BatchCreateResult, error) {
+	args := m.Called(items, opts)
+	result, _ := args.Get(0).(*BatchCreateResult)
+	return result, args.Error(1)
+}
+
 func (m *MockQuery) BatchDelete(keys []any) error {
 	args := m.Called(keys)
 	return args.Error(0)
@@ -391,6 +480,21 @@ func (m *MockUpdateBuilder) SetListElement(field string, index int, value any) U
 	return mustUpdateBuilder(args.Get(0))
 }
 
+func (m *MockUpdateBuilder) AppendToListWithLimit(field string, values any, maxLen int) UpdateBuilder {
+	args := m.Called(field, values, maxLen)
+	return mustUpdateBuilder(args.Get(0))
+}
+
+func (m *MockUpdateBuilder) SetMapKey(field string, key string, value any) UpdateBuilder {
+	args := m.Called(field, key, value)
+	return mustUpdateBuilder(args.Get(0))
+}
+
+func (m *MockUpdateBuilder) RemoveMapKey(field string, key string) UpdateBuilder {
+	args := m.Called(field, key)
+	return mustUpdateBuilder(args.Get(0))
+}
+
 func (m *MockUpdateBuilder) Condition(field string, operator string, value any) UpdateBuilder {
 	return m
 }
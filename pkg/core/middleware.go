@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Operation types passed to middleware.
+const (
+	OperationQuery          = "Query"
+	OperationScan           = "Scan"
+	OperationGetItem        = "GetItem"
+	OperationPutItem        = "PutItem"
+	OperationUpdateItem     = "UpdateItem"
+	OperationDeleteItem     = "DeleteItem"
+	OperationBatchGetItem   = "BatchGetItem"
+	OperationBatchWriteItem = "BatchWriteItem"
+)
+
+// Item cache outcomes recorded in Operation.Cache.
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+)
+
+// Operation describes one compiled request on its way to DynamoDB. Middleware may read or
+// replace its fields before calling the next handler; the request is sent with whatever
+// they hold when the chain reaches the end.
+type Operation struct {
+	// Input is the compiled query for Query, Scan, GetItem, PutItem, UpdateItem, and
+	// DeleteItem operations.
+	Input *CompiledQuery
+	// Key is the primary key for GetItem, UpdateItem, and DeleteItem.
+	Key map[string]types.AttributeValue
+	// Item is the marshaled item for PutItem.
+	Item map[string]types.AttributeValue
+	// Keys are the primary keys for BatchGetItem.
+	Keys []map[string]types.AttributeValue
+	// Writes are the put and delete requests for BatchWriteItem.
+	Writes []types.WriteRequest
+	// Dest receives the result of reads. Middleware that short-circuits a read, such as a
+	// cache, fills it instead of calling the next handler.
+	Dest any
+	// Tags are the request tags from WithRequestTags. Changes made here are what the
+	// slow query log records for the operation.
+	Tags map[string]string
+	// ConsumedCapacity collects the capacity DynamoDB reports for each call the operation
+	// makes (one per page for paginated reads) when ReturnConsumedCapacity is set.
+	ConsumedCapacity []types.ConsumedCapacity
+	// ReturnConsumedCapacity asks DynamoDB to report consumed capacity, e.g. TOTAL.
+	ReturnConsumedCapacity types.ReturnConsumedCapacity
+
+	Type  string
+	Table string
+	Index string
+
+	// ItemCount and ScannedCount are filled in as the request runs: the items returned or
+	// written, and for queries and scans the items evaluated before filtering.
+	ItemCount    int
+	ScannedCount int
+
+	// Attempts counts the HTTP attempts the AWS SDK made for the operation's calls,
+	// retries included. Throttles counts the attempts DynamoDB throttled: each throttled
+	// attempt of a call that went on to succeed, and the last attempt of a call that failed
+	// throttled.
+	Attempts  int
+	Throttles int
+
+	// Cache is CacheHit when a GetItem was answered by the item cache and CacheMiss when
+	// the cache was consulted but had to read DynamoDB. It is empty when the read bypassed
+	// the cache.
+	Cache string
+}
+
+// Handler sends an operation, or hands it to the next middleware.
+type Handler func(ctx context.Context, op *Operation) error
+
+// Middleware wraps the handler for every operation. It can log or measure the call,
+// change op or ctx before calling next, or return without calling next to short-circuit
+// the request:
+//
+//	db.Use(func(next core.Handler) core.Handler {
+//		return func(ctx context.Context, op *core.Operation) error {
+//			start := time.Now()
+//			err := next(ctx, op)
+//			log.Printf("%s %s took %s", op.Type, op.Table, time.Since(start))
+//			return err
+//		}
+//	})
+type Middleware func(next Handler) Handler
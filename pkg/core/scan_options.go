@@ -0,0 +1,44 @@
+package core
+
+// ScanProgress describes one page of a parallel scan, passed to ScanOptions.OnProgress.
+type ScanProgress struct {
+	// Segment is the segment that read the page, and TotalSegments the segment count.
+	Segment       int32
+	TotalSegments int32
+	// Items and ScannedCount are the page's returned and evaluated item counts.
+	Items        int
+	ScannedCount int
+	// CapacityUnits is the read capacity the page consumed, or zero when DynamoDB did
+	// not report it.
+	CapacityUnits float64
+	// TotalItems counts the items returned so far across all segments.
+	TotalItems int
+	// SegmentDone is set on the segment's last page.
+	SegmentDone bool
+}
+
+// ScanProgressCallback receives scan progress. Calls are serialized, so it does not need
+// its own locking.
+type ScanProgressCallback func(progress ScanProgress)
+
+// ScanOptions tune ScanAllSegmentsWithOptions so a large scan, such as a migration, can
+// leave table capacity for live traffic.
+type ScanOptions struct {
+	// OnProgress, when set, is called after every page of every segment.
+	OnProgress ScanProgressCallback
+	// MaxConcurrency caps the segments scanned at once; the rest wait for a free worker.
+	// Zero or less scans every segment at once.
+	MaxConcurrency int
+	// ItemsPerSecond caps each segment's rate of returned items. Zero means no limit.
+	ItemsPerSecond float64
+	// CapacityPerSecond caps each segment's rate of consumed read capacity units, and
+	// asks DynamoDB to report TOTAL consumed capacity when the query does not already.
+	// Zero means no limit.
+	CapacityPerSecond float64
+}
+
+// DefaultScanOptions returns the options ScanAllSegments uses: every segment at once,
+// without rate limits.
+func DefaultScanOptions() *ScanOptions {
+	return &ScanOptions{}
+}
@@ -0,0 +1,13 @@
+package core
+
+// ThrottleFeedback adapts bulk writes to the throttling DynamoDB reports for each table.
+// Bulk operations report what they sent and what was throttled with Observe, and size
+// their batches or pace their writes by Scale. throttle.Controller implements it.
+type ThrottleFeedback interface {
+	// Observe records that attempted requests or items were sent to table and throttled of
+	// them were throttled or left unprocessed.
+	Observe(table string, attempted, throttled int)
+	// Scale returns the fraction, between 0 and 1, of the configured batch size or rate to
+	// use for table.
+	Scale(table string) float64
+}
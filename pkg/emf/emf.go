@@ -0,0 +1,194 @@
+// Package emf records DynamORM operation metrics as CloudWatch Embedded Metric Format
+// (EMF) log lines. CloudWatch Logs extracts the metrics from the lines, so Lambda
+// functions get latency, throttle, retry, and item count metrics without running a
+// metrics agent. Wire it in with DB.Use:
+//
+//	db.Use(emf.Middleware(os.Stdout, emf.WithNamespace("Payments")))
+//
+// Every operation writes one JSON line with these metrics, dimensioned by Table and
+// Operation:
+//
+//	Latency    milliseconds from the first middleware to the response
+//	Items      items returned or written
+//	Retries    attempts beyond the first, across the operation's calls
+//	Throttles  attempts DynamoDB throttled
+//	Errors     1 if the operation failed
+//
+// The index, request tags, and error are written as properties, which CloudWatch Logs
+// Insights can query without adding dimensions.
+package emf
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+// DefaultNamespace is the CloudWatch namespace metrics are written to unless WithNamespace
+// sets another.
+const DefaultNamespace = "DynamORM"
+
+// Metric names.
+const (
+	MetricLatency   = "Latency"
+	MetricItems     = "Items"
+	MetricRetries   = "Retries"
+	MetricThrottles = "Throttles"
+	MetricErrors    = "Errors"
+)
+
+type config struct {
+	now        func() time.Time
+	dimensions map[string]string
+	namespace  string
+}
+
+// Option configures Middleware.
+type Option func(*config)
+
+// WithNamespace sets the CloudWatch namespace.
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		if namespace != "" {
+			c.namespace = namespace
+		}
+	}
+}
+
+// WithDimensions adds fixed dimensions, such as the service or stage, to every metric
+// alongside Table and Operation.
+func WithDimensions(dimensions map[string]string) Option {
+	return func(c *config) {
+		for k, v := range dimensions {
+			c.dimensions[k] = v
+		}
+	}
+}
+
+// Middleware returns DynamORM middleware that writes an EMF line to w after every
+// operation. Lines are written whole, one Write call each, so w may be shared by
+// concurrent operations; a write error is dropped rather than failing the operation.
+func Middleware(w io.Writer, opts ...Option) core.Middleware {
+	cfg := config{
+		now:        time.Now,
+		namespace:  DefaultNamespace,
+		dimensions: make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return newMiddleware(w, cfg)
+}
+
+func newMiddleware(w io.Writer, cfg config) core.Middleware {
+	e := &emitter{w: w, cfg: cfg, dimensionKeys: dimensionKeys(cfg.dimensions)}
+
+	return func(next core.Handler) core.Handler {
+		return func(ctx context.Context, op *core.Operation) error {
+			start := cfg.now()
+			err := next(ctx, op)
+			e.emit(op, cfg.now().Sub(start), err)
+			return err
+		}
+	}
+}
+
+type emitter struct {
+	w             io.Writer
+	cfg           config
+	dimensionKeys []string
+	mu            sync.Mutex
+}
+
+func dimensionKeys(dimensions map[string]string) []string {
+	keys := []string{"Table", "Operation"}
+	extra := make([]string, 0, len(dimensions))
+	for k := range dimensions {
+		extra = append(extra, k)
+	}
+	sort.Strings(extra)
+	return append(keys, extra...)
+}
+
+type metricDefinition struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+type metricDirective struct {
+	Namespace  string             `json:"Namespace"`
+	Dimensions [][]string         `json:"Dimensions"`
+	Metrics    []metricDefinition `json:"Metrics"`
+}
+
+type metadata struct {
+	CloudWatchMetrics []metricDirective `json:"CloudWatchMetrics"`
+	Timestamp         int64             `json:"Timestamp"`
+}
+
+var metricDefinitions = []metricDefinition{
+	{Name: MetricLatency, Unit: "Milliseconds"},
+	{Name: MetricItems, Unit: "Count"},
+	{Name: MetricRetries, Unit: "Count"},
+	{Name: MetricThrottles, Unit: "Count"},
+	{Name: MetricErrors, Unit: "Count"},
+}
+
+func (e *emitter) emit(op *core.Operation, latency time.Duration, err error) {
+	if e.w == nil || op == nil {
+		return
+	}
+
+	retries := op.Attempts - 1
+	if retries < 0 {
+		retries = 0
+	}
+	failed := 0
+	if err != nil {
+		failed = 1
+	}
+
+	line := make(map[string]any, 12+len(e.cfg.dimensions)+len(op.Tags))
+	for k, v := range op.Tags {
+		line[k] = v
+	}
+	for k, v := range e.cfg.dimensions {
+		line[k] = v
+	}
+	line["_aws"] = metadata{
+		Timestamp: e.cfg.now().UnixMilli(),
+		CloudWatchMetrics: []metricDirective{{
+			Namespace:  e.cfg.namespace,
+			Dimensions: [][]string{e.dimensionKeys},
+			Metrics:    metricDefinitions,
+		}},
+	}
+	line["Table"] = op.Table
+	line["Operation"] = op.Type
+	line[MetricLatency] = float64(latency) / float64(time.Millisecond)
+	line[MetricItems] = op.ItemCount
+	line[MetricRetries] = retries
+	line[MetricThrottles] = op.Throttles
+	line[MetricErrors] = failed
+	if op.Index != "" {
+		line["Index"] = op.Index
+	}
+	if err != nil {
+		line["Error"] = err.Error()
+	}
+
+	data, marshalErr := json.Marshal(line)
+	if marshalErr != nil {
+		return
+	}
+	data = append(data, '\n')
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, _ = e.w.Write(data)
+}
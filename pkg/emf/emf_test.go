@@ -0,0 +1,143 @@
+package emf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal([]byte(raw), &line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// steppingClock advances by step on every reading.
+func steppingClock(start time.Time, step time.Duration) func() time.Time {
+	now := start
+	return func() time.Time {
+		current := now
+		now = now.Add(step)
+		return current
+	}
+}
+
+func TestMiddlewareWritesEMFLine(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := config{
+		now:        steppingClock(time.UnixMilli(1_700_000_000_000), 25*time.Millisecond),
+		namespace:  DefaultNamespace,
+		dimensions: make(map[string]string),
+	}
+	WithNamespace("Payments")(&cfg)
+	WithDimensions(map[string]string{"Service": "ledger"})(&cfg)
+
+	handler := newMiddleware(&buf, cfg)(func(_ context.Context, op *core.Operation) error {
+		op.ItemCount = 3
+		op.Attempts = 3
+		op.Throttles = 2
+		return nil
+	})
+	op := &core.Operation{Type: core.OperationQuery, Table: "orders", Index: "gsi-status", Tags: map[string]string{"tenant": "t1"}}
+	require.NoError(t, handler(context.Background(), op))
+
+	lines := decodeLines(t, &buf)
+	require.Len(t, lines, 1)
+	line := lines[0]
+	require.Equal(t, "orders", line["Table"])
+	require.Equal(t, "Query", line["Operation"])
+	require.Equal(t, "gsi-status", line["Index"])
+	require.Equal(t, "ledger", line["Service"])
+	require.Equal(t, "t1", line["tenant"])
+	require.Equal(t, float64(25), line[MetricLatency])
+	require.Equal(t, float64(3), line[MetricItems])
+	require.Equal(t, float64(2), line[MetricRetries])
+	require.Equal(t, float64(2), line[MetricThrottles])
+	require.Equal(t, float64(0), line[MetricErrors])
+	require.NotContains(t, line, "Error")
+
+	meta, ok := line["_aws"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, float64(1_700_000_000_050), meta["Timestamp"])
+	directives, ok := meta["CloudWatchMetrics"].([]any)
+	require.True(t, ok)
+	require.Len(t, directives, 1)
+	directive, ok := directives[0].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, "Payments", directive["Namespace"])
+	require.Equal(t, []any{[]any{"Table", "Operation", "Service"}}, directive["Dimensions"])
+	require.Len(t, directive["Metrics"], len(metricDefinitions))
+}
+
+func TestMiddlewareRecordsFailures(t *testing.T) {
+	var buf bytes.Buffer
+	boom := errors.New("ProvisionedThroughputExceededException: slow down")
+	handler := Middleware(&buf)(func(_ context.Context, op *core.Operation) error {
+		op.Attempts = 1
+		op.Throttles = 1
+		return boom
+	})
+
+	err := handler(context.Background(), &core.Operation{Type: core.OperationPutItem, Table: "orders"})
+	require.ErrorIs(t, err, boom)
+
+	line := decodeLines(t, &buf)[0]
+	require.Equal(t, float64(1), line[MetricErrors])
+	require.Equal(t, float64(0), line[MetricRetries])
+	require.Equal(t, float64(1), line[MetricThrottles])
+	require.Equal(t, boom.Error(), line["Error"])
+	require.NotContains(t, line, "Index")
+
+	meta, ok := line["_aws"].(map[string]any)
+	require.True(t, ok)
+	directive, ok := meta["CloudWatchMetrics"].([]any)[0].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, DefaultNamespace, directive["Namespace"])
+}
+
+type lockedBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func TestMiddlewareWritesWholeLinesConcurrently(t *testing.T) {
+	var out lockedBuffer
+	handler := Middleware(&out)(func(context.Context, *core.Operation) error { return nil })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = handler(context.Background(), &core.Operation{Type: core.OperationGetItem, Table: "orders"})
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, decodeLines(t, &out.buf), 20)
+}
+
+func TestMiddlewareWithoutWriter(t *testing.T) {
+	handler := Middleware(nil)(func(context.Context, *core.Operation) error { return nil })
+	require.NoError(t, handler(context.Background(), &core.Operation{Type: core.OperationScan}))
+}
@@ -4,6 +4,8 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 )
 
 // Common errors that can occur in DynamORM operations
@@ -58,8 +60,280 @@ var (
 
 	// ErrEncryptedFieldNotQueryable is returned when a dynamorm:"encrypted" field is used in query/filter conditions.
 	ErrEncryptedFieldNotQueryable = errors.New("encrypted fields are not queryable/filterable")
+
+	// ErrDeadlineBudgetExhausted is returned when a multi-call operation stops before its next call because the
+	// remaining context (or Lambda) deadline cannot cover it.
+	ErrDeadlineBudgetExhausted = errors.New("deadline budget exhausted")
+
+	// ErrOverflowNotConfigured is returned when a model uses dynamorm:"s3overflow" fields but no S3 bucket is configured.
+	ErrOverflowNotConfigured = errors.New("s3 overflow not configured")
+
+	// ErrItemTooLarge is returned when item size validation finds a write exceeding DynamoDB's 400KB item limit.
+	ErrItemTooLarge = errors.New("item exceeds maximum size")
+
+	// ErrNumberOutOfRange is returned under the strict number policy when a stored number does not fit
+	// the destination field without overflow or precision loss.
+	ErrNumberOutOfRange = errors.New("number does not fit destination type")
+
+	// ErrShuttingDown is returned for operations started after DB.Shutdown was called.
+	ErrShuttingDown = errors.New("db is shutting down")
+
+	// ErrShutdownIncomplete is returned by DB.Shutdown when work was still pending at its deadline.
+	ErrShutdownIncomplete = errors.New("shutdown incomplete")
+
+	// ErrCircuitOpen is returned for requests rejected without being sent because the session's retry
+	// policy circuit breaker is open after repeated throttling or server errors.
+	ErrCircuitOpen = errors.New("circuit breaker open")
+
+	// ErrMaintenanceMode is returned for requests rejected without being sent because their table
+	// is read-only or offline for maintenance.
+	ErrMaintenanceMode = errors.New("table in maintenance mode")
+
+	// ErrMissingRequiredField is returned by strict reads when a stored item lacks a field tagged
+	// dynamorm:"required".
+	ErrMissingRequiredField = errors.New("missing required field")
+
+	// ErrInvariantViolated is returned by EnforceInvariant when a transaction would break a declared
+	// cross-item invariant.
+	ErrInvariantViolated = errors.New("invariant violated")
+
+	// ErrValidationFailed is returned when a model breaks its validation tags or its Validate method
+	// on Create, CreateOrUpdate, BatchCreate, or Update.
+	ErrValidationFailed = errors.New("validation failed")
+
+	// ErrUniqueConstraint is returned when a write would give an item the value of a field
+	// tagged dynamorm:"unique" that another item already has.
+	ErrUniqueConstraint = errors.New("unique constraint violated")
+
+	// ErrInvalidEnumValue is returned when a field tagged with enum names holds a value
+	// without a name, or an item stores a name the field does not list.
+	ErrInvalidEnumValue = errors.New("invalid enum value")
+
+	// ErrTenantRequired is returned when a model with a field tagged dynamorm:"tenant" is
+	// read or written through a DB not scoped to a tenant with WithTenant.
+	ErrTenantRequired = errors.New("tenant required")
+
+	// ErrCrossTenant is returned when a request made through a DB scoped to a tenant
+	// names an item, key, or condition of another tenant.
+	ErrCrossTenant = errors.New("cross-tenant access denied")
 )
 
+// ShutdownError reports work that DB.Shutdown could not finish before its context ended
+// or that failed while draining.
+type ShutdownError struct {
+	// Err is the context error when the deadline was reached, or nil.
+	Err error
+	// DrainErrors holds the errors returned by shutdown hooks, keyed by hook name.
+	DrainErrors map[string]error
+	// Undrained names the shutdown hooks that never ran because the deadline was reached.
+	Undrained []string
+	// InFlight is the number of operations still running when Shutdown returned.
+	InFlight int
+}
+
+// Error implements the error interface.
+func (e *ShutdownError) Error() string {
+	if e == nil {
+		return ErrShutdownIncomplete.Error()
+	}
+	parts := make([]string, 0, 3)
+	if e.InFlight > 0 {
+		parts = append(parts, fmt.Sprintf("%d operations still in flight", e.InFlight))
+	}
+	if len(e.Undrained) > 0 {
+		parts = append(parts, "hooks not run: "+strings.Join(e.Undrained, ", "))
+	}
+	if len(e.DrainErrors) > 0 {
+		names := make([]string, 0, len(e.DrainErrors))
+		for name := range e.DrainErrors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			names[i] = fmt.Sprintf("%s: %v", name, e.DrainErrors[name])
+		}
+		parts = append(parts, "hooks failed: "+strings.Join(names, "; "))
+	}
+	msg := "dynamorm: shutdown incomplete"
+	if len(parts) > 0 {
+		msg += ": " + strings.Join(parts, "; ")
+	}
+	if e.Err != nil {
+		msg += fmt.Sprintf(" (%v)", e.Err)
+	}
+	return msg
+}
+
+// Unwrap returns ErrShutdownIncomplete and the context error, if any.
+func (e *ShutdownError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{ErrShutdownIncomplete}
+	}
+	return []error{ErrShutdownIncomplete, e.Err}
+}
+
+// NumberRangeError reports a stored number rejected by the strict number policy.
+type NumberRangeError struct {
+	Value  string
+	Target string
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *NumberRangeError) Error() string {
+	if e == nil {
+		return ErrNumberOutOfRange.Error()
+	}
+	return fmt.Sprintf("dynamorm: number %s does not fit %s: %s", e.Value, e.Target, e.Reason)
+}
+
+// Unwrap returns ErrNumberOutOfRange.
+func (e *NumberRangeError) Unwrap() error {
+	return ErrNumberOutOfRange
+}
+
+// BatchWriteError reports a batch write in which some items were not written. The
+// per-item outcomes are in the result returned alongside it.
+type BatchWriteError struct {
+	Operation string
+	Total     int
+	Succeeded int
+	Failed    int
+	Throttled int
+}
+
+// Error implements the error interface.
+func (e *BatchWriteError) Error() string {
+	if e == nil {
+		return ErrBatchOperationFailed.Error()
+	}
+	return fmt.Sprintf("dynamorm: %s wrote %d of %d items (%d failed, %d throttled)",
+		e.Operation, e.Succeeded, e.Total, e.Failed, e.Throttled)
+}
+
+// Unwrap returns ErrBatchOperationFailed.
+func (e *BatchWriteError) Unwrap() error {
+	return ErrBatchOperationFailed
+}
+
+// PipelineError reports the pipeline stage that failed.
+type PipelineError struct {
+	Err      error
+	Pipeline string
+	Stage    string
+}
+
+// Error implements the error interface.
+func (e *PipelineError) Error() string {
+	if e == nil {
+		return "dynamorm: pipeline failed"
+	}
+	return fmt.Sprintf("dynamorm: pipeline %s: stage %s: %v", e.Pipeline, e.Stage, e.Err)
+}
+
+// Unwrap returns the stage's error.
+func (e *PipelineError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// ItemSizeError reports an item rejected by item size validation, with its largest
+// top-level attributes to show where the bytes went.
+type ItemSizeError struct {
+	Largest   []AttributeSize
+	Operation string
+	Size      int
+	Limit     int
+}
+
+// AttributeSize is the size in bytes of one top-level attribute, name included.
+type AttributeSize struct {
+	Name  string
+	Bytes int
+}
+
+// Error implements the error interface.
+func (e *ItemSizeError) Error() string {
+	if e == nil {
+		return ErrItemTooLarge.Error()
+	}
+	parts := make([]string, len(e.Largest))
+	for i, attr := range e.Largest {
+		parts[i] = fmt.Sprintf("%s (%d bytes)", attr.Name, attr.Bytes)
+	}
+	return fmt.Sprintf("dynamorm: %s item is %d bytes, exceeding the %d byte limit; largest attributes: %s",
+		e.Operation, e.Size, e.Limit, strings.Join(parts, ", "))
+}
+
+// Unwrap returns ErrItemTooLarge.
+func (e *ItemSizeError) Unwrap() error {
+	return ErrItemTooLarge
+}
+
+// MissingFieldsError reports a stored item that lacks fields tagged dynamorm:"required",
+// such as one partially written by a legacy writer.
+type MissingFieldsError struct {
+	Table string
+	// Key describes the item's primary key, such as "id=a1".
+	Key string
+	// Fields are the attribute names the item lacks.
+	Fields []string
+}
+
+// Error implements the error interface.
+func (e *MissingFieldsError) Error() string {
+	if e == nil {
+		return ErrMissingRequiredField.Error()
+	}
+	return fmt.Sprintf("dynamorm: %s item %s is missing required fields: %s",
+		e.Table, e.Key, strings.Join(e.Fields, ", "))
+}
+
+// Unwrap returns ErrMissingRequiredField.
+func (e *MissingFieldsError) Unwrap() error {
+	return ErrMissingRequiredField
+}
+
+// ValidationError reports every validation rule a model broke before a write, so callers
+// can return all the problems at once.
+type ValidationError struct {
+	Model  string
+	Fields []FieldError
+}
+
+// FieldError is one broken validation rule. Field is empty for errors from the model's
+// own Validate method.
+type FieldError struct {
+	Field string
+	// Rule is the tag that failed, such as "min" or "pattern", or "custom" for a
+	// Validate method.
+	Rule    string
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	if e == nil {
+		return ErrValidationFailed.Error()
+	}
+	parts := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		if field.Field == "" {
+			parts[i] = field.Message
+		} else {
+			parts[i] = field.Field + " " + field.Message
+		}
+	}
+	return fmt.Sprintf("dynamorm: %s validation failed: %s", e.Model, strings.Join(parts, "; "))
+}
+
+// Unwrap returns ErrValidationFailed.
+func (e *ValidationError) Unwrap() error {
+	return ErrValidationFailed
+}
+
 // EncryptedFieldError wraps failures related to dynamorm:"encrypted" fields (encryption/decryption).
 // It is safe-by-default: the error string must never include decrypted plaintext.
 type EncryptedFieldError struct {
@@ -155,15 +429,33 @@ func IsConditionFailed(err error) bool {
 	return errors.Is(err, ErrConditionFailed)
 }
 
-// TransactionError provides context for transactional failures.
+// TransactionError provides context for transactional failures. Operation, Model,
+// and Reason describe the first canceled item; Reasons lists every item DynamoDB
+// reported as the cause of the cancellation.
 type TransactionError struct {
 	Err            error
 	Operation      string
 	Model          string
 	Reason         string
+	Reasons        []CancellationReason
 	OperationIndex int
 }
 
+// CancellationReason describes why DynamoDB canceled one item of a transaction.
+type CancellationReason struct {
+	// Code is DynamoDB's reason code, such as ConditionalCheckFailed or TransactionConflict.
+	Code           string
+	Message        string
+	Operation      string
+	Model          string
+	OperationIndex int
+}
+
+// ConditionFailed reports whether the item's condition expression evaluated to false.
+func (r CancellationReason) ConditionFailed() bool {
+	return r.Code == "ConditionalCheckFailed"
+}
+
 // Error implements the error interface.
 func (e *TransactionError) Error() string {
 	if e == nil {
@@ -190,3 +482,29 @@ func (e *TransactionError) Unwrap() error {
 	}
 	return e.Err
 }
+
+// PartialProgressError reports how far a multi-call operation got before it stopped.
+// Completed and Total are counted in the operation's natural unit (items for batch
+// writes, segments for parallel scans).
+type PartialProgressError struct {
+	Err       error
+	Operation string
+	Completed int
+	Total     int
+}
+
+// Error implements the error interface.
+func (e *PartialProgressError) Error() string {
+	if e == nil {
+		return "dynamorm: operation stopped early"
+	}
+	return fmt.Sprintf("dynamorm: %s stopped after %d of %d: %v", e.Operation, e.Completed, e.Total, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *PartialProgressError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
@@ -0,0 +1,164 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// Remediation codes identify the hints attached to common failures. They are stable, so
+// services can count or route on them.
+const (
+	// RemediationMissingPartitionKey: a single-item operation had no partition key value.
+	RemediationMissingPartitionKey = "missing_partition_key"
+	// RemediationMissingSortKey: a single-item operation on a composite key had no sort
+	// key value.
+	RemediationMissingSortKey = "missing_sort_key"
+	// RemediationScanFallback: a read had no key condition and would scan, which the
+	// model's access patterns do not allow.
+	RemediationScanFallback = "scan_fallback"
+	// RemediationUndeclaredAccessPattern: a keyed read matched no declared access pattern.
+	RemediationUndeclaredAccessPattern = "undeclared_access_pattern"
+	// RemediationGSIConsistentRead: a strongly consistent read targeted a global
+	// secondary index.
+	RemediationGSIConsistentRead = "gsi_consistent_read"
+	// RemediationEncryptedFieldQuery: a condition or filter used an encrypted field.
+	RemediationEncryptedFieldQuery = "encrypted_field_query"
+	// RemediationKeySchemaMismatch: a request's key did not match the table's key schema.
+	RemediationKeySchemaMismatch = "key_schema_mismatch"
+)
+
+// Remediation is a machine-readable hint on how to fix a failure.
+type Remediation struct {
+	// Details holds specifics such as "model", "field", "index", or "operation".
+	Details map[string]string
+	Code    string
+	// Hint tells the developer what to change.
+	Hint string
+}
+
+// LogValue groups the remediation's code, hint, and details for log/slog:
+//
+//	if r, ok := errors.GetRemediation(err); ok {
+//		logger.Error("request failed", "error", err, "remediation", r)
+//	}
+func (r Remediation) LogValue() slog.Value {
+	attrs := []slog.Attr{slog.String("code", r.Code), slog.String("hint", r.Hint)}
+	keys := make([]string, 0, len(r.Details))
+	for k := range r.Details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		attrs = append(attrs, slog.String(k, r.Details[k]))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Remediable is implemented by errors carrying a Remediation.
+type Remediable interface {
+	Remediation() Remediation
+}
+
+// RemediationError attaches a Remediation to an error without changing its message.
+type RemediationError struct {
+	Err  error
+	Hint Remediation
+}
+
+// Error returns the wrapped error's message.
+func (e *RemediationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *RemediationError) Unwrap() error {
+	return e.Err
+}
+
+// Remediation returns the attached hint.
+func (e *RemediationError) Remediation() Remediation {
+	return e.Hint
+}
+
+// WithRemediation attaches r to err. It returns nil for a nil err.
+func WithRemediation(err error, r Remediation) error {
+	if err == nil {
+		return nil
+	}
+	return &RemediationError{Err: err, Hint: r}
+}
+
+// GetRemediation returns the hint of the first error in err's chain carrying one.
+func GetRemediation(err error) (Remediation, bool) {
+	var remediable Remediable
+	if errors.As(err, &remediable) {
+		return remediable.Remediation(), true
+	}
+	return Remediation{}, false
+}
+
+// MissingKeyError reports that operation needs a value for the key field. sortKey
+// selects the sort key hint over the partition key one.
+func MissingKeyError(field, operation string, sortKey bool) error {
+	kind, code := "partition", RemediationMissingPartitionKey
+	if sortKey {
+		kind, code = "sort", RemediationMissingSortKey
+	}
+	return WithRemediation(fmt.Errorf("%s key %s is required for %s", kind, field, operation), Remediation{
+		Code: code,
+		Hint: fmt.Sprintf(`add Where(%q, "=", value) to address a single item, or use All() to read many`, field),
+		Details: map[string]string{
+			"field":     field,
+			"operation": operation,
+		},
+	})
+}
+
+// EncryptedFieldQueryError reports a condition or filter on the encrypted field.
+func EncryptedFieldQueryError(field string) error {
+	return WithRemediation(fmt.Errorf("%w: %s", ErrEncryptedFieldNotQueryable, field), Remediation{
+		Code: RemediationEncryptedFieldQuery,
+		Hint: fmt.Sprintf("encrypted values are randomized; add a field tagged dynamorm:\"blindindex:%s\" (for example one indexed by a GSI) to compare %s with = or IN", field, field),
+		Details: map[string]string{
+			"field": field,
+		},
+	})
+}
+
+// Remediate attaches hints to the DynamoDB errors that have a known cause, identified by
+// their error code and message. Other errors, and errors already carrying a hint, are
+// returned unchanged.
+func Remediate(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := GetRemediation(err); ok {
+		return err
+	}
+	var apiErr interface {
+		ErrorCode() string
+		ErrorMessage() string
+	}
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "ValidationException" {
+		return err
+	}
+
+	message := strings.ToLower(apiErr.ErrorMessage())
+	switch {
+	case strings.Contains(message, "consistent read") && strings.Contains(message, "global secondary index"):
+		return WithRemediation(err, Remediation{
+			Code: RemediationGSIConsistentRead,
+			Hint: "global secondary indexes are eventually consistent; drop ConsistentRead, or read the item from the table by its primary key after the index read",
+		})
+	case strings.Contains(message, "key element does not match the schema"),
+		strings.Contains(message, "missed key schema element"):
+		return WithRemediation(err, Remediation{
+			Code: RemediationKeySchemaMismatch,
+			Hint: "the key attributes sent do not match the table; check the model's pk/sk tags and attribute names against the table's key schema",
+		})
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRemediationKeepsMessageAndChain(t *testing.T) {
+	assert.NoError(t, WithRemediation(nil, Remediation{Code: "x"}))
+
+	err := fmt.Errorf("reading order: %w", WithRemediation(ErrItemNotFound, Remediation{Code: "x", Hint: "y"}))
+	assert.Equal(t, "reading order: item not found", err.Error())
+	assert.ErrorIs(t, err, ErrItemNotFound)
+
+	r, ok := GetRemediation(err)
+	require.True(t, ok)
+	assert.Equal(t, "x", r.Code)
+
+	_, ok = GetRemediation(ErrItemNotFound)
+	assert.False(t, ok)
+}
+
+func TestMissingKeyError(t *testing.T) {
+	err := MissingKeyError("ID", "delete", false)
+	assert.EqualError(t, err, "partition key ID is required for delete")
+	r, ok := GetRemediation(err)
+	require.True(t, ok)
+	assert.Equal(t, RemediationMissingPartitionKey, r.Code)
+	assert.Contains(t, r.Hint, `Where("ID", "=", value)`)
+	assert.Equal(t, map[string]string{"field": "ID", "operation": "delete"}, r.Details)
+
+	err = MissingKeyError("SK", "update", true)
+	assert.EqualError(t, err, "sort key SK is required for update")
+	r, _ = GetRemediation(err)
+	assert.Equal(t, RemediationMissingSortKey, r.Code)
+}
+
+func TestEncryptedFieldQueryError(t *testing.T) {
+	err := EncryptedFieldQueryError("email")
+	assert.ErrorIs(t, err, ErrEncryptedFieldNotQueryable)
+	assert.EqualError(t, err, "encrypted fields are not queryable/filterable: email")
+	r, ok := GetRemediation(err)
+	require.True(t, ok)
+	assert.Equal(t, RemediationEncryptedFieldQuery, r.Code)
+	assert.Equal(t, "email", r.Details["field"])
+}
+
+func TestRemediateClassifiesValidationErrors(t *testing.T) {
+	validation := func(msg string) error {
+		return fmt.Errorf("failed to query items: %w", &smithy.GenericAPIError{Code: "ValidationException", Message: msg})
+	}
+
+	tests := map[string]string{
+		"Consistent reads are not supported on global secondary indexes": RemediationGSIConsistentRead,
+		"The provided key element does not match the schema":             RemediationKeySchemaMismatch,
+		"Query condition missed key schema element: tenantId":            RemediationKeySchemaMismatch,
+	}
+	for msg, code := range tests {
+		err := Remediate(validation(msg))
+		r, ok := GetRemediation(err)
+		require.True(t, ok, msg)
+		assert.Equal(t, code, r.Code, msg)
+		assert.Contains(t, err.Error(), msg)
+	}
+
+	other := validation("One or more parameter values were invalid")
+	assert.Same(t, other, Remediate(other))
+	throttled := &smithy.GenericAPIError{Code: "ThrottlingException", Message: "global secondary index consistent read"}
+	assert.Same(t, error(throttled), Remediate(throttled))
+	assert.NoError(t, Remediate(nil))
+
+	hinted := MissingKeyError("ID", "get", false)
+	assert.Same(t, hinted, Remediate(hinted))
+}
+
+func TestRemediationLogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	r, _ := GetRemediation(MissingKeyError("ID", "get", false))
+	logger.Error("read failed", "remediation", r)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	group, ok := line["remediation"].(map[string]any)
+	require.True(t, ok, buf.String())
+	assert.Equal(t, RemediationMissingPartitionKey, group["code"])
+	assert.Equal(t, "ID", group["field"])
+	assert.Equal(t, "get", group["operation"])
+	assert.NotEmpty(t, group["hint"])
+}
+
+func TestGetRemediationFindsRemediableErrors(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", remediableStub{})
+	r, ok := GetRemediation(err)
+	require.True(t, ok)
+	assert.Equal(t, "stub", r.Code)
+	assert.False(t, errors.Is(err, ErrItemNotFound))
+}
+
+type remediableStub struct{}
+
+func (remediableStub) Error() string            { return "stub" }
+func (remediableStub) Remediation() Remediation { return Remediation{Code: "stub"} }
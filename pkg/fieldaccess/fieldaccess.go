@@ -0,0 +1,272 @@
+// Package fieldaccess samples the attributes reads request from each table and index, so
+// index projections can be narrowed to what readers use and the storage and read
+// capacity spent on unread attributes saved.
+package fieldaccess
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Usage aggregates the sampled reads of one table or index.
+type Usage struct {
+	// Attributes counts, per attribute, the sampled reads with a projection that named it.
+	Attributes map[string]int64
+	Table      string
+	// Index is empty for reads of the table itself.
+	Index string
+	// Reads is the number of sampled reads. FullReads of them had no projection and so
+	// read every attribute.
+	Reads     int64
+	FullReads int64
+}
+
+type target struct {
+	table string
+	index string
+}
+
+// Sampler records the attributes requested by a fraction of reads. It is safe for
+// concurrent use.
+type Sampler struct {
+	random  func() float64
+	entries map[target]*Usage
+	rate    float64
+	mu      sync.Mutex
+}
+
+// NewSampler creates a sampler that records rate of the reads passed to Record, such as
+// 0.01 for one in a hundred. A rate of zero or less, or above one, records every read.
+func NewSampler(rate float64) *Sampler {
+	s := &Sampler{random: rand.Float64, entries: make(map[target]*Usage)}
+	s.SetRate(rate)
+	return s
+}
+
+// SetRate changes the fraction of reads recorded.
+func (s *Sampler) SetRate(rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rate <= 0 || rate > 1 {
+		rate = 1
+	}
+	s.rate = rate
+}
+
+// Record samples one read of table, or of index when it is not empty. attributes are the
+// top-level attributes the read's projection named; nil means the read had none and
+// returned whole items.
+func (s *Sampler) Record(table, index string, attributes []string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rate < 1 && s.random() >= s.rate {
+		return
+	}
+
+	k := target{table: table, index: index}
+	usage, ok := s.entries[k]
+	if !ok {
+		usage = &Usage{Table: table, Index: index, Attributes: make(map[string]int64)}
+		s.entries[k] = usage
+	}
+	usage.Reads++
+	if attributes == nil {
+		usage.FullReads++
+		return
+	}
+	for _, name := range attributes {
+		usage.Attributes[name]++
+	}
+}
+
+// Usage returns a copy of the sampled usage, ordered by table and index.
+func (s *Sampler) Usage() []Usage {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	out := make([]Usage, 0, len(s.entries))
+	for _, usage := range s.entries {
+		copied := *usage
+		copied.Attributes = make(map[string]int64, len(usage.Attributes))
+		for name, count := range usage.Attributes {
+			copied.Attributes[name] = count
+		}
+		out = append(out, copied)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Table != out[j].Table {
+			return out[i].Table < out[j].Table
+		}
+		return out[i].Index < out[j].Index
+	})
+	return out
+}
+
+// Reset discards all sampled usage.
+func (s *Sampler) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = make(map[target]*Usage)
+}
+
+// Projection describes the attributes an index projects.
+type Projection struct {
+	Table string
+	Index string
+	// Type is ALL, KEYS_ONLY, or INCLUDE. Empty means ALL.
+	Type types.ProjectionType
+	// Keys are the key attributes of the table and the index, which every index projects.
+	Keys []string
+	// NonKeyAttributes are the attributes an INCLUDE projection adds to the keys.
+	NonKeyAttributes []string
+}
+
+// Suggestion is a projection change for an index, based on its sampled reads.
+type Suggestion struct {
+	Table     string
+	Index     string
+	Current   types.ProjectionType
+	Suggested types.ProjectionType
+	// NonKeyAttributes are the attributes to project with Suggested INCLUDE.
+	NonKeyAttributes []string
+	Reason           string
+}
+
+// String describes the suggestion on one line.
+func (s Suggestion) String() string {
+	suggested := string(s.Suggested)
+	if s.Suggested == types.ProjectionTypeInclude {
+		suggested += " (" + strings.Join(s.NonKeyAttributes, ", ") + ")"
+	}
+	return fmt.Sprintf("%s/%s: %s -> %s: %s", s.Table, s.Index, s.Current, suggested, s.Reason)
+}
+
+// Suggest compares the sampled usage of each index with its projection. It suggests
+// INCLUDE, or KEYS_ONLY, for an index whose reads all named a subset of the attributes
+// it projects, and ALL or a wider INCLUDE for one whose reads asked for attributes it
+// does not project. Indexes with fewer than minReads sampled reads are skipped, since
+// a rare read that needs every attribute may not have been sampled yet.
+func Suggest(usage []Usage, projections []Projection, minReads int64) []Suggestion {
+	byTarget := make(map[target]Usage, len(usage))
+	for _, u := range usage {
+		byTarget[target{table: u.Table, index: u.Index}] = u
+	}
+
+	var suggestions []Suggestion
+	for _, projection := range projections {
+		u, ok := byTarget[target{table: projection.Table, index: projection.Index}]
+		if !ok || u.Reads == 0 || u.Reads < minReads {
+			continue
+		}
+		if suggestion, ok := suggest(projection, u); ok {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Table != suggestions[j].Table {
+			return suggestions[i].Table < suggestions[j].Table
+		}
+		return suggestions[i].Index < suggestions[j].Index
+	})
+	return suggestions
+}
+
+func suggest(projection Projection, u Usage) (Suggestion, bool) {
+	current := projection.Type
+	if current == "" {
+		current = types.ProjectionTypeAll
+	}
+	suggestion := Suggestion{Table: projection.Table, Index: projection.Index, Current: current}
+
+	if u.FullReads > 0 {
+		if current == types.ProjectionTypeAll {
+			return suggestion, false
+		}
+		suggestion.Suggested = types.ProjectionTypeAll
+		suggestion.Reason = fmt.Sprintf("%d of %d sampled reads had no projection and read whole items", u.FullReads, u.Reads)
+		return suggestion, true
+	}
+
+	keys := make(map[string]bool, len(projection.Keys))
+	for _, key := range projection.Keys {
+		keys[key] = true
+	}
+	var needed []string
+	for name := range u.Attributes {
+		if !keys[name] {
+			needed = append(needed, name)
+		}
+	}
+	sort.Strings(needed)
+
+	switch {
+	case len(needed) == 0:
+		if current == types.ProjectionTypeKeysOnly {
+			return suggestion, false
+		}
+		suggestion.Suggested = types.ProjectionTypeKeysOnly
+		suggestion.Reason = fmt.Sprintf("%d sampled reads named only key attributes", u.Reads)
+		return suggestion, true
+	case current == types.ProjectionTypeInclude && sameStrings(needed, projection.NonKeyAttributes):
+		return suggestion, false
+	}
+
+	suggestion.Suggested = types.ProjectionTypeInclude
+	suggestion.NonKeyAttributes = needed
+	switch current {
+	case types.ProjectionTypeAll:
+		suggestion.Reason = fmt.Sprintf("%d sampled reads named %d non-key attributes", u.Reads, len(needed))
+	default:
+		projected := make(map[string]bool, len(projection.NonKeyAttributes))
+		for _, name := range projection.NonKeyAttributes {
+			projected[name] = true
+		}
+		var missing, unused []string
+		for _, name := range needed {
+			if !projected[name] {
+				missing = append(missing, name)
+			}
+			delete(projected, name)
+		}
+		for name := range projected {
+			unused = append(unused, name)
+		}
+		sort.Strings(unused)
+		var reasons []string
+		if len(missing) > 0 {
+			reasons = append(reasons, "reads named unprojected "+strings.Join(missing, ", "))
+		}
+		if len(unused) > 0 {
+			reasons = append(reasons, "no sampled read named "+strings.Join(unused, ", "))
+		}
+		suggestion.Reason = strings.Join(reasons, "; ")
+	}
+	return suggestion, true
+}
+
+func sameStrings(sorted []string, values []string) bool {
+	if len(sorted) != len(values) {
+		return false
+	}
+	other := append([]string(nil), values...)
+	sort.Strings(other)
+	for i := range sorted {
+		if sorted[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package fieldaccess
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSamplerRecordsAttributes(t *testing.T) {
+	s := NewSampler(1)
+	s.Record("users", "email-index", []string{"id", "name"})
+	s.Record("users", "email-index", []string{"name"})
+	s.Record("users", "", nil)
+
+	usage := s.Usage()
+	require.Len(t, usage, 2)
+	require.Equal(t, Usage{Table: "users", Reads: 1, FullReads: 1, Attributes: map[string]int64{}}, usage[0])
+	require.Equal(t, "email-index", usage[1].Index)
+	require.Equal(t, int64(2), usage[1].Reads)
+	require.Equal(t, map[string]int64{"id": 1, "name": 2}, usage[1].Attributes)
+
+	s.Reset()
+	require.Empty(t, s.Usage())
+}
+
+func TestSamplerRate(t *testing.T) {
+	s := NewSampler(0.5)
+	draws := []float64{0.2, 0.7, 0.4, 0.9}
+	s.random = func() float64 {
+		v := draws[0]
+		draws = draws[1:]
+		return v
+	}
+	for i := 0; i < 4; i++ {
+		s.Record("users", "", nil)
+	}
+	require.Equal(t, int64(2), s.Usage()[0].Reads)
+}
+
+func TestSuggest(t *testing.T) {
+	usage := []Usage{
+		{Table: "users", Index: "email-index", Reads: 10, Attributes: map[string]int64{"id": 10, "email": 10, "name": 4}},
+		{Table: "users", Index: "tenant-index", Reads: 3, FullReads: 1, Attributes: map[string]int64{"id": 2}},
+		{Table: "users", Index: "status-index", Reads: 5, Attributes: map[string]int64{"id": 5, "status": 5}},
+		{Table: "users", Index: "plan-index", Reads: 5, Attributes: map[string]int64{"plan": 5, "seats": 5}},
+		{Table: "users", Index: "rare-index", Reads: 1, Attributes: map[string]int64{"id": 1}},
+	}
+	keys := []string{"id"}
+	projections := []Projection{
+		{Table: "users", Index: "email-index", Keys: append(keys, "email")},
+		{Table: "users", Index: "tenant-index", Type: types.ProjectionTypeInclude, Keys: append(keys, "tenant"), NonKeyAttributes: []string{"name"}},
+		{Table: "users", Index: "status-index", Type: types.ProjectionTypeAll, Keys: append(keys, "status")},
+		{Table: "users", Index: "plan-index", Type: types.ProjectionTypeInclude, Keys: append(keys, "plan"), NonKeyAttributes: []string{"seats"}},
+		{Table: "users", Index: "rare-index", Keys: keys},
+	}
+
+	suggestions := Suggest(usage, projections, 2)
+	require.Len(t, suggestions, 3)
+
+	require.Equal(t, "email-index", suggestions[0].Index)
+	require.Equal(t, types.ProjectionTypeAll, suggestions[0].Current)
+	require.Equal(t, types.ProjectionTypeInclude, suggestions[0].Suggested)
+	require.Equal(t, []string{"name"}, suggestions[0].NonKeyAttributes)
+	require.Equal(t, "users/email-index: ALL -> INCLUDE (name): 10 sampled reads named 1 non-key attributes", suggestions[0].String())
+
+	require.Equal(t, "status-index", suggestions[1].Index)
+	require.Equal(t, types.ProjectionTypeKeysOnly, suggestions[1].Suggested)
+
+	require.Equal(t, "tenant-index", suggestions[2].Index)
+	require.Equal(t, types.ProjectionTypeAll, suggestions[2].Suggested)
+}
@@ -59,6 +59,54 @@ func (s *Selector) SelectOptimal(required RequiredKeys, conditions []any) (*core
 	return bestIndex, nil
 }
 
+// SelectForConditions selects the best index for conditions by matching them against
+// the keys of each index in turn, so a condition on an attribute that is not the index's
+// partition key does not hide an index whose partition key also has an equality
+// condition. The table itself wins over an index that matches as many keys, since it
+// holds every attribute and supports consistent reads. It returns nil when no index has
+// a usable partition key condition, and the query must scan.
+func (s *Selector) SelectForConditions(conditions []Condition) (*core.IndexSchema, error) {
+	var bestIndex *core.IndexSchema
+	var bestScore int
+
+	for _, idx := range s.indexes {
+		score := s.scoreIndex(idx, requiredKeysFor(idx, conditions))
+		if score > 0 && idx.Type == "PRIMARY" {
+			score += 20
+		}
+		if score > bestScore {
+			bestScore = score
+			idxCopy := idx
+			bestIndex = &idxCopy
+		}
+	}
+	return bestIndex, nil
+}
+
+// requiredKeysFor returns the keys of idx that conditions can use in a key condition:
+// the partition key with an equality condition, and the sort key with a comparison,
+// BETWEEN, or begins_with condition.
+func requiredKeysFor(idx core.IndexSchema, conditions []Condition) RequiredKeys {
+	var required RequiredKeys
+	for _, cond := range conditions {
+		op := normalizeOperator(cond.Operator)
+		switch {
+		case cond.Field == idx.PartitionKey && idx.PartitionKey != "" && op == "=":
+			required.PartitionKey = cond.Field
+		case cond.Field == idx.SortKey && idx.SortKey != "" && required.SortKey == "":
+			switch op {
+			case "=", "<", "<=", ">", ">=", "between", "begins_with":
+				required.SortKey = cond.Field
+				required.SortKeyOp = op
+			}
+		}
+	}
+	if required.PartitionKey == "" {
+		return RequiredKeys{}
+	}
+	return required
+}
+
 // scoreIndex calculates a score for how well an index matches the requirements
 func (s *Selector) scoreIndex(idx core.IndexSchema, required RequiredKeys) int {
 	score := 0
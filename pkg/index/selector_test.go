@@ -396,6 +396,69 @@ func TestSelectOptimal_ComplexScenarios(t *testing.T) {
 	}
 }
 
+func TestSelectForConditions(t *testing.T) {
+	selector := index.NewSelector([]core.IndexSchema{
+		{Name: "", Type: "PRIMARY", PartitionKey: "id", SortKey: "timestamp"},
+		{Name: "user-date-index", Type: "GSI", PartitionKey: "userId", SortKey: "date", ProjectionType: "ALL"},
+		{Name: "status-index", Type: "GSI", PartitionKey: "status", SortKey: "timestamp", ProjectionType: "ALL"},
+		{Name: "email-index", Type: "GSI", PartitionKey: "email", ProjectionType: "ALL"},
+	})
+
+	tests := []struct {
+		name         string
+		conditions   []index.Condition
+		expectedName string
+		expectNil    bool
+	}{
+		{
+			name: "non-key equality before the index key",
+			conditions: []index.Condition{
+				{Field: "name", Operator: "=", Value: "a"},
+				{Field: "email", Operator: "=", Value: "a@example.com"},
+			},
+			expectedName: "email-index",
+		},
+		{
+			name: "sort key condition picks between indexes",
+			conditions: []index.Condition{
+				{Field: "status", Operator: "=", Value: "open"},
+				{Field: "userId", Operator: "=", Value: "u1"},
+				{Field: "date", Operator: "BETWEEN", Value: []any{"a", "b"}},
+			},
+			expectedName: "user-date-index",
+		},
+		{
+			name: "table wins a tie",
+			conditions: []index.Condition{
+				{Field: "email", Operator: "=", Value: "a@example.com"},
+				{Field: "id", Operator: "=", Value: "1"},
+			},
+			expectedName: "",
+		},
+		{
+			name: "partition key without equality scans",
+			conditions: []index.Condition{
+				{Field: "email", Operator: "BEGINS_WITH", Value: "a"},
+				{Field: "timestamp", Operator: ">", Value: 1},
+			},
+			expectNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := selector.SelectForConditions(tt.conditions)
+			require.NoError(t, err)
+			if tt.expectNil {
+				assert.Nil(t, result)
+				return
+			}
+			require.NotNil(t, result)
+			assert.Equal(t, tt.expectedName, result.Name)
+		})
+	}
+}
+
 func TestNormalizeOperator(t *testing.T) {
 	// This function is not exported, so we test it indirectly through AnalyzeConditions
 	tests := []struct {
@@ -0,0 +1,135 @@
+// Package leadingkeys checks DynamoDB requests against dynamodb:LeadingKeys-style
+// fine-grained access control before they are sent.
+//
+// Tables shared by many tenants are commonly locked down with IAM policies whose
+// dynamodb:LeadingKeys condition limits a principal to partition keys derived from its
+// tenant. When the data layer builds a key the policy does not allow, the failure only
+// shows up as an AccessDeniedException in a deployed environment. A Rule checks that every
+// partition key a request touches is built from the tenant carried in the request
+// context, and a Simulator evaluates requests against the policy document itself, so
+// policy/data-layer mismatches fail in tests instead.
+package leadingkeys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDenied is returned (wrapped in a *DeniedError) when a checker rejects a request.
+var ErrDenied = errors.New("leading key access denied")
+
+// TenantPlaceholder is replaced with the request's tenant in Rule templates.
+const TenantPlaceholder = "{tenant}"
+
+type tenantKey struct{}
+
+// WithTenant returns a context carrying tenant, from which leading keys are checked.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant stored by WithTenant.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// Request describes one DynamoDB request as IAM evaluates it.
+type Request struct {
+	// Action is the IAM action, e.g. "dynamodb:Query".
+	Action string
+	Table  string
+	Index  string
+	// Tenant is the tenant from the request context, if any.
+	Tenant string
+	// LeadingKeys are the partition key values the request touches. Scans have none.
+	LeadingKeys []string
+}
+
+// Checker decides whether a request is allowed.
+type Checker interface {
+	Check(req Request) error
+}
+
+// DeniedError reports a rejected request.
+type DeniedError struct {
+	Reason  string
+	Request Request
+}
+
+// Error implements the error interface.
+func (e *DeniedError) Error() string {
+	target := e.Request.Table
+	if e.Request.Index != "" {
+		target += "/" + e.Request.Index
+	}
+	return fmt.Sprintf("dynamorm: %s on %s denied: %s", e.Request.Action, target, e.Reason)
+}
+
+// Unwrap returns ErrDenied.
+func (e *DeniedError) Unwrap() error {
+	return ErrDenied
+}
+
+// Rule requires every leading key to be built from the request's tenant. Template is
+// the expected partition key with TenantPlaceholder standing for the tenant, such as
+// "TENANT#{tenant}"; an empty template means the partition key is the tenant itself.
+// With Prefix, keys may continue after the rendered template ("TENANT#t1#ORDER#9"),
+// matching a StringLike "TENANT#${aws:PrincipalTag/tenant}*" policy condition.
+//
+// Requests without a tenant in their context, and scans, which cannot name a leading
+// key, are denied.
+type Rule struct {
+	Template string
+	Prefix   bool
+}
+
+// Check implements Checker.
+func (r Rule) Check(req Request) error {
+	if req.Tenant == "" {
+		return &DeniedError{Request: req, Reason: "no tenant in request context"}
+	}
+	if len(req.LeadingKeys) == 0 {
+		return &DeniedError{Request: req, Reason: "request does not name a leading key"}
+	}
+
+	template := r.Template
+	if template == "" {
+		template = TenantPlaceholder
+	}
+	expected := strings.ReplaceAll(template, TenantPlaceholder, req.Tenant)
+	for _, key := range req.LeadingKeys {
+		if key == expected || (r.Prefix && strings.HasPrefix(key, expected)) {
+			continue
+		}
+		return &DeniedError{
+			Request: req,
+			Reason:  fmt.Sprintf("leading key %q is not allowed for tenant %q", key, req.Tenant),
+		}
+	}
+	return nil
+}
+
+// All combines checkers; a request must pass every one of them.
+func All(checkers ...Checker) Checker {
+	return allCheckers(checkers)
+}
+
+type allCheckers []Checker
+
+func (c allCheckers) Check(req Request) error {
+	for _, checker := range c {
+		if checker == nil {
+			continue
+		}
+		if err := checker.Check(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package leadingkeys
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantContext(t *testing.T) {
+	_, ok := TenantFromContext(context.Background())
+	require.False(t, ok)
+
+	tenant, ok := TenantFromContext(WithTenant(context.Background(), "t1"))
+	require.True(t, ok)
+	require.Equal(t, "t1", tenant)
+}
+
+func TestRule(t *testing.T) {
+	rule := Rule{Template: "TENANT#{tenant}", Prefix: true}
+
+	require.NoError(t, rule.Check(Request{Action: "dynamodb:GetItem", Tenant: "t1", LeadingKeys: []string{"TENANT#t1"}}))
+	require.NoError(t, rule.Check(Request{Action: "dynamodb:Query", Tenant: "t1", LeadingKeys: []string{"TENANT#t1#ORDER"}}))
+
+	err := rule.Check(Request{Action: "dynamodb:GetItem", Table: "orders", Tenant: "t1", LeadingKeys: []string{"TENANT#t2"}})
+	require.ErrorIs(t, err, ErrDenied)
+	require.ErrorContains(t, err, `leading key "TENANT#t2" is not allowed for tenant "t1"`)
+
+	require.ErrorIs(t, rule.Check(Request{Action: "dynamodb:GetItem", LeadingKeys: []string{"TENANT#t1"}}), ErrDenied)
+	require.ErrorIs(t, rule.Check(Request{Action: "dynamodb:Scan", Tenant: "t1"}), ErrDenied)
+
+	exact := Rule{}
+	require.NoError(t, exact.Check(Request{Tenant: "t1", LeadingKeys: []string{"t1"}}))
+	require.ErrorIs(t, exact.Check(Request{Tenant: "t1", LeadingKeys: []string{"t1#x"}}), ErrDenied)
+}
+
+const tenantPolicy = `{
+	"Version": "2012-10-17",
+	"Statement": [
+		{
+			"Effect": "Allow",
+			"Action": ["dynamodb:GetItem", "dynamodb:Query", "dynamodb:PutItem"],
+			"Resource": [
+				"arn:aws:dynamodb:us-east-1:123456789012:table/orders",
+				"arn:aws:dynamodb:us-east-1:123456789012:table/orders/index/*"
+			],
+			"Condition": {
+				"ForAllValues:StringLike": {
+					"dynamodb:LeadingKeys": ["TENANT#${aws:PrincipalTag/tenant}*"]
+				}
+			}
+		},
+		{
+			"Effect": "Deny",
+			"Action": "dynamodb:*",
+			"Resource": "arn:aws:dynamodb:*:*:table/orders/index/gsi-admin"
+		}
+	]
+}`
+
+func TestSimulator(t *testing.T) {
+	sim, err := NewSimulator([]byte(tenantPolicy))
+	require.NoError(t, err)
+	sim.TenantVariable = "aws:PrincipalTag/tenant"
+
+	allowed := Request{Action: "dynamodb:Query", Table: "orders", Tenant: "t1", LeadingKeys: []string{"TENANT#t1#2024"}}
+	require.NoError(t, sim.Check(allowed))
+
+	index := allowed
+	index.Index = "gsi-status"
+	require.NoError(t, sim.Check(index))
+
+	otherTenant := allowed
+	otherTenant.LeadingKeys = []string{"TENANT#t2"}
+	require.ErrorContains(t, sim.Check(otherTenant), "no policy statement allows the request")
+
+	noTenant := allowed
+	noTenant.Tenant = ""
+	require.ErrorIs(t, sim.Check(noTenant), ErrDenied)
+
+	notGranted := allowed
+	notGranted.Action = "dynamodb:DeleteItem"
+	require.ErrorIs(t, sim.Check(notGranted), ErrDenied)
+
+	otherTable := allowed
+	otherTable.Table = "payments"
+	require.ErrorIs(t, sim.Check(otherTable), ErrDenied)
+
+	denied := allowed
+	denied.Index = "gsi-admin"
+	require.ErrorContains(t, sim.Check(denied), "explicitly denied")
+
+	// ForAllValues holds for an empty set, so IAM itself would not stop a key-less request.
+	scan := Request{Action: "dynamodb:Query", Table: "orders", Tenant: "t1"}
+	require.NoError(t, sim.Check(scan))
+
+	sim.Account = "999999999999"
+	require.ErrorIs(t, sim.Check(allowed), ErrDenied)
+}
+
+func TestSimulator_ForAnyValueAndSingleStatement(t *testing.T) {
+	sim, err := NewSimulator([]byte(`{"Statement":{"Effect":"Allow","Action":"dynamodb:Get*","Resource":"*",
+		"Condition":{"ForAnyValue:StringEquals":{"dynamodb:LeadingKeys":"${owner}"}}}}`))
+	require.NoError(t, err)
+	sim.Variables = map[string]string{"owner": "u1"}
+
+	require.NoError(t, sim.Check(Request{Action: "dynamodb:GetItem", Table: "t", LeadingKeys: []string{"u1"}}))
+	require.ErrorIs(t, sim.Check(Request{Action: "dynamodb:GetItem", Table: "t", LeadingKeys: []string{"u2"}}), ErrDenied)
+	require.ErrorIs(t, sim.Check(Request{Action: "dynamodb:GetItem", Table: "t"}), ErrDenied)
+}
+
+func TestNewSimulator_RejectsUnsupportedStatements(t *testing.T) {
+	_, err := NewSimulator([]byte(`{"Statement":[{"Effect":"Allow","NotAction":"dynamodb:Scan","Resource":"*"}]}`))
+	require.ErrorContains(t, err, "NotAction is not supported")
+
+	_, err = NewSimulator([]byte(`{"Statement":[{"Effect":"Allow","Action":"*","Resource":"*",
+		"Condition":{"IpAddress":{"aws:SourceIp":"10.0.0.0/8"}}}]}`))
+	require.ErrorContains(t, err, `condition operator "IpAddress" is not supported`)
+
+	_, err = NewSimulator([]byte(`{"Statement":[{"Effect":"Maybe","Action":"*","Resource":"*"}]}`))
+	require.ErrorContains(t, err, "invalid Effect")
+}
+
+func TestAll(t *testing.T) {
+	sim, err := NewSimulator([]byte(`{"Statement":[{"Effect":"Allow","Action":"*","Resource":"*"}]}`))
+	require.NoError(t, err)
+	checker := All(sim, Rule{})
+
+	require.NoError(t, checker.Check(Request{Tenant: "t1", LeadingKeys: []string{"t1"}}))
+	require.ErrorIs(t, checker.Check(Request{Tenant: "t1", LeadingKeys: []string{"t2"}}), ErrDenied)
+}
+
+func TestGlobMatch(t *testing.T) {
+	require.True(t, globMatch("a*c", "abbbc"))
+	require.True(t, globMatch("a?c", "abc"))
+	require.True(t, globMatch("*", ""))
+	require.False(t, globMatch("a?c", "ac"))
+	require.False(t, globMatch("a*d", "abc"))
+}
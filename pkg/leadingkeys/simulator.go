@@ -0,0 +1,336 @@
+package leadingkeys
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// LeadingKeysCondition is the IAM condition key holding a request's partition key values.
+const LeadingKeysCondition = "dynamodb:LeadingKeys"
+
+// Simulator evaluates requests against an IAM policy document the way IAM would for
+// the statements it understands: Allow and Deny statements with Action and Resource
+// (including * and ? wildcards) and String conditions on dynamodb:LeadingKeys, with
+// ForAllValues and ForAnyValue qualifiers and ${...} policy variables. An explicit Deny
+// wins, and requests no statement allows are denied.
+//
+// It is meant for development and tests; it does not replace IAM. Statements with
+// NotAction, NotResource, or condition operators it does not understand are rejected
+// by NewSimulator rather than silently misread.
+type Simulator struct {
+	// Variables supplies policy variables such as "aws:PrincipalTag/tenant".
+	Variables map[string]string
+	// TenantVariable, when set, names the policy variable that takes the request's
+	// tenant, so one simulator serves every tenant.
+	TenantVariable string
+	// Region and Account are matched against policy resource ARNs; when empty, any
+	// region or account the policy names is accepted.
+	Region  string
+	Account string
+
+	statements []statement
+}
+
+type statement struct {
+	effect     string
+	actions    []string
+	resources  []string
+	conditions []condition
+}
+
+type condition struct {
+	operator  string
+	qualifier string
+	key       string
+	values    []string
+}
+
+// NewSimulator parses an IAM policy document.
+func NewSimulator(policy []byte) (*Simulator, error) {
+	var doc struct {
+		Statement json.RawMessage
+	}
+	if err := json.Unmarshal(policy, &doc); err != nil {
+		return nil, fmt.Errorf("invalid policy document: %w", err)
+	}
+
+	var raw []map[string]json.RawMessage
+	if len(doc.Statement) > 0 && doc.Statement[0] == '{' {
+		var single map[string]json.RawMessage
+		if err := json.Unmarshal(doc.Statement, &single); err != nil {
+			return nil, fmt.Errorf("invalid policy statement: %w", err)
+		}
+		raw = append(raw, single)
+	} else if err := json.Unmarshal(doc.Statement, &raw); err != nil {
+		return nil, fmt.Errorf("invalid policy statements: %w", err)
+	}
+
+	sim := &Simulator{}
+	for i, fields := range raw {
+		stmt, err := parseStatement(fields)
+		if err != nil {
+			return nil, fmt.Errorf("statement %d: %w", i, err)
+		}
+		sim.statements = append(sim.statements, stmt)
+	}
+	return sim, nil
+}
+
+func parseStatement(fields map[string]json.RawMessage) (statement, error) {
+	for _, unsupported := range []string{"NotAction", "NotResource", "NotPrincipal"} {
+		if _, ok := fields[unsupported]; ok {
+			return statement{}, fmt.Errorf("%s is not supported", unsupported)
+		}
+	}
+
+	var stmt statement
+	if err := json.Unmarshal(fields["Effect"], &stmt.effect); err != nil {
+		return stmt, fmt.Errorf("invalid Effect: %w", err)
+	}
+	if stmt.effect != "Allow" && stmt.effect != "Deny" {
+		return stmt, fmt.Errorf("invalid Effect %q", stmt.effect)
+	}
+
+	var err error
+	if stmt.actions, err = stringOrList(fields["Action"]); err != nil {
+		return stmt, fmt.Errorf("invalid Action: %w", err)
+	}
+	if stmt.resources, err = stringOrList(fields["Resource"]); err != nil {
+		return stmt, fmt.Errorf("invalid Resource: %w", err)
+	}
+
+	if rawConditions, ok := fields["Condition"]; ok {
+		var operators map[string]map[string]json.RawMessage
+		if err := json.Unmarshal(rawConditions, &operators); err != nil {
+			return stmt, fmt.Errorf("invalid Condition: %w", err)
+		}
+		for operator, keys := range operators {
+			qualifier, op := "", operator
+			if i := strings.Index(operator, ":"); i >= 0 {
+				qualifier, op = operator[:i], operator[i+1:]
+			}
+			if qualifier != "" && qualifier != "ForAllValues" && qualifier != "ForAnyValue" {
+				return stmt, fmt.Errorf("condition qualifier %q is not supported", qualifier)
+			}
+			switch op {
+			case "StringEquals", "StringNotEquals", "StringLike", "StringNotLike":
+			default:
+				return stmt, fmt.Errorf("condition operator %q is not supported", op)
+			}
+			for key, rawValues := range keys {
+				values, err := stringOrList(rawValues)
+				if err != nil {
+					return stmt, fmt.Errorf("invalid values for %s: %w", key, err)
+				}
+				stmt.conditions = append(stmt.conditions, condition{
+					operator:  op,
+					qualifier: qualifier,
+					key:       key,
+					values:    values,
+				})
+			}
+		}
+	}
+	return stmt, nil
+}
+
+func stringOrList(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}, nil
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Check implements Checker.
+func (s *Simulator) Check(req Request) error {
+	variables := s.variables(req)
+
+	allowed := false
+	for _, stmt := range s.statements {
+		if !s.applies(stmt, req, variables) {
+			continue
+		}
+		if stmt.effect == "Deny" {
+			return &DeniedError{Request: req, Reason: "explicitly denied by policy"}
+		}
+		allowed = true
+	}
+	if !allowed {
+		return &DeniedError{Request: req, Reason: "no policy statement allows the request"}
+	}
+	return nil
+}
+
+// matchesResource matches a policy Resource against the request's table or index. An
+// empty Region or Account accepts whatever the policy names.
+func (s *Simulator) matchesResource(pattern string, req Request) bool {
+	resource := "table/" + req.Table
+	if req.Index != "" {
+		resource += "/index/" + req.Index
+	}
+	if pattern == "*" {
+		return true
+	}
+
+	parts := strings.SplitN(pattern, ":", 6)
+	if len(parts) != 6 {
+		return false
+	}
+	return globMatch(parts[0], "arn") &&
+		strings.HasPrefix(parts[1], "aws") &&
+		globMatch(parts[2], "dynamodb") &&
+		(s.Region == "" || globMatch(parts[3], s.Region)) &&
+		(s.Account == "" || globMatch(parts[4], s.Account)) &&
+		globMatch(parts[5], resource)
+}
+
+func (s *Simulator) variables(req Request) map[string]string {
+	variables := make(map[string]string, len(s.Variables)+1)
+	for name, value := range s.Variables {
+		variables[name] = value
+	}
+	if s.TenantVariable != "" && req.Tenant != "" {
+		variables[s.TenantVariable] = req.Tenant
+	}
+	return variables
+}
+
+func (s *Simulator) applies(stmt statement, req Request, variables map[string]string) bool {
+	if !matchesAction(stmt.actions, req.Action) {
+		return false
+	}
+	resourceMatched := false
+	for _, pattern := range stmt.resources {
+		if s.matchesResource(pattern, req) {
+			resourceMatched = true
+			break
+		}
+	}
+	if !resourceMatched {
+		return false
+	}
+	for _, cond := range stmt.conditions {
+		if !cond.holds(req.LeadingKeys, variables) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAction(patterns []string, action string) bool {
+	for _, pattern := range patterns {
+		if globMatch(strings.ToLower(pattern), strings.ToLower(action)) {
+			return true
+		}
+	}
+	return false
+}
+
+// holds evaluates the condition. Keys other than dynamodb:LeadingKeys are treated as
+// absent from the request, as they are for most DynamoDB data-plane calls.
+func (c condition) holds(leadingKeys []string, variables map[string]string) bool {
+	var requestValues []string
+	if c.key == LeadingKeysCondition {
+		requestValues = leadingKeys
+	}
+
+	negated := c.operator == "StringNotEquals" || c.operator == "StringNotLike"
+	matches := func(value string) bool {
+		for _, pattern := range c.values {
+			expanded, ok := expandVariables(pattern, variables)
+			if !ok {
+				continue
+			}
+			var matched bool
+			if c.operator == "StringLike" || c.operator == "StringNotLike" {
+				matched = globMatch(expanded, value)
+			} else {
+				matched = expanded == value
+			}
+			if matched {
+				return !negated
+			}
+		}
+		return negated
+	}
+
+	switch c.qualifier {
+	case "ForAllValues":
+		// True for an empty set, exactly as IAM evaluates it.
+		for _, value := range requestValues {
+			if !matches(value) {
+				return false
+			}
+		}
+		return true
+	default:
+		for _, value := range requestValues {
+			if matches(value) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// expandVariables substitutes ${name} policy variables; it reports false when a variable
+// is not defined, which makes the value match nothing.
+func expandVariables(pattern string, variables map[string]string) (string, bool) {
+	var b strings.Builder
+	for {
+		start := strings.Index(pattern, "${")
+		if start < 0 {
+			b.WriteString(pattern)
+			return b.String(), true
+		}
+		end := strings.Index(pattern[start:], "}")
+		if end < 0 {
+			b.WriteString(pattern)
+			return b.String(), true
+		}
+		name := pattern[start+2 : start+end]
+		value, ok := variables[name]
+		if !ok {
+			return "", false
+		}
+		b.WriteString(pattern[:start])
+		b.WriteString(value)
+		pattern = pattern[start+end+1:]
+	}
+}
+
+// globMatch reports whether value matches pattern, where * matches any run of
+// characters and ? matches exactly one.
+func globMatch(pattern, value string) bool {
+	p, v := 0, 0
+	star, mark := -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == value[v]):
+			p++
+			v++
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, v
+			p++
+		case star >= 0:
+			p = star + 1
+			mark++
+			v = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
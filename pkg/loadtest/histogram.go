@@ -0,0 +1,80 @@
+package loadtest
+
+import "time"
+
+// Histogram buckets latencies exponentially, doubling from 100µs, so percentiles are
+// accurate to within a factor of two while recording stays constant time.
+type Histogram struct {
+	buckets []int64
+	Count   int64
+	Sum     time.Duration
+	Min     time.Duration
+	Max     time.Duration
+}
+
+const (
+	histogramBase    = 100 * time.Microsecond
+	histogramBuckets = 22 // the last bucket holds everything over ~105s
+)
+
+// bucketBound returns the upper bound of bucket i.
+func bucketBound(i int) time.Duration {
+	return histogramBase << uint(i)
+}
+
+// Observe records a latency.
+func (h *Histogram) Observe(d time.Duration) {
+	if h.buckets == nil {
+		h.buckets = make([]int64, histogramBuckets)
+	}
+	i := 0
+	for i < histogramBuckets-1 && d > bucketBound(i) {
+		i++
+	}
+	h.buckets[i]++
+	if h.Count == 0 || d < h.Min {
+		h.Min = d
+	}
+	if d > h.Max {
+		h.Max = d
+	}
+	h.Count++
+	h.Sum += d
+}
+
+// Mean returns the average latency.
+func (h *Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Percentile returns an upper bound on the latency below which fraction q (0 to 1) of
+// the observations fall: the bound of the bucket holding it, capped at Max.
+func (h *Histogram) Percentile(q float64) time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := int64(q * float64(h.Count))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen int64
+	for i, n := range h.buckets {
+		seen += n
+		if seen >= rank {
+			if bound := bucketBound(i); bound < h.Max && i < histogramBuckets-1 {
+				return bound
+			}
+			return h.Max
+		}
+	}
+	return h.Max
+}
+
+func (h *Histogram) clone() Histogram {
+	c := *h
+	c.buckets = append([]int64(nil), h.buckets...)
+	return c
+}
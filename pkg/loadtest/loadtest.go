@@ -0,0 +1,328 @@
+// Package loadtest drives a weighted mix of reads and writes through DynamORM and reports
+// latency histograms and throttle counts per operation, so capacity planning exercises
+// the same code path, middleware, and retry policy as production:
+//
+//	h := loadtest.New(loadtest.Config{
+//		Concurrency: 16,
+//		Duration:    time.Minute,
+//		Operations: []loadtest.Operation{
+//			{Name: "get-order", Weight: 8, Run: func(ctx context.Context, rng *rand.Rand) error {
+//				var order Order
+//				return db.WithContext(ctx).Model(&Order{}).Where("ID", "=", orderIDs[rng.Intn(len(orderIDs))]).First(&order)
+//			}},
+//			{Name: "create-order", Weight: 2, Run: func(ctx context.Context, rng *rand.Rand) error {
+//				return db.WithContext(ctx).Model(newOrder(rng)).Create()
+//			}},
+//		},
+//	})
+//	db.Use(h.Middleware())
+//	report, err := h.Run(ctx)
+//	fmt.Print(report)
+//
+// SimulateThrottling injects throttling errors without DynamoDB, to rehearse how an
+// application backs off before a table runs out of capacity.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+// Operation is one kind of request in the mix.
+type Operation struct {
+	// Run performs the request. It should pass ctx to DynamORM (db.WithContext(ctx)) so
+	// the harness middleware can attribute retried throttles to the operation. rng is
+	// owned by the calling worker and seeded from Config.Seed, for choosing keys.
+	Run  func(ctx context.Context, rng *rand.Rand) error
+	Name string
+	// Weight is the operation's share of the mix relative to the other weights.
+	Weight int
+}
+
+// Config configures a load test. Duration, Requests, or both bound the run.
+type Config struct {
+	Operations []Operation
+	// Concurrency is the number of workers issuing requests. Zero means one.
+	Concurrency int
+	// Duration stops the run after this long.
+	Duration time.Duration
+	// Requests stops the run after this many requests in total.
+	Requests int64
+	// Rate caps requests per second across all workers. Zero means as fast as the
+	// workers go.
+	Rate float64
+	// Seed seeds the workers' random sources, which pick operations and are passed to
+	// Run. Zero uses the current time.
+	Seed int64
+}
+
+// Harness runs a load test and collects its statistics.
+type Harness struct {
+	stats  map[string]*opStats
+	pacer  *pacer
+	cfg    Config
+	total  int
+	issued atomic.Int64
+}
+
+type operationKey struct{}
+
+// New returns a harness for cfg. Run reports configuration errors.
+func New(cfg Config) *Harness {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	h := &Harness{cfg: cfg, stats: make(map[string]*opStats, len(cfg.Operations))}
+	for _, op := range cfg.Operations {
+		h.stats[op.Name] = &opStats{}
+		if op.Weight > 0 {
+			h.total += op.Weight
+		}
+	}
+	if cfg.Rate > 0 {
+		h.pacer = &pacer{interval: time.Duration(float64(time.Second) / cfg.Rate)}
+	}
+	return h
+}
+
+// Middleware returns DynamORM middleware counting the attempts DynamoDB throttled,
+// including those the SDK retried successfully, against the operation whose context the
+// request carries. Install it with DB.Use before Run, ahead of SimulateThrottling so it
+// sees the simulated throttles too.
+func (h *Harness) Middleware() core.Middleware {
+	return func(next core.Handler) core.Handler {
+		return func(ctx context.Context, op *core.Operation) error {
+			err := next(ctx, op)
+			if name, ok := ctx.Value(operationKey{}).(string); ok && op.Throttles > 0 {
+				if stats := h.stats[name]; stats != nil {
+					stats.recordThrottledAttempts(op.Throttles)
+				}
+			}
+			return err
+		}
+	}
+}
+
+// Run issues requests until ctx ends or the configured duration or request count is
+// reached, then returns the report. Failed requests are counted, not returned.
+func (h *Harness) Run(ctx context.Context) (*Report, error) {
+	if err := h.validate(); err != nil {
+		return nil, err
+	}
+	if h.cfg.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.cfg.Duration)
+		defer cancel()
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for w := 0; w < h.cfg.Concurrency; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			h.work(ctx, rand.New(rand.NewSource(h.cfg.Seed+int64(worker)))) // #nosec G404 -- load mix, not security
+		}(w)
+	}
+	wg.Wait()
+
+	report := &Report{Elapsed: time.Since(start), Operations: make(map[string]Stats, len(h.stats))}
+	for name, stats := range h.stats {
+		report.Operations[name] = stats.snapshot()
+	}
+	return report, nil
+}
+
+func (h *Harness) validate() error {
+	if len(h.cfg.Operations) == 0 {
+		return errors.New("loadtest: no operations configured")
+	}
+	if h.total == 0 {
+		return errors.New("loadtest: operations need a positive weight")
+	}
+	if h.cfg.Duration <= 0 && h.cfg.Requests <= 0 {
+		return errors.New("loadtest: set Duration or Requests to bound the run")
+	}
+	for _, op := range h.cfg.Operations {
+		if op.Run == nil {
+			return fmt.Errorf("loadtest: operation %q has no Run function", op.Name)
+		}
+	}
+	return nil
+}
+
+func (h *Harness) work(ctx context.Context, rng *rand.Rand) {
+	for ctx.Err() == nil {
+		if h.cfg.Requests > 0 && h.issued.Add(1) > h.cfg.Requests {
+			return
+		}
+		if h.pacer != nil && !h.pacer.wait(ctx) {
+			return
+		}
+
+		op := h.pick(rng)
+		opCtx := context.WithValue(ctx, operationKey{}, op.Name)
+		start := time.Now()
+		err := op.Run(opCtx, rng)
+		if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+			// Cut off by the end of the run rather than failed.
+			return
+		}
+		h.stats[op.Name].record(time.Since(start), err)
+	}
+}
+
+func (h *Harness) pick(rng *rand.Rand) Operation {
+	n := rng.Intn(h.total)
+	for _, op := range h.cfg.Operations {
+		if op.Weight <= 0 {
+			continue
+		}
+		if n < op.Weight {
+			return op
+		}
+		n -= op.Weight
+	}
+	return h.cfg.Operations[len(h.cfg.Operations)-1]
+}
+
+// pacer spaces requests interval apart across all workers.
+type pacer struct {
+	next     time.Time
+	interval time.Duration
+	mu       sync.Mutex
+}
+
+func (p *pacer) wait(ctx context.Context) bool {
+	p.mu.Lock()
+	now := time.Now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+	slot := p.next
+	p.next = p.next.Add(p.interval)
+	p.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return true
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// IsThrottle reports whether err is a DynamoDB throttling error, as returned once the
+// SDK gives up retrying or by SimulateThrottling.
+func IsThrottle(err error) bool {
+	return err != nil && retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) == aws.TrueTernary
+}
+
+// Stats are the results for one operation.
+type Stats struct {
+	Latency Histogram
+	// Count is the number of requests issued, failed ones included.
+	Count int64
+	// Errors is the number of requests that failed, throttled ones included.
+	Errors int64
+	// Throttles is the number of requests that failed throttled after the SDK's retries.
+	Throttles int64
+	// ThrottledAttempts is the number of attempts DynamoDB throttled, retried ones
+	// included. It needs the harness middleware.
+	ThrottledAttempts int64
+}
+
+// opStats collects one operation's Stats across workers.
+type opStats struct {
+	stats Stats
+	mu    sync.Mutex
+}
+
+func (s *opStats) record(latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Count++
+	s.stats.Latency.Observe(latency)
+	if err != nil {
+		s.stats.Errors++
+		if IsThrottle(err) {
+			s.stats.Throttles++
+		}
+	}
+}
+
+func (s *opStats) recordThrottledAttempts(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.ThrottledAttempts += int64(n)
+}
+
+func (s *opStats) snapshot() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshot := s.stats
+	snapshot.Latency = s.stats.Latency.clone()
+	return snapshot
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	Operations map[string]Stats
+	Elapsed    time.Duration
+}
+
+// Throughput returns the requests per second the named operation achieved, or all
+// operations when name is empty.
+func (r *Report) Throughput(name string) float64 {
+	if r == nil || r.Elapsed <= 0 {
+		return 0
+	}
+	var count int64
+	for opName, stats := range r.Operations {
+		if name == "" || opName == name {
+			count += stats.Count
+		}
+	}
+	return float64(count) / r.Elapsed.Seconds()
+}
+
+// String formats the report as a table, one row per operation.
+func (r *Report) String() string {
+	names := make([]string, 0, len(r.Operations))
+	for name := range r.Operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-20s %8s %8s %9s %12s %10s %10s %10s %10s\n",
+		"operation", "count", "errors", "throttled", "thr-attempts", "req/s", "p50", "p99", "max")
+	for _, name := range names {
+		stats := r.Operations[name]
+		fmt.Fprintf(&b, "%-20s %8d %8d %9d %12d %10.1f %10s %10s %10s\n",
+			name, stats.Count, stats.Errors, stats.Throttles, stats.ThrottledAttempts, r.Throughput(name),
+			stats.Latency.Percentile(0.50), stats.Latency.Percentile(0.99), stats.Latency.Max)
+	}
+	fmt.Fprintf(&b, "elapsed %s, %.1f req/s\n", r.Elapsed.Round(time.Millisecond), r.Throughput(""))
+	return b.String()
+}
@@ -0,0 +1,189 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+func TestRunHonoursRequestsAndWeights(t *testing.T) {
+	var reads, writes atomic.Int64
+	h := New(Config{
+		Concurrency: 4,
+		Requests:    1000,
+		Seed:        7,
+		Operations: []Operation{
+			{Name: "read", Weight: 9, Run: func(context.Context, *rand.Rand) error { reads.Add(1); return nil }},
+			{Name: "write", Weight: 1, Run: func(context.Context, *rand.Rand) error { writes.Add(1); return nil }},
+			{Name: "disabled", Weight: 0, Run: func(context.Context, *rand.Rand) error { t.Error("zero weight operation ran"); return nil }},
+		},
+	})
+
+	report, err := h.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1000), reads.Load()+writes.Load())
+	assert.Equal(t, reads.Load(), report.Operations["read"].Count)
+	assert.Equal(t, writes.Load(), report.Operations["write"].Count)
+	assert.InDelta(t, 900, reads.Load(), 60)
+	assert.Equal(t, int64(1000), report.Operations["read"].Latency.Count+report.Operations["write"].Latency.Count)
+	assert.Contains(t, report.String(), "read")
+}
+
+func TestRunCountsErrorsAndThrottles(t *testing.T) {
+	throttled := &smithy.GenericAPIError{Code: "ThrottlingException"}
+	var n atomic.Int64
+	h := New(Config{
+		Requests: 30,
+		Operations: []Operation{{Name: "op", Weight: 1, Run: func(context.Context, *rand.Rand) error {
+			switch n.Add(1) % 3 {
+			case 0:
+				return throttled
+			case 1:
+				return errors.New("boom")
+			}
+			return nil
+		}}},
+	})
+
+	report, err := h.Run(context.Background())
+	require.NoError(t, err)
+
+	stats := report.Operations["op"]
+	assert.Equal(t, int64(30), stats.Count)
+	assert.Equal(t, int64(20), stats.Errors)
+	assert.Equal(t, int64(10), stats.Throttles)
+}
+
+func TestMiddlewareAttributesThrottledAttempts(t *testing.T) {
+	var h *Harness
+	send := func(ctx context.Context) error {
+		handler := h.Middleware()(func(_ context.Context, op *core.Operation) error {
+			op.Attempts, op.Throttles = 3, 2
+			return nil
+		})
+		return handler(ctx, &core.Operation{Type: core.OperationGetItem})
+	}
+	h = New(Config{
+		Requests:   5,
+		Operations: []Operation{{Name: "get", Weight: 1, Run: func(ctx context.Context, _ *rand.Rand) error { return send(ctx) }}},
+	})
+
+	report, err := h.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), report.Operations["get"].ThrottledAttempts)
+	assert.Zero(t, report.Operations["get"].Throttles)
+
+	// Requests from outside the harness are not attributed.
+	require.NoError(t, send(context.Background()))
+}
+
+func TestRunStopsAfterDuration(t *testing.T) {
+	h := New(Config{
+		Concurrency: 2,
+		Duration:    50 * time.Millisecond,
+		Operations: []Operation{{Name: "wait", Weight: 1, Run: func(ctx context.Context, _ *rand.Rand) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Millisecond):
+				return nil
+			}
+		}}},
+	})
+
+	report, err := h.Run(context.Background())
+	require.NoError(t, err)
+	assert.Less(t, report.Elapsed, time.Second)
+	assert.Positive(t, report.Operations["wait"].Count)
+	assert.Zero(t, report.Operations["wait"].Errors, "requests cut off by the deadline are not errors")
+	assert.Positive(t, report.Throughput(""))
+}
+
+func TestRunPacesRate(t *testing.T) {
+	h := New(Config{
+		Concurrency: 4,
+		Requests:    6,
+		Rate:        100,
+		Operations:  []Operation{{Name: "op", Weight: 1, Run: func(context.Context, *rand.Rand) error { return nil }}},
+	})
+
+	report, err := h.Run(context.Background())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, report.Elapsed, 45*time.Millisecond)
+}
+
+func TestRunValidatesConfig(t *testing.T) {
+	noop := func(context.Context, *rand.Rand) error { return nil }
+	for name, cfg := range map[string]Config{
+		"no operations": {Requests: 1},
+		"no weight":     {Requests: 1, Operations: []Operation{{Name: "op", Run: noop}}},
+		"unbounded":     {Operations: []Operation{{Name: "op", Weight: 1, Run: noop}}},
+		"no run":        {Requests: 1, Operations: []Operation{{Name: "op", Weight: 1}}},
+	} {
+		_, err := New(cfg).Run(context.Background())
+		assert.Error(t, err, name)
+	}
+}
+
+func TestSimulateThrottling(t *testing.T) {
+	var sent int
+	handler := SimulateThrottling(0.25, 42)(func(context.Context, *core.Operation) error {
+		sent++
+		return nil
+	})
+
+	var throttled int
+	for i := 0; i < 400; i++ {
+		op := &core.Operation{Type: core.OperationPutItem, Table: "orders"}
+		err := handler(context.Background(), op)
+		if err == nil {
+			assert.Zero(t, op.Throttles)
+			continue
+		}
+		throttled++
+		assert.True(t, IsThrottle(err))
+		assert.Equal(t, 1, op.Throttles)
+		assert.True(t, strings.Contains(err.Error(), ThrottleErrorCode))
+	}
+	assert.Equal(t, 400, sent+throttled)
+	assert.InDelta(t, 100, throttled, 30)
+
+	never := SimulateThrottling(0, 1)(func(context.Context, *core.Operation) error { return nil })
+	assert.NoError(t, never(context.Background(), &core.Operation{}))
+}
+
+func TestHistogramPercentiles(t *testing.T) {
+	var h Histogram
+	assert.Zero(t, h.Percentile(0.5))
+	assert.Zero(t, h.Mean())
+
+	for i := 0; i < 90; i++ {
+		h.Observe(150 * time.Microsecond)
+	}
+	for i := 0; i < 10; i++ {
+		h.Observe(30 * time.Millisecond)
+	}
+
+	assert.Equal(t, int64(100), h.Count)
+	assert.Equal(t, 150*time.Microsecond, h.Min)
+	assert.Equal(t, 30*time.Millisecond, h.Max)
+	assert.Equal(t, 200*time.Microsecond, h.Percentile(0.5))
+	assert.Equal(t, 30*time.Millisecond, h.Percentile(0.99))
+	assert.Equal(t, (90*150*time.Microsecond+10*30*time.Millisecond)/100, h.Mean())
+
+	clone := h.clone()
+	h.Observe(time.Minute)
+	assert.Equal(t, int64(100), clone.Count)
+	assert.Equal(t, 30*time.Millisecond, clone.Percentile(1))
+}
@@ -0,0 +1,48 @@
+package loadtest
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+
+	"github.com/aws/smithy-go"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+// ThrottleErrorCode is the error code of the errors SimulateThrottling returns.
+const ThrottleErrorCode = "ProvisionedThroughputExceededException"
+
+// SimulateThrottling returns DynamORM middleware that fails the given fraction (0 to 1)
+// of operations with a ProvisionedThroughputExceededException before they reach
+// DynamoDB, counting each as a throttled attempt. The SDK does not retry these, so the
+// application sees them as it would once a table's capacity is exhausted. seed makes
+// the choice of failed operations repeatable; zero picks them unpredictably.
+//
+//	db.Use(loadtest.SimulateThrottling(0.05, 1))
+func SimulateThrottling(fraction float64, seed int64) core.Middleware {
+	src := rand.NewSource(seed) // #nosec G404 -- fault injection, not security
+	if seed == 0 {
+		src = rand.NewSource(rand.Int63()) // #nosec G404 -- fault injection, not security
+	}
+	rng := rand.New(src) // #nosec G404 -- fault injection, not security
+	var mu sync.Mutex
+
+	return func(next core.Handler) core.Handler {
+		return func(ctx context.Context, op *core.Operation) error {
+			mu.Lock()
+			throttle := rng.Float64() < fraction
+			mu.Unlock()
+			if !throttle {
+				return next(ctx, op)
+			}
+			op.Attempts++
+			op.Throttles++
+			return &smithy.GenericAPIError{
+				Code:    ThrottleErrorCode,
+				Message: "simulated throttle on " + op.Table,
+				Fault:   smithy.FaultClient,
+			}
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Package maintenance freezes individual tables during maintenance windows, such as a
+// migration or a restore, without redeploying the services that use them.
+//
+// A Registry holds a Mode per table. ReadOnly rejects writes to the table and Offline
+// rejects all of its traffic; rejected requests fail with errors.ErrMaintenanceMode
+// before anything is sent to DynamoDB. Modes change at runtime through Set, through the
+// HTTP control endpoint served by Handler, or by polling a shared source with Poll so
+// that every instance of a service picks up the same modes:
+//
+//	db.Maintenance().Set("orders", maintenance.ReadOnly)
+//	defer db.Maintenance().Set("orders", maintenance.Normal)
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// Mode is the traffic a table accepts.
+type Mode string
+
+const (
+	// Normal accepts all traffic. It is the zero Mode.
+	Normal Mode = ""
+	// ReadOnly rejects writes and accepts reads.
+	ReadOnly Mode = "read-only"
+	// Offline rejects reads and writes.
+	Offline Mode = "offline"
+)
+
+// ParseMode parses "read-only", "offline", or "normal" (or "") into a Mode.
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case Normal, "normal":
+		return Normal, nil
+	case ReadOnly, Offline:
+		return Mode(s), nil
+	default:
+		return Normal, fmt.Errorf("unknown maintenance mode %q", s)
+	}
+}
+
+// String returns "normal" for Normal and the mode's name otherwise.
+func (m Mode) String() string {
+	if m == Normal {
+		return "normal"
+	}
+	return string(m)
+}
+
+// Registry holds the maintenance mode of each table. It is safe for concurrent use.
+type Registry struct {
+	modes map[string]Mode
+	hook  func(table string, mode Mode)
+	mu    sync.RWMutex
+}
+
+// NewRegistry creates a registry with every table in Normal mode.
+func NewRegistry() *Registry {
+	return &Registry{modes: make(map[string]Mode)}
+}
+
+// SetHook registers a function called whenever a table's mode changes, e.g. to log it.
+func (r *Registry) SetHook(hook func(table string, mode Mode)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hook = hook
+}
+
+// Set changes the mode of table. Setting Normal removes it from the registry.
+func (r *Registry) Set(table string, mode Mode) {
+	r.mu.Lock()
+	previous := r.modes[table]
+	if mode == Normal {
+		delete(r.modes, table)
+	} else {
+		r.modes[table] = mode
+	}
+	hook := r.hook
+	r.mu.Unlock()
+
+	if hook != nil && previous != mode {
+		hook(table, mode)
+	}
+}
+
+// Replace sets the mode of every table at once: tables missing from modes return to
+// Normal. Poll uses it to apply the modes read from a shared source.
+func (r *Registry) Replace(modes map[string]Mode) {
+	next := make(map[string]Mode, len(modes))
+	for table, mode := range modes {
+		if mode != Normal {
+			next[table] = mode
+		}
+	}
+
+	r.mu.Lock()
+	previous := r.modes
+	r.modes = next
+	hook := r.hook
+	r.mu.Unlock()
+
+	if hook == nil {
+		return
+	}
+	for table, mode := range previous {
+		if next[table] != mode {
+			hook(table, next[table])
+		}
+	}
+	for table, mode := range next {
+		if _, ok := previous[table]; !ok {
+			hook(table, mode)
+		}
+	}
+}
+
+// Mode returns the mode of table.
+func (r *Registry) Mode(table string) Mode {
+	if r == nil {
+		return Normal
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.modes[table]
+}
+
+// Modes returns the tables that are not in Normal mode.
+func (r *Registry) Modes() map[string]Mode {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make(map[string]Mode, len(r.modes))
+	for table, mode := range r.modes {
+		out[table] = mode
+	}
+	return out
+}
+
+// Check returns an error wrapping errors.ErrMaintenanceMode if table does not accept
+// the request: any request while it is Offline, or a write while it is ReadOnly.
+func (r *Registry) Check(table string, write bool) error {
+	mode := r.Mode(table)
+	if mode == Offline || (mode == ReadOnly && write) {
+		return fmt.Errorf("%w: table %s is %s", customerrors.ErrMaintenanceMode, table, mode)
+	}
+	return nil
+}
+
+// Poll calls load every interval and applies the modes it returns with Replace, until
+// ctx ends. load typically reads a parameter store entry or a control table shared by
+// every instance of the service. A failed load keeps the current modes and is passed
+// to onError, if set. Poll loads once before waiting and blocks; run it in a goroutine.
+func (r *Registry) Poll(ctx context.Context, interval time.Duration, load func(ctx context.Context) (map[string]Mode, error), onError func(error)) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		modes, err := load(ctx)
+		switch {
+		case err != nil && onError != nil:
+			onError(err)
+		case err == nil:
+			r.Replace(modes)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Handler returns an HTTP control endpoint for the registry. GET returns the tables
+// not in Normal mode as a JSON object. PUT or POST with the table and mode query
+// parameters sets a table's mode and returns the updated object:
+//
+//	curl -X PUT 'localhost:8080/maintenance?table=orders&mode=read-only'
+//	curl -X PUT 'localhost:8080/maintenance?table=orders&mode=normal'
+//
+// The endpoint freezes tables for every request the service makes; mount it only on an
+// internal listener, behind authentication.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			table := req.URL.Query().Get("table")
+			if table == "" {
+				http.Error(w, "table is required", http.StatusBadRequest)
+				return
+			}
+			mode, err := ParseMode(req.URL.Query().Get("mode"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			r.Set(table, mode)
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(r.Modes())
+	})
+}
@@ -0,0 +1,120 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+func TestRegistryCheck(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Check("orders", true))
+
+	r.Set("orders", ReadOnly)
+	require.NoError(t, r.Check("orders", false))
+	err := r.Check("orders", true)
+	require.ErrorIs(t, err, customerrors.ErrMaintenanceMode)
+	require.EqualError(t, err, "table in maintenance mode: table orders is read-only")
+
+	r.Set("orders", Offline)
+	require.ErrorIs(t, r.Check("orders", false), customerrors.ErrMaintenanceMode)
+	require.NoError(t, r.Check("customers", true))
+
+	r.Set("orders", Normal)
+	require.NoError(t, r.Check("orders", true))
+	require.Empty(t, r.Modes())
+
+	var nilRegistry *Registry
+	require.NoError(t, nilRegistry.Check("orders", true))
+}
+
+func TestRegistryReplaceReportsChanges(t *testing.T) {
+	r := NewRegistry()
+	r.Set("orders", ReadOnly)
+	r.Set("customers", Offline)
+
+	changes := map[string]Mode{}
+	r.SetHook(func(table string, mode Mode) { changes[table] = mode })
+	r.Replace(map[string]Mode{"orders": ReadOnly, "invoices": Offline, "refunds": Normal})
+
+	require.Equal(t, map[string]Mode{"orders": ReadOnly, "invoices": Offline}, r.Modes())
+	require.Equal(t, map[string]Mode{"customers": Normal, "invoices": Offline}, changes)
+}
+
+func TestParseMode(t *testing.T) {
+	for input, want := range map[string]Mode{"": Normal, "normal": Normal, "read-only": ReadOnly, "offline": Offline} {
+		mode, err := ParseMode(input)
+		require.NoError(t, err)
+		require.Equal(t, want, mode)
+	}
+	_, err := ParseMode("frozen")
+	require.ErrorContains(t, err, `unknown maintenance mode "frozen"`)
+	require.Equal(t, "normal", Normal.String())
+}
+
+func TestRegistryHandler(t *testing.T) {
+	r := NewRegistry()
+	handler := r.Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/?table=orders&mode=read-only", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var modes map[string]Mode
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &modes))
+	require.Equal(t, map[string]Mode{"orders": ReadOnly}, modes)
+	require.Equal(t, ReadOnly, r.Mode("orders"))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/?table=orders&mode=paused", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/?mode=offline", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.JSONEq(t, `{"orders":"read-only"}`, rec.Body.String())
+}
+
+func TestRegistryPoll(t *testing.T) {
+	r := NewRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	loads := 0
+	var loadErrs []error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Poll(ctx, time.Millisecond, func(context.Context) (map[string]Mode, error) {
+			loads++
+			switch loads {
+			case 1:
+				return map[string]Mode{"orders": Offline}, nil
+			case 2:
+				return nil, errors.New("parameter store unavailable")
+			default:
+				cancel()
+				return map[string]Mode{}, nil
+			}
+		}, func(err error) { loadErrs = append(loadErrs, err) })
+	}()
+	<-done
+
+	require.Equal(t, 3, loads)
+	require.Len(t, loadErrs, 1)
+	require.Empty(t, r.Modes(), "the last successful load cleared the modes")
+}
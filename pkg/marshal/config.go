@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"reflect"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
 	"github.com/pay-theory/dynamorm/pkg/model"
+	"github.com/pay-theory/dynamorm/pkg/naming"
 	pkgTypes "github.com/pay-theory/dynamorm/pkg/types"
 )
 
@@ -31,6 +33,12 @@ type MarshalerInterface interface {
 	MarshalItem(model any, metadata *model.Metadata) (map[string]types.AttributeValue, error)
 }
 
+// NestedNamer is implemented by marshalers that can report the attribute name they store
+// a field of a nested struct under, so document paths can be resolved the same way.
+type NestedNamer interface {
+	NestedAttributeName(field reflect.StructField, convention naming.Convention) string
+}
+
 // Config holds marshaler configuration with security defaults
 type Config struct {
 	// MarshalerType specifies which marshaler to use (default: safe)
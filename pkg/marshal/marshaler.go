@@ -43,6 +43,7 @@ type fieldMarshaler struct {
 	index       int
 	offset      uintptr
 	omitEmpty   bool
+	allowNull   bool
 	isSet       bool
 	isCreatedAt bool
 	isUpdatedAt bool
@@ -84,6 +85,15 @@ func (m *Marshaler) ClearCache() {
 	})
 }
 
+// InvalidateType removes the cached struct marshaler for typ, so the next marshal of
+// that type is rebuilt from the metadata it is given.
+func (m *Marshaler) InvalidateType(typ reflect.Type) {
+	if m == nil {
+		return
+	}
+	m.cache.Delete(typ)
+}
+
 func derefStructValue(model any) (reflect.Value, error) {
 	v := reflect.ValueOf(model)
 	if v.Kind() == reflect.Ptr {
@@ -125,7 +135,7 @@ func (m *Marshaler) MarshalItem(model any, metadata *model.Metadata) (map[string
 	if nowFn == nil {
 		nowFn = time.Now
 	}
-	nowStr := nowTimestampIfNeeded(sm.fields, nowFn)
+	nowStr := nowTimestampIfNeeded(sm.fields, nowFn, m.converter)
 
 	if err := m.marshalStructFields(ptr, sm.fields, result, nowStr); err != nil {
 		return nil, err
@@ -161,10 +171,10 @@ func structUnsafePointer(v reflect.Value) unsafe.Pointer {
 	return unsafe.Pointer(vcopy.UnsafeAddr()) // #nosec G103 -- performance-critical marshaling uses verified field offsets
 }
 
-func nowTimestampIfNeeded(fields []fieldMarshaler, now func() time.Time) string {
+func nowTimestampIfNeeded(fields []fieldMarshaler, now func() time.Time, converter *pkgTypes.Converter) string {
 	for _, fm := range fields {
 		if fm.isCreatedAt || fm.isUpdatedAt {
-			return now().Format(time.RFC3339Nano)
+			return converter.FormatTime(now())
 		}
 	}
 	return ""
@@ -193,6 +203,11 @@ func (m *Marshaler) marshalStructFields(ptr unsafe.Pointer, fields []fieldMarsha
 		}
 
 		fieldPtr := unsafe.Add(ptr, fm.offset)
+		if fm.allowNull && *(*unsafe.Pointer)(fieldPtr) == nil {
+			result[fm.dbName] = &types.AttributeValueMemberNULL{Value: true}
+			continue
+		}
+
 		av, err := fm.marshalFunc(fieldPtr)
 		if err != nil {
 			return fmt.Errorf("field %s: %w", fm.dbName, err)
@@ -236,6 +251,7 @@ func (m *Marshaler) buildStructMarshaler(typ reflect.Type, metadata *model.Metad
 			offset:      fieldOffsetForIndexPath(typ, fieldMeta.IndexPath),
 			typ:         field.Type,
 			omitEmpty:   fieldMeta.OmitEmpty,
+			allowNull:   fieldMeta.AllowNull,
 			isSet:       fieldMeta.IsSet,
 			isCreatedAt: fieldMeta.IsCreatedAt,
 			isUpdatedAt: fieldMeta.IsUpdatedAt,
@@ -348,7 +364,7 @@ func (m *Marshaler) buildStructMarshalFunc(typ reflect.Type, fieldMeta *model.Fi
 			if fieldMeta.IsTTL {
 				return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.Unix(), 10)}, nil
 			}
-			return &types.AttributeValueMemberS{Value: t.Format(time.RFC3339Nano)}, nil
+			return &types.AttributeValueMemberS{Value: m.converter.FormatTime(t)}, nil
 		}
 	}
 
@@ -356,6 +372,21 @@ func (m *Marshaler) buildStructMarshalFunc(typ reflect.Type, fieldMeta *model.Fi
 }
 
 func (m *Marshaler) buildSliceMarshalFunc(typ reflect.Type, fieldMeta *model.FieldMetadata) func(unsafe.Pointer) (types.AttributeValue, error) {
+	if typ.Elem().Kind() == reflect.Uint8 {
+		return func(ptr unsafe.Pointer) (types.AttributeValue, error) {
+			b := reflect.NewAt(typ, ptr).Elem().Bytes()
+			if b == nil || (len(b) == 0 && fieldMeta.OmitEmpty) {
+				return &types.AttributeValueMemberNULL{Value: true}, nil
+			}
+			return &types.AttributeValueMemberB{Value: b}, nil
+		}
+	}
+	if fieldMeta.IsSet && typ.Elem().Kind() == reflect.Slice && typ.Elem().Elem().Kind() == reflect.Uint8 {
+		return func(ptr unsafe.Pointer) (types.AttributeValue, error) {
+			return marshalBinarySet(reflect.NewAt(typ, ptr).Elem())
+		}
+	}
+
 	if typ.Elem().Kind() == reflect.String {
 		if fieldMeta.IsSet {
 			return func(ptr unsafe.Pointer) (types.AttributeValue, error) {
@@ -447,6 +478,10 @@ func (m *Marshaler) marshalSliceComplex(v reflect.Value) (types.AttributeValue,
 		return &types.AttributeValueMemberNULL{Value: true}, nil
 	}
 
+	if v.Type().Elem().Kind() == reflect.Uint8 {
+		return &types.AttributeValueMemberB{Value: v.Bytes()}, nil
+	}
+
 	list := make([]types.AttributeValue, v.Len())
 	for i := 0; i < v.Len(); i++ {
 		elem := v.Index(i)
@@ -486,7 +521,7 @@ func (m *Marshaler) marshalStructComplex(v reflect.Value) (types.AttributeValue,
 		if t.IsZero() {
 			return &types.AttributeValueMemberNULL{Value: true}, nil
 		}
-		return &types.AttributeValueMemberS{Value: t.Format(time.RFC3339Nano)}, nil
+		return &types.AttributeValueMemberS{Value: m.converter.FormatTime(t)}, nil
 	}
 
 	return m.marshalStructAsMap(v)
@@ -526,6 +561,15 @@ func (m *Marshaler) marshalStructAsMap(v reflect.Value) (types.AttributeValue, e
 	return &types.AttributeValueMemberM{Value: structMap}, nil
 }
 
+// NestedAttributeName returns the name marshalStructAsMap stores field under: its json
+// tag name, or the field name in the model's naming convention.
+func (m *Marshaler) NestedAttributeName(field reflect.StructField, convention naming.Convention) string {
+	if jsonTag := field.Tag.Get("json"); jsonTag != "" && jsonTag != "-" {
+		return jsonTagName(jsonTag)
+	}
+	return naming.ConvertAttrName(field.Name, convention)
+}
+
 func jsonTagName(tag string) string {
 	commaIdx := strings.IndexByte(tag, ',')
 	if commaIdx > 0 {
@@ -592,6 +636,19 @@ func (m *Marshaler) marshalInterfaceValue(v reflect.Value) (types.AttributeValue
 	return m.marshalValue(elem)
 }
 
+// marshalBinarySet marshals a [][]byte field tagged set as a BS attribute. Empty sets
+// are stored as NULL, since DynamoDB rejects them.
+func marshalBinarySet(v reflect.Value) (types.AttributeValue, error) {
+	if v.Len() == 0 {
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	}
+	set := make([][]byte, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		set[i] = v.Index(i).Bytes()
+	}
+	return &types.AttributeValueMemberBS{Value: set}, nil
+}
+
 func marshalFloatNumber(v reflect.Value) types.AttributeValue {
 	bitSize := 64
 	if v.Kind() == reflect.Float32 {
@@ -120,6 +120,10 @@ func withOmitEmpty() func(*model.FieldMetadata) {
 	return func(fm *model.FieldMetadata) { fm.OmitEmpty = true }
 }
 
+func withAllowNull() func(*model.FieldMetadata) {
+	return func(fm *model.FieldMetadata) { fm.AllowNull = true }
+}
+
 // Helper to create metadata
 func createMetadata(fields ...*model.FieldMetadata) *model.Metadata {
 	metadata := &model.Metadata{
@@ -753,6 +757,81 @@ func TestMarshalItem_EmptySetEncodesNullWithoutOmitEmpty(t *testing.T) {
 	require.True(t, isNull, "expected NULL for empty set, got %T", av)
 }
 
+func TestMarshalItem_BinaryAndBinarySets(t *testing.T) {
+	type BinaryStruct struct {
+		ID     string   `dynamodb:"id"`
+		Data   []byte   `dynamodb:"data"`
+		Hashes [][]byte `dynamodb:"hashes,set"`
+		Parts  [][]byte `dynamodb:"parts"`
+		Empty  [][]byte `dynamodb:"empty,set"`
+	}
+
+	input := BinaryStruct{
+		ID:     "test-id",
+		Data:   []byte{1, 2},
+		Hashes: [][]byte{{1}, {2}},
+		Parts:  [][]byte{{3}},
+		Empty:  [][]byte{},
+	}
+	structType := reflect.TypeOf(BinaryStruct{})
+	metadata := createMetadata(
+		createFieldMetadata(structType, "ID", "id", reflect.TypeOf("")),
+		createFieldMetadata(structType, "Data", "data", reflect.TypeOf([]byte{})),
+		createFieldMetadata(structType, "Hashes", "hashes", reflect.TypeOf([][]byte{}), withSet()),
+		createFieldMetadata(structType, "Parts", "parts", reflect.TypeOf([][]byte{})),
+		createFieldMetadata(structType, "Empty", "empty", reflect.TypeOf([][]byte{}), withSet()),
+	)
+
+	for name, marshal := range map[string]func(any, *model.Metadata) (map[string]types.AttributeValue, error){
+		"fast": New(nil).MarshalItem,
+		"safe": NewSafeMarshaler().MarshalItem,
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := marshal(input, metadata)
+			require.NoError(t, err)
+			assert.Equal(t, &types.AttributeValueMemberB{Value: []byte{1, 2}}, result["data"])
+			assert.Equal(t, &types.AttributeValueMemberBS{Value: [][]byte{{1}, {2}}}, result["hashes"])
+			assert.Equal(t, &types.AttributeValueMemberL{Value: []types.AttributeValue{
+				&types.AttributeValueMemberB{Value: []byte{3}},
+			}}, result["parts"])
+			assert.Equal(t, &types.AttributeValueMemberNULL{Value: true}, result["empty"])
+		})
+	}
+}
+
+func TestMarshalItem_AllowNullWritesNilPointersAsNull(t *testing.T) {
+	type NullableStruct struct {
+		Nickname *string `dynamodb:"nickname"`
+		Title    *string `dynamodb:"title"`
+		Label    *string `dynamodb:"label"`
+		ID       string  `dynamodb:"id"`
+	}
+
+	label := "set"
+	input := NullableStruct{ID: "test-id", Label: &label}
+	structType := reflect.TypeOf(NullableStruct{})
+	stringPtr := reflect.TypeOf((*string)(nil))
+	metadata := createMetadata(
+		createFieldMetadata(structType, "ID", "id", reflect.TypeOf("")),
+		createFieldMetadata(structType, "Nickname", "nickname", stringPtr, withOmitEmpty(), withAllowNull()),
+		createFieldMetadata(structType, "Title", "title", stringPtr, withOmitEmpty()),
+		createFieldMetadata(structType, "Label", "label", stringPtr, withOmitEmpty(), withAllowNull()),
+	)
+
+	for name, marshal := range map[string]func(any, *model.Metadata) (map[string]types.AttributeValue, error){
+		"fast": New(nil).MarshalItem,
+		"safe": NewSafeMarshaler().MarshalItem,
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := marshal(input, metadata)
+			require.NoError(t, err)
+			assert.Equal(t, &types.AttributeValueMemberNULL{Value: true}, result["nickname"])
+			assert.NotContains(t, result, "title")
+			assert.Equal(t, &types.AttributeValueMemberS{Value: "set"}, result["label"])
+		})
+	}
+}
+
 func TestMarshalItem_DeepNestedStructures(t *testing.T) {
 	marshaler := New(nil)
 
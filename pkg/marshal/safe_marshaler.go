@@ -11,6 +11,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
 	"github.com/pay-theory/dynamorm/pkg/model"
+	"github.com/pay-theory/dynamorm/pkg/naming"
 	pkgTypes "github.com/pay-theory/dynamorm/pkg/types"
 )
 
@@ -36,6 +37,7 @@ type safeFieldMarshaler struct {
 	dbName      string
 	fieldIndex  []int
 	omitEmpty   bool
+	allowNull   bool
 	isSet       bool
 	isCreatedAt bool
 	isUpdatedAt bool
@@ -67,11 +69,20 @@ func (m *SafeMarshaler) MarshalItem(model any, metadata *model.Metadata) (map[st
 	if nowFn == nil {
 		nowFn = time.Now
 	}
-	nowStr := nowTimestampIfSafeNeeded(sm.fields, nowFn)
+	nowStr := nowTimestampIfSafeNeeded(sm.fields, nowFn, m.converter)
 
 	return m.marshalSafeStructFields(v, sm.fields, sm.minFields, nowStr)
 }
 
+// InvalidateType removes the cached struct marshaler for typ, so the next marshal of
+// that type is rebuilt from the metadata it is given.
+func (m *SafeMarshaler) InvalidateType(typ reflect.Type) {
+	if m == nil {
+		return
+	}
+	m.cache.Delete(typ)
+}
+
 func (m *SafeMarshaler) getOrBuildSafeStructMarshaler(typ reflect.Type, metadata *model.Metadata) *safeStructMarshaler {
 	cached, ok := m.cache.Load(typ)
 	if !ok {
@@ -90,10 +101,10 @@ func (m *SafeMarshaler) getOrBuildSafeStructMarshaler(typ reflect.Type, metadata
 	return sm
 }
 
-func nowTimestampIfSafeNeeded(fields []safeFieldMarshaler, now func() time.Time) string {
+func nowTimestampIfSafeNeeded(fields []safeFieldMarshaler, now func() time.Time, converter *pkgTypes.Converter) string {
 	for _, fm := range fields {
 		if fm.isCreatedAt || fm.isUpdatedAt {
-			return now().Format(time.RFC3339Nano)
+			return converter.FormatTime(now())
 		}
 	}
 	return ""
@@ -125,6 +136,11 @@ func (m *SafeMarshaler) marshalSafeStructFields(
 			continue
 		}
 
+		if fm.allowNull && field.IsNil() {
+			result[fm.dbName] = &types.AttributeValueMemberNULL{Value: true}
+			continue
+		}
+
 		av, err := m.marshalValue(field, fm)
 		if err != nil {
 			return nil, fmt.Errorf("field %s: %w", fm.dbName, err)
@@ -169,6 +185,7 @@ func (m *SafeMarshaler) buildSafeStructMarshaler(typ reflect.Type, metadata *mod
 			dbName:      fieldMeta.DBName,
 			typ:         field.Type,
 			omitEmpty:   fieldMeta.OmitEmpty,
+			allowNull:   fieldMeta.AllowNull,
 			isSet:       fieldMeta.IsSet,
 			isCreatedAt: fieldMeta.IsCreatedAt,
 			isUpdatedAt: fieldMeta.IsUpdatedAt,
@@ -242,7 +259,7 @@ func (m *SafeMarshaler) marshalTimeValue(v reflect.Value, fieldMeta *safeFieldMa
 	if fieldMeta.isTTL {
 		return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.Unix(), 10)}, true, nil
 	}
-	return &types.AttributeValueMemberS{Value: t.Format(time.RFC3339Nano)}, true, nil
+	return &types.AttributeValueMemberS{Value: m.converter.FormatTime(t)}, true, nil
 }
 
 func (m *SafeMarshaler) marshalValueByKind(v reflect.Value, fieldMeta *safeFieldMarshaler) (types.AttributeValue, error) {
@@ -279,7 +296,7 @@ func (m *SafeMarshaler) marshalStructValue(v reflect.Value, fieldMeta *safeField
 		if fieldMeta.isTTL {
 			return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.Unix(), 10)}, nil
 		}
-		return &types.AttributeValueMemberS{Value: t.Format(time.RFC3339Nano)}, nil
+		return &types.AttributeValueMemberS{Value: m.converter.FormatTime(t)}, nil
 	}
 
 	return m.marshalStruct(v)
@@ -290,7 +307,18 @@ func (m *SafeMarshaler) marshalSliceValue(v reflect.Value, fieldMeta *safeFieldM
 		return &types.AttributeValueMemberNULL{Value: true}, nil
 	}
 
-	if v.Type().Elem().Kind() == reflect.String && fieldMeta.isSet {
+	elemKind := v.Type().Elem().Kind()
+	if elemKind == reflect.Uint8 {
+		if v.Len() == 0 && fieldMeta.omitEmpty {
+			return &types.AttributeValueMemberNULL{Value: true}, nil
+		}
+		return &types.AttributeValueMemberB{Value: v.Bytes()}, nil
+	}
+	if fieldMeta.isSet && elemKind == reflect.Slice && v.Type().Elem().Elem().Kind() == reflect.Uint8 {
+		return marshalBinarySet(v)
+	}
+
+	if elemKind == reflect.String && fieldMeta.isSet {
 		if v.Len() == 0 {
 			return &types.AttributeValueMemberNULL{Value: true}, nil
 		}
@@ -345,6 +373,12 @@ func (m *SafeMarshaler) marshalMap(v reflect.Value) (types.AttributeValue, error
 	return &types.AttributeValueMemberM{Value: avMap}, nil
 }
 
+// NestedAttributeName returns the name marshalStruct stores field under, which is always
+// the Go field name.
+func (m *SafeMarshaler) NestedAttributeName(field reflect.StructField, _ naming.Convention) string {
+	return field.Name
+}
+
 // marshalStruct safely marshals a struct as a map
 func (m *SafeMarshaler) marshalStruct(v reflect.Value) (types.AttributeValue, error) {
 	structMap := make(map[string]types.AttributeValue)
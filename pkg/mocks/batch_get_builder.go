@@ -59,6 +59,12 @@ func (m *MockBatchGetBuilder) OnError(handler core.BatchChunkErrorHandler) core.
 	return args.Get(0).(core.BatchGetBuilder)
 }
 
+// OnMissing registers a handler for keys without an item.
+func (m *MockBatchGetBuilder) OnMissing(handler core.BatchMissingKeysHandler) core.BatchGetBuilder {
+	args := m.Called(handler)
+	return args.Get(0).(core.BatchGetBuilder)
+}
+
 // Execute performs the batch get operation.
 func (m *MockBatchGetBuilder) Execute(dest any) error {
 	args := m.Called(dest)
@@ -23,6 +23,7 @@ func TestMockBatchGetBuilder_FluentMethods(t *testing.T) {
 	builder.On("Select", []string{"a", "b"}).Return(builder).Once()
 	builder.On("OnProgress", mock.Anything).Return(builder).Once()
 	builder.On("OnError", mock.Anything).Return(builder).Once()
+	builder.On("OnMissing", mock.Anything).Return(builder).Once()
 	builder.On("Execute", mock.Anything).Return(nil).Once()
 
 	require.Same(t, builder, builder.Keys(keys))
@@ -33,6 +34,7 @@ func TestMockBatchGetBuilder_FluentMethods(t *testing.T) {
 	require.Same(t, builder, builder.Select("a", "b"))
 	require.Same(t, builder, builder.OnProgress(func(int, int) {}))
 	require.Same(t, builder, builder.OnError(func([]any, error) error { return nil }))
+	require.Same(t, builder, builder.OnMissing(func([]any) {}))
 	require.NoError(t, builder.Execute(&[]any{}))
 
 	builder.AssertExpectations(t)
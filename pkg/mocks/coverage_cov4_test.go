@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"errors"
+	"net/http"
 	"reflect"
 	"testing"
 	"time"
@@ -11,7 +12,10 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"github.com/pay-theory/dynamorm/pkg/cache"
+	"github.com/pay-theory/dynamorm/pkg/cond"
 	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/leadingkeys"
 )
 
 func TestMockDynamoDBClient_DataOperations(t *testing.T) {
@@ -89,42 +93,90 @@ func TestMockExtendedDB_MethodCoverage(t *testing.T) {
 	db.AssertExpectations(t)
 }
 
+func TestMockExtendedDB_ExtensionMethods(t *testing.T) {
+	db := NewMockExtendedDBStrict()
+	query := new(MockQuery)
+	handler := http.NotFoundHandler()
+
+	db.On("WithItemSizeValidation", true).Return(db).Once()
+	db.On("WithRequestTags", map[string]string{"feature": "checkout"}).Return(db).Once()
+	db.On("WithLeadingKeys", mock.Anything).Return(db).Once()
+	db.On("WithItemCache", mock.Anything).Return(db).Once()
+	db.On("WithStrictReads", mock.Anything).Return(db).Once()
+	db.On("Use", mock.Anything).Return().Once()
+	db.On("OnConsumedCapacity", mock.Anything).Return().Once()
+	db.On("OnShutdown", "flush", mock.Anything).Return().Once()
+	db.On("Shutdown", mock.Anything).Return(nil).Once()
+	db.On("DebugHandler", mock.Anything).Return(handler).Once()
+	db.On("QueryString", "from Order limit 1", mock.Anything).Return(query).Once()
+	db.On("ItemCollection", "CUSTOMER#1").Return(nil).Once()
+	db.On("ConsistentSnapshot", mock.Anything).Return(nil).Once()
+	db.On("InvalidateModelCache", mock.Anything).Return(nil).Once()
+
+	require.Same(t, db, db.WithItemSizeValidation(true))
+	require.Same(t, db, db.WithRequestTags(map[string]string{"feature": "checkout"}))
+	require.Same(t, db, db.WithLeadingKeys(leadingkeys.Rule{Template: "TENANT#{tenant}"}))
+	require.Same(t, db, db.WithItemCache(cache.Options{Store: cache.NewLRU(1)}))
+	require.Same(t, db, db.WithStrictReads(nil))
+	db.Use(func(next core.Handler) core.Handler { return next })
+	db.OnConsumedCapacity(func(context.Context, *core.Operation) {})
+	db.OnShutdown("flush", func(context.Context) error { return nil })
+	require.NoError(t, db.Shutdown(context.Background()))
+	require.NotNil(t, db.DebugHandler())
+	require.Same(t, query, db.QueryString("from Order limit 1"))
+	require.Nil(t, db.ItemCollection("CUSTOMER#1"))
+	require.NoError(t, db.ConsistentSnapshot(&struct{}{}, &struct{}{}))
+	require.NoError(t, db.InvalidateModelCache(&struct{}{}))
+
+	db.AssertExpectations(t)
+}
+
 func TestMockQuery_MethodCoverage(t *testing.T) {
 	q := new(MockQuery)
 
 	q.On("OrFilter", "a", "=", 1).Return(q).Once()
 	q.On("FilterGroup", mock.Anything).Return(q).Once()
 	q.On("OrFilterGroup", mock.Anything).Return(q).Once()
+	q.On("FilterFunc", mock.Anything).Return(q).Once()
 	q.On("IfNotExists").Return(q).Once()
 	q.On("IfExists").Return(q).Once()
+	q.On("ReturnConsumedCapacity", "TOTAL").Return(q).Once()
 	q.On("WithCondition", "a", "=", 1).Return(q).Once()
 	q.On("WithConditionExpression", "a = :v", mock.Anything).Return(q).Once()
 	q.On("Select", []string{"a", "b"}).Return(q).Once()
 	q.On("CreateOrUpdate").Return(nil).Once()
 	q.On("BatchGetWithOptions", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	q.On("BatchGetOrdered", []any{"k"}, mock.Anything).Return(nil).Once()
+	q.On("BatchGetMap", []any{"k"}, mock.Anything).Return(nil).Once()
 	builder := new(MockBatchGetBuilder)
 	q.On("BatchGetBuilder").Return(builder).Once()
 	q.On("SetCursor", "cursor").Return(nil).Once()
 	q.On("WithContext", mock.Anything).Return(q).Once()
 	q.On("BatchUpdateWithOptions", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	q.On("ScanAllSegmentsWithOptions", mock.Anything, int32(4), mock.Anything).Return(nil).Once()
 
 	require.Same(t, q, q.OrFilter("a", "=", 1))
 	require.Same(t, q, q.FilterGroup(func(core.Query) {}))
 	require.Same(t, q, q.OrFilterGroup(func(core.Query) {}))
+	require.Same(t, q, q.FilterFunc(func(*cond.Builder) {}))
 	require.Same(t, q, q.IfNotExists())
 	require.Same(t, q, q.IfExists())
+	require.Same(t, q, q.ReturnConsumedCapacity("TOTAL"))
 	require.Same(t, q, q.WithCondition("a", "=", 1))
 	require.Same(t, q, q.WithConditionExpression("a = :v", map[string]any{":v": 1}))
 	require.Same(t, q, q.Select("a", "b"))
 
 	require.NoError(t, q.CreateOrUpdate())
 	require.NoError(t, q.BatchGetWithOptions([]any{"k"}, &[]any{}, &core.BatchGetOptions{}))
+	require.NoError(t, q.BatchGetOrdered([]any{"k"}, &[]any{}))
+	require.NoError(t, q.BatchGetMap([]any{"k"}, &map[string]any{}))
 	require.Same(t, builder, q.BatchGetBuilder())
 
 	require.NoError(t, q.SetCursor("cursor"))
 	require.Same(t, q, q.WithContext(context.Background()))
 
 	require.NoError(t, q.BatchUpdateWithOptions([]any{}, []string{"f"}, "opt"))
+	require.NoError(t, q.ScanAllSegmentsWithOptions(&[]any{}, 4, &core.ScanOptions{MaxConcurrency: 2}))
 
 	q.AssertExpectations(t)
 }
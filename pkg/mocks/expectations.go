@@ -0,0 +1,113 @@
+package mocks
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+var (
+	queryInterface         = reflect.TypeOf((*core.Query)(nil)).Elem()
+	updateBuilderInterface = reflect.TypeOf((*core.UpdateBuilder)(nil)).Elem()
+)
+
+// NewMockQuery creates a MockQuery whose builder methods (Where, Index, Filter, Limit,
+// OrderBy, Select, WithContext, and the rest returning core.Query) return the mock
+// itself, so only the terminal methods need expectations:
+//
+//	q := mocks.NewMockQuery()
+//	q.ExpectFirst(&User{ID: "123", Name: "Alice"})
+//
+//	// ... exercise the code under test ...
+//	q.AssertCalled(t, "Where", "ID", "=", "123")
+//
+// The defaults are registered first, so they answer every builder call; assert the
+// arguments with AssertCalled rather than per-call expectations. Use new(MockQuery)
+// when a builder method must return something else.
+func NewMockQuery() *MockQuery {
+	q := &MockQuery{}
+	chainDefaults(&q.Mock, queryInterface, q)
+	return q
+}
+
+// NewMockUpdateBuilder creates a MockUpdateBuilder whose chainable methods return the
+// mock itself, leaving Execute and ExecuteWithResult to be set up by the test.
+func NewMockUpdateBuilder() *MockUpdateBuilder {
+	b := &MockUpdateBuilder{}
+	chainDefaults(&b.Mock, updateBuilderInterface, b)
+	return b
+}
+
+// chainDefaults makes every method of iface that returns iface return self.
+func chainDefaults(m *mock.Mock, iface reflect.Type, self any) {
+	for i := 0; i < iface.NumMethod(); i++ {
+		method := iface.Method(i)
+		if method.Type.NumOut() != 1 || method.Type.Out(0) != iface {
+			continue
+		}
+		args := make([]any, method.Type.NumIn())
+		for j := range args {
+			args[j] = mock.Anything
+		}
+		m.On(method.Name, args...).Return(self).Maybe()
+	}
+}
+
+// ExpectModel makes Model return a NewMockQuery for any model of the same type as
+// model, and returns that query for further expectations:
+//
+//	db := new(mocks.MockDB)
+//	db.ExpectModel(&User{}).ExpectAll([]User{{ID: "1"}, {ID: "2"}})
+func (m *MockDB) ExpectModel(model any) *MockQuery {
+	q := NewMockQuery()
+	m.On("Model", mock.AnythingOfType(fmt.Sprintf("%T", model))).Return(q)
+	return q
+}
+
+// ExpectFirst makes First fill its destination with result and return nil. result may
+// be a value or a pointer to one.
+func (m *MockQuery) ExpectFirst(result any) *mock.Call {
+	return m.On("First", mock.Anything).Run(FillDest(result)).Return(nil)
+}
+
+// ExpectNotFound makes First return errors.ErrItemNotFound.
+func (m *MockQuery) ExpectNotFound() *mock.Call {
+	return m.On("First", mock.Anything).Return(customerrors.ErrItemNotFound)
+}
+
+// ExpectAll makes All fill its destination slice with results and return nil.
+func (m *MockQuery) ExpectAll(results any) *mock.Call {
+	return m.On("All", mock.Anything).Run(FillDest(results)).Return(nil)
+}
+
+// FillDest returns a Run function that copies value into the call's destination: the
+// first pointer argument whose element value can be assigned to. value may itself be
+// a pointer, in which case the value it points to is copied. It panics when no
+// argument fits, which points at a mismatched canned result in the test.
+//
+//	q.On("BatchGet", mock.Anything, mock.Anything).Run(mocks.FillDest(users)).Return(nil)
+func FillDest(value any) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		src := reflect.ValueOf(value)
+		for _, arg := range args {
+			dest := reflect.ValueOf(arg)
+			if dest.Kind() != reflect.Ptr || dest.IsNil() {
+				continue
+			}
+			target := dest.Elem()
+			switch {
+			case src.IsValid() && src.Type().AssignableTo(target.Type()):
+				target.Set(src)
+				return
+			case src.Kind() == reflect.Ptr && !src.IsNil() && src.Elem().Type().AssignableTo(target.Type()):
+				target.Set(src.Elem())
+				return
+			}
+		}
+		panic(fmt.Sprintf("mocks: no destination argument can hold a %T", value))
+	}
+}
@@ -0,0 +1,62 @@
+package mocks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+func TestExpectModel_CannedResults(t *testing.T) {
+	db := new(MockDB)
+	q := db.ExpectModel(&User{})
+	q.ExpectFirst(&User{ID: "123", Name: "Alice"}).Once()
+	q.ExpectAll([]User{{ID: "1", Name: "Ann"}, {ID: "2", Name: "Bob"}})
+
+	service := NewUserService(db)
+	user, err := service.GetUser("123")
+	require.NoError(t, err)
+	require.Equal(t, "Alice", user.Name)
+
+	users, err := service.GetActiveUsers()
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+
+	q.AssertCalled(t, "Where", "ID", "=", "123")
+	q.AssertCalled(t, "OrderBy", "Name", "ASC")
+	db.AssertExpectations(t)
+	q.AssertExpectations(t)
+}
+
+func TestNewMockQuery_NotFoundAndUpdates(t *testing.T) {
+	db := new(MockDB)
+	q := db.ExpectModel(&User{})
+	q.ExpectNotFound()
+
+	_, err := NewUserService(db).GetUser("missing")
+	require.ErrorIs(t, err, customerrors.ErrItemNotFound)
+
+	builder := NewMockUpdateBuilder()
+	q.On("UpdateBuilder").Return(builder)
+	builder.On("Execute").Return(nil).Once()
+
+	require.NoError(t, NewUserService(db).UpdateUserEmail("123", "a@example.com"))
+	builder.AssertCalled(t, "Set", "Email", "a@example.com")
+	builder.AssertExpectations(t)
+}
+
+func TestFillDest(t *testing.T) {
+	q := NewMockQuery()
+	q.On("BatchGet", mock.Anything, mock.Anything).Run(FillDest([]User{{ID: "1"}})).Return(nil)
+
+	var users []User
+	require.NoError(t, q.BatchGet([]any{"1"}, &users))
+	require.Equal(t, []User{{ID: "1"}}, users)
+
+	require.PanicsWithValue(t, "mocks: no destination argument can hold a int", func() {
+		var user User
+		FillDest(42)(mock.Arguments{&user})
+	})
+}
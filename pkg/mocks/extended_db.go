@@ -2,12 +2,19 @@ package mocks
 
 import (
 	"context"
+	"net/http"
 	"reflect"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/pay-theory/dynamorm/pkg/assumerole"
+	"github.com/pay-theory/dynamorm/pkg/audit"
+	"github.com/pay-theory/dynamorm/pkg/cache"
 	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/leadingkeys"
 	pkgTypes "github.com/pay-theory/dynamorm/pkg/types"
 )
 
@@ -85,6 +92,12 @@ func (m *MockExtendedDB) TransactionFunc(fn func(tx any) error) error {
 	return args.Error(0)
 }
 
+// AtomicTransaction runs a function with a queued-write transaction
+func (m *MockExtendedDB) AtomicTransaction(fn func(tx *core.Tx) error) error {
+	args := m.Called(fn)
+	return args.Error(0)
+}
+
 // Transact returns a transaction builder mock
 func (m *MockExtendedDB) Transact() core.TransactionBuilder {
 	args := m.Called()
@@ -100,6 +113,134 @@ func (m *MockExtendedDB) TransactWrite(ctx context.Context, fn func(core.Transac
 	return args.Error(0)
 }
 
+// WithItemSizeValidation returns a DB that validates item sizes before writes
+func (m *MockExtendedDB) WithItemSizeValidation(enabled bool) core.ExtendedDB {
+	args := m.Called(enabled)
+	return mustCoreExtendedDB(args.Get(0))
+}
+
+// WithIndexSelection returns a DB that does or does not choose indexes for queries
+func (m *MockExtendedDB) WithIndexSelection(enabled bool) core.ExtendedDB {
+	args := m.Called(enabled)
+	return mustCoreExtendedDB(args.Get(0))
+}
+
+// WithRequestTags returns a DB whose operations carry tags
+func (m *MockExtendedDB) WithRequestTags(tags map[string]string) core.ExtendedDB {
+	args := m.Called(tags)
+	return mustCoreExtendedDB(args.Get(0))
+}
+
+// WithLeadingKeys returns a DB that checks requests against a leading key checker
+func (m *MockExtendedDB) WithLeadingKeys(checker leadingkeys.Checker) core.ExtendedDB {
+	args := m.Called(checker)
+	return mustCoreExtendedDB(args.Get(0))
+}
+
+// WithItemCache returns a DB that caches GetItem reads
+func (m *MockExtendedDB) WithItemCache(opts cache.Options) core.ExtendedDB {
+	args := m.Called(opts)
+	return mustCoreExtendedDB(args.Get(0))
+}
+
+// WithStrictReads returns a DB that reports items missing required fields
+func (m *MockExtendedDB) WithStrictReads(onMissing func(ctx context.Context, missing *customerrors.MissingFieldsError) error) core.ExtendedDB {
+	args := m.Called(onMissing)
+	return mustCoreExtendedDB(args.Get(0))
+}
+
+// WithAudit returns a DB that records the changes its writes make
+func (m *MockExtendedDB) WithAudit(opts audit.Options) core.ExtendedDB {
+	args := m.Called(opts)
+	return mustCoreExtendedDB(args.Get(0))
+}
+
+// WithTenant returns a DB scoped to a tenant
+func (m *MockExtendedDB) WithTenant(ctx context.Context, tenantID string) core.ExtendedDB {
+	args := m.Called(ctx, tenantID)
+	return mustCoreExtendedDB(args.Get(0))
+}
+
+// WithCredentialsProvider returns a DB signing requests with provider
+func (m *MockExtendedDB) WithCredentialsProvider(provider aws.CredentialsProvider) core.ExtendedDB {
+	args := m.Called(provider)
+	return mustCoreExtendedDB(args.Get(0))
+}
+
+// WithAssumedRole returns a DB signing requests with an assumed role's credentials
+func (m *MockExtendedDB) WithAssumedRole(roleARN, externalID string, opts ...assumerole.Option) core.ExtendedDB {
+	args := m.Called(roleARN, externalID, opts)
+	return mustCoreExtendedDB(args.Get(0))
+}
+
+// Use appends middleware to the request chain
+func (m *MockExtendedDB) Use(middleware ...core.Middleware) {
+	m.Called(middleware)
+}
+
+// OnConsumedCapacity registers a consumed capacity callback
+func (m *MockExtendedDB) OnConsumedCapacity(fn func(ctx context.Context, op *core.Operation)) {
+	m.Called(fn)
+}
+
+// OnShutdown registers a drain function
+func (m *MockExtendedDB) OnShutdown(name string, drain func(ctx context.Context) error) {
+	m.Called(name, drain)
+}
+
+// Shutdown drains in-flight operations
+func (m *MockExtendedDB) Shutdown(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+// DebugHandler returns the debug HTTP handler
+func (m *MockExtendedDB) DebugHandler(opts ...core.DebugOption) http.Handler {
+	args := m.Called(opts)
+	if handler, ok := args.Get(0).(http.Handler); ok {
+		return handler
+	}
+	return nil
+}
+
+// QueryString parses a query string into a query
+func (m *MockExtendedDB) QueryString(query string, args ...any) core.Query {
+	callArgs := m.Called(query, args)
+	return mustCoreQuery(callArgs.Get(0))
+}
+
+// ItemCollection starts an item collection read
+func (m *MockExtendedDB) ItemCollection(pk any) core.ItemCollectionQuery {
+	args := m.Called(pk)
+	if q, ok := args.Get(0).(core.ItemCollectionQuery); ok {
+		return q
+	}
+	return nil
+}
+
+// InvalidateModelCache drops cached metadata for a model and re-parses it
+func (m *MockExtendedDB) InvalidateModelCache(model any) error {
+	args := m.Called(model)
+	return args.Error(0)
+}
+
+// ConsistentSnapshot reads models in one transactional read
+func (m *MockExtendedDB) ConsistentSnapshot(models ...any) error {
+	args := m.Called(models)
+	return args.Error(0)
+}
+
+func mustCoreExtendedDB(v any) core.ExtendedDB {
+	if v == nil {
+		return nil
+	}
+	db, ok := v.(core.ExtendedDB)
+	if !ok {
+		panic("unexpected type: expected core.ExtendedDB")
+	}
+	return db
+}
+
 // NewMockExtendedDB creates a new MockExtendedDB with sensible defaults
 // for methods that are rarely used in unit tests. This reduces boilerplate
 // in tests that only need to mock core functionality.
@@ -133,6 +274,24 @@ func NewMockExtendedDB() *MockExtendedDB {
 	mockDB.On("Transact").Return(nil).Maybe()
 	mockDB.On("TransactWrite", mock.Anything, mock.Anything).
 		Return(nil).Maybe()
+	mockDB.On("ConsistentSnapshot", mock.Anything).Return(nil).Maybe()
+	mockDB.On("InvalidateModelCache", mock.Anything).Return(nil).Maybe()
+
+	// Derived handles default to the mock itself
+	mockDB.On("WithItemSizeValidation", mock.Anything).Return(mockDB).Maybe()
+	mockDB.On("WithIndexSelection", mock.Anything).Return(mockDB).Maybe()
+	mockDB.On("WithRequestTags", mock.Anything).Return(mockDB).Maybe()
+	mockDB.On("WithLeadingKeys", mock.Anything).Return(mockDB).Maybe()
+	mockDB.On("WithItemCache", mock.Anything).Return(mockDB).Maybe()
+	mockDB.On("WithStrictReads", mock.Anything).Return(mockDB).Maybe()
+	mockDB.On("WithAudit", mock.Anything).Return(mockDB).Maybe()
+	mockDB.On("WithTenant", mock.Anything, mock.Anything).Return(mockDB).Maybe()
+	mockDB.On("WithCredentialsProvider", mock.Anything).Return(mockDB).Maybe()
+	mockDB.On("WithAssumedRole", mock.Anything, mock.Anything, mock.Anything).Return(mockDB).Maybe()
+	mockDB.On("Use", mock.Anything).Return().Maybe()
+	mockDB.On("OnConsumedCapacity", mock.Anything).Return().Maybe()
+	mockDB.On("OnShutdown", mock.Anything, mock.Anything).Return().Maybe()
+	mockDB.On("Shutdown", mock.Anything).Return(nil).Maybe()
 
 	// Set up common base DB method defaults
 	mockDB.On("WithContext", mock.Anything).Return(mockDB).Maybe()
@@ -52,6 +52,19 @@
 //	    *dest = users
 //	}).Return(nil)
 //
+// # Chainable Mocks and Canned Results
+//
+// NewMockQuery returns a query whose builder methods return the mock itself, and
+// MockDB.ExpectModel wires one up for a model type. ExpectFirst, ExpectAll, and
+// ExpectNotFound set up the terminal call:
+//
+//	db := new(mocks.MockDB)
+//	db.ExpectModel(&User{}).ExpectFirst(&User{ID: "123", Name: "Alice"})
+//
+// FillDest copies a canned result into the destination of any other method:
+//
+//	mockQuery.On("Scan", mock.Anything).Run(mocks.FillDest(users)).Return(nil)
+//
 // # Error Handling
 //
 // To simulate errors:
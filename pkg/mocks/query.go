@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/mock"
 
+	"github.com/pay-theory/dynamorm/pkg/cond"
 	"github.com/pay-theory/dynamorm/pkg/core"
 )
 
@@ -45,6 +46,28 @@ func mustPaginatedResult(v any) *core.PaginatedResult {
 	return result
 }
 
+func mustBatchCreateResult(v any) *core.BatchCreateResult {
+	if v == nil {
+		return nil
+	}
+	result, ok := v.(*core.BatchCreateResult)
+	if !ok {
+		panic("unexpected type: expected *core.BatchCreateResult")
+	}
+	return result
+}
+
+func mustCountResult(v any) *core.CountResult {
+	if v == nil {
+		return nil
+	}
+	result, ok := v.(*core.CountResult)
+	if !ok {
+		panic("unexpected type: expected *core.CountResult")
+	}
+	return result
+}
+
 func mustInt64(v any) int64 {
 	n, ok := v.(int64)
 	if !ok {
@@ -112,6 +135,12 @@ func (m *MockQuery) OrFilterGroup(fn func(core.Query)) core.Query {
 	return mustCoreQuery(args.Get(0))
 }
 
+// FilterFunc adds the filter conditions built by fn
+func (m *MockQuery) FilterFunc(fn func(*cond.Builder)) core.Query {
+	args := m.Called(fn)
+	return mustCoreQuery(args.Get(0))
+}
+
 // IfNotExists adds a condition that the item must not exist
 func (m *MockQuery) IfNotExists() core.Query {
 	args := m.Called()
@@ -136,6 +165,42 @@ func (m *MockQuery) WithConditionExpression(expr string, values map[string]any)
 	return mustCoreQuery(args.Get(0))
 }
 
+// Pages iterates over result pages
+func (m *MockQuery) Pages(dest any, fn func(page *core.PaginatedResult) bool) error {
+	args := m.Called(dest, fn)
+	return args.Error(0)
+}
+
+// WithClientToken makes Create verify ambiguous failures by client token
+func (m *MockQuery) WithClientToken(field, token string) core.Query {
+	args := m.Called(field, token)
+	return mustCoreQuery(args.Get(0))
+}
+
+// WhereBeginsWith adds a begins_with sort key condition
+func (m *MockQuery) WhereBeginsWith(field string, prefix string) core.Query {
+	args := m.Called(field, prefix)
+	return mustCoreQuery(args.Get(0))
+}
+
+// WhereBetween adds a BETWEEN sort key condition
+func (m *MockQuery) WhereBetween(field string, lo, hi any) core.Query {
+	args := m.Called(field, lo, hi)
+	return mustCoreQuery(args.Get(0))
+}
+
+// Profile restricts retrieved fields to a serialization profile
+func (m *MockQuery) Profile(name string) core.Query {
+	args := m.Called(name)
+	return mustCoreQuery(args.Get(0))
+}
+
+// Preload loads related models into query results
+func (m *MockQuery) Preload(relations ...string) core.Query {
+	args := m.Called(relations)
+	return mustCoreQuery(args.Get(0))
+}
+
 // OrderBy sets the sort order
 func (m *MockQuery) OrderBy(field string, order string) core.Query {
 	args := m.Called(field, order)
@@ -184,6 +249,12 @@ func (m *MockQuery) Count() (int64, error) {
 	return mustInt64(args.Get(0)), args.Error(1)
 }
 
+// CountWithResult returns the number of matching and evaluated items
+func (m *MockQuery) CountWithResult() (*core.CountResult, error) {
+	args := m.Called()
+	return mustCountResult(args.Get(0)), args.Error(1)
+}
+
 // Create creates a new item
 func (m *MockQuery) Create() error {
 	args := m.Called()
@@ -208,6 +279,12 @@ func (m *MockQuery) UpdateBuilder() core.UpdateBuilder {
 	return mustUpdateBuilder(args.Get(0))
 }
 
+// UpdateWithOptimisticRetry updates with reload-and-retry on version conflicts
+func (m *MockQuery) UpdateWithOptimisticRetry(maxAttempts int, mutate func(model any) error, fields ...string) error {
+	args := m.Called(maxAttempts, mutate, fields)
+	return args.Error(0)
+}
+
 // Delete deletes the matching items
 func (m *MockQuery) Delete() error {
 	args := m.Called()
@@ -232,6 +309,12 @@ func (m *MockQuery) ScanAllSegments(dest any, totalSegments int32) error {
 	return args.Error(0)
 }
 
+// ScanAllSegmentsWithOptions performs parallel scan across all segments with options
+func (m *MockQuery) ScanAllSegmentsWithOptions(dest any, totalSegments int32, opts *core.ScanOptions) error {
+	args := m.Called(dest, totalSegments, opts)
+	return args.Error(0)
+}
+
 // BatchGet retrieves multiple items by their primary keys
 func (m *MockQuery) BatchGet(keys []any, dest any) error {
 	args := m.Called(keys, dest)
@@ -244,6 +327,18 @@ func (m *MockQuery) BatchGetWithOptions(keys []any, dest any, opts *core.BatchGe
 	return args.Error(0)
 }
 
+// BatchGetOrdered retrieves items aligned with their keys
+func (m *MockQuery) BatchGetOrdered(keys []any, dest any) error {
+	args := m.Called(keys, dest)
+	return args.Error(0)
+}
+
+// BatchGetMap retrieves items keyed by their keys
+func (m *MockQuery) BatchGetMap(keys []any, dest any) error {
+	args := m.Called(keys, dest)
+	return args.Error(0)
+}
+
 // BatchGetBuilder returns a fluent builder for BatchGet
 func (m *MockQuery) BatchGetBuilder() core.BatchGetBuilder {
 	args := m.Called()
@@ -259,6 +354,12 @@ func (m *MockQuery) BatchCreate(items any) error {
 	return args.Error(0)
 }
 
+// BatchCreateWithOptions creates multiple items and reports per-item outcomes
+func (m *MockQuery) BatchCreateWithOptions(items any, opts *core.BatchCreateOptions) (*core.BatchCreateResult, error) {
+	args := m.Called(items, opts)
+	return mustBatchCreateResult(args.Get(0)), args.Error(1)
+}
+
 // BatchDelete deletes multiple items by their primary keys
 func (m *MockQuery) BatchDelete(keys []any) error {
 	args := m.Called(keys)
@@ -289,6 +390,30 @@ func (m *MockQuery) ConsistentRead() core.Query {
 	return mustCoreQuery(args.Get(0))
 }
 
+// Refresh writes the stored item back into the model after writes
+func (m *MockQuery) Refresh() core.Query {
+	args := m.Called()
+	return mustCoreQuery(args.Get(0))
+}
+
+// ReturnConsumedCapacity requests consumed capacity reporting
+func (m *MockQuery) ReturnConsumedCapacity(mode string) core.Query {
+	args := m.Called(mode)
+	return mustCoreQuery(args.Get(0))
+}
+
+// AtomicIncrement adds delta to a numeric field and returns the new value
+func (m *MockQuery) AtomicIncrement(field string, delta int64) (int64, error) {
+	args := m.Called(field, delta)
+	return mustInt64(args.Get(0)), args.Error(1)
+}
+
+// AtomicDecrement subtracts delta from a numeric field and returns the new value
+func (m *MockQuery) AtomicDecrement(field string, delta int64) (int64, error) {
+	args := m.Called(field, delta)
+	return mustInt64(args.Get(0)), args.Error(1)
+}
+
 // WithRetry configures retry behavior for eventually consistent reads
 func (m *MockQuery) WithRetry(maxRetries int, initialDelay time.Duration) core.Query {
 	args := m.Called(maxRetries, initialDelay)
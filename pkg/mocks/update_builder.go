@@ -84,6 +84,24 @@ func (m *MockUpdateBuilder) SetListElement(field string, index int, value any) c
 	return args.Get(0).(core.UpdateBuilder)
 }
 
+// AppendToListWithLimit appends to a list only while it stays within maxLen elements
+func (m *MockUpdateBuilder) AppendToListWithLimit(field string, values any, maxLen int) core.UpdateBuilder {
+	args := m.Called(field, values, maxLen)
+	return args.Get(0).(core.UpdateBuilder)
+}
+
+// SetMapKey sets a single key of a map attribute
+func (m *MockUpdateBuilder) SetMapKey(field string, key string, value any) core.UpdateBuilder {
+	args := m.Called(field, key, value)
+	return args.Get(0).(core.UpdateBuilder)
+}
+
+// RemoveMapKey removes a single key from a map attribute
+func (m *MockUpdateBuilder) RemoveMapKey(field string, key string) core.UpdateBuilder {
+	args := m.Called(field, key)
+	return args.Get(0).(core.UpdateBuilder)
+}
+
 // Condition adds a condition that must be met for the update to succeed
 func (m *MockUpdateBuilder) Condition(field string, operator string, value any) core.UpdateBuilder {
 	m.Called(field, operator, value)
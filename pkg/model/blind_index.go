@@ -0,0 +1,68 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// tagBlindIndex marks a string field that holds the blind index of an encrypted field,
+// a keyed hash of its plaintext that makes equality conditions on it possible:
+//
+//	SSN     string `dynamorm:"encrypted"`
+//	SSNHash string `dynamorm:"blindindex:SSN,index:gsi-ssn"`
+const tagBlindIndex = "blindindex"
+
+// resolveBlindIndexes checks the fields tagged blindindex: and records them.
+func resolveBlindIndexes(metadata *Metadata) error {
+	sources := make(map[string]string)
+	for _, field := range metadata.Fields {
+		if field.BlindIndexOf == "" {
+			continue
+		}
+		source, ok := metadata.Fields[field.BlindIndexOf]
+		switch {
+		case !ok:
+			return fmt.Errorf("%w: blind index %s names unknown field %s", errors.ErrInvalidTag, field.Name, field.BlindIndexOf)
+		case !source.IsEncrypted:
+			return fmt.Errorf("%w: blind index %s needs %s to be encrypted", errors.ErrInvalidTag, field.Name, source.Name)
+		case field.IsEncrypted || field.Type.Kind() != reflect.String:
+			return fmt.Errorf("%w: blind index %s must be an unencrypted string field", errors.ErrInvalidTag, field.Name)
+		case field.IsPK || field.IsSK:
+			return fmt.Errorf("%w: blind index %s cannot be a primary key field", errors.ErrInvalidTag, field.Name)
+		}
+		if other, ok := sources[source.Name]; ok {
+			return fmt.Errorf("%w: %s has two blind indexes, %s and %s", errors.ErrInvalidTag, source.Name, other, field.Name)
+		}
+		sources[source.Name] = field.Name
+		metadata.BlindIndexes = append(metadata.BlindIndexes, field)
+	}
+	sortFieldsByIndexPath(metadata.BlindIndexes)
+	return nil
+}
+
+// BlindIndexFor returns the blind index field of source, or nil if it has none.
+func (m *Metadata) BlindIndexFor(source *FieldMetadata) *FieldMetadata {
+	if m == nil || source == nil {
+		return nil
+	}
+	for _, field := range m.BlindIndexes {
+		if field.BlindIndexOf == source.Name {
+			return field
+		}
+	}
+	return nil
+}
+
+// BlindIndexUpdates returns the blind index fields of any of fields that are not among
+// them, so an update of an encrypted field also rewrites its blind index.
+func (m *Metadata) BlindIndexUpdates(fields []*FieldMetadata) []*FieldMetadata {
+	var updates []*FieldMetadata
+	for _, field := range fields {
+		if index := m.BlindIndexFor(field); index != nil && !containsField(fields, index) && !containsField(updates, index) {
+			updates = append(updates, index)
+		}
+	}
+	return updates
+}
@@ -0,0 +1,159 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// setEnumNames records the names of an integer field tagged enum:, which must be
+// distinct since each maps back to one value.
+func setEnumNames(meta *FieldMetadata, names []string) error {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			return fmt.Errorf("%w: enum on %s lists %q twice", errors.ErrInvalidTag, meta.Name, name)
+		}
+		seen[name] = true
+	}
+	meta.EnumNames = names
+	return nil
+}
+
+// resolveEnumFields records the fields with enum names.
+func resolveEnumFields(metadata *Metadata) {
+	for _, field := range metadata.Fields {
+		if field.IsEnum() {
+			metadata.EnumFields = append(metadata.EnumFields, field)
+		}
+	}
+	sortFieldsByIndexPath(metadata.EnumFields)
+}
+
+// IsEnum reports whether the field is an integer stored as one of its EnumNames.
+func (f *FieldMetadata) IsEnum() bool {
+	return f != nil && len(f.EnumNames) > 0
+}
+
+// EnumName returns the name stored for value, an integer of the field's type or a
+// pointer to one.
+func (f *FieldMetadata) EnumName(value reflect.Value) (string, error) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return "", fmt.Errorf("%w: %s is nil", errors.ErrInvalidEnumValue, f.Name)
+		}
+		value = value.Elem()
+	}
+	name, ok := f.enumName(value)
+	if !ok {
+		return "", fmt.Errorf("%w: %s has no name for %v", errors.ErrInvalidEnumValue, f.Name, value.Interface())
+	}
+	return name, nil
+}
+
+func (f *FieldMetadata) enumName(value reflect.Value) (string, bool) {
+	var index int64
+	switch kind := value.Kind(); {
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		index = value.Int()
+	case kind >= reflect.Uint && kind <= reflect.Uint64:
+		if value.Uint() >= uint64(len(f.EnumNames)) {
+			return "", false
+		}
+		index = int64(value.Uint())
+	default:
+		return "", false
+	}
+	if index < 0 || index >= int64(len(f.EnumNames)) {
+		return "", false
+	}
+	return f.EnumNames[index], true
+}
+
+// EnumValue returns the value stored as name.
+func (f *FieldMetadata) EnumValue(name string) (int, bool) {
+	for i, candidate := range f.EnumNames {
+		if candidate == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// EncodeEnum converts av, the field's value as marshaled, to the name stored for it.
+// Names, such as those of an item read earlier, are kept after they are checked.
+func (f *FieldMetadata) EncodeEnum(av types.AttributeValue) (types.AttributeValue, error) {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberN:
+		index, err := strconv.ParseInt(v.Value, 10, 64)
+		if err != nil || index < 0 || index >= int64(len(f.EnumNames)) {
+			return nil, fmt.Errorf("%w: %s has no name for %s", errors.ErrInvalidEnumValue, f.Name, v.Value)
+		}
+		return &types.AttributeValueMemberS{Value: f.EnumNames[index]}, nil
+	case *types.AttributeValueMemberS:
+		if _, ok := f.EnumValue(v.Value); !ok {
+			return nil, fmt.Errorf("%w: %s has no value named %q", errors.ErrInvalidEnumValue, f.Name, v.Value)
+		}
+		return av, nil
+	default:
+		return av, nil
+	}
+}
+
+// DecodeEnum converts av, the field's value as stored, back to its number so it can be
+// unmarshaled into the field. Numbers, as written before the field had names, are kept.
+func (f *FieldMetadata) DecodeEnum(av types.AttributeValue) (types.AttributeValue, error) {
+	stored, ok := av.(*types.AttributeValueMemberS)
+	if !ok {
+		return av, nil
+	}
+	index, ok := f.EnumValue(stored.Value)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s has no value named %q", errors.ErrInvalidEnumValue, f.Name, stored.Value)
+	}
+	return &types.AttributeValueMemberN{Value: strconv.Itoa(index)}, nil
+}
+
+// ApplyEnums stores the enum fields of item, a marshaled item about to be written, as
+// their names.
+func (m *Metadata) ApplyEnums(item map[string]types.AttributeValue) error {
+	for _, field := range m.EnumFields {
+		av, ok := item[field.DBName]
+		if !ok {
+			continue
+		}
+		encoded, err := field.EncodeEnum(av)
+		if err != nil {
+			return err
+		}
+		item[field.DBName] = encoded
+	}
+	return nil
+}
+
+// EnumCondition converts a condition or update value for the field to its name: an
+// integer, or a slice of them for BETWEEN and IN. Strings and values without a name are
+// returned unchanged.
+func (f *FieldMetadata) EnumCondition(value any) any {
+	v := reflect.ValueOf(value)
+	switch {
+	case !v.IsValid():
+		return value
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8:
+		converted := make([]any, v.Len())
+		for i := range converted {
+			converted[i] = f.EnumCondition(v.Index(i).Interface())
+		}
+		return converted
+	case v.Kind() == reflect.Ptr && !v.IsNil():
+		return f.EnumCondition(v.Elem().Interface())
+	}
+	if name, ok := f.enumName(v); ok {
+		return name
+	}
+	return value
+}
@@ -0,0 +1,165 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// FieldKind identifies the struct fields whose handling during registration is
+// configurable with a FieldPolicy.
+type FieldKind int
+
+const (
+	// UnexportedField is a field whose name starts with a lower-case letter, including an
+	// unexported embedded struct.
+	UnexportedField FieldKind = iota + 1
+	// InterfaceField is a named field of interface type, such as Meta any.
+	InterfaceField
+	// EmbeddedInterfaceField is an embedded interface, such as an anonymous fmt.Stringer.
+	EmbeddedInterfaceField
+)
+
+// String returns the kind's name as used in error messages.
+func (k FieldKind) String() string {
+	switch k {
+	case UnexportedField:
+		return "unexported field"
+	case InterfaceField:
+		return "interface field"
+	case EmbeddedInterfaceField:
+		return "embedded interface"
+	default:
+		return fmt.Sprintf("FieldKind(%d)", int(k))
+	}
+}
+
+// FieldAction is what registration does with a field of a configurable kind.
+type FieldAction int
+
+const (
+	// FieldDefault leaves the decision to the next rule: the policy's per-kind action, then
+	// the built-in default, which skips unexported fields and stores interfaces.
+	FieldDefault FieldAction = iota
+	// FieldSkip leaves the field out of the model, so it is neither written nor read.
+	FieldSkip
+	// FieldStore makes the field an attribute. For an unexported embedded struct it
+	// promotes the struct's exported fields, as encoding/json does; other unexported
+	// fields cannot be stored and fail registration.
+	FieldStore
+	// FieldReject fails registration with ErrUnsupportedType.
+	FieldReject
+)
+
+// FieldResolver decides the action for one field of owner, a model or an embedded struct
+// in it. Returning FieldDefault defers to the policy's per-kind action; an error fails
+// registration.
+type FieldResolver func(owner reflect.Type, field reflect.StructField, kind FieldKind) (FieldAction, error)
+
+// FieldPolicy sets how registration treats unexported fields, interface fields, and
+// embedded interfaces. Both marshalers write, and reads fill, exactly the fields
+// registration keeps, so the policy applies to every path. The zero value keeps the
+// defaults.
+//
+//	policy := model.FieldPolicy{
+//		Unexported: model.FieldReject, // catch fields meant to be exported
+//		Interfaces: model.FieldSkip,
+//	}
+type FieldPolicy struct {
+	// Resolve, when set, is asked first about every field of a configurable kind.
+	Resolve FieldResolver
+	// Unexported applies to unexported fields, including embedded structs. The default
+	// skips them.
+	Unexported FieldAction
+	// Interfaces applies to named interface fields. The default stores them; reads decode
+	// into an empty interface as plain Go values (string, int64 or float64, bool,
+	// []byte, []any, map[string]any, and the set slices) and into a non-empty interface
+	// only when it already holds a pointer to decode into.
+	Interfaces FieldAction
+	// EmbeddedInterfaces applies to embedded interfaces. The default stores them as a
+	// field named after the interface type.
+	EmbeddedInterfaces FieldAction
+}
+
+// WithFieldPolicy sets how the registry treats unexported fields, interface fields, and
+// embedded interfaces.
+func WithFieldPolicy(policy FieldPolicy) RegistryOption {
+	return func(r *Registry) {
+		r.fields = policy
+	}
+}
+
+// fieldKind returns the configurable kind of field, or false for an ordinary field.
+func fieldKind(field reflect.StructField) (FieldKind, bool) {
+	switch {
+	case !field.IsExported():
+		return UnexportedField, true
+	case field.Type.Kind() != reflect.Interface:
+		return 0, false
+	case field.Anonymous:
+		return EmbeddedInterfaceField, true
+	default:
+		return InterfaceField, true
+	}
+}
+
+// action returns what registration does with field, a field of owner of the given kind.
+func (p FieldPolicy) action(owner reflect.Type, field reflect.StructField, kind FieldKind) (FieldAction, error) {
+	if p.Resolve != nil {
+		action, err := p.Resolve(owner, field, kind)
+		if err != nil {
+			return 0, fmt.Errorf("field %s.%s: %w", owner.Name(), field.Name, err)
+		}
+		if action != FieldDefault {
+			return action, nil
+		}
+	}
+
+	action := FieldDefault
+	switch kind {
+	case UnexportedField:
+		action = p.Unexported
+	case InterfaceField:
+		action = p.Interfaces
+	case EmbeddedInterfaceField:
+		action = p.EmbeddedInterfaces
+	}
+	if action != FieldDefault {
+		return action, nil
+	}
+	if kind == UnexportedField {
+		return FieldSkip, nil
+	}
+	return FieldStore, nil
+}
+
+// resolveField applies the policy to field, reporting whether registration keeps it.
+func (p FieldPolicy) resolveField(owner reflect.Type, field reflect.StructField) (bool, error) {
+	kind, ok := fieldKind(field)
+	if !ok {
+		return true, nil
+	}
+
+	action, err := p.action(owner, field, kind)
+	if err != nil {
+		return false, err
+	}
+
+	switch action {
+	case FieldSkip:
+		return false, nil
+	case FieldStore:
+		if kind == UnexportedField && !isEmbeddedStruct(field) {
+			return false, fmt.Errorf("%w: field %s.%s: an unexported field cannot be stored; export it or skip it",
+				errors.ErrUnsupportedType, owner.Name(), field.Name)
+		}
+		return true, nil
+	case FieldReject:
+		return false, fmt.Errorf("%w: field %s.%s: %s is not allowed by the model field policy",
+			errors.ErrUnsupportedType, owner.Name(), field.Name, kind)
+	default:
+		return false, fmt.Errorf("%w: field %s.%s: unknown field action %d",
+			errors.ErrUnsupportedType, owner.Name(), field.Name, int(action))
+	}
+}
@@ -0,0 +1,130 @@
+package model_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dynamormErrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+type auditFields struct {
+	CreatedBy string
+	Reviewer  string
+}
+
+type policyModel struct {
+	fmt.Stringer
+	auditFields
+	Meta   any
+	Source fmt.Stringer
+	ID     string `dynamorm:"pk"`
+	secret string
+}
+
+func TestFieldPolicy_DefaultsSkipUnexportedAndStoreInterfaces(t *testing.T) {
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&policyModel{}))
+
+	metadata, err := registry.GetMetadata(&policyModel{})
+	require.NoError(t, err)
+
+	assert.Contains(t, metadata.Fields, "Meta")
+	assert.Contains(t, metadata.Fields, "Source")
+	assert.Contains(t, metadata.Fields, "Stringer")
+	assert.NotContains(t, metadata.Fields, "secret")
+	assert.NotContains(t, metadata.Fields, "CreatedBy")
+}
+
+func TestFieldPolicy_PerKindActions(t *testing.T) {
+	registry := model.NewRegistry(model.WithFieldPolicy(model.FieldPolicy{
+		Unexported:         model.FieldSkip,
+		Interfaces:         model.FieldSkip,
+		EmbeddedInterfaces: model.FieldSkip,
+	}))
+	require.NoError(t, registry.Register(&policyModel{}))
+
+	metadata, err := registry.GetMetadata(&policyModel{})
+	require.NoError(t, err)
+	assert.Len(t, metadata.Fields, 1)
+	assert.Contains(t, metadata.Fields, "ID")
+}
+
+func TestFieldPolicy_RejectFailsRegistration(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy model.FieldPolicy
+		field  string
+	}{
+		{name: "interfaces", policy: model.FieldPolicy{Interfaces: model.FieldReject}, field: "Meta"},
+		{name: "embedded interfaces", policy: model.FieldPolicy{EmbeddedInterfaces: model.FieldReject}, field: "Stringer"},
+		{name: "unexported", policy: model.FieldPolicy{Unexported: model.FieldReject}, field: "auditFields"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := model.NewRegistry(model.WithFieldPolicy(tt.policy)).Register(&policyModel{})
+			require.ErrorIs(t, err, dynamormErrors.ErrUnsupportedType)
+			assert.Contains(t, err.Error(), "policyModel."+tt.field)
+		})
+	}
+}
+
+func TestFieldPolicy_StoreUnexported(t *testing.T) {
+	t.Run("embedded struct fields are promoted", func(t *testing.T) {
+		registry := model.NewRegistry(model.WithFieldPolicy(model.FieldPolicy{
+			Resolve: func(_ reflect.Type, field reflect.StructField, kind model.FieldKind) (model.FieldAction, error) {
+				if kind == model.UnexportedField && field.Anonymous {
+					return model.FieldStore, nil
+				}
+				return model.FieldDefault, nil
+			},
+		}))
+		require.NoError(t, registry.Register(&policyModel{}))
+
+		metadata, err := registry.GetMetadata(&policyModel{})
+		require.NoError(t, err)
+		require.Contains(t, metadata.Fields, "CreatedBy")
+		assert.Equal(t, []int{1, 0}, metadata.Fields["CreatedBy"].IndexPath)
+		assert.NotContains(t, metadata.Fields, "secret")
+	})
+
+	t.Run("other unexported fields cannot be stored", func(t *testing.T) {
+		err := model.NewRegistry(model.WithFieldPolicy(model.FieldPolicy{Unexported: model.FieldStore})).Register(&policyModel{})
+		require.ErrorIs(t, err, dynamormErrors.ErrUnsupportedType)
+		assert.Contains(t, err.Error(), "policyModel.secret")
+	})
+}
+
+func TestFieldPolicy_ResolverIsAskedFirst(t *testing.T) {
+	var asked []string
+	registry := model.NewRegistry(model.WithFieldPolicy(model.FieldPolicy{
+		Interfaces: model.FieldReject,
+		Resolve: func(owner reflect.Type, field reflect.StructField, kind model.FieldKind) (model.FieldAction, error) {
+			asked = append(asked, owner.Name()+"."+field.Name+":"+kind.String())
+			if field.Name == "Meta" {
+				return model.FieldStore, nil
+			}
+			return model.FieldDefault, nil
+		},
+	}))
+
+	err := registry.Register(&policyModel{})
+	require.ErrorIs(t, err, dynamormErrors.ErrUnsupportedType)
+	assert.Contains(t, err.Error(), "policyModel.Source")
+	assert.Contains(t, asked, "policyModel.Meta:interface field")
+	assert.Contains(t, asked, "policyModel.Stringer:embedded interface")
+	assert.Contains(t, asked, "policyModel.auditFields:unexported field")
+
+	errResolve := errors.New("no interfaces here")
+	err = model.NewRegistry(model.WithFieldPolicy(model.FieldPolicy{
+		Resolve: func(reflect.Type, reflect.StructField, model.FieldKind) (model.FieldAction, error) {
+			return 0, errResolve
+		},
+	})).Register(&policyModel{})
+	require.ErrorIs(t, err, errResolve)
+}
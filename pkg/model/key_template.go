@@ -0,0 +1,310 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+const (
+	tagPrefix    = "prefix"
+	tagComposite = "composite"
+)
+
+// KeySeparator joins the parts of a composite key, as in "open#2026-01-02T03:04:05.000000000Z".
+const KeySeparator = "#"
+
+// keyTimeLayout formats time components of composite keys in UTC with a fixed number of
+// fractional digits, so keys sort in time order.
+const keyTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// isCompositeComponent reports whether a tag token continues a composite: list: a field
+// name, which is exported and so never a lowercase tag.
+func isCompositeComponent(token string) bool {
+	if token == "" || strings.Contains(token, ":") {
+		return false
+	}
+	return unicode.IsUpper([]rune(token)[0])
+}
+
+// resolveKeyTemplates checks the components of composite fields and records the fields
+// with templates.
+func resolveKeyTemplates(metadata *Metadata) error {
+	for _, field := range metadata.Fields {
+		if field.KeyPrefix == "" && len(field.Composite) == 0 {
+			continue
+		}
+		for _, name := range field.Composite {
+			component, ok := metadata.Fields[name]
+			if !ok {
+				return fmt.Errorf("%w: composite field %s names unknown field %s", errors.ErrInvalidTag, field.Name, name)
+			}
+			if component == field || len(component.Composite) > 0 {
+				return fmt.Errorf("%w: composite field %s cannot include composite field %s", errors.ErrInvalidTag, field.Name, name)
+			}
+			if !isKeyComponentType(component.Type) {
+				return fmt.Errorf("%w: composite field %s cannot include %s of type %s; use strings, integers, or time.Time", errors.ErrInvalidTag, field.Name, name, component.Type)
+			}
+		}
+		metadata.KeyTemplates = append(metadata.KeyTemplates, field)
+	}
+	sortFieldsByIndexPath(metadata.KeyTemplates)
+	return nil
+}
+
+func sortFieldsByIndexPath(fields []*FieldMetadata) {
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i].IndexPath, fields[j].IndexPath
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+}
+
+func isKeyComponentType(typ reflect.Type) bool {
+	if typ == reflect.TypeOf(time.Time{}) {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// HasKeyTemplate reports whether the field is tagged prefix: or composite:.
+func (f *FieldMetadata) HasKeyTemplate() bool {
+	return f != nil && (f.KeyPrefix != "" || len(f.Composite) > 0)
+}
+
+// StoredKey returns value as the field stores it, with its prefix.
+func (f *FieldMetadata) StoredKey(value string) string {
+	return f.KeyPrefix + value
+}
+
+// KeyValue returns the value of a key template field without its prefix. For a composite
+// field whose components are all set, that is the components joined by KeySeparator;
+// when none are set, the field's own value is used, so a key read earlier can be passed
+// back as is.
+func (m *Metadata) KeyValue(f *FieldMetadata, modelValue reflect.Value) (string, error) {
+	own := modelValue.FieldByIndex(f.IndexPath).String()
+	if len(f.Composite) == 0 {
+		return own, nil
+	}
+
+	parts := make([]string, len(f.Composite))
+	var missing []string
+	for i, name := range f.Composite {
+		component := modelValue.FieldByIndex(m.Fields[name].IndexPath)
+		if component.IsZero() {
+			missing = append(missing, name)
+			continue
+		}
+		part, err := formatKeyComponent(component)
+		if err != nil {
+			return "", fmt.Errorf("composite field %s: %w", f.Name, err)
+		}
+		if i < len(parts)-1 && strings.Contains(part, KeySeparator) {
+			return "", fmt.Errorf("composite field %s: %s %q contains %q; only the last component may", f.Name, name, part, KeySeparator)
+		}
+		parts[i] = part
+	}
+	switch len(missing) {
+	case 0:
+		return strings.Join(parts, KeySeparator), nil
+	case len(parts):
+		return own, nil
+	default:
+		return "", fmt.Errorf("%w: composite field %s needs %s", errors.ErrInvalidPrimaryKey, f.Name, strings.Join(missing, ", "))
+	}
+}
+
+// ApplyKeyTemplates stores the key template fields of modelValue in item, a marshaled
+// item about to be written: with their prefixes, and composites built from their
+// components. Components that are created_at or updated_at fields are taken from item,
+// where the marshaler set them.
+func (m *Metadata) ApplyKeyTemplates(modelValue reflect.Value, item map[string]types.AttributeValue) error {
+	if len(m.KeyTemplates) == 0 {
+		return nil
+	}
+	modelValue = m.withWriteTimestamps(modelValue, item)
+	for _, field := range m.KeyTemplates {
+		value, err := m.KeyValue(field, modelValue)
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			continue
+		}
+		item[field.DBName] = &types.AttributeValueMemberS{Value: field.StoredKey(value)}
+	}
+	return nil
+}
+
+// withWriteTimestamps returns modelValue, or a copy of it with the created_at and
+// updated_at components set from item.
+func (m *Metadata) withWriteTimestamps(modelValue reflect.Value, item map[string]types.AttributeValue) reflect.Value {
+	var copied reflect.Value
+	for _, field := range []*FieldMetadata{m.CreatedAtField, m.UpdatedAtField} {
+		if field == nil || !m.isKeyComponent(field.Name) {
+			continue
+		}
+		stored, ok := item[field.DBName].(*types.AttributeValueMemberS)
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, stored.Value)
+		if err != nil {
+			continue
+		}
+		if !copied.IsValid() {
+			copied = reflect.New(modelValue.Type()).Elem()
+			copied.Set(modelValue)
+		}
+		target := copied.FieldByIndex(field.IndexPath)
+		if target.Type() == reflect.TypeOf(time.Time{}) {
+			target.Set(reflect.ValueOf(t))
+		}
+	}
+	if copied.IsValid() {
+		return copied
+	}
+	return modelValue
+}
+
+func (m *Metadata) isKeyComponent(name string) bool {
+	for _, field := range m.KeyTemplates {
+		for _, component := range field.Composite {
+			if component == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LoadKeyTemplates undoes ApplyKeyTemplates on a model just unmarshaled from an item:
+// prefixes are removed, and components that are zero, such as those left out of an
+// index projection, are parsed from their composite.
+func (m *Metadata) LoadKeyTemplates(modelValue reflect.Value) error {
+	for _, field := range m.KeyTemplates {
+		target := modelValue.FieldByIndex(field.IndexPath)
+		value := strings.TrimPrefix(target.String(), field.KeyPrefix)
+		target.SetString(value)
+		if len(field.Composite) == 0 || value == "" {
+			continue
+		}
+
+		parts := strings.SplitN(value, KeySeparator, len(field.Composite))
+		if len(parts) != len(field.Composite) {
+			return fmt.Errorf("composite field %s: %q has %d parts, want %d", field.Name, value, len(parts), len(field.Composite))
+		}
+		for i, name := range field.Composite {
+			component := modelValue.FieldByIndex(m.Fields[name].IndexPath)
+			if !component.IsZero() {
+				continue
+			}
+			if err := parseKeyComponent(component, parts[i]); err != nil {
+				return fmt.Errorf("composite field %s: %s: %w", field.Name, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// KeyUpdates returns the composite fields, other than the primary key, built from any of
+// fields and not among them, so an update that changes a component also rewrites the
+// composite.
+func (m *Metadata) KeyUpdates(fields []*FieldMetadata) []*FieldMetadata {
+	var updates []*FieldMetadata
+	for _, template := range m.KeyTemplates {
+		if template.IsPK || template.IsSK || len(template.Composite) == 0 || containsField(fields, template) {
+			continue
+		}
+		for _, field := range fields {
+			if containsString(template.Composite, field.Name) {
+				updates = append(updates, template)
+				break
+			}
+		}
+	}
+	return updates
+}
+
+func containsField(fields []*FieldMetadata, target *FieldMetadata) bool {
+	for _, field := range fields {
+		if field == target {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+func formatKeyComponent(v reflect.Value) (string, error) {
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.UTC().Format(keyTimeLayout), nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	default:
+		return "", fmt.Errorf("unsupported key component type %s", v.Type())
+	}
+}
+
+func parseKeyComponent(v reflect.Value, part string) error {
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		t, err := time.Parse(time.RFC3339Nano, part)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(part)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(part, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(part, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	default:
+		return fmt.Errorf("unsupported key component type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// MaskStrategy controls how a field is scrubbed when items are exported for analytics.
+// Masks never affect what is stored in DynamoDB.
+type MaskStrategy string
+
+const (
+	// MaskNone leaves the value unchanged.
+	MaskNone MaskStrategy = ""
+	// MaskHash replaces the value with a deterministic SHA-256 token, so masked values can
+	// still be joined and counted.
+	MaskHash MaskStrategy = "hash"
+	// MaskPartial keeps the last four characters of a string (at most half of it) and
+	// replaces the rest with '*'.
+	MaskPartial MaskStrategy = "partial"
+	// MaskDrop removes the attribute.
+	MaskDrop MaskStrategy = "drop"
+)
+
+const tagMask = "mask"
+
+// parseMaskTag parses tags of the form `dynamorm:"mask:partial"`.
+func parseMaskTag(meta *FieldMetadata, value string) error {
+	strategy := MaskStrategy(value)
+	switch strategy {
+	case MaskDrop:
+	case MaskPartial:
+		if indirectKind(meta.Type) != reflect.String {
+			return fmt.Errorf("%w: mask:partial on %s requires a string field", errors.ErrInvalidTag, meta.Name)
+		}
+	case MaskHash:
+		switch kind := indirectKind(meta.Type); {
+		case kind == reflect.String, kind >= reflect.Int && kind <= reflect.Float64:
+		case kind == reflect.Slice && meta.Type.Elem().Kind() == reflect.Uint8:
+		default:
+			return fmt.Errorf("%w: mask:hash on %s requires a string, number, or []byte field", errors.ErrInvalidTag, meta.Name)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported mask strategy %q on %s (use hash, partial, or drop)", errors.ErrInvalidTag, value, meta.Name)
+	}
+	meta.Tags[tagMask] = value
+	return nil
+}
+
+// Mask returns the export masking strategy declared on the field.
+func (f *FieldMetadata) Mask() MaskStrategy {
+	return MaskStrategy(f.Tags[tagMask])
+}
+
+func indirectKind(t reflect.Type) reflect.Kind {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind()
+}
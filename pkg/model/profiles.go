@@ -0,0 +1,89 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// StorageProfile is the built-in serialization profile that includes every field. Writes
+// always use it, so items are stored in full.
+const StorageProfile = "storage"
+
+const tagExclude = "exclude"
+
+// parseExcludeTag records the profiles a field is left out of, written as
+// `dynamorm:"exclude:public|partner"`.
+func parseExcludeTag(meta *FieldMetadata, value string) error {
+	profiles := strings.Split(value, "|")
+	for i, profile := range profiles {
+		profile = strings.TrimSpace(profile)
+		if profile == "" {
+			return fmt.Errorf("%w: exclude tag on %s has an empty profile name", errors.ErrInvalidTag, meta.Name)
+		}
+		if profile == StorageProfile {
+			return fmt.Errorf("%w: fields cannot be excluded from the %s profile", errors.ErrInvalidTag, StorageProfile)
+		}
+		profiles[i] = profile
+	}
+	meta.Tags[tagExclude] = strings.Join(profiles, "|")
+	return nil
+}
+
+// ExcludedFrom reports whether the field is left out of the named profile.
+func (f *FieldMetadata) ExcludedFrom(profile string) bool {
+	for _, excluded := range strings.Split(f.Tags[tagExclude], "|") {
+		if excluded == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// Profiles returns the names of the profiles declared by the model's exclude tags, sorted.
+func (m *Metadata) Profiles() []string {
+	seen := make(map[string]bool)
+	for _, field := range m.Fields {
+		if field.Tags[tagExclude] == "" {
+			continue
+		}
+		for _, profile := range strings.Split(field.Tags[tagExclude], "|") {
+			seen[profile] = true
+		}
+	}
+	profiles := make([]string, 0, len(seen))
+	for profile := range seen {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+	return profiles
+}
+
+// ProfileFields returns the fields included in the named profile, sorted by attribute name.
+// StorageProfile includes every field; any other profile must be declared by at least one
+// exclude tag on the model.
+func (m *Metadata) ProfileFields(profile string) ([]*FieldMetadata, error) {
+	if profile != StorageProfile {
+		declared := false
+		for _, name := range m.Profiles() {
+			if name == profile {
+				declared = true
+				break
+			}
+		}
+		if !declared {
+			return nil, fmt.Errorf("%w: profile %q is not declared on %s", errors.ErrInvalidModel, profile, m.Type.Name())
+		}
+	}
+
+	fields := make([]*FieldMetadata, 0, len(m.Fields))
+	for _, field := range m.Fields {
+		if !field.ExcludedFrom(profile) {
+			fields = append(fields, field)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].DBName < fields[j].DBName })
+	return fields, nil
+}
@@ -4,9 +4,11 @@ package model
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 
+	"github.com/pay-theory/dynamorm/pkg/cache"
 	"github.com/pay-theory/dynamorm/pkg/errors"
 	"github.com/pay-theory/dynamorm/pkg/naming"
 )
@@ -20,15 +22,34 @@ const (
 type Registry struct {
 	models map[reflect.Type]*Metadata
 	tables map[string]*Metadata
+	naming naming.NamingStrategy
+	fields FieldPolicy
 	mu     sync.RWMutex
 }
 
+// RegistryOption configures a Registry.
+type RegistryOption func(*Registry)
+
+// WithNamingStrategy maps the table name of every model registered to the one the
+// strategy returns, so metadata, queries, and table management all use it.
+func WithNamingStrategy(strategy naming.NamingStrategy) RegistryOption {
+	return func(r *Registry) {
+		r.naming = strategy
+	}
+}
+
 // NewRegistry creates a new model registry
-func NewRegistry() *Registry {
-	return &Registry{
+func NewRegistry(opts ...RegistryOption) *Registry {
+	r := &Registry{
 		models: make(map[reflect.Type]*Metadata),
 		tables: make(map[string]*Metadata),
 	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(r)
+		}
+	}
+	return r
 }
 
 // Register registers a model and parses its metadata
@@ -51,10 +72,13 @@ func (r *Registry) Register(model any) error {
 	}
 
 	// Parse metadata
-	metadata, err := parseMetadata(modelType)
+	metadata, err := parseMetadata(modelType, r.fields)
 	if err != nil {
 		return err
 	}
+	if r.naming != nil {
+		metadata.TableName = r.naming.TableName(modelType, metadata.TableName)
+	}
 
 	// Register model
 	r.models[modelType] = metadata
@@ -63,6 +87,42 @@ func (r *Registry) Register(model any) error {
 	return nil
 }
 
+// Reparse parses the model's metadata again and replaces what the registry holds, or
+// registers the model if it was not. Metadata handed out earlier is left unchanged, so
+// operations already running finish with it. If parsing fails the previous metadata
+// stays registered.
+func (r *Registry) Reparse(model any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modelType := reflect.TypeOf(model)
+	if modelType == nil {
+		return fmt.Errorf("%w: model cannot be nil", errors.ErrInvalidModel)
+	}
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	if modelType.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: model must be a struct", errors.ErrInvalidModel)
+	}
+
+	metadata, err := parseMetadata(modelType, r.fields)
+	if err != nil {
+		return err
+	}
+	if r.naming != nil {
+		metadata.TableName = r.naming.TableName(modelType, metadata.TableName)
+	}
+
+	if previous, exists := r.models[modelType]; exists && r.tables[previous.TableName] == previous {
+		delete(r.tables, previous.TableName)
+	}
+	r.models[modelType] = metadata
+	r.tables[metadata.TableName] = metadata
+
+	return nil
+}
+
 // GetMetadata retrieves metadata for a model
 func (r *Registry) GetMetadata(model any) (*Metadata, error) {
 	r.mu.RLock()
@@ -81,6 +141,24 @@ func (r *Registry) GetMetadata(model any) (*Metadata, error) {
 	return metadata, nil
 }
 
+// Models returns the metadata of every registered model, ordered by table name.
+func (r *Registry) Models() []*Metadata {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	models := make([]*Metadata, 0, len(r.models))
+	for _, metadata := range r.models {
+		models = append(models, metadata)
+	}
+	sort.Slice(models, func(i, j int) bool {
+		if models[i].TableName != models[j].TableName {
+			return models[i].TableName < models[j].TableName
+		}
+		return models[i].Type.String() < models[j].Type.String()
+	})
+	return models
+}
+
 // GetMetadataByTable retrieves metadata by table name
 func (r *Registry) GetMetadataByTable(tableName string) (*Metadata, error) {
 	r.mu.RLock()
@@ -94,19 +172,58 @@ func (r *Registry) GetMetadataByTable(tableName string) (*Metadata, error) {
 	return metadata, nil
 }
 
+// GetMetadataByName retrieves metadata for a registered model by its Go type name or
+// table name, compared case-insensitively. Type names take precedence over table names.
+func (r *Registry) GetMetadataByName(name string) (*Metadata, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var byTable *Metadata
+	for modelType, metadata := range r.models {
+		if strings.EqualFold(modelType.Name(), name) {
+			return metadata, nil
+		}
+		if byTable == nil && strings.EqualFold(metadata.TableName, name) {
+			byTable = metadata
+		}
+	}
+	if byTable != nil {
+		return byTable, nil
+	}
+
+	return nil, fmt.Errorf("%w: model not registered: %s", errors.ErrInvalidModel, name)
+}
+
 // Metadata holds all metadata for a model
 type Metadata struct {
 	Type             reflect.Type
 	PrimaryKey       *KeySchema
 	Fields           map[string]*FieldMetadata
 	FieldsByDBName   map[string]*FieldMetadata
+	Relations        map[string]*RelationMetadata
 	VersionField     *FieldMetadata
 	TTLField         *FieldMetadata
 	CreatedAtField   *FieldMetadata
 	UpdatedAtField   *FieldMetadata
+	CachePolicy      *cache.Policy
 	TableName        string
 	Indexes          []IndexSchema
 	NamingConvention naming.Convention
+	// KeyTemplates are the fields tagged prefix: or composite:, in field order.
+	KeyTemplates []*FieldMetadata
+	// EntityType is the type name set with a blank field tagged `dynamorm:"entity:ORDER"`.
+	// Writes store it in EntityAttribute so reads of a table shared by several models can
+	// tell their items apart.
+	EntityType      string
+	EntityAttribute string
+	// UniqueFields are the fields tagged unique, in field order.
+	UniqueFields []*FieldMetadata
+	// EnumFields are the integer fields tagged with enum names, in field order.
+	EnumFields []*FieldMetadata
+	// BlindIndexes are the fields tagged blindindex:, in field order.
+	BlindIndexes []*FieldMetadata
+	// TenantField is the field tagged tenant, or nil if items are not scoped to tenants.
+	TenantField *FieldMetadata
 }
 
 // KeySchema represents a primary key or index key schema
@@ -139,6 +256,7 @@ type FieldMetadata struct {
 	Type        reflect.Type
 	IndexInfo   map[string]IndexRole
 	Tags        map[string]string
+	Rules       *Rules
 	DBName      string
 	Name        string
 	IndexPath   []int
@@ -151,7 +269,22 @@ type FieldMetadata struct {
 	IsUpdatedAt bool
 	IsSet       bool
 	OmitEmpty   bool
-	IsSK        bool
+	// AllowNull marks a pointer field whose nil value is an explicit NULL: it is written
+	// as a NULL attribute, even with omitempty, and removed by updates.
+	AllowNull bool
+	IsSK      bool
+	// KeyPrefix is prepended to the stored value of a field tagged prefix:, and removed
+	// when it is read.
+	KeyPrefix string
+	// Composite names the fields whose values, joined by KeySeparator, make up the stored
+	// value of a field tagged composite:.
+	Composite []string
+	// EnumNames are the names an integer field tagged enum: stores for its values 0, 1,
+	// 2, and so on.
+	EnumNames []string
+	// BlindIndexOf names the encrypted field a field tagged blindindex: holds the blind
+	// index of.
+	BlindIndexOf string
 }
 
 // IndexRole represents a field's role in an index
@@ -162,12 +295,12 @@ type IndexRole struct {
 }
 
 // parseMetadata parses model metadata from struct tags
-func parseMetadata(modelType reflect.Type) (*Metadata, error) {
+func parseMetadata(modelType reflect.Type, fields FieldPolicy) (*Metadata, error) {
 	convention := detectNamingConvention(modelType)
 	metadata := newMetadata(modelType, resolveTableName(modelType), convention)
 
 	indexMap := make(map[string]*IndexSchema)
-	if err := parseFields(modelType, metadata, indexMap, []int{}); err != nil {
+	if err := parseFields(modelType, metadata, indexMap, []int{}, fields); err != nil {
 		return nil, err
 	}
 
@@ -179,6 +312,35 @@ func parseMetadata(modelType reflect.Type) (*Metadata, error) {
 		return nil, err
 	}
 
+	if err := resolveKeyTemplates(metadata); err != nil {
+		return nil, err
+	}
+	if err := resolveUniqueFields(metadata); err != nil {
+		return nil, err
+	}
+	resolveEnumFields(metadata)
+	if err := resolveBlindIndexes(metadata); err != nil {
+		return nil, err
+	}
+	if err := resolveTenantField(metadata); err != nil {
+		return nil, err
+	}
+
+	policy, err := detectCachePolicy(modelType)
+	if err != nil {
+		return nil, err
+	}
+	metadata.CachePolicy = policy
+
+	entityType, err := detectEntityType(modelType)
+	if err != nil {
+		return nil, err
+	}
+	if entityType != "" {
+		metadata.EntityType = entityType
+		metadata.EntityAttribute = naming.ConvertAttrName(EntityTypeField, convention)
+	}
+
 	return metadata, nil
 }
 
@@ -189,6 +351,7 @@ func newMetadata(modelType reflect.Type, tableName string, convention naming.Con
 		NamingConvention: convention,
 		Fields:           make(map[string]*FieldMetadata),
 		FieldsByDBName:   make(map[string]*FieldMetadata),
+		Relations:        make(map[string]*RelationMetadata),
 		Indexes:          make([]IndexSchema, 0),
 	}
 }
@@ -235,12 +398,20 @@ func registerIndexes(metadata *Metadata, indexMap map[string]*IndexSchema) error
 }
 
 // parseFields recursively parses fields including embedded structs
-func parseFields(modelType reflect.Type, metadata *Metadata, indexMap map[string]*IndexSchema, indexPath []int) error {
+func parseFields(modelType reflect.Type, metadata *Metadata, indexMap map[string]*IndexSchema, indexPath []int, fields FieldPolicy) error {
 	for i := 0; i < modelType.NumField(); i++ {
 		field := modelType.Field(i)
 		currentPath := appendIndexPath(indexPath, i)
 
-		if err := parseField(field, currentPath, metadata, indexMap); err != nil {
+		keep, err := fields.resolveField(modelType, field)
+		if err != nil {
+			return err
+		}
+		if !keep {
+			continue
+		}
+
+		if err := parseField(field, currentPath, metadata, indexMap, fields); err != nil {
 			return err
 		}
 	}
@@ -255,13 +426,18 @@ func appendIndexPath(indexPath []int, index int) []int {
 	return currentPath
 }
 
-func parseField(field reflect.StructField, indexPath []int, metadata *Metadata, indexMap map[string]*IndexSchema) error {
-	if !field.IsExported() {
-		return nil
+func parseField(field reflect.StructField, indexPath []int, metadata *Metadata, indexMap map[string]*IndexSchema, fields FieldPolicy) error {
+	if isEmbeddedStruct(field) {
+		return parseFields(field.Type, metadata, indexMap, indexPath, fields)
 	}
 
-	if isEmbeddedStruct(field) {
-		return parseFields(field.Type, metadata, indexMap, indexPath)
+	if tag := field.Tag.Get("dynamorm"); isRelationTag(tag) {
+		rel, err := parseRelation(field, indexPath, tag)
+		if err != nil {
+			return err
+		}
+		metadata.Relations[rel.Name] = rel
+		return nil
 	}
 
 	fieldMeta, err := parseFieldMetadata(field, indexPath, metadata.NamingConvention)
@@ -469,10 +645,27 @@ func applyKeyValueTag(meta *FieldMetadata, key, value string) error {
 	case "project":
 		meta.Tags["project"] = value
 		return nil
+	case tagPrefix:
+		meta.KeyPrefix = value
+		return nil
+	case tagBlindIndex:
+		meta.BlindIndexOf = value
+		return nil
+	case tagComposite:
+		for _, name := range strings.Split(value, ",") {
+			meta.Composite = append(meta.Composite, strings.TrimSpace(name))
+		}
+		return nil
 	case tagEncrypted:
 		meta.Tags[tagEncrypted] = value
 		meta.IsEncrypted = true
 		return nil
+	case tagExclude:
+		return parseExcludeTag(meta, value)
+	case tagMask:
+		return parseMaskTag(meta, value)
+	case tagMin, tagMax, tagPattern, tagEnum:
+		return parseRuleTag(meta, key, value)
 	default:
 		meta.Tags[key] = value
 		return nil
@@ -505,7 +698,10 @@ func applySimpleTag(meta *FieldMetadata, tag string) error {
 	case "omitempty":
 		meta.OmitEmpty = true
 		return nil
-	case "binary", "json", tagEncrypted:
+	case "allownull":
+		meta.AllowNull = true
+		return nil
+	case "binary", "json", tagEncrypted, tagS3Overflow, tagRequired, tagUnique, tagTenant:
 		meta.Tags[tag] = tagValueTrue
 		if tag == tagEncrypted {
 			meta.IsEncrypted = true
@@ -574,6 +770,66 @@ func parseIndexTag(meta *FieldMetadata, value string) error {
 	return nil
 }
 
+// tagS3Overflow marks a string, []byte, or json field whose large values are stored in S3.
+const tagS3Overflow = "s3overflow"
+
+// IsS3Overflow reports whether the field's large values are offloaded to S3.
+func (f *FieldMetadata) IsS3Overflow() bool {
+	_, ok := f.Tags[tagS3Overflow]
+	return ok
+}
+
+// tagRequired marks a field every stored item must have. Strict reads report items
+// missing it instead of leaving the field zero.
+const tagRequired = "required"
+
+// IsRequired reports whether stored items must have the field.
+func (f *FieldMetadata) IsRequired() bool {
+	_, ok := f.Tags[tagRequired]
+	return ok
+}
+
+// tagUnique marks a field no two items of the model may share a value of. Writes keep
+// a uniqueness item per value in the model's table to enforce it.
+const tagUnique = "unique"
+
+// IsUnique reports whether the field's values must be unique across items.
+func (f *FieldMetadata) IsUnique() bool {
+	_, ok := f.Tags[tagUnique]
+	return ok
+}
+
+// resolveUniqueFields checks the fields tagged unique and records them. Uniqueness items
+// share the model's table, so its keys must be strings to hold their keys.
+func resolveUniqueFields(metadata *Metadata) error {
+	for _, field := range metadata.Fields {
+		if !field.IsUnique() {
+			continue
+		}
+		if field.IsPK || field.IsSK {
+			return fmt.Errorf("%w: unique field %s is already unique as part of the primary key", errors.ErrInvalidTag, field.Name)
+		}
+		if field.IsEncrypted {
+			return fmt.Errorf("%w: unique field %s cannot be encrypted", errors.ErrInvalidTag, field.Name)
+		}
+		switch field.Type.Kind() {
+		case reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		default:
+			return fmt.Errorf("%w: unique field %s must be a string or integer", errors.ErrInvalidTag, field.Name)
+		}
+		for _, key := range []*FieldMetadata{metadata.PrimaryKey.PartitionKey, metadata.PrimaryKey.SortKey} {
+			if key != nil && key.Type.Kind() != reflect.String {
+				return fmt.Errorf("%w: unique field %s needs string key fields, %s is %s", errors.ErrInvalidTag, field.Name, key.Name, key.Type)
+			}
+		}
+		metadata.UniqueFields = append(metadata.UniqueFields, field)
+	}
+	sortFieldsByIndexPath(metadata.UniqueFields)
+	return nil
+}
+
 // validateFieldType validates field type against tag requirements
 func validateFieldType(meta *FieldMetadata) error {
 	// Validate version field
@@ -597,11 +853,51 @@ func validateFieldType(meta *FieldMetadata) error {
 		}
 	}
 
+	if meta.KeyPrefix != "" || len(meta.Composite) > 0 {
+		if meta.Type.Kind() != reflect.String {
+			return fmt.Errorf("%w: prefix and composite can only be used on string fields", errors.ErrInvalidTag)
+		}
+		if meta.IsEncrypted {
+			return fmt.Errorf("%w: prefix and composite fields cannot be encrypted", errors.ErrInvalidTag)
+		}
+	}
+
+	if meta.IsEnum() && (meta.IsPK || meta.IsSK) {
+		return fmt.Errorf("%w: enum names cannot be used on primary key fields", errors.ErrInvalidTag)
+	}
+
 	// Validate set tag
 	if meta.IsSet && meta.Type.Kind() != reflect.Slice {
 		return fmt.Errorf("%w: set tag can only be used on slice types", errors.ErrInvalidTag)
 	}
 
+	// Only a pointer can be nil, and key attributes cannot be NULL
+	if meta.AllowNull {
+		if meta.Type.Kind() != reflect.Ptr {
+			return fmt.Errorf("%w: allownull can only be used on pointer fields", errors.ErrInvalidTag)
+		}
+		if meta.IsPK || meta.IsSK || len(meta.IndexInfo) > 0 || meta.IsVersion {
+			return fmt.Errorf("%w: allownull fields cannot be keys, index keys, or versions", errors.ErrInvalidTag)
+		}
+	}
+
+	// Offloaded attributes become object pointers, so they cannot be keys or encrypted
+	if _, ok := meta.Tags[tagS3Overflow]; ok {
+		if meta.IsPK || meta.IsSK || len(meta.IndexInfo) > 0 || meta.IsEncrypted {
+			return fmt.Errorf("%w: s3overflow fields cannot be keys, index keys, or encrypted", errors.ErrInvalidTag)
+		}
+		if kind := meta.Type.Kind(); kind != reflect.String && !(kind == reflect.Slice && meta.Type.Elem().Kind() == reflect.Uint8) {
+			if _, isJSON := meta.Tags["json"]; !isJSON {
+				return fmt.Errorf("%w: s3overflow fields must be string, []byte, or json", errors.ErrInvalidTag)
+			}
+		}
+	}
+
+	// Key attributes identify the item and must be present in every profile
+	if (meta.IsPK || meta.IsSK) && meta.Tags[tagExclude] != "" {
+		return fmt.Errorf("%w: key fields cannot be excluded from a profile", errors.ErrInvalidTag)
+	}
+
 	// Validate created_at and updated_at
 	if meta.IsCreatedAt || meta.IsUpdatedAt {
 		if meta.Type.String() != "time.Time" {
@@ -640,6 +936,7 @@ func splitTags(tag string) []string {
 
 	var current strings.Builder
 	inIndexClause := false
+	inCompositeClause := false
 
 	flushCurrent := func() {
 		if current.Len() == 0 {
@@ -648,6 +945,7 @@ func splitTags(tag string) []string {
 		parts = append(parts, current.String())
 		current.Reset()
 		inIndexClause = false
+		inCompositeClause = false
 	}
 
 	for _, raw := range tokens {
@@ -665,12 +963,27 @@ func splitTags(tag string) []string {
 			flushCurrent()
 		}
 
+		if inCompositeClause {
+			if isCompositeComponent(part) {
+				current.WriteString(",")
+				current.WriteString(part)
+				continue
+			}
+			flushCurrent()
+		}
+
 		if strings.HasPrefix(part, "index:") || strings.HasPrefix(part, "lsi:") {
 			inIndexClause = true
 			current.WriteString(part)
 			continue
 		}
 
+		if strings.HasPrefix(part, tagComposite+":") {
+			inCompositeClause = true
+			current.WriteString(part)
+			continue
+		}
+
 		parts = append(parts, part)
 	}
 
@@ -711,6 +1024,70 @@ func detectNamingConvention(modelType reflect.Type) naming.Convention {
 	return naming.CamelCase
 }
 
+// detectCachePolicy parses the model's cache policy from a blank field tagged like
+// `dynamorm:"cache:ttl=30s,scope=tenant"`. The options run from the cache: prefix to
+// the next key:value tag. Models without one have no policy.
+func detectCachePolicy(modelType reflect.Type) (*cache.Policy, error) {
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if field.Name != "_" {
+			continue
+		}
+
+		var options []string
+		inCache := false
+		for _, part := range strings.Split(field.Tag.Get("dynamorm"), ",") {
+			part = strings.TrimSpace(part)
+			switch {
+			case strings.HasPrefix(part, "cache:"):
+				inCache = true
+				options = append(options, strings.TrimPrefix(part, "cache:"))
+			case strings.Contains(part, ":"):
+				inCache = false
+			case inCache:
+				options = append(options, part)
+			}
+		}
+		if options == nil {
+			continue
+		}
+
+		policy, err := cache.ParsePolicy(strings.Join(options, ","))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errors.ErrInvalidTag, err)
+		}
+		return policy, nil
+	}
+	return nil, nil
+}
+
+// EntityTypeField is the name, converted to the model's naming convention, of the
+// attribute that holds the entity type: entityType, or entity_type for snake_case models.
+const EntityTypeField = "EntityType"
+
+// detectEntityType parses the model's entity type from a blank field tagged like
+// `dynamorm:"entity:ORDER"`. Models without one have no entity type.
+func detectEntityType(modelType reflect.Type) (string, error) {
+	for i := 0; i < modelType.NumField(); i++ {
+		field := modelType.Field(i)
+		if field.Name != "_" {
+			continue
+		}
+		for _, part := range strings.Split(field.Tag.Get("dynamorm"), ",") {
+			part = strings.TrimSpace(part)
+			if !strings.HasPrefix(part, "entity:") {
+				continue
+			}
+			entityType := strings.TrimSpace(strings.TrimPrefix(part, "entity:"))
+			if entityType == "" {
+				return "", fmt.Errorf("%w: entity type cannot be empty", errors.ErrInvalidTag)
+			}
+			return entityType, nil
+		}
+	}
+	return "", nil
+}
+
 // isIndexModifier returns true if the token belongs to the current index/LSI clause
 func isIndexModifier(token string) bool {
 	switch token {
@@ -1,14 +1,18 @@
 package model_test
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/pay-theory/dynamorm/pkg/cache"
 	dynamormErrors "github.com/pay-theory/dynamorm/pkg/errors"
 	"github.com/pay-theory/dynamorm/pkg/model"
+	"github.com/pay-theory/dynamorm/pkg/naming"
 )
 
 // Test models with various struct tag configurations
@@ -250,6 +254,31 @@ func TestRegisterDuplicatePrimaryKey(t *testing.T) {
 	assert.Contains(t, err.Error(), "duplicate primary key")
 }
 
+func TestRegisterAllowNullTag(t *testing.T) {
+	type NullableModel struct {
+		DeletedAt *time.Time `dynamorm:"omitempty,allownull"`
+		ID        string     `dynamorm:"pk"`
+	}
+	type NonPointerModel struct {
+		ID   string `dynamorm:"pk"`
+		Name string `dynamorm:"allownull"`
+	}
+	type KeyModel struct {
+		ID    string  `dynamorm:"pk"`
+		Email *string `dynamorm:"index:gsi-email,allownull"`
+	}
+
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&NullableModel{}))
+	metadata, err := registry.GetMetadata(&NullableModel{})
+	require.NoError(t, err)
+	assert.True(t, metadata.Fields["DeletedAt"].AllowNull)
+	assert.True(t, metadata.Fields["DeletedAt"].OmitEmpty)
+
+	require.ErrorIs(t, registry.Register(&NonPointerModel{}), dynamormErrors.ErrInvalidTag)
+	require.ErrorIs(t, registry.Register(&KeyModel{}), dynamormErrors.ErrInvalidTag)
+}
+
 func TestRegisterModelWithIndexModifiers(t *testing.T) {
 	type IndexModifierModel struct {
 		PK     string `dynamorm:"pk,attr:PK"`
@@ -385,6 +414,24 @@ func TestGetMetadataByTable(t *testing.T) {
 	assert.Contains(t, err.Error(), "table not found")
 }
 
+func TestGetMetadataByName(t *testing.T) {
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&BasicModel{}))
+
+	// Go type name, case-insensitive
+	metadata, err := registry.GetMetadataByName("basicmodel")
+	require.NoError(t, err)
+	assert.Equal(t, "BasicModels", metadata.TableName)
+
+	// Table name
+	metadata, err = registry.GetMetadataByName("BasicModels")
+	require.NoError(t, err)
+	assert.Equal(t, "BasicModel", metadata.Type.Name())
+
+	_, err = registry.GetMetadataByName("Unknown")
+	assert.ErrorIs(t, err, dynamormErrors.ErrInvalidModel)
+}
+
 func TestTableNameDerivation(t *testing.T) {
 	tests := []struct {
 		model     any
@@ -415,6 +462,284 @@ func TestTableNameDerivation(t *testing.T) {
 	}
 }
 
+type namedTableModel struct {
+	ID string `dynamorm:"pk"`
+}
+
+func (namedTableModel) TableName() string { return "orders" }
+
+func TestRegistryNamingStrategy(t *testing.T) {
+	registry := model.NewRegistry(model.WithNamingStrategy(naming.TableAffix{Prefix: "dev_", Suffix: "_eu"}))
+	require.NoError(t, registry.Register(&BasicModel{}))
+	require.NoError(t, registry.Register(&namedTableModel{}))
+
+	basic, err := registry.GetMetadata(&BasicModel{})
+	require.NoError(t, err)
+	assert.Equal(t, "dev_BasicModels_eu", basic.TableName)
+
+	named, err := registry.GetMetadataByTable("dev_orders_eu")
+	require.NoError(t, err)
+	assert.Equal(t, "namedTableModel", named.Type.Name())
+
+	_, err = registry.GetMetadataByTable("orders")
+	assert.Error(t, err)
+}
+
+type cachedSessionModel struct {
+	_  struct{} `dynamorm:"naming:snake_case,cache:ttl=30s,scope=tenant,negative=5s"`
+	ID string   `dynamorm:"pk"`
+}
+
+type badCachePolicyModel struct {
+	_  struct{} `dynamorm:"cache:ttl=forever"`
+	ID string   `dynamorm:"pk"`
+}
+
+func TestRegistryCachePolicy(t *testing.T) {
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&cachedSessionModel{}))
+	require.NoError(t, registry.Register(&BasicModel{}))
+
+	metadata, err := registry.GetMetadata(&cachedSessionModel{})
+	require.NoError(t, err)
+	assert.Equal(t, naming.SnakeCase, metadata.NamingConvention)
+	assert.Equal(t, &cache.Policy{Scope: cache.ScopeTenant, TTL: 30 * time.Second, NegativeTTL: 5 * time.Second}, metadata.CachePolicy)
+
+	basic, err := registry.GetMetadata(&BasicModel{})
+	require.NoError(t, err)
+	assert.Nil(t, basic.CachePolicy)
+
+	err = registry.Register(&badCachePolicyModel{})
+	assert.ErrorIs(t, err, dynamormErrors.ErrInvalidTag)
+}
+
+func TestRegistryEntityType(t *testing.T) {
+	type order struct {
+		_  struct{} `dynamorm:"entity:ORDER"`
+		ID string   `dynamorm:"pk"`
+	}
+	type snakeOrder struct {
+		_  struct{} `dynamorm:"naming:snake_case,entity:ORDER"`
+		ID string   `dynamorm:"pk"`
+	}
+	type emptyEntity struct {
+		_  struct{} `dynamorm:"entity:"`
+		ID string   `dynamorm:"pk"`
+	}
+
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&order{}))
+	require.NoError(t, registry.Register(&snakeOrder{}))
+	require.NoError(t, registry.Register(&BasicModel{}))
+
+	metadata, err := registry.GetMetadata(&order{})
+	require.NoError(t, err)
+	assert.Equal(t, "ORDER", metadata.EntityType)
+	assert.Equal(t, "entityType", metadata.EntityAttribute)
+
+	metadata, err = registry.GetMetadata(&snakeOrder{})
+	require.NoError(t, err)
+	assert.Equal(t, "entity_type", metadata.EntityAttribute)
+
+	basic, err := registry.GetMetadata(&BasicModel{})
+	require.NoError(t, err)
+	assert.Empty(t, basic.EntityType)
+
+	err = registry.Register(&emptyEntity{})
+	assert.ErrorIs(t, err, dynamormErrors.ErrInvalidTag)
+}
+
+func TestRegistryKeyTemplates(t *testing.T) {
+	type ticket struct {
+		CreatedAt time.Time
+		TenantID  string `dynamorm:"pk,prefix:TENANT#"`
+		SK        string `dynamorm:"sk,composite:Status,CreatedAt"`
+		Status    string
+		Number    int
+	}
+	type unknownComponent struct {
+		ID string `dynamorm:"pk,composite:Missing"`
+	}
+	type nonStringPrefix struct {
+		ID int `dynamorm:"pk,prefix:N#"`
+	}
+
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&ticket{}))
+	metadata, err := registry.GetMetadata(&ticket{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "TENANT#", metadata.Fields["TenantID"].KeyPrefix)
+	assert.Equal(t, []string{"Status", "CreatedAt"}, metadata.Fields["SK"].Composite)
+	require.Len(t, metadata.KeyTemplates, 2)
+	assert.Equal(t, "TenantID", metadata.KeyTemplates[0].Name)
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	value := reflect.ValueOf(ticket{TenantID: "t1", Status: "open", CreatedAt: created})
+	key, err := metadata.KeyValue(metadata.Fields["SK"], value)
+	require.NoError(t, err)
+	assert.Equal(t, "open#2026-01-02T03:04:05.000000000Z", key)
+
+	_, err = metadata.KeyValue(metadata.Fields["SK"], reflect.ValueOf(ticket{Status: "open"}))
+	assert.ErrorIs(t, err, dynamormErrors.ErrInvalidPrimaryKey)
+
+	loaded := ticket{TenantID: "TENANT#t1", SK: key}
+	require.NoError(t, metadata.LoadKeyTemplates(reflect.ValueOf(&loaded).Elem()))
+	assert.Equal(t, "t1", loaded.TenantID)
+	assert.Equal(t, "open", loaded.Status)
+	assert.True(t, created.Equal(loaded.CreatedAt))
+
+	assert.ErrorIs(t, registry.Register(&unknownComponent{}), dynamormErrors.ErrInvalidTag)
+	assert.Error(t, registry.Register(&nonStringPrefix{}))
+}
+
+func TestRegistryUniqueFields(t *testing.T) {
+	type account struct {
+		ID     string `dynamorm:"pk"`
+		Email  string `dynamorm:"unique"`
+		Handle string `dynamorm:"unique,attr:handle"`
+		Number int64  `dynamorm:"unique"`
+		Name   string
+	}
+	type uniqueKey struct {
+		ID string `dynamorm:"pk,unique"`
+	}
+	type uniqueFloat struct {
+		ID    string  `dynamorm:"pk"`
+		Score float64 `dynamorm:"unique"`
+	}
+	type numericKey struct {
+		ID    int64  `dynamorm:"pk"`
+		Email string `dynamorm:"unique"`
+	}
+
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&account{}))
+	metadata, err := registry.GetMetadata(&account{})
+	require.NoError(t, err)
+
+	require.Len(t, metadata.UniqueFields, 3)
+	assert.Equal(t, "Email", metadata.UniqueFields[0].Name)
+	assert.Equal(t, "handle", metadata.UniqueFields[1].DBName)
+	assert.True(t, metadata.Fields["Number"].IsUnique())
+	assert.False(t, metadata.Fields["Name"].IsUnique())
+
+	assert.ErrorIs(t, registry.Register(&uniqueKey{}), dynamormErrors.ErrInvalidTag)
+	assert.ErrorIs(t, registry.Register(&uniqueFloat{}), dynamormErrors.ErrInvalidTag)
+	assert.ErrorIs(t, registry.Register(&numericKey{}), dynamormErrors.ErrInvalidTag)
+}
+
+func TestRegistryEnumNames(t *testing.T) {
+	type payment struct {
+		ID     string `dynamorm:"pk"`
+		Status int    `dynamorm:"enum:pending|paid|failed,attr:status"`
+		Retry  *uint8 `dynamorm:"enum:never|once"`
+	}
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&payment{}))
+	metadata, err := registry.GetMetadata(&payment{})
+	require.NoError(t, err)
+
+	require.Len(t, metadata.EnumFields, 2)
+	status := metadata.EnumFields[0]
+	assert.Equal(t, []string{"pending", "paid", "failed"}, status.EnumNames)
+	assert.True(t, metadata.Fields["Retry"].IsEnum())
+
+	name, err := status.EnumName(reflect.ValueOf(2))
+	require.NoError(t, err)
+	assert.Equal(t, "failed", name)
+	_, err = status.EnumName(reflect.ValueOf(3))
+	assert.ErrorIs(t, err, dynamormErrors.ErrInvalidEnumValue)
+
+	item := map[string]types.AttributeValue{"status": &types.AttributeValueMemberN{Value: "1"}}
+	require.NoError(t, metadata.ApplyEnums(item))
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "paid"}, item["status"])
+	item["status"] = &types.AttributeValueMemberN{Value: "-1"}
+	assert.ErrorIs(t, metadata.ApplyEnums(item), dynamormErrors.ErrInvalidEnumValue)
+
+	decoded, err := status.DecodeEnum(&types.AttributeValueMemberS{Value: "paid"})
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1"}, decoded)
+	decoded, err = status.DecodeEnum(&types.AttributeValueMemberN{Value: "2"})
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "2"}, decoded)
+	_, err = status.DecodeEnum(&types.AttributeValueMemberS{Value: "refunded"})
+	assert.ErrorIs(t, err, dynamormErrors.ErrInvalidEnumValue)
+
+	assert.Equal(t, "paid", status.EnumCondition(1))
+	assert.Equal(t, []any{"pending", "failed"}, status.EnumCondition([]int{0, 2}))
+	assert.Equal(t, "paid", status.EnumCondition("paid"))
+
+	assert.Empty(t, status.Validate(reflect.ValueOf(0)))
+	failures := status.Validate(reflect.ValueOf(5))
+	require.Len(t, failures, 1)
+	assert.Equal(t, "enum", failures[0].Rule)
+}
+
+func TestRegistryValidationTags(t *testing.T) {
+	type ruled struct {
+		ID     string `dynamorm:"pk"`
+		Code   string `dynamorm:"pattern:^[a-z]+:[0-9]+$"`
+		Rating int    `dynamorm:"min:1,max:5,enum:1|3|5"`
+	}
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&ruled{}))
+	metadata, err := registry.GetMetadata(&ruled{})
+	require.NoError(t, err)
+
+	code := metadata.Fields["Code"]
+	require.NotNil(t, code.Rules)
+	assert.Empty(t, code.Validate(reflect.ValueOf("abc:12")))
+	assert.Equal(t, "pattern", code.Validate(reflect.ValueOf("ABC"))[0].Rule)
+
+	rating := metadata.Fields["Rating"]
+	assert.Empty(t, rating.Validate(reflect.ValueOf(3)))
+	failures := rating.Validate(reflect.ValueOf(7))
+	require.Len(t, failures, 2)
+	assert.Equal(t, "must be at most 5", failures[0].Message)
+	assert.Equal(t, "enum", failures[1].Rule)
+
+	for name, bad := range map[string]any{
+		"min on bool": &struct {
+			ID string `dynamorm:"pk"`
+			On bool   `dynamorm:"min:1"`
+		}{},
+		"bad bound": &struct {
+			ID string `dynamorm:"pk"`
+			N  int    `dynamorm:"max:lots"`
+		}{},
+		"min above max": &struct {
+			ID string `dynamorm:"pk"`
+			N  int    `dynamorm:"min:5,max:1"`
+		}{},
+		"pattern on int": &struct {
+			ID string `dynamorm:"pk"`
+			N  int    `dynamorm:"pattern:^1$"`
+		}{},
+		"bad pattern": &struct {
+			ID string `dynamorm:"pk"`
+			S  string `dynamorm:"pattern:(["`
+		}{},
+		"names on float": &struct {
+			ID string  `dynamorm:"pk"`
+			F  float64 `dynamorm:"enum:one|two"`
+		}{},
+		"numbers and names": &struct {
+			ID string `dynamorm:"pk"`
+			N  int    `dynamorm:"enum:1|two"`
+		}{},
+		"repeated name": &struct {
+			ID string `dynamorm:"pk"`
+			N  int    `dynamorm:"enum:one|one"`
+		}{},
+		"enum names on key": &struct {
+			ID int `dynamorm:"pk,enum:one|two"`
+		}{},
+	} {
+		assert.ErrorIs(t, registry.Register(bad), dynamormErrors.ErrInvalidTag, name)
+	}
+}
+
 func TestRegisterPointerVsValue(t *testing.T) {
 	registry := model.NewRegistry()
 
@@ -433,3 +758,347 @@ func TestRegisterPointerVsValue(t *testing.T) {
 	// Should be the same metadata
 	assert.Equal(t, metadata1, metadata2)
 }
+
+type relationOrderItem struct {
+	OrderID string `dynamorm:"pk"`
+	SKU     string `dynamorm:"sk"`
+}
+
+type relationOrder struct {
+	ID         string               `dynamorm:"pk"`
+	CustomerID string               `dynamorm:"attr:customerId"`
+	Items      []relationOrderItem  `dynamorm:"hasmany:OrderItems,fk:OrderID"`
+	Customer   *relationOrderItem   `dynamorm:"belongsto:Customer,fk:CustomerID"`
+	Extras     []*relationOrderItem `dynamorm:"hasmany:,fk:OrderID"`
+}
+
+func TestRegisterRelationships(t *testing.T) {
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&relationOrder{}))
+
+	metadata, err := registry.GetMetadata(&relationOrder{})
+	require.NoError(t, err)
+
+	assert.NotContains(t, metadata.Fields, "Items", "relationship fields are not stored")
+	assert.NotContains(t, metadata.Fields, "Customer")
+
+	items, ok := metadata.Relation("OrderItems")
+	require.True(t, ok)
+	assert.Equal(t, model.HasMany, items.Kind)
+	assert.Equal(t, "Items", items.FieldName)
+	assert.Equal(t, "OrderID", items.ForeignKey)
+
+	byField, ok := metadata.Relation("Items")
+	require.True(t, ok)
+	assert.Same(t, items, byField)
+
+	customer, ok := metadata.Relation("Customer")
+	require.True(t, ok)
+	assert.Equal(t, model.BelongsTo, customer.Kind)
+
+	extras, ok := metadata.Relation("Extras")
+	require.True(t, ok, "the field name is used when the tag omits a name")
+	assert.Equal(t, "relationOrderItem", extras.Target.Name())
+}
+
+func TestRegisterRelationshipsValidation(t *testing.T) {
+	type missingFK struct {
+		ID    string              `dynamorm:"pk"`
+		Items []relationOrderItem `dynamorm:"hasmany:Items"`
+	}
+	type notSlice struct {
+		ID    string            `dynamorm:"pk"`
+		Items relationOrderItem `dynamorm:"hasmany:Items,fk:OrderID"`
+	}
+
+	registry := model.NewRegistry()
+	err := registry.Register(&missingFK{})
+	require.ErrorIs(t, err, dynamormErrors.ErrInvalidTag)
+	assert.Contains(t, err.Error(), "requires an fk option")
+
+	err = registry.Register(&notSlice{})
+	require.ErrorIs(t, err, dynamormErrors.ErrInvalidTag)
+	assert.Contains(t, err.Error(), "must be a slice")
+}
+
+type profiledCustomer struct {
+	ID       string `dynamorm:"pk"`
+	Name     string
+	SSN      string `dynamorm:"attr:ssn,exclude:public|partner"`
+	RiskNote string `dynamorm:"attr:riskNote,exclude:public"`
+}
+
+func TestRegisterSerializationProfiles(t *testing.T) {
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&profiledCustomer{}))
+
+	metadata, err := registry.GetMetadata(&profiledCustomer{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"partner", "public"}, metadata.Profiles())
+
+	fieldNames := func(fields []*model.FieldMetadata) []string {
+		names := make([]string, len(fields))
+		for i, field := range fields {
+			names[i] = field.Name
+		}
+		return names
+	}
+
+	public, err := metadata.ProfileFields("public")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ID", "Name"}, fieldNames(public))
+
+	partner, err := metadata.ProfileFields("partner")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ID", "Name", "RiskNote"}, fieldNames(partner))
+
+	storage, err := metadata.ProfileFields(model.StorageProfile)
+	require.NoError(t, err)
+	assert.Len(t, storage, 4)
+
+	_, err = metadata.ProfileFields("internal")
+	assert.ErrorIs(t, err, dynamormErrors.ErrInvalidModel)
+}
+
+func TestRegisterSerializationProfilesValidation(t *testing.T) {
+	type excludedKey struct {
+		ID string `dynamorm:"pk,exclude:public"`
+	}
+	type excludedFromStorage struct {
+		ID   string `dynamorm:"pk"`
+		Note string `dynamorm:"exclude:storage"`
+	}
+
+	registry := model.NewRegistry()
+	err := registry.Register(&excludedKey{})
+	require.ErrorIs(t, err, dynamormErrors.ErrInvalidTag)
+	assert.Contains(t, err.Error(), "key fields cannot be excluded")
+
+	err = registry.Register(&excludedFromStorage{})
+	require.ErrorIs(t, err, dynamormErrors.ErrInvalidTag)
+}
+
+func TestRegisterS3OverflowValidation(t *testing.T) {
+	type overflowDoc struct {
+		ID   string `dynamorm:"pk"`
+		Body string `dynamorm:"s3overflow"`
+	}
+	type overflowKey struct {
+		ID string `dynamorm:"pk,s3overflow"`
+	}
+	type overflowNumber struct {
+		ID    string `dynamorm:"pk"`
+		Count int    `dynamorm:"s3overflow"`
+	}
+
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&overflowDoc{}))
+	metadata, err := registry.GetMetadata(&overflowDoc{})
+	require.NoError(t, err)
+	assert.True(t, metadata.Fields["Body"].IsS3Overflow())
+	assert.False(t, metadata.Fields["ID"].IsS3Overflow())
+
+	require.ErrorIs(t, registry.Register(&overflowKey{}), dynamormErrors.ErrInvalidTag)
+	require.ErrorIs(t, registry.Register(&overflowNumber{}), dynamormErrors.ErrInvalidTag)
+}
+
+func TestRegisterRequiredTag(t *testing.T) {
+	type requiredDoc struct {
+		ID     string `dynamorm:"pk"`
+		Status string `dynamorm:"required,attr:status"`
+		Note   string
+	}
+
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&requiredDoc{}))
+	metadata, err := registry.GetMetadata(&requiredDoc{})
+	require.NoError(t, err)
+	assert.True(t, metadata.Fields["Status"].IsRequired())
+	assert.False(t, metadata.Fields["Note"].IsRequired())
+}
+
+func TestRegisterMaskTags(t *testing.T) {
+	type masked struct {
+		ID    string `dynamorm:"pk"`
+		Email string `dynamorm:"mask:hash"`
+		Card  string `dynamorm:"mask:partial"`
+		Notes string `dynamorm:"mask:drop"`
+	}
+	type partialNumber struct {
+		ID    string `dynamorm:"pk"`
+		Count int    `dynamorm:"mask:partial"`
+	}
+	type unknownStrategy struct {
+		ID    string `dynamorm:"pk"`
+		Email string `dynamorm:"mask:redact"`
+	}
+
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&masked{}))
+	metadata, err := registry.GetMetadata(&masked{})
+	require.NoError(t, err)
+	assert.Equal(t, model.MaskHash, metadata.Fields["Email"].Mask())
+	assert.Equal(t, model.MaskPartial, metadata.Fields["Card"].Mask())
+	assert.Equal(t, model.MaskDrop, metadata.Fields["Notes"].Mask())
+	assert.Equal(t, model.MaskNone, metadata.Fields["ID"].Mask())
+
+	require.ErrorIs(t, registry.Register(&partialNumber{}), dynamormErrors.ErrInvalidTag)
+	require.ErrorIs(t, registry.Register(&unknownStrategy{}), dynamormErrors.ErrInvalidTag)
+}
+
+func TestRegistryModelsOrderedByTable(t *testing.T) {
+	type zebra struct {
+		ID string `dynamorm:"pk"`
+	}
+	type aardvark struct {
+		ID string `dynamorm:"pk"`
+	}
+
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&zebra{}))
+	require.NoError(t, registry.Register(&aardvark{}))
+
+	models := registry.Models()
+	require.Len(t, models, 2)
+	assert.Equal(t, "aardvark", models[0].Type.Name())
+	assert.Equal(t, "zebra", models[1].Type.Name())
+}
+
+func TestRegistryReparseReplacesMetadata(t *testing.T) {
+	type reloaded struct {
+		Meta any    `dynamorm:"attr:meta"`
+		ID   string `dynamorm:"pk"`
+	}
+
+	prefix := "v1_"
+	action := model.FieldSkip
+	registry := model.NewRegistry(
+		model.WithNamingStrategy(naming.NamingStrategyFunc(func(_ reflect.Type, name string) string {
+			return prefix + name
+		})),
+		model.WithFieldPolicy(model.FieldPolicy{
+			Resolve: func(reflect.Type, reflect.StructField, model.FieldKind) (model.FieldAction, error) {
+				return action, nil
+			},
+		}),
+	)
+	require.NoError(t, registry.Register(&reloaded{}))
+	before, err := registry.GetMetadata(&reloaded{})
+	require.NoError(t, err)
+	require.NotContains(t, before.Fields, "Meta")
+
+	prefix, action = "v2_", model.FieldStore
+	require.NoError(t, registry.Reparse(&reloaded{}))
+
+	after, err := registry.GetMetadata(&reloaded{})
+	require.NoError(t, err)
+	assert.Equal(t, "v2_reloadeds", after.TableName)
+	assert.Contains(t, after.Fields, "Meta")
+	assert.NotContains(t, before.Fields, "Meta", "metadata handed out earlier is unchanged")
+
+	byTable, err := registry.GetMetadataByTable("v2_reloadeds")
+	require.NoError(t, err)
+	assert.Same(t, after, byTable)
+	_, err = registry.GetMetadataByTable("v1_reloadeds")
+	require.ErrorIs(t, err, dynamormErrors.ErrTableNotFound)
+
+	action = model.FieldReject
+	require.ErrorIs(t, registry.Reparse(&reloaded{}), dynamormErrors.ErrUnsupportedType)
+	kept, err := registry.GetMetadata(&reloaded{})
+	require.NoError(t, err)
+	assert.Same(t, after, kept)
+}
+
+func TestRegistryBlindIndexes(t *testing.T) {
+	type patient struct {
+		ID      string `dynamorm:"pk"`
+		SSN     string `dynamorm:"encrypted"`
+		SSNHash string `dynamorm:"blindindex:SSN,index:gsi-ssn"`
+		Name    string
+	}
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&patient{}))
+	metadata, err := registry.GetMetadata(&patient{})
+	require.NoError(t, err)
+
+	index := metadata.Fields["SSNHash"]
+	require.Equal(t, []*model.FieldMetadata{index}, metadata.BlindIndexes)
+	assert.Same(t, index, metadata.BlindIndexFor(metadata.Fields["SSN"]))
+	assert.Nil(t, metadata.BlindIndexFor(metadata.Fields["Name"]))
+	assert.Equal(t, []*model.FieldMetadata{index}, metadata.BlindIndexUpdates([]*model.FieldMetadata{metadata.Fields["SSN"], metadata.Fields["Name"]}))
+	assert.Empty(t, metadata.BlindIndexUpdates([]*model.FieldMetadata{metadata.Fields["SSN"], index}))
+
+	type unknownSource struct {
+		ID   string `dynamorm:"pk"`
+		Hash string `dynamorm:"blindindex:SSN"`
+	}
+	type plaintextSource struct {
+		ID   string `dynamorm:"pk"`
+		SSN  string
+		Hash string `dynamorm:"blindindex:SSN"`
+	}
+	type numericIndex struct {
+		ID   string `dynamorm:"pk"`
+		SSN  string `dynamorm:"encrypted"`
+		Hash int    `dynamorm:"blindindex:SSN"`
+	}
+	type twoIndexes struct {
+		ID    string `dynamorm:"pk"`
+		SSN   string `dynamorm:"encrypted"`
+		Hash  string `dynamorm:"blindindex:SSN"`
+		Other string `dynamorm:"blindindex:SSN"`
+	}
+	for name, m := range map[string]any{
+		"unknown source":   &unknownSource{},
+		"plaintext source": &plaintextSource{},
+		"numeric index":    &numericIndex{},
+		"two indexes":      &twoIndexes{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, model.NewRegistry().Register(m), dynamormErrors.ErrInvalidTag)
+		})
+	}
+}
+
+func TestRegistryTenantField(t *testing.T) {
+	type invoice struct {
+		TenantID string `dynamorm:"tenant,pk"`
+		ID       string `dynamorm:"sk"`
+	}
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&invoice{}))
+	metadata, err := registry.GetMetadata(&invoice{})
+	require.NoError(t, err)
+	assert.Same(t, metadata.Fields["TenantID"], metadata.TenantField)
+
+	type untenanted struct {
+		ID string `dynamorm:"pk"`
+	}
+	require.NoError(t, registry.Register(&untenanted{}))
+	metadata, err = registry.GetMetadata(&untenanted{})
+	require.NoError(t, err)
+	assert.Nil(t, metadata.TenantField)
+
+	type twoTenants struct {
+		ID    string `dynamorm:"pk"`
+		Org   string `dynamorm:"tenant"`
+		Owner string `dynamorm:"tenant"`
+	}
+	type numericTenant struct {
+		ID  string `dynamorm:"pk"`
+		Org int    `dynamorm:"tenant"`
+	}
+	type encryptedTenant struct {
+		ID  string `dynamorm:"pk"`
+		Org string `dynamorm:"tenant,encrypted"`
+	}
+	for name, m := range map[string]any{
+		"two tenants":      &twoTenants{},
+		"numeric tenant":   &numericTenant{},
+		"encrypted tenant": &encryptedTenant{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, model.NewRegistry().Register(m), dynamormErrors.ErrInvalidTag)
+		})
+	}
+}
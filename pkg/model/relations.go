@@ -0,0 +1,95 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// RelationKind identifies how a related model is joined to its owner.
+type RelationKind string
+
+const (
+	// HasMany loads every item of the related model whose foreign key equals the owner's partition key.
+	HasMany RelationKind = "hasmany"
+	// BelongsTo loads the single related item whose partition key equals the owner's foreign key.
+	BelongsTo RelationKind = "belongsto"
+)
+
+// RelationMetadata describes a relationship field declared with a hasmany or belongsto tag.
+// Relationship fields are populated by Preload and are never stored on the owning item.
+type RelationMetadata struct {
+	Type       reflect.Type
+	Target     reflect.Type
+	Name       string
+	FieldName  string
+	ForeignKey string
+	Kind       RelationKind
+	IndexPath  []int
+}
+
+// Relation returns the relationship registered under name, matching either the tag name or
+// the Go field name.
+func (m *Metadata) Relation(name string) (*RelationMetadata, bool) {
+	if rel, ok := m.Relations[name]; ok {
+		return rel, true
+	}
+	for _, rel := range m.Relations {
+		if rel.FieldName == name {
+			return rel, true
+		}
+	}
+	return nil, false
+}
+
+func isRelationTag(tag string) bool {
+	return strings.HasPrefix(tag, string(HasMany)+":") || strings.HasPrefix(tag, string(BelongsTo)+":")
+}
+
+// parseRelation parses tags of the form `dynamorm:"hasmany:OrderItems,fk:OrderID"`.
+func parseRelation(field reflect.StructField, indexPath []int, tag string) (*RelationMetadata, error) {
+	rel := &RelationMetadata{
+		FieldName: field.Name,
+		Type:      field.Type,
+		IndexPath: indexPath,
+	}
+
+	for _, part := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), ":")
+		switch key {
+		case string(HasMany), string(BelongsTo):
+			rel.Kind = RelationKind(key)
+			rel.Name = value
+		case "fk":
+			rel.ForeignKey = value
+		default:
+			return nil, fmt.Errorf("%w: unsupported relationship option %q on field %s", errors.ErrInvalidTag, part, field.Name)
+		}
+	}
+
+	if rel.Name == "" {
+		rel.Name = field.Name
+	}
+	if rel.ForeignKey == "" {
+		return nil, fmt.Errorf("%w: relationship %s requires an fk option", errors.ErrInvalidTag, rel.Name)
+	}
+
+	target := field.Type
+	if rel.Kind == HasMany {
+		if target.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("%w: hasmany field %s must be a slice", errors.ErrInvalidTag, field.Name)
+		}
+		target = target.Elem()
+	}
+	if target.Kind() == reflect.Ptr {
+		target = target.Elem()
+	}
+	if target.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: relationship field %s must reference a struct model", errors.ErrInvalidTag, field.Name)
+	}
+	rel.Target = target
+
+	return rel, nil
+}
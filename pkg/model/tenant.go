@@ -0,0 +1,39 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// tagTenant marks the string field holding the tenant an item belongs to. A DB scoped
+// with WithTenant sets it on writes and refuses to read or write items of other tenants:
+//
+//	TenantID string `dynamorm:"tenant,index:gsi-tenant,pk"`
+const tagTenant = "tenant"
+
+// IsTenant reports whether the field holds the tenant of its item.
+func (f *FieldMetadata) IsTenant() bool {
+	_, ok := f.Tags[tagTenant]
+	return ok
+}
+
+// resolveTenantField checks the field tagged tenant, if any, and records it.
+func resolveTenantField(metadata *Metadata) error {
+	for _, field := range metadata.Fields {
+		if !field.IsTenant() {
+			continue
+		}
+		switch {
+		case metadata.TenantField != nil:
+			return fmt.Errorf("%w: %s and %s are both tagged tenant", errors.ErrInvalidTag, metadata.TenantField.Name, field.Name)
+		case field.IsEncrypted || field.Type.Kind() != reflect.String:
+			return fmt.Errorf("%w: tenant field %s must be an unencrypted string field", errors.ErrInvalidTag, field.Name)
+		case field.HasKeyTemplate():
+			return fmt.Errorf("%w: tenant field %s cannot have a key template", errors.ErrInvalidTag, field.Name)
+		}
+		metadata.TenantField = field
+	}
+	return nil
+}
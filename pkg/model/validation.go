@@ -0,0 +1,212 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pay-theory/dynamorm/internal/reflectutil"
+	"github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// Validation tags constrain what Create, CreateOrUpdate, BatchCreate, and Update may
+// write, alongside dynamorm:"required":
+//
+//	Name   string `dynamorm:"required,min:1,max:64"`
+//	Code   string `dynamorm:"pattern:^[A-Z]{3}$"`
+//	Status string `dynamorm:"enum:open|paid|void"`
+//	Amount int64  `dynamorm:"min:0,max:1000000"`
+//
+// min and max bound the length of strings, slices, and maps, and the value of numbers.
+// Patterns cannot contain commas, which separate tags. enum lists the values a string
+// or number field may hold; on an integer field it can instead list names, which are
+// stored in place of the values 0, 1, 2, and so on (see FieldMetadata.EnumNames).
+const (
+	tagMin     = "min"
+	tagMax     = "max"
+	tagPattern = "pattern"
+	tagEnum    = "enum"
+)
+
+// Rules are the constraints a field's validation tags declare.
+type Rules struct {
+	Min     *float64
+	Max     *float64
+	Pattern *regexp.Regexp
+	Enum    []string
+}
+
+// parseRuleTag parses a min, max, pattern, or enum tag into the field's rules.
+func parseRuleTag(meta *FieldMetadata, key, value string) error {
+	if meta.Rules == nil {
+		meta.Rules = &Rules{}
+	}
+	kind := indirectKind(meta.Type)
+
+	switch key {
+	case tagMin, tagMax:
+		if !isLengthKind(kind) && !isNumberKind(kind) {
+			return fmt.Errorf("%w: %s on %s requires a string, slice, map, or number field", errors.ErrInvalidTag, key, meta.Name)
+		}
+		bound, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %s on %s is not a number: %q", errors.ErrInvalidTag, key, meta.Name, value)
+		}
+		if key == tagMin {
+			meta.Rules.Min = &bound
+		} else {
+			meta.Rules.Max = &bound
+		}
+		if meta.Rules.Min != nil && meta.Rules.Max != nil && *meta.Rules.Min > *meta.Rules.Max {
+			return fmt.Errorf("%w: min on %s is greater than max", errors.ErrInvalidTag, meta.Name)
+		}
+	case tagPattern:
+		if kind != reflect.String {
+			return fmt.Errorf("%w: pattern on %s requires a string field", errors.ErrInvalidTag, meta.Name)
+		}
+		pattern, err := regexp.Compile(value)
+		if err != nil {
+			return fmt.Errorf("%w: pattern on %s: %v", errors.ErrInvalidTag, meta.Name, err)
+		}
+		meta.Rules.Pattern = pattern
+	case tagEnum:
+		if kind != reflect.String && !isNumberKind(kind) {
+			return fmt.Errorf("%w: enum on %s requires a string or number field", errors.ErrInvalidTag, meta.Name)
+		}
+		values := strings.Split(value, "|")
+		names := 0
+		for i, v := range values {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				return fmt.Errorf("%w: enum on %s has an empty value", errors.ErrInvalidTag, meta.Name)
+			}
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				names++
+			}
+			values[i] = v
+		}
+		switch {
+		case kind == reflect.String || names == 0:
+			meta.Rules.Enum = values
+		case names == len(values) && isIntegerKind(kind):
+			return setEnumNames(meta, values)
+		case isIntegerKind(kind):
+			return fmt.Errorf("%w: enum on %s mixes numbers and names", errors.ErrInvalidTag, meta.Name)
+		default:
+			return fmt.Errorf("%w: enum on %s has a non-numeric value for a non-integer field", errors.ErrInvalidTag, meta.Name)
+		}
+	}
+	return nil
+}
+
+// Validate checks value, the field's value in a model about to be written, against the
+// field's required and validation tags, returning one error per broken rule.
+func (f *FieldMetadata) Validate(value reflect.Value) []errors.FieldError {
+	if f.IsRequired() && isMissing(value, f.OmitEmpty) {
+		return []errors.FieldError{{Field: f.Name, Rule: tagRequired, Message: "is required"}}
+	}
+	if f.Rules == nil {
+		return nil
+	}
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if f.IsEnum() {
+		if _, ok := f.enumName(value); !ok {
+			return []errors.FieldError{{Field: f.Name, Rule: tagEnum, Message: "must be the value of one of " + strings.Join(f.EnumNames, ", ")}}
+		}
+	}
+
+	var failures []errors.FieldError
+	fail := func(rule, format string, args ...any) {
+		failures = append(failures, errors.FieldError{Field: f.Name, Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	kind := value.Kind()
+	if f.Rules.Min != nil || f.Rules.Max != nil {
+		measure, unit := measureValue(value)
+		if f.Rules.Min != nil && measure < *f.Rules.Min {
+			fail(tagMin, "must be at least %s%s", formatBound(*f.Rules.Min), unit)
+		}
+		if f.Rules.Max != nil && measure > *f.Rules.Max {
+			fail(tagMax, "must be at most %s%s", formatBound(*f.Rules.Max), unit)
+		}
+	}
+	if f.Rules.Pattern != nil && kind == reflect.String && !f.Rules.Pattern.MatchString(value.String()) {
+		fail(tagPattern, "must match %s", f.Rules.Pattern)
+	}
+	if len(f.Rules.Enum) > 0 && !inEnum(value, f.Rules.Enum) {
+		fail(tagEnum, "must be one of %s", strings.Join(f.Rules.Enum, ", "))
+	}
+	return failures
+}
+
+// isMissing reports whether a required field's value would be written empty or left out.
+// Numbers and booleans are always written unless omitempty.
+func isMissing(value reflect.Value, omitEmpty bool) bool {
+	if !reflectutil.IsEmpty(value) {
+		return false
+	}
+	kind := value.Kind()
+	return omitEmpty || (kind != reflect.Bool && !isNumberKind(kind))
+}
+
+// measureValue returns what min and max bound for value: its length or its number.
+func measureValue(value reflect.Value) (float64, string) {
+	switch kind := value.Kind(); {
+	case kind == reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), " characters"
+	case isLengthKind(kind):
+		return float64(value.Len()), " items"
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		return float64(value.Int()), ""
+	case kind >= reflect.Uint && kind <= reflect.Uintptr:
+		return float64(value.Uint()), ""
+	default:
+		return value.Float(), ""
+	}
+}
+
+func inEnum(value reflect.Value, enum []string) bool {
+	for _, allowed := range enum {
+		switch kind := value.Kind(); {
+		case kind == reflect.String:
+			if value.String() == allowed {
+				return true
+			}
+		case isNumberKind(kind):
+			n, _ := strconv.ParseFloat(allowed, 64)
+			if measure, _ := measureValue(value); measure == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func formatBound(bound float64) string {
+	return strconv.FormatFloat(bound, 'f', -1, 64)
+}
+
+func isLengthKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return true
+	default:
+		return false
+	}
+}
+
+func isIntegerKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Uint64
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Float64 && kind != reflect.Uintptr
+}
@@ -184,3 +184,23 @@ func TestConvertAttrName(t *testing.T) {
 		}
 	}
 }
+
+func TestTableNamingStrategies(t *testing.T) {
+	typ := reflect.TypeOf(sample{})
+
+	if got := (TableAffix{Prefix: "dev_", Suffix: "_v2"}).TableName(typ, "orders"); got != "dev_orders_v2" {
+		t.Fatalf("TableAffix = %q, want dev_orders_v2", got)
+	}
+
+	if ChainStrategies(nil, nil) != nil {
+		t.Fatal("ChainStrategies of nil strategies should be nil")
+	}
+
+	typeName := NamingStrategyFunc(func(modelType reflect.Type, name string) string {
+		return modelType.Name() + "." + name
+	})
+	chain := ChainStrategies(typeName, nil, TableAffix{Prefix: "prod_"})
+	if got := chain.TableName(typ, "orders"); got != "prod_sample.orders" {
+		t.Fatalf("chained strategy = %q, want prod_sample.orders", got)
+	}
+}
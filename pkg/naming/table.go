@@ -0,0 +1,52 @@
+package naming
+
+import "reflect"
+
+// NamingStrategy maps a model's table name to the table it is stored in, for example
+// adding an environment prefix so the same model uses "dev_orders" and "prod_orders".
+// name is the model's TableName() or its derived default.
+type NamingStrategy interface {
+	TableName(modelType reflect.Type, name string) string
+}
+
+// NamingStrategyFunc adapts a function to NamingStrategy.
+type NamingStrategyFunc func(modelType reflect.Type, name string) string
+
+// TableName calls f.
+func (f NamingStrategyFunc) TableName(modelType reflect.Type, name string) string {
+	return f(modelType, name)
+}
+
+// TableAffix is a NamingStrategy adding Prefix and Suffix to every table name.
+type TableAffix struct {
+	Prefix string
+	Suffix string
+}
+
+// TableName returns name between the prefix and suffix.
+func (a TableAffix) TableName(_ reflect.Type, name string) string {
+	return a.Prefix + name + a.Suffix
+}
+
+// ChainStrategies applies strategies in order, each to the name the previous returned.
+// Nil strategies are skipped; ChainStrategies returns nil when none remain.
+func ChainStrategies(strategies ...NamingStrategy) NamingStrategy {
+	var chain []NamingStrategy
+	for _, s := range strategies {
+		if s != nil {
+			chain = append(chain, s)
+		}
+	}
+	switch len(chain) {
+	case 0:
+		return nil
+	case 1:
+		return chain[0]
+	}
+	return NamingStrategyFunc(func(modelType reflect.Type, name string) string {
+		for _, s := range chain {
+			name = s.TableName(modelType, name)
+		}
+		return name
+	})
+}
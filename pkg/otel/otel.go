@@ -0,0 +1,150 @@
+// Package otel emits an OpenTelemetry span for every DynamoDB operation DynamORM sends.
+//
+// It is a separate module so applications that do not trace do not pull in the
+// OpenTelemetry SDK. Wire it in with DB.Use:
+//
+//	db.Use(otel.Middleware(otel.WithTracerProvider(tp)))
+//
+// Each span is a client span named "<operation> <table>" carrying the table, index,
+// operation, item count, scanned count, consumed capacity, and request tags. The span's
+// context is the context the request is sent with, so spans from the AWS SDK's own
+// instrumentation nest beneath it.
+package otel
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	global "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/pay-theory/dynamorm"
+)
+
+// InstrumentationName identifies the tracer spans are created with.
+const InstrumentationName = "github.com/pay-theory/dynamorm/pkg/otel"
+
+// Attribute keys set on every span, following the OpenTelemetry DynamoDB conventions
+// where they define one.
+const (
+	DBSystemKey         = attribute.Key("db.system.name")
+	OperationKey        = attribute.Key("db.operation.name")
+	TableNamesKey       = attribute.Key("aws.dynamodb.table_names")
+	IndexNameKey        = attribute.Key("aws.dynamodb.index_name")
+	ScannedCountKey     = attribute.Key("aws.dynamodb.scanned_count")
+	ConsumedCapacityKey = attribute.Key("aws.dynamodb.consumed_capacity")
+	ItemCountKey        = attribute.Key("dynamorm.item_count")
+	CapacityUnitsKey    = attribute.Key("dynamorm.consumed_capacity.units")
+	// TagKeyPrefix prefixes request tags, so tag "feature" becomes "dynamorm.tag.feature".
+	TagKeyPrefix = "dynamorm.tag."
+)
+
+type config struct {
+	provider trace.TracerProvider
+	capacity types.ReturnConsumedCapacity
+}
+
+// Option configures Middleware.
+type Option func(*config)
+
+// WithTracerProvider sets the provider spans are created with. The global provider is
+// used by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		if provider != nil {
+			c.provider = provider
+		}
+	}
+}
+
+// WithConsumedCapacity sets the ReturnConsumedCapacity mode requested for traced
+// operations. The default is TOTAL; NONE stops requesting it.
+func WithConsumedCapacity(mode types.ReturnConsumedCapacity) Option {
+	return func(c *config) {
+		c.capacity = mode
+	}
+}
+
+// Middleware returns DynamORM middleware that records a span per operation.
+func Middleware(opts ...Option) dynamorm.Middleware {
+	cfg := config{
+		provider: global.GetTracerProvider(),
+		capacity: types.ReturnConsumedCapacityTotal,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	tracer := cfg.provider.Tracer(InstrumentationName)
+
+	return func(next dynamorm.Handler) dynamorm.Handler {
+		return func(ctx context.Context, op *dynamorm.Operation) error {
+			if op.ReturnConsumedCapacity == "" && cfg.capacity != types.ReturnConsumedCapacityNone {
+				op.ReturnConsumedCapacity = cfg.capacity
+			}
+
+			name := op.Type
+			if op.Table != "" {
+				name += " " + op.Table
+			}
+			ctx, span := tracer.Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(requestAttributes(op)...),
+			)
+			defer span.End()
+
+			err := next(ctx, op)
+
+			span.SetAttributes(responseAttributes(op)...)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
+
+func requestAttributes(op *dynamorm.Operation) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		DBSystemKey.String("aws.dynamodb"),
+		OperationKey.String(op.Type),
+	}
+	if op.Table != "" {
+		attrs = append(attrs, TableNamesKey.StringSlice([]string{op.Table}))
+	}
+	if op.Index != "" {
+		attrs = append(attrs, IndexNameKey.String(op.Index))
+	}
+	for k, v := range op.Tags {
+		attrs = append(attrs, attribute.String(TagKeyPrefix+k, v))
+	}
+	return attrs
+}
+
+func responseAttributes(op *dynamorm.Operation) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{ItemCountKey.Int(op.ItemCount)}
+	if op.Type == dynamorm.OperationQuery || op.Type == dynamorm.OperationScan {
+		attrs = append(attrs, ScannedCountKey.Int(op.ScannedCount))
+	}
+	if len(op.ConsumedCapacity) == 0 {
+		return attrs
+	}
+
+	var units float64
+	encoded := make([]string, 0, len(op.ConsumedCapacity))
+	for _, c := range op.ConsumedCapacity {
+		if c.CapacityUnits != nil {
+			units += *c.CapacityUnits
+		}
+		if raw, err := json.Marshal(c); err == nil {
+			encoded = append(encoded, string(raw))
+		}
+	}
+	return append(attrs,
+		CapacityUnitsKey.Float64(units),
+		ConsumedCapacityKey.StringSlice(encoded),
+	)
+}
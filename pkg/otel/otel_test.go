@@ -0,0 +1,89 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/pay-theory/dynamorm"
+)
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestMiddlewareRecordsOperationSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	handler := Middleware(WithTracerProvider(provider))(func(ctx context.Context, op *dynamorm.Operation) error {
+		require.True(t, trace.SpanFromContext(ctx).SpanContext().IsValid(), "request context carries the span")
+		require.Equal(t, types.ReturnConsumedCapacityTotal, op.ReturnConsumedCapacity)
+		op.ItemCount = 2
+		op.ScannedCount = 5
+		op.ConsumedCapacity = []types.ConsumedCapacity{
+			{TableName: aws.String("orders"), CapacityUnits: aws.Float64(0.5)},
+			{TableName: aws.String("orders"), CapacityUnits: aws.Float64(1)},
+		}
+		return nil
+	})
+
+	op := &dynamorm.Operation{
+		Type:  dynamorm.OperationQuery,
+		Table: "orders",
+		Index: "gsi-customer",
+		Tags:  map[string]string{"feature": "checkout"},
+	}
+	require.NoError(t, handler(context.Background(), op))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	require.Equal(t, "Query orders", span.Name())
+	require.Equal(t, trace.SpanKindClient, span.SpanKind())
+
+	attrs := spanAttributes(span)
+	require.Equal(t, "aws.dynamodb", attrs[DBSystemKey].AsString())
+	require.Equal(t, "Query", attrs[OperationKey].AsString())
+	require.Equal(t, []string{"orders"}, attrs[TableNamesKey].AsStringSlice())
+	require.Equal(t, "gsi-customer", attrs[IndexNameKey].AsString())
+	require.Equal(t, int64(2), attrs[ItemCountKey].AsInt64())
+	require.Equal(t, int64(5), attrs[ScannedCountKey].AsInt64())
+	require.Equal(t, 1.5, attrs[CapacityUnitsKey].AsFloat64())
+	require.Len(t, attrs[ConsumedCapacityKey].AsStringSlice(), 2)
+	require.Equal(t, "checkout", attrs[attribute.Key(TagKeyPrefix+"feature")].AsString())
+}
+
+func TestMiddlewareRecordsErrors(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	failure := errors.New("ThrottlingException")
+	handler := Middleware(
+		WithTracerProvider(provider),
+		WithConsumedCapacity(types.ReturnConsumedCapacityNone),
+	)(func(_ context.Context, op *dynamorm.Operation) error {
+		require.Empty(t, op.ReturnConsumedCapacity)
+		return failure
+	})
+
+	err := handler(context.Background(), &dynamorm.Operation{Type: dynamorm.OperationPutItem, Table: "orders"})
+	require.ErrorIs(t, err, failure)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+	require.NotContains(t, spanAttributes(spans[0]), ScannedCountKey)
+	require.Len(t, spans[0].Events(), 1)
+}
@@ -0,0 +1,153 @@
+// Package outbox implements the transactional outbox pattern: a domain write and the
+// event announcing it commit in the same TransactWriteItems call, and a Relay publishes
+// pending events to SNS, SQS, EventBridge, or any other Publisher afterwards, so an
+// event is never lost or sent for a write that rolled back:
+//
+//	err := db.TransactWrite(ctx, func(tx core.TransactionBuilder) error {
+//		tx.Create(&payment)
+//		_, err := outbox.Enqueue(tx, "payment.created", payment, outbox.WithKey(payment.MerchantID))
+//		return err
+//	})
+//
+// A Relay either polls the outbox table's status index or consumes its DynamoDB stream:
+//
+//	relay := outbox.NewRelay(db, outbox.PublisherFunc(func(ctx context.Context, e *outbox.Event) error {
+//		_, err := snsClient.Publish(ctx, &sns.PublishInput{
+//			TopicArn:               aws.String(topicARNs[e.Topic]),
+//			Message:                aws.String(e.Payload),
+//			MessageGroupId:         aws.String(e.Key),
+//			MessageDeduplicationId: aws.String(e.ID),
+//		})
+//		return err
+//	}), outbox.Options{})
+//	go relay.Run(ctx)
+//
+// Delivery is at least once: an event is marked published after its Publisher returns,
+// so a crash in between, or two relays racing, publishes it again. Consumers should
+// deduplicate on Event.ID.
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+// Table is the name of the outbox table, before any session naming strategy.
+const Table = "outbox_events"
+
+// StatusIndex is the GSI on Status and EnqueuedAt that Relay.PollOnce queries.
+const StatusIndex = "gsi-status"
+
+// Event statuses.
+const (
+	StatusPending   = "pending"
+	StatusPublished = "published"
+	// StatusFailed marks an event that failed Options.MaxAttempts times; it is kept for
+	// inspection and no longer retried.
+	StatusFailed = "failed"
+)
+
+// Event is an item in the outbox table.
+type Event struct {
+	Attributes map[string]string `dynamorm:"attr:attributes,omitempty" json:"attributes,omitempty"`
+	ID         string            `dynamorm:"pk,attr:id" json:"id"`
+	Topic      string            `dynamorm:"attr:topic" json:"topic"`
+	// Key groups events that must be published in order, such as an aggregate ID.
+	Key string `dynamorm:"attr:key,omitempty" json:"key,omitempty"`
+	// Payload is the event body, JSON unless enqueued as a string or []byte.
+	Payload   string `dynamorm:"attr:payload" json:"payload"`
+	Status    string `dynamorm:"index:gsi-status,pk,attr:status" json:"status"`
+	LastError string `dynamorm:"attr:lastError,omitempty" json:"lastError,omitempty"`
+	// EnqueuedAt is the enqueue time in Unix nanoseconds, ordering pending events.
+	EnqueuedAt  int64     `dynamorm:"index:gsi-status,sk,attr:enqueuedAt" json:"enqueuedAt"`
+	PublishedAt time.Time `dynamorm:"attr:publishedAt,omitempty" json:"publishedAt,omitempty"`
+	Attempts    int       `dynamorm:"attr:attempts" json:"attempts"`
+	// ExpiresAt lets DynamoDB TTL remove published events after Options.Retention.
+	ExpiresAt int64 `dynamorm:"ttl,attr:expiresAt,omitempty" json:"expiresAt,omitempty"`
+}
+
+// TableName returns Table.
+func (Event) TableName() string {
+	return Table
+}
+
+// EventOption configures an enqueued event.
+type EventOption func(*Event)
+
+// WithKey sets the event's ordering key, for example an SNS FIFO message group.
+func WithKey(key string) EventOption {
+	return func(e *Event) {
+		e.Key = key
+	}
+}
+
+// WithID replaces the generated event ID, for example with one derived from the
+// domain write so retried requests enqueue the same event.
+func WithID(id string) EventOption {
+	return func(e *Event) {
+		e.ID = id
+	}
+}
+
+// WithAttributes sets message attributes for the publisher.
+func WithAttributes(attributes map[string]string) EventOption {
+	return func(e *Event) {
+		e.Attributes = attributes
+	}
+}
+
+var now = time.Now
+
+// NewEvent returns a pending event for topic. payload is stored as is when it is a
+// string, []byte, or json.RawMessage and as JSON otherwise.
+func NewEvent(topic string, payload any, opts ...EventOption) (*Event, error) {
+	if topic == "" {
+		return nil, errors.New("outbox: event topic is required")
+	}
+	var body string
+	switch p := payload.(type) {
+	case string:
+		body = p
+	case []byte:
+		body = string(p)
+	case json.RawMessage:
+		body = string(p)
+	default:
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("outbox: failed to encode %s payload: %w", topic, err)
+		}
+		body = string(data)
+	}
+
+	event := &Event{
+		ID:         uuid.NewString(),
+		Topic:      topic,
+		Payload:    body,
+		Status:     StatusPending,
+		EnqueuedAt: now().UnixNano(),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(event)
+		}
+	}
+	return event, nil
+}
+
+// Enqueue adds a pending event for topic to tx, so it commits with the transaction's
+// other writes, and returns it.
+func Enqueue(tx core.TransactionBuilder, topic string, payload any, opts ...EventOption) (*Event, error) {
+	event, err := NewEvent(topic, payload, opts...)
+	if err != nil {
+		return nil, err
+	}
+	tx.Create(event)
+	return event, nil
+}
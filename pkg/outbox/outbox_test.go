@@ -0,0 +1,250 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/mocks"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+var fixedNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func useFixedClock(t *testing.T) {
+	t.Helper()
+	prev := now
+	now = func() time.Time { return fixedNow }
+	t.Cleanup(func() { now = prev })
+}
+
+// createRecorder is a TransactionBuilder recording Create calls.
+type createRecorder struct {
+	core.TransactionBuilder
+	created []any
+}
+
+func (c *createRecorder) Create(model any, _ ...core.TransactCondition) core.TransactionBuilder {
+	c.created = append(c.created, model)
+	return c
+}
+
+func TestEnqueueAddsPendingEvent(t *testing.T) {
+	useFixedClock(t)
+	tx := &createRecorder{}
+
+	event, err := Enqueue(tx, "payment.created", map[string]any{"id": "p1", "amount": 100},
+		WithKey("merchant-1"), WithAttributes(map[string]string{"source": "api"}))
+	require.NoError(t, err)
+
+	require.Equal(t, []any{event}, tx.created)
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, "payment.created", event.Topic)
+	assert.JSONEq(t, `{"id":"p1","amount":100}`, event.Payload)
+	assert.Equal(t, "merchant-1", event.Key)
+	assert.Equal(t, map[string]string{"source": "api"}, event.Attributes)
+	assert.Equal(t, StatusPending, event.Status)
+	assert.Equal(t, fixedNow.UnixNano(), event.EnqueuedAt)
+}
+
+func TestNewEventPayloadsAndValidation(t *testing.T) {
+	event, err := NewEvent("raw", []byte("bytes"), WithID("evt-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "bytes", event.Payload)
+	assert.Equal(t, "evt-1", event.ID)
+
+	event, err = NewEvent("text", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", event.Payload)
+
+	_, err = NewEvent("", "x")
+	assert.Error(t, err)
+	_, err = NewEvent("bad", make(chan int))
+	assert.Error(t, err)
+}
+
+// relayDB is a MockDB whose Model calls return q and are recorded.
+func relayDB(q *mocks.MockQuery) (*mocks.MockDB, *[]*Event) {
+	db := new(mocks.MockDB)
+	db.On("WithContext", mock.Anything).Return(db)
+	var models []*Event
+	db.On("Model", mock.AnythingOfType("*outbox.Event")).Run(func(args mock.Arguments) {
+		models = append(models, args.Get(0).(*Event))
+	}).Return(q)
+	return db, &models
+}
+
+func TestPollOncePublishesInOrderAndMarksPublished(t *testing.T) {
+	useFixedClock(t)
+	q := mocks.NewMockQuery()
+	q.ExpectAll([]Event{
+		{ID: "e1", Topic: "t", Status: StatusPending, EnqueuedAt: 1},
+		{ID: "e2", Topic: "t", Status: StatusPending, EnqueuedAt: 2},
+	})
+	q.On("Update", []string{"Status", "PublishedAt", "ExpiresAt"}).Return(nil)
+	db, models := relayDB(q)
+
+	var published []string
+	relay := NewRelay(db, PublisherFunc(func(_ context.Context, e *Event) error {
+		published = append(published, e.ID)
+		return nil
+	}), Options{Retention: time.Hour})
+
+	n, err := relay.PollOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"e1", "e2"}, published)
+
+	q.AssertCalled(t, "Index", StatusIndex)
+	q.AssertCalled(t, "Where", "Status", "=", StatusPending)
+	q.AssertCalled(t, "OrderBy", "EnqueuedAt", "asc")
+	q.AssertCalled(t, "Limit", DefaultBatchSize)
+	q.AssertCalled(t, "WithCondition", "Status", "=", StatusPending)
+
+	require.Len(t, *models, 3)
+	marked := (*models)[2]
+	assert.Equal(t, StatusPublished, marked.Status)
+	assert.Equal(t, fixedNow, marked.PublishedAt)
+	assert.Equal(t, fixedNow.Add(time.Hour).Unix(), marked.ExpiresAt)
+}
+
+func TestPollOnceStopsAtFailureAndEventuallyMarksFailed(t *testing.T) {
+	q := mocks.NewMockQuery()
+	q.ExpectAll([]Event{
+		{ID: "e1", Topic: "t", Status: StatusPending, Attempts: 2},
+		{ID: "e2", Topic: "t", Status: StatusPending},
+	})
+	q.On("Update", []string{"Attempts", "LastError", "Status"}).Return(nil)
+	db, models := relayDB(q)
+
+	boom := errors.New("sns unavailable")
+	var reported []error
+	var published []string
+	relay := NewRelay(db, PublisherFunc(func(_ context.Context, e *Event) error {
+		published = append(published, e.ID)
+		return boom
+	}), Options{MaxAttempts: 3, OnError: func(_ *Event, err error) { reported = append(reported, err) }})
+
+	n, err := relay.PollOnce(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Equal(t, []string{"e1"}, published, "later events wait behind the failed one")
+	assert.Equal(t, []error{boom}, reported)
+
+	failed := (*models)[1]
+	assert.Equal(t, 3, failed.Attempts)
+	assert.Equal(t, StatusFailed, failed.Status)
+	assert.Equal(t, "sns unavailable", failed.LastError)
+}
+
+func TestPollOnceIgnoresEventsAlreadyRecorded(t *testing.T) {
+	q := mocks.NewMockQuery()
+	q.ExpectAll([]Event{{ID: "e1", Topic: "t", Status: StatusPending}})
+	q.On("Update", mock.Anything).Return(customerrors.ErrConditionFailed)
+	db, _ := relayDB(q)
+
+	relay := NewRelay(db, PublisherFunc(func(context.Context, *Event) error { return nil }), Options{})
+	n, err := relay.PollOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestPollOnceReturnsRecordErrors(t *testing.T) {
+	q := mocks.NewMockQuery()
+	q.ExpectAll([]Event{{ID: "e1", Topic: "t", Status: StatusPending}})
+	q.On("Update", mock.Anything).Return(errors.New("throttled"))
+	db, _ := relayDB(q)
+
+	relay := NewRelay(db, PublisherFunc(func(context.Context, *Event) error { return nil }), Options{})
+	_, err := relay.PollOnce(context.Background())
+	assert.ErrorContains(t, err, "failed to record event e1")
+}
+
+func TestHandleStreamPublishesPendingInserts(t *testing.T) {
+	q := mocks.NewMockQuery()
+	q.On("First", mock.Anything).Run(func(args mock.Arguments) {
+		*args.Get(0).(*Event) = Event{ID: "e1", Topic: "t", Status: StatusPending, Attempts: 1}
+	}).Return(nil).Once()
+	q.On("First", mock.Anything).Run(mocks.FillDest(Event{ID: "e2", Topic: "t", Status: StatusPublished})).Return(nil).Once()
+	q.On("Update", mock.Anything).Return(nil)
+	db, _ := relayDB(q)
+
+	var published []string
+	relay := NewRelay(db, PublisherFunc(func(_ context.Context, e *Event) error {
+		published = append(published, e.ID)
+		return nil
+	}), Options{})
+
+	record := func(name, id string) events.DynamoDBEventRecord {
+		return events.DynamoDBEventRecord{
+			EventName: name,
+			Change: events.DynamoDBStreamRecord{NewImage: map[string]events.DynamoDBAttributeValue{
+				"id":     events.NewStringAttribute(id),
+				"topic":  events.NewStringAttribute("t"),
+				"status": events.NewStringAttribute(StatusPending),
+			}},
+		}
+	}
+	err := relay.HandleStream(context.Background(), events.DynamoDBEvent{Records: []events.DynamoDBEventRecord{
+		record("INSERT", "e1"),
+		record("MODIFY", "e1"),
+		record("INSERT", "e2"),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"e1"}, published)
+	q.AssertCalled(t, "Where", "ID", "=", "e2")
+	q.AssertCalled(t, "ConsistentRead")
+}
+
+func TestHandleStreamFailsBatchForRetry(t *testing.T) {
+	q := mocks.NewMockQuery()
+	q.ExpectFirst(Event{ID: "e1", Topic: "t", Status: StatusPending})
+	q.On("Update", mock.Anything).Return(nil)
+	db, _ := relayDB(q)
+
+	relay := NewRelay(db, PublisherFunc(func(context.Context, *Event) error { return errors.New("queue full") }), Options{})
+	err := relay.HandleStream(context.Background(), events.DynamoDBEvent{Records: []events.DynamoDBEventRecord{{
+		EventName: "INSERT",
+		Change: events.DynamoDBStreamRecord{NewImage: map[string]events.DynamoDBAttributeValue{
+			"id":    events.NewStringAttribute("e1"),
+			"topic": events.NewStringAttribute("t"),
+		}},
+	}}})
+	assert.ErrorContains(t, err, "queue full")
+}
+
+func TestRunStopsWithContext(t *testing.T) {
+	q := mocks.NewMockQuery()
+	q.ExpectAll([]Event{})
+	db, _ := relayDB(q)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	relay := NewRelay(db, PublisherFunc(func(context.Context, *Event) error { return nil }), Options{Interval: 5 * time.Millisecond})
+	assert.ErrorIs(t, relay.Run(ctx), context.DeadlineExceeded)
+	assert.Greater(t, len(q.Calls), 1)
+}
+
+func TestEventModelMetadata(t *testing.T) {
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&Event{}))
+	metadata, err := registry.GetMetadata(&Event{})
+	require.NoError(t, err)
+
+	assert.Equal(t, Table, metadata.TableName)
+	assert.Equal(t, "id", metadata.PrimaryKey.PartitionKey.DBName)
+	require.Len(t, metadata.Indexes, 1)
+	assert.Equal(t, StatusIndex, metadata.Indexes[0].Name)
+	assert.Equal(t, "status", metadata.Indexes[0].PartitionKey.DBName)
+	assert.Equal(t, "enqueuedAt", metadata.Indexes[0].SortKey.DBName)
+	require.NotNil(t, metadata.TTLField)
+	assert.Equal(t, "expiresAt", metadata.TTLField.DBName)
+}
@@ -0,0 +1,214 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/pay-theory/dynamorm"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// Defaults applied to zero Options fields.
+const (
+	DefaultBatchSize    = 25
+	DefaultInterval     = time.Second
+	DefaultMaxAttempts  = 10
+	DefaultRetention    = 7 * 24 * time.Hour
+	maxErrorMessageSize = 1024
+)
+
+// Publisher delivers an event to a broker such as SNS, SQS, or EventBridge.
+type Publisher interface {
+	Publish(ctx context.Context, event *Event) error
+}
+
+// PublisherFunc adapts a function to Publisher.
+type PublisherFunc func(ctx context.Context, event *Event) error
+
+// Publish calls f.
+func (f PublisherFunc) Publish(ctx context.Context, event *Event) error {
+	return f(ctx, event)
+}
+
+// Options configures a Relay.
+type Options struct {
+	// OnError is called when publishing an event or recording its outcome fails.
+	OnError func(event *Event, err error)
+	// BatchSize is the number of pending events PollOnce reads.
+	BatchSize int
+	// Interval is how long Run waits after a poll that found no events.
+	Interval time.Duration
+	// MaxAttempts is the number of failed publishes after which an event is marked
+	// StatusFailed instead of retried.
+	MaxAttempts int
+	// Retention is how long published events are kept before DynamoDB TTL removes
+	// them. Negative keeps them forever.
+	Retention time.Duration
+}
+
+// Relay publishes pending outbox events.
+type Relay struct {
+	db        core.DB
+	publisher Publisher
+	opts      Options
+}
+
+// NewRelay returns a relay publishing the events in db's outbox table with publisher.
+func NewRelay(db core.DB, publisher Publisher, opts Options) *Relay {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Retention == 0 {
+		opts.Retention = DefaultRetention
+	}
+	return &Relay{db: db, publisher: publisher, opts: opts}
+}
+
+// Run polls until ctx is done, polling again at once while polls find events.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		published, err := r.PollOnce(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil || published == 0 {
+			timer := time.NewTimer(r.opts.Interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+}
+
+// PollOnce publishes up to BatchSize pending events, oldest first, and returns how many
+// it published. It stops at the first event that fails to publish, which stays pending,
+// so later events do not overtake it until it is marked failed.
+func (r *Relay) PollOnce(ctx context.Context) (int, error) {
+	var pending []Event
+	err := r.db.WithContext(ctx).Model(&Event{}).
+		Index(StatusIndex).
+		Where("Status", "=", StatusPending).
+		OrderBy("EnqueuedAt", "asc").
+		Limit(r.opts.BatchSize).
+		All(&pending)
+	if err != nil {
+		return 0, fmt.Errorf("outbox: failed to read pending events: %w", err)
+	}
+
+	published := 0
+	for i := range pending {
+		ok, err := r.deliver(ctx, &pending[i])
+		if err != nil {
+			return published, err
+		}
+		if !ok {
+			break
+		}
+		published++
+	}
+	return published, nil
+}
+
+// HandleStream publishes the events inserted into the outbox table, for a Lambda
+// function subscribed to its DynamoDB stream. Each event is read back first, so events
+// already published, by the poller or an earlier delivery of the batch, are skipped. It
+// returns an error, so Lambda retries the batch, when an event fails to publish before
+// reaching MaxAttempts.
+func (r *Relay) HandleStream(ctx context.Context, event events.DynamoDBEvent) error {
+	for _, record := range event.Records {
+		if record.EventName != string(events.DynamoDBOperationTypeInsert) {
+			continue
+		}
+		var inserted Event
+		if err := dynamorm.UnmarshalStreamImage(record.Change.NewImage, &inserted); err != nil {
+			return fmt.Errorf("outbox: failed to decode stream record %s: %w", record.EventID, err)
+		}
+		if inserted.ID == "" || inserted.Topic == "" {
+			continue
+		}
+
+		// The stream image is as inserted; read the event's current status and attempts,
+		// which earlier deliveries of this batch or the poller may have changed.
+		var e Event
+		err := r.db.WithContext(ctx).Model(&Event{}).Where("ID", "=", inserted.ID).ConsistentRead().First(&e)
+		if errors.Is(err, customerrors.ErrItemNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("outbox: failed to read event %s: %w", inserted.ID, err)
+		}
+		if e.Status != StatusPending {
+			continue
+		}
+		ok, err := r.deliver(ctx, &e)
+		if err != nil {
+			return err
+		}
+		if !ok && e.Status == StatusPending {
+			return fmt.Errorf("outbox: failed to publish event %s: %s", e.ID, e.LastError)
+		}
+	}
+	return nil
+}
+
+// deliver publishes event and records the outcome, reporting whether it was published.
+// The error is for failures to record the outcome.
+func (r *Relay) deliver(ctx context.Context, event *Event) (bool, error) {
+	if err := r.publisher.Publish(ctx, event); err != nil {
+		r.report(event, err)
+		event.Attempts++
+		event.LastError = truncate(err.Error(), maxErrorMessageSize)
+		if event.Attempts >= r.opts.MaxAttempts {
+			event.Status = StatusFailed
+		}
+		return false, r.record(ctx, event, "Attempts", "LastError", "Status")
+	}
+
+	event.Status = StatusPublished
+	event.PublishedAt = now().UTC()
+	if r.opts.Retention > 0 {
+		event.ExpiresAt = event.PublishedAt.Add(r.opts.Retention).Unix()
+	}
+	return true, r.record(ctx, event, "Status", "PublishedAt", "ExpiresAt")
+}
+
+// record updates fields of an event that is still pending. An event another relay has
+// already moved on is left alone.
+func (r *Relay) record(ctx context.Context, event *Event, fields ...string) error {
+	err := r.db.WithContext(ctx).Model(event).
+		WithCondition("Status", "=", StatusPending).
+		Update(fields...)
+	if err == nil || errors.Is(err, customerrors.ErrConditionFailed) {
+		return nil
+	}
+	err = fmt.Errorf("outbox: failed to record event %s as %s: %w", event.ID, event.Status, err)
+	r.report(event, err)
+	return err
+}
+
+func (r *Relay) report(event *Event, err error) {
+	if r.opts.OnError != nil {
+		r.opts.OnError(event, err)
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,196 @@
+// Package prometheus exports DynamORM operation metrics to Prometheus, for services on
+// ECS, EKS, or anywhere else a scraper can reach. Lambda functions should use pkg/emf.
+//
+// It is a separate module so applications that do not use Prometheus do not pull in its
+// client. Register a Collector with a registry and wire its middleware in with DB.Use:
+//
+//	collector := prometheus.NewCollector(prometheus.WithNamespace("payments"))
+//	registry.MustRegister(collector)
+//	db.Use(collector.Middleware())
+//
+// The collector exports these metrics, labeled by table and operation:
+//
+//	dynamorm_operations_total            operations, also labeled by outcome (ok or error)
+//	dynamorm_operation_duration_seconds  latency from the middleware to the response
+//	dynamorm_items_total                 items returned or written
+//	dynamorm_retries_total               attempts beyond the first
+//	dynamorm_throttles_total             attempts DynamoDB throttled
+//	dynamorm_cache_requests_total        item cache lookups, labeled by result (hit or miss)
+//
+// The cache hit rate is hits over all cache requests, for example:
+//
+//	sum(rate(dynamorm_cache_requests_total{result="hit"}[5m]))
+//	  / sum(rate(dynamorm_cache_requests_total[5m]))
+package prometheus
+
+import (
+	"context"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/pay-theory/dynamorm"
+)
+
+// DefaultNamespace prefixes metric names unless WithNamespace sets another.
+const DefaultNamespace = "dynamorm"
+
+// Label names.
+const (
+	LabelTable     = "table"
+	LabelOperation = "operation"
+	LabelOutcome   = "outcome"
+	LabelResult    = "result"
+)
+
+// Outcome label values.
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+)
+
+type config struct {
+	now         func() time.Time
+	constLabels prom.Labels
+	namespace   string
+	buckets     []float64
+}
+
+// Option configures NewCollector.
+type Option func(*config)
+
+// WithNamespace sets the prefix of metric names.
+func WithNamespace(namespace string) Option {
+	return func(c *config) {
+		if namespace != "" {
+			c.namespace = namespace
+		}
+	}
+}
+
+// WithConstLabels adds fixed labels, such as the service, to every metric.
+func WithConstLabels(labels map[string]string) Option {
+	return func(c *config) {
+		for k, v := range labels {
+			c.constLabels[k] = v
+		}
+	}
+}
+
+// WithBuckets sets the latency histogram's buckets, in seconds. The default is
+// prometheus.DefBuckets.
+func WithBuckets(buckets []float64) Option {
+	return func(c *config) {
+		if len(buckets) > 0 {
+			c.buckets = buckets
+		}
+	}
+}
+
+// Collector records DynamORM operations and exports them as Prometheus metrics. It
+// implements prometheus.Collector.
+type Collector struct {
+	now        func() time.Time
+	operations *prom.CounterVec
+	duration   *prom.HistogramVec
+	items      *prom.CounterVec
+	retries    *prom.CounterVec
+	throttles  *prom.CounterVec
+	cache      *prom.CounterVec
+}
+
+var _ prom.Collector = (*Collector)(nil)
+
+// NewCollector returns a Collector with no recorded operations.
+func NewCollector(opts ...Option) *Collector {
+	cfg := config{
+		now:         time.Now,
+		namespace:   DefaultNamespace,
+		constLabels: make(prom.Labels),
+		buckets:     prom.DefBuckets,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return newCollector(cfg)
+}
+
+func newCollector(cfg config) *Collector {
+	counter := func(name, help string, labels ...string) *prom.CounterVec {
+		return prom.NewCounterVec(prom.CounterOpts{
+			Namespace:   cfg.namespace,
+			Name:        name,
+			Help:        help,
+			ConstLabels: cfg.constLabels,
+		}, append([]string{LabelTable, LabelOperation}, labels...))
+	}
+
+	return &Collector{
+		now:        cfg.now,
+		operations: counter("operations_total", "DynamoDB operations sent by DynamORM.", LabelOutcome),
+		duration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace:   cfg.namespace,
+			Name:        "operation_duration_seconds",
+			Help:        "Latency of DynamoDB operations, retries included.",
+			ConstLabels: cfg.constLabels,
+			Buckets:     cfg.buckets,
+		}, []string{LabelTable, LabelOperation}),
+		items:     counter("items_total", "Items returned or written."),
+		retries:   counter("retries_total", "Attempts beyond the first."),
+		throttles: counter("throttles_total", "Attempts DynamoDB throttled."),
+		cache:     counter("cache_requests_total", "Item cache lookups.", LabelResult),
+	}
+}
+
+// Middleware returns DynamORM middleware that records every operation in c.
+func (c *Collector) Middleware() dynamorm.Middleware {
+	return func(next dynamorm.Handler) dynamorm.Handler {
+		return func(ctx context.Context, op *dynamorm.Operation) error {
+			start := c.now()
+			err := next(ctx, op)
+			c.record(op, c.now().Sub(start), err)
+			return err
+		}
+	}
+}
+
+func (c *Collector) record(op *dynamorm.Operation, latency time.Duration, err error) {
+	if op == nil {
+		return
+	}
+	outcome := OutcomeOK
+	if err != nil {
+		outcome = OutcomeError
+	}
+
+	c.operations.WithLabelValues(op.Table, op.Type, outcome).Inc()
+	c.duration.WithLabelValues(op.Table, op.Type).Observe(latency.Seconds())
+	c.items.WithLabelValues(op.Table, op.Type).Add(float64(op.ItemCount))
+	if op.Attempts > 1 {
+		c.retries.WithLabelValues(op.Table, op.Type).Add(float64(op.Attempts - 1))
+	}
+	if op.Throttles > 0 {
+		c.throttles.WithLabelValues(op.Table, op.Type).Add(float64(op.Throttles))
+	}
+	if op.Cache != "" {
+		c.cache.WithLabelValues(op.Table, op.Type, op.Cache).Inc()
+	}
+}
+
+func (c *Collector) collectors() []prom.Collector {
+	return []prom.Collector{c.operations, c.duration, c.items, c.retries, c.throttles, c.cache}
+}
+
+// Describe sends the descriptors of every metric c exports.
+func (c *Collector) Describe(ch chan<- *prom.Desc) {
+	for _, collector := range c.collectors() {
+		collector.Describe(ch)
+	}
+}
+
+// Collect sends the current value of every metric c exports.
+func (c *Collector) Collect(ch chan<- prom.Metric) {
+	for _, collector := range c.collectors() {
+		collector.Collect(ch)
+	}
+}
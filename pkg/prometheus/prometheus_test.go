@@ -0,0 +1,111 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm"
+)
+
+// steppingClock advances by step on every reading.
+func steppingClock(start time.Time, step time.Duration) func() time.Time {
+	now := start
+	return func() time.Time {
+		current := now
+		now = now.Add(step)
+		return current
+	}
+}
+
+func testCollector(opts ...Option) *Collector {
+	cfg := config{
+		now:         steppingClock(time.Unix(1_700_000_000, 0), 30*time.Millisecond),
+		namespace:   DefaultNamespace,
+		constLabels: make(prom.Labels),
+		buckets:     []float64{0.01, 0.1},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return newCollector(cfg)
+}
+
+func TestCollectorRecordsOperations(t *testing.T) {
+	collector := testCollector(WithConstLabels(map[string]string{"service": "ledger"}))
+	registry := prom.NewPedanticRegistry()
+	require.NoError(t, registry.Register(collector))
+
+	middleware := collector.Middleware()
+	query := middleware(func(_ context.Context, op *dynamorm.Operation) error {
+		op.ItemCount = 3
+		op.Attempts = 3
+		op.Throttles = 2
+		return nil
+	})
+	require.NoError(t, query(context.Background(), &dynamorm.Operation{Type: dynamorm.OperationQuery, Table: "orders"}))
+
+	hit := middleware(func(_ context.Context, op *dynamorm.Operation) error {
+		op.ItemCount = 1
+		op.Cache = dynamorm.CacheHit
+		return nil
+	})
+	require.NoError(t, hit(context.Background(), &dynamorm.Operation{Type: dynamorm.OperationGetItem, Table: "orders"}))
+
+	failing := middleware(func(_ context.Context, op *dynamorm.Operation) error {
+		op.Attempts = 1
+		op.Cache = dynamorm.CacheMiss
+		return errors.New("boom")
+	})
+	require.Error(t, failing(context.Background(), &dynamorm.Operation{Type: dynamorm.OperationGetItem, Table: "orders"}))
+
+	expected := `
+# HELP dynamorm_cache_requests_total Item cache lookups.
+# TYPE dynamorm_cache_requests_total counter
+dynamorm_cache_requests_total{operation="GetItem",result="hit",service="ledger",table="orders"} 1
+dynamorm_cache_requests_total{operation="GetItem",result="miss",service="ledger",table="orders"} 1
+# HELP dynamorm_items_total Items returned or written.
+# TYPE dynamorm_items_total counter
+dynamorm_items_total{operation="GetItem",service="ledger",table="orders"} 1
+dynamorm_items_total{operation="Query",service="ledger",table="orders"} 3
+# HELP dynamorm_operation_duration_seconds Latency of DynamoDB operations, retries included.
+# TYPE dynamorm_operation_duration_seconds histogram
+dynamorm_operation_duration_seconds_bucket{operation="GetItem",service="ledger",table="orders",le="0.01"} 0
+dynamorm_operation_duration_seconds_bucket{operation="GetItem",service="ledger",table="orders",le="0.1"} 2
+dynamorm_operation_duration_seconds_bucket{operation="GetItem",service="ledger",table="orders",le="+Inf"} 2
+dynamorm_operation_duration_seconds_sum{operation="GetItem",service="ledger",table="orders"} 0.06
+dynamorm_operation_duration_seconds_count{operation="GetItem",service="ledger",table="orders"} 2
+dynamorm_operation_duration_seconds_bucket{operation="Query",service="ledger",table="orders",le="0.01"} 0
+dynamorm_operation_duration_seconds_bucket{operation="Query",service="ledger",table="orders",le="0.1"} 1
+dynamorm_operation_duration_seconds_bucket{operation="Query",service="ledger",table="orders",le="+Inf"} 1
+dynamorm_operation_duration_seconds_sum{operation="Query",service="ledger",table="orders"} 0.03
+dynamorm_operation_duration_seconds_count{operation="Query",service="ledger",table="orders"} 1
+# HELP dynamorm_operations_total DynamoDB operations sent by DynamORM.
+# TYPE dynamorm_operations_total counter
+dynamorm_operations_total{operation="GetItem",outcome="error",service="ledger",table="orders"} 1
+dynamorm_operations_total{operation="GetItem",outcome="ok",service="ledger",table="orders"} 1
+dynamorm_operations_total{operation="Query",outcome="ok",service="ledger",table="orders"} 1
+# HELP dynamorm_retries_total Attempts beyond the first.
+# TYPE dynamorm_retries_total counter
+dynamorm_retries_total{operation="Query",service="ledger",table="orders"} 2
+# HELP dynamorm_throttles_total Attempts DynamoDB throttled.
+# TYPE dynamorm_throttles_total counter
+dynamorm_throttles_total{operation="Query",service="ledger",table="orders"} 2
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected)))
+}
+
+func TestCollectorNamespace(t *testing.T) {
+	collector := testCollector(WithNamespace("payments"))
+	handler := collector.Middleware()(func(context.Context, *dynamorm.Operation) error { return nil })
+	require.NoError(t, handler(context.Background(), &dynamorm.Operation{Type: dynamorm.OperationPutItem, Table: "orders"}))
+
+	require.Equal(t, 1, testutil.CollectAndCount(collector, "payments_operations_total"))
+	require.Equal(t, float64(1), testutil.ToFloat64(collector.operations.WithLabelValues("orders", dynamorm.OperationPutItem, OutcomeOK)))
+}
@@ -0,0 +1,341 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	dynamormErrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// BatchCreateWithOptions writes items like BatchCreate but reports the outcome of each
+// item instead of stopping at the first problem. Unprocessed and throttled items are
+// retried within opts.RetryPolicy; items still unwritten after that, or when the query's
+// context ends during a backoff, are reported as throttled. An item whose primary key
+// repeats an earlier item in the same BatchWriteItem chunk is reported as failed, since
+// DynamoDB rejects the whole request otherwise. When the query carries write conditions (IfNotExists, WithCondition, ...),
+// which BatchWriteItem cannot express, each item is written with its own conditional
+// PutItem and items failing the condition are reported as failed.
+//
+// If any item was not written, the result is returned together with a
+// *errors.BatchWriteError; Unwritten lists the indexes to resume with.
+func (q *Query) BatchCreateWithOptions(items any, opts *core.BatchCreateOptions) (*core.BatchCreateResult, error) {
+	if err := q.checkBuilderError(); err != nil {
+		return nil, err
+	}
+	itemsValue := reflect.ValueOf(items)
+	if itemsValue.Kind() != reflect.Slice {
+		return nil, errors.New("items must be a slice")
+	}
+	if opts == nil {
+		opts = core.DefaultBatchCreateOptions()
+	}
+	policy := opts.RetryPolicy
+	if policy == nil {
+		policy = &core.RetryPolicy{}
+	}
+
+	total := itemsValue.Len()
+	result := &core.BatchCreateResult{Items: make([]core.BatchItemOutcome, total)}
+	marshaled := make([]map[string]types.AttributeValue, total)
+	for i := 0; i < total; i++ {
+		result.Items[i].Index = i
+		item := hookTarget(itemsValue.Index(i))
+		if err := runBeforeHook(q.hookContext(), item, hookCreate); err != nil {
+			markBatchItem(result, i, core.BatchItemFailed, err)
+			continue
+		}
+		if err := q.validateModel(item, nil); err != nil {
+			markBatchItem(result, i, core.BatchItemFailed, err)
+			continue
+		}
+		av, err := q.marshalItem(item)
+		if err != nil {
+			markBatchItem(result, i, core.BatchItemFailed, fmt.Errorf("failed to marshal item: %w", err))
+			continue
+		}
+		marshaled[i] = av
+	}
+
+	if len(q.writeConditions) > 0 || len(q.rawConditionExpressions) > 0 {
+		if err := q.putItemsConditionally(marshaled, result, policy); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := q.batchPutItems(marshaled, result, policy, opts.ChunkSize, opts.Feedback); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range result.Items {
+		if result.Items[i].Status != core.BatchItemSucceeded {
+			continue
+		}
+		// The item is stored, but the caller's after hook did not run to completion.
+		if err := runAfterHook(q.hookContext(), hookTarget(itemsValue.Index(i)), hookCreate); err != nil {
+			result.Items[i].Status = core.BatchItemFailed
+			result.Items[i].Err = err
+		}
+	}
+
+	for _, outcome := range result.Items {
+		switch outcome.Status {
+		case core.BatchItemSucceeded:
+			result.Succeeded++
+		case core.BatchItemFailed:
+			result.Failed++
+		case core.BatchItemThrottled:
+			result.Throttled++
+		}
+	}
+	if result.Succeeded == total {
+		return result, nil
+	}
+	return result, &dynamormErrors.BatchWriteError{
+		Operation: "BatchCreate",
+		Total:     total,
+		Succeeded: result.Succeeded,
+		Failed:    result.Failed,
+		Throttled: result.Throttled,
+	}
+}
+
+func markBatchItem(result *core.BatchCreateResult, index int, status core.BatchItemStatus, err error) {
+	result.Items[index].Status = status
+	result.Items[index].Err = err
+}
+
+// markUnwritten reports every item that has no outcome yet as throttled with err.
+func markUnwritten(result *core.BatchCreateResult, err error) {
+	for index := range result.Items {
+		if result.Items[index].Status == "" {
+			markBatchItem(result, index, core.BatchItemThrottled, err)
+		}
+	}
+}
+
+// batchPutItems writes the marshaled items that have no outcome yet with BatchWriteItem,
+// matching unprocessed items back to their input index by primary key. With feedback,
+// each chunk is sized by the table's current scale and every call's unprocessed or
+// throttled items are reported to it.
+func (q *Query) batchPutItems(marshaled []map[string]types.AttributeValue, result *core.BatchCreateResult, policy *core.RetryPolicy, chunkSize int, feedback core.ThrottleFeedback) error {
+	executor, ok := q.executor.(BatchWriteItemExecutor)
+	if !ok {
+		return fmt.Errorf("executor does not support batch write operations")
+	}
+	if chunkSize <= 0 || chunkSize > 25 {
+		chunkSize = 25
+	}
+
+	pending := make([]int, 0, len(marshaled))
+	for i, av := range marshaled {
+		if av != nil && result.Items[i].Status == "" {
+			pending = append(pending, i)
+		}
+	}
+
+	tableName := q.metadata.TableName()
+	budget := q.newDeadlineBudget()
+	for start, end := 0, 0; start < len(pending); start = end {
+		size := chunkSize
+		if feedback != nil {
+			size = scaledChunkSize(chunkSize, feedback.Scale(tableName))
+		}
+		end = min(start+size, len(pending))
+		chunk := pending[start:end]
+
+		if !budget.CanStart() {
+			for _, index := range pending[start:] {
+				markBatchItem(result, index, core.BatchItemThrottled, dynamormErrors.ErrDeadlineBudgetExhausted)
+			}
+			return nil
+		}
+
+		byKey := make(map[string]int, len(chunk))
+		requests := make([]types.WriteRequest, 0, len(chunk))
+		for _, index := range chunk {
+			key := q.batchItemKey(marshaled[index])
+			if first, dup := byKey[key]; dup {
+				markBatchItem(result, index, core.BatchItemFailed,
+					fmt.Errorf("item has the same primary key as item %d in its batch", first))
+				continue
+			}
+			byKey[key] = index
+			requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: marshaled[index]}})
+		}
+
+		for attempt := 0; ; attempt++ {
+			done := budget.Start()
+			output, err := executor.ExecuteBatchWriteItem(tableName, requests)
+			done()
+
+			if err != nil {
+				status := core.BatchItemFailed
+				if isRetryableError(err) {
+					if feedback != nil {
+						feedback.Observe(tableName, len(requests), len(requests))
+					}
+					if attempt < policy.MaxRetries {
+						if waitErr := q.waitForRetry(calculateBatchRetryDelay(policy, attempt)); waitErr != nil {
+							markUnwritten(result, waitErr)
+							return nil
+						}
+						continue
+					}
+					status = core.BatchItemThrottled
+				}
+				for _, index := range byKey {
+					markBatchItem(result, index, status, err)
+				}
+				break
+			}
+
+			var unprocessed []types.WriteRequest
+			if output != nil {
+				for _, writes := range output.UnprocessedItems {
+					unprocessed = append(unprocessed, writes...)
+				}
+			}
+			remaining := make(map[string]int, len(unprocessed))
+			for _, write := range unprocessed {
+				if write.PutRequest == nil {
+					continue
+				}
+				key := q.batchItemKey(write.PutRequest.Item)
+				if index, ok := byKey[key]; ok {
+					remaining[key] = index
+				}
+			}
+			for key, index := range byKey {
+				if _, left := remaining[key]; !left {
+					markBatchItem(result, index, core.BatchItemSucceeded, nil)
+				}
+			}
+			if feedback != nil {
+				feedback.Observe(tableName, len(requests), len(remaining))
+			}
+			if len(remaining) == 0 {
+				break
+			}
+			if attempt >= policy.MaxRetries {
+				for _, index := range remaining {
+					markBatchItem(result, index, core.BatchItemThrottled,
+						fmt.Errorf("item still unprocessed after %d attempts", attempt+1))
+				}
+				break
+			}
+
+			if err := q.waitForRetry(calculateBatchRetryDelay(policy, attempt)); err != nil {
+				markUnwritten(result, err)
+				return nil
+			}
+			byKey = remaining
+			requests = requests[:0]
+			for _, index := range remaining {
+				requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: marshaled[index]}})
+			}
+		}
+	}
+	return nil
+}
+
+// scaledChunkSize scales chunkSize by scale, rounding up to at least one item.
+func scaledChunkSize(chunkSize int, scale float64) int {
+	if scale >= 1 {
+		return chunkSize
+	}
+	return max(int(math.Ceil(float64(chunkSize)*scale)), 1)
+}
+
+// putItemsConditionally writes each marshaled item that has no outcome yet with its own
+// PutItem carrying the query's write conditions.
+func (q *Query) putItemsConditionally(marshaled []map[string]types.AttributeValue, result *core.BatchCreateResult, policy *core.RetryPolicy) error {
+	executor, ok := q.executor.(PutItemExecutor)
+	if !ok {
+		return fmt.Errorf("executor does not support PutItem operation")
+	}
+
+	conditionExpr, names, values, err := q.buildConditionExpression(nil, false, false, false)
+	if err != nil {
+		return err
+	}
+	compiled := &core.CompiledQuery{
+		Operation:           "PutItem",
+		TableName:           q.metadata.TableName(),
+		ConditionExpression: conditionExpr,
+	}
+	if len(names) > 0 {
+		compiled.ExpressionAttributeNames = names
+	}
+	if len(values) > 0 {
+		compiled.ExpressionAttributeValues = values
+	}
+
+	budget := q.newDeadlineBudget()
+	for index, av := range marshaled {
+		if av == nil || result.Items[index].Status != "" {
+			continue
+		}
+		if !budget.CanStart() {
+			markBatchItem(result, index, core.BatchItemThrottled, dynamormErrors.ErrDeadlineBudgetExhausted)
+			continue
+		}
+
+		for attempt := 0; ; attempt++ {
+			done := budget.Start()
+			err := executor.ExecutePutItem(compiled, av)
+			done()
+
+			switch {
+			case err == nil:
+				markBatchItem(result, index, core.BatchItemSucceeded, nil)
+			case errors.Is(err, dynamormErrors.ErrConditionFailed):
+				markBatchItem(result, index, core.BatchItemFailed, err)
+			case isRetryableError(err) && attempt < policy.MaxRetries:
+				if waitErr := q.waitForRetry(calculateBatchRetryDelay(policy, attempt)); waitErr != nil {
+					markUnwritten(result, waitErr)
+					return nil
+				}
+				continue
+			case isRetryableError(err):
+				markBatchItem(result, index, core.BatchItemThrottled, err)
+			default:
+				markBatchItem(result, index, core.BatchItemFailed, err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// batchItemKey identifies an item by its primary key attribute values.
+func (q *Query) batchItemKey(item map[string]types.AttributeValue) string {
+	schema := q.metadata.PrimaryKey()
+	var b strings.Builder
+	for _, field := range []string{schema.PartitionKey, schema.SortKey} {
+		if field == "" {
+			continue
+		}
+		b.WriteString(attributeValueKey(item[q.resolveAttributeName(field)]))
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+func attributeValueKey(av types.AttributeValue) string {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return "S:" + v.Value
+	case *types.AttributeValueMemberN:
+		return "N:" + v.Value
+	case *types.AttributeValueMemberB:
+		return "B:" + string(v.Value)
+	default:
+		return fmt.Sprintf("%T:%v", av, av)
+	}
+}
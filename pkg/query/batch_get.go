@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
@@ -20,39 +21,151 @@ func (q *Query) BatchGet(keys []any, dest any) error {
 
 // BatchGetWithOptions retrieves items with fine-grained control over chunking, retries, and callbacks.
 func (q *Query) BatchGetWithOptions(keys []any, dest any, opts *core.BatchGetOptions) error {
-	if err := q.checkBuilderError(); err != nil {
+	if err := q.validateBatchGetDestination(keys, dest); err != nil {
 		return err
 	}
 
-	if err := q.validateBatchGetInputs(keys, dest); err != nil {
+	ordered, err := q.batchGetAligned(keys, opts)
+	if err != nil {
 		return err
 	}
+	flattened := flattenBatchGetResults(ordered)
 
-	executor, err := q.batchGetExecutor()
-	if err != nil {
+	if rawDest, ok := dest.(*[]map[string]types.AttributeValue); ok {
+		*rawDest = append((*rawDest)[:0], flattened...)
+		return nil
+	}
+
+	if q.rawMetadata != nil && q.converter != nil {
+		return q.unmarshalItemsWithMetadata(flattened, dest)
+	}
+
+	return UnmarshalItems(flattened, dest)
+}
+
+// BatchGetOrdered retrieves items by primary key into dest, a pointer to a slice, with
+// dest[i] holding the item for keys[i]. Keys without an item leave the zero value, or nil
+// for a slice of pointers.
+func (q *Query) BatchGetOrdered(keys []any, dest any) error {
+	if err := q.validateBatchGetDestination(keys, dest); err != nil {
 		return err
 	}
 
-	effectiveOpts, keySpecs, chunks, err := q.buildBatchGetPlan(keys, opts)
+	ordered, err := q.batchGetAligned(keys, nil)
 	if err != nil {
 		return err
 	}
 
-	flattened, err := executeBatchGetChunks(executor, chunks, keySpecs, effectiveOpts)
+	if rawDest, ok := dest.(*[]map[string]types.AttributeValue); ok {
+		*rawDest = ordered
+		return nil
+	}
+
+	sliceValue := reflect.ValueOf(dest).Elem()
+	aligned := reflect.MakeSlice(sliceValue.Type(), len(ordered), len(ordered))
+	for i, item := range ordered {
+		if item == nil {
+			continue
+		}
+		if err := q.unmarshalBatchGetItem(item, aligned.Index(i)); err != nil {
+			return fmt.Errorf("failed to unmarshal item %d: %w", i, err)
+		}
+	}
+	sliceValue.Set(aligned)
+	return nil
+}
+
+// BatchGetMap retrieves items by primary key into dest, a pointer to a map keyed by the
+// values passed in keys, such as map[string]Order for partition keys or
+// map[core.KeyPair]*Order for composite keys. Keys without an item are left out.
+func (q *Query) BatchGetMap(keys []any, dest any) error {
+	if err := q.checkBuilderError(); err != nil {
+		return err
+	}
+	if q.metadata == nil {
+		return errors.New("model metadata is required for batch get")
+	}
+	if len(keys) == 0 {
+		return errors.New("no keys provided")
+	}
+
+	destValue := reflect.ValueOf(dest)
+	if !destValue.IsValid() || destValue.Kind() != reflect.Ptr || destValue.IsNil() || destValue.Elem().Kind() != reflect.Map {
+		return errors.New("dest must be a pointer to map")
+	}
+	mapValue := destValue.Elem()
+	keyType := mapValue.Type().Key()
+	mapKeys := make([]reflect.Value, len(keys))
+	for i, key := range keys {
+		keyValue := reflect.ValueOf(key)
+		if !keyValue.IsValid() || !keyValue.Type().ConvertibleTo(keyType) || !keyValue.Comparable() {
+			return fmt.Errorf("key %d of type %T cannot be used as a %s map key", i, key, keyType)
+		}
+		mapKeys[i] = keyValue.Convert(keyType)
+	}
+
+	ordered, err := q.batchGetAligned(keys, nil)
 	if err != nil {
 		return err
 	}
 
-	if rawDest, ok := dest.(*[]map[string]types.AttributeValue); ok {
-		*rawDest = append((*rawDest)[:0], flattened...)
+	if mapValue.IsNil() {
+		mapValue.Set(reflect.MakeMapWithSize(mapValue.Type(), len(keys)))
+	}
+	elemType := mapValue.Type().Elem()
+	for i, item := range ordered {
+		if item == nil {
+			continue
+		}
+		elem := reflect.New(elemType).Elem()
+		if err := q.unmarshalBatchGetItem(item, elem); err != nil {
+			return fmt.Errorf("failed to unmarshal item %d: %w", i, err)
+		}
+		mapValue.SetMapIndex(mapKeys[i], elem)
+	}
+	return nil
+}
+
+// unmarshalBatchGetItem unmarshals item into target, a settable struct, struct pointer, or
+// raw attribute map value.
+func (q *Query) unmarshalBatchGetItem(item map[string]types.AttributeValue, target reflect.Value) error {
+	if raw, ok := target.Addr().Interface().(*map[string]types.AttributeValue); ok {
+		*raw = item
 		return nil
 	}
 
+	dest := target.Addr()
+	if target.Kind() == reflect.Ptr {
+		target.Set(reflect.New(target.Type().Elem()))
+		dest = target
+	}
 	if q.rawMetadata != nil && q.converter != nil {
-		return q.unmarshalItemsWithMetadata(flattened, dest)
+		return q.unmarshalItemWithMetadata(item, dest.Interface())
 	}
+	return UnmarshalItem(item, dest.Interface())
+}
 
-	return UnmarshalItems(flattened, dest)
+// batchGetAligned fetches keys and returns the items in key order, with nil for keys that
+// have no item.
+func (q *Query) batchGetAligned(keys []any, opts *core.BatchGetOptions) ([]map[string]types.AttributeValue, error) {
+	executor, err := q.batchGetExecutor()
+	if err != nil {
+		return nil, err
+	}
+
+	effectiveOpts, keySpecs, chunks, err := q.buildBatchGetPlan(keys, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return executeBatchGetChunks(executor, chunks, keySpecs, effectiveOpts)
+}
+
+func (q *Query) validateBatchGetDestination(keys []any, dest any) error {
+	if err := q.checkBuilderError(); err != nil {
+		return err
+	}
+	return q.validateBatchGetInputs(keys, dest)
 }
 
 func (q *Query) validateBatchGetInputs(keys []any, dest any) error {
@@ -105,6 +218,7 @@ func (q *Query) buildBatchGetPlan(keys []any, opts *core.BatchGetOptions) (*core
 
 func executeBatchGetChunks(executor BatchExecutor, chunks []batchGetChunk, keySpecs []batchKeySpec, opts *core.BatchGetOptions) ([]map[string]types.AttributeValue, error) {
 	ordered := make([]map[string]types.AttributeValue, len(keySpecs))
+	skipped := make([]bool, len(keySpecs))
 	var orderMu sync.Mutex
 
 	progress := makeProgressReporter(opts.ProgressCallback, len(keySpecs))
@@ -112,7 +226,15 @@ func executeBatchGetChunks(executor BatchExecutor, chunks []batchGetChunk, keySp
 		items, execErr := executor.ExecuteBatchGet(chunk.request, opts)
 		if execErr != nil {
 			if opts.OnChunkError != nil {
-				return opts.OnChunkError(chunk.originals, execErr)
+				if err := opts.OnChunkError(chunk.originals, execErr); err != nil {
+					return err
+				}
+				orderMu.Lock()
+				for _, key := range chunk.keys {
+					skipped[key.index] = true
+				}
+				orderMu.Unlock()
+				return nil
 			}
 			return execErr
 		}
@@ -134,7 +256,19 @@ func executeBatchGetChunks(executor BatchExecutor, chunks []batchGetChunk, keySp
 		}
 	}
 
-	return flattenBatchGetResults(ordered), nil
+	if opts.OnMissing != nil {
+		var missing []any
+		for i, item := range ordered {
+			if item == nil && !skipped[i] {
+				missing = append(missing, keySpecs[i].original)
+			}
+		}
+		if len(missing) > 0 {
+			opts.OnMissing(missing)
+		}
+	}
+
+	return ordered, nil
 }
 
 func flattenBatchGetResults(ordered []map[string]types.AttributeValue) []map[string]types.AttributeValue {
@@ -321,12 +455,24 @@ func isStructLike(value any) bool {
 	return rv.Kind() == reflect.Struct
 }
 
+// buildBatchGetProjection projects the selected fields plus the primary key, which the
+// results are matched back to their keys by; without it every item would look missing.
 func (q *Query) buildBatchGetProjection() (string, map[string]string, error) {
 	if len(q.projection) == 0 {
 		return "", nil, nil
 	}
+	fields := append([]string(nil), q.projection...)
+	schema := q.metadata.PrimaryKey()
+	for _, key := range []string{schema.PartitionKey, schema.SortKey} {
+		if key == "" {
+			continue
+		}
+		if attr := q.resolveAttributeName(key); !slices.Contains(fields, attr) {
+			fields = append(fields, attr)
+		}
+	}
 	builder := expr.NewBuilder()
-	builder.AddProjection(q.projection...)
+	builder.AddProjection(fields...)
 	components := builder.Build()
 	return components.ProjectionExpression, components.ExpressionAttributeNames, nil
 }
@@ -543,6 +689,11 @@ func (b *batchGetBuilder) OnError(handler core.BatchChunkErrorHandler) core.Batc
 	return b
 }
 
+func (b *batchGetBuilder) OnMissing(handler core.BatchMissingKeysHandler) core.BatchGetBuilder {
+	b.opts.OnMissing = handler
+	return b
+}
+
 func (b *batchGetBuilder) Execute(dest any) error {
 	if len(b.projection) > 0 {
 		if next, ok := b.query.Select(b.projection...).(*Query); ok {
@@ -677,11 +828,18 @@ func (q *Query) unmarshalItemWithMetadataToStruct(item map[string]types.Attribut
 		if !structField.CanSet() {
 			continue
 		}
+		if fieldMeta.IsEnum() {
+			decoded, err := fieldMeta.DecodeEnum(attrValue)
+			if err != nil {
+				return err
+			}
+			attrValue = decoded
+		}
 
 		if err := q.converter.FromAttributeValue(attrValue, structField.Addr().Interface()); err != nil {
 			return fmt.Errorf("failed to unmarshal field %s: %w", fieldMeta.Name, err)
 		}
 	}
 
-	return nil
+	return q.rawMetadata.LoadKeyTemplates(destValue)
 }
@@ -0,0 +1,97 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/internal/encryption"
+	"github.com/pay-theory/dynamorm/internal/expr"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// WithBlindIndexKey sets the key the blind indexes of encrypted fields are computed
+// with, session.Config.BlindIndexKey.
+func (q *Query) WithBlindIndexKey(key []byte) *Query {
+	q.blindIndexKey = key
+	return q
+}
+
+// blindIndexCondition rewrites an equality or IN condition on an encrypted field that has
+// a blind index into the same condition on the index, comparing blind indexes of value.
+// ok is false for other fields and operators.
+func (q *Query) blindIndexCondition(field, op string, value any) (indexField string, indexValue any, ok bool, err error) {
+	source := q.conditionFieldMetadata(field)
+	if source == nil || !source.IsEncrypted {
+		return "", nil, false, nil
+	}
+	index := q.rawMetadata.BlindIndexFor(source)
+	if index == nil {
+		return "", nil, false, nil
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(op)) {
+	case "=", "EQ", "!=", "<>", "NE":
+		indexValue, err = q.blindIndexValue(source, value)
+	case "IN":
+		v := reflect.ValueOf(value)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return "", nil, false, nil
+		}
+		hashes := make([]any, v.Len())
+		for i := range hashes {
+			if hashes[i], err = q.blindIndexValue(source, v.Index(i).Interface()); err != nil {
+				break
+			}
+		}
+		indexValue = hashes
+	default:
+		return "", nil, false, nil
+	}
+	if err != nil {
+		return "", nil, false, err
+	}
+	return index.Name, indexValue, true, nil
+}
+
+// blindIndexValue returns the blind index of value, a plaintext value of source.
+func (q *Query) blindIndexValue(source *model.FieldMetadata, value any) (string, error) {
+	av, err := q.blindIndexAttributeValue(source, value)
+	if err != nil {
+		return "", err
+	}
+	return encryption.BlindIndex(q.blindIndexKey, source.DBName, av)
+}
+
+func (q *Query) blindIndexAttributeValue(source *model.FieldMetadata, value any) (types.AttributeValue, error) {
+	value = q.conditionValue(source.Name, value)
+	if q.converter != nil {
+		return q.converter.ToAttributeValue(value)
+	}
+	return expr.ConvertToAttributeValue(value)
+}
+
+// setBlindIndex adds the update of index, a blind index field, to builder: the blind
+// index of its source field in modelValue, or its removal when the source is nil.
+func (q *Query) setBlindIndex(builder *expr.Builder, index *model.FieldMetadata, modelValue reflect.Value) error {
+	source := q.rawMetadata.Fields[index.BlindIndexOf]
+	value := modelValue.FieldByIndex(source.IndexPath)
+	if isNilValue(value) {
+		return builder.AddUpdateRemove(index.DBName)
+	}
+	hash, err := q.blindIndexValue(source, value.Interface())
+	if err != nil {
+		return err
+	}
+	return builder.AddUpdateSet(index.DBName, hash)
+}
+
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	default:
+		return !v.IsValid()
+	}
+}
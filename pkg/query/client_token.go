@@ -0,0 +1,108 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"syscall"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	dynamormErrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+type clientTokenOption struct {
+	field *model.FieldMetadata
+	token string
+}
+
+// WithClientToken makes Create safe to retry after an ambiguous failure. The model's
+// string field is set to token before the write. If the PutItem then fails with a
+// network timeout or connection reset, or with a condition failure (as when an
+// IfNotExists create is retried after the first attempt actually landed), the item is
+// read back with a consistent read and Create reports success when the stored token
+// matches.
+func (q *Query) WithClientToken(field, token string) core.Query {
+	if token == "" {
+		q.recordBuilderError(fmt.Errorf("client token cannot be empty"))
+		return q
+	}
+	if q.rawMetadata == nil {
+		q.recordBuilderError(fmt.Errorf("client token requires model metadata"))
+		return q
+	}
+
+	meta := q.rawMetadata.Fields[field]
+	if meta == nil {
+		meta = q.rawMetadata.FieldsByDBName[field]
+	}
+	if meta == nil {
+		q.recordBuilderError(fmt.Errorf("%w: client token field %s not found", dynamormErrors.ErrInvalidModel, field))
+		return q
+	}
+	if meta.Type.Kind() != reflect.String {
+		q.recordBuilderError(fmt.Errorf("%w: client token field %s must be a string", dynamormErrors.ErrInvalidModel, field))
+		return q
+	}
+
+	q.clientToken = &clientTokenOption{field: meta, token: token}
+	return q
+}
+
+// applyClientToken writes the client token into the model before it is marshaled.
+func (q *Query) applyClientToken() error {
+	if q.clientToken == nil {
+		return nil
+	}
+	modelValue := reflect.ValueOf(q.model)
+	if modelValue.Kind() != reflect.Ptr || modelValue.IsNil() {
+		return fmt.Errorf("model must be a pointer to use a client token")
+	}
+	modelValue.Elem().FieldByIndex(q.clientToken.field.IndexPath).SetString(q.clientToken.token)
+	return nil
+}
+
+// confirmCreatedByToken reports whether a failed create actually stored this query's
+// item, judged by reading it back and comparing client tokens.
+func (q *Query) confirmCreatedByToken(writeErr error) bool {
+	if q.clientToken == nil {
+		return false
+	}
+	if !errors.Is(writeErr, dynamormErrors.ErrConditionFailed) && !isAmbiguousWriteError(writeErr) {
+		return false
+	}
+
+	getExecutor, ok := q.executor.(GetItemExecutor)
+	if !ok {
+		return false
+	}
+	key, err := q.buildPrimaryKeyMap("create")
+	if err != nil {
+		return false
+	}
+
+	consistent := true
+	compiled := &core.CompiledQuery{
+		Operation:      "GetItem",
+		TableName:      q.metadata.TableName(),
+		ConsistentRead: &consistent,
+	}
+
+	stored := reflect.New(reflect.ValueOf(q.model).Elem().Type())
+	if err := getExecutor.ExecuteGetItem(compiled, key, stored.Interface()); err != nil {
+		return false
+	}
+	return stored.Elem().FieldByIndex(q.clientToken.field.IndexPath).String() == q.clientToken.token
+}
+
+// isAmbiguousWriteError reports whether err leaves it unknown if DynamoDB applied the
+// write: the request may have been received even though no response arrived.
+func isAmbiguousWriteError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)
+}
@@ -0,0 +1,38 @@
+package query
+
+import (
+	"fmt"
+)
+
+// AtomicIncrement adds delta to a numeric field with a single ADD update expression and
+// returns the field's new value, read back with ReturnValues UPDATED_NEW. A missing field
+// starts at zero. The item is identified by the Where conditions or the model's key.
+func (q *Query) AtomicIncrement(field string, delta int64) (int64, error) {
+	return q.atomicAdd(field, delta)
+}
+
+// AtomicDecrement subtracts delta from a numeric field and returns the new value.
+func (q *Query) AtomicDecrement(field string, delta int64) (int64, error) {
+	return q.atomicAdd(field, -delta)
+}
+
+func (q *Query) atomicAdd(field string, delta int64) (int64, error) {
+	if field == "" {
+		return 0, fmt.Errorf("counter field is required")
+	}
+
+	var updated map[string]int64
+	err := q.UpdateBuilder().
+		Add(field, delta).
+		ReturnValues("UPDATED_NEW").
+		ExecuteWithResult(&updated)
+	if err != nil {
+		return 0, err
+	}
+
+	value, ok := updated[field]
+	if !ok {
+		return 0, fmt.Errorf("update of %s did not return its new value", field)
+	}
+	return value, nil
+}
@@ -0,0 +1,24 @@
+package query
+
+import (
+	"time"
+
+	"github.com/pay-theory/dynamorm/internal/deadline"
+)
+
+// DeadlineExecutor is implemented by executors that enforce a deadline stricter than
+// the query context's, such as a Lambda deadline adjusted for its cleanup buffer.
+// Multi-call operations use it to stop before a call that would overrun.
+type DeadlineExecutor interface {
+	EffectiveDeadline() (time.Time, bool)
+}
+
+func (q *Query) newDeadlineBudget() *deadline.Budget {
+	var executorDeadline time.Time
+	if provider, ok := q.executor.(DeadlineExecutor); ok {
+		if d, ok := provider.EffectiveDeadline(); ok {
+			executorDeadline = d
+		}
+	}
+	return deadline.New(q.ctx, deadline.DefaultReserve, executorDeadline)
+}
@@ -0,0 +1,121 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	dynamormErrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+type deadlineBatchWriteExecutor struct {
+	cov6BatchWriteExecutor
+	deadline time.Time
+	delay    time.Duration
+}
+
+func (e *deadlineBatchWriteExecutor) EffectiveDeadline() (time.Time, bool) {
+	return e.deadline, !e.deadline.IsZero()
+}
+
+func (e *deadlineBatchWriteExecutor) ExecuteBatchWriteItem(tableName string, writeRequests []types.WriteRequest) (*core.BatchWriteResult, error) {
+	time.Sleep(e.delay)
+	return e.cov6BatchWriteExecutor.ExecuteBatchWriteItem(tableName, writeRequests)
+}
+
+func TestQuery_BatchCreate_StopsWhenDeadlineBudgetIsSpent(t *testing.T) {
+	exec := &deadlineBatchWriteExecutor{
+		cov6BatchWriteExecutor: cov6BatchWriteExecutor{result: &core.BatchWriteResult{}},
+		deadline:               time.Now().Add(100 * time.Millisecond),
+		delay:                  60 * time.Millisecond,
+	}
+	q := New(&cov6BatchCreateItem{}, cov6Metadata{table: "tbl"}, exec)
+
+	err := q.BatchCreate(make([]cov6BatchCreateItem, 60))
+	require.Error(t, err)
+	require.True(t, errors.Is(err, dynamormErrors.ErrDeadlineBudgetExhausted))
+
+	var partial *dynamormErrors.PartialProgressError
+	require.True(t, errors.As(err, &partial))
+	require.Equal(t, "BatchCreate", partial.Operation)
+	require.Equal(t, 25, partial.Completed)
+	require.Equal(t, 60, partial.Total)
+	require.Equal(t, 1, exec.calls)
+}
+
+type deadlineScanExecutor struct {
+	deadline time.Time
+	scans    int
+}
+
+func (e *deadlineScanExecutor) EffectiveDeadline() (time.Time, bool) { return e.deadline, true }
+func (e *deadlineScanExecutor) ExecuteQuery(*core.CompiledQuery, any) error {
+	return nil
+}
+func (e *deadlineScanExecutor) ExecuteScan(*core.CompiledQuery, any) error {
+	e.scans++
+	return nil
+}
+
+func TestQuery_ScanAllSegments_RefusesToStartPastDeadline(t *testing.T) {
+	exec := &deadlineScanExecutor{deadline: time.Now().Add(-time.Second)}
+	q := New(&cov6BatchCreateItem{}, cov6Metadata{table: "tbl"}, exec)
+
+	var out []cov6BatchCreateItem
+	err := q.ScanAllSegments(&out, 4)
+
+	var partial *dynamormErrors.PartialProgressError
+	require.True(t, errors.As(err, &partial))
+	require.Equal(t, "ScanAllSegments", partial.Operation)
+	require.Equal(t, 0, partial.Completed)
+	require.Equal(t, 4, partial.Total)
+	require.Equal(t, 0, exec.scans)
+}
+
+type boundScanExecutor struct {
+	deadlineScanExecutor
+	ctx   context.Context
+	mu    *sync.Mutex
+	bound *[]time.Time
+}
+
+func (e *boundScanExecutor) WithExecutorContext(ctx context.Context) QueryExecutor {
+	bound := *e
+	bound.ctx = ctx
+	return &bound
+}
+
+func (e *boundScanExecutor) ExecuteScan(*core.CompiledQuery, any) error {
+	d, _ := e.ctx.Deadline()
+	e.mu.Lock()
+	*e.bound = append(*e.bound, d)
+	e.mu.Unlock()
+	return nil
+}
+
+func TestQuery_ScanAllSegments_BindsBudgetPerSegment(t *testing.T) {
+	deadline := time.Now().Add(time.Minute)
+	var deadlines []time.Time
+	exec := &boundScanExecutor{
+		deadlineScanExecutor: deadlineScanExecutor{deadline: deadline},
+		ctx:                  context.Background(),
+		mu:                   &sync.Mutex{},
+		bound:                &deadlines,
+	}
+	q := New(&cov6BatchCreateItem{}, cov6Metadata{table: "tbl"}, exec)
+
+	var out []cov6BatchCreateItem
+	require.NoError(t, q.ScanAllSegments(&out, 3))
+	require.Len(t, deadlines, 3)
+	for _, d := range deadlines {
+		require.Equal(t, deadline, d)
+	}
+	_, shared := exec.ctx.Deadline()
+	require.False(t, shared, "the shared executor keeps its own context")
+}
@@ -0,0 +1,81 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pay-theory/dynamorm/pkg/marshal"
+)
+
+// resolveDocumentPath maps a document path written with Go field names, such as
+// "Address.City" or "Items[0].SKU", to the attribute names the marshaler stores. Segments
+// that cannot be matched against the model, including map keys, are left unchanged.
+func (q *Query) resolveDocumentPath(path string) string {
+	segments := strings.Split(path, ".")
+	first, indexes := splitPathSegment(segments[0])
+	fieldMeta := q.conditionFieldMetadata(first)
+	if fieldMeta == nil {
+		return path
+	}
+	segments[0] = fieldMeta.DBName + indexes
+	typ := listElemType(fieldMeta.Type, indexes)
+
+	for i := 1; i < len(segments); i++ {
+		name, indexes := splitPathSegment(segments[i])
+		typ = derefType(typ)
+		switch {
+		case typ == nil:
+		case typ.Kind() == reflect.Map:
+			typ = typ.Elem()
+		case typ.Kind() == reflect.Struct:
+			field, ok := typ.FieldByName(name)
+			if !ok || !field.IsExported() {
+				typ = nil
+				break
+			}
+			segments[i] = q.nestedAttrName(field) + indexes
+			typ = field.Type
+		default:
+			typ = nil
+		}
+		typ = listElemType(typ, indexes)
+	}
+
+	return strings.Join(segments, ".")
+}
+
+// nestedAttrName returns the name the query's marshaler stores a nested struct field
+// under. Without a marshaler items are written by the converter, which, like the default
+// safe marshaler, uses the Go field name.
+func (q *Query) nestedAttrName(field reflect.StructField) string {
+	if namer, ok := q.marshaler.(marshal.NestedNamer); ok {
+		return namer.NestedAttributeName(field, q.rawMetadata.NamingConvention)
+	}
+	return field.Name
+}
+
+func splitPathSegment(segment string) (name string, indexes string) {
+	if open := strings.IndexByte(segment, '['); open > 0 {
+		return segment[:open], segment[open:]
+	}
+	return segment, ""
+}
+
+func derefType(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// listElemType steps into one slice or array element type per list index.
+func listElemType(typ reflect.Type, indexes string) reflect.Type {
+	for n := strings.Count(indexes, "["); n > 0; n-- {
+		typ = derefType(typ)
+		if typ == nil || (typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array) {
+			return nil
+		}
+		typ = typ.Elem()
+	}
+	return typ
+}
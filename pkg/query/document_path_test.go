@@ -0,0 +1,43 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/marshal"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+type documentPathAddress struct {
+	City string
+	Zip  string `json:"postalCode"`
+}
+
+type documentPathModel struct {
+	ID      string              `dynamorm:"pk"`
+	Address documentPathAddress `dynamorm:"attr:shipTo"`
+}
+
+func newDocumentPathQuery(t *testing.T) *Query {
+	t.Helper()
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&documentPathModel{}))
+	metadata, err := registry.GetMetadata(&documentPathModel{})
+	require.NoError(t, err)
+	return &Query{rawMetadata: metadata}
+}
+
+func TestResolveDocumentPath_FollowsMarshaler(t *testing.T) {
+	q := newDocumentPathQuery(t)
+	assert.Equal(t, "shipTo.Zip", q.resolveDocumentPath("Address.Zip"), "without a marshaler the Go field name is stored")
+
+	q.marshaler = marshal.NewSafeMarshaler()
+	assert.Equal(t, "shipTo.City", q.resolveDocumentPath("Address.City"))
+	assert.Equal(t, "shipTo.Zip", q.resolveDocumentPath("Address.Zip"))
+
+	q.marshaler = marshal.New(nil)
+	assert.Equal(t, "shipTo.city", q.resolveDocumentPath("Address.City"))
+	assert.Equal(t, "shipTo.postalCode", q.resolveDocumentPath("Address.Zip"))
+}
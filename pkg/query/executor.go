@@ -204,6 +204,13 @@ func NewExecutor(client DynamoDBAPI, ctx context.Context) *MainExecutor { //noli
 	}
 }
 
+// WithExecutorContext returns a copy of the executor bound to ctx, leaving e unchanged.
+func (e *MainExecutor) WithExecutorContext(ctx context.Context) QueryExecutor {
+	bound := *e
+	bound.SetContext(ctx)
+	return &bound
+}
+
 // SetContext updates the context used for subsequent DynamoDB calls.
 func (e *MainExecutor) SetContext(ctx context.Context) {
 	if ctx == nil {
@@ -907,6 +914,11 @@ func UnmarshalItem(item map[string]types.AttributeValue, dest any) error {
 					Err:       customerrors.ErrEncryptionNotConfigured,
 				}
 			}
+			if stored, ok := av.(*types.AttributeValueMemberS); ok {
+				if index, ok := enumTagValue(field, stored.Value); ok {
+					av = &types.AttributeValueMemberN{Value: strconv.Itoa(index)}
+				}
+			}
 			if err := unmarshalAttributeValue(av, fieldValue); err != nil {
 				return fmt.Errorf("failed to unmarshal field %s: %w", field.Name, err)
 			}
@@ -1241,6 +1253,33 @@ func fieldHasEncryptedTag(field reflect.StructField) bool {
 	return false
 }
 
+// enumTagValue returns the value of name on an integer field tagged with enum names,
+// such as `dynamorm:"enum:pending|paid|failed"`. Enums that list numbers have no names.
+func enumTagValue(field reflect.StructField, name string) (int, bool) {
+	if _, err := strconv.ParseFloat(name, 64); err == nil {
+		return 0, false
+	}
+	typ := field.Type
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() < reflect.Int || typ.Kind() > reflect.Uint64 {
+		return 0, false
+	}
+	for _, part := range strings.Split(field.Tag.Get("dynamorm"), ",") {
+		values, ok := strings.CutPrefix(strings.TrimSpace(part), "enum:")
+		if !ok {
+			continue
+		}
+		for i, value := range strings.Split(values, "|") {
+			if strings.TrimSpace(value) == name {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
 func looksLikeEncryptedEnvelope(av types.AttributeValue) bool {
 	env, ok := av.(*types.AttributeValueMemberM)
 	if !ok || env == nil || len(env.Value) == 0 {
@@ -0,0 +1,51 @@
+package query
+
+import (
+	"github.com/pay-theory/dynamorm/pkg/cond"
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+// FilterFunc adds the filter conditions built by fn, grouped and joined to existing
+// filters with AND. Conditions go through Filter, OrFilter, and the filter groups, so
+// field names, encrypted field checks, and time conversion behave the same way.
+func (q *Query) FilterFunc(fn func(b *cond.Builder)) core.Query {
+	if fn == nil {
+		return q
+	}
+	b := new(cond.Builder)
+	fn(b)
+	switch b.Len() {
+	case 0:
+		return q
+	case 1:
+		// A single condition or group needs no enclosing parentheses.
+		b.Apply(filterTarget{q: q})
+		return q
+	}
+	return q.addFilterGroup(cond.JoinAnd, func(sub core.Query) {
+		if subQuery, ok := sub.(*Query); ok {
+			b.Apply(filterTarget{q: subQuery})
+		}
+	})
+}
+
+// filterTarget applies cond conditions to a Query's filter builder.
+type filterTarget struct {
+	q *Query
+}
+
+func (t filterTarget) Condition(join, field, op string, value any) {
+	if join == cond.JoinOr {
+		t.q.OrFilter(field, op, value)
+		return
+	}
+	t.q.Filter(field, op, value)
+}
+
+func (t filterTarget) Group(join string, build func(cond.Target)) {
+	t.q.addFilterGroup(join, func(sub core.Query) {
+		if subQuery, ok := sub.(*Query); ok {
+			build(filterTarget{q: subQuery})
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/cond"
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+func TestQuery_FilterFunc_BuildsNestedGroups(t *testing.T) {
+	exec := &cov5QueryExecutor{}
+	q := New(&struct{}{}, cov5Metadata{
+		table:      "tbl",
+		primaryKey: core.KeySchema{PartitionKey: "pk"},
+	}, exec)
+
+	q.Filter("kind", "=", "order")
+	q.FilterFunc(func(b *cond.Builder) {
+		b.And(cond.Eq("status", "active"))
+		b.And(cond.Or(
+			cond.And(cond.Gte("total", 100), cond.BeginsWith("region", "us-")),
+			cond.Exists("priority"),
+		))
+		b.Or(cond.In("tier", "gold", "platinum"))
+	})
+
+	var out []struct{}
+	require.NoError(t, q.All(&out))
+	require.NotNil(t, exec.lastScan)
+	require.Equal(t,
+		"#n1 = :v1 AND (#STATUS = :v2 AND ((#TOTAL >= :v3 AND begins_with(#REGION, :v4)) OR attribute_exists(#n5)) OR #n6 IN (:v5, :v6))",
+		exec.lastScan.FilterExpression)
+	require.Equal(t, "priority", exec.lastScan.ExpressionAttributeNames["#n5"])
+	require.Len(t, exec.lastScan.ExpressionAttributeValues, 6)
+}
+
+func TestQuery_FilterFunc_SkipsEmptyBuilder(t *testing.T) {
+	exec := &cov5QueryExecutor{}
+	q := New(&struct{}{}, cov5Metadata{
+		table:      "tbl",
+		primaryKey: core.KeySchema{PartitionKey: "pk"},
+	}, exec)
+
+	q.FilterFunc(nil)
+	q.FilterFunc(func(b *cond.Builder) { b.And(cond.Or()) })
+
+	var out []struct{}
+	require.NoError(t, q.All(&out))
+	require.Empty(t, exec.lastScan.FilterExpression)
+}
+
+func TestQuery_FilterFunc_RecordsBuilderError(t *testing.T) {
+	exec := &cov5QueryExecutor{}
+	q := New(&struct{}{}, cov5Metadata{
+		table:      "tbl",
+		primaryKey: core.KeySchema{PartitionKey: "pk"},
+	}, exec)
+
+	q.FilterFunc(func(b *cond.Builder) {
+		b.And(cond.Or(cond.Eq("status", "a"), cond.And(cond.Eq("tier", 1), cond.Eq("", "x"))))
+	})
+
+	var out []struct{}
+	require.Error(t, q.All(&out))
+	require.Zero(t, exec.scanCalls)
+}
+
+func TestQuery_FilterGroup_ContinuesPlaceholders(t *testing.T) {
+	exec := &cov5QueryExecutor{}
+	q := New(&struct{}{}, cov5Metadata{
+		table:      "tbl",
+		primaryKey: core.KeySchema{PartitionKey: "pk"},
+	}, exec)
+
+	q.Filter("kind", "=", "order")
+	q.FilterGroup(func(sub core.Query) {
+		sub.Filter("tier", "=", "gold").OrFilter("kind", "=", "refund")
+	})
+
+	var out []struct{}
+	require.NoError(t, q.All(&out))
+	require.Equal(t, "#n1 = :v1 AND (#n2 = :v2 OR #n1 = :v3)", exec.lastScan.FilterExpression)
+	require.Len(t, exec.lastScan.ExpressionAttributeNames, 2)
+	require.Len(t, exec.lastScan.ExpressionAttributeValues, 3)
+}
@@ -0,0 +1,85 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+type hookEvent int
+
+const (
+	hookCreate hookEvent = iota
+	hookUpdate
+	hookDelete
+)
+
+// runBeforeHook invokes the model's Before hook for event, if it implements one.
+func runBeforeHook(ctx context.Context, model any, event hookEvent) error {
+	var (
+		name string
+		err  error
+	)
+	switch event {
+	case hookCreate:
+		if h, ok := model.(core.BeforeCreateHook); ok {
+			name, err = "BeforeCreate", h.BeforeCreate(ctx)
+		}
+	case hookUpdate:
+		if h, ok := model.(core.BeforeUpdateHook); ok {
+			name, err = "BeforeUpdate", h.BeforeUpdate(ctx)
+		}
+	case hookDelete:
+		if h, ok := model.(core.BeforeDeleteHook); ok {
+			name, err = "BeforeDelete", h.BeforeDelete(ctx)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+	return nil
+}
+
+// runAfterHook invokes the model's After hook for event, if it implements one.
+func runAfterHook(ctx context.Context, model any, event hookEvent) error {
+	var (
+		name string
+		err  error
+	)
+	switch event {
+	case hookCreate:
+		if h, ok := model.(core.AfterCreateHook); ok {
+			name, err = "AfterCreate", h.AfterCreate(ctx)
+		}
+	case hookUpdate:
+		if h, ok := model.(core.AfterUpdateHook); ok {
+			name, err = "AfterUpdate", h.AfterUpdate(ctx)
+		}
+	case hookDelete:
+		if h, ok := model.(core.AfterDeleteHook); ok {
+			name, err = "AfterDelete", h.AfterDelete(ctx)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s hook failed after a successful write: %w", name, err)
+	}
+	return nil
+}
+
+func (q *Query) hookContext() context.Context {
+	if q != nil && q.ctx != nil {
+		return q.ctx
+	}
+	return context.Background()
+}
+
+// hookTarget returns a slice element in the form hooks are usually declared on: a
+// pointer to the struct when the element is an addressable struct value.
+func hookTarget(v reflect.Value) any {
+	if v.Kind() == reflect.Struct && v.CanAddr() {
+		return v.Addr().Interface()
+	}
+	return v.Interface()
+}
@@ -0,0 +1,167 @@
+package query_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/query"
+)
+
+type hookedItem struct {
+	calls     *[]string
+	failOn    string
+	ID        string `dynamodb:"id"`
+	Status    string `dynamodb:"status"`
+	Timestamp int64  `dynamodb:"timestamp"`
+}
+
+func (h *hookedItem) record(name string) error {
+	if h.calls != nil {
+		*h.calls = append(*h.calls, name)
+	}
+	if h.failOn == name {
+		return errors.New("rejected")
+	}
+	return nil
+}
+
+func (h *hookedItem) BeforeCreate(context.Context) error {
+	h.Status = "derived"
+	return h.record("BeforeCreate")
+}
+func (h *hookedItem) AfterCreate(context.Context) error  { return h.record("AfterCreate") }
+func (h *hookedItem) BeforeUpdate(context.Context) error { return h.record("BeforeUpdate") }
+func (h *hookedItem) AfterUpdate(context.Context) error  { return h.record("AfterUpdate") }
+func (h *hookedItem) BeforeDelete(context.Context) error { return h.record("BeforeDelete") }
+func (h *hookedItem) AfterDelete(context.Context) error  { return h.record("AfterDelete") }
+
+func TestQuery_LifecycleHooks(t *testing.T) {
+	metadata := &mockMetadata{}
+
+	t.Run("create runs hooks around the write and persists before-hook changes", func(t *testing.T) {
+		var calls []string
+		exec := &recordingExecutor{}
+		item := &hookedItem{calls: &calls, ID: "a"}
+
+		require.NoError(t, query.New(item, metadata, exec).Create())
+		require.Equal(t, []string{"BeforeCreate", "AfterCreate"}, calls)
+		require.NotNil(t, exec.lastItem)
+		require.Equal(t, &types.AttributeValueMemberS{Value: "derived"}, exec.lastItem["Status"])
+	})
+
+	t.Run("before hook error aborts the write", func(t *testing.T) {
+		var calls []string
+		exec := &recordingExecutor{}
+		item := &hookedItem{calls: &calls, ID: "a", failOn: "BeforeCreate"}
+
+		err := query.New(item, metadata, exec).Create()
+		require.ErrorContains(t, err, "BeforeCreate hook failed: rejected")
+		require.Nil(t, exec.lastCompiled)
+	})
+
+	t.Run("after hook error is reported after the write", func(t *testing.T) {
+		var calls []string
+		exec := &recordingExecutor{}
+		item := &hookedItem{calls: &calls, ID: "a", failOn: "AfterDelete"}
+
+		err := query.New(item, metadata, exec).
+			Where("id", "=", "a").
+			Where("timestamp", "=", int64(1)).
+			Delete()
+		require.ErrorContains(t, err, "AfterDelete hook failed after a successful write")
+		require.NotNil(t, exec.lastCompiled)
+		require.Equal(t, []string{"BeforeDelete", "AfterDelete"}, calls)
+	})
+
+	t.Run("update builder runs update hooks", func(t *testing.T) {
+		var calls []string
+		exec := &recordingExecutor{}
+		item := &hookedItem{calls: &calls, ID: "a"}
+
+		err := query.New(item, metadata, exec).
+			Where("id", "=", "a").
+			Where("timestamp", "=", int64(1)).
+			UpdateBuilder().
+			Set("status", "done").
+			Execute()
+		require.NoError(t, err)
+		require.Equal(t, []string{"BeforeUpdate", "AfterUpdate"}, calls)
+	})
+
+	t.Run("atomic increment runs update hooks", func(t *testing.T) {
+		var calls []string
+		exec := &resultUpdateExecutor{}
+		item := &hookedItem{calls: &calls, ID: "a"}
+
+		value, err := query.New(item, metadata, exec).
+			Where("id", "=", "a").
+			Where("timestamp", "=", int64(1)).
+			AtomicIncrement("count", 1)
+		require.NoError(t, err)
+		require.Equal(t, int64(7), value)
+		require.Equal(t, []string{"BeforeUpdate", "AfterUpdate"}, calls)
+	})
+
+	t.Run("before update hook error aborts ExecuteWithResult", func(t *testing.T) {
+		var calls []string
+		exec := &resultUpdateExecutor{}
+		item := &hookedItem{calls: &calls, ID: "a", failOn: "BeforeUpdate"}
+
+		var out map[string]any
+		err := query.New(item, metadata, exec).
+			Where("id", "=", "a").
+			Where("timestamp", "=", int64(1)).
+			UpdateBuilder().
+			Set("status", "done").
+			ExecuteWithResult(&out)
+		require.ErrorContains(t, err, "BeforeUpdate hook failed: rejected")
+		require.Zero(t, exec.calls)
+	})
+
+	t.Run("ExecuteWithResult without a result executor runs update hooks", func(t *testing.T) {
+		var calls []string
+		exec := &recordingExecutor{}
+		item := &hookedItem{calls: &calls, ID: "a"}
+
+		var out map[string]any
+		err := query.New(item, metadata, exec).
+			Where("id", "=", "a").
+			Where("timestamp", "=", int64(1)).
+			UpdateBuilder().
+			Set("status", "done").
+			ExecuteWithResult(&out)
+		require.NoError(t, err)
+		require.NotNil(t, exec.lastCompiled)
+		require.Equal(t, []string{"BeforeUpdate", "AfterUpdate"}, calls)
+	})
+
+	t.Run("batch create runs hooks for each item", func(t *testing.T) {
+		var calls []string
+		exec := &capturingBatchExecutor{}
+		items := []hookedItem{{calls: &calls, ID: "a"}, {calls: &calls, ID: "b"}}
+
+		require.NoError(t, query.New(&hookedItem{}, metadata, exec).BatchCreate(items))
+		require.Equal(t, []string{"BeforeCreate", "BeforeCreate", "AfterCreate", "AfterCreate"}, calls)
+		require.Equal(t, "derived", items[1].Status)
+	})
+}
+
+// resultUpdateExecutor answers UpdateItem requests that ask for return values.
+type resultUpdateExecutor struct {
+	recordingExecutor
+	calls int
+}
+
+func (e *resultUpdateExecutor) ExecuteUpdateItemWithResult(input *core.CompiledQuery, key map[string]types.AttributeValue) (*core.UpdateResult, error) {
+	e.calls++
+	e.lastCompiled = input
+	e.lastKey = key
+	return &core.UpdateResult{
+		Attributes: map[string]types.AttributeValue{"count": &types.AttributeValueMemberN{Value: "7"}},
+	}, nil
+}
@@ -0,0 +1,39 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// MarshalItemJSON encodes an item in DynamoDB JSON, the format cursors use for keys,
+// such as {"id":{"S":"a1"},"total":{"N":"12"}}.
+func MarshalItemJSON(item map[string]types.AttributeValue) ([]byte, error) {
+	encoded := make(map[string]any, len(item))
+	for k, v := range item {
+		jsonValue, err := attributeValueToJSON(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert attribute %s: %w", k, err)
+		}
+		encoded[k] = jsonValue
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalItemJSON decodes an item written by MarshalItemJSON.
+func UnmarshalItemJSON(data []byte) (map[string]types.AttributeValue, error) {
+	var encoded map[string]any
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal item: %w", err)
+	}
+	item := make(map[string]types.AttributeValue, len(encoded))
+	for k, v := range encoded {
+		av, err := jsonToAttributeValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert attribute %s: %w", k, err)
+		}
+		item[k] = av
+	}
+	return item, nil
+}
@@ -0,0 +1,35 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemJSONRoundTrip(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"id":     &types.AttributeValueMemberS{Value: "a1"},
+		"total":  &types.AttributeValueMemberN{Value: "12.5"},
+		"blob":   &types.AttributeValueMemberB{Value: []byte{0, 1, 2}},
+		"active": &types.AttributeValueMemberBOOL{Value: true},
+		"note":   &types.AttributeValueMemberNULL{Value: true},
+		"tags":   &types.AttributeValueMemberSS{Value: []string{"x", "y"}},
+		"lines": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+				"sku": &types.AttributeValueMemberS{Value: "SKU-1"},
+			}},
+		}},
+	}
+
+	data, err := MarshalItemJSON(item)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"id":{"S":"a1"}`)
+
+	decoded, err := UnmarshalItemJSON(data)
+	require.NoError(t, err)
+	require.Equal(t, item, decoded)
+
+	_, err = UnmarshalItemJSON([]byte(`{"id":{"X":"a1"}}`))
+	require.ErrorContains(t, err, "failed to convert attribute id")
+}
@@ -0,0 +1,76 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// keyFieldValue returns the value of field as it is stored, or false when it is zero.
+// Fields tagged prefix: or composite: are returned with their prefix, composed from
+// their components when those are set.
+func keyFieldValue(metadata *model.Metadata, field *model.FieldMetadata, modelValue reflect.Value) (any, bool) {
+	if !field.HasKeyTemplate() {
+		value := modelValue.FieldByIndex(field.IndexPath)
+		if !value.IsValid() || value.IsZero() {
+			return nil, false
+		}
+		return value.Interface(), true
+	}
+	value, err := metadata.KeyValue(field, modelValue)
+	if err != nil || value == "" {
+		return nil, false
+	}
+	return field.StoredKey(value), true
+}
+
+// prefixKeyCondition adds the prefix of a prefix: field to string condition values, and
+// to each string of a slice for BETWEEN and IN. Values that already start with the prefix
+// are left as they are, so stored keys can be passed back.
+func prefixKeyCondition(field *model.FieldMetadata, value any) any {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, field.KeyPrefix) {
+			return v
+		}
+		return field.StoredKey(v)
+	case []string:
+		converted := make([]any, len(v))
+		for i, elem := range v {
+			converted[i] = prefixKeyCondition(field, elem)
+		}
+		return converted
+	case []any:
+		converted := make([]any, len(v))
+		for i, elem := range v {
+			converted[i] = prefixKeyCondition(field, elem)
+		}
+		return converted
+	default:
+		return value
+	}
+}
+
+// keyTemplateUpdates returns the values to set for the key template fields among
+// updated, and for the composite fields built from any of them, keyed by field.
+func keyTemplateUpdates(metadata *model.Metadata, modelValue reflect.Value, updated []*model.FieldMetadata) (map[*model.FieldMetadata]string, error) {
+	if metadata == nil || len(metadata.KeyTemplates) == 0 {
+		return nil, nil
+	}
+	values := make(map[*model.FieldMetadata]string)
+	fields := append(append([]*model.FieldMetadata(nil), updated...), metadata.KeyUpdates(updated)...)
+	for _, field := range fields {
+		if !field.HasKeyTemplate() {
+			continue
+		}
+		value, err := metadata.KeyValue(field, modelValue)
+		if err != nil {
+			return nil, err
+		}
+		if value != "" {
+			values[field] = field.StoredKey(value)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,110 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/internal/deadline"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	dynamormErrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// optimisticRetryBackoff is the base delay between attempts; it grows linearly.
+const optimisticRetryBackoff = 10 * time.Millisecond
+
+// UpdateWithOptimisticRetry applies mutate to the query's model and updates fields (all
+// non-key fields when none are given) guarded by the model's version field. When the
+// version check fails because another writer got there first, the item is reloaded with
+// a consistent read, mutate is applied again, and the update is retried, up to
+// maxAttempts attempts in total.
+//
+// mutate receives the model pointer passed to Model and may be called more than once.
+// Returning an error from mutate aborts without writing. On success the model holds the
+// written state, including the incremented version. When every attempt conflicts the
+// returned error wraps ErrConditionFailed.
+func (q *Query) UpdateWithOptimisticRetry(maxAttempts int, mutate func(model any) error, fields ...string) error {
+	if err := q.checkBuilderError(); err != nil {
+		return err
+	}
+	if mutate == nil {
+		return fmt.Errorf("mutate function cannot be nil")
+	}
+	if q.rawMetadata == nil || q.rawMetadata.VersionField == nil {
+		return fmt.Errorf("%w: optimistic retry requires a version field", dynamormErrors.ErrInvalidModel)
+	}
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	modelValue, err := q.updateModelValue()
+	if err != nil {
+		return err
+	}
+	if reflect.ValueOf(q.model).Kind() != reflect.Ptr {
+		return fmt.Errorf("model must be a pointer to reload it")
+	}
+
+	key, err := q.buildPrimaryKeyMap("update")
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := mutate(q.model); err != nil {
+			return err
+		}
+
+		err := q.Update(fields...)
+		if err == nil {
+			// Refresh already loaded the written item, version included.
+			if !q.refresh {
+				version := modelValue.FieldByIndex(q.rawMetadata.VersionField.IndexPath)
+				version.SetInt(version.Int() + 1)
+			}
+			return nil
+		}
+		if !errors.Is(err, dynamormErrors.ErrConditionFailed) {
+			return err
+		}
+		if attempt >= maxAttempts {
+			return fmt.Errorf("%w: optimistic update gave up after %d attempts", dynamormErrors.ErrConditionFailed, attempt)
+		}
+
+		if err := q.waitForRetry(time.Duration(attempt) * optimisticRetryBackoff); err != nil {
+			return err
+		}
+		if err := q.reloadModel(modelValue, key); err != nil {
+			return fmt.Errorf("failed to reload item after version conflict: %w", err)
+		}
+	}
+}
+
+// reloadModel replaces the model with the stored item using a consistent GetItem.
+func (q *Query) reloadModel(modelValue reflect.Value, key map[string]types.AttributeValue) error {
+	getExecutor, ok := q.executor.(GetItemExecutor)
+	if !ok {
+		return fmt.Errorf("executor does not support GetItem operation")
+	}
+
+	consistent := true
+	compiled := &core.CompiledQuery{
+		Operation:      "GetItem",
+		TableName:      q.metadata.TableName(),
+		ConsistentRead: &consistent,
+	}
+
+	fresh := reflect.New(modelValue.Type())
+	if err := getExecutor.ExecuteGetItem(compiled, key, fresh.Interface()); err != nil {
+		return err
+	}
+	modelValue.Set(fresh.Elem())
+	return nil
+}
+
+func (q *Query) waitForRetry(delay time.Duration) error {
+	return deadline.Sleep(q.hookContext(), delay)
+}
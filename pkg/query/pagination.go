@@ -2,9 +2,11 @@ package query
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
+	"github.com/pay-theory/dynamorm/internal/numutil"
 	"github.com/pay-theory/dynamorm/pkg/core"
 )
 
@@ -69,10 +71,92 @@ func (q *Query) AllPaginated(dest any) (*core.PaginatedResult, error) {
 	if lastKey, ok := queryResult["LastEvaluatedKey"].(map[string]types.AttributeValue); ok {
 		paginatedResult.LastEvaluatedKey = lastKey
 	}
+	paginatedResult.ConsumedCapacity, _ = queryResult["ConsumedCapacity"].([]types.ConsumedCapacity)
 
 	return paginatedResult, nil
 }
 
+// Pages executes the query page by page, following LastEvaluatedKey until the results
+// are exhausted, the Limit budget is spent, or fn returns false. dest must be a pointer
+// to a slice; it is overwritten with each page's items before fn is called. Each page's
+// NextCursor resumes immediately after that page, so a handler can stop at any page and
+// hand the cursor back to its caller.
+func (q *Query) Pages(dest any, fn func(page *core.PaginatedResult) bool) error {
+	if err := q.checkBuilderError(); err != nil {
+		return err
+	}
+	if fn == nil {
+		return fmt.Errorf("page callback cannot be nil")
+	}
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.IsNil() || destValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("destination must be a pointer to a slice")
+	}
+
+	compiled, err := q.Compile()
+	if err != nil {
+		return err
+	}
+
+	budget := q.limit
+	remaining := budget
+	for {
+		if budget > 0 {
+			pageLimit := numutil.ClampIntToInt32(remaining)
+			compiled.Limit = &pageLimit
+		}
+
+		var result any
+		if compiled.Operation == operationQuery {
+			result, err = q.executePaginatedQuery(compiled, dest)
+		} else {
+			result, err = q.executePaginatedScan(compiled, dest)
+		}
+		if err != nil {
+			return err
+		}
+
+		info, ok := result.(map[string]any)
+		if !ok {
+			return fmt.Errorf("unexpected pagination result type: %T", result)
+		}
+		lastKey, _ := info["LastEvaluatedKey"].(map[string]types.AttributeValue)
+
+		items := destValue.Elem().Len()
+		page := &core.PaginatedResult{
+			Items:            dest,
+			LastEvaluatedKey: lastKey,
+			NextCursor:       q.encodeCursor(lastKey),
+			Count:            items,
+			ScannedCount:     paginationCount(info["ScannedCount"]),
+		}
+		page.ConsumedCapacity, _ = info["ConsumedCapacity"].([]types.ConsumedCapacity)
+		page.HasMore = page.NextCursor != ""
+
+		if !fn(page) || len(lastKey) == 0 {
+			return nil
+		}
+		if budget > 0 {
+			remaining -= items
+			if remaining <= 0 {
+				return nil
+			}
+		}
+		compiled.ExclusiveStartKey = lastKey
+	}
+}
+
+func paginationCount(value any) int {
+	switch v := value.(type) {
+	case int64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
 // SetCursor sets the pagination cursor for the query
 func (q *Query) SetCursor(cursor string) error {
 	if cursor == "" {
@@ -97,11 +181,12 @@ func (q *Query) Cursor(cursor string) core.Query {
 	return q
 }
 
-func paginationInfoMap(count int64, scannedCount int64, lastEvaluatedKey map[string]types.AttributeValue) map[string]any {
+func paginationInfoMap(count int64, scannedCount int64, lastEvaluatedKey map[string]types.AttributeValue, capacity []types.ConsumedCapacity) map[string]any {
 	return map[string]any{
 		"Count":            count,
 		"ScannedCount":     scannedCount,
 		"LastEvaluatedKey": lastEvaluatedKey,
+		"ConsumedCapacity": capacity,
 	}
 }
 
@@ -116,16 +201,16 @@ func emptyPaginationInfoMap() map[string]any {
 func (q *Query) executeWithOptionalPagination(
 	compiled *core.CompiledQuery,
 	dest any,
-	execPaginated func(PaginatedQueryExecutor, *core.CompiledQuery, any) (int64, int64, map[string]types.AttributeValue, error),
+	execPaginated func(PaginatedQueryExecutor, *core.CompiledQuery, any) (*ScanResult, error),
 	exec func(*core.CompiledQuery, any) error,
 ) (any, error) {
 	// Check if executor supports pagination
 	if paginatedExecutor, ok := q.executor.(PaginatedQueryExecutor); ok {
-		count, scannedCount, lastEvaluatedKey, err := execPaginated(paginatedExecutor, compiled, dest)
+		result, err := execPaginated(paginatedExecutor, compiled, dest)
 		if err != nil {
 			return nil, err
 		}
-		return paginationInfoMap(count, scannedCount, lastEvaluatedKey), nil
+		return paginationInfoMap(result.Count, result.ScannedCount, result.LastEvaluatedKey, result.ConsumedCapacity), nil
 	}
 
 	// Fall back to regular execution without pagination info
@@ -142,12 +227,12 @@ func (q *Query) executePaginatedQuery(compiled *core.CompiledQuery, dest any) (a
 	return q.executeWithOptionalPagination(
 		compiled,
 		dest,
-		func(exec PaginatedQueryExecutor, compiled *core.CompiledQuery, dest any) (int64, int64, map[string]types.AttributeValue, error) {
+		func(exec PaginatedQueryExecutor, compiled *core.CompiledQuery, dest any) (*ScanResult, error) {
 			result, err := exec.ExecuteQueryWithPagination(compiled, dest)
 			if err != nil {
-				return 0, 0, nil, err
+				return nil, err
 			}
-			return result.Count, result.ScannedCount, result.LastEvaluatedKey, nil
+			return (*ScanResult)(result), nil
 		},
 		q.executor.ExecuteQuery,
 	)
@@ -158,12 +243,8 @@ func (q *Query) executePaginatedScan(compiled *core.CompiledQuery, dest any) (an
 	return q.executeWithOptionalPagination(
 		compiled,
 		dest,
-		func(exec PaginatedQueryExecutor, compiled *core.CompiledQuery, dest any) (int64, int64, map[string]types.AttributeValue, error) {
-			result, err := exec.ExecuteScanWithPagination(compiled, dest)
-			if err != nil {
-				return 0, 0, nil, err
-			}
-			return result.Count, result.ScannedCount, result.LastEvaluatedKey, nil
+		func(exec PaginatedQueryExecutor, compiled *core.CompiledQuery, dest any) (*ScanResult, error) {
+			return exec.ExecuteScanWithPagination(compiled, dest)
 		},
 		q.executor.ExecuteScan,
 	)
@@ -0,0 +1,195 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/internal/numutil"
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+// segmentScanResult is what one segment of ScanAllSegmentsWithOptions produced.
+type segmentScanResult struct {
+	err   error
+	items []any
+}
+
+// scanSegment scans one segment of a parallel scan into a new slice of sliceType.
+func (q *Query) scanSegment(ctx context.Context, executor QueryExecutor, sliceType reflect.Type, segment, totalSegments int32, opts *core.ScanOptions, progress *scanProgressReporter) segmentScanResult {
+	segmentQuery := &Query{
+		builderErr:     q.builderErr,
+		model:          q.model,
+		conditions:     q.conditions,
+		filters:        q.filters,
+		rawFilters:     q.rawFilters,
+		index:          q.index,
+		limit:          q.limit,
+		offset:         q.offset,
+		projection:     q.projection,
+		orderBy:        q.orderBy,
+		exclusive:      q.exclusive,
+		consistentRead: q.consistentRead,
+		ctx:            ctx,
+		metadata:       q.metadata,
+		rawMetadata:    q.rawMetadata,
+		converter:      q.converter,
+		marshaler:      q.marshaler,
+		executor:       executor,
+		builder:        q.builder,
+		segment:        &segment,
+		totalSegments:  &totalSegments,
+
+		returnConsumedCapacity: q.returnConsumedCapacity,
+	}
+
+	segmentDest := reflect.New(sliceType)
+	var err error
+	if opts.OnProgress != nil || opts.ItemsPerSecond > 0 || opts.CapacityPerSecond > 0 {
+		err = segmentQuery.scanSegmentPages(ctx, segmentDest, opts, progress)
+	} else {
+		err = segmentQuery.Scan(segmentDest.Interface())
+	}
+	if err != nil {
+		return segmentScanResult{err: err}
+	}
+
+	// Convert results to []any
+	segmentSlice := segmentDest.Elem()
+	items := make([]any, segmentSlice.Len())
+	for j := 0; j < segmentSlice.Len(); j++ {
+		items[j] = segmentSlice.Index(j).Interface()
+	}
+	return segmentScanResult{items: items}
+}
+
+// scanSegmentPages scans the query's segment a page at a time into dest, a pointer to a
+// slice, reporting each page and pacing the next to the options' rate limits.
+func (q *Query) scanSegmentPages(ctx context.Context, dest reflect.Value, opts *core.ScanOptions, progress *scanProgressReporter) error {
+	if err := q.checkBuilderError(); err != nil {
+		return err
+	}
+	compiled, err := q.compileScan()
+	if err != nil {
+		return err
+	}
+	if opts.CapacityPerSecond > 0 && (compiled.ReturnConsumedCapacity == "" || compiled.ReturnConsumedCapacity == string(types.ReturnConsumedCapacityNone)) {
+		compiled.ReturnConsumedCapacity = string(types.ReturnConsumedCapacityTotal)
+	}
+
+	pacer := scanPacer{start: time.Now(), itemsPerSecond: opts.ItemsPerSecond, unitsPerSecond: opts.CapacityPerSecond}
+	all := dest.Elem()
+	remaining := q.limit
+	for {
+		if q.limit > 0 {
+			pageLimit := numutil.ClampIntToInt32(remaining)
+			compiled.Limit = &pageLimit
+		}
+
+		page := reflect.New(all.Type())
+		result, err := q.executePaginatedScan(compiled, page.Interface())
+		if err != nil {
+			return err
+		}
+		info, ok := result.(map[string]any)
+		if !ok {
+			return fmt.Errorf("unexpected pagination result type: %T", result)
+		}
+		lastKey, _ := info["LastEvaluatedKey"].(map[string]types.AttributeValue)
+		capacity, _ := info["ConsumedCapacity"].([]types.ConsumedCapacity)
+
+		items := page.Elem().Len()
+		all = reflect.AppendSlice(all, page.Elem())
+		remaining -= items
+		done := len(lastKey) == 0 || (q.limit > 0 && remaining <= 0)
+		units := consumedCapacityUnits(capacity)
+		progress.report(core.ScanProgress{
+			Segment:       *q.segment,
+			TotalSegments: *q.totalSegments,
+			Items:         items,
+			ScannedCount:  paginationCount(info["ScannedCount"]),
+			CapacityUnits: units,
+			SegmentDone:   done,
+		})
+		if done {
+			break
+		}
+
+		if err := pacer.wait(ctx, items, units); err != nil {
+			return err
+		}
+		compiled.ExclusiveStartKey = lastKey
+	}
+
+	dest.Elem().Set(all)
+	return nil
+}
+
+// consumedCapacityUnits sums the capacity units DynamoDB reported for a page.
+func consumedCapacityUnits(capacity []types.ConsumedCapacity) float64 {
+	var units float64
+	for _, c := range capacity {
+		units += aws.ToFloat64(c.CapacityUnits)
+	}
+	return units
+}
+
+// scanPacer holds a segment to its rate limits by delaying each page until the items
+// and capacity read so far are within the limits since the segment started.
+type scanPacer struct {
+	start          time.Time
+	itemsPerSecond float64
+	unitsPerSecond float64
+	items          float64
+	units          float64
+}
+
+func (p *scanPacer) wait(ctx context.Context, items int, units float64) error {
+	p.items += float64(items)
+	p.units += units
+
+	var due time.Duration
+	if p.itemsPerSecond > 0 {
+		due = max(due, time.Duration(p.items/p.itemsPerSecond*float64(time.Second)))
+	}
+	if p.unitsPerSecond > 0 {
+		due = max(due, time.Duration(p.units/p.unitsPerSecond*float64(time.Second)))
+	}
+	delay := due - time.Since(p.start)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// scanProgressReporter serializes progress callbacks from concurrent segments and keeps
+// the running item total.
+type scanProgressReporter struct {
+	fn    core.ScanProgressCallback
+	mu    sync.Mutex
+	total int
+}
+
+func (r *scanProgressReporter) report(progress core.ScanProgress) {
+	if r == nil || r.fn == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.total += progress.Items
+	progress.TotalItems = r.total
+	r.fn(progress)
+}
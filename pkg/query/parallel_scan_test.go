@@ -0,0 +1,130 @@
+package query
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+// pagedScanExecutor serves each segment as two pages, the first of two items and the
+// second of one, and tracks how many scans run at once.
+type pagedScanExecutor struct {
+	delay    time.Duration
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	inputs   []core.CompiledQuery
+}
+
+func (e *pagedScanExecutor) ExecuteQuery(*core.CompiledQuery, any) error { return nil }
+
+// ExecuteScan reads both pages of the segment, as the DB's executor does.
+func (e *pagedScanExecutor) ExecuteScan(input *core.CompiledQuery, dest any) error {
+	first := *input
+	if _, err := e.ExecuteScanWithPagination(&first, dest); err != nil {
+		return err
+	}
+	second := *input
+	second.ExclusiveStartKey = map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "next"}}
+	_, err := e.ExecuteScanWithPagination(&second, dest)
+	return err
+}
+
+func (e *pagedScanExecutor) ExecuteQueryWithPagination(*core.CompiledQuery, any) (*QueryResult, error) {
+	return &QueryResult{}, nil
+}
+
+func (e *pagedScanExecutor) ExecuteScanWithPagination(input *core.CompiledQuery, dest any) (*ScanResult, error) {
+	e.mu.Lock()
+	e.inFlight++
+	e.peak = max(e.peak, e.inFlight)
+	e.inputs = append(e.inputs, *input)
+	e.mu.Unlock()
+
+	time.Sleep(e.delay)
+
+	e.mu.Lock()
+	e.inFlight--
+	e.mu.Unlock()
+
+	if input.ExclusiveStartKey == nil {
+		appendZeroElementToSlice(dest)
+		appendZeroElementToSlice(dest)
+		return &ScanResult{
+			Count:            2,
+			ScannedCount:     4,
+			LastEvaluatedKey: map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "next"}},
+			ConsumedCapacity: []types.ConsumedCapacity{{CapacityUnits: aws.Float64(1.5)}},
+		}, nil
+	}
+	appendZeroElementToSlice(dest)
+	return &ScanResult{Count: 1, ScannedCount: 1}, nil
+}
+
+func TestQuery_ScanAllSegmentsWithOptions_BoundsWorkers(t *testing.T) {
+	exec := &pagedScanExecutor{delay: 10 * time.Millisecond}
+	q := New(&cov6BatchCreateItem{}, cov6Metadata{table: "tbl"}, exec)
+
+	var out []cov6BatchCreateItem
+	require.NoError(t, q.ScanAllSegmentsWithOptions(&out, 6, &core.ScanOptions{MaxConcurrency: 2}))
+	require.Len(t, out, 6*3)
+	require.Equal(t, 2, exec.peak)
+	require.Len(t, exec.inputs, 6*2)
+}
+
+func TestQuery_ScanAllSegmentsWithOptions_ReportsProgressPerPage(t *testing.T) {
+	exec := &pagedScanExecutor{}
+	q := New(&cov6BatchCreateItem{}, cov6Metadata{table: "tbl"}, exec)
+
+	var reports []core.ScanProgress
+	var out []cov6BatchCreateItem
+	err := q.ScanAllSegmentsWithOptions(&out, 2, &core.ScanOptions{
+		MaxConcurrency: 1,
+		OnProgress:     func(p core.ScanProgress) { reports = append(reports, p) },
+	})
+	require.NoError(t, err)
+	require.Len(t, out, 6)
+
+	require.Equal(t, []core.ScanProgress{
+		{Segment: 0, TotalSegments: 2, Items: 2, ScannedCount: 4, CapacityUnits: 1.5, TotalItems: 2},
+		{Segment: 0, TotalSegments: 2, Items: 1, ScannedCount: 1, TotalItems: 3, SegmentDone: true},
+		{Segment: 1, TotalSegments: 2, Items: 2, ScannedCount: 4, CapacityUnits: 1.5, TotalItems: 5},
+		{Segment: 1, TotalSegments: 2, Items: 1, ScannedCount: 1, TotalItems: 6, SegmentDone: true},
+	}, reports)
+}
+
+func TestQuery_ScanAllSegmentsWithOptions_PacesSegments(t *testing.T) {
+	exec := &pagedScanExecutor{}
+	q := New(&cov6BatchCreateItem{}, cov6Metadata{table: "tbl"}, exec)
+
+	// Each segment's first page returns 2 items and 1.5 units; at 20 units per second the
+	// second page waits 75ms, longer than the 50ms the item limit asks for.
+	start := time.Now()
+	var out []cov6BatchCreateItem
+	err := q.ScanAllSegmentsWithOptions(&out, 2, &core.ScanOptions{ItemsPerSecond: 40, CapacityPerSecond: 20})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), 70*time.Millisecond)
+	require.Len(t, out, 6)
+
+	segments := make([]int32, 0, len(exec.inputs))
+	for _, input := range exec.inputs {
+		require.Equal(t, "TOTAL", input.ReturnConsumedCapacity)
+		segments = append(segments, *input.Segment)
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i] < segments[j] })
+	require.Equal(t, []int32{0, 0, 1, 1}, segments)
+}
+
+func TestQuery_ScanAllSegmentsWithOptions_RejectsNegativeRates(t *testing.T) {
+	q := New(&cov6BatchCreateItem{}, cov6Metadata{table: "tbl"}, &pagedScanExecutor{})
+
+	var out []cov6BatchCreateItem
+	require.ErrorContains(t, q.ScanAllSegmentsWithOptions(&out, 2, &core.ScanOptions{ItemsPerSecond: -1}), "cannot be negative")
+}
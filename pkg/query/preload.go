@@ -0,0 +1,40 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	dynamormErrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// PreloadExecutor loads relationship fields into results that have already been read.
+type PreloadExecutor interface {
+	ExecutePreload(dest any, relations []string) error
+}
+
+// Preload populates the named relationships on the results of First and All. Relationships
+// are declared on the model with `dynamorm:"hasmany:Name,fk:Field"` or
+// `dynamorm:"belongsto:Name,fk:Field"` and may be named by tag name or Go field name.
+func (q *Query) Preload(relations ...string) core.Query {
+	for _, name := range relations {
+		if q.rawMetadata != nil {
+			if _, ok := q.rawMetadata.Relation(name); !ok {
+				q.recordBuilderError(fmt.Errorf("%w: relationship %s not found on %s", dynamormErrors.ErrInvalidModel, name, q.rawMetadata.Type.Name()))
+				return q
+			}
+		}
+		q.preloads = append(q.preloads, name)
+	}
+	return q
+}
+
+func (q *Query) loadPreloads(dest any) error {
+	if len(q.preloads) == 0 {
+		return nil
+	}
+	preloader, ok := q.executor.(PreloadExecutor)
+	if !ok {
+		return fmt.Errorf("executor does not support Preload")
+	}
+	return preloader.ExecutePreload(dest, q.preloads)
+}
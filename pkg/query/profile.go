@@ -0,0 +1,36 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// Profile projects reads onto the fields of a serialization profile, so a model stored in
+// full can be read without the attributes a profile excludes. Fields opt out of profiles
+// with `dynamorm:"exclude:public|partner"`; model.StorageProfile selects every field.
+// Writes are unaffected.
+func (q *Query) Profile(name string) core.Query {
+	if q.rawMetadata == nil {
+		q.recordBuilderError(fmt.Errorf("profile %q requires model metadata", name))
+		return q
+	}
+
+	fields, err := q.rawMetadata.ProfileFields(name)
+	if err != nil {
+		q.recordBuilderError(err)
+		return q
+	}
+	if name == model.StorageProfile {
+		q.projection = nil
+		return q
+	}
+
+	projection := make([]string, len(fields))
+	for i, field := range fields {
+		projection[i] = field.DBName
+	}
+	q.projection = projection
+	return q
+}
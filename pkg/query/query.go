@@ -29,6 +29,7 @@ type Query struct {
 	converter               AttributeValueConverter
 	marshaler               marshal.MarshalerInterface
 	ctx                     context.Context
+	blindIndexKey           []byte
 	model                   any
 	exclusive               map[string]types.AttributeValue
 	retryConfig             *RetryConfig
@@ -36,16 +37,22 @@ type Query struct {
 	segment                 *int32
 	builder                 *expr.Builder
 	offset                  *int
+	clientToken             *clientTokenOption
 	orderBy                 OrderBy
 	index                   string
 	projection              []string
+	preloads                []string
 	rawFilters              []RawFilter
 	filters                 []Filter
 	rawConditionExpressions []conditionExpression
 	writeConditions         []Condition
 	conditions              []Condition
+	sortKeyFields           []string
 	limit                   int
+	returnConsumedCapacity  string
 	consistentRead          bool
+	refresh                 bool
+	noIndexSelection        bool
 }
 
 // Condition represents a query condition
@@ -78,6 +85,13 @@ type executorContextSetter interface {
 	SetContext(ctx context.Context)
 }
 
+// ContextBoundExecutor is implemented by executors that can return a copy bound to a
+// context. Concurrent calls use it to carry their own deadline without changing the
+// context of the shared executor.
+type ContextBoundExecutor interface {
+	WithExecutorContext(ctx context.Context) QueryExecutor
+}
+
 // normalizeCondition resolves a condition's field to its canonical DynamoDB attribute name
 // and returns the normalized condition along with the Go field name and DynamoDB attribute name.
 func (q *Query) normalizeCondition(cond Condition) (Condition, string, string) {
@@ -105,7 +119,9 @@ func (q *Query) rejectEncryptedConditionField(field string) error {
 		return nil
 	}
 
-	meta := q.metadata.AttributeMetadata(field)
+	// A document path such as "Secret.tier" is rejected when its root attribute is encrypted.
+	root, _ := splitPathSegment(strings.SplitN(field, ".", 2)[0])
+	meta := q.metadata.AttributeMetadata(root)
 	if meta == nil || len(meta.Tags) == 0 {
 		return nil
 	}
@@ -116,10 +132,23 @@ func (q *Query) rejectEncryptedConditionField(field string) error {
 
 	name := meta.Name
 	if name == "" {
-		name = field
+		name = root
 	}
 
-	return fmt.Errorf("%w: %s", dynamormErrors.ErrEncryptedFieldNotQueryable, name)
+	return dynamormErrors.EncryptedFieldQueryError(name)
+}
+
+// encryptedConditionField moves a condition on an encrypted field to its blind index
+// where possible, and otherwise rejects conditions on encrypted fields.
+func (q *Query) encryptedConditionField(field, op string, value any) (string, any, error) {
+	indexField, indexValue, ok, err := q.blindIndexCondition(field, op, value)
+	switch {
+	case err != nil:
+		return field, value, err
+	case ok:
+		return indexField, indexValue, nil
+	}
+	return field, value, q.rejectEncryptedConditionField(field)
 }
 
 // addPrimaryKeyCondition appends a condition targeting the table primary key
@@ -165,6 +194,9 @@ func (q *Query) resolveAttributeName(field string) string {
 			return meta.Name
 		}
 	}
+	if strings.ContainsAny(field, ".[") {
+		return q.resolveDocumentPath(field)
+	}
 	return field
 }
 
@@ -429,21 +461,23 @@ func New(model any, metadata core.ModelMetadata, executor QueryExecutor) *Query
 
 // Where adds a condition to the query
 func (q *Query) Where(field string, op string, value any) core.Query {
-	if err := q.rejectEncryptedConditionField(field); err != nil {
+	field, value, err := q.encryptedConditionField(field, op, value)
+	if err != nil {
 		q.recordBuilderError(err)
 		return q
 	}
 	q.conditions = append(q.conditions, Condition{
 		Field:    field,
 		Operator: op,
-		Value:    value,
+		Value:    q.conditionValue(field, value),
 	})
 	return q
 }
 
 // Filter adds a filter expression to the query
 func (q *Query) Filter(field string, op string, value any) core.Query {
-	if err := q.rejectEncryptedConditionField(field); err != nil {
+	field, value, err := q.encryptedConditionField(field, op, value)
+	if err != nil {
 		q.recordBuilderError(err)
 		return q
 	}
@@ -452,7 +486,7 @@ func (q *Query) Filter(field string, op string, value any) core.Query {
 		q.builder = q.newBuilder()
 	}
 
-	if err := q.builder.AddFilterCondition("AND", q.resolveAttributeName(field), op, value); err != nil {
+	if err := q.builder.AddFilterCondition("AND", q.resolveAttributeName(field), op, q.conditionValue(field, value)); err != nil {
 		q.recordBuilderError(err)
 	}
 	return q
@@ -506,6 +540,27 @@ func (q *Query) ConsistentRead() core.Query {
 	return q
 }
 
+// ReturnConsumedCapacity asks DynamoDB to report the capacity consumed by the query's
+// reads and writes: "TOTAL", "INDEXES", or "NONE". Paginated reads return it in
+// PaginatedResult.ConsumedCapacity, and middleware sees it in Operation.ConsumedCapacity.
+func (q *Query) ReturnConsumedCapacity(mode string) core.Query {
+	switch types.ReturnConsumedCapacity(mode) {
+	case types.ReturnConsumedCapacityTotal, types.ReturnConsumedCapacityIndexes, types.ReturnConsumedCapacityNone:
+		q.returnConsumedCapacity = mode
+	default:
+		q.recordBuilderError(fmt.Errorf("invalid ReturnConsumedCapacity mode %q", mode))
+	}
+	return q
+}
+
+// Refresh makes Create, CreateOrUpdate, and Update write the stored item back into the
+// model. Update asks DynamoDB for ALL_NEW return values; puts, which cannot return the new
+// item, are followed by a consistent GetItem.
+func (q *Query) Refresh() core.Query {
+	q.refresh = true
+	return q
+}
+
 // WithRetry configures retry behavior for eventually consistent reads
 func (q *Query) WithRetry(maxRetries int, initialDelay time.Duration) core.Query {
 	q.retryConfig = &RetryConfig{
@@ -520,10 +575,16 @@ func (q *Query) First(dest any) error {
 	if err := q.checkBuilderError(); err != nil {
 		return err
 	}
+	var err error
 	if q.retryConfig != nil {
-		return q.firstWithRetry(dest)
+		err = q.firstWithRetry(dest)
+	} else {
+		err = q.firstInternal(dest)
+	}
+	if err != nil {
+		return err
 	}
-	return q.firstInternal(dest)
+	return q.loadPreloads(dest)
 }
 
 // All executes the query and returns all results
@@ -531,37 +592,55 @@ func (q *Query) All(dest any) error {
 	if err := q.checkBuilderError(); err != nil {
 		return err
 	}
+	var err error
 	if q.retryConfig != nil {
-		return q.allWithRetry(dest)
+		err = q.allWithRetry(dest)
+	} else {
+		err = q.allInternal(dest)
+	}
+	if err != nil {
+		return err
 	}
-	return q.allInternal(dest)
+	return q.loadPreloads(dest)
 }
 
 // Count returns the count of matching items
 func (q *Query) Count() (int64, error) {
-	if err := q.checkBuilderError(); err != nil {
+	result, err := q.CountWithResult()
+	if err != nil {
 		return 0, err
 	}
-	compiled, err := q.Compile()
+	return result.Count, nil
+}
+
+// CountWithResult returns the count of matching items together with the number of items
+// DynamoDB evaluated, so filter efficiency can be monitored on count-only reads. The read
+// is sent with Select=COUNT, keeping the index, key condition, and filter; any projection
+// is dropped since DynamoDB rejects one alongside COUNT.
+func (q *Query) CountWithResult() (*core.CountResult, error) {
+	if err := q.checkBuilderError(); err != nil {
+		return nil, err
+	}
+	clone := *q
+	clone.projection = nil
+	compiled, err := clone.Compile()
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	// Set select to COUNT for efficiency
 	compiled.Select = "COUNT"
 
-	var result struct {
-		Count        int64
-		ScannedCount int64
-	}
-
+	var result core.CountResult
 	if compiled.Operation == operationQuery {
 		err = q.executor.ExecuteQuery(compiled, &result)
 	} else {
 		err = q.executor.ExecuteScan(compiled, &result)
 	}
-
-	return result.Count, err
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
 
 func (q *Query) firstInternal(dest any) error {
@@ -702,6 +781,15 @@ func (q *Query) Create() error {
 	if err := q.checkBuilderError(); err != nil {
 		return err
 	}
+	if err := runBeforeHook(q.hookContext(), q.model, hookCreate); err != nil {
+		return err
+	}
+	if err := q.validateModel(q.model, nil); err != nil {
+		return err
+	}
+	if err := q.applyClientToken(); err != nil {
+		return err
+	}
 	// Marshal the model to AttributeValues
 	item, err := q.marshalItem(q.model)
 	if err != nil {
@@ -710,8 +798,9 @@ func (q *Query) Create() error {
 
 	// Build PutItem request
 	compiled := &core.CompiledQuery{
-		Operation: "PutItem",
-		TableName: q.metadata.TableName(),
+		Operation:              "PutItem",
+		TableName:              q.metadata.TableName(),
+		ReturnConsumedCapacity: q.returnConsumedCapacity,
 	}
 
 	conditionExpr, names, values, err := q.buildConditionExpression(nil, false, false, false)
@@ -730,14 +819,17 @@ func (q *Query) Create() error {
 
 	// Execute through a specialized PutItem executor
 	if putExecutor, ok := q.executor.(PutItemExecutor); ok {
-		if err := putExecutor.ExecutePutItem(compiled, item); err != nil {
+		if err := putExecutor.ExecutePutItem(compiled, item); err != nil && !q.confirmCreatedByToken(err) {
 			if errors.Is(err, dynamormErrors.ErrConditionFailed) {
 				return fmt.Errorf("%w: item with the same key already exists", dynamormErrors.ErrConditionFailed)
 			}
 			return err
 		}
 		q.updateTimestampsInModel()
-		return nil
+		if err := q.refreshModel(); err != nil {
+			return err
+		}
+		return runAfterHook(q.hookContext(), q.model, hookCreate)
 	}
 
 	// Fallback: return error if executor doesn't support PutItem
@@ -749,6 +841,12 @@ func (q *Query) CreateOrUpdate() error {
 	if err := q.checkBuilderError(); err != nil {
 		return err
 	}
+	if err := runBeforeHook(q.hookContext(), q.model, hookCreate); err != nil {
+		return err
+	}
+	if err := q.validateModel(q.model, nil); err != nil {
+		return err
+	}
 	item, err := q.marshalItem(q.model)
 	if err != nil {
 		return fmt.Errorf("failed to marshal item: %w", err)
@@ -756,8 +854,9 @@ func (q *Query) CreateOrUpdate() error {
 
 	// Compile the query for PutItem (without condition expression)
 	compiled := &core.CompiledQuery{
-		Operation: "PutItem",
-		TableName: q.metadata.TableName(),
+		Operation:              "PutItem",
+		TableName:              q.metadata.TableName(),
+		ReturnConsumedCapacity: q.returnConsumedCapacity,
 	}
 
 	// Execute through a specialized PutItem executor
@@ -766,7 +865,10 @@ func (q *Query) CreateOrUpdate() error {
 			return err
 		}
 		q.updateTimestampsInModel()
-		return nil
+		if err := q.refreshModel(); err != nil {
+			return err
+		}
+		return runAfterHook(q.hookContext(), q.model, hookCreate)
 	}
 
 	// Fallback: return error if executor doesn't support PutItem
@@ -814,6 +916,12 @@ func (q *Query) Update(fields ...string) error {
 	if err := q.checkBuilderError(); err != nil {
 		return err
 	}
+	if err := runBeforeHook(q.hookContext(), q.model, hookUpdate); err != nil {
+		return err
+	}
+	if err := q.validateModel(q.model, fields); err != nil {
+		return err
+	}
 
 	key, keyErr := q.buildPrimaryKeyMap("update")
 	if keyErr != nil {
@@ -844,19 +952,67 @@ func (q *Query) Update(fields ...string) error {
 	compiled := &core.CompiledQuery{
 		Operation:                 "UpdateItem",
 		TableName:                 q.metadata.TableName(),
+		ReturnConsumedCapacity:    q.returnConsumedCapacity,
 		UpdateExpression:          components.UpdateExpression,
 		ConditionExpression:       conditionExpr,
 		ExpressionAttributeNames:  names,
 		ExpressionAttributeValues: values,
 	}
 
+	if resultExecutor, ok := q.executor.(UpdateItemWithResultExecutor); ok && q.refresh {
+		compiled.ReturnValues = "ALL_NEW"
+		result, err := resultExecutor.ExecuteUpdateItemWithResult(compiled, key)
+		if err != nil {
+			return err
+		}
+		if result != nil && len(result.Attributes) > 0 {
+			if err := q.unmarshalItemWithMetadata(result.Attributes, q.model); err != nil {
+				return fmt.Errorf("failed to refresh model: %w", err)
+			}
+		}
+		return runAfterHook(q.hookContext(), q.model, hookUpdate)
+	}
+
 	if updateExecutor, ok := q.executor.(UpdateItemExecutor); ok {
-		return updateExecutor.ExecuteUpdateItem(compiled, key)
+		if err := updateExecutor.ExecuteUpdateItem(compiled, key); err != nil {
+			return err
+		}
+		if err := q.refreshModel(); err != nil {
+			return err
+		}
+		return runAfterHook(q.hookContext(), q.model, hookUpdate)
 	}
 
 	return fmt.Errorf("executor does not support UpdateItem operation")
 }
 
+// refreshModel reloads the model from the table with a consistent read when Refresh
+// was requested.
+func (q *Query) refreshModel() error {
+	if !q.refresh {
+		return nil
+	}
+	getExecutor, ok := q.executor.(GetItemExecutor)
+	if !ok {
+		return fmt.Errorf("executor does not support GetItem operation required by Refresh")
+	}
+
+	key, err := q.buildPrimaryKeyMap("refresh")
+	if err != nil {
+		return err
+	}
+	consistent := true
+	compiled := &core.CompiledQuery{
+		Operation:      "GetItem",
+		TableName:      q.metadata.TableName(),
+		ConsistentRead: &consistent,
+	}
+	if err := getExecutor.ExecuteGetItem(compiled, key, q.model); err != nil {
+		return fmt.Errorf("failed to refresh model: %w", err)
+	}
+	return nil
+}
+
 func (q *Query) updateModelValue() (reflect.Value, error) {
 	modelValue := reflect.ValueOf(q.model)
 	if modelValue.Kind() == reflect.Ptr {
@@ -884,12 +1040,21 @@ func (q *Query) buildUpdateExpressionFromMetadata(builder *expr.Builder, modelVa
 		fieldsToUpdate = q.metadataFieldsToUpdate(modelValue)
 	}
 
+	updated := make([]*model.FieldMetadata, 0, len(fieldsToUpdate))
 	for _, fieldName := range fieldsToUpdate {
 		fieldMeta, err := q.updateFieldMetadata(fieldName)
 		if err != nil {
 			return err
 		}
+		updated = append(updated, fieldMeta)
+	}
+	keyValues, err := keyTemplateUpdates(q.rawMetadata, modelValue, updated)
+	if err != nil {
+		return err
+	}
 
+	for i, fieldMeta := range updated {
+		fieldName := fieldsToUpdate[i]
 		switch {
 		case fieldMeta.IsPK || fieldMeta.IsSK:
 			return fmt.Errorf("field '%s' is part of the primary key and cannot be updated", fieldName)
@@ -897,13 +1062,49 @@ func (q *Query) buildUpdateExpressionFromMetadata(builder *expr.Builder, modelVa
 			continue
 		case fieldMeta.IsUpdatedAt, fieldMeta.IsVersion:
 			continue // handled below
+		case fieldMeta.BlindIndexOf != "":
+			if err := q.setBlindIndex(builder, fieldMeta, modelValue); err != nil {
+				return fmt.Errorf("failed to build update for %s: %w", fieldName, err)
+			}
+			continue
 		}
 
+		if value, ok := keyValues[fieldMeta]; ok {
+			if err := builder.AddUpdateSet(fieldMeta.DBName, value); err != nil {
+				return fmt.Errorf("failed to build update for %s: %w", fieldName, err)
+			}
+			delete(keyValues, fieldMeta)
+			continue
+		}
 		fieldValue := modelValue.FieldByIndex(fieldMeta.IndexPath)
-		if err := builder.AddUpdateSet(fieldMeta.DBName, fieldValue.Interface()); err != nil {
+		if fieldMeta.AllowNull && fieldValue.IsNil() {
+			if err := builder.AddUpdateRemove(fieldMeta.DBName); err != nil {
+				return fmt.Errorf("failed to build update for %s: %w", fieldName, err)
+			}
+			continue
+		}
+		value := fieldValue.Interface()
+		if fieldMeta.IsEnum() {
+			if value, err = fieldMeta.EnumName(fieldValue); err != nil {
+				return err
+			}
+		}
+		if err := builder.AddUpdateSet(fieldMeta.DBName, value); err != nil {
 			return fmt.Errorf("failed to build update for %s: %w", fieldName, err)
 		}
 	}
+	for _, fieldMeta := range q.rawMetadata.KeyTemplates {
+		if value, ok := keyValues[fieldMeta]; ok {
+			if err := builder.AddUpdateSet(fieldMeta.DBName, value); err != nil {
+				return fmt.Errorf("failed to build update for %s: %w", fieldMeta.Name, err)
+			}
+		}
+	}
+	for _, fieldMeta := range q.rawMetadata.BlindIndexUpdates(updated) {
+		if err := q.setBlindIndex(builder, fieldMeta, modelValue); err != nil {
+			return fmt.Errorf("failed to build update for %s: %w", fieldMeta.Name, err)
+		}
+	}
 
 	return q.appendUpdatedAtAndVersionUpdates(builder, modelValue)
 }
@@ -915,7 +1116,7 @@ func (q *Query) metadataFieldsToUpdate(modelValue reflect.Value) []string {
 			continue
 		}
 		fieldValue := modelValue.FieldByIndex(fieldMeta.IndexPath)
-		if fieldMeta.OmitEmpty && reflectutil.IsEmpty(fieldValue) {
+		if fieldMeta.OmitEmpty && !fieldMeta.AllowNull && reflectutil.IsEmpty(fieldValue) {
 			continue
 		}
 		fieldsToUpdate = append(fieldsToUpdate, fieldName)
@@ -1015,13 +1216,13 @@ func extractKeyValues(primaryKey core.KeySchema, conditions []Condition) (map[st
 
 func validateKeyValues(primaryKey core.KeySchema, keyValues map[string]any, operation string) error {
 	if _, ok := keyValues[primaryKey.PartitionKey]; !ok {
-		return fmt.Errorf("partition key %s is required for %s", primaryKey.PartitionKey, operation)
+		return dynamormErrors.MissingKeyError(primaryKey.PartitionKey, operation, false)
 	}
 	if primaryKey.SortKey == "" {
 		return nil
 	}
 	if _, ok := keyValues[primaryKey.SortKey]; !ok {
-		return fmt.Errorf("sort key %s is required for %s", primaryKey.SortKey, operation)
+		return dynamormErrors.MissingKeyError(primaryKey.SortKey, operation, true)
 	}
 	return nil
 }
@@ -1044,6 +1245,9 @@ func (q *Query) Delete() error {
 	if err := q.checkBuilderError(); err != nil {
 		return err
 	}
+	if err := runBeforeHook(q.hookContext(), q.model, hookDelete); err != nil {
+		return err
+	}
 
 	key, keyErr := q.buildPrimaryKeyMap("delete")
 	if keyErr != nil {
@@ -1075,13 +1279,17 @@ func (q *Query) Delete() error {
 	compiled := &core.CompiledQuery{
 		Operation:                 "DeleteItem",
 		TableName:                 q.metadata.TableName(),
+		ReturnConsumedCapacity:    q.returnConsumedCapacity,
 		ConditionExpression:       conditionExpr,
 		ExpressionAttributeNames:  condNames,
 		ExpressionAttributeValues: condValues,
 	}
 
 	if deleteExecutor, ok := q.executor.(DeleteItemExecutor); ok {
-		return deleteExecutor.ExecuteDeleteItem(compiled, key)
+		if err := deleteExecutor.ExecuteDeleteItem(compiled, key); err != nil {
+			return err
+		}
+		return runAfterHook(q.hookContext(), q.model, hookDelete)
 	}
 
 	return fmt.Errorf("executor does not support DeleteItem operation")
@@ -1109,6 +1317,13 @@ func (q *Query) ParallelScan(segment int32, totalSegments int32) core.Query {
 
 // ScanAllSegments performs a parallel scan across all segments and combines results
 func (q *Query) ScanAllSegments(dest any, totalSegments int32) error {
+	return q.ScanAllSegmentsWithOptions(dest, totalSegments, nil)
+}
+
+// ScanAllSegmentsWithOptions performs a parallel scan across all segments and combines
+// results, scanning at most opts.MaxConcurrency segments at once and pacing each one to
+// the options' rate limits. nil options behave like ScanAllSegments.
+func (q *Query) ScanAllSegmentsWithOptions(dest any, totalSegments int32, opts *core.ScanOptions) error {
 	if err := q.checkBuilderError(); err != nil {
 		return err
 	}
@@ -1119,71 +1334,78 @@ func (q *Query) ScanAllSegments(dest any, totalSegments int32) error {
 	}
 	sliceType := destValue.Elem().Type()
 
-	// Create a channel to collect results from each segment
-	type segmentResult struct {
-		err   error
-		items []any
+	if opts == nil {
+		opts = core.DefaultScanOptions()
+	}
+	if opts.ItemsPerSecond < 0 || opts.CapacityPerSecond < 0 {
+		return fmt.Errorf("scan rate limits cannot be negative")
 	}
 
-	results := make(chan segmentResult, totalSegments)
+	// Segments run in parallel, so the whole scan shares one deadline budget.
+	budget := q.newDeadlineBudget()
+	if !budget.CanStart() {
+		return &dynamormErrors.PartialProgressError{
+			Operation: "ScanAllSegments",
+			Total:     int(totalSegments),
+			Err:       dynamormErrors.ErrDeadlineBudgetExhausted,
+		}
+	}
+	scanCtx := q.ctx
+	if scanCtx == nil {
+		scanCtx = context.Background()
+	}
+	segmentExecutor := q.executor
+	if budgetDeadline, ok := budget.Deadline(); ok {
+		var cancel context.CancelFunc
+		scanCtx, cancel = context.WithDeadline(scanCtx, budgetDeadline)
+		defer cancel()
+		if bound, ok := q.executor.(ContextBoundExecutor); ok {
+			segmentExecutor = bound.WithExecutorContext(scanCtx)
+		}
+	}
+	// Canceled on return so queued segments are not started after a failure.
+	scanCtx, cancelScan := context.WithCancel(scanCtx)
+	defer cancelScan()
 
-	// Launch goroutines for each segment
+	// Buffered for every segment, so workers never block on a caller that has returned.
+	results := make(chan segmentScanResult, totalSegments)
+	segments := make(chan int32, totalSegments)
 	for i := int32(0); i < totalSegments; i++ {
-		go func(segment int32) {
-			// Create a new query for this segment
-			segmentQuery := &Query{
-				builderErr:     q.builderErr,
-				model:          q.model,
-				conditions:     q.conditions,
-				filters:        q.filters,
-				rawFilters:     q.rawFilters,
-				index:          q.index,
-				limit:          q.limit,
-				offset:         q.offset,
-				projection:     q.projection,
-				orderBy:        q.orderBy,
-				exclusive:      q.exclusive,
-				consistentRead: q.consistentRead,
-				ctx:            q.ctx,
-				metadata:       q.metadata,
-				rawMetadata:    q.rawMetadata,
-				converter:      q.converter,
-				marshaler:      q.marshaler,
-				executor:       q.executor,
-				builder:        q.builder,
-				segment:        &segment,
-				totalSegments:  &totalSegments,
-			}
-
-			// Create a slice to hold this segment's results
-			elemType := sliceType.Elem()
-			segmentDest := reflect.New(reflect.SliceOf(elemType))
-
-			// Execute scan for this segment
-			err := segmentQuery.Scan(segmentDest.Interface())
-			if err != nil {
-				results <- segmentResult{err: err}
-				return
-			}
+		segments <- i
+	}
+	close(segments)
 
-			// Convert results to []any
-			segmentSlice := segmentDest.Elem()
-			items := make([]any, segmentSlice.Len())
-			for j := 0; j < segmentSlice.Len(); j++ {
-				items[j] = segmentSlice.Index(j).Interface()
+	workers := int(totalSegments)
+	if opts.MaxConcurrency > 0 && opts.MaxConcurrency < workers {
+		workers = opts.MaxConcurrency
+	}
+	progress := &scanProgressReporter{fn: opts.OnProgress}
+	for w := 0; w < workers; w++ {
+		go func() {
+			for segment := range segments {
+				if err := scanCtx.Err(); err != nil {
+					results <- segmentScanResult{err: err}
+					continue
+				}
+				results <- q.scanSegment(scanCtx, segmentExecutor, sliceType, segment, totalSegments, opts, progress)
 			}
-
-			results <- segmentResult{items: items}
-		}(i)
+		}()
 	}
 
 	// Collect results from all segments
 	var allItems []any
+	var deadlineErr error
+	completed := 0
 	for i := int32(0); i < totalSegments; i++ {
 		result := <-results
 		if result.err != nil {
+			if errors.Is(result.err, context.DeadlineExceeded) {
+				deadlineErr = result.err
+				continue
+			}
 			return result.err
 		}
+		completed++
 		allItems = append(allItems, result.items...)
 	}
 
@@ -1196,6 +1418,16 @@ func (q *Query) ScanAllSegments(dest any, totalSegments int32) error {
 	}
 
 	destSlice.Set(newSlice)
+
+	if deadlineErr != nil {
+		// dest holds the items of the segments that finished in time.
+		return &dynamormErrors.PartialProgressError{
+			Operation: "ScanAllSegments",
+			Completed: completed,
+			Total:     int(totalSegments),
+			Err:       fmt.Errorf("%w: %w", dynamormErrors.ErrDeadlineBudgetExhausted, deadlineErr),
+		}
+	}
 	return nil
 }
 
@@ -1219,8 +1451,18 @@ func (q *Query) BatchCreate(items any) error {
 		tableName := q.metadata.TableName()
 		const batchSize = 25
 		totalItems := itemsValue.Len()
+		budget := q.newDeadlineBudget()
 
 		for i := 0; i < totalItems; i += batchSize {
+			if !budget.CanStart() {
+				return &dynamormErrors.PartialProgressError{
+					Operation: "BatchCreate",
+					Completed: i,
+					Total:     totalItems,
+					Err:       dynamormErrors.ErrDeadlineBudgetExhausted,
+				}
+			}
+
 			end := i + batchSize
 			if end > totalItems {
 				end = totalItems
@@ -1228,7 +1470,13 @@ func (q *Query) BatchCreate(items any) error {
 
 			writeRequests := make([]types.WriteRequest, 0, end-i)
 			for j := i; j < end; j++ {
-				item := itemsValue.Index(j).Interface()
+				item := hookTarget(itemsValue.Index(j))
+				if err := runBeforeHook(q.hookContext(), item, hookCreate); err != nil {
+					return fmt.Errorf("item %d: %w", j, err)
+				}
+				if err := q.validateModel(item, nil); err != nil {
+					return fmt.Errorf("item %d: %w", j, err)
+				}
 				av, err := q.marshalItem(item)
 				if err != nil {
 					return fmt.Errorf("failed to marshal item %d: %w", j, err)
@@ -1241,9 +1489,18 @@ func (q *Query) BatchCreate(items any) error {
 				})
 			}
 
-			if err := q.executeBatchWriteWithRetries(tableName, writeRequests, nil); err != nil {
+			done := budget.Start()
+			err := q.executeBatchWriteWithRetries(tableName, writeRequests, nil)
+			done()
+			if err != nil {
 				return err
 			}
+
+			for j := i; j < end; j++ {
+				if err := runAfterHook(q.hookContext(), hookTarget(itemsValue.Index(j)), hookCreate); err != nil {
+					return fmt.Errorf("item %d: %w", j, err)
+				}
+			}
 		}
 
 		return nil
@@ -1259,7 +1516,13 @@ func (q *Query) BatchCreate(items any) error {
 
 		// Convert items to AttributeValues
 		for i := 0; i < itemsValue.Len(); i++ {
-			item := itemsValue.Index(i).Interface()
+			item := hookTarget(itemsValue.Index(i))
+			if err := runBeforeHook(q.hookContext(), item, hookCreate); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+			if err := q.validateModel(item, nil); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
 
 			// Convert item to map[string]types.AttributeValue
 			av, err := q.marshalItem(item)
@@ -1270,7 +1533,15 @@ func (q *Query) BatchCreate(items any) error {
 			batchWrite.Items = append(batchWrite.Items, av)
 		}
 
-		return executor.ExecuteBatchWrite(batchWrite)
+		if err := executor.ExecuteBatchWrite(batchWrite); err != nil {
+			return err
+		}
+		for i := 0; i < itemsValue.Len(); i++ {
+			if err := runAfterHook(q.hookContext(), hookTarget(itemsValue.Index(i)), hookCreate); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		return nil
 	}
 
 	return errors.New("executor does not support batch operations")
@@ -1292,6 +1563,14 @@ func (q *Query) WithMarshaler(marshaler marshal.MarshalerInterface) *Query {
 	return q
 }
 
+// WithIndexSelection turns choosing an index from the Where conditions on or off for
+// queries without Index(). It is on by default. When off, such queries use the table's
+// own key, or scan.
+func (q *Query) WithIndexSelection(enabled bool) *Query {
+	q.noIndexSelection = !enabled
+	return q
+}
+
 func (q *Query) setExecutorContext(ctx context.Context) {
 	if ctx == nil {
 		return
@@ -1311,7 +1590,9 @@ func (q *Query) WithContext(ctx context.Context) core.Query {
 	return q
 }
 
-// selectBestIndex analyzes conditions and selects the optimal index
+// selectBestIndex picks the table or index whose keys the conditions can use, through
+// index.Selector, the same as for reads of explicit indexes. With index selection
+// turned off, only the table's own key is considered.
 func (q *Query) selectBestIndex() (*core.IndexSchema, error) {
 	// Get all indexes including the primary index
 	rawIndexes := make([]core.IndexSchema, 0, len(q.metadata.Indexes())+1)
@@ -1326,7 +1607,9 @@ func (q *Query) selectBestIndex() (*core.IndexSchema, error) {
 	})
 
 	// Add GSIs and LSIs
-	rawIndexes = append(rawIndexes, q.metadata.Indexes()...)
+	if !q.noIndexSelection {
+		rawIndexes = append(rawIndexes, q.metadata.Indexes()...)
+	}
 
 	// Keep Go field names; Compile() resolves to DynamoDB names when needed
 	selector := index.NewSelector(rawIndexes)
@@ -1335,8 +1618,9 @@ func (q *Query) selectBestIndex() (*core.IndexSchema, error) {
 	indexConditions := make([]index.Condition, len(q.conditions))
 	for i, cond := range q.conditions {
 		normalized, goField, attrName := q.normalizeCondition(cond)
+		condGoName, _ := q.resolveConditionNames(goField, attrName)
 
-		fieldForIndex := goField
+		fieldForIndex := condGoName
 		if fieldForIndex == "" {
 			fieldForIndex = attrName
 		}
@@ -1351,11 +1635,7 @@ func (q *Query) selectBestIndex() (*core.IndexSchema, error) {
 		}
 	}
 
-	// Analyze conditions to find required keys
-	requiredKeys := index.AnalyzeConditions(indexConditions)
-
-	// Use the selector to find the best index
-	return selector.SelectOptimal(requiredKeys, nil)
+	return selector.SelectForConditions(indexConditions)
 }
 
 // Compile compiles the query into executable form
@@ -1363,12 +1643,16 @@ func (q *Query) Compile() (*core.CompiledQuery, error) {
 	builder := q.effectiveBuilder()
 
 	compiled := &core.CompiledQuery{
-		TableName: q.metadata.TableName(),
+		TableName:              q.metadata.TableName(),
+		ReturnConsumedCapacity: q.returnConsumedCapacity,
 	}
 
 	if err := q.compileOperation(builder, compiled); err != nil {
 		return nil, err
 	}
+	if err := q.applyEntityFilter(builder); err != nil {
+		return nil, err
+	}
 
 	q.applyProjections(builder)
 	q.applyExpressionComponents(compiled, builder)
@@ -1377,7 +1661,19 @@ func (q *Query) Compile() (*core.CompiledQuery, error) {
 	return compiled, nil
 }
 
+// applyEntityFilter limits reads of a model declared with `dynamorm:"entity:..."` to
+// the items of its entity type, so models sharing a table do not read each other's items.
+func (q *Query) applyEntityFilter(builder *expr.Builder) error {
+	if q.rawMetadata == nil || q.rawMetadata.EntityType == "" {
+		return nil
+	}
+	return builder.AddRequiredFilterCondition(q.rawMetadata.EntityAttribute, "=", q.rawMetadata.EntityType)
+}
+
 func (q *Query) compileOperation(builder *expr.Builder, compiled *core.CompiledQuery) error {
+	if err := q.validateSortKeyFields(); err != nil {
+		return err
+	}
 	if q.index != "" {
 		return q.compileWithExplicitIndex(builder, compiled, q.index)
 	}
@@ -1387,8 +1683,10 @@ func (q *Query) compileOperation(builder *expr.Builder, compiled *core.CompiledQ
 func (q *Query) compileWithExplicitIndex(builder *expr.Builder, compiled *core.CompiledQuery, name string) error {
 	compiled.IndexName = name
 
-	keys := q.keyNamesForIndex(q.indexSchemaByName(name))
+	index := q.indexSchemaByName(name)
+	keys := q.keyNamesForIndex(index)
 	keyConditions, filterConditions := q.partitionConditionsForKeys(keys)
+	keyConditions = append(keyConditions, q.inferIndexKeyConditions(index, keys)...)
 	if q.hasPartitionKeyCondition(keyConditions, keys.pkAttr) {
 		compiled.Operation = operationQuery
 		return q.applyKeyAndFilterConditions(builder, keyConditions, filterConditions)
@@ -1398,6 +1696,70 @@ func (q *Query) compileWithExplicitIndex(builder *expr.Builder, compiled *core.C
 	return q.applyScanConditions(builder)
 }
 
+// inferIndexKeyConditions returns equality key conditions for index taken from the
+// model's non-zero key fields, as the primary key is for Get, Update, and Delete, so
+// Model(&User{Email: email}).Index("email-index").First(&user) needs no Where. Keys
+// the query already has a condition on are left alone, and the sort key is inferred
+// only along with the partition key.
+func (q *Query) inferIndexKeyConditions(index *core.IndexSchema, keys keyNameSet) []Condition {
+	if index == nil || index.Name == "" || q.referencesField(keys.pkGo, keys.pkAttr) {
+		return nil
+	}
+	modelValue, ok := q.modelStructValue()
+	if !ok {
+		return nil
+	}
+
+	pkValue, ok := q.modelKeyValue(modelValue, keys.pkGo, keys.pkAttr)
+	if !ok {
+		return nil
+	}
+	inferred := []Condition{{Field: keys.pkAttr, Operator: "=", Value: q.conditionValue(keys.pkGo, pkValue)}}
+
+	if index.SortKey == "" || q.referencesField(keys.skGo, keys.skAttr) {
+		return inferred
+	}
+	if skValue, ok := q.modelKeyValue(modelValue, keys.skGo, keys.skAttr); ok {
+		inferred = append(inferred, Condition{Field: keys.skAttr, Operator: "=", Value: q.conditionValue(keys.skGo, skValue)})
+	}
+	return inferred
+}
+
+// referencesField reports whether any Where condition names the field.
+func (q *Query) referencesField(goName, attrName string) bool {
+	target := keyNameSet{pkGo: goName, pkAttr: attrName}
+	for _, original := range q.conditions {
+		_, goField, condAttr := q.normalizeCondition(original)
+		if target.isPartitionKey(q.resolveConditionNames(goField, condAttr)) {
+			return true
+		}
+	}
+	return false
+}
+
+// modelKeyValue returns the model's value for a key field, or false when it is zero.
+func (q *Query) modelKeyValue(modelValue reflect.Value, goName, attrName string) (any, bool) {
+	var field reflect.Value
+	if q.rawMetadata != nil {
+		meta, ok := q.rawMetadata.Fields[goName]
+		if !ok {
+			meta, ok = q.rawMetadata.FieldsByDBName[attrName]
+		}
+		if ok {
+			return keyFieldValue(q.rawMetadata, meta, modelValue)
+		}
+	}
+	if !field.IsValid() && goName != "" {
+		field = modelValue.FieldByNameFunc(func(name string) bool {
+			return strings.EqualFold(name, goName)
+		})
+	}
+	if !field.IsValid() || field.IsZero() {
+		return nil, false
+	}
+	return field.Interface(), true
+}
+
 func (q *Query) compileWithBestIndex(builder *expr.Builder, compiled *core.CompiledQuery) error {
 	bestIndex, err := q.selectBestIndex()
 	if err != nil {
@@ -1409,7 +1771,8 @@ func (q *Query) compileWithBestIndex(builder *expr.Builder, compiled *core.Compi
 		if bestIndex.Name != "" {
 			compiled.IndexName = bestIndex.Name
 		}
-		return q.applyQueryConditions(builder, bestIndex)
+		keyConditions, filterConditions := q.partitionConditionsForKeys(q.keyNamesForIndex(bestIndex))
+		return q.applyKeyAndFilterConditions(builder, keyConditions, filterConditions)
 	}
 
 	compiled.Operation = operationScan
@@ -1544,19 +1907,11 @@ func (q *Query) fillKeyValuesFromRawMetadata(modelValue reflect.Value, skGo stri
 	}
 
 	if q.rawMetadata.PrimaryKey.PartitionKey != nil && !*pkFound {
-		field := modelValue.FieldByIndex(q.rawMetadata.PrimaryKey.PartitionKey.IndexPath)
-		if field.IsValid() && !field.IsZero() {
-			*pkValue = field.Interface()
-			*pkFound = true
-		}
+		*pkValue, *pkFound = keyFieldValue(q.rawMetadata, q.rawMetadata.PrimaryKey.PartitionKey, modelValue)
 	}
 
 	if skGo != "" && q.rawMetadata.PrimaryKey.SortKey != nil && !*skFound {
-		field := modelValue.FieldByIndex(q.rawMetadata.PrimaryKey.SortKey.IndexPath)
-		if field.IsValid() && !field.IsZero() {
-			*skValue = field.Interface()
-			*skFound = true
-		}
+		*skValue, *skFound = keyFieldValue(q.rawMetadata, q.rawMetadata.PrimaryKey.SortKey, modelValue)
 	}
 }
 
@@ -1654,10 +2009,10 @@ func (q *Query) extractPrimaryKeyValuesFromConditions(pkGo, pkAttr, skGo, skAttr
 
 func validatePrimaryKeyValues(operation, pkGo, skGo string, pkFound, skFound bool) error {
 	if !pkFound {
-		return fmt.Errorf("partition key %s is required for %s", pkGo, operation)
+		return dynamormErrors.MissingKeyError(pkGo, operation, false)
 	}
 	if skGo != "" && !skFound {
-		return fmt.Errorf("sort key %s is required for %s", skGo, operation)
+		return dynamormErrors.MissingKeyError(skGo, operation, true)
 	}
 	return nil
 }
@@ -1709,25 +2064,6 @@ func (k keyNameSet) isSortKey(goName, attrName string) bool {
 	return strings.EqualFold(goName, k.skGo) || strings.EqualFold(attrName, k.skAttr)
 }
 
-func (q *Query) applyQueryConditions(builder *expr.Builder, bestIndex *core.IndexSchema) error {
-	keys := q.keyNamesForIndex(bestIndex)
-	keyConditions, filterConditions := q.splitConditionsByKey(keys)
-
-	for _, cond := range keyConditions {
-		if err := builder.AddKeyCondition(cond.Field, cond.Operator, cond.Value); err != nil {
-			return err
-		}
-	}
-
-	for _, cond := range filterConditions {
-		if err := builder.AddFilterCondition("AND", cond.Field, cond.Operator, cond.Value); err != nil {
-			return err
-		}
-	}
-
-	return nil
-}
-
 func (q *Query) applyScanConditions(builder *expr.Builder) error {
 	for _, original := range q.conditions {
 		normalized, _, _ := q.normalizeCondition(original)
@@ -1761,10 +2097,10 @@ func (q *Query) keyNamesForIndex(bestIndex *core.IndexSchema) keyNameSet {
 	if pkAttrName == "" {
 		pkAttrName = primaryPKAttr
 	}
-	if skGoName == "" {
+	if bestIndex.SortKey != "" && skGoName == "" {
 		skGoName = primarySKGo
 	}
-	if skAttrName == "" {
+	if bestIndex.SortKey != "" && skAttrName == "" {
 		skAttrName = primarySKAttr
 	}
 
@@ -1780,24 +2116,6 @@ func (q *Query) resolveGoAndAttrName(field string) (string, string) {
 	return q.resolveGoFieldName(field), q.resolveAttributeName(field)
 }
 
-func (q *Query) splitConditionsByKey(keys keyNameSet) ([]Condition, []Condition) {
-	keyConditions := make([]Condition, 0)
-	filterConditions := make([]Condition, 0)
-
-	for _, original := range q.conditions {
-		normalized, goField, attrName := q.normalizeCondition(original)
-		condGoName, condAttrName := q.resolveConditionNames(goField, attrName)
-
-		if keys.isKey(condGoName, condAttrName) {
-			keyConditions = append(keyConditions, normalized)
-		} else {
-			filterConditions = append(filterConditions, normalized)
-		}
-	}
-
-	return keyConditions, filterConditions
-}
-
 func (q *Query) resolveConditionNames(goField, attrName string) (string, string) {
 	condGoName := goField
 	condAttrName := attrName
@@ -1864,8 +2182,9 @@ func (q *Query) compileScan() (*core.CompiledQuery, error) {
 	builder := q.effectiveBuilder()
 
 	compiled := &core.CompiledQuery{
-		TableName: q.metadata.TableName(),
-		Operation: operationScan,
+		TableName:              q.metadata.TableName(),
+		Operation:              operationScan,
+		ReturnConsumedCapacity: q.returnConsumedCapacity,
 	}
 	if q.index != "" {
 		compiled.IndexName = q.index
@@ -1880,6 +2199,9 @@ func (q *Query) compileScan() (*core.CompiledQuery, error) {
 	}
 
 	// Note: Additional filters from Filter/OrFilter calls are already in the builder
+	if err := q.applyEntityFilter(builder); err != nil {
+		return nil, err
+	}
 
 	// Add projections
 	if len(q.projection) > 0 {
@@ -1962,8 +2284,9 @@ func (q *Query) compileGetItem() (*core.CompiledQuery, map[string]types.Attribut
 	}
 
 	compiled := &core.CompiledQuery{
-		Operation: "GetItem",
-		TableName: q.metadata.TableName(),
+		Operation:              "GetItem",
+		TableName:              q.metadata.TableName(),
+		ReturnConsumedCapacity: q.returnConsumedCapacity,
 	}
 	if len(q.projection) > 0 {
 		builder := q.newBuilder()
@@ -2035,14 +2358,38 @@ func (q *Query) marshalItem(item any) (map[string]types.AttributeValue, error) {
 		return nil, fmt.Errorf("query cannot be nil")
 	}
 
-	if q.rawMetadata != nil {
-		if q.marshaler != nil {
-			return q.marshaler.MarshalItem(item, q.rawMetadata)
-		}
-		return q.marshalItemReflect(item)
+	if q.rawMetadata == nil {
+		return q.marshalItemTagged(item)
+	}
+
+	var (
+		av  map[string]types.AttributeValue
+		err error
+	)
+	if q.marshaler != nil {
+		av, err = q.marshaler.MarshalItem(item, q.rawMetadata)
+	} else {
+		av, err = q.marshalItemReflect(item)
+	}
+	if err != nil {
+		return nil, err
+	}
+	stampEntityType(q.rawMetadata, av)
+	if err := q.rawMetadata.ApplyKeyTemplates(reflect.Indirect(reflect.ValueOf(item)), av); err != nil {
+		return nil, err
+	}
+	if err := q.rawMetadata.ApplyEnums(av); err != nil {
+		return nil, err
 	}
+	return av, nil
+}
 
-	return q.marshalItemTagged(item)
+// stampEntityType sets the entity type attribute of a model declared with
+// `dynamorm:"entity:..."` on an item about to be written.
+func stampEntityType(metadata *model.Metadata, item map[string]types.AttributeValue) {
+	if metadata != nil && metadata.EntityType != "" && item != nil {
+		item[metadata.EntityAttribute] = &types.AttributeValueMemberS{Value: metadata.EntityType}
+	}
 }
 
 func (q *Query) marshalItemReflect(item any) (map[string]types.AttributeValue, error) {
@@ -2230,7 +2577,8 @@ func (q *Query) updateTimestampsInModel() {
 
 // OrFilter adds an OR filter condition
 func (q *Query) OrFilter(field string, op string, value any) core.Query {
-	if err := q.rejectEncryptedConditionField(field); err != nil {
+	field, value, err := q.encryptedConditionField(field, op, value)
+	if err != nil {
 		q.recordBuilderError(err)
 		return q
 	}
@@ -2239,7 +2587,7 @@ func (q *Query) OrFilter(field string, op string, value any) core.Query {
 		q.builder = q.newBuilder()
 	}
 
-	if err := q.builder.AddFilterCondition("OR", q.resolveAttributeName(field), op, value); err != nil {
+	if err := q.builder.AddFilterCondition("OR", q.resolveAttributeName(field), op, q.conditionValue(field, value)); err != nil {
 		q.recordBuilderError(err)
 	}
 	return q
@@ -2251,8 +2599,9 @@ func (q *Query) addFilterGroup(groupOperator string, fn func(core.Query)) core.Q
 		q.builder = q.newBuilder()
 	}
 
-	// Create a new sub-query and builder for the group
-	subBuilder := q.newBuilder()
+	// Create a new sub-query and builder for the group. The group's placeholders continue
+	// from the parent's so merging it back cannot overwrite an existing name or value.
+	subBuilder := q.builder.NewGroup()
 	subQuery := &Query{
 		model:    q.model,
 		metadata: q.metadata,
@@ -2260,9 +2609,10 @@ func (q *Query) addFilterGroup(groupOperator string, fn func(core.Query)) core.Q
 		ctx:      q.ctx,
 		builder:  subBuilder,
 		// Ensure grouped conditions behave identically to the parent query.
-		rawMetadata: q.rawMetadata,
-		converter:   q.converter,
-		marshaler:   q.marshaler,
+		rawMetadata:   q.rawMetadata,
+		converter:     q.converter,
+		marshaler:     q.marshaler,
+		blindIndexKey: q.blindIndexKey,
 	}
 
 	// Execute the user's function to build the sub-query
@@ -2271,11 +2621,8 @@ func (q *Query) addFilterGroup(groupOperator string, fn func(core.Query)) core.Q
 		q.recordBuilderError(err)
 	}
 
-	// Build the components from the sub-query
-	components := subBuilder.Build()
-
 	// Add the built group to the main builder
-	q.builder.AddGroupFilter(groupOperator, components)
+	q.builder.AddGroup(groupOperator, subBuilder)
 	return q
 }
 
@@ -2303,7 +2650,8 @@ func (q *Query) IfExists() core.Query {
 
 // WithCondition appends an additional write condition
 func (q *Query) WithCondition(field, operator string, value any) core.Query {
-	if err := q.rejectEncryptedConditionField(field); err != nil {
+	field, value, err := q.encryptedConditionField(field, operator, value)
+	if err != nil {
 		q.recordBuilderError(err)
 		return q
 	}
@@ -2311,7 +2659,7 @@ func (q *Query) WithCondition(field, operator string, value any) core.Query {
 	q.writeConditions = append(q.writeConditions, Condition{
 		Field:    attrName,
 		Operator: operator,
-		Value:    value,
+		Value:    q.conditionValue(field, value),
 	})
 	return q
 }
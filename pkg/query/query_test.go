@@ -247,6 +247,52 @@ func TestQuery_IndexSelection(t *testing.T) {
 	assert.Equal(t, "status-index", compiled.IndexName)
 }
 
+func TestQuery_ExplicitIndexInfersKeysFromModel(t *testing.T) {
+	metadata := &mockMetadata{}
+	executor := &mockExecutor{}
+
+	q := query.New(&TestItem{Status: "active", Timestamp: 1000}, metadata, executor)
+	q.Index("status-index")
+
+	compiled, err := q.Compile()
+	require.NoError(t, err)
+	require.Equal(t, "Query", compiled.Operation)
+	require.Equal(t, "status-index", compiled.IndexName)
+	require.Equal(t, "#STATUS = :v1 AND #TIMESTAMP = :v2", compiled.KeyConditionExpression)
+	require.Equal(t, "status", compiled.ExpressionAttributeNames["#STATUS"])
+	require.Equal(t, "timestamp", compiled.ExpressionAttributeNames["#TIMESTAMP"])
+	require.Equal(t, &types.AttributeValueMemberS{Value: "active"}, compiled.ExpressionAttributeValues[":v1"])
+	require.Empty(t, compiled.FilterExpression)
+}
+
+func TestQuery_ExplicitIndexInferenceDefersToConditions(t *testing.T) {
+	metadata := &mockMetadata{}
+	executor := &mockExecutor{}
+
+	q := query.New(&TestItem{Status: "active", Timestamp: 1000}, metadata, executor)
+	q.Index("status-index").Where("timestamp", ">", 500)
+
+	compiled, err := q.Compile()
+	require.NoError(t, err)
+	require.Equal(t, "Query", compiled.Operation)
+	require.Equal(t, "#TIMESTAMP > :v1 AND #STATUS = :v2", compiled.KeyConditionExpression)
+
+	q = query.New(&TestItem{Status: "active"}, metadata, executor)
+	q.Index("status-index").Where("status", "<>", "archived")
+
+	compiled, err = q.Compile()
+	require.NoError(t, err)
+	require.Equal(t, "Scan", compiled.Operation)
+
+	q = query.New(&TestItem{Timestamp: 1000}, metadata, executor)
+	q.Index("status-index")
+
+	compiled, err = q.Compile()
+	require.NoError(t, err)
+	require.Equal(t, "Scan", compiled.Operation)
+	require.Empty(t, compiled.KeyConditionExpression)
+}
+
 func TestQuery_ScanFallback(t *testing.T) {
 	metadata := &mockMetadata{}
 	executor := &mockExecutor{}
@@ -0,0 +1,166 @@
+package query
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	dynamormErrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// WhereBeginsWith adds a begins_with key condition on field, for sort keys such as
+// "ORDER#2026-". Unlike Where(field, "begins_with", prefix), the query fails to build
+// unless field is the sort key of the index it uses, or of the table, so the condition
+// cannot silently become a filter over a whole partition.
+func (q *Query) WhereBeginsWith(field string, prefix string) core.Query {
+	if prefix == "" {
+		q.recordBuilderError(fmt.Errorf("%w: WhereBeginsWith(%s) needs a non-empty prefix", dynamormErrors.ErrEmptyValue, field))
+		return q
+	}
+	q.sortKeyFields = append(q.sortKeyFields, field)
+	return q.Where(field, "BEGINS_WITH", prefix)
+}
+
+// WhereBetween adds a key condition matching sort key values from lo to hi inclusive.
+// field must be the sort key of the index the query uses, or of the table, and the
+// bounds must be strings, numbers, times, or byte slices of one type with lo <= hi;
+// DynamoDB otherwise rejects the request only when it runs.
+func (q *Query) WhereBetween(field string, lo, hi any) core.Query {
+	bounds, ok := q.conditionValue(field, []any{lo, hi}).([]any)
+	if !ok || len(bounds) != 2 {
+		bounds = []any{lo, hi}
+	}
+	if err := checkBetweenBounds(bounds[0], bounds[1]); err != nil {
+		q.recordBuilderError(fmt.Errorf("WhereBetween(%s): %w", field, err))
+		return q
+	}
+	q.sortKeyFields = append(q.sortKeyFields, field)
+	if err := q.rejectEncryptedConditionField(field); err != nil {
+		q.recordBuilderError(err)
+		return q
+	}
+	q.conditions = append(q.conditions, Condition{Field: field, Operator: "BETWEEN", Value: bounds})
+	return q
+}
+
+// validateSortKeyFields checks that the fields of WhereBeginsWith and WhereBetween are
+// sort keys: of the query's index when it names one, and otherwise of the table or of an
+// index the query can be routed to.
+func (q *Query) validateSortKeyFields() error {
+	if len(q.sortKeyFields) == 0 {
+		return nil
+	}
+
+	var candidates []*core.IndexSchema
+	if q.index != "" {
+		schema := q.indexSchemaByName(q.index)
+		if schema == nil {
+			return fmt.Errorf("%w: %s is not defined on %s", dynamormErrors.ErrIndexNotFound, q.index, q.metadata.TableName())
+		}
+		candidates = append(candidates, schema)
+	} else {
+		primaryKey := q.metadata.PrimaryKey()
+		candidates = append(candidates, &core.IndexSchema{PartitionKey: primaryKey.PartitionKey, SortKey: primaryKey.SortKey})
+		for _, schema := range q.metadata.Indexes() {
+			schema := schema
+			candidates = append(candidates, &schema)
+		}
+	}
+
+	for _, field := range q.sortKeyFields {
+		goName, attrName := q.resolveGoAndAttrName(field)
+		var sortKeys []string
+		matched := false
+		for _, schema := range candidates {
+			if schema.SortKey == "" {
+				continue
+			}
+			skGo, skAttr := q.resolveGoAndAttrName(schema.SortKey)
+			if strings.EqualFold(goName, skGo) || strings.EqualFold(attrName, skAttr) {
+				matched = true
+				break
+			}
+			sortKeys = append(sortKeys, sortKeyDescription(schema.Name, skGo))
+		}
+		if matched {
+			continue
+		}
+
+		target := q.metadata.TableName()
+		if q.index != "" {
+			target += " index " + q.index
+		}
+		if len(sortKeys) == 0 {
+			return fmt.Errorf("%w: %s has no sort key, so %s cannot take a sort key condition", dynamormErrors.ErrInvalidOperator, target, field)
+		}
+		return fmt.Errorf("%w: %s is not a sort key of %s; sort keys are %s", dynamormErrors.ErrInvalidOperator, field, target, strings.Join(sortKeys, ", "))
+	}
+	return nil
+}
+
+func sortKeyDescription(index, field string) string {
+	if index == "" {
+		return field + " (table)"
+	}
+	return field + " (" + index + ")"
+}
+
+// checkBetweenBounds reports bounds DynamoDB would reject: of different types, of a type
+// that cannot be a key, or with lo above hi.
+func checkBetweenBounds(lo, hi any) error {
+	if lo == nil || hi == nil {
+		return fmt.Errorf("%w: BETWEEN needs both bounds", dynamormErrors.ErrEmptyValue)
+	}
+	cmp, ok := compareKeyValues(reflect.ValueOf(lo), reflect.ValueOf(hi))
+	if !ok {
+		return fmt.Errorf("%w: BETWEEN bounds %T and %T must be strings, numbers, or byte slices of the same kind", dynamormErrors.ErrInvalidOperator, lo, hi)
+	}
+	if cmp > 0 {
+		return fmt.Errorf("%w: BETWEEN lower bound %v is above upper bound %v", dynamormErrors.ErrInvalidOperator, lo, hi)
+	}
+	return nil
+}
+
+// compareKeyValues orders two key values the way DynamoDB does: strings and byte
+// slices bytewise, numbers numerically. It returns false for values of different kinds.
+func compareKeyValues(a, b reflect.Value) (int, bool) {
+	switch {
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return strings.Compare(a.String(), b.String()), true
+	case isByteSlice(a) && isByteSlice(b):
+		return bytes.Compare(a.Bytes(), b.Bytes()), true
+	}
+
+	x, okA := numericValue(a)
+	y, okB := numericValue(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	switch {
+	case x < y:
+		return -1, true
+	case x > y:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
+
+func isByteSlice(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8
+}
+
+func numericValue(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,143 @@
+package query
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+
+	// timeLayoutWarnings records the string attributes already reported so the hook runs
+	// once per table and attribute.
+	timeLayoutWarnings sync.Map
+	timeLayoutHook     atomic.Value
+)
+
+// OnTimeLayoutMismatch registers fn to be called the first time a time.Time condition
+// value is compared against each string attribute, whose stored layout dynamorm cannot
+// know. Use it to log or count the comparison; nothing is reported by default.
+func OnTimeLayoutMismatch(fn func(table, attribute string)) {
+	timeLayoutHook.Store(fn)
+}
+
+// timeFormatter is implemented by converters that apply a time policy, such as
+// *types.Converter.
+type timeFormatter interface {
+	FormatTime(t time.Time) string
+}
+
+// conditionValue converts time.Time condition values into the representation the
+// marshaler writes for field: Unix seconds for TTL and numeric fields, and RFC3339Nano
+// under the converter's time policy otherwise (UTC with types.TimeUTC). Monotonic clock
+// readings are dropped, and under types.TimeUTC times in other zones compare equal to
+// the stored value of the same instant. Slices (for BETWEEN
+// and IN) are converted element by element; other values are returned unchanged.
+// Values of fields tagged prefix: get the prefix, and those of enum fields their names.
+func (q *Query) conditionValue(field string, value any) any {
+	if fieldMeta := q.conditionFieldMetadata(field); fieldMeta != nil && fieldMeta.KeyPrefix != "" {
+		return prefixKeyCondition(fieldMeta, value)
+	} else if fieldMeta.IsEnum() {
+		return fieldMeta.EnumCondition(value)
+	}
+	switch v := value.(type) {
+	case time.Time:
+		return q.conditionTime(field, v)
+	case *time.Time:
+		if v == nil {
+			return value
+		}
+		return q.conditionTime(field, *v)
+	case []time.Time:
+		converted := make([]any, len(v))
+		for i, t := range v {
+			converted[i] = q.conditionTime(field, t)
+		}
+		return converted
+	case []any:
+		var converted []any
+		for i, elem := range v {
+			if _, isTime := elem.(time.Time); !isTime {
+				if ptr, isPtr := elem.(*time.Time); !isPtr || ptr == nil {
+					continue
+				}
+			}
+			if converted == nil {
+				converted = append([]any(nil), v...)
+			}
+			converted[i] = q.conditionValue(field, elem)
+		}
+		if converted == nil {
+			return value
+		}
+		return converted
+	default:
+		return value
+	}
+}
+
+func (q *Query) conditionTime(field string, t time.Time) any {
+	fieldMeta := q.conditionFieldMetadata(field)
+	if fieldMeta == nil {
+		return q.formatTime(t)
+	}
+
+	typ := fieldMeta.Type
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case fieldMeta.IsTTL:
+		return t.Unix()
+	case typ == nil || typ == timeType:
+		return q.formatTime(t)
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return t.Unix()
+	case reflect.String:
+		q.warnTimeLayout(fieldMeta)
+	}
+	return q.formatTime(t)
+}
+
+func (q *Query) formatTime(t time.Time) string {
+	if q != nil {
+		if formatter, ok := q.converter.(timeFormatter); ok {
+			return formatter.FormatTime(t)
+		}
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func (q *Query) conditionFieldMetadata(field string) *model.FieldMetadata {
+	if q == nil || q.rawMetadata == nil || field == "" {
+		return nil
+	}
+	if fieldMeta, ok := q.rawMetadata.Fields[field]; ok {
+		return fieldMeta
+	}
+	return q.rawMetadata.FieldsByDBName[field]
+}
+
+// warnTimeLayout reports, through the OnTimeLayoutMismatch hook, that a time.Time is
+// compared against a string attribute.
+func (q *Query) warnTimeLayout(fieldMeta *model.FieldMetadata) {
+	hook, _ := timeLayoutHook.Load().(func(table, attribute string))
+	if hook == nil {
+		return
+	}
+	key := q.rawMetadata.TableName + "." + fieldMeta.DBName
+	if _, seen := timeLayoutWarnings.LoadOrStore(key, struct{}{}); seen {
+		return
+	}
+	hook(q.rawMetadata.TableName, fieldMeta.DBName)
+}
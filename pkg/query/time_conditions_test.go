@@ -0,0 +1,97 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/model"
+	pkgTypes "github.com/pay-theory/dynamorm/pkg/types"
+)
+
+type timeConditionModel struct {
+	CreatedAt time.Time  `dynamorm:"attr:createdAt"`
+	ExpiresAt int64      `dynamorm:"ttl"`
+	DeletedAt *time.Time `dynamorm:"attr:deletedAt"`
+	ID        string     `dynamorm:"pk"`
+	Day       string     `dynamorm:"sk"`
+	Epoch     int64      `dynamorm:"attr:epoch"`
+}
+
+func newTimeConditionQuery(t *testing.T) *Query {
+	t.Helper()
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&timeConditionModel{}))
+	metadata, err := registry.GetMetadata(&timeConditionModel{})
+	require.NoError(t, err)
+	return &Query{rawMetadata: metadata}
+}
+
+func TestConditionValue_NormalizesTimes(t *testing.T) {
+	q := newTimeConditionQuery(t)
+	converter := pkgTypes.NewConverter()
+	converter.SetTimePolicy(pkgTypes.TimeUTC)
+	q.converter = converter
+	eastern := time.FixedZone("EST", -5*60*60)
+	local := time.Date(2024, 3, 1, 10, 30, 0, 0, eastern)
+
+	assert.Equal(t, "2024-03-01T15:30:00Z", q.conditionValue("CreatedAt", local))
+	assert.Equal(t, "2024-03-01T15:30:00Z", q.conditionValue("createdAt", &local))
+	assert.Equal(t, "2024-03-01T15:30:00Z", q.conditionValue("DeletedAt", local))
+	assert.Equal(t, local.Unix(), q.conditionValue("ExpiresAt", local))
+	assert.Equal(t, local.Unix(), q.conditionValue("epoch", local))
+
+	// time.Now carries a monotonic reading; the formatted value must not.
+	now := time.Now()
+	assert.Equal(t, now.UTC().Format(time.RFC3339Nano), q.conditionValue("CreatedAt", now))
+
+	between := q.conditionValue("CreatedAt", []any{local, local.Add(time.Hour)})
+	assert.Equal(t, []any{"2024-03-01T15:30:00Z", "2024-03-01T16:30:00Z"}, between)
+	assert.Equal(t, []any{local.Unix()}, q.conditionValue("ExpiresAt", []time.Time{local}))
+
+	assert.Equal(t, "unchanged", q.conditionValue("CreatedAt", "unchanged"))
+}
+
+func TestConditionValue_DefaultKeepsZone(t *testing.T) {
+	q := newTimeConditionQuery(t)
+	local := time.Date(2024, 3, 1, 10, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+
+	assert.Equal(t, "2024-03-01T10:30:00-05:00", q.conditionValue("CreatedAt", local))
+	assert.Equal(t, local.Unix(), q.conditionValue("ExpiresAt", local))
+	assert.Equal(t, "2024-03-01T10:30:00-05:00", (*Query)(nil).conditionValue("CreatedAt", local))
+
+	q.converter = pkgTypes.NewConverter()
+	assert.Equal(t, "2024-03-01T10:30:00-05:00", q.conditionValue("CreatedAt", local))
+}
+
+func TestConditionValue_ReportsStringAttributes(t *testing.T) {
+	q := newTimeConditionQuery(t)
+	q.rawMetadata.TableName = "time-condition-warnings"
+
+	var reported []string
+	OnTimeLayoutMismatch(func(table, attribute string) {
+		reported = append(reported, table+"."+attribute)
+	})
+	t.Cleanup(func() { OnTimeLayoutMismatch(nil) })
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "2024-03-01T00:00:00Z", q.conditionValue("Day", day))
+	q.conditionValue("Day", day)
+
+	assert.Equal(t, []string{"time-condition-warnings.day"}, reported)
+}
+
+func TestWhere_NormalizesTimeValues(t *testing.T) {
+	q := newTimeConditionQuery(t)
+	local := time.Date(2024, 3, 1, 10, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+
+	q.Where("ExpiresAt", ">", local)
+	require.Len(t, q.conditions, 1)
+	assert.Equal(t, local.Unix(), q.conditions[0].Value)
+
+	q.WithCondition("CreatedAt", "<", local)
+	require.Len(t, q.writeConditions, 1)
+	assert.Equal(t, "2024-03-01T10:30:00-05:00", q.writeConditions[0].Value)
+}
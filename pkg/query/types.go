@@ -32,6 +32,7 @@ type BatchExecutor interface {
 type QueryResult struct {
 	LastEvaluatedKey map[string]types.AttributeValue
 	Items            []map[string]types.AttributeValue
+	ConsumedCapacity []types.ConsumedCapacity
 	Count            int64
 	ScannedCount     int64
 }
@@ -40,6 +41,7 @@ type QueryResult struct {
 type ScanResult struct {
 	LastEvaluatedKey map[string]types.AttributeValue
 	Items            []map[string]types.AttributeValue
+	ConsumedCapacity []types.ConsumedCapacity
 	Count            int64
 	ScannedCount     int64
 }
@@ -4,6 +4,7 @@ package query
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 
@@ -45,6 +46,9 @@ func (ub *UpdateBuilder) mapFieldToDynamoDBName(field string) string {
 		if fieldMeta := ub.query.metadata.AttributeMetadata(field); fieldMeta != nil {
 			return fieldMeta.DynamoDBName
 		}
+		if strings.ContainsAny(field, ".[") {
+			return ub.query.resolveDocumentPath(field)
+		}
 	}
 	return field
 }
@@ -52,9 +56,15 @@ func (ub *UpdateBuilder) mapFieldToDynamoDBName(field string) string {
 // Set adds a SET expression to update a field
 func (ub *UpdateBuilder) Set(field string, value any) core.UpdateBuilder {
 	dbFieldName := ub.mapFieldToDynamoDBName(field)
+	if fieldMeta := ub.query.conditionFieldMetadata(field); fieldMeta != nil && fieldMeta.KeyPrefix != "" {
+		value = prefixKeyCondition(fieldMeta, value)
+	} else if fieldMeta.IsEnum() {
+		value = fieldMeta.EnumCondition(value)
+	}
 	if err := ub.expr.AddUpdateSet(dbFieldName, value); err != nil && ub.buildErr == nil {
 		ub.buildErr = fmt.Errorf("Set(%s): %w", field, err)
 	}
+	ub.updateBlindIndex(field, value)
 	return ub
 }
 
@@ -70,9 +80,13 @@ func (ub *UpdateBuilder) SetIfNotExists(field string, value any, defaultValue an
 	return ub
 }
 
-// Add increments a numeric field (atomic counter)
+// Add increments a numeric field (atomic counter), or adds the elements of a slice to a
+// set. A []byte adds one element to a binary set.
 func (ub *UpdateBuilder) Add(field string, value any) core.UpdateBuilder {
 	dbFieldName := ub.mapFieldToDynamoDBName(field)
+	if b, ok := value.([]byte); ok {
+		value = [][]byte{b}
+	}
 	if err := ub.expr.AddUpdateAdd(dbFieldName, value); err != nil && ub.buildErr == nil {
 		ub.buildErr = fmt.Errorf("Add(%s): %w", field, err)
 	}
@@ -95,9 +109,32 @@ func (ub *UpdateBuilder) Remove(field string) core.UpdateBuilder {
 	if err := ub.expr.AddUpdateRemove(dbFieldName); err != nil && ub.buildErr == nil {
 		ub.buildErr = fmt.Errorf("Remove(%s): %w", field, err)
 	}
+	ub.updateBlindIndex(field, nil)
 	return ub
 }
 
+// updateBlindIndex keeps the blind index of field, if it has one, in step with a Set of
+// value or, when value is nil, a Remove.
+func (ub *UpdateBuilder) updateBlindIndex(field string, value any) {
+	source := ub.query.conditionFieldMetadata(field)
+	index := ub.query.rawMetadata.BlindIndexFor(source)
+	if index == nil {
+		return
+	}
+	var err error
+	if isNilValue(reflect.ValueOf(value)) {
+		err = ub.expr.AddUpdateRemove(index.DBName)
+	} else {
+		var hash string
+		if hash, err = ub.query.blindIndexValue(source, value); err == nil {
+			err = ub.expr.AddUpdateSet(index.DBName, hash)
+		}
+	}
+	if err != nil && ub.buildErr == nil {
+		ub.buildErr = fmt.Errorf("blind index of %s: %w", field, err)
+	}
+}
+
 // Delete removes elements from a set
 func (ub *UpdateBuilder) Delete(field string, value any) core.UpdateBuilder {
 	dbFieldName := ub.mapFieldToDynamoDBName(field)
@@ -175,6 +212,74 @@ func (ub *UpdateBuilder) SetListElement(field string, index int, value any) core
 	return ub
 }
 
+// AppendToListWithLimit appends values to a list (creating it when absent) only if the list
+// holds at most maxLen elements afterwards. When the list is full the update fails with
+// ErrConditionFailed and nothing is written.
+func (ub *UpdateBuilder) AppendToListWithLimit(field string, values any, maxLen int) core.UpdateBuilder {
+	count, err := listValueCount(values)
+	if err == nil && count > maxLen {
+		err = fmt.Errorf("cannot append %d values to a list limited to %d", count, maxLen)
+	}
+	if err == nil {
+		err = ub.expr.AddCappedListAppend(ub.mapFieldToDynamoDBName(field), values, maxLen-count)
+	}
+	if err != nil && ub.buildErr == nil {
+		ub.buildErr = fmt.Errorf("AppendToListWithLimit(%s): %w", field, err)
+	}
+	return ub
+}
+
+func listValueCount(values any) (int, error) {
+	rv := reflect.ValueOf(values)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return 0, fmt.Errorf("values must be a slice, got %T", values)
+	}
+	if rv.Type().Elem().Kind() == reflect.Uint8 {
+		return 0, fmt.Errorf("values must be a list, not binary data")
+	}
+	return rv.Len(), nil
+}
+
+// SetMapKey sets one key of a map attribute using a document path (SET #field.#key = :v),
+// leaving the map's other keys untouched. The map attribute must already exist.
+func (ub *UpdateBuilder) SetMapKey(field string, key string, value any) core.UpdateBuilder {
+	path, err := ub.mapKeyPath(field, key)
+	if err == nil {
+		err = ub.expr.AddUpdateSet(path, value)
+	}
+	if err != nil && ub.buildErr == nil {
+		ub.buildErr = fmt.Errorf("SetMapKey(%s, %s): %w", field, key, err)
+	}
+	return ub
+}
+
+// RemoveMapKey removes one key of a map attribute using a document path (REMOVE #field.#key)
+func (ub *UpdateBuilder) RemoveMapKey(field string, key string) core.UpdateBuilder {
+	path, err := ub.mapKeyPath(field, key)
+	if err == nil {
+		err = ub.expr.AddUpdateRemove(path)
+	}
+	if err != nil && ub.buildErr == nil {
+		ub.buildErr = fmt.Errorf("RemoveMapKey(%s, %s): %w", field, key, err)
+	}
+	return ub
+}
+
+func (ub *UpdateBuilder) mapKeyPath(field string, key string) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("map key cannot be empty")
+	}
+	if strings.ContainsAny(key, ".[]") {
+		return "", fmt.Errorf("map key cannot contain '.', '[' or ']'")
+	}
+	if ub.query != nil && ub.query.rawMetadata != nil {
+		if fieldMeta := ub.query.rawMetadata.Fields[field]; fieldMeta != nil && fieldMeta.Type.Kind() != reflect.Map {
+			return "", fmt.Errorf("field %s is not a map", field)
+		}
+	}
+	return ub.mapFieldToDynamoDBName(field) + "." + key, nil
+}
+
 // Condition adds a condition that must be met for the update to succeed
 func (ub *UpdateBuilder) Condition(field string, operator string, value any) core.UpdateBuilder {
 	if ub.buildErr == nil && ub.query != nil && ub.query.metadata != nil {
@@ -184,7 +289,7 @@ func (ub *UpdateBuilder) Condition(field string, operator string, value any) cor
 				if name == "" {
 					name = field
 				}
-				ub.buildErr = fmt.Errorf("%w: %s", dynamormErrors.ErrEncryptedFieldNotQueryable, name)
+				ub.buildErr = dynamormErrors.EncryptedFieldQueryError(name)
 				return ub
 			}
 		}
@@ -193,7 +298,7 @@ func (ub *UpdateBuilder) Condition(field string, operator string, value any) cor
 	ub.conditions = append(ub.conditions, updateCondition{
 		field:    field,
 		operator: operator,
-		value:    value,
+		value:    ub.query.conditionValue(field, value),
 		logicOp:  "AND",
 	})
 	return ub
@@ -208,7 +313,7 @@ func (ub *UpdateBuilder) OrCondition(field string, operator string, value any) c
 				if name == "" {
 					name = field
 				}
-				ub.buildErr = fmt.Errorf("%w: %s", dynamormErrors.ErrEncryptedFieldNotQueryable, name)
+				ub.buildErr = dynamormErrors.EncryptedFieldQueryError(name)
 				return ub
 			}
 		}
@@ -217,7 +322,7 @@ func (ub *UpdateBuilder) OrCondition(field string, operator string, value any) c
 	ub.conditions = append(ub.conditions, updateCondition{
 		field:    field,
 		operator: operator,
-		value:    value,
+		value:    ub.query.conditionValue(field, value),
 		logicOp:  "OR",
 	})
 	return ub
@@ -281,11 +386,11 @@ func (ub *UpdateBuilder) populateKeyValues() error {
 		ub.keyValues = normalized
 
 		if _, ok := ub.keyValues[pkAttr]; !ok {
-			return fmt.Errorf("partition key %s is required for update", primaryKey.PartitionKey)
+			return dynamormErrors.MissingKeyError(primaryKey.PartitionKey, "update", false)
 		}
 		if primaryKey.SortKey != "" {
 			if _, ok := ub.keyValues[skAttr]; !ok {
-				return fmt.Errorf("sort key %s is required for update", primaryKey.SortKey)
+				return dynamormErrors.MissingKeyError(primaryKey.SortKey, "update", true)
 			}
 		}
 
@@ -304,12 +409,12 @@ func (ub *UpdateBuilder) populateKeyValues() error {
 	}
 
 	if _, ok := ub.keyValues[pkAttr]; !ok {
-		return fmt.Errorf("partition key %s is required for update", primaryKey.PartitionKey)
+		return dynamormErrors.MissingKeyError(primaryKey.PartitionKey, "update", false)
 	}
 
 	if primaryKey.SortKey != "" {
 		if _, ok := ub.keyValues[skAttr]; !ok {
-			return fmt.Errorf("sort key %s is required for update", primaryKey.SortKey)
+			return dynamormErrors.MissingKeyError(primaryKey.SortKey, "update", true)
 		}
 	}
 
@@ -322,10 +427,12 @@ func (ub *UpdateBuilder) Execute() error {
 	if ub.buildErr != nil {
 		return ub.buildErr
 	}
-
 	if err := ub.populateKeyValues(); err != nil {
 		return err
 	}
+	if err := runBeforeHook(ub.query.hookContext(), ub.query.model, hookUpdate); err != nil {
+		return err
+	}
 
 	// Add conditions to expression builder
 	for _, cond := range ub.conditions {
@@ -383,6 +490,7 @@ func (ub *UpdateBuilder) Execute() error {
 	compiled := &core.CompiledQuery{
 		Operation:                "UpdateItem",
 		TableName:                ub.query.metadata.TableName(),
+		ReturnConsumedCapacity:   ub.query.returnConsumedCapacity,
 		UpdateExpression:         updateExpr,
 		ConditionExpression:      finalCondExpr,
 		ExpressionAttributeNames: exprAttrNames,
@@ -406,7 +514,10 @@ func (ub *UpdateBuilder) Execute() error {
 
 	// Execute update through executor
 	if updateExecutor, ok := ub.query.executor.(UpdateItemExecutor); ok {
-		return updateExecutor.ExecuteUpdateItem(compiled, keyAV)
+		if err := updateExecutor.ExecuteUpdateItem(compiled, keyAV); err != nil {
+			return err
+		}
+		return runAfterHook(ub.query.hookContext(), ub.query.model, hookUpdate)
 	}
 
 	return fmt.Errorf("executor does not support UpdateItem operation")
@@ -433,6 +544,9 @@ func (ub *UpdateBuilder) ExecuteWithResult(result any) error {
 	if err := ub.populateKeyValues(); err != nil {
 		return err
 	}
+	if err := runBeforeHook(ub.query.hookContext(), ub.query.model, hookUpdate); err != nil {
+		return err
+	}
 
 	// Add conditions to expression builder
 	for _, cond := range ub.conditions {
@@ -489,6 +603,7 @@ func (ub *UpdateBuilder) ExecuteWithResult(result any) error {
 	compiled := &core.CompiledQuery{
 		Operation:                "UpdateItem",
 		TableName:                ub.query.metadata.TableName(),
+		ReturnConsumedCapacity:   ub.query.returnConsumedCapacity,
 		UpdateExpression:         updateExpr,
 		ConditionExpression:      finalCondExpr,
 		ExpressionAttributeNames: exprAttrNames,
@@ -527,14 +642,19 @@ func (ub *UpdateBuilder) ExecuteWithResult(result any) error {
 				}
 			}
 			mapAV := &types.AttributeValueMemberM{Value: normalized}
-			return expr.ConvertFromAttributeValue(mapAV, result)
+			if err := expr.ConvertFromAttributeValue(mapAV, result); err != nil {
+				return err
+			}
 		}
-		return nil
+		return runAfterHook(ub.query.hookContext(), ub.query.model, hookUpdate)
 	}
 
 	// Fallback to regular update without result
 	if updateExecutor, ok := ub.query.executor.(UpdateItemExecutor); ok {
-		return updateExecutor.ExecuteUpdateItem(compiled, keyAV)
+		if err := updateExecutor.ExecuteUpdateItem(compiled, keyAV); err != nil {
+			return err
+		}
+		return runAfterHook(ub.query.hookContext(), ub.query.model, hookUpdate)
 	}
 
 	return fmt.Errorf("executor does not support UpdateItem operation")
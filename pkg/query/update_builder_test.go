@@ -291,6 +291,50 @@ func TestUpdateBuilder_RemoveAttribute(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestUpdateBuilder_MapKeyOperations(t *testing.T) {
+	executor := new(mockUpdateExecutor)
+	metadata := &mockMetadata{
+		tableName: "Users",
+		primaryKey: core.KeySchema{
+			PartitionKey: "ID",
+		},
+	}
+
+	q := &Query{
+		executor: executor,
+		metadata: metadata,
+		conditions: []Condition{
+			{Field: "ID", Operator: "=", Value: "user789"},
+		},
+	}
+
+	var captured *core.CompiledQuery
+	executor.On("ExecuteUpdateItem", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		captured = args.Get(0).(*core.CompiledQuery)
+	}).Return(nil)
+
+	err := q.UpdateBuilder().
+		SetMapKey("Preferences", "theme", "dark").
+		RemoveMapKey("Preferences", "legacyLayout").
+		Execute()
+	assert.NoError(t, err)
+
+	assert.Regexp(t, `SET #n\d+\.#n\d+ = :v\d+`, captured.UpdateExpression)
+	assert.Regexp(t, `REMOVE #n\d+\.#n\d+`, captured.UpdateExpression)
+	names := make([]string, 0, len(captured.ExpressionAttributeNames))
+	for _, name := range captured.ExpressionAttributeNames {
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{"Preferences", "theme", "legacyLayout"}, names)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "dark"}, captured.ExpressionAttributeValues[":v1"])
+
+	err = q.UpdateBuilder().SetMapKey("Preferences", "a.b", "x").Execute()
+	assert.ErrorContains(t, err, "map key cannot contain")
+
+	err = q.UpdateBuilder().RemoveMapKey("Preferences", "").Execute()
+	assert.ErrorContains(t, err, "map key cannot be empty")
+}
+
 func TestUpdateBuilder_ComplexUpdate(t *testing.T) {
 	// Test a complex update with multiple operations
 	executor := new(mockUpdateExecutor)
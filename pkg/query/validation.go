@@ -0,0 +1,104 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	dynamormErrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// validateModel checks item against its validation tags and Validate methods before a
+// write, returning a *errors.ValidationError with every failure. fields limits the
+// checks to the fields an Update writes; empty checks them all.
+func (q *Query) validateModel(item any, fields []string) error {
+	ctx := q.hookContext()
+	var failures []dynamormErrors.FieldError
+
+	modelValue := reflect.ValueOf(item)
+	for modelValue.Kind() == reflect.Ptr && !modelValue.IsNil() {
+		modelValue = modelValue.Elem()
+	}
+	if q.rawMetadata != nil && modelValue.Kind() == reflect.Struct {
+		for _, fieldMeta := range q.fieldsToValidate(fields) {
+			fieldValue := modelValue.FieldByIndex(fieldMeta.IndexPath)
+			failures = append(failures, fieldMeta.Validate(fieldValue)...)
+			if err := runFieldValidator(ctx, fieldValue); err != nil {
+				failures = append(failures, customFailure(fieldMeta.Name, err)...)
+			}
+		}
+	}
+
+	if validator, ok := item.(core.Validator); ok {
+		if err := validator.Validate(ctx); err != nil {
+			failures = append(failures, customFailure("", err)...)
+		}
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+	return &dynamormErrors.ValidationError{Model: modelValue.Type().Name(), Fields: failures}
+}
+
+// fieldsToValidate returns the metadata of the named fields, or of every field in
+// declaration order when names is empty. Unknown names are left for the write to report.
+func (q *Query) fieldsToValidate(names []string) []*model.FieldMetadata {
+	if len(names) > 0 {
+		fields := make([]*model.FieldMetadata, 0, len(names))
+		for _, name := range names {
+			if fieldMeta, err := q.updateFieldMetadata(name); err == nil {
+				fields = append(fields, fieldMeta)
+			}
+		}
+		return fields
+	}
+
+	fields := make([]*model.FieldMetadata, 0, len(q.rawMetadata.Fields))
+	for _, fieldMeta := range q.rawMetadata.Fields {
+		if fieldMeta != nil {
+			fields = append(fields, fieldMeta)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i].IndexPath, fields[j].IndexPath
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return fields
+}
+
+// runFieldValidator calls Validate on a field whose type implements core.Validator.
+func runFieldValidator(ctx context.Context, fieldValue reflect.Value) error {
+	if (fieldValue.Kind() == reflect.Ptr || fieldValue.Kind() == reflect.Interface) && fieldValue.IsNil() {
+		return nil
+	}
+	if fieldValue.CanAddr() {
+		if validator, ok := fieldValue.Addr().Interface().(core.Validator); ok {
+			return validator.Validate(ctx)
+		}
+	}
+	if fieldValue.CanInterface() {
+		if validator, ok := fieldValue.Interface().(core.Validator); ok {
+			return validator.Validate(ctx)
+		}
+	}
+	return nil
+}
+
+// customFailure converts an error from a Validate method into field errors, keeping
+// the failures of a returned *errors.ValidationError.
+func customFailure(field string, err error) []dynamormErrors.FieldError {
+	var validationErr *dynamormErrors.ValidationError
+	if errors.As(err, &validationErr) && validationErr != nil {
+		return validationErr.Fields
+	}
+	return []dynamormErrors.FieldError{{Field: field, Rule: "custom", Message: err.Error()}}
+}
@@ -0,0 +1,450 @@
+// Package querystring parses a small SQL-like query language into a structured
+// statement that can be applied to the DynamORM fluent builder.
+//
+// Grammar (keywords are case-insensitive):
+//
+//	[SELECT field {, field}] FROM model [INDEX name]
+//	[WHERE cond {AND cond}]
+//	[ORDER BY field [ASC|DESC]] [LIMIT n] [CONSISTENT]
+//
+//	cond := field op value
+//	      | field BETWEEN value AND value
+//	      | field IN ( value {, value} )
+//	      | field BEGINS_WITH value | field CONTAINS value
+//	      | field EXISTS | field NOT EXISTS
+//	op   := = | != | <> | < | <= | > | >=
+//	value := ? | 'string' | number | TRUE | FALSE
+//
+// Values are never interpolated into expressions: `?` placeholders bind positional
+// arguments and literals become typed values, so the result is as safe as calling the
+// builder directly. OR is intentionally unsupported because the builder decides which
+// conditions become key conditions.
+package querystring
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrSyntax is wrapped by every parse error.
+var ErrSyntax = errors.New("query string syntax error")
+
+// Condition is one WHERE clause term. Operator uses the builder's spelling
+// (e.g. "=", "BETWEEN", "BEGINS_WITH", "NOT_EXISTS").
+type Condition struct {
+	Value    any
+	Field    string
+	Operator string
+}
+
+// Statement is a parsed query string.
+type Statement struct {
+	Model          string
+	Index          string
+	OrderBy        string
+	Order          string
+	Select         []string
+	Conditions     []Condition
+	Limit          int
+	ConsistentRead bool
+}
+
+// Fields returns every field name referenced by the statement, in order of appearance.
+func (s *Statement) Fields() []string {
+	fields := make([]string, 0, len(s.Select)+len(s.Conditions)+1)
+	fields = append(fields, s.Select...)
+	for _, cond := range s.Conditions {
+		fields = append(fields, cond.Field)
+	}
+	if s.OrderBy != "" {
+		fields = append(fields, s.OrderBy)
+	}
+	return fields
+}
+
+// Parse parses input, binding `?` placeholders to args in order. The number of
+// placeholders must match len(args).
+func Parse(input string, args ...any) (*Statement, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, args: args}
+	stmt, err := p.parseStatement()
+	if err != nil {
+		return nil, err
+	}
+	if p.argIndex != len(args) {
+		return nil, fmt.Errorf("%w: query has %d placeholders but %d arguments were given", ErrSyntax, p.argIndex, len(args))
+	}
+	return stmt, nil
+}
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenString
+	tokenNumber
+	tokenPlaceholder
+	tokenSymbol
+	tokenEOF
+)
+
+type token struct {
+	text string
+	kind tokenKind
+	pos  int
+}
+
+func (t token) is(keyword string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.text, keyword)
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '?':
+			tokens = append(tokens, token{kind: tokenPlaceholder, text: "?", pos: i})
+			i++
+		case r == '\'':
+			var sb strings.Builder
+			start := i
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						sb.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					closed = true
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("%w: unterminated string at position %d", ErrSyntax, start)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), pos: start})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.' || runes[i] == '-') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+		case strings.ContainsRune("(),", r):
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(r), pos: i})
+			i++
+		case strings.ContainsRune("=<>!", r):
+			start := i
+			i++
+			if i < len(runes) && (runes[i] == '=' || (r == '<' && runes[i] == '>')) {
+				i++
+			}
+			text := string(runes[start:i])
+			if text == "!" {
+				return nil, fmt.Errorf("%w: unexpected '!' at position %d", ErrSyntax, start)
+			}
+			tokens = append(tokens, token{kind: tokenSymbol, text: text, pos: start})
+		default:
+			return nil, fmt.Errorf("%w: unexpected character %q at position %d", ErrSyntax, r, i)
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+type parser struct {
+	tokens   []token
+	args     []any
+	pos      int
+	argIndex int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return fmt.Errorf("%w: %s at position %d", ErrSyntax, fmt.Sprintf(format, args...), t.pos)
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	t := p.next()
+	if !t.is(keyword) {
+		return p.errorf(t, "expected %s", strings.ToUpper(keyword))
+	}
+	return nil
+}
+
+func (p *parser) expectSymbol(symbol string) error {
+	t := p.next()
+	if t.kind != tokenSymbol || t.text != symbol {
+		return p.errorf(t, "expected %q", symbol)
+	}
+	return nil
+}
+
+func (p *parser) identifier(what string) (string, error) {
+	t := p.next()
+	if t.kind != tokenIdent || isReserved(t.text) {
+		return "", p.errorf(t, "expected %s", what)
+	}
+	return t.text, nil
+}
+
+var reserved = map[string]bool{
+	"select": true, "from": true, "index": true, "where": true, "and": true, "or": true,
+	"order": true, "by": true, "limit": true, "consistent": true, "between": true, "in": true,
+	"begins_with": true, "contains": true, "exists": true, "not": true, "asc": true, "desc": true,
+}
+
+func isReserved(word string) bool {
+	return reserved[strings.ToLower(word)]
+}
+
+func (p *parser) parseStatement() (*Statement, error) {
+	stmt := &Statement{}
+
+	if p.peek().is("select") {
+		p.next()
+		for {
+			field, err := p.identifier("field name")
+			if err != nil {
+				return nil, err
+			}
+			stmt.Select = append(stmt.Select, field)
+			if t := p.peek(); t.kind == tokenSymbol && t.text == "," {
+				p.next()
+				continue
+			}
+			break
+		}
+	}
+
+	if err := p.expectKeyword("from"); err != nil {
+		return nil, err
+	}
+	modelName, err := p.identifier("model name")
+	if err != nil {
+		return nil, err
+	}
+	stmt.Model = modelName
+
+	if p.peek().is("index") {
+		p.next()
+		if stmt.Index, err = p.identifier("index name"); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek().is("where") {
+		p.next()
+		for {
+			cond, err := p.parseCondition()
+			if err != nil {
+				return nil, err
+			}
+			stmt.Conditions = append(stmt.Conditions, cond)
+
+			t := p.peek()
+			if t.is("and") {
+				p.next()
+				continue
+			}
+			if t.is("or") {
+				return nil, p.errorf(t, "OR is not supported")
+			}
+			break
+		}
+	}
+
+	if p.peek().is("order") {
+		p.next()
+		if err := p.expectKeyword("by"); err != nil {
+			return nil, err
+		}
+		if stmt.OrderBy, err = p.identifier("field name"); err != nil {
+			return nil, err
+		}
+		stmt.Order = "ASC"
+		if t := p.peek(); t.is("asc") || t.is("desc") {
+			stmt.Order = strings.ToUpper(p.next().text)
+		}
+	}
+
+	if p.peek().is("limit") {
+		p.next()
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		limit, ok := toInt(value)
+		if !ok || limit <= 0 {
+			return nil, fmt.Errorf("%w: LIMIT must be a positive integer", ErrSyntax)
+		}
+		stmt.Limit = limit
+	}
+
+	if p.peek().is("consistent") {
+		p.next()
+		stmt.ConsistentRead = true
+	}
+
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorf(t, "unexpected %q", t.text)
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseCondition() (Condition, error) {
+	field, err := p.identifier("field name")
+	if err != nil {
+		return Condition{}, err
+	}
+	cond := Condition{Field: field}
+
+	t := p.next()
+	switch {
+	case t.kind == tokenSymbol && isComparison(t.text):
+		cond.Operator = t.text
+		cond.Value, err = p.parseValue()
+	case t.is("between"):
+		cond.Operator = "BETWEEN"
+		var low, high any
+		if low, err = p.parseValue(); err != nil {
+			return cond, err
+		}
+		if err = p.expectKeyword("and"); err != nil {
+			return cond, err
+		}
+		if high, err = p.parseValue(); err != nil {
+			return cond, err
+		}
+		cond.Value = []any{low, high}
+	case t.is("in"):
+		cond.Operator = "IN"
+		cond.Value, err = p.parseValueList()
+	case t.is("begins_with"):
+		cond.Operator = "BEGINS_WITH"
+		cond.Value, err = p.parseValue()
+	case t.is("contains"):
+		cond.Operator = "CONTAINS"
+		cond.Value, err = p.parseValue()
+	case t.is("exists"):
+		cond.Operator = "EXISTS"
+	case t.is("not"):
+		if err = p.expectKeyword("exists"); err != nil {
+			return cond, err
+		}
+		cond.Operator = "NOT_EXISTS"
+	default:
+		return cond, p.errorf(t, "expected operator after %s", field)
+	}
+	return cond, err
+}
+
+func isComparison(symbol string) bool {
+	switch symbol {
+	case "=", "!=", "<>", "<", "<=", ">", ">=":
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseValueList() ([]any, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	var values []any
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		t := p.next()
+		if t.kind == tokenSymbol && t.text == ")" {
+			return values, nil
+		}
+		if t.kind != tokenSymbol || t.text != "," {
+			return nil, p.errorf(t, "expected ',' or ')'")
+		}
+	}
+}
+
+func (p *parser) parseValue() (any, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenPlaceholder:
+		if p.argIndex >= len(p.args) {
+			return nil, p.errorf(t, "missing argument for placeholder %d", p.argIndex+1)
+		}
+		value := p.args[p.argIndex]
+		p.argIndex++
+		return value, nil
+	case tokenString:
+		return t.text, nil
+	case tokenNumber:
+		if strings.Contains(t.text, ".") {
+			f, err := strconv.ParseFloat(t.text, 64)
+			if err != nil {
+				return nil, p.errorf(t, "invalid number %q", t.text)
+			}
+			return f, nil
+		}
+		n, err := strconv.ParseInt(t.text, 10, 64)
+		if err != nil {
+			return nil, p.errorf(t, "invalid number %q", t.text)
+		}
+		return n, nil
+	case tokenIdent:
+		if t.is("true") {
+			return true, nil
+		}
+		if t.is("false") {
+			return false, nil
+		}
+	}
+	return nil, p.errorf(t, "expected a value")
+}
+
+func toInt(value any) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	}
+	return 0, false
+}
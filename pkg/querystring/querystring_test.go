@@ -0,0 +1,76 @@
+package querystring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_FullStatement(t *testing.T) {
+	stmt, err := Parse(
+		"SELECT CustomerID, Total FROM Orders INDEX gsi-customer WHERE CustomerID = ? AND CreatedAt > ? ORDER BY CreatedAt desc LIMIT 50 consistent",
+		"c1", "2024-01-01",
+	)
+	require.NoError(t, err)
+
+	require.Equal(t, "Orders", stmt.Model)
+	require.Equal(t, "gsi-customer", stmt.Index)
+	require.Equal(t, []string{"CustomerID", "Total"}, stmt.Select)
+	require.Equal(t, []Condition{
+		{Field: "CustomerID", Operator: "=", Value: "c1"},
+		{Field: "CreatedAt", Operator: ">", Value: "2024-01-01"},
+	}, stmt.Conditions)
+	require.Equal(t, "CreatedAt", stmt.OrderBy)
+	require.Equal(t, "DESC", stmt.Order)
+	require.Equal(t, 50, stmt.Limit)
+	require.True(t, stmt.ConsistentRead)
+	require.Equal(t, []string{"CustomerID", "Total", "CustomerID", "CreatedAt", "CreatedAt"}, stmt.Fields())
+}
+
+func TestParse_ConditionForms(t *testing.T) {
+	stmt, err := Parse(
+		"from Orders where Total between ? and 100 and Status in ('open', 'it''s') and SKU begins_with 'A-' "+
+			"and Tags contains ? and Note exists and DeletedAt not exists and Active = true and Price <= 9.5 and Kind <> ?",
+		10, "red", "x",
+	)
+	require.NoError(t, err)
+	require.Equal(t, []Condition{
+		{Field: "Total", Operator: "BETWEEN", Value: []any{10, int64(100)}},
+		{Field: "Status", Operator: "IN", Value: []any{"open", "it's"}},
+		{Field: "SKU", Operator: "BEGINS_WITH", Value: "A-"},
+		{Field: "Tags", Operator: "CONTAINS", Value: "red"},
+		{Field: "Note", Operator: "EXISTS"},
+		{Field: "DeletedAt", Operator: "NOT_EXISTS"},
+		{Field: "Active", Operator: "=", Value: true},
+		{Field: "Price", Operator: "<=", Value: 9.5},
+		{Field: "Kind", Operator: "<>", Value: "x"},
+	}, stmt.Conditions)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+		args  []any
+	}{
+		{name: "missing from", query: "where a = 1", want: "expected FROM"},
+		{name: "or unsupported", query: "from T where a = 1 or b = 2", want: "OR is not supported"},
+		{name: "too few args", query: "from T where a = ? and b = ?", args: []any{1}, want: "missing argument for placeholder 2"},
+		{name: "too many args", query: "from T where a = ?", args: []any{1, 2}, want: "1 placeholders but 2 arguments"},
+		{name: "bad limit", query: "from T limit 0", want: "LIMIT must be a positive integer"},
+		{name: "unterminated string", query: "from T where a = 'x", want: "unterminated string"},
+		{name: "trailing tokens", query: "from T where a = 1 b", want: `unexpected "b"`},
+		{name: "reserved field", query: "from T where limit = 1", want: "expected field name"},
+		{name: "missing operator", query: "from T where a 1", want: "expected operator after a"},
+		{name: "bad character", query: "from T; drop", want: "unexpected character ';'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.query, tt.args...)
+			require.ErrorIs(t, err, ErrSyntax)
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
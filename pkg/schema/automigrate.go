@@ -20,6 +20,7 @@ type AutoMigrateOptions struct {
 	BackupTable string
 	BatchSize   int
 	DataCopy    bool
+	SkipTTL     bool
 }
 
 // AutoMigrateOption is a function that configures AutoMigrateOptions
@@ -60,6 +61,14 @@ func WithBatchSize(size int) AutoMigrateOption {
 	}
 }
 
+// WithoutTTLEnablement stops AutoMigrate from enabling Time to Live when it creates the
+// target table
+func WithoutTTLEnablement() AutoMigrateOption {
+	return func(opts *AutoMigrateOptions) {
+		opts.SkipTTL = true
+	}
+}
+
 // WithContext sets the context for the operation
 func WithContext(ctx context.Context) AutoMigrateOption {
 	return func(opts *AutoMigrateOptions) {
@@ -80,7 +89,11 @@ func (m *Manager) AutoMigrateWithOptions(sourceModel any, options ...AutoMigrate
 		return err
 	}
 
-	if err := m.ensureTargetTable(targetModel, targetMetadata.TableName); err != nil {
+	var tableOpts []TableOption
+	if opts.SkipTTL {
+		tableOpts = append(tableOpts, WithoutTTL())
+	}
+	if err := m.ensureTargetTable(targetModel, targetMetadata.TableName, tableOpts...); err != nil {
 		return err
 	}
 
@@ -134,14 +147,14 @@ func (m *Manager) createBackupIfRequested(ctx context.Context, sourceTable strin
 	return nil
 }
 
-func (m *Manager) ensureTargetTable(targetModel any, tableName string) error {
+func (m *Manager) ensureTargetTable(targetModel any, tableName string, opts ...TableOption) error {
 	exists, err := m.TableExists(tableName)
 	if err != nil {
 		return fmt.Errorf("failed to check target table existence: %w", err)
 	}
 
 	if !exists {
-		if err := m.CreateTable(targetModel); err != nil {
+		if err := m.CreateTable(targetModel, opts...); err != nil {
 			return fmt.Errorf("failed to create target table: %w", err)
 		}
 	}
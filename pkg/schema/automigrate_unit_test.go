@@ -146,6 +146,15 @@ func countRequestsByTarget(reqs []capturedRequest, target string) int {
 	return count
 }
 
+func findRequestByTarget(reqs []capturedRequest, target string) *capturedRequest {
+	for i := range reqs {
+		if reqs[i].Target == target {
+			return &reqs[i]
+		}
+	}
+	return nil
+}
+
 func newTestManager(t *testing.T, httpClient aws.HTTPClient) *Manager {
 	t.Helper()
 
@@ -0,0 +1,122 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// CapacityKind selects the read or write capacity of a table or index
+type CapacityKind string
+
+const (
+	// ReadCapacity scales read capacity units
+	ReadCapacity CapacityKind = "ReadCapacityUnits"
+	// WriteCapacity scales write capacity units
+	WriteCapacity CapacityKind = "WriteCapacityUnits"
+)
+
+// AutoScalingTarget describes target-tracking auto scaling for one capacity dimension
+// of a provisioned table or one of its global secondary indexes.
+type AutoScalingTarget struct {
+	// IndexName selects a GSI; empty targets the table itself.
+	IndexName string
+	Capacity  CapacityKind
+	// TargetUtilization is the consumed/provisioned percentage to track (20-90).
+	TargetUtilization float64
+	MinCapacity       int32
+	MaxCapacity       int32
+}
+
+// ScalingRegistration is an AutoScalingTarget resolved to Application Auto Scaling
+// identifiers for a concrete table.
+type ScalingRegistration struct {
+	// ResourceID is "table/<table>" or "table/<table>/index/<index>".
+	ResourceID string
+	// ScalableDimension is e.g. "dynamodb:table:ReadCapacityUnits".
+	ScalableDimension string
+	PolicyName        string
+	TargetUtilization float64
+	MinCapacity       int32
+	MaxCapacity       int32
+}
+
+// AutoScaler applies scaling registrations. Implementations typically call the
+// Application Auto Scaling RegisterScalableTarget and PutScalingPolicy APIs with the
+// DynamoDBReadCapacityUtilization/DynamoDBWriteCapacityUtilization predefined metrics.
+type AutoScaler interface {
+	RegisterScaling(ctx context.Context, registration ScalingRegistration) error
+}
+
+// ConfigureAutoScaling registers auto-scaling targets for the model's table and GSIs.
+// The table must use provisioned billing; call it after CreateTable returns.
+func (m *Manager) ConfigureAutoScaling(model any, scaler AutoScaler, targets ...AutoScalingTarget) error {
+	if scaler == nil {
+		return fmt.Errorf("auto scaler cannot be nil")
+	}
+
+	metadata, err := m.registry.GetMetadata(model)
+	if err != nil {
+		return fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	registrations := make([]ScalingRegistration, 0, len(targets))
+	for _, target := range targets {
+		registration, err := buildScalingRegistration(metadata, target)
+		if err != nil {
+			return err
+		}
+		registrations = append(registrations, registration)
+	}
+
+	ctx := context.Background()
+	for _, registration := range registrations {
+		if err := scaler.RegisterScaling(ctx, registration); err != nil {
+			return fmt.Errorf("failed to configure auto scaling for %s %s: %w",
+				registration.ResourceID, registration.ScalableDimension, err)
+		}
+	}
+	return nil
+}
+
+func buildScalingRegistration(metadata *model.Metadata, target AutoScalingTarget) (ScalingRegistration, error) {
+	if target.Capacity != ReadCapacity && target.Capacity != WriteCapacity {
+		return ScalingRegistration{}, fmt.Errorf("invalid auto scaling capacity %q", target.Capacity)
+	}
+	if target.MinCapacity < 1 || target.MaxCapacity < target.MinCapacity {
+		return ScalingRegistration{}, fmt.Errorf("invalid auto scaling capacity range %d-%d", target.MinCapacity, target.MaxCapacity)
+	}
+	if target.TargetUtilization < 20 || target.TargetUtilization > 90 {
+		return ScalingRegistration{}, fmt.Errorf("auto scaling target utilization must be between 20 and 90, got %v", target.TargetUtilization)
+	}
+
+	registration := ScalingRegistration{
+		ResourceID:        "table/" + metadata.TableName,
+		ScalableDimension: "dynamodb:table:" + string(target.Capacity),
+		PolicyName:        fmt.Sprintf("%s-%s-scaling", metadata.TableName, target.Capacity),
+		TargetUtilization: target.TargetUtilization,
+		MinCapacity:       target.MinCapacity,
+		MaxCapacity:       target.MaxCapacity,
+	}
+
+	if target.IndexName != "" {
+		if !hasGlobalIndex(metadata, target.IndexName) {
+			return ScalingRegistration{}, fmt.Errorf("global secondary index %s not found on table %s", target.IndexName, metadata.TableName)
+		}
+		registration.ResourceID += "/index/" + target.IndexName
+		registration.ScalableDimension = "dynamodb:index:" + string(target.Capacity)
+		registration.PolicyName = fmt.Sprintf("%s-%s-%s-scaling", metadata.TableName, target.IndexName, target.Capacity)
+	}
+
+	return registration, nil
+}
+
+func hasGlobalIndex(metadata *model.Metadata, name string) bool {
+	for _, index := range metadata.Indexes {
+		if index.Type == model.GlobalSecondaryIndex && index.Name == name {
+			return true
+		}
+	}
+	return false
+}
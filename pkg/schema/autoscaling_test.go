@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+type recordingAutoScaler struct {
+	err           error
+	registrations []ScalingRegistration
+}
+
+func (r *recordingAutoScaler) RegisterScaling(_ context.Context, registration ScalingRegistration) error {
+	r.registrations = append(r.registrations, registration)
+	return r.err
+}
+
+func TestManager_ConfigureAutoScaling(t *testing.T) {
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&cov6ManagerTwoGSIsModel{}))
+	mgr := NewManager(nil, registry)
+
+	scaler := &recordingAutoScaler{}
+	err := mgr.ConfigureAutoScaling(&cov6ManagerTwoGSIsModel{}, scaler,
+		AutoScalingTarget{Capacity: ReadCapacity, MinCapacity: 5, MaxCapacity: 100, TargetUtilization: 70},
+		AutoScalingTarget{IndexName: "one", Capacity: WriteCapacity, MinCapacity: 1, MaxCapacity: 10, TargetUtilization: 50},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []ScalingRegistration{
+		{
+			ResourceID:        "table/tbl",
+			ScalableDimension: "dynamodb:table:ReadCapacityUnits",
+			PolicyName:        "tbl-ReadCapacityUnits-scaling",
+			TargetUtilization: 70,
+			MinCapacity:       5,
+			MaxCapacity:       100,
+		},
+		{
+			ResourceID:        "table/tbl/index/one",
+			ScalableDimension: "dynamodb:index:WriteCapacityUnits",
+			PolicyName:        "tbl-one-WriteCapacityUnits-scaling",
+			TargetUtilization: 50,
+			MinCapacity:       1,
+			MaxCapacity:       10,
+		},
+	}, scaler.registrations)
+}
+
+func TestManager_ConfigureAutoScaling_Validation(t *testing.T) {
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&cov6ManagerTwoGSIsModel{}))
+	mgr := NewManager(nil, registry)
+	valid := AutoScalingTarget{Capacity: ReadCapacity, MinCapacity: 1, MaxCapacity: 10, TargetUtilization: 70}
+
+	require.ErrorContains(t, mgr.ConfigureAutoScaling(&cov6ManagerTwoGSIsModel{}, nil, valid), "auto scaler cannot be nil")
+
+	cases := map[string]AutoScalingTarget{
+		"invalid auto scaling capacity":            {Capacity: "Other", MinCapacity: 1, MaxCapacity: 10, TargetUtilization: 70},
+		"invalid auto scaling capacity range":      {Capacity: ReadCapacity, MinCapacity: 10, MaxCapacity: 5, TargetUtilization: 70},
+		"target utilization must be between":       {Capacity: ReadCapacity, MinCapacity: 1, MaxCapacity: 10, TargetUtilization: 95},
+		"global secondary index missing not found": {IndexName: "missing", Capacity: ReadCapacity, MinCapacity: 1, MaxCapacity: 10, TargetUtilization: 70},
+	}
+	for msg, target := range cases {
+		scaler := &recordingAutoScaler{}
+		require.ErrorContains(t, mgr.ConfigureAutoScaling(&cov6ManagerTwoGSIsModel{}, scaler, valid, target), msg)
+		require.Empty(t, scaler.registrations, "targets are validated before any registration")
+	}
+
+	scaler := &recordingAutoScaler{err: errors.New("denied")}
+	err := mgr.ConfigureAutoScaling(&cov6ManagerTwoGSIsModel{}, scaler, valid)
+	require.ErrorContains(t, err, "failed to configure auto scaling for table/tbl dynamodb:table:ReadCapacityUnits: denied")
+}
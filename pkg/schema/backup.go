@@ -0,0 +1,271 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type backupSettings struct {
+	exportTime  *time.Time
+	bucketOwner string
+	format      types.ExportFormat
+	wait        time.Duration
+}
+
+// BackupOption configures CreateBackup, RestoreBackup, and ExportToS3.
+type BackupOption func(*backupSettings)
+
+// WithBackupWait blocks until the operation finishes, or until timeout elapses: until the
+// backup is AVAILABLE, the restored table is ACTIVE, or the export has COMPLETED.
+func WithBackupWait(timeout time.Duration) BackupOption {
+	return func(s *backupSettings) {
+		s.wait = timeout
+	}
+}
+
+// WithExportFormat sets the format ExportToS3 writes, DYNAMODB_JSON by default.
+func WithExportFormat(format types.ExportFormat) BackupOption {
+	return func(s *backupSettings) {
+		s.format = format
+	}
+}
+
+// WithExportTime exports the table as it was at t, which must fall within the
+// point-in-time recovery window. By default ExportToS3 exports the current state.
+func WithExportTime(t time.Time) BackupOption {
+	return func(s *backupSettings) {
+		s.exportTime = &t
+	}
+}
+
+// WithExportBucketOwner sets the account ID that owns the export bucket, for buckets in
+// another account.
+func WithExportBucketOwner(accountID string) BackupOption {
+	return func(s *backupSettings) {
+		s.bucketOwner = accountID
+	}
+}
+
+func newBackupSettings(opts []BackupOption) *backupSettings {
+	settings := &backupSettings{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(settings)
+		}
+	}
+	return settings
+}
+
+// CreateBackup creates an on-demand backup of the model's table named name and returns
+// its details, including the BackupArn that RestoreBackup takes.
+func (m *Manager) CreateBackup(model any, name string, opts ...BackupOption) (*types.BackupDetails, error) {
+	if name == "" {
+		return nil, errors.New("backup name is required")
+	}
+	settings := newBackupSettings(opts)
+	tableName, client, err := m.readinessTarget(model)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.CreateBackup(context.Background(), &dynamodb.CreateBackupInput{
+		TableName:  aws.String(tableName),
+		BackupName: aws.String(name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup %s of table %s: %w", name, tableName, err)
+	}
+	details := output.BackupDetails
+	if settings.wait <= 0 || details == nil {
+		return details, nil
+	}
+
+	description, err := m.WaitForBackup(aws.ToString(details.BackupArn), settings.wait)
+	if err != nil {
+		return details, err
+	}
+	return description.BackupDetails, nil
+}
+
+// WaitForBackup blocks until the backup is AVAILABLE, or until timeout elapses.
+func (m *Manager) WaitForBackup(backupARN string, timeout time.Duration) (*types.BackupDescription, error) {
+	client, err := m.session.Client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client for backup status: %w", err)
+	}
+
+	var description *types.BackupDescription
+	err = pollUntil(timeout, "backup "+backupARN, func(ctx context.Context) (bool, error) {
+		output, err := client.DescribeBackup(ctx, &dynamodb.DescribeBackupInput{BackupArn: aws.String(backupARN)})
+		if err != nil {
+			return false, fmt.Errorf("failed to describe backup %s: %w", backupARN, err)
+		}
+		description = output.BackupDescription
+		if description == nil || description.BackupDetails == nil {
+			return false, nil
+		}
+		switch description.BackupDetails.BackupStatus {
+		case types.BackupStatusAvailable:
+			return true, nil
+		case types.BackupStatusDeleted:
+			return false, fmt.Errorf("backup %s was deleted", backupARN)
+		default:
+			return false, nil
+		}
+	})
+	return description, err
+}
+
+// RestoreBackup restores a backup into a new table named newTable, which must not exist.
+// newTable is used as given; it is not a model, so table naming does not apply.
+func (m *Manager) RestoreBackup(backupARN, newTable string, opts ...BackupOption) (*types.TableDescription, error) {
+	if backupARN == "" || newTable == "" {
+		return nil, errors.New("backup ARN and target table name are required")
+	}
+	settings := newBackupSettings(opts)
+	client, err := m.session.Client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client for backup restore: %w", err)
+	}
+
+	output, err := client.RestoreTableFromBackup(context.Background(), &dynamodb.RestoreTableFromBackupInput{
+		BackupArn:       aws.String(backupARN),
+		TargetTableName: aws.String(newTable),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore backup %s to table %s: %w", backupARN, newTable, err)
+	}
+	if settings.wait <= 0 {
+		return output.TableDescription, nil
+	}
+	return m.WaitForTable(newTable, settings.wait)
+}
+
+// WaitForTable blocks until the named table is ACTIVE, or until timeout elapses. Unlike
+// WaitForActive it takes a table name, for tables with no model such as restore targets.
+func (m *Manager) WaitForTable(tableName string, timeout time.Duration) (*types.TableDescription, error) {
+	client, err := m.session.Client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client for readiness check: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var description *types.TableDescription
+	err = pollTable(ctx, client, tableName, func(table *types.TableDescription) bool {
+		description = table
+		return table.TableStatus == types.TableStatusActive
+	})
+	return description, err
+}
+
+// ExportToS3 exports the model's table to s3://bucket/prefix with the native export,
+// which reads from point-in-time recovery and consumes no table capacity. The table must
+// have point-in-time recovery enabled. The data files can be loaded back with
+// ImportTable.
+func (m *Manager) ExportToS3(model any, bucket, prefix string, opts ...BackupOption) (*types.ExportDescription, error) {
+	if bucket == "" {
+		return nil, errors.New("export bucket is required")
+	}
+	settings := newBackupSettings(opts)
+	table, err := m.DescribeTable(model)
+	if err != nil {
+		return nil, err
+	}
+	client, err := m.session.Client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client for table export: %w", err)
+	}
+
+	input := &dynamodb.ExportTableToPointInTimeInput{
+		TableArn:     table.TableArn,
+		S3Bucket:     aws.String(bucket),
+		ExportFormat: settings.format,
+		ExportTime:   settings.exportTime,
+	}
+	if input.ExportFormat == "" {
+		input.ExportFormat = types.ExportFormatDynamodbJson
+	}
+	if prefix != "" {
+		input.S3Prefix = aws.String(prefix)
+	}
+	if settings.bucketOwner != "" {
+		input.S3BucketOwner = aws.String(settings.bucketOwner)
+	}
+
+	output, err := client.ExportTableToPointInTime(context.Background(), input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export table %s: %w", aws.ToString(table.TableName), err)
+	}
+	description := output.ExportDescription
+	if settings.wait <= 0 || description == nil {
+		return description, nil
+	}
+	return m.WaitForExport(aws.ToString(description.ExportArn), settings.wait)
+}
+
+// WaitForExport blocks until the export has COMPLETED, or until timeout elapses. A
+// failed export returns its failure message.
+func (m *Manager) WaitForExport(exportARN string, timeout time.Duration) (*types.ExportDescription, error) {
+	client, err := m.session.Client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client for export status: %w", err)
+	}
+
+	var description *types.ExportDescription
+	err = pollUntil(timeout, "export "+exportARN, func(ctx context.Context) (bool, error) {
+		output, err := client.DescribeExport(ctx, &dynamodb.DescribeExportInput{ExportArn: aws.String(exportARN)})
+		if err != nil {
+			return false, fmt.Errorf("failed to describe export %s: %w", exportARN, err)
+		}
+		description = output.ExportDescription
+		if description == nil {
+			return false, nil
+		}
+		switch description.ExportStatus {
+		case types.ExportStatusCompleted:
+			return true, nil
+		case types.ExportStatusFailed:
+			return false, fmt.Errorf("export %s failed: %s: %s", exportARN,
+				aws.ToString(description.FailureCode), aws.ToString(description.FailureMessage))
+		default:
+			return false, nil
+		}
+	})
+	return description, err
+}
+
+// pollUntil calls check every readinessPollInterval until it reports done or fails, or
+// until timeout elapses.
+func pollUntil(timeout time.Duration, what string, check func(ctx context.Context) (bool, error)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for {
+		done, err := check(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("timed out waiting for %s: %w", what, ctx.Err())
+			}
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		timer := time.NewTimer(readinessPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("timed out waiting for %s: %w", what, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package schema
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_CreateBackup_WaitsUntilAvailable(t *testing.T) {
+	withFastReadinessPolling(t)
+
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.CreateBackup": `{"BackupDetails":{"BackupArn":"arn:backup","BackupName":"nightly","BackupStatus":"CREATING"}}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.DescribeBackup", []stubbedResponse{
+		{body: `{"BackupDescription":{"BackupDetails":{"BackupArn":"arn:backup","BackupStatus":"CREATING"}}}`},
+		{body: `{"BackupDescription":{"BackupDetails":{"BackupArn":"arn:backup","BackupStatus":"AVAILABLE"}}}`},
+	})
+	mgr := newTestManager(t, httpClient)
+	require.NoError(t, mgr.registry.Register(&readinessModel{}))
+
+	details, err := mgr.CreateBackup(&readinessModel{}, "nightly", WithBackupWait(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, types.BackupStatusAvailable, details.BackupStatus)
+
+	reqs := httpClient.Requests()
+	create := findRequestByTarget(reqs, "DynamoDB_20120810.CreateBackup")
+	require.Equal(t, "readiness", create.Payload["TableName"])
+	require.Equal(t, "nightly", create.Payload["BackupName"])
+	require.Equal(t, 2, countRequestsByTarget(reqs, "DynamoDB_20120810.DescribeBackup"))
+}
+
+func TestManager_WaitForBackup_FailsWhenDeleted(t *testing.T) {
+	withFastReadinessPolling(t)
+
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.DescribeBackup": `{"BackupDescription":{"BackupDetails":{"BackupArn":"arn:backup","BackupStatus":"DELETED"}}}`,
+	})
+	mgr := newTestManager(t, httpClient)
+
+	_, err := mgr.WaitForBackup("arn:backup", time.Second)
+	require.ErrorContains(t, err, "backup arn:backup was deleted")
+}
+
+func TestManager_RestoreBackup(t *testing.T) {
+	withFastReadinessPolling(t)
+
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.RestoreTableFromBackup": `{"TableDescription":{"TableName":"restored","TableStatus":"CREATING"}}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.DescribeTable", []stubbedResponse{
+		{body: `{"Table":{"TableName":"restored","TableStatus":"CREATING"}}`},
+		{body: `{"Table":{"TableName":"restored","TableStatus":"ACTIVE"}}`},
+	})
+	mgr := newTestManager(t, httpClient)
+
+	table, err := mgr.RestoreBackup("arn:backup", "restored", WithBackupWait(time.Second))
+	require.NoError(t, err)
+	require.Equal(t, types.TableStatusActive, table.TableStatus)
+
+	restore := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.RestoreTableFromBackup")
+	require.Equal(t, "arn:backup", restore.Payload["BackupArn"])
+	require.Equal(t, "restored", restore.Payload["TargetTableName"])
+
+	_, err = mgr.RestoreBackup("", "restored")
+	require.ErrorContains(t, err, "are required")
+}
+
+func TestManager_ExportToS3(t *testing.T) {
+	withFastReadinessPolling(t)
+
+	t.Run("exports by table ARN and waits", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(map[string]string{
+			"DynamoDB_20120810.DescribeTable":            `{"Table":{"TableName":"readiness","TableArn":"arn:table/readiness","TableStatus":"ACTIVE"}}`,
+			"DynamoDB_20120810.ExportTableToPointInTime": `{"ExportDescription":{"ExportArn":"arn:export","ExportStatus":"IN_PROGRESS"}}`,
+		})
+		httpClient.SetResponseSequence("DynamoDB_20120810.DescribeExport", []stubbedResponse{
+			{body: `{"ExportDescription":{"ExportArn":"arn:export","ExportStatus":"IN_PROGRESS"}}`},
+			{body: `{"ExportDescription":{"ExportArn":"arn:export","ExportStatus":"COMPLETED","ItemCount":42}}`},
+		})
+		mgr := newTestManager(t, httpClient)
+		require.NoError(t, mgr.registry.Register(&readinessModel{}))
+
+		at := time.Unix(1767225600, 0)
+		export, err := mgr.ExportToS3(&readinessModel{}, "backups", "readiness/", WithExportTime(at), WithBackupWait(time.Second))
+		require.NoError(t, err)
+		require.Equal(t, int64(42), aws.ToInt64(export.ItemCount))
+
+		req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.ExportTableToPointInTime")
+		require.Equal(t, "arn:table/readiness", req.Payload["TableArn"])
+		require.Equal(t, "backups", req.Payload["S3Bucket"])
+		require.Equal(t, "readiness/", req.Payload["S3Prefix"])
+		require.Equal(t, "DYNAMODB_JSON", req.Payload["ExportFormat"])
+		require.Equal(t, float64(at.Unix()), req.Payload["ExportTime"])
+	})
+
+	t.Run("a failed export returns its failure", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(map[string]string{
+			"DynamoDB_20120810.DescribeExport": `{"ExportDescription":{"ExportArn":"arn:export","ExportStatus":"FAILED","FailureCode":"S3AccessDenied","FailureMessage":"denied"}}`,
+		})
+		mgr := newTestManager(t, httpClient)
+
+		_, err := mgr.WaitForExport("arn:export", time.Second)
+		require.ErrorContains(t, err, "export arn:export failed: S3AccessDenied: denied")
+	})
+
+	t.Run("waiting times out", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(map[string]string{
+			"DynamoDB_20120810.DescribeExport": `{"ExportDescription":{"ExportArn":"arn:export","ExportStatus":"IN_PROGRESS"}}`,
+		})
+		mgr := newTestManager(t, httpClient)
+
+		_, err := mgr.WaitForExport("arn:export", 20*time.Millisecond)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
@@ -170,3 +170,53 @@ func TestManager_UpdateTable_RejectsMultipleGSIChanges_COV6(t *testing.T) {
 	err := mgr.UpdateTable(&cov6ManagerTwoGSIsModel{})
 	require.ErrorContains(t, err, "multiple GSI changes detected")
 }
+
+func TestManager_SecurityOptions_CreateAndUpdate(t *testing.T) {
+	const policy = `{"Version":"2012-10-17","Statement":[]}`
+
+	t.Run("create sends deletion protection and policy", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(map[string]string{
+			"DynamoDB_20120810.DescribeTable": `{"Table":{"TableName":"tbl","TableStatus":"ACTIVE"}}`,
+		})
+		mgr := newTestManager(t, httpClient)
+		require.NoError(t, mgr.registry.Register(&cov6ManagerModel{}))
+
+		require.NoError(t, mgr.CreateTable(&cov6ManagerModel{}, WithDeletionProtection(true), WithResourcePolicy(policy)))
+
+		req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.CreateTable")
+		require.NotNil(t, req)
+		require.Equal(t, true, req.Payload["DeletionProtectionEnabled"])
+		require.Equal(t, policy, req.Payload["ResourcePolicy"])
+	})
+
+	t.Run("update with only a policy skips UpdateTable", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(map[string]string{
+			"DynamoDB_20120810.DescribeTable": `{"Table":{"TableName":"tbl","TableArn":"arn:aws:dynamodb:us-east-1:123:table/tbl","TableStatus":"ACTIVE"}}`,
+		})
+		mgr := newTestManager(t, httpClient)
+		require.NoError(t, mgr.registry.Register(&cov6ManagerModel{}))
+
+		require.NoError(t, mgr.UpdateTable(&cov6ManagerModel{}, WithResourcePolicy(policy)))
+
+		reqs := httpClient.Requests()
+		put := findRequestByTarget(reqs, "DynamoDB_20120810.PutResourcePolicy")
+		require.NotNil(t, put)
+		require.Equal(t, "arn:aws:dynamodb:us-east-1:123:table/tbl", put.Payload["ResourceArn"])
+		require.Equal(t, policy, put.Payload["Policy"])
+		require.Equal(t, 0, countRequestsByTarget(reqs, "DynamoDB_20120810.UpdateTable"))
+	})
+
+	t.Run("update enables deletion protection", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(map[string]string{
+			"DynamoDB_20120810.DescribeTable": `{"Table":{"TableName":"tbl","TableStatus":"ACTIVE"}}`,
+		})
+		mgr := newTestManager(t, httpClient)
+		require.NoError(t, mgr.registry.Register(&cov6ManagerModel{}))
+
+		require.NoError(t, mgr.UpdateTable(&cov6ManagerModel{}, WithDeletionProtection(true)))
+
+		req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateTable")
+		require.NotNil(t, req)
+		require.Equal(t, true, req.Payload["DeletionProtectionEnabled"])
+	})
+}
@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ImportSource names the S3 objects ImportTable loads into a new table.
+type ImportSource struct {
+	Bucket      string
+	KeyPrefix   string
+	BucketOwner string
+	// Format defaults to DYNAMODB_JSON, the format of DynamoDB exports to S3.
+	Format types.InputFormat
+	// Compression defaults to GZIP, as DynamoDB exports to S3 write.
+	Compression types.InputCompressionType
+}
+
+// ImportTable starts a native DynamoDB import of source into a new table built from the
+// model, as CreateTable would build it. The import runs asynchronously and can take
+// hours; poll DescribeImport with the returned ImportArn. The table must not exist yet
+// and imports cannot create local secondary indexes. TTL and WithPITR are not applied;
+// enable them with UpdateTable once the import completes.
+func (m *Manager) ImportTable(model any, source ImportSource, opts ...TableOption) (*types.ImportTableDescription, error) {
+	if source.Bucket == "" {
+		return nil, errors.New("import source bucket is required")
+	}
+	metadata, err := m.registry.GetMetadata(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	create, _ := m.createTableInput(metadata, opts)
+	if len(create.LocalSecondaryIndexes) > 0 {
+		return nil, fmt.Errorf("table %s has local secondary indexes, which imports cannot create", metadata.TableName)
+	}
+
+	input := &dynamodb.ImportTableInput{
+		InputFormat:          source.Format,
+		InputCompressionType: source.Compression,
+		S3BucketSource: &types.S3BucketSource{
+			S3Bucket: aws.String(source.Bucket),
+		},
+		TableCreationParameters: &types.TableCreationParameters{
+			TableName:              create.TableName,
+			KeySchema:              create.KeySchema,
+			AttributeDefinitions:   create.AttributeDefinitions,
+			BillingMode:            create.BillingMode,
+			GlobalSecondaryIndexes: create.GlobalSecondaryIndexes,
+			ProvisionedThroughput:  create.ProvisionedThroughput,
+			OnDemandThroughput:     create.OnDemandThroughput,
+			SSESpecification:       create.SSESpecification,
+		},
+	}
+	if input.InputFormat == "" {
+		input.InputFormat = types.InputFormatDynamodbJson
+	}
+	if input.InputCompressionType == "" {
+		input.InputCompressionType = types.InputCompressionTypeGzip
+	}
+	if source.KeyPrefix != "" {
+		input.S3BucketSource.S3KeyPrefix = aws.String(source.KeyPrefix)
+	}
+	if source.BucketOwner != "" {
+		input.S3BucketSource.S3BucketOwner = aws.String(source.BucketOwner)
+	}
+
+	client, err := m.session.Client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client for table import: %w", err)
+	}
+	output, err := client.ImportTable(context.Background(), input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to import table %s: %w", metadata.TableName, err)
+	}
+	return output.ImportTableDescription, nil
+}
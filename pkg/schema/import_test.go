@@ -0,0 +1,48 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_ImportTable(t *testing.T) {
+	t.Run("builds the table from the model", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(map[string]string{
+			"DynamoDB_20120810.ImportTable": `{"ImportTableDescription":{"ImportArn":"arn:import","ImportStatus":"IN_PROGRESS"}}`,
+		})
+		mgr := newTestManager(t, httpClient)
+		require.NoError(t, mgr.registry.Register(&cov6ManagerTwoGSIsModel{}))
+
+		desc, err := mgr.ImportTable(&cov6ManagerTwoGSIsModel{}, ImportSource{Bucket: "exports", KeyPrefix: "AWSDynamoDB/0001/data/"})
+		require.NoError(t, err)
+		require.Equal(t, "arn:import", *desc.ImportArn)
+
+		req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.ImportTable")
+		require.NotNil(t, req)
+		require.Equal(t, "DYNAMODB_JSON", req.Payload["InputFormat"])
+		require.Equal(t, "GZIP", req.Payload["InputCompressionType"])
+		require.Equal(t, map[string]any{"S3Bucket": "exports", "S3KeyPrefix": "AWSDynamoDB/0001/data/"}, req.Payload["S3BucketSource"])
+
+		params := req.Payload["TableCreationParameters"].(map[string]any)
+		require.Equal(t, "tbl", params["TableName"])
+		require.Equal(t, "PAY_PER_REQUEST", params["BillingMode"])
+		require.Len(t, params["GlobalSecondaryIndexes"], 2)
+	})
+
+	t.Run("rejects local secondary indexes", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(nil)
+		mgr := newTestManager(t, httpClient)
+		require.NoError(t, mgr.registry.Register(&Product{}))
+
+		_, err := mgr.ImportTable(&Product{}, ImportSource{Bucket: "exports"})
+		require.ErrorContains(t, err, "local secondary indexes")
+		require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.ImportTable"))
+	})
+
+	t.Run("requires a bucket", func(t *testing.T) {
+		mgr := newTestManager(t, newCapturingHTTPClient(nil))
+		_, err := mgr.ImportTable(&cov6ManagerModel{}, ImportSource{})
+		require.ErrorContains(t, err, "bucket is required")
+	})
+}
@@ -0,0 +1,196 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// defaultIndexTimeout bounds how long SyncIndexes waits for each index change.
+const defaultIndexTimeout = 30 * time.Minute
+
+type indexSyncOptions struct {
+	progress      func(IndexStatus)
+	timeout       time.Duration
+	deleteRemoved bool
+}
+
+// IndexSyncOption configures SyncIndexes
+type IndexSyncOption func(*indexSyncOptions)
+
+// WithIndexProgress calls progress with each index status observed while waiting
+func WithIndexProgress(progress func(IndexStatus)) IndexSyncOption {
+	return func(o *indexSyncOptions) {
+		o.progress = progress
+	}
+}
+
+// WithIndexTimeout sets how long to wait for each index to be created or deleted
+func WithIndexTimeout(timeout time.Duration) IndexSyncOption {
+	return func(o *indexSyncOptions) {
+		if timeout > 0 {
+			o.timeout = timeout
+		}
+	}
+}
+
+// WithIndexDeletion allows SyncIndexes to delete GSIs that are no longer declared on the
+// model. Deletion is off by default because it cannot be undone.
+func WithIndexDeletion(enabled bool) IndexSyncOption {
+	return func(o *indexSyncOptions) {
+		o.deleteRemoved = enabled
+	}
+}
+
+// DiffIndexes compares the GSIs declared on the model with those of its existing table
+// without changing anything. ToCreate lists the declared indexes the table lacks and
+// ToDelete the table's indexes the model no longer declares, each sorted by name.
+func (m *Manager) DiffIndexes(model any) (*GSIUpdatePlan, error) {
+	metadata, err := m.registry.GetMetadata(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	current, err := m.DescribeTable(model)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := m.calculateGSIUpdates(metadata, current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate GSI updates: %w", err)
+	}
+	sort.Slice(plan.ToCreate, func(i, j int) bool {
+		return aws.ToString(plan.ToCreate[i].IndexName) < aws.ToString(plan.ToCreate[j].IndexName)
+	})
+	sort.Strings(plan.ToDelete)
+	return plan, nil
+}
+
+// SyncIndexes brings the GSIs of the model's existing table in line with its index tags.
+// DynamoDB allows one GSI change per UpdateTable call, so each missing index is created
+// with its own call and waited on until it is ACTIVE and backfilled before the next
+// change starts. GSIs missing from the model are deleted only with WithIndexDeletion.
+// It returns the changes that were applied.
+func (m *Manager) SyncIndexes(model any, opts ...IndexSyncOption) (*GSIUpdatePlan, error) {
+	options := &indexSyncOptions{timeout: defaultIndexTimeout}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(options)
+		}
+	}
+
+	metadata, err := m.registry.GetMetadata(model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	plan, err := m.DiffIndexes(model)
+	if err != nil {
+		return nil, err
+	}
+	if !options.deleteRemoved {
+		plan.ToDelete = []string{}
+	}
+
+	client, err := m.session.Client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client for index sync: %w", err)
+	}
+
+	applied := &GSIUpdatePlan{
+		ToCreate: []types.GlobalSecondaryIndex{},
+		ToDelete: []string{},
+	}
+
+	for _, gsi := range plan.ToCreate {
+		name := aws.ToString(gsi.IndexName)
+		_, err := client.UpdateTable(context.Background(), &dynamodb.UpdateTableInput{
+			TableName:            aws.String(metadata.TableName),
+			AttributeDefinitions: m.indexAttributeDefinitions(metadata, gsi),
+			GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{
+				{Create: createIndexAction(gsi)},
+			},
+		})
+		if err != nil {
+			return applied, fmt.Errorf("failed to create index %s on table %s: %w", name, metadata.TableName, err)
+		}
+		if err := m.WaitForIndex(model, name, options.timeout, options.progress); err != nil {
+			return applied, err
+		}
+		applied.ToCreate = append(applied.ToCreate, gsi)
+	}
+
+	for _, name := range plan.ToDelete {
+		_, err := client.UpdateTable(context.Background(), &dynamodb.UpdateTableInput{
+			TableName: aws.String(metadata.TableName),
+			GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{
+				{Delete: &types.DeleteGlobalSecondaryIndexAction{IndexName: aws.String(name)}},
+			},
+		})
+		if err != nil {
+			return applied, fmt.Errorf("failed to delete index %s on table %s: %w", name, metadata.TableName, err)
+		}
+		if err := waitForIndexDeleted(client, metadata.TableName, name, options); err != nil {
+			return applied, err
+		}
+		applied.ToDelete = append(applied.ToDelete, name)
+	}
+
+	return applied, nil
+}
+
+// waitForIndexDeleted polls until the named index no longer appears on the table.
+func waitForIndexDeleted(client *dynamodb.Client, tableName, indexName string, options *indexSyncOptions) error {
+	ctx, cancel := context.WithTimeout(context.Background(), options.timeout)
+	defer cancel()
+
+	return pollTable(ctx, client, tableName, func(table *types.TableDescription) bool {
+		for _, status := range indexStatuses(table) {
+			if status.Name == indexName {
+				if options.progress != nil {
+					options.progress(status)
+				}
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func createIndexAction(gsi types.GlobalSecondaryIndex) *types.CreateGlobalSecondaryIndexAction {
+	return &types.CreateGlobalSecondaryIndexAction{
+		IndexName:             gsi.IndexName,
+		KeySchema:             gsi.KeySchema,
+		Projection:            gsi.Projection,
+		ProvisionedThroughput: gsi.ProvisionedThroughput,
+		OnDemandThroughput:    gsi.OnDemandThroughput,
+	}
+}
+
+// indexAttributeDefinitions returns the attribute definitions UpdateTable needs for the
+// key attributes of a new index.
+func (m *Manager) indexAttributeDefinitions(metadata *model.Metadata, gsi types.GlobalSecondaryIndex) []types.AttributeDefinition {
+	keys := make(map[string]bool, len(gsi.KeySchema))
+	for _, element := range gsi.KeySchema {
+		keys[aws.ToString(element.AttributeName)] = true
+	}
+
+	var definitions []types.AttributeDefinition
+	for _, definition := range m.buildAttributeDefinitions(metadata) {
+		if keys[aws.ToString(definition.AttributeName)] {
+			definitions = append(definitions, definition)
+		}
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return aws.ToString(definitions[i].AttributeName) < aws.ToString(definitions[j].AttributeName)
+	})
+	return definitions
+}
@@ -0,0 +1,96 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+type syncIndexModel struct {
+	ID         string `dynamorm:"pk"`
+	Status     string `dynamorm:"index:status-index,pk"`
+	CustomerID string `dynamorm:"index:customer-index,pk"`
+	CreatedAt  int64  `dynamorm:"index:customer-index,sk"`
+}
+
+func (syncIndexModel) TableName() string { return "sync" }
+
+const syncTableWithStatusIndex = `{"Table":{"TableName":"sync","TableStatus":"ACTIVE","BillingModeSummary":{"BillingMode":"PAY_PER_REQUEST"},` +
+	`"GlobalSecondaryIndexes":[{"IndexName":"status-index","IndexStatus":"ACTIVE"},{"IndexName":"legacy-index","IndexStatus":"ACTIVE"}]}}`
+
+func TestManager_SyncIndexes_CreatesMissingIndexAndWaits(t *testing.T) {
+	withFastReadinessPolling(t)
+
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.DescribeTable", []stubbedResponse{
+		{body: syncTableWithStatusIndex},
+		{body: `{"Table":{"TableName":"sync","TableStatus":"UPDATING","GlobalSecondaryIndexes":[{"IndexName":"customer-index","IndexStatus":"CREATING","Backfilling":true}]}}`},
+		{body: `{"Table":{"TableName":"sync","TableStatus":"ACTIVE","GlobalSecondaryIndexes":[{"IndexName":"customer-index","IndexStatus":"ACTIVE"}]}}`},
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.UpdateTable", []stubbedResponse{{body: `{}`}})
+
+	mgr := newTestManager(t, httpClient)
+	require.NoError(t, mgr.registry.Register(&syncIndexModel{}))
+
+	var seen []IndexStatus
+	plan, err := mgr.SyncIndexes(&syncIndexModel{}, WithIndexTimeout(time.Second), WithIndexProgress(func(s IndexStatus) {
+		seen = append(seen, s)
+	}))
+	require.NoError(t, err)
+	require.Len(t, plan.ToCreate, 1)
+	require.Equal(t, "customer-index", aws.ToString(plan.ToCreate[0].IndexName))
+	require.Empty(t, plan.ToDelete, "legacy-index is kept without WithIndexDeletion")
+	require.Len(t, seen, 2)
+	require.Equal(t, types.IndexStatusActive, seen[1].Status)
+
+	reqs := httpClient.Requests()
+	require.Equal(t, 1, countRequestsByTarget(reqs, "DynamoDB_20120810.UpdateTable"))
+	update := findRequestByTarget(reqs, "DynamoDB_20120810.UpdateTable")
+	require.NotNil(t, update)
+	require.Equal(t, []any{
+		map[string]any{"AttributeName": "createdAt", "AttributeType": "N"},
+		map[string]any{"AttributeName": "customerID", "AttributeType": "S"},
+	}, update.Payload["AttributeDefinitions"])
+	updates := update.Payload["GlobalSecondaryIndexUpdates"].([]any)
+	require.Contains(t, updates[0].(map[string]any), "Create")
+}
+
+func TestManager_SyncIndexes_DeletesRemovedIndexWhenEnabled(t *testing.T) {
+	withFastReadinessPolling(t)
+
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.DescribeTable", []stubbedResponse{
+		{body: syncTableWithStatusIndex},
+		{body: `{"Table":{"TableName":"sync","TableStatus":"ACTIVE","GlobalSecondaryIndexes":[{"IndexName":"customer-index","IndexStatus":"ACTIVE"}]}}`},
+		{body: `{"Table":{"TableName":"sync","TableStatus":"UPDATING","GlobalSecondaryIndexes":[{"IndexName":"legacy-index","IndexStatus":"DELETING"}]}}`},
+		{body: `{"Table":{"TableName":"sync","TableStatus":"ACTIVE","GlobalSecondaryIndexes":[{"IndexName":"status-index","IndexStatus":"ACTIVE"}]}}`},
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.UpdateTable", []stubbedResponse{{body: `{}`}})
+
+	mgr := newTestManager(t, httpClient)
+	require.NoError(t, mgr.registry.Register(&syncIndexModel{}))
+
+	plan, err := mgr.SyncIndexes(&syncIndexModel{}, WithIndexTimeout(time.Second), WithIndexDeletion(true))
+	require.NoError(t, err)
+	require.Len(t, plan.ToCreate, 1)
+	require.Equal(t, []string{"legacy-index"}, plan.ToDelete)
+	require.Equal(t, 2, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateTable"))
+}
+
+func TestManager_DiffIndexes_ReportsWithoutUpdating(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.DescribeTable", []stubbedResponse{{body: syncTableWithStatusIndex}})
+
+	mgr := newTestManager(t, httpClient)
+	require.NoError(t, mgr.registry.Register(&syncIndexModel{}))
+
+	plan, err := mgr.DiffIndexes(&syncIndexModel{})
+	require.NoError(t, err)
+	require.Len(t, plan.ToCreate, 1)
+	require.Equal(t, "customer-index", aws.ToString(plan.ToCreate[0].IndexName))
+	require.Equal(t, []string{"legacy-index"}, plan.ToDelete)
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateTable"))
+}
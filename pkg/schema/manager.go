@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -54,6 +55,37 @@ func WithThroughput(rcu, wcu int64) TableOption {
 	}
 }
 
+// WithGSIThroughput sets provisioned throughput for a single global secondary index.
+// Indexes without an explicit setting inherit the table's provisioned throughput.
+func WithGSIThroughput(indexName string, rcu, wcu int64) TableOption {
+	return func(input *dynamodb.CreateTableInput) {
+		for i := range input.GlobalSecondaryIndexes {
+			if aws.ToString(input.GlobalSecondaryIndexes[i].IndexName) == indexName {
+				input.GlobalSecondaryIndexes[i].ProvisionedThroughput = &types.ProvisionedThroughput{
+					ReadCapacityUnits:  aws.Int64(rcu),
+					WriteCapacityUnits: aws.Int64(wcu),
+				}
+			}
+		}
+	}
+}
+
+// WithOnDemandThroughput caps the request units an on-demand table (and its GSIs)
+// may consume. Zero leaves the corresponding dimension uncapped.
+func WithOnDemandThroughput(maxReadUnits, maxWriteUnits int64) TableOption {
+	return func(input *dynamodb.CreateTableInput) {
+		input.BillingMode = types.BillingModePayPerRequest
+		input.ProvisionedThroughput = nil
+		input.OnDemandThroughput = &types.OnDemandThroughput{}
+		if maxReadUnits > 0 {
+			input.OnDemandThroughput.MaxReadRequestUnits = aws.Int64(maxReadUnits)
+		}
+		if maxWriteUnits > 0 {
+			input.OnDemandThroughput.MaxWriteRequestUnits = aws.Int64(maxWriteUnits)
+		}
+	}
+}
+
 // WithStreamSpecification enables DynamoDB streams
 func WithStreamSpecification(spec types.StreamSpecification) TableOption {
 	return func(input *dynamodb.CreateTableInput) {
@@ -68,13 +100,108 @@ func WithSSESpecification(spec types.SSESpecification) TableOption {
 	}
 }
 
-// CreateTable creates a DynamoDB table based on the model struct
+// WithDeletionProtection enables or disables deletion protection on the table
+func WithDeletionProtection(enabled bool) TableOption {
+	return func(input *dynamodb.CreateTableInput) {
+		input.DeletionProtectionEnabled = aws.Bool(enabled)
+	}
+}
+
+// WithResourcePolicy attaches a resource-based policy (a JSON policy document) to the
+// table. On UpdateTable the policy replaces the table's current policy.
+func WithResourcePolicy(policy string) TableOption {
+	return func(input *dynamodb.CreateTableInput) {
+		input.ResourcePolicy = aws.String(policy)
+	}
+}
+
+// tableSettings holds options that DynamoDB applies with separate calls rather than
+// through CreateTableInput. They are recorded against the input the options were
+// applied to.
+type tableSettings struct {
+	skipTTL bool
+	pitr    bool
+}
+
+var pendingTableSettings sync.Map
+
+func settingsFor(input *dynamodb.CreateTableInput) *tableSettings {
+	settings, _ := pendingTableSettings.LoadOrStore(input, &tableSettings{})
+	return settings.(*tableSettings)
+}
+
+func takeSettings(input *dynamodb.CreateTableInput) tableSettings {
+	settings, ok := pendingTableSettings.LoadAndDelete(input)
+	if !ok {
+		return tableSettings{}
+	}
+	return *settings.(*tableSettings)
+}
+
+// WithoutTTL stops CreateTable from enabling Time to Live on the model's ttl attribute
+func WithoutTTL() TableOption {
+	return func(input *dynamodb.CreateTableInput) {
+		settingsFor(input).skipTTL = true
+	}
+}
+
+// WithPITR enables point-in-time recovery (continuous backups) on the table
+func WithPITR() TableOption {
+	return func(input *dynamodb.CreateTableInput) {
+		settingsFor(input).pitr = true
+	}
+}
+
+// CreateTable creates a DynamoDB table based on the model struct. When the model has a
+// ttl field, Time to Live is enabled on that attribute once the table is active, unless
+// WithoutTTL is given. WithPITR enables point-in-time recovery at the same point.
 func (m *Manager) CreateTable(model any, opts ...TableOption) error {
 	metadata, err := m.registry.GetMetadata(model)
 	if err != nil {
 		return fmt.Errorf("failed to get model metadata: %w", err)
 	}
+	input, settings := m.createTableInput(metadata, opts)
+
+	// Create table
+	ctx := context.Background()
+	client, err := m.session.Client()
+	if err != nil {
+		return fmt.Errorf("failed to get client for table creation: %w", err)
+	}
 
+	_, err = client.CreateTable(ctx, input)
+	if err != nil {
+		// Check if table already exists
+		var existsErr *types.ResourceInUseException
+		if errors.As(err, &existsErr) {
+			// Table already exists, which is fine
+			return nil
+		}
+		return fmt.Errorf("failed to create table %s: %w", metadata.TableName, err)
+	}
+
+	// Wait for table to be active
+	waiter := dynamodb.NewTableExistsWaiter(client)
+	if err := waiter.Wait(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(metadata.TableName),
+	}, 5*time.Minute); err != nil {
+		return err
+	}
+
+	if metadata.TTLField != nil && !settings.skipTTL {
+		if err := enableTTL(ctx, client, metadata.TableName, metadata.TTLField.DBName); err != nil {
+			return err
+		}
+	}
+	if settings.pitr {
+		return enablePITR(ctx, client, metadata.TableName)
+	}
+	return nil
+}
+
+// createTableInput builds the CreateTable request for metadata with opts applied, and
+// returns the settings that only apply once the table is active.
+func (m *Manager) createTableInput(metadata *model.Metadata, opts []TableOption) (*dynamodb.CreateTableInput, tableSettings) {
 	input := &dynamodb.CreateTableInput{
 		TableName:   aws.String(metadata.TableName),
 		BillingMode: types.BillingModePayPerRequest, // Default to on-demand
@@ -99,30 +226,59 @@ func (m *Manager) CreateTable(model any, opts ...TableOption) error {
 	for _, opt := range opts {
 		opt(input)
 	}
+	normalizeIndexThroughput(input)
+	return input, takeSettings(input)
+}
 
-	// Create table
-	ctx := context.Background()
-	client, err := m.session.Client()
+// enablePITR turns on point-in-time recovery for an active table.
+func enablePITR(ctx context.Context, client *dynamodb.Client, tableName string) error {
+	_, err := client.UpdateContinuousBackups(ctx, &dynamodb.UpdateContinuousBackupsInput{
+		TableName: aws.String(tableName),
+		PointInTimeRecoverySpecification: &types.PointInTimeRecoverySpecification{
+			PointInTimeRecoveryEnabled: aws.Bool(true),
+		},
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get client for table creation: %w", err)
+		return fmt.Errorf("failed to enable point-in-time recovery for table %s: %w", tableName, err)
 	}
+	return nil
+}
 
-	_, err = client.CreateTable(ctx, input)
+// enableTTL turns on Time to Live for attribute, which DynamoDB only allows on an
+// active table.
+func enableTTL(ctx context.Context, client *dynamodb.Client, tableName, attribute string) error {
+	_, err := client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(tableName),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(attribute),
+			Enabled:       aws.Bool(true),
+		},
+	})
 	if err != nil {
-		// Check if table already exists
-		var existsErr *types.ResourceInUseException
-		if errors.As(err, &existsErr) {
-			// Table already exists, which is fine
-			return nil
-		}
-		return fmt.Errorf("failed to create table %s: %w", metadata.TableName, err)
+		return fmt.Errorf("failed to enable TTL on %s for table %s: %w", attribute, tableName, err)
 	}
+	return nil
+}
 
-	// Wait for table to be active
-	waiter := dynamodb.NewTableExistsWaiter(client)
-	return waiter.Wait(ctx, &dynamodb.DescribeTableInput{
-		TableName: aws.String(metadata.TableName),
-	}, 5*time.Minute)
+// normalizeIndexThroughput makes GSI capacity settings agree with the table's billing
+// mode: provisioned tables require throughput on every GSI, on-demand tables reject it
+// and instead inherit any on-demand maximums.
+func normalizeIndexThroughput(input *dynamodb.CreateTableInput) {
+	for i := range input.GlobalSecondaryIndexes {
+		gsi := &input.GlobalSecondaryIndexes[i]
+		if input.BillingMode != types.BillingModeProvisioned {
+			gsi.ProvisionedThroughput = nil
+			if gsi.OnDemandThroughput == nil && input.OnDemandThroughput != nil {
+				throughput := *input.OnDemandThroughput
+				gsi.OnDemandThroughput = &throughput
+			}
+			continue
+		}
+		if gsi.ProvisionedThroughput == nil && input.ProvisionedThroughput != nil {
+			throughput := *input.ProvisionedThroughput
+			gsi.ProvisionedThroughput = &throughput
+		}
+	}
 }
 
 // buildKeySchema builds the primary key schema
@@ -158,10 +314,10 @@ func (m *Manager) buildAttributeDefinitions(metadata *model.Metadata) []types.At
 	// Index attributes
 	for _, index := range metadata.Indexes {
 		if index.PartitionKey != nil {
-			attrs[index.PartitionKey.DBName] = m.getAttributeType(index.PartitionKey.Type.Kind())
+			attrs[index.PartitionKey.DBName] = m.indexKeyAttributeType(index.PartitionKey)
 		}
 		if index.SortKey != nil {
-			attrs[index.SortKey.DBName] = m.getAttributeType(index.SortKey.Type.Kind())
+			attrs[index.SortKey.DBName] = m.indexKeyAttributeType(index.SortKey)
 		}
 	}
 
@@ -177,6 +333,15 @@ func (m *Manager) buildAttributeDefinitions(metadata *model.Metadata) []types.At
 	return definitions
 }
 
+// indexKeyAttributeType returns the attribute type of an index key field, which is a
+// string for enum fields stored as their names.
+func (m *Manager) indexKeyAttributeType(field *model.FieldMetadata) types.ScalarAttributeType {
+	if field.IsEnum() {
+		return types.ScalarAttributeTypeS
+	}
+	return m.getAttributeType(field.Type.Kind())
+}
+
 // getAttributeType converts Go reflect.Kind to DynamoDB attribute type
 func (m *Manager) getAttributeType(kind reflect.Kind) types.ScalarAttributeType {
 	switch kind {
@@ -370,10 +535,12 @@ func (m *Manager) UpdateTable(model any, opts ...TableOption) error {
 	}
 
 	createInput := buildCreateTableInput(opts)
+	settings := takeSettings(createInput)
 
 	applyBillingModeUpdate(input, createInput, current)
 	applyStreamUpdate(input, createInput)
 	applySSEUpdate(input, createInput)
+	applyDeletionProtectionUpdate(input, createInput, current)
 
 	if err = m.applyGSIUpdates(input, metadata, current); err != nil {
 		return err
@@ -385,6 +552,28 @@ func (m *Manager) UpdateTable(model any, opts ...TableOption) error {
 		return fmt.Errorf("failed to get client for table update: %w", err)
 	}
 
+	if settings.pitr {
+		if err := enablePITR(ctx, client, metadata.TableName); err != nil {
+			return err
+		}
+		if createInput.ResourcePolicy == nil && !hasTableUpdates(input) {
+			return nil
+		}
+	}
+
+	if createInput.ResourcePolicy != nil {
+		_, err = client.PutResourcePolicy(ctx, &dynamodb.PutResourcePolicyInput{
+			ResourceArn: current.TableArn,
+			Policy:      createInput.ResourcePolicy,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to put resource policy on table %s: %w", metadata.TableName, err)
+		}
+		if !hasTableUpdates(input) {
+			return nil
+		}
+	}
+
 	_, err = client.UpdateTable(ctx, input)
 	if err != nil {
 		return fmt.Errorf("failed to update table %s: %w", metadata.TableName, err)
@@ -394,6 +583,16 @@ func (m *Manager) UpdateTable(model any, opts ...TableOption) error {
 	return m.waitForTableActive(metadata.TableName)
 }
 
+// hasTableUpdates reports whether input changes anything besides naming the table
+func hasTableUpdates(input *dynamodb.UpdateTableInput) bool {
+	return input.BillingMode != "" ||
+		input.ProvisionedThroughput != nil ||
+		input.StreamSpecification != nil ||
+		input.SSESpecification != nil ||
+		input.DeletionProtectionEnabled != nil ||
+		len(input.GlobalSecondaryIndexUpdates) > 0
+}
+
 func buildCreateTableInput(opts []TableOption) *dynamodb.CreateTableInput {
 	createInput := &dynamodb.CreateTableInput{}
 	for _, opt := range opts {
@@ -429,6 +628,16 @@ func applySSEUpdate(input *dynamodb.UpdateTableInput, createInput *dynamodb.Crea
 	}
 }
 
+func applyDeletionProtectionUpdate(input *dynamodb.UpdateTableInput, createInput *dynamodb.CreateTableInput, current *types.TableDescription) {
+	if createInput.DeletionProtectionEnabled == nil {
+		return
+	}
+	if aws.ToBool(createInput.DeletionProtectionEnabled) == aws.ToBool(current.DeletionProtectionEnabled) {
+		return
+	}
+	input.DeletionProtectionEnabled = createInput.DeletionProtectionEnabled
+}
+
 func (m *Manager) applyGSIUpdates(input *dynamodb.UpdateTableInput, metadata *model.Metadata, current *types.TableDescription) error {
 	gsiUpdates, err := m.calculateGSIUpdates(metadata, current)
 	if err != nil {
@@ -442,22 +651,16 @@ func (m *Manager) applyGSIUpdates(input *dynamodb.UpdateTableInput, metadata *mo
 
 	if totalChanges > 1 {
 		return fmt.Errorf(
-			"multiple GSI changes detected (%d creates, %d deletes). DynamoDB allows only one GSI operation per UpdateTable call. Please use AutoMigrate for complex schema changes",
+			"multiple GSI changes detected (%d creates, %d deletes). DynamoDB allows only one GSI operation per UpdateTable call. Use SyncIndexes to apply them one at a time",
 			len(gsiUpdates.ToCreate),
 			len(gsiUpdates.ToDelete),
 		)
 	}
 
 	if len(gsiUpdates.ToCreate) == 1 {
+		input.AttributeDefinitions = m.indexAttributeDefinitions(metadata, gsiUpdates.ToCreate[0])
 		input.GlobalSecondaryIndexUpdates = []types.GlobalSecondaryIndexUpdate{
-			{
-				Create: &types.CreateGlobalSecondaryIndexAction{
-					IndexName:             gsiUpdates.ToCreate[0].IndexName,
-					KeySchema:             gsiUpdates.ToCreate[0].KeySchema,
-					Projection:            gsiUpdates.ToCreate[0].Projection,
-					ProvisionedThroughput: gsiUpdates.ToCreate[0].ProvisionedThroughput,
-				},
-			},
+			{Create: createIndexAction(gsiUpdates.ToCreate[0])},
 		}
 		return nil
 	}
@@ -37,6 +37,52 @@ func TestTableOptions_ApplyToCreateTableInput(t *testing.T) {
 		require.Equal(t, int64(4), aws.ToInt64(input.ProvisionedThroughput.WriteCapacityUnits))
 	})
 
+	t.Run("WithGSIThroughput targets a single index", func(t *testing.T) {
+		input := &dynamodb.CreateTableInput{
+			GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+				{IndexName: aws.String("a")},
+				{IndexName: aws.String("b")},
+			},
+		}
+
+		WithGSIThroughput("b", 7, 8)(input)
+		require.Nil(t, input.GlobalSecondaryIndexes[0].ProvisionedThroughput)
+		require.Equal(t, int64(7), aws.ToInt64(input.GlobalSecondaryIndexes[1].ProvisionedThroughput.ReadCapacityUnits))
+		require.Equal(t, int64(8), aws.ToInt64(input.GlobalSecondaryIndexes[1].ProvisionedThroughput.WriteCapacityUnits))
+	})
+
+	t.Run("WithOnDemandThroughput sets maximums", func(t *testing.T) {
+		input := &dynamodb.CreateTableInput{}
+
+		WithThroughput(1, 1)(input)
+		WithOnDemandThroughput(100, 0)(input)
+		require.Equal(t, types.BillingModePayPerRequest, input.BillingMode)
+		require.Nil(t, input.ProvisionedThroughput)
+		require.Equal(t, int64(100), aws.ToInt64(input.OnDemandThroughput.MaxReadRequestUnits))
+		require.Nil(t, input.OnDemandThroughput.MaxWriteRequestUnits)
+	})
+
+	t.Run("normalizeIndexThroughput matches GSIs to billing mode", func(t *testing.T) {
+		input := &dynamodb.CreateTableInput{
+			GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+				{IndexName: aws.String("a")},
+				{IndexName: aws.String("b")},
+			},
+		}
+		WithThroughput(3, 4)(input)
+		WithGSIThroughput("b", 7, 8)(input)
+		normalizeIndexThroughput(input)
+		require.Equal(t, int64(3), aws.ToInt64(input.GlobalSecondaryIndexes[0].ProvisionedThroughput.ReadCapacityUnits))
+		require.Equal(t, int64(7), aws.ToInt64(input.GlobalSecondaryIndexes[1].ProvisionedThroughput.ReadCapacityUnits))
+
+		WithOnDemandThroughput(50, 60)(input)
+		normalizeIndexThroughput(input)
+		for _, gsi := range input.GlobalSecondaryIndexes {
+			require.Nil(t, gsi.ProvisionedThroughput)
+			require.Equal(t, int64(60), aws.ToInt64(gsi.OnDemandThroughput.MaxWriteRequestUnits))
+		}
+	})
+
 	t.Run("WithStreamSpecification and WithSSESpecification", func(t *testing.T) {
 		input := &dynamodb.CreateTableInput{}
 
@@ -136,6 +182,23 @@ func TestUpdateTableHelpers(t *testing.T) {
 		require.NotNil(t, updateInput.StreamSpecification)
 		require.NotNil(t, updateInput.SSESpecification)
 	})
+
+	t.Run("applyDeletionProtectionUpdate only sends changes", func(t *testing.T) {
+		current := &types.TableDescription{DeletionProtectionEnabled: aws.Bool(true)}
+		createInput := &dynamodb.CreateTableInput{}
+		WithDeletionProtection(true)(createInput)
+
+		updateInput := &dynamodb.UpdateTableInput{}
+		applyDeletionProtectionUpdate(updateInput, createInput, current)
+		require.Nil(t, updateInput.DeletionProtectionEnabled)
+		require.False(t, hasTableUpdates(updateInput))
+
+		WithDeletionProtection(false)(createInput)
+		applyDeletionProtectionUpdate(updateInput, createInput, current)
+		require.False(t, aws.ToBool(updateInput.DeletionProtectionEnabled))
+		require.NotNil(t, updateInput.DeletionProtectionEnabled)
+		require.True(t, hasTableUpdates(updateInput))
+	})
 }
 
 func TestApplyGSIUpdates(t *testing.T) {
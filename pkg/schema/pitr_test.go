@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPITR_RecordsSetting(t *testing.T) {
+	input := &dynamodb.CreateTableInput{}
+	WithPITR()(input)
+	WithoutTTL()(input)
+
+	require.Equal(t, tableSettings{skipTTL: true, pitr: true}, takeSettings(input))
+	require.Equal(t, tableSettings{}, takeSettings(input), "settings are consumed once")
+}
+
+func TestManager_PITR_CreateAndUpdate(t *testing.T) {
+	t.Run("create enables PITR once the table is active", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(map[string]string{
+			"DynamoDB_20120810.DescribeTable": `{"Table":{"TableName":"tbl","TableStatus":"ACTIVE"}}`,
+		})
+		mgr := newTestManager(t, httpClient)
+		require.NoError(t, mgr.registry.Register(&cov6ManagerModel{}))
+
+		require.NoError(t, mgr.CreateTable(&cov6ManagerModel{}, WithPITR(), WithDeletionProtection(true)))
+
+		reqs := httpClient.Requests()
+		create := findRequestByTarget(reqs, "DynamoDB_20120810.CreateTable")
+		require.NotNil(t, create)
+		require.Equal(t, true, create.Payload["DeletionProtectionEnabled"])
+
+		backups := findRequestByTarget(reqs, "DynamoDB_20120810.UpdateContinuousBackups")
+		require.NotNil(t, backups)
+		require.Equal(t, "tbl", backups.Payload["TableName"])
+		require.Equal(t, map[string]any{"PointInTimeRecoveryEnabled": true}, backups.Payload["PointInTimeRecoverySpecification"])
+	})
+
+	t.Run("create without PITR skips continuous backups", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(map[string]string{
+			"DynamoDB_20120810.DescribeTable": `{"Table":{"TableName":"tbl","TableStatus":"ACTIVE"}}`,
+		})
+		mgr := newTestManager(t, httpClient)
+		require.NoError(t, mgr.registry.Register(&cov6ManagerModel{}))
+
+		require.NoError(t, mgr.CreateTable(&cov6ManagerModel{}))
+		require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateContinuousBackups"))
+	})
+
+	t.Run("update with only PITR skips UpdateTable", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(map[string]string{
+			"DynamoDB_20120810.DescribeTable": `{"Table":{"TableName":"tbl","TableStatus":"ACTIVE"}}`,
+		})
+		mgr := newTestManager(t, httpClient)
+		require.NoError(t, mgr.registry.Register(&cov6ManagerModel{}))
+
+		require.NoError(t, mgr.UpdateTable(&cov6ManagerModel{}, WithPITR()))
+
+		reqs := httpClient.Requests()
+		require.Equal(t, 1, countRequestsByTarget(reqs, "DynamoDB_20120810.UpdateContinuousBackups"))
+		require.Zero(t, countRequestsByTarget(reqs, "DynamoDB_20120810.UpdateTable"))
+	})
+
+	t.Run("PITR errors are wrapped", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(map[string]string{
+			"DynamoDB_20120810.DescribeTable": `{"Table":{"TableName":"tbl","TableStatus":"ACTIVE"}}`,
+		})
+		httpClient.SetResponseSequence("DynamoDB_20120810.UpdateContinuousBackups", []stubbedResponse{
+			stubbedAWSError("ContinuousBackupsUnavailableException", "not yet"),
+		})
+		mgr := newTestManager(t, httpClient)
+		require.NoError(t, mgr.registry.Register(&cov6ManagerModel{}))
+
+		require.ErrorContains(t, mgr.CreateTable(&cov6ManagerModel{}, WithPITR()), "failed to enable point-in-time recovery for table tbl")
+	})
+}
@@ -0,0 +1,149 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// readinessPollInterval is the delay between DescribeTable calls while waiting.
+var readinessPollInterval = 5 * time.Second
+
+// IndexStatus is a snapshot of a global secondary index's build progress
+type IndexStatus struct {
+	Name        string
+	Status      types.IndexStatus
+	ItemCount   int64
+	Backfilling bool
+}
+
+// Ready reports whether the index is ACTIVE and has finished backfilling
+func (s IndexStatus) Ready() bool {
+	return s.Status == types.IndexStatusActive && !s.Backfilling
+}
+
+// WaitForActive blocks until the model's table and all of its global secondary
+// indexes are ACTIVE, or until timeout elapses.
+func (m *Manager) WaitForActive(model any, timeout time.Duration) error {
+	tableName, client, err := m.readinessTarget(model)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return pollTable(ctx, client, tableName, func(table *types.TableDescription) bool {
+		if table.TableStatus != types.TableStatusActive {
+			return false
+		}
+		for _, status := range indexStatuses(table) {
+			if !status.Ready() {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// IndexProgress returns the current status of every global secondary index on the
+// model's table.
+func (m *Manager) IndexProgress(model any) ([]IndexStatus, error) {
+	table, err := m.DescribeTable(model)
+	if err != nil {
+		return nil, err
+	}
+	return indexStatuses(table), nil
+}
+
+// WaitForIndex blocks until the named global secondary index is ACTIVE and done
+// backfilling, or until timeout elapses. progress, when non-nil, is called with each
+// status observed while polling.
+func (m *Manager) WaitForIndex(model any, indexName string, timeout time.Duration, progress func(IndexStatus)) error {
+	tableName, client, err := m.readinessTarget(model)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var missing bool
+	err = pollTable(ctx, client, tableName, func(table *types.TableDescription) bool {
+		for _, status := range indexStatuses(table) {
+			if status.Name != indexName {
+				continue
+			}
+			if progress != nil {
+				progress(status)
+			}
+			return status.Ready()
+		}
+		missing = true
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if missing {
+		return fmt.Errorf("index %s not found on table %s", indexName, tableName)
+	}
+	return nil
+}
+
+func (m *Manager) readinessTarget(model any) (string, *dynamodb.Client, error) {
+	metadata, err := m.registry.GetMetadata(model)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get model metadata: %w", err)
+	}
+
+	client, err := m.session.Client()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get client for readiness check: %w", err)
+	}
+
+	return metadata.TableName, client, nil
+}
+
+// pollTable describes tableName until done returns true or ctx expires.
+func pollTable(ctx context.Context, client *dynamodb.Client, tableName string, done func(*types.TableDescription) bool) error {
+	for {
+		output, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+			TableName: aws.String(tableName),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("timed out waiting for table %s: %w", tableName, ctx.Err())
+			}
+			return fmt.Errorf("failed to describe table %s: %w", tableName, err)
+		}
+		if output.Table != nil && done(output.Table) {
+			return nil
+		}
+
+		timer := time.NewTimer(readinessPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("timed out waiting for table %s: %w", tableName, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func indexStatuses(table *types.TableDescription) []IndexStatus {
+	statuses := make([]IndexStatus, 0, len(table.GlobalSecondaryIndexes))
+	for _, gsi := range table.GlobalSecondaryIndexes {
+		statuses = append(statuses, IndexStatus{
+			Name:        aws.ToString(gsi.IndexName),
+			Status:      gsi.IndexStatus,
+			ItemCount:   aws.ToInt64(gsi.ItemCount),
+			Backfilling: aws.ToBool(gsi.Backfilling),
+		})
+	}
+	return statuses
+}
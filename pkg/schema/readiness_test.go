@@ -0,0 +1,96 @@
+package schema
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+type readinessModel struct {
+	ID     string `dynamorm:"pk"`
+	Status string `dynamorm:"index:status-index,pk"`
+}
+
+func (readinessModel) TableName() string { return "readiness" }
+
+func withFastReadinessPolling(t *testing.T) {
+	t.Helper()
+	prev := readinessPollInterval
+	readinessPollInterval = time.Millisecond
+	t.Cleanup(func() { readinessPollInterval = prev })
+}
+
+func TestManager_WaitForActive_PollsUntilTableAndIndexesActive(t *testing.T) {
+	withFastReadinessPolling(t)
+
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.DescribeTable", []stubbedResponse{
+		{body: `{"Table":{"TableName":"readiness","TableStatus":"CREATING"}}`},
+		{body: `{"Table":{"TableName":"readiness","TableStatus":"ACTIVE","GlobalSecondaryIndexes":[{"IndexName":"status-index","IndexStatus":"CREATING","Backfilling":true}]}}`},
+		{body: `{"Table":{"TableName":"readiness","TableStatus":"ACTIVE","GlobalSecondaryIndexes":[{"IndexName":"status-index","IndexStatus":"ACTIVE"}]}}`},
+	})
+
+	mgr := newTestManager(t, httpClient)
+	require.NoError(t, mgr.registry.Register(&readinessModel{}))
+
+	require.NoError(t, mgr.WaitForActive(&readinessModel{}, time.Second))
+	require.Equal(t, 3, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.DescribeTable"))
+}
+
+func TestManager_WaitForActive_TimesOut(t *testing.T) {
+	withFastReadinessPolling(t)
+
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.DescribeTable", []stubbedResponse{
+		{body: `{"Table":{"TableName":"readiness","TableStatus":"UPDATING"}}`},
+	})
+
+	mgr := newTestManager(t, httpClient)
+	require.NoError(t, mgr.registry.Register(&readinessModel{}))
+
+	err := mgr.WaitForActive(&readinessModel{}, 20*time.Millisecond)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "timed out waiting for table readiness")
+}
+
+func TestManager_WaitForIndex_ReportsBackfillProgress(t *testing.T) {
+	withFastReadinessPolling(t)
+
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.DescribeTable", []stubbedResponse{
+		{body: `{"Table":{"TableName":"readiness","TableStatus":"UPDATING","GlobalSecondaryIndexes":[{"IndexName":"status-index","IndexStatus":"CREATING","Backfilling":true,"ItemCount":10}]}}`},
+		{body: `{"Table":{"TableName":"readiness","TableStatus":"ACTIVE","GlobalSecondaryIndexes":[{"IndexName":"status-index","IndexStatus":"ACTIVE","ItemCount":25}]}}`},
+	})
+
+	mgr := newTestManager(t, httpClient)
+	require.NoError(t, mgr.registry.Register(&readinessModel{}))
+
+	var seen []IndexStatus
+	err := mgr.WaitForIndex(&readinessModel{}, "status-index", time.Second, func(s IndexStatus) {
+		seen = append(seen, s)
+	})
+	require.NoError(t, err)
+	require.Equal(t, []IndexStatus{
+		{Name: "status-index", Status: types.IndexStatusCreating, ItemCount: 10, Backfilling: true},
+		{Name: "status-index", Status: types.IndexStatusActive, ItemCount: 25},
+	}, seen)
+
+	progress, err := mgr.IndexProgress(&readinessModel{})
+	require.NoError(t, err)
+	require.Len(t, progress, 1)
+	require.True(t, progress[0].Ready())
+
+	err = mgr.WaitForIndex(&readinessModel{}, "missing-index", time.Second, nil)
+	require.ErrorContains(t, err, "index missing-index not found on table readiness")
+}
+
+func TestManager_Readiness_ClientErrors(t *testing.T) {
+	mgr := NewManager(nil, newTestManager(t, newCapturingHTTPClient(nil)).registry)
+	require.NoError(t, mgr.registry.Register(&readinessModel{}))
+
+	require.ErrorContains(t, mgr.WaitForActive(&readinessModel{}, time.Second), "failed to get client for readiness check")
+	require.ErrorContains(t, mgr.WaitForIndex(&readinessModel{}, "status-index", time.Second, nil), "failed to get client for readiness check")
+}
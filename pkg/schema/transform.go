@@ -185,6 +185,17 @@ func wrapModelTransform(
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal target item: %w", err)
 		}
+		if targetMetadata != nil && targetMetadata.EntityType != "" {
+			targetMap[targetMetadata.EntityAttribute] = &types.AttributeValueMemberS{Value: targetMetadata.EntityType}
+		}
+		if targetMetadata != nil {
+			if err := targetMetadata.ApplyKeyTemplates(reflect.Indirect(reflect.ValueOf(targetModel)), targetMap); err != nil {
+				return nil, fmt.Errorf("failed to build target keys: %w", err)
+			}
+			if err := targetMetadata.ApplyEnums(targetMap); err != nil {
+				return nil, err
+			}
+		}
 
 		return targetMap, nil
 	}
@@ -212,6 +223,13 @@ func buildSourceModel(
 		if !structField.CanSet() {
 			continue
 		}
+		if field.IsEnum() {
+			decoded, err := field.DecodeEnum(attrValue)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			attrValue = decoded
+		}
 
 		if err := converter.FromAttributeValue(attrValue, structField.Addr().Interface()); err != nil {
 			return reflect.Value{}, fmt.Errorf("failed to unmarshal field %s: %w", field.Name, err)
@@ -0,0 +1,95 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type ttlSessionModel struct {
+	ID        string `dynamorm:"pk"`
+	ExpiresAt int64  `dynamorm:"ttl,attr:expiresAt"`
+}
+
+func (ttlSessionModel) TableName() string { return "sessions" }
+
+const activeSessionsTable = `{"Table":{"TableName":"sessions","TableStatus":"ACTIVE"}}`
+
+func TestManager_CreateTable_EnablesTTL(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.DescribeTable": activeSessionsTable,
+	})
+	mgr := newTestManager(t, httpClient)
+	require.NoError(t, mgr.registry.Register(&ttlSessionModel{}))
+
+	require.NoError(t, mgr.CreateTable(&ttlSessionModel{}))
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateTimeToLive")
+	require.NotNil(t, req)
+	require.Equal(t, "sessions", req.Payload["TableName"])
+	require.Equal(t, map[string]any{"AttributeName": "expiresAt", "Enabled": true}, req.Payload["TimeToLiveSpecification"])
+}
+
+func TestManager_CreateTable_TTLSkipsAndErrors(t *testing.T) {
+	t.Run("WithoutTTL skips enablement", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(map[string]string{
+			"DynamoDB_20120810.DescribeTable": activeSessionsTable,
+		})
+		mgr := newTestManager(t, httpClient)
+		require.NoError(t, mgr.registry.Register(&ttlSessionModel{}))
+
+		require.NoError(t, mgr.CreateTable(&ttlSessionModel{}, WithoutTTL()))
+		require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateTimeToLive"))
+	})
+
+	t.Run("models without ttl skip enablement", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(map[string]string{
+			"DynamoDB_20120810.DescribeTable": `{"Table":{"TableName":"tbl","TableStatus":"ACTIVE"}}`,
+		})
+		mgr := newTestManager(t, httpClient)
+		require.NoError(t, mgr.registry.Register(&cov6ManagerModel{}))
+
+		require.NoError(t, mgr.CreateTable(&cov6ManagerModel{}))
+		require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateTimeToLive"))
+	})
+
+	t.Run("existing tables are left alone", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(nil)
+		httpClient.SetResponseSequence("DynamoDB_20120810.CreateTable", []stubbedResponse{
+			stubbedAWSError("ResourceInUseException", "exists"),
+		})
+		mgr := newTestManager(t, httpClient)
+		require.NoError(t, mgr.registry.Register(&ttlSessionModel{}))
+
+		require.NoError(t, mgr.CreateTable(&ttlSessionModel{}))
+		require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateTimeToLive"))
+	})
+
+	t.Run("enablement errors are wrapped", func(t *testing.T) {
+		httpClient := newCapturingHTTPClient(map[string]string{
+			"DynamoDB_20120810.DescribeTable": activeSessionsTable,
+		})
+		httpClient.SetResponseSequence("DynamoDB_20120810.UpdateTimeToLive", []stubbedResponse{
+			stubbedAWSError("ValidationException", "boom"),
+		})
+		mgr := newTestManager(t, httpClient)
+		require.NoError(t, mgr.registry.Register(&ttlSessionModel{}))
+
+		require.ErrorContains(t, mgr.CreateTable(&ttlSessionModel{}), "failed to enable TTL on expiresAt for table sessions")
+	})
+}
+
+func TestManager_AutoMigrate_WithoutTTLEnablement(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.DescribeTable", []stubbedResponse{
+		stubbedAWSError("ResourceNotFoundException", "missing"),
+		{body: activeSessionsTable},
+	})
+	mgr := newTestManager(t, httpClient)
+
+	require.NoError(t, mgr.AutoMigrateWithOptions(&ttlSessionModel{}, WithoutTTLEnablement()))
+
+	reqs := httpClient.Requests()
+	require.Equal(t, 1, countRequestsByTarget(reqs, "DynamoDB_20120810.CreateTable"))
+	require.Zero(t, countRequestsByTarget(reqs, "DynamoDB_20120810.UpdateTimeToLive"))
+}
@@ -0,0 +1,168 @@
+package session
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultMaxDecryptedKeys is the DataKeyCachePolicy.MaxDecryptedKeys used when it is zero.
+const defaultMaxDecryptedKeys = 1000
+
+// DataKeyCachePolicy bounds the reuse of KMS data keys by dynamorm:"encrypted" fields.
+// Without it every encrypted attribute value costs a GenerateDataKey call to write and a
+// Decrypt call to read.
+type DataKeyCachePolicy struct {
+	// TTL is how long a data key is reused after KMS returns it. Zero disables caching.
+	TTL time.Duration
+	// MaxUses caps the attribute values one data key encrypts before a new one is
+	// generated. Zero means no cap within TTL.
+	MaxUses int
+	// MaxDecryptedKeys caps the decrypted data keys kept for reads, 1000 by default.
+	MaxDecryptedKeys int
+}
+
+// DataKey is a KMS data key: its plaintext and the ciphertext KMS encrypted it to.
+type DataKey struct {
+	Plaintext      []byte
+	CiphertextBlob []byte
+}
+
+// DataKeyCache holds KMS data keys under a DataKeyCachePolicy. A session has one when
+// Config.DataKeyCache sets a TTL; it is safe for concurrent use. Keys are held per set of
+// credentials, see ForCredentials.
+type DataKeyCache struct {
+	keys        *dataKeys
+	credentials string
+}
+
+// dataKeys is the store shared by a DataKeyCache and its ForCredentials views.
+type dataKeys struct {
+	now     func() time.Time
+	encrypt map[string]*cachedDataKey
+	decrypt map[string]*cachedDataKey
+	policy  DataKeyCachePolicy
+	mu      sync.Mutex
+}
+
+type cachedDataKey struct {
+	expires time.Time
+	key     DataKey
+	uses    int
+}
+
+// NewDataKeyCache returns a cache with policy, or nil when policy.TTL is not positive.
+// now defaults to time.Now.
+func NewDataKeyCache(policy DataKeyCachePolicy, now func() time.Time) *DataKeyCache {
+	if policy.TTL <= 0 {
+		return nil
+	}
+	if policy.MaxDecryptedKeys <= 0 {
+		policy.MaxDecryptedKeys = defaultMaxDecryptedKeys
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &DataKeyCache{keys: &dataKeys{
+		policy:  policy,
+		now:     now,
+		encrypt: make(map[string]*cachedDataKey),
+		decrypt: make(map[string]*cachedDataKey),
+	}}
+}
+
+// ForCredentials returns a view of the cache holding the keys KMS returned to the
+// credentials with accessKeyID, so keys KMS authorized for one set of credentials are
+// not reused by another. Views share the cache's policy and MaxDecryptedKeys.
+func (c *DataKeyCache) ForCredentials(accessKeyID string) *DataKeyCache {
+	if c == nil {
+		return nil
+	}
+	return &DataKeyCache{keys: c.keys, credentials: accessKeyID}
+}
+
+// EncryptionKey returns the data key to encrypt one more value under keyARN with, and
+// counts the use. ok is false when there is none or it is spent.
+func (c *DataKeyCache) EncryptionKey(keyARN string) (key DataKey, ok bool) {
+	if c == nil {
+		return DataKey{}, false
+	}
+	c.keys.mu.Lock()
+	defer c.keys.mu.Unlock()
+	id := c.encryptKey(keyARN)
+	entry := c.keys.encrypt[id]
+	if entry == nil || !c.keys.now().Before(entry.expires) || (c.keys.policy.MaxUses > 0 && entry.uses >= c.keys.policy.MaxUses) {
+		delete(c.keys.encrypt, id)
+		return DataKey{}, false
+	}
+	entry.uses++
+	return entry.key, true
+}
+
+// PutEncryptionKey caches key, just generated under keyARN and used once.
+func (c *DataKeyCache) PutEncryptionKey(keyARN string, key DataKey) {
+	if c == nil {
+		return
+	}
+	c.keys.mu.Lock()
+	defer c.keys.mu.Unlock()
+	c.keys.encrypt[c.encryptKey(keyARN)] = &cachedDataKey{key: copyDataKey(key), expires: c.keys.now().Add(c.keys.policy.TTL), uses: 1}
+}
+
+// DecryptedKey returns the plaintext of the data key ciphertext decrypted under keyARN.
+func (c *DataKeyCache) DecryptedKey(keyARN string, ciphertext []byte) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.keys.mu.Lock()
+	defer c.keys.mu.Unlock()
+	id := c.decryptKey(keyARN, ciphertext)
+	entry := c.keys.decrypt[id]
+	if entry == nil || !c.keys.now().Before(entry.expires) {
+		delete(c.keys.decrypt, id)
+		return nil, false
+	}
+	return entry.key.Plaintext, true
+}
+
+// PutDecryptedKey caches plaintext, the data key ciphertext decrypted under keyARN.
+// When the cache is full, expired keys are dropped, or else the one expiring first.
+func (c *DataKeyCache) PutDecryptedKey(keyARN string, ciphertext, plaintext []byte) {
+	if c == nil {
+		return
+	}
+	c.keys.mu.Lock()
+	defer c.keys.mu.Unlock()
+	now := c.keys.now()
+	if len(c.keys.decrypt) >= c.keys.policy.MaxDecryptedKeys {
+		var oldest string
+		for id, entry := range c.keys.decrypt {
+			if !now.Before(entry.expires) {
+				delete(c.keys.decrypt, id)
+			} else if oldest == "" || entry.expires.Before(c.keys.decrypt[oldest].expires) {
+				oldest = id
+			}
+		}
+		if len(c.keys.decrypt) >= c.keys.policy.MaxDecryptedKeys {
+			delete(c.keys.decrypt, oldest)
+		}
+	}
+	c.keys.decrypt[c.decryptKey(keyARN, ciphertext)] = &cachedDataKey{
+		key:     copyDataKey(DataKey{Plaintext: plaintext, CiphertextBlob: ciphertext}),
+		expires: now.Add(c.keys.policy.TTL),
+	}
+}
+
+func (c *DataKeyCache) encryptKey(keyARN string) string {
+	return c.credentials + "\x00" + keyARN
+}
+
+func (c *DataKeyCache) decryptKey(keyARN string, ciphertext []byte) string {
+	return c.credentials + "\x00" + keyARN + "\x00" + string(ciphertext)
+}
+
+func copyDataKey(key DataKey) DataKey {
+	return DataKey{
+		Plaintext:      append([]byte(nil), key.Plaintext...),
+		CiphertextBlob: append([]byte(nil), key.CiphertextBlob...),
+	}
+}
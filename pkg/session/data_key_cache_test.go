@@ -0,0 +1,83 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataKeyCacheEncryptionKeys(t *testing.T) {
+	assert.Nil(t, NewDataKeyCache(DataKeyCachePolicy{}, nil))
+
+	now := time.Unix(1700000000, 0)
+	cache := NewDataKeyCache(DataKeyCachePolicy{TTL: time.Minute, MaxUses: 3}, func() time.Time { return now })
+	key := DataKey{Plaintext: []byte("plaintext"), CiphertextBlob: []byte("edk")}
+
+	_, ok := cache.EncryptionKey("arn:a")
+	assert.False(t, ok)
+	cache.PutEncryptionKey("arn:a", key)
+	for i := 0; i < 2; i++ {
+		got, ok := cache.EncryptionKey("arn:a")
+		require.True(t, ok)
+		assert.Equal(t, key, got)
+	}
+	_, ok = cache.EncryptionKey("arn:a")
+	assert.False(t, ok, "a key is used at most MaxUses times")
+
+	cache.PutEncryptionKey("arn:a", key)
+	_, ok = cache.EncryptionKey("arn:b")
+	assert.False(t, ok)
+	now = now.Add(time.Minute)
+	_, ok = cache.EncryptionKey("arn:a")
+	assert.False(t, ok, "a key expires after TTL")
+}
+
+func TestDataKeyCacheDecryptedKeys(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cache := NewDataKeyCache(DataKeyCachePolicy{TTL: time.Minute, MaxDecryptedKeys: 2}, func() time.Time { return now })
+
+	cache.PutDecryptedKey("arn:a", []byte("edk1"), []byte("key1"))
+	now = now.Add(time.Second)
+	cache.PutDecryptedKey("arn:a", []byte("edk2"), []byte("key2"))
+	got, ok := cache.DecryptedKey("arn:a", []byte("edk1"))
+	require.True(t, ok)
+	assert.Equal(t, []byte("key1"), got)
+	_, ok = cache.DecryptedKey("arn:b", []byte("edk1"))
+	assert.False(t, ok, "keys are cached per KMS key")
+
+	cache.PutDecryptedKey("arn:a", []byte("edk3"), []byte("key3"))
+	_, ok = cache.DecryptedKey("arn:a", []byte("edk1"))
+	assert.False(t, ok, "the key expiring first is evicted when full")
+	_, ok = cache.DecryptedKey("arn:a", []byte("edk3"))
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = cache.DecryptedKey("arn:a", []byte("edk3"))
+	assert.False(t, ok)
+}
+
+func TestDataKeyCacheForCredentials(t *testing.T) {
+	assert.Nil(t, (*DataKeyCache)(nil).ForCredentials("AKIA1"))
+
+	cache := NewDataKeyCache(DataKeyCachePolicy{TTL: time.Minute, MaxDecryptedKeys: 2}, nil)
+	first, second := cache.ForCredentials("AKIA1"), cache.ForCredentials("AKIA2")
+	key := DataKey{Plaintext: []byte("plaintext"), CiphertextBlob: []byte("edk")}
+
+	first.PutEncryptionKey("arn:a", key)
+	first.PutDecryptedKey("arn:a", []byte("edk1"), []byte("key1"))
+	_, ok := second.EncryptionKey("arn:a")
+	assert.False(t, ok, "keys are cached per credentials")
+	_, ok = second.DecryptedKey("arn:a", []byte("edk1"))
+	assert.False(t, ok)
+
+	got, ok := cache.ForCredentials("AKIA1").EncryptionKey("arn:a")
+	require.True(t, ok, "views of the same credentials share keys")
+	assert.Equal(t, key, got)
+
+	second.PutDecryptedKey("arn:a", []byte("edk2"), []byte("key2"))
+	second.PutDecryptedKey("arn:a", []byte("edk3"), []byte("key3"))
+	_, ok = first.DecryptedKey("arn:a", []byte("edk1"))
+	assert.False(t, ok, "views share MaxDecryptedKeys")
+}
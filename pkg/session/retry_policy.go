@@ -0,0 +1,228 @@
+package session
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// Retry policy defaults applied to zero fields.
+const (
+	DefaultRetryMaxAttempts       = 3
+	DefaultRetryBaseDelay         = 50 * time.Millisecond
+	DefaultRetryMaxDelay          = 5 * time.Second
+	DefaultThrottleRetryBaseDelay = 500 * time.Millisecond
+	DefaultThrottleRetryMaxDelay  = 20 * time.Second
+	DefaultCircuitFailureCount    = 10
+	DefaultCircuitCooldown        = 10 * time.Second
+)
+
+// RetryPolicy controls how every AWS SDK request made through the session is retried,
+// including DynamoDB, DAX, KMS, and S3 calls. When set on Config it replaces MaxRetries.
+// Throttling errors back off from their own, longer base delay, since a throttled
+// partition needs time to recover rather than an immediate retry.
+type RetryPolicy struct {
+	// CircuitBreaker, when set, rejects requests without sending them after repeated
+	// throttling or server errors, until a trial request succeeds.
+	CircuitBreaker *CircuitBreaker
+	// MaxAttempts is the number of attempts per request, including the first.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry of a retryable error other than
+	// throttling. It doubles with each attempt up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// ThrottleBaseDelay is the backoff before the first retry of a throttling error. It
+	// doubles with each attempt up to ThrottleMaxDelay.
+	ThrottleBaseDelay time.Duration
+	ThrottleMaxDelay  time.Duration
+	// Jitter is the fraction of each delay, between 0 and 1, that is randomized. Zero
+	// disables jitter.
+	Jitter float64
+}
+
+// CircuitBreaker configures the circuit breaker of a RetryPolicy. Requests rejected
+// while the circuit is open fail with errors.ErrCircuitOpen.
+type CircuitBreaker struct {
+	// FailureThreshold is the number of consecutive attempts failing with a retryable
+	// error, such as throttling or a 5xx response, that opens the circuit.
+	FailureThreshold int
+	// Cooldown is how long the circuit stays open before one trial request is sent. The
+	// circuit closes if it succeeds and reopens if it fails with a retryable error.
+	Cooldown time.Duration
+}
+
+// DefaultRetryPolicy returns a policy with the default attempts and delays, 50% jitter,
+// and no circuit breaker.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:       DefaultRetryMaxAttempts,
+		BaseDelay:         DefaultRetryBaseDelay,
+		MaxDelay:          DefaultRetryMaxDelay,
+		ThrottleBaseDelay: DefaultThrottleRetryBaseDelay,
+		ThrottleMaxDelay:  DefaultThrottleRetryMaxDelay,
+		Jitter:            0.5,
+	}
+}
+
+// withDefaults returns a copy of p with zero fields set to their defaults.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryMaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultRetryBaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = DefaultRetryMaxDelay
+	}
+	if p.ThrottleBaseDelay <= 0 {
+		p.ThrottleBaseDelay = DefaultThrottleRetryBaseDelay
+	}
+	if p.ThrottleMaxDelay <= 0 {
+		p.ThrottleMaxDelay = DefaultThrottleRetryMaxDelay
+	}
+	if p.Jitter < 0 {
+		p.Jitter = 0
+	}
+	if p.Jitter > 1 {
+		p.Jitter = 1
+	}
+	return p
+}
+
+// newRetryer returns an aws.Config Retryer factory applying policy. Every retryer it
+// creates shares one circuit breaker, so throttling seen by one client opens the circuit
+// for all of the session's clients.
+func newRetryer(policy RetryPolicy) func() aws.Retryer {
+	policy = policy.withDefaults()
+	var breaker *circuitBreaker
+	if policy.CircuitBreaker != nil {
+		breaker = newCircuitBreaker(*policy.CircuitBreaker, time.Now)
+	}
+	backoff := &policyBackoff{policy: policy, random: rand.Float64}
+
+	return func() aws.Retryer {
+		standard := retry.NewStandard(func(o *retry.StandardOptions) {
+			o.MaxAttempts = policy.MaxAttempts
+			o.Backoff = backoff
+		})
+		if breaker == nil {
+			return standard
+		}
+		return &breakerRetryer{RetryerV2: standard, breaker: breaker}
+	}
+}
+
+// policyBackoff computes retry delays, backing off from ThrottleBaseDelay for throttling
+// errors and from BaseDelay for everything else.
+type policyBackoff struct {
+	random func() float64
+	policy RetryPolicy
+}
+
+var throttleCheck = retry.IsErrorThrottles(retry.DefaultThrottles)
+
+func (b *policyBackoff) BackoffDelay(attempt int, err error) (time.Duration, error) {
+	base, maxDelay := b.policy.BaseDelay, b.policy.MaxDelay
+	if throttleCheck.IsErrorThrottle(err).Bool() {
+		base, maxDelay = b.policy.ThrottleBaseDelay, b.policy.ThrottleMaxDelay
+	}
+
+	delay := base
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	if b.policy.Jitter > 0 {
+		spread := float64(delay) * b.policy.Jitter
+		delay = time.Duration(float64(delay) - spread + spread*b.random())
+	}
+	return delay, nil
+}
+
+// breakerRetryer rejects attempts while the circuit breaker is open and reports each
+// attempt's outcome to it.
+type breakerRetryer struct {
+	aws.RetryerV2
+	breaker *circuitBreaker
+}
+
+func (r *breakerRetryer) GetAttemptToken(ctx context.Context) (func(error) error, error) {
+	release, err := r.RetryerV2.GetAttemptToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.breaker.allow(); err != nil {
+		_ = release(err)
+		return nil, err
+	}
+	return func(attemptErr error) error {
+		r.breaker.record(attemptErr != nil && r.IsErrorRetryable(attemptErr))
+		return release(attemptErr)
+	}, nil
+}
+
+type circuitBreaker struct {
+	now       func() time.Time
+	openUntil time.Time
+	cfg       CircuitBreaker
+	failures  int
+	mu        sync.Mutex
+	open      bool
+	probing   bool
+}
+
+func newCircuitBreaker(cfg CircuitBreaker, now func() time.Time) *circuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = DefaultCircuitFailureCount
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = DefaultCircuitCooldown
+	}
+	return &circuitBreaker{cfg: cfg, now: now}
+}
+
+// allow reports whether an attempt may be sent. Once the cooldown has passed, a single
+// trial attempt is let through while the circuit stays open.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return nil
+	}
+	if b.probing || b.now().Before(b.openUntil) {
+		return customerrors.ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// record counts an attempt that failed with a retryable error, or resets the breaker for
+// any other outcome, since the service answered.
+func (b *circuitBreaker) record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.failures = 0
+		b.open = false
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	if b.probing || b.failures >= b.cfg.FailureThreshold {
+		b.open = true
+		b.probing = false
+		b.openUntil = b.now().Add(b.cfg.Cooldown)
+	}
+}
@@ -0,0 +1,134 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+func TestPolicyBackoffSeparatesThrottling(t *testing.T) {
+	b := &policyBackoff{
+		policy: RetryPolicy{
+			BaseDelay:         10 * time.Millisecond,
+			MaxDelay:          30 * time.Millisecond,
+			ThrottleBaseDelay: 100 * time.Millisecond,
+			ThrottleMaxDelay:  time.Second,
+		}.withDefaults(),
+		random: func() float64 { return 0 },
+	}
+	serverErr := &smithy.GenericAPIError{Code: "InternalServerError"}
+	throttle := &smithy.GenericAPIError{Code: "ProvisionedThroughputExceededException"}
+
+	for attempt, want := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 3: 30 * time.Millisecond, 8: 30 * time.Millisecond} {
+		delay, err := b.BackoffDelay(attempt, serverErr)
+		require.NoError(t, err)
+		require.Equal(t, want, delay, "attempt %d", attempt)
+	}
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 3: 400 * time.Millisecond, 5: time.Second} {
+		delay, err := b.BackoffDelay(attempt, throttle)
+		require.NoError(t, err)
+		require.Equal(t, want, delay, "attempt %d", attempt)
+	}
+}
+
+func TestPolicyBackoffAppliesJitter(t *testing.T) {
+	b := &policyBackoff{
+		policy: RetryPolicy{BaseDelay: 100 * time.Millisecond, Jitter: 0.5}.withDefaults(),
+		random: func() float64 { return 0.5 },
+	}
+	delay, err := b.BackoffDelay(1, errors.New("boom"))
+	require.NoError(t, err)
+	require.Equal(t, 75*time.Millisecond, delay)
+
+	require.Equal(t, 1.0, RetryPolicy{Jitter: 3}.withDefaults().Jitter)
+}
+
+func TestCircuitBreakerOpensAndProbes(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newCircuitBreaker(CircuitBreaker{FailureThreshold: 2, Cooldown: time.Minute}, func() time.Time { return now })
+
+	require.NoError(t, b.allow())
+	b.record(true)
+	require.NoError(t, b.allow())
+	b.record(true)
+	require.ErrorIs(t, b.allow(), customerrors.ErrCircuitOpen)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, b.allow(), "one trial request after the cooldown")
+	require.ErrorIs(t, b.allow(), customerrors.ErrCircuitOpen, "only one trial at a time")
+	b.record(true)
+	require.ErrorIs(t, b.allow(), customerrors.ErrCircuitOpen, "a failed trial reopens the circuit")
+
+	now = now.Add(time.Minute)
+	require.NoError(t, b.allow())
+	b.record(false)
+	require.NoError(t, b.allow())
+	require.NoError(t, b.allow())
+}
+
+func TestCircuitBreakerDefaults(t *testing.T) {
+	b := newCircuitBreaker(CircuitBreaker{}, time.Now)
+	require.Equal(t, DefaultCircuitFailureCount, b.cfg.FailureThreshold)
+	require.Equal(t, DefaultCircuitCooldown, b.cfg.Cooldown)
+}
+
+func TestNewSessionAppliesRetryPolicy(t *testing.T) {
+	originalConfigLoad := configLoadFunc
+	defer func() { configLoadFunc = originalConfigLoad }()
+	configLoadFunc = func(context.Context, ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return aws.Config{
+			Region: "us-east-1",
+			Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+				return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+			}),
+		}, nil
+	}
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ProvisionedThroughputExceededException","message":"slow down"}`))
+	}))
+	defer server.Close()
+
+	sess, err := NewSession(&Config{
+		Region:   "us-east-1",
+		Endpoint: server.URL,
+		RetryPolicy: &RetryPolicy{
+			MaxAttempts:       3,
+			BaseDelay:         time.Microsecond,
+			ThrottleBaseDelay: time.Microsecond,
+			CircuitBreaker:    &CircuitBreaker{FailureThreshold: 3, Cooldown: time.Hour},
+		},
+	})
+	require.NoError(t, err)
+	client, err := sess.Client()
+	require.NoError(t, err)
+
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String("orders"),
+		Key:       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "o1"}},
+	}
+	_, err = client.GetItem(context.Background(), input)
+	require.Error(t, err)
+	require.Equal(t, int32(3), calls.Load())
+
+	_, err = client.GetItem(context.Background(), input)
+	require.ErrorIs(t, err, customerrors.ErrCircuitOpen)
+	require.Equal(t, int32(3), calls.Load(), "open circuit sends nothing")
+}
@@ -6,13 +6,19 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/pay-theory/dynamorm/pkg/model"
+	"github.com/pay-theory/dynamorm/pkg/naming"
 )
 
 // configLoadFunc is a variable to allow mocking config.LoadDefaultConfig in tests
@@ -25,7 +31,22 @@ type Config struct {
 	Endpoint            string
 	// KMSKeyARN is required when using dynamorm:"encrypted" fields.
 	// DynamORM does not manage KMS keys; callers must provide a valid key ARN.
-	KMSKeyARN        string
+	KMSKeyARN string
+	// ItemEncryptor, when set, encrypts the items of models with dynamorm:"encrypted"
+	// fields in its own format instead of DynamORM's per-attribute envelopes, and
+	// KMSKeyARN, KMSPreviousKeyARNs and DataKeyCache are not used.
+	ItemEncryptor ItemEncryptor `json:"-" yaml:"-"`
+	// KMSPreviousKeyARNs are keys that earlier values of dynamorm:"encrypted" fields were
+	// written with. After rotating KMSKeyARN, list the old key here so items written under
+	// it stay readable; new values are always written under KMSKeyARN.
+	KMSPreviousKeyARNs []string
+	// DataKeyCache, when its TTL is set, lets encrypted fields reuse KMS data keys
+	// instead of calling KMS for every attribute value.
+	DataKeyCache DataKeyCachePolicy
+	// BlindIndexKey is the secret, at least 32 bytes, that fields tagged
+	// dynamorm:"blindindex:..." are keyed with. Keep it with the same care as KMSKeyARN's
+	// key: anyone holding it can test guesses against stored blind indexes.
+	BlindIndexKey    []byte           `json:"-" yaml:"-"`
 	KMSClient        KMSClient        `json:"-" yaml:"-"`
 	EncryptionRand   io.Reader        `json:"-" yaml:"-"`
 	Now              func() time.Time `json:"-" yaml:"-"`
@@ -36,6 +57,64 @@ type Config struct {
 	DefaultWCU       int64
 	AutoMigrate      bool
 	EnableMetrics    bool
+	// EnableDAX routes GetItem, Query, Scan, BatchGetItem, PutItem, UpdateItem,
+	// DeleteItem and BatchWriteItem through an Amazon DAX cluster at DAXEndpoints, so
+	// writes refresh the DAX item cache. Transactions, PartiQL and table management use
+	// DynamoDB directly; reads that must see their writes should use ConsistentRead.
+	EnableDAX    bool
+	DAXEndpoints []string
+	// DAXClientFactory builds the DAX client, e.g. with github.com/aws/aws-dax-go-v2.
+	// It is required when EnableDAX is set. Sessions from WithCredentials share the
+	// client and pass it their credentials as a dynamodb.Options option on each call.
+	DAXClientFactory func(cfg aws.Config, endpoints []string) (DAXClient, error) `json:"-" yaml:"-"`
+	// S3OverflowBucket is required when using dynamorm:"s3overflow" fields. Values of
+	// those fields larger than S3OverflowThreshold bytes (default 64KB) are stored as
+	// objects under S3OverflowPrefix and the item keeps a pointer to the object.
+	S3OverflowBucket    string
+	S3OverflowPrefix    string
+	S3OverflowThreshold int
+	S3Client            S3Client `json:"-" yaml:"-"`
+	// S3OverflowCleanupError is called when an overflow object replaced by an overwrite
+	// or orphaned by a delete cannot be removed. The write itself has already succeeded,
+	// so the error is not returned to the caller; without a hook it is dropped and the
+	// object is left for the bucket's lifecycle rule.
+	S3OverflowCleanupError func(bucket, key string, err error) `json:"-" yaml:"-"`
+	// RetryPolicy, when set, replaces MaxRetries and the SDK's default backoff for every
+	// AWS request the session makes, adding throttle-aware delays and an optional
+	// circuit breaker.
+	RetryPolicy *RetryPolicy
+	// TableNamePrefix and TableNameSuffix are added to every model's table name, after
+	// NamingStrategy when both are set, so one set of models can serve "dev_orders" and
+	// "prod_orders" without overriding TableName() on each.
+	TableNamePrefix string
+	TableNameSuffix string
+	NamingStrategy  naming.NamingStrategy `json:"-" yaml:"-"`
+	// FieldPolicy sets how models' unexported fields, interface fields, and embedded
+	// interfaces are registered. The zero value skips unexported fields and stores the
+	// others.
+	FieldPolicy model.FieldPolicy `json:"-" yaml:"-"`
+}
+
+// TableNamingStrategy returns the strategy mapping model table names to tables:
+// NamingStrategy followed by TableNamePrefix and TableNameSuffix, or nil when the
+// configuration sets none of them.
+func (c *Config) TableNamingStrategy() naming.NamingStrategy {
+	if c == nil {
+		return nil
+	}
+	var affix naming.NamingStrategy
+	if c.TableNamePrefix != "" || c.TableNameSuffix != "" {
+		affix = naming.TableAffix{Prefix: c.TableNamePrefix, Suffix: c.TableNameSuffix}
+	}
+	return naming.ChainStrategies(c.NamingStrategy, affix)
+}
+
+// S3Client is the minimal Amazon S3 surface DynamORM needs for attribute overflow.
+// Providing this enables deterministic tests without real S3 calls.
+type S3Client interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
 }
 
 // KMSClient is the minimal AWS KMS surface DynamORM needs for attribute encryption.
@@ -45,6 +124,40 @@ type KMSClient interface {
 	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
 }
 
+// ItemEncryptor encrypts and decrypts whole items of a table. DynamORM implements no
+// item format of its own: it only hands items to the ItemEncryptor supplied, such as an
+// adapter over the item encryptors of the AWS Database Encryption SDK for DynamoDB, and
+// stores what it returns. Compatibility with other services depends on that encryptor.
+type ItemEncryptor interface {
+	EncryptItem(ctx context.Context, tableName string, item map[string]types.AttributeValue) (map[string]types.AttributeValue, error)
+	DecryptItem(ctx context.Context, tableName string, item map[string]types.AttributeValue) (map[string]types.AttributeValue, error)
+}
+
+// ReadClient is the subset of the DynamoDB API used for reads. Both *dynamodb.Client
+// and the DAX client satisfy it.
+type ReadClient interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+	BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
+}
+
+// WriteClient is the subset of the DynamoDB API used for single-item and batch writes.
+// Both *dynamodb.Client and the DAX client satisfy it.
+type WriteClient interface {
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+}
+
+// DAXClient is the DAX surface DynamORM routes operations through. Writes must go
+// through DAX as well as reads, or the DAX item cache serves stale items until its TTL.
+type DAXClient interface {
+	ReadClient
+	WriteClient
+}
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -61,7 +174,13 @@ func DefaultConfig() *Config {
 type Session struct {
 	config    *Config
 	client    *dynamodb.Client
+	daxClient DAXClient
 	awsConfig aws.Config
+
+	s3Once   sync.Once
+	s3Client S3Client
+
+	dataKeys *DataKeyCache
 }
 
 // NewSession creates a new session with the given configuration
@@ -88,6 +207,9 @@ func NewSession(cfg *Config) (*Session, error) {
 	if maxAttempts <= 0 {
 		maxAttempts = 3 // Default
 	}
+	if cfg.RetryPolicy != nil {
+		maxAttempts = cfg.RetryPolicy.withDefaults().MaxAttempts
+	}
 	options = append(options, config.WithRetryMode(aws.RetryModeStandard))
 	options = append(options, config.WithRetryMaxAttempts(maxAttempts))
 
@@ -105,7 +227,9 @@ func NewSession(cfg *Config) (*Session, error) {
 	}
 
 	// Ensure we have a valid retryer
-	if awsConfig.Retryer == nil {
+	if cfg.RetryPolicy != nil {
+		awsConfig.Retryer = newRetryer(*cfg.RetryPolicy)
+	} else if awsConfig.Retryer == nil {
 		awsConfig.Retryer = func() aws.Retryer {
 			return retry.NewStandard(func(o *retry.StandardOptions) {
 				o.MaxAttempts = maxAttempts
@@ -145,13 +269,41 @@ func NewSession(cfg *Config) (*Session, error) {
 		return nil, fmt.Errorf("failed to create DynamoDB client")
 	}
 
+	daxClient, err := newDAXClient(cfg, awsConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Session{
 		config:    cfg,
 		awsConfig: awsConfig,
 		client:    client,
+		daxClient: daxClient,
+		dataKeys:  NewDataKeyCache(cfg.DataKeyCache, cfg.Now),
 	}, nil
 }
 
+func newDAXClient(cfg *Config, awsConfig aws.Config) (DAXClient, error) {
+	if !cfg.EnableDAX {
+		return nil, nil
+	}
+	if len(cfg.DAXEndpoints) == 0 {
+		return nil, fmt.Errorf("DAX is enabled but no DAXEndpoints are configured")
+	}
+	if cfg.DAXClientFactory == nil {
+		return nil, fmt.Errorf("DAX is enabled but no DAXClientFactory is configured")
+	}
+
+	client, err := cfg.DAXClientFactory(awsConfig, cfg.DAXEndpoints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DAX client: %w", err)
+	}
+	if client == nil {
+		return nil, fmt.Errorf("failed to create DAX client: factory returned nil")
+	}
+	return client, nil
+}
+
 // Client returns the DynamoDB client
 func (s *Session) Client() (*dynamodb.Client, error) {
 	if s == nil {
@@ -163,6 +315,56 @@ func (s *Session) Client() (*dynamodb.Client, error) {
 	return s.client, nil
 }
 
+// ReadClient returns the client reads should use: the DAX client when DAX is
+// enabled, otherwise the DynamoDB client
+func (s *Session) ReadClient() (ReadClient, error) {
+	if s != nil && s.daxClient != nil {
+		return s.daxClient, nil
+	}
+	return s.Client()
+}
+
+// WriteClient returns the client single-item and batch writes should use: the DAX
+// client when DAX is enabled, so the item cache is written through, otherwise the
+// DynamoDB client
+func (s *Session) WriteClient() (WriteClient, error) {
+	if s != nil && s.daxClient != nil {
+		return s.daxClient, nil
+	}
+	return s.Client()
+}
+
+// DAXEnabled reports whether reads and writes are routed through DAX
+func (s *Session) DAXEnabled() bool {
+	return s != nil && s.daxClient != nil
+}
+
+// S3Client returns the client used for attribute overflow: Config.S3Client when set,
+// otherwise an S3 client built from the session's AWS config on first use and shared
+// by every later call
+func (s *Session) S3Client() S3Client {
+	if s == nil {
+		return nil
+	}
+	s.s3Once.Do(func() {
+		if s.config != nil && s.config.S3Client != nil {
+			s.s3Client = s.config.S3Client
+			return
+		}
+		s.s3Client = s3.NewFromConfig(s.awsConfig)
+	})
+	return s.s3Client
+}
+
+// DataKeyCache returns the cache of KMS data keys shared by the session's encrypted
+// fields, or nil when Config.DataKeyCache has no TTL.
+func (s *Session) DataKeyCache() *DataKeyCache {
+	if s == nil {
+		return nil
+	}
+	return s.dataKeys
+}
+
 // Config returns the session configuration
 func (s *Session) Config() *Config {
 	return s.config
@@ -173,6 +375,78 @@ func (s *Session) AWSConfig() aws.Config {
 	return s.awsConfig
 }
 
+// WithCredentials returns a session that signs its AWS requests with provider instead of
+// the session's credentials, for credentials scoped to one request or tenant. It shares
+// the session's configuration, HTTP connections and DAX client, which is passed the
+// credentials with each call, and Config.S3Client and Config.KMSClient, when set, are used
+// as they are. The data key cache is shared too, holding keys per credentials, so KMS
+// authorizes each data key the credentials use.
+func (s *Session) WithCredentials(provider aws.CredentialsProvider) *Session {
+	if s == nil {
+		return nil
+	}
+	if _, ok := provider.(*aws.CredentialsCache); !ok && provider != nil {
+		provider = aws.NewCredentialsCache(provider)
+	}
+	awsConfig := s.awsConfig.Copy()
+	awsConfig.Credentials = provider
+
+	scoped := &Session{config: s.config, awsConfig: awsConfig, dataKeys: s.dataKeys}
+	if s.client != nil {
+		scoped.client = dynamodb.New(s.client.Options(), func(o *dynamodb.Options) {
+			o.Credentials = provider
+		})
+	}
+	if s.daxClient != nil {
+		scoped.daxClient = credentialsDAXClient{client: s.daxClient, credentials: provider}
+	}
+	return scoped
+}
+
+// credentialsDAXClient signs the calls of a shared DAX client with other credentials.
+type credentialsDAXClient struct {
+	client      DAXClient
+	credentials aws.CredentialsProvider
+}
+
+func (c credentialsDAXClient) withCredentials(optFns []func(*dynamodb.Options)) []func(*dynamodb.Options) {
+	return append(optFns[:len(optFns):len(optFns)], func(o *dynamodb.Options) {
+		o.Credentials = c.credentials
+	})
+}
+
+func (c credentialsDAXClient) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return c.client.GetItem(ctx, params, c.withCredentials(optFns)...)
+}
+
+func (c credentialsDAXClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return c.client.Query(ctx, params, c.withCredentials(optFns)...)
+}
+
+func (c credentialsDAXClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return c.client.Scan(ctx, params, c.withCredentials(optFns)...)
+}
+
+func (c credentialsDAXClient) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	return c.client.BatchGetItem(ctx, params, c.withCredentials(optFns)...)
+}
+
+func (c credentialsDAXClient) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	return c.client.PutItem(ctx, params, c.withCredentials(optFns)...)
+}
+
+func (c credentialsDAXClient) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	return c.client.UpdateItem(ctx, params, c.withCredentials(optFns)...)
+}
+
+func (c credentialsDAXClient) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	return c.client.DeleteItem(ctx, params, c.withCredentials(optFns)...)
+}
+
+func (c credentialsDAXClient) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	return c.client.BatchWriteItem(ctx, params, c.withCredentials(optFns)...)
+}
+
 // WithContext returns a new session with the given context
 func (s *Session) WithContext(ctx context.Context) *Session {
 	_ = ctx
@@ -3,13 +3,17 @@ package session
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/naming"
 )
 
 // TestDefaultConfig tests the DefaultConfig function
@@ -262,6 +266,81 @@ func TestSession_Getters(t *testing.T) {
 	})
 }
 
+type fakeDAXClient struct {
+	DAXClient
+}
+
+// TestSession_DAX tests read and write client selection when DAX is enabled
+func TestSession_DAX(t *testing.T) {
+	originalConfigLoad := configLoadFunc
+	defer func() { configLoadFunc = originalConfigLoad }()
+
+	configLoadFunc = func(ctx context.Context, opts ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return aws.Config{Region: "test-region"}, nil
+	}
+
+	t.Run("Disabled uses DynamoDB client", func(t *testing.T) {
+		sess, err := NewSession(&Config{Region: "test-region"})
+		require.NoError(t, err)
+
+		client, err := sess.ReadClient()
+		require.NoError(t, err)
+		assert.IsType(t, &dynamodb.Client{}, client)
+		assert.False(t, sess.DAXEnabled())
+
+		writeClient, err := sess.WriteClient()
+		require.NoError(t, err)
+		assert.IsType(t, &dynamodb.Client{}, writeClient)
+	})
+
+	t.Run("Enabled uses DAX client for reads and writes", func(t *testing.T) {
+		dax := &fakeDAXClient{}
+		var gotEndpoints []string
+		sess, err := NewSession(&Config{
+			Region:       "test-region",
+			EnableDAX:    true,
+			DAXEndpoints: []string{"dax://cluster.example.com"},
+			DAXClientFactory: func(cfg aws.Config, endpoints []string) (DAXClient, error) {
+				assert.Equal(t, "test-region", cfg.Region)
+				gotEndpoints = endpoints
+				return dax, nil
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"dax://cluster.example.com"}, gotEndpoints)
+
+		client, err := sess.ReadClient()
+		require.NoError(t, err)
+		assert.Same(t, dax, client)
+		assert.True(t, sess.DAXEnabled())
+
+		writeClient, err := sess.WriteClient()
+		require.NoError(t, err)
+		assert.Same(t, dax, writeClient)
+
+		dynamoClient, err := sess.Client()
+		require.NoError(t, err)
+		assert.IsType(t, &dynamodb.Client{}, dynamoClient)
+	})
+
+	t.Run("Enabled requires endpoints and factory", func(t *testing.T) {
+		_, err := NewSession(&Config{EnableDAX: true})
+		assert.ErrorContains(t, err, "no DAXEndpoints")
+
+		_, err = NewSession(&Config{EnableDAX: true, DAXEndpoints: []string{"dax://cluster"}})
+		assert.ErrorContains(t, err, "no DAXClientFactory")
+
+		_, err = NewSession(&Config{
+			EnableDAX:    true,
+			DAXEndpoints: []string{"dax://cluster"},
+			DAXClientFactory: func(aws.Config, []string) (DAXClient, error) {
+				return nil, errors.New("unreachable")
+			},
+		})
+		assert.ErrorContains(t, err, "failed to create DAX client: unreachable")
+	})
+}
+
 // TestSession_WithContext tests the WithContext method
 func TestSession_WithContext(t *testing.T) {
 	// Mock AWS config loading
@@ -422,3 +501,92 @@ func BenchmarkSessionGetters(b *testing.B) {
 		}
 	})
 }
+
+func TestConfigTableNamingStrategy(t *testing.T) {
+	assert.Nil(t, (&Config{}).TableNamingStrategy())
+	assert.Nil(t, (*Config)(nil).TableNamingStrategy())
+
+	typ := reflect.TypeOf(Config{})
+	cfg := &Config{TableNamePrefix: "dev_", TableNameSuffix: "_v2"}
+	require.NotNil(t, cfg.TableNamingStrategy())
+	assert.Equal(t, "dev_orders_v2", cfg.TableNamingStrategy().TableName(typ, "orders"))
+
+	cfg.NamingStrategy = naming.NamingStrategyFunc(func(_ reflect.Type, name string) string { return "app-" + name })
+	assert.Equal(t, "dev_app-orders_v2", cfg.TableNamingStrategy().TableName(typ, "orders"))
+}
+
+// TestSession_WithCredentials tests sessions signing with other credentials
+func TestSession_WithCredentials(t *testing.T) {
+	originalConfigLoad := configLoadFunc
+	defer func() { configLoadFunc = originalConfigLoad }()
+
+	configLoadFunc = func(ctx context.Context, opts ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return aws.Config{Region: "test-region", Credentials: credentials.NewStaticCredentialsProvider("BASE", "secret", "")}, nil
+	}
+	scopedCreds := credentials.NewStaticCredentialsProvider("SCOPED", "secret", "token")
+
+	t.Run("DynamoDB client", func(t *testing.T) {
+		sess, err := NewSession(&Config{Region: "test-region"})
+		require.NoError(t, err)
+		scoped := sess.WithCredentials(scopedCreds)
+
+		client, err := scoped.Client()
+		require.NoError(t, err)
+		creds, err := client.Options().Credentials.Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "SCOPED", creds.AccessKeyID)
+		assert.Equal(t, "test-region", client.Options().Region)
+		creds, err = scoped.AWSConfig().Credentials.Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "SCOPED", creds.AccessKeyID)
+		assert.Same(t, sess.Config(), scoped.Config())
+
+		base, err := sess.Client()
+		require.NoError(t, err)
+		creds, err = base.Options().Credentials.Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "BASE", creds.AccessKeyID)
+	})
+
+	t.Run("DAX client", func(t *testing.T) {
+		dax := &credentialsRecordingDAX{}
+		factoryCalls := 0
+		sess, err := NewSession(&Config{
+			Region:       "test-region",
+			EnableDAX:    true,
+			DAXEndpoints: []string{"dax://cluster"},
+			DAXClientFactory: func(cfg aws.Config, endpoints []string) (DAXClient, error) {
+				factoryCalls++
+				return dax, nil
+			},
+		})
+		require.NoError(t, err)
+
+		for range 2 {
+			client, err := sess.WithCredentials(scopedCreds).ReadClient()
+			require.NoError(t, err)
+			_, err = client.GetItem(context.Background(), &dynamodb.GetItemInput{})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, factoryCalls, "scoped sessions share the DAX client")
+		require.Len(t, dax.credentials, 2)
+		creds, err := dax.credentials[1].Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "SCOPED", creds.AccessKeyID)
+	})
+}
+
+// credentialsRecordingDAX records the credentials each GetItem call is made with.
+type credentialsRecordingDAX struct {
+	DAXClient
+	credentials []aws.CredentialsProvider
+}
+
+func (d *credentialsRecordingDAX) GetItem(_ context.Context, _ *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	var o dynamodb.Options
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	d.credentials = append(d.credentials, o.Credentials)
+	return &dynamodb.GetItemOutput{}, nil
+}
@@ -0,0 +1,132 @@
+// Package slowquery keeps a bounded log of DynamoDB operations that exceeded a latency
+// threshold, so an ops endpoint can show what was slow without a tracing backend.
+package slowquery
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultThreshold is the latency at or above which an operation is logged.
+	DefaultThreshold = time.Second
+
+	// DefaultCapacity bounds how many slow operations are retained.
+	DefaultCapacity = 50
+)
+
+// Entry describes one slow operation.
+type Entry struct {
+	Time      time.Time     `json:"time"`
+	Operation string        `json:"operation"`
+	Table     string        `json:"table"`
+	Index     string        `json:"index,omitempty"`
+	Duration  time.Duration `json:"duration"`
+	// Tags are the request tags the operation carried, such as endpoint or feature.
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// Log retains the most recent slow operations. It is safe for concurrent use.
+type Log struct {
+	now       func() time.Time
+	entries   []Entry
+	next      int
+	threshold time.Duration
+	capacity  int
+	mu        sync.Mutex
+}
+
+// NewLog creates a log using DefaultThreshold and DefaultCapacity.
+func NewLog() *Log {
+	return &Log{
+		now:       time.Now,
+		threshold: DefaultThreshold,
+		capacity:  DefaultCapacity,
+	}
+}
+
+// SetThreshold changes the latency at or above which operations are logged. Zero or
+// less disables logging.
+func (l *Log) SetThreshold(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.threshold = d
+}
+
+// Threshold returns the current threshold.
+func (l *Log) Threshold() time.Duration {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.threshold
+}
+
+// SetCapacity changes how many entries are retained, discarding the current ones.
+func (l *Log) SetCapacity(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if n <= 0 {
+		n = DefaultCapacity
+	}
+	l.capacity = n
+	l.entries = nil
+	l.next = 0
+}
+
+// Observe records an operation that took duration if it meets the threshold.
+func (l *Log) Observe(operation, table, index string, duration time.Duration) {
+	l.ObserveWithTags(operation, table, index, nil, duration)
+}
+
+// ObserveWithTags is Observe for an operation carrying request tags, which are kept with
+// its entry.
+func (l *Log) ObserveWithTags(operation, table, index string, tags map[string]string, duration time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.threshold <= 0 || duration < l.threshold {
+		return
+	}
+
+	entry := Entry{
+		Time:      l.now(),
+		Operation: operation,
+		Table:     table,
+		Index:     index,
+		Duration:  duration,
+		Tags:      tags,
+	}
+	if len(l.entries) < l.capacity {
+		l.entries = append(l.entries, entry)
+		return
+	}
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % l.capacity
+}
+
+// Recent returns the retained entries, most recent first.
+func (l *Log) Recent() []Entry {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := make([]Entry, 0, len(l.entries))
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		out = append(out, l.entries[(l.next+i)%len(l.entries)])
+	}
+	return out
+}
+
+// Reset discards all entries.
+func (l *Log) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = nil
+	l.next = 0
+}
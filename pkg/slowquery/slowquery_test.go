@@ -0,0 +1,64 @@
+package slowquery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogKeepsOperationsAtOrAboveThreshold(t *testing.T) {
+	l := NewLog()
+	fixed := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return fixed }
+	l.SetThreshold(100 * time.Millisecond)
+
+	l.Observe("GetItem", "users", "", 20*time.Millisecond)
+	l.Observe("Query", "orders", "gsi-customer", 150*time.Millisecond)
+	l.Observe("PutItem", "orders", "", 100*time.Millisecond)
+
+	require.Equal(t, []Entry{
+		{Time: fixed, Operation: "PutItem", Table: "orders", Duration: 100 * time.Millisecond},
+		{Time: fixed, Operation: "Query", Table: "orders", Index: "gsi-customer", Duration: 150 * time.Millisecond},
+	}, l.Recent())
+
+	l.Reset()
+	require.Empty(t, l.Recent())
+}
+
+func TestLogKeepsRequestTags(t *testing.T) {
+	l := NewLog()
+	l.SetThreshold(time.Millisecond)
+
+	l.ObserveWithTags("Query", "orders", "", map[string]string{"feature": "checkout"}, time.Second)
+
+	recent := l.Recent()
+	require.Len(t, recent, 1)
+	require.Equal(t, map[string]string{"feature": "checkout"}, recent[0].Tags)
+}
+
+func TestLogRetainsMostRecentEntries(t *testing.T) {
+	l := NewLog()
+	l.SetThreshold(time.Millisecond)
+	l.SetCapacity(2)
+
+	l.Observe("Op1", "t", "", time.Second)
+	l.Observe("Op2", "t", "", time.Second)
+	l.Observe("Op3", "t", "", time.Second)
+
+	recent := l.Recent()
+	require.Len(t, recent, 2)
+	require.Equal(t, "Op3", recent[0].Operation)
+	require.Equal(t, "Op2", recent[1].Operation)
+}
+
+func TestLogDisabledByZeroThreshold(t *testing.T) {
+	l := NewLog()
+	l.SetThreshold(0)
+	l.Observe("Scan", "t", "", time.Hour)
+	require.Empty(t, l.Recent())
+
+	var nilLog *Log
+	nilLog.Observe("Scan", "t", "", time.Hour)
+	require.Nil(t, nilLog.Recent())
+}
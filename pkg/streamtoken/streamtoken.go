@@ -0,0 +1,178 @@
+// Package streamtoken converts positions in a DynamoDB stream into opaque tokens that
+// external clients can hold and hand back, so change-feed APIs can offer "changes since
+// this token" reads.
+//
+// Shard iterators cannot serve as tokens: they expire after 15 minutes. What stays
+// valid is the sequence number of the last record delivered from each shard, for as
+// long as the stream retains the record (24 hours). A Token records those per shard,
+// and Position turns them back into the GetShardIterator arguments that resume after
+// them:
+//
+//	token, err := streamtoken.Decode(clientToken, streamARN)
+//	if err != nil {
+//		return err // ErrInvalidToken or ErrStreamMismatch: ask the client to start over
+//	}
+//	for _, shard := range shards {
+//		iteratorType, sequenceNumber := token.Position(shard.ShardId)
+//		// GetShardIterator with iteratorType and, if set, sequenceNumber;
+//		// then for each record delivered:
+//		//	token.Advance(shard.ShardId, record.SequenceNumber)
+//	}
+//	next, err := token.Encode()
+//
+// Shards the token has not seen start at TRIM_HORIZON, which is what a child shard
+// needs once its parent is exhausted. Forget drops exhausted shards to keep tokens
+// small. A token older than the stream's retention can name records that were trimmed;
+// GetShardIterator then fails with TrimmedDataAccessException and the client must
+// start over.
+package streamtoken
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Shard iterator types Position returns, as GetShardIterator takes them.
+const (
+	IteratorTrimHorizon         = "TRIM_HORIZON"
+	IteratorAfterSequenceNumber = "AFTER_SEQUENCE_NUMBER"
+)
+
+// tokenVersion is written into every token so the format can change later.
+const tokenVersion = 1
+
+var (
+	// ErrInvalidToken is returned by Decode for a token it did not produce.
+	ErrInvalidToken = errors.New("invalid stream token")
+	// ErrStreamMismatch is returned by Decode for a token from another stream, such as
+	// one from before the table's stream was disabled and enabled again.
+	ErrStreamMismatch = errors.New("stream token is for a different stream")
+)
+
+// Token is a resumable position in one DynamoDB stream: the sequence number of the last
+// record delivered from each shard read so far. The zero value is not usable; use New
+// or Decode.
+type Token struct {
+	// Shards maps shard IDs to the sequence number of the last record delivered.
+	Shards map[string]string
+	// StreamARN identifies the stream, including its label.
+	StreamARN string
+}
+
+type encodedToken struct {
+	Shards    map[string]string `json:"p,omitempty"`
+	StreamARN string            `json:"s"`
+	Version   int               `json:"v"`
+}
+
+// New returns a token for streamARN positioned before every record.
+func New(streamARN string) *Token {
+	return &Token{StreamARN: streamARN, Shards: make(map[string]string)}
+}
+
+// Advance records that the record with sequenceNumber was delivered from shardID.
+// Positions only move forward, so records delivered again after a retry do not move
+// the token back.
+func (t *Token) Advance(shardID, sequenceNumber string) error {
+	if shardID == "" {
+		return errors.New("shard ID cannot be empty")
+	}
+	if !validSequenceNumber(sequenceNumber) {
+		return fmt.Errorf("invalid sequence number %q", sequenceNumber)
+	}
+	if current, ok := t.Shards[shardID]; ok && CompareSequenceNumbers(current, sequenceNumber) >= 0 {
+		return nil
+	}
+	t.Shards[shardID] = sequenceNumber
+	return nil
+}
+
+// Forget drops shardID from the token, for a closed shard whose records have all been
+// delivered and whose children are being read.
+func (t *Token) Forget(shardID string) {
+	delete(t.Shards, shardID)
+}
+
+// Position returns the GetShardIterator arguments that resume shardID after the last
+// record delivered: AFTER_SEQUENCE_NUMBER with its sequence number, or TRIM_HORIZON and
+// no sequence number for a shard the token has not seen.
+func (t *Token) Position(shardID string) (iteratorType, sequenceNumber string) {
+	if sequenceNumber, ok := t.Shards[shardID]; ok {
+		return IteratorAfterSequenceNumber, sequenceNumber
+	}
+	return IteratorTrimHorizon, ""
+}
+
+// Encode returns the token as an opaque, URL-safe string.
+func (t *Token) Encode() (string, error) {
+	data, err := json.Marshal(encodedToken{Version: tokenVersion, StreamARN: t.StreamARN, Shards: t.Shards})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal stream token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Decode parses a token produced by Encode. An empty string decodes to a new token for
+// streamARN. When streamARN is set, a token for another stream fails with
+// ErrStreamMismatch; pass "" to accept any stream.
+func Decode(encoded, streamARN string) (*Token, error) {
+	if encoded == "" {
+		return New(streamARN), nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	var decoded encodedToken
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	if decoded.Version != tokenVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidToken, decoded.Version)
+	}
+	if decoded.StreamARN == "" {
+		return nil, fmt.Errorf("%w: missing stream ARN", ErrInvalidToken)
+	}
+	if streamARN != "" && decoded.StreamARN != streamARN {
+		return nil, fmt.Errorf("%w: token is for %s", ErrStreamMismatch, decoded.StreamARN)
+	}
+
+	token := New(decoded.StreamARN)
+	for shardID, sequenceNumber := range decoded.Shards {
+		if err := token.Advance(shardID, sequenceNumber); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+		}
+	}
+	return token, nil
+}
+
+// CompareSequenceNumbers compares two sequence numbers from the same shard, returning
+// -1, 0, or 1. Sequence numbers are decimal strings of up to 40 digits, too long for
+// integer types, so they are compared by length and then digit by digit.
+func CompareSequenceNumbers(a, b string) int {
+	a = strings.TrimLeft(a, "0")
+	b = strings.TrimLeft(b, "0")
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func validSequenceNumber(sequenceNumber string) bool {
+	if sequenceNumber == "" {
+		return false
+	}
+	for _, r := range sequenceNumber {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,75 @@
+package streamtoken
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	streamARN      = "arn:aws:dynamodb:us-east-1:123456789012:table/orders/stream/2026-01-01T00:00:00.000"
+	otherStreamARN = "arn:aws:dynamodb:us-east-1:123456789012:table/orders/stream/2026-02-01T00:00:00.000"
+)
+
+func TestTokenRoundTripsPositions(t *testing.T) {
+	token := New(streamARN)
+	require.NoError(t, token.Advance("shardId-0001", "100000000000000000001"))
+	require.NoError(t, token.Advance("shardId-0002", "9"))
+
+	encoded, err := token.Encode()
+	require.NoError(t, err)
+	require.NotContains(t, encoded, "=")
+
+	decoded, err := Decode(encoded, streamARN)
+	require.NoError(t, err)
+	require.Equal(t, token, decoded)
+
+	iteratorType, sequenceNumber := decoded.Position("shardId-0001")
+	require.Equal(t, IteratorAfterSequenceNumber, iteratorType)
+	require.Equal(t, "100000000000000000001", sequenceNumber)
+
+	iteratorType, sequenceNumber = decoded.Position("shardId-0003")
+	require.Equal(t, IteratorTrimHorizon, iteratorType)
+	require.Empty(t, sequenceNumber)
+
+	decoded.Forget("shardId-0002")
+	iteratorType, _ = decoded.Position("shardId-0002")
+	require.Equal(t, IteratorTrimHorizon, iteratorType)
+}
+
+func TestTokenAdvanceOnlyMovesForward(t *testing.T) {
+	token := New(streamARN)
+	require.NoError(t, token.Advance("shard", "200"))
+	require.NoError(t, token.Advance("shard", "99"))
+	require.Equal(t, "200", token.Shards["shard"])
+	require.NoError(t, token.Advance("shard", "1000"))
+	require.Equal(t, "1000", token.Shards["shard"])
+
+	require.ErrorContains(t, token.Advance("", "1"), "shard ID cannot be empty")
+	require.ErrorContains(t, token.Advance("shard", "12a"), "invalid sequence number")
+}
+
+func TestDecode(t *testing.T) {
+	empty, err := Decode("", streamARN)
+	require.NoError(t, err)
+	require.Equal(t, New(streamARN), empty)
+
+	encoded, err := New(otherStreamARN).Encode()
+	require.NoError(t, err)
+	_, err = Decode(encoded, streamARN)
+	require.ErrorIs(t, err, ErrStreamMismatch)
+	anyStream, err := Decode(encoded, "")
+	require.NoError(t, err)
+	require.Equal(t, otherStreamARN, anyStream.StreamARN)
+
+	for _, bad := range []string{"%%%", "bm90LWpzb24", "eyJ2IjoyLCJzIjoieCJ9", "eyJ2IjoxfQ", "eyJ2IjoxLCJzIjoieCIsInAiOnsiYSI6Im5vIn19"} {
+		_, err := Decode(bad, "")
+		require.ErrorIs(t, err, ErrInvalidToken, bad)
+	}
+}
+
+func TestCompareSequenceNumbers(t *testing.T) {
+	require.Equal(t, -1, CompareSequenceNumbers("99", "100"))
+	require.Equal(t, 1, CompareSequenceNumbers("100000000000000000000000000000000000002", "100000000000000000000000000000000000001"))
+	require.Equal(t, 0, CompareSequenceNumbers("0042", "42"))
+}
@@ -221,6 +221,21 @@ func (m *MockUpdateBuilder) SetListElement(field string, index int, value any) c
 	return mustUpdateBuilder(args.Get(0))
 }
 
+func (m *MockUpdateBuilder) AppendToListWithLimit(field string, values any, maxLen int) core.UpdateBuilder {
+	args := m.Called(field, values, maxLen)
+	return mustUpdateBuilder(args.Get(0))
+}
+
+func (m *MockUpdateBuilder) SetMapKey(field string, key string, value any) core.UpdateBuilder {
+	args := m.Called(field, key, value)
+	return mustUpdateBuilder(args.Get(0))
+}
+
+func (m *MockUpdateBuilder) RemoveMapKey(field string, key string) core.UpdateBuilder {
+	args := m.Called(field, key)
+	return mustUpdateBuilder(args.Get(0))
+}
+
 func (m *MockUpdateBuilder) Condition(field string, operator string, value any) core.UpdateBuilder {
 	args := m.Called(field, operator, value)
 	return mustUpdateBuilder(args.Get(0))
@@ -0,0 +1,278 @@
+// Package throttle adapts bulk writes to the throttling DynamoDB reports for each table.
+//
+// A Controller keeps a scale per table: the fraction of their configured batch size or
+// write rate that bulk operations use. Throttling cuts the scale by DecreaseFactor, and
+// every RecoveryInterval the table goes without throttling raises it by IncreaseStep
+// until it is back to 1. Pass the controller to the bulk operations that accept a
+// core.ThrottleFeedback, and install its middleware so that attempts the SDK retried
+// after a throttle count too:
+//
+//	feedback := throttle.NewController(throttle.Options{})
+//	db.Use(feedback.Middleware())
+//
+//	result, err := db.Model(&Order{}).BatchCreateWithOptions(orders, &core.BatchCreateOptions{
+//		RetryPolicy: core.DefaultRetryPolicy(),
+//		ChunkSize:   25,
+//		Feedback:    feedback,
+//	})
+//
+// One controller should be shared by every bulk job writing to the same tables, so a job
+// started while another is being throttled starts small.
+package throttle
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+// Defaults applied to zero Options fields.
+const (
+	DefaultWindow           = time.Minute
+	DefaultDecreaseInterval = time.Second
+	DefaultRecoveryInterval = 5 * time.Second
+	DefaultDecreaseFactor   = 0.5
+	DefaultIncreaseStep     = 0.1
+	DefaultMinScale         = 0.05
+)
+
+// Options configures a Controller.
+type Options struct {
+	// Window is how far back Stats counts attempts and throttles.
+	Window time.Duration
+	// DecreaseInterval is the minimum time between two decreases, so one burst of
+	// throttled requests cuts the scale once rather than once per request.
+	DecreaseInterval time.Duration
+	// RecoveryInterval is how long a table must go without throttling before its scale
+	// is raised by IncreaseStep, and again for each further step.
+	RecoveryInterval time.Duration
+	// DecreaseFactor multiplies the scale when throttling is observed (0 to 1).
+	DecreaseFactor float64
+	// IncreaseStep is added to the scale after each RecoveryInterval without throttling.
+	IncreaseStep float64
+	// MinScale is the lowest scale a table is cut to.
+	MinScale float64
+}
+
+func (o Options) withDefaults() Options {
+	if o.Window <= 0 {
+		o.Window = DefaultWindow
+	}
+	if o.DecreaseInterval <= 0 {
+		o.DecreaseInterval = DefaultDecreaseInterval
+	}
+	if o.RecoveryInterval <= 0 {
+		o.RecoveryInterval = DefaultRecoveryInterval
+	}
+	if o.DecreaseFactor <= 0 || o.DecreaseFactor >= 1 {
+		o.DecreaseFactor = DefaultDecreaseFactor
+	}
+	if o.IncreaseStep <= 0 {
+		o.IncreaseStep = DefaultIncreaseStep
+	}
+	if o.MinScale <= 0 || o.MinScale > 1 {
+		o.MinScale = DefaultMinScale
+	}
+	return o
+}
+
+// TableStats reports the state of one table.
+type TableStats struct {
+	LastThrottle time.Time
+	Table        string
+	// Scale is the fraction of the configured batch size or rate in use, between
+	// MinScale and 1.
+	Scale float64
+	// Attempted and Throttled count the requests or items observed within the window,
+	// and how many of them were throttled.
+	Attempted int64
+	Throttled int64
+}
+
+// ThrottleRate returns the fraction of the attempts within the window that were
+// throttled.
+func (s TableStats) ThrottleRate() float64 {
+	if s.Attempted == 0 {
+		return 0
+	}
+	return float64(s.Throttled) / float64(s.Attempted)
+}
+
+type counts struct {
+	attempted int64
+	throttled int64
+}
+
+type tableState struct {
+	windowStart  time.Time
+	lastThrottle time.Time
+	lastDecrease time.Time
+	lastIncrease time.Time
+	current      counts
+	previous     counts
+	scale        float64
+}
+
+// Controller tracks throttling per table. It implements core.ThrottleFeedback and is
+// safe for concurrent use.
+type Controller struct {
+	now    func() time.Time
+	tables map[string]*tableState
+	opts   Options
+	mu     sync.Mutex
+}
+
+var _ core.ThrottleFeedback = (*Controller)(nil)
+
+// NewController creates a controller. Zero fields of opts take their defaults.
+func NewController(opts Options) *Controller {
+	return &Controller{
+		now:    time.Now,
+		tables: make(map[string]*tableState),
+		opts:   opts.withDefaults(),
+	}
+}
+
+// Observe records that attempted requests or items were sent to table and throttled of
+// them were throttled or left unprocessed.
+func (c *Controller) Observe(table string, attempted, throttled int) {
+	if c == nil || (attempted <= 0 && throttled <= 0) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	state := c.tables[table]
+	if state == nil {
+		state = &tableState{scale: 1, windowStart: now}
+		c.tables[table] = state
+	}
+	c.roll(state, now)
+	state.current.attempted += int64(max(attempted, throttled))
+	state.current.throttled += int64(max(throttled, 0))
+	if throttled <= 0 {
+		return
+	}
+
+	c.recover(state, now)
+	state.lastThrottle = now
+	if !state.lastDecrease.IsZero() && now.Sub(state.lastDecrease) < c.opts.DecreaseInterval {
+		return
+	}
+	state.scale = math.Max(c.opts.MinScale, state.scale*c.opts.DecreaseFactor)
+	state.lastDecrease = now
+}
+
+// Scale returns the fraction of the configured batch size or rate to use for table: 1
+// for a table that has not been throttled recently.
+func (c *Controller) Scale(table string) float64 {
+	if c == nil {
+		return 1
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	state := c.tables[table]
+	if state == nil {
+		return 1
+	}
+	c.recover(state, c.now())
+	return state.scale
+}
+
+// Size scales n, a batch size or concurrency, to table's current scale. The result is
+// at least 1.
+func (c *Controller) Size(table string, n int) int {
+	scale := c.Scale(table)
+	if scale >= 1 || n <= 1 {
+		return max(n, 1)
+	}
+	return max(int(math.Ceil(float64(n)*scale)), 1)
+}
+
+// Stats returns the state of every observed table, ordered by table name.
+func (c *Controller) Stats() []TableStats {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	now := c.now()
+	out := make([]TableStats, 0, len(c.tables))
+	for table, state := range c.tables {
+		c.roll(state, now)
+		c.recover(state, now)
+		out = append(out, TableStats{
+			Table:        table,
+			Scale:        state.scale,
+			Attempted:    state.current.attempted + state.previous.attempted,
+			Throttled:    state.current.throttled + state.previous.throttled,
+			LastThrottle: state.lastThrottle,
+		})
+	}
+	c.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Table < out[j].Table })
+	return out
+}
+
+// Reset forgets every table, restoring them all to full scale.
+func (c *Controller) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tables = make(map[string]*tableState)
+}
+
+// Middleware returns DynamORM middleware that observes the attempts and throttles of
+// every operation:
+//
+//	db.Use(feedback.Middleware())
+func (c *Controller) Middleware() core.Middleware {
+	return func(next core.Handler) core.Handler {
+		return func(ctx context.Context, op *core.Operation) error {
+			err := next(ctx, op)
+			if op.Table != "" {
+				c.Observe(op.Table, op.Attempts, op.Throttles)
+			}
+			return err
+		}
+	}
+}
+
+// roll starts a new counting window once the current one is Window old. The window
+// before it is kept, so Stats always covers between one and two windows.
+func (c *Controller) roll(state *tableState, now time.Time) {
+	elapsed := now.Sub(state.windowStart)
+	if elapsed < c.opts.Window {
+		return
+	}
+	state.previous = state.current
+	if elapsed >= 2*c.opts.Window {
+		state.previous = counts{}
+	}
+	state.current = counts{}
+	state.windowStart = now
+}
+
+// recover raises the scale by IncreaseStep for each RecoveryInterval that has passed
+// since the last throttle or increase.
+func (c *Controller) recover(state *tableState, now time.Time) {
+	if state.scale >= 1 {
+		return
+	}
+	since := state.lastThrottle
+	if state.lastIncrease.After(since) {
+		since = state.lastIncrease
+	}
+	steps := int(now.Sub(since) / c.opts.RecoveryInterval)
+	if steps <= 0 {
+		return
+	}
+	state.scale = math.Min(1, state.scale+float64(steps)*c.opts.IncreaseStep)
+	state.lastIncrease = since.Add(time.Duration(steps) * c.opts.RecoveryInterval)
+}
@@ -0,0 +1,126 @@
+package throttle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestController(opts Options) (*Controller, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}
+	c := NewController(opts)
+	c.now = func() time.Time { return clock.now }
+	return c, clock
+}
+
+func TestControllerDecreasesOncePerInterval(t *testing.T) {
+	c, clock := newTestController(Options{})
+	require.Equal(t, 1.0, c.Scale("orders"))
+
+	c.Observe("orders", 25, 5)
+	require.Equal(t, 0.5, c.Scale("orders"))
+
+	// A burst of throttled requests within DecreaseInterval counts as one.
+	c.Observe("orders", 25, 5)
+	c.Observe("orders", 25, 5)
+	require.Equal(t, 0.5, c.Scale("orders"))
+
+	clock.advance(DefaultDecreaseInterval)
+	c.Observe("orders", 25, 5)
+	require.Equal(t, 0.25, c.Scale("orders"))
+	require.Equal(t, 7, c.Size("orders", 25))
+
+	for i := 0; i < 10; i++ {
+		clock.advance(DefaultDecreaseInterval)
+		c.Observe("orders", 25, 5)
+	}
+	require.Equal(t, DefaultMinScale, c.Scale("orders"))
+	require.Equal(t, 2, c.Size("orders", 25))
+	require.Equal(t, 1, c.Size("orders", 5))
+
+	require.Equal(t, 1.0, c.Scale("customers"), "tables are tracked separately")
+}
+
+func TestControllerRecoversStepByStep(t *testing.T) {
+	c, clock := newTestController(Options{IncreaseStep: 0.25})
+
+	c.Observe("orders", 10, 10)
+	require.Equal(t, 0.5, c.Scale("orders"))
+
+	clock.advance(DefaultRecoveryInterval - time.Millisecond)
+	require.Equal(t, 0.5, c.Scale("orders"))
+
+	clock.advance(time.Millisecond)
+	require.Equal(t, 0.75, c.Scale("orders"))
+
+	// Throttling again restarts recovery from the new, lower scale.
+	clock.advance(DefaultRecoveryInterval / 2)
+	c.Observe("orders", 10, 1)
+	require.Equal(t, 0.375, c.Scale("orders"))
+
+	clock.advance(DefaultRecoveryInterval / 2)
+	require.Equal(t, 0.375, c.Scale("orders"))
+
+	clock.advance(3 * DefaultRecoveryInterval)
+	require.Equal(t, 1.0, c.Scale("orders"))
+}
+
+func TestControllerStatsCoverRecentWindows(t *testing.T) {
+	c, clock := newTestController(Options{Window: time.Minute})
+
+	c.Observe("orders", 100, 10)
+	clock.advance(time.Minute)
+	c.Observe("orders", 100, 0)
+	c.Observe("customers", 4, 0)
+
+	stats := c.Stats()
+	require.Len(t, stats, 2)
+	require.Equal(t, "customers", stats[0].Table)
+	require.Equal(t, 1.0, stats[0].Scale)
+	require.Zero(t, stats[0].ThrottleRate())
+
+	orders := stats[1]
+	require.Equal(t, int64(200), orders.Attempted)
+	require.Equal(t, int64(10), orders.Throttled)
+	require.Equal(t, 0.05, orders.ThrottleRate())
+	require.Equal(t, clock.now.Add(-time.Minute), orders.LastThrottle)
+
+	clock.advance(2 * time.Minute)
+	orders = c.Stats()[1]
+	require.Zero(t, orders.Attempted)
+	require.Equal(t, 1.0, orders.Scale)
+
+	c.Reset()
+	require.Empty(t, c.Stats())
+}
+
+func TestControllerMiddlewareObservesOperations(t *testing.T) {
+	c, _ := newTestController(Options{})
+	handler := c.Middleware()(func(_ context.Context, op *core.Operation) error {
+		op.Attempts = 3
+		op.Throttles = 2
+		return nil
+	})
+
+	require.NoError(t, handler(context.Background(), &core.Operation{Type: "PutItem", Table: "orders"}))
+	stats := c.Stats()
+	require.Len(t, stats, 1)
+	require.Equal(t, int64(3), stats[0].Attempted)
+	require.Equal(t, int64(2), stats[0].Throttled)
+	require.Equal(t, 0.5, stats[0].Scale)
+}
+
+func TestNilControllerIsFullScale(t *testing.T) {
+	var c *Controller
+	c.Observe("orders", 1, 1)
+	require.Equal(t, 1.0, c.Scale("orders"))
+	require.Nil(t, c.Stats())
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"reflect"
 	"strings"
 	"time"
@@ -12,10 +11,11 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-	"github.com/google/uuid"
 
+	"github.com/pay-theory/dynamorm/internal/deadline"
 	"github.com/pay-theory/dynamorm/internal/encryption"
 	"github.com/pay-theory/dynamorm/internal/expr"
+	"github.com/pay-theory/dynamorm/pkg/contention"
 	"github.com/pay-theory/dynamorm/pkg/core"
 	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
 	"github.com/pay-theory/dynamorm/pkg/model"
@@ -41,13 +41,19 @@ type dynamoTransactAPI interface {
 
 // Builder implements the core.TransactionBuilder interface.
 type Builder struct {
-	client     dynamoTransactAPI
-	ctx        context.Context
-	err        error
-	session    *session.Session
-	registry   *model.Registry
-	converter  *pkgTypes.Converter
-	operations []transactOperation
+	client      dynamoTransactAPI
+	ctx         context.Context
+	err         error
+	session     *session.Session
+	registry    *model.Registry
+	converter   *pkgTypes.Converter
+	contention  *contention.Tracker
+	tokens      *TokenCache
+	onWrite     func(context.Context, []types.TransactWriteItem)
+	guard       func(table string, write bool) error
+	itemGuard   func(item *types.TransactWriteItem, metadata *model.Metadata) error
+	clientToken string
+	operations  []transactOperation
 }
 
 type operationType int
@@ -147,6 +153,56 @@ func (b *Builder) WithContext(ctx context.Context) core.TransactionBuilder {
 	return b
 }
 
+// WithContentionTracker records condition failures and transaction conflicts reported
+// in cancellation reasons against the affected items.
+func (b *Builder) WithContentionTracker(tracker *contention.Tracker) *Builder {
+	b.contention = tracker
+	return b
+}
+
+// WithClientRequestToken sets the ClientRequestToken for the next Execute. Without
+// one, a token is derived from the Lambda request ID and the transaction's operations
+// when running in Lambda with a token cache configured, and generated randomly otherwise.
+func (b *Builder) WithClientRequestToken(token string) core.TransactionBuilder {
+	if err := validateClientToken(token); err != nil {
+		b.recordError(err)
+		return b
+	}
+	b.clientToken = token
+	return b
+}
+
+// WithTokenCache skips transactions whose client request token already committed
+// with the same operations within the idempotency window, and enables Lambda request
+// ID derived tokens.
+func (b *Builder) WithTokenCache(cache *TokenCache) *Builder {
+	b.tokens = cache
+	return b
+}
+
+// WithWriteObserver calls fn with the items of every TransactWriteItems request the
+// builder sends, after DynamoDB responds and whether or not the transaction committed.
+// Caches use it to drop the items a transaction may have changed.
+func (b *Builder) WithWriteObserver(fn func(ctx context.Context, items []types.TransactWriteItem)) *Builder {
+	b.onWrite = fn
+	return b
+}
+
+// WithTableGuard calls fn with the table of every item before the transaction is sent,
+// and returns its first error instead of sending it.
+func (b *Builder) WithTableGuard(fn func(table string, write bool) error) *Builder {
+	b.guard = fn
+	return b
+}
+
+// WithItemGuard calls fn with every item, and the metadata of the model it writes, before
+// the transaction is sent, and returns its first error instead of sending it. fn may
+// change the item, as by adding to its condition.
+func (b *Builder) WithItemGuard(fn func(item *types.TransactWriteItem, metadata *model.Metadata) error) *Builder {
+	b.itemGuard = fn
+	return b
+}
+
 // Execute commits the transaction using the builder's configured context.
 func (b *Builder) Execute() error {
 	return b.ExecuteWithContext(b.ctx)
@@ -169,18 +225,50 @@ func (b *Builder) ExecuteWithContext(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if b.guard != nil {
+		for _, item := range items {
+			table, _ := transactItemTarget(item)
+			if err := b.guard(table, true); err != nil {
+				return err
+			}
+		}
+	}
+	if b.itemGuard != nil {
+		for i := range items {
+			if err := b.itemGuard(&items[i], b.operations[i].metadata); err != nil {
+				return err
+			}
+		}
+	}
+
+	digest, err := operationsDigest(items)
+	if err != nil {
+		return err
+	}
+	token := resolveClientToken(ctx, b.clientToken, digest, b.tokens)
+	if b.tokens.replayed(token, digest) {
+		b.operations = nil
+		b.clientToken = ""
+		return nil
+	}
 
 	input := &dynamodb.TransactWriteItemsInput{
 		TransactItems:      items,
-		ClientRequestToken: aws.String(uuid.NewString()),
+		ClientRequestToken: aws.String(token),
 	}
 
-	if err := b.executeWithRetry(ctx, input); err != nil {
+	err = b.executeWithRetry(ctx, input)
+	if b.onWrite != nil {
+		b.onWrite(ctx, items)
+	}
+	if err != nil {
 		return err
 	}
+	b.tokens.remember(token, digest)
 
 	// Allow builder reuse after successful execution
 	b.operations = nil
+	b.clientToken = ""
 	return nil
 }
 
@@ -207,6 +295,12 @@ func (b *Builder) addOperation(opType operationType, model any, fields []string,
 		b.recordError(err)
 		return
 	}
+	if opType != opConditionCheck {
+		if err := rejectUniqueFields(metadata); err != nil {
+			b.recordError(err)
+			return
+		}
+	}
 
 	b.operations = append(b.operations, transactOperation{
 		typ:        opType,
@@ -218,6 +312,16 @@ func (b *Builder) addOperation(opType operationType, model any, fields []string,
 	})
 }
 
+// rejectUniqueFields fails for models with unique fields, whose uniqueness items
+// transactions cannot maintain.
+func rejectUniqueFields(metadata *model.Metadata) error {
+	if len(metadata.UniqueFields) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: transactions cannot maintain the unique fields of %s; write it outside the transaction",
+		customerrors.ErrInvalidOperator, metadata.Type.Name())
+}
+
 func (b *Builder) recordError(err error) {
 	if err != nil && b.err == nil {
 		b.err = err
@@ -336,19 +440,45 @@ func (b *Builder) buildFieldUpdate(op transactOperation) (*types.Update, error)
 	}
 
 	builder := expr.NewBuilderWithConverter(b.converter)
+	updated := make([]*model.FieldMetadata, 0, len(op.fields))
 	for _, field := range op.fields {
 		fieldMeta := op.metadata.Fields[field]
 		if fieldMeta == nil {
 			return nil, fmt.Errorf("unknown field %s for update", field)
 		}
-		fieldValue := value.Field(fieldMeta.Index)
+		updated = append(updated, fieldMeta)
+	}
+	for _, fieldMeta := range append(updated, op.metadata.KeyUpdates(updated)...) {
+		field := fieldMeta.Name
+		if fieldMeta.BlindIndexOf != "" {
+			continue // set with the blind index updates below
+		}
+		fieldValue, err := keyFieldValue(op.metadata, fieldMeta, value)
+		if err != nil {
+			return nil, err
+		}
 		if !fieldValue.IsValid() {
 			return nil, fmt.Errorf("field %s is invalid", field)
 		}
-		if err := builder.AddUpdateSet(fieldMeta.DBName, fieldValue.Interface()); err != nil {
+		if fieldMeta.AllowNull && fieldValue.IsNil() {
+			if err := builder.AddUpdateRemove(fieldMeta.DBName); err != nil {
+				return nil, fmt.Errorf("failed to build update for %s: %w", field, err)
+			}
+			continue
+		}
+		value := fieldValue.Interface()
+		if fieldMeta.IsEnum() {
+			if value, err = fieldMeta.EnumName(fieldValue); err != nil {
+				return nil, err
+			}
+		}
+		if err := builder.AddUpdateSet(fieldMeta.DBName, value); err != nil {
 			return nil, fmt.Errorf("failed to build update for %s: %w", field, err)
 		}
 	}
+	if err := b.addBlindIndexUpdates(builder, op.metadata, value, updated); err != nil {
+		return nil, err
+	}
 
 	rawConds, err := b.applyConditionsToBuilder(op.metadata, builder, op.conditions)
 	if err != nil {
@@ -369,18 +499,9 @@ func (b *Builder) buildFieldUpdate(op transactOperation) (*types.Update, error)
 		if err := encryption.FailClosedIfEncryptedWithoutKMSKeyARN(b.session, op.metadata); err != nil {
 			return nil, err
 		}
-		cfg := b.session.Config()
-		keyARN := ""
-		var rng io.Reader
-		if cfg != nil {
-			keyARN = cfg.KMSKeyARN
-			rng = cfg.EncryptionRand
-		}
-		var svc *encryption.Service
-		if cfg != nil && cfg.KMSClient != nil {
-			svc = encryption.NewServiceWithRand(keyARN, cfg.KMSClient, rng)
-		} else {
-			svc = encryption.NewServiceFromAWSConfigWithRand(keyARN, b.session.AWSConfig(), rng)
+		svc, err := encryption.NewServiceForSession(b.session)
+		if err != nil {
+			return nil, err
 		}
 		ctx := b.ctx
 		if ctx == nil {
@@ -410,9 +531,58 @@ func (b *Builder) buildFieldUpdate(op transactOperation) (*types.Update, error)
 	return update, nil
 }
 
+// addBlindIndexUpdates sets the blind indexes among updated, and those of the encrypted
+// fields among updated, from the encrypted fields' values in modelValue.
+func (b *Builder) addBlindIndexUpdates(builder *expr.Builder, metadata *model.Metadata, modelValue reflect.Value, updated []*model.FieldMetadata) error {
+	indexes := metadata.BlindIndexUpdates(updated)
+	for _, field := range updated {
+		if field.BlindIndexOf != "" {
+			indexes = append(indexes, field)
+		}
+	}
+	if len(indexes) == 0 {
+		return nil
+	}
+	key, err := encryption.BlindIndexKey(b.session, metadata)
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		source := metadata.Fields[index.BlindIndexOf]
+		value := modelValue.FieldByIndex(source.IndexPath)
+		if (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) && value.IsNil() {
+			if err := builder.AddUpdateRemove(index.DBName); err != nil {
+				return fmt.Errorf("failed to build update for %s: %w", index.Name, err)
+			}
+			continue
+		}
+		var av types.AttributeValue
+		if b.converter != nil {
+			av, err = b.converter.ToAttributeValue(value.Interface())
+		} else {
+			av, err = expr.ConvertToAttributeValue(value.Interface())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to build update for %s: %w", index.Name, err)
+		}
+		hash, err := encryption.BlindIndex(key, source.DBName, av)
+		if err != nil {
+			return err
+		}
+		if err := builder.AddUpdateSet(index.DBName, hash); err != nil {
+			return fmt.Errorf("failed to build update for %s: %w", index.Name, err)
+		}
+	}
+	return nil
+}
+
 func (b *Builder) buildBuilderUpdate(op transactOperation, index int) (*types.Update, error) {
 	capture := &capturingUpdateExecutor{}
 	q := query.New(op.model, adaptMetadata(op.metadata), capture)
+	if b.session != nil && b.session.Config() != nil {
+		q.WithBlindIndexKey(b.session.Config().BlindIndexKey)
+	}
 
 	if err := b.populateKeyConditions(q, op.metadata, op.model); err != nil {
 		return nil, err
@@ -498,18 +668,9 @@ func (b *Builder) buildBuilderUpdate(op transactOperation, index int) (*types.Up
 		if err := encryption.FailClosedIfEncryptedWithoutKMSKeyARN(b.session, op.metadata); err != nil {
 			return nil, err
 		}
-		cfg := b.session.Config()
-		keyARN := ""
-		var rng io.Reader
-		if cfg != nil {
-			keyARN = cfg.KMSKeyARN
-			rng = cfg.EncryptionRand
-		}
-		var svc *encryption.Service
-		if cfg != nil && cfg.KMSClient != nil {
-			svc = encryption.NewServiceWithRand(keyARN, cfg.KMSClient, rng)
-		} else {
-			svc = encryption.NewServiceFromAWSConfigWithRand(keyARN, b.session.AWSConfig(), rng)
+		svc, err := encryption.NewServiceForSession(b.session)
+		if err != nil {
+			return nil, err
 		}
 		ctx := b.ctx
 		if ctx == nil {
@@ -622,7 +783,10 @@ func (b *Builder) populateKeyConditions(q *query.Query, metadata *model.Metadata
 	}
 
 	pkMeta := metadata.PrimaryKey.PartitionKey
-	pkValue := value.Field(pkMeta.Index)
+	pkValue, err := keyFieldValue(metadata, pkMeta, value)
+	if err != nil {
+		return err
+	}
 	if !pkValue.IsValid() || pkValue.IsZero() {
 		return fmt.Errorf("partition key %s is required", pkMeta.Name)
 	}
@@ -631,7 +795,10 @@ func (b *Builder) populateKeyConditions(q *query.Query, metadata *model.Metadata
 
 	if metadata.PrimaryKey.SortKey != nil {
 		skMeta := metadata.PrimaryKey.SortKey
-		skValue := value.Field(skMeta.Index)
+		skValue, err := keyFieldValue(metadata, skMeta, value)
+		if err != nil {
+			return err
+		}
 		if !skValue.IsValid() || skValue.IsZero() {
 			return fmt.Errorf("sort key %s is required", skMeta.Name)
 		}
@@ -789,6 +956,7 @@ func (b *Builder) mergeRawConditions(expression string, names map[string]string,
 
 func (b *Builder) executeWithRetry(ctx context.Context, input *dynamodb.TransactWriteItemsInput) error {
 	var attempt int
+	budget := deadline.New(ctx, deadline.DefaultReserve)
 
 	for {
 		if b.client == nil {
@@ -802,11 +970,14 @@ func (b *Builder) executeWithRetry(ctx context.Context, input *dynamodb.Transact
 			b.client = client
 		}
 
+		done := budget.Start()
 		_, err := b.client.TransactWriteItems(ctx, input)
+		done()
 		if err == nil {
 			return nil
 		}
 
+		b.recordContention(err, input.TransactItems)
 		retryable, translated := b.translateError(err)
 		if !retryable || attempt >= len(retrySchedule) {
 			return translated
@@ -815,6 +986,11 @@ func (b *Builder) executeWithRetry(ctx context.Context, input *dynamodb.Transact
 		sleep := retrySchedule[attempt]
 		attempt++
 
+		if !budget.CanAfford(sleep) {
+			return fmt.Errorf("%w: transaction not retried after %d attempts: %w",
+				customerrors.ErrDeadlineBudgetExhausted, attempt, translated)
+		}
+
 		timer := time.NewTimer(sleep)
 		select {
 		case <-ctx.Done():
@@ -826,7 +1002,63 @@ func (b *Builder) executeWithRetry(ctx context.Context, input *dynamodb.Transact
 	}
 }
 
+// recordContention reports every ConditionalCheckFailed and TransactionConflict
+// cancellation reason to the contention tracker.
+func (b *Builder) recordContention(err error, items []types.TransactWriteItem) {
+	if b.contention == nil {
+		return
+	}
+	var canceled *types.TransactionCanceledException
+	if !errors.As(err, &canceled) {
+		return
+	}
+
+	for idx, reason := range canceled.CancellationReasons {
+		var kind contention.Kind
+		switch aws.ToString(reason.Code) {
+		case "ConditionalCheckFailed":
+			kind = contention.KindConditionFailed
+		case "TransactionConflict":
+			kind = contention.KindTransactionConflict
+		default:
+			continue
+		}
+		if idx >= len(items) {
+			continue
+		}
+
+		table, key := transactItemTarget(items[idx])
+		var partitionKey types.AttributeValue
+		if idx < len(b.operations) && b.operations[idx].metadata != nil {
+			if pk := b.operations[idx].metadata.PrimaryKey.PartitionKey; pk != nil {
+				partitionKey = key[pk.DBName]
+			}
+		}
+		b.contention.Record(kind, "TransactWriteItems", table, partitionKey)
+	}
+}
+
+func transactItemTarget(item types.TransactWriteItem) (string, map[string]types.AttributeValue) {
+	switch {
+	case item.Put != nil:
+		return aws.ToString(item.Put.TableName), item.Put.Item
+	case item.Update != nil:
+		return aws.ToString(item.Update.TableName), item.Update.Key
+	case item.Delete != nil:
+		return aws.ToString(item.Delete.TableName), item.Delete.Key
+	case item.ConditionCheck != nil:
+		return aws.ToString(item.ConditionCheck.TableName), item.ConditionCheck.Key
+	}
+	return "", nil
+}
+
 func (b *Builder) translateError(err error) (bool, error) {
+	var mismatch *types.IdempotentParameterMismatchException
+	if errors.As(err, &mismatch) {
+		return false, fmt.Errorf("%w: client request token was already used for a different transaction: %w",
+			customerrors.ErrTransactionFailed, err)
+	}
+
 	var canceled *types.TransactionCanceledException
 	if errors.As(err, &canceled) {
 		retryable := true
@@ -850,8 +1082,9 @@ func (b *Builder) buildTransactionError(exc *types.TransactionCanceledException,
 		return fmt.Errorf("transaction canceled: %w", original)
 	}
 
+	var reasons []customerrors.CancellationReason
 	for idx, reason := range exc.CancellationReasons {
-		if reason.Code == nil {
+		if reason.Code == nil || *reason.Code == "None" {
 			continue
 		}
 
@@ -864,21 +1097,32 @@ func (b *Builder) buildTransactionError(exc *types.TransactionCanceledException,
 			modelName = reflect.TypeOf(b.operations[idx].model).String()
 		}
 
-		baseErr := customerrors.ErrTransactionFailed
-		if *reason.Code == "ConditionalCheckFailed" {
-			baseErr = customerrors.ErrConditionFailed
-		}
-
-		return &customerrors.TransactionError{
-			OperationIndex: idx,
+		reasons = append(reasons, customerrors.CancellationReason{
+			Code:           *reason.Code,
+			Message:        aws.ToString(reason.Message),
 			Operation:      opName,
 			Model:          modelName,
-			Reason:         aws.ToString(reason.Message),
-			Err:            baseErr,
-		}
+			OperationIndex: idx,
+		})
+	}
+	if len(reasons) == 0 {
+		return fmt.Errorf("transaction canceled: %w", original)
+	}
+
+	first := reasons[0]
+	baseErr := customerrors.ErrTransactionFailed
+	if first.ConditionFailed() {
+		baseErr = customerrors.ErrConditionFailed
 	}
 
-	return fmt.Errorf("transaction canceled: %w", original)
+	return &customerrors.TransactionError{
+		OperationIndex: first.OperationIndex,
+		Operation:      first.Operation,
+		Model:          first.Model,
+		Reason:         first.Message,
+		Reasons:        reasons,
+		Err:            baseErr,
+	}
 }
 
 func isRetryableReason(code string) bool {
@@ -1105,6 +1349,11 @@ func (m *metadataAdapter) VersionFieldName() string {
 	return ""
 }
 
+// RawMetadata returns the underlying model metadata.
+func (m *metadataAdapter) RawMetadata() *model.Metadata {
+	return m.meta
+}
+
 func convertFieldMetadata(field *model.FieldMetadata) *core.AttributeMetadata {
 	if field == nil {
 		return nil
@@ -0,0 +1,196 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+// Op is one write in a change-set committed with RunChunked.
+type Op struct {
+	// Apply queues the write on a transaction.
+	Apply func(tx core.TransactionBuilder)
+	// Undo queues the write reversing Apply. RunChunked runs it when a later chunk fails;
+	// leave it nil for writes that need no undoing.
+	Undo func(tx core.TransactionBuilder)
+}
+
+// PutOp puts model. It has no Undo, since the item it replaces is unknown.
+func PutOp(model any, conditions ...core.TransactCondition) Op {
+	return Op{Apply: func(tx core.TransactionBuilder) { tx.Put(model, conditions...) }}
+}
+
+// CreateOp creates model, failing if it exists, and is undone by deleting it.
+func CreateOp(model any, conditions ...core.TransactCondition) Op {
+	return Op{
+		Apply: func(tx core.TransactionBuilder) { tx.Create(model, conditions...) },
+		Undo:  func(tx core.TransactionBuilder) { tx.Delete(model) },
+	}
+}
+
+// UpdateOp updates fields of model. Set Undo to restore the previous values.
+func UpdateOp(model any, fields []string, conditions ...core.TransactCondition) Op {
+	return Op{Apply: func(tx core.TransactionBuilder) { tx.Update(model, fields, conditions...) }}
+}
+
+// DeleteOp deletes model. Set Undo, for example to a PutOp of the item as read, to
+// restore it.
+func DeleteOp(model any, conditions ...core.TransactCondition) Op {
+	return Op{Apply: func(tx core.TransactionBuilder) { tx.Delete(model, conditions...) }}
+}
+
+// ChunkProgress reports a committed chunk.
+type ChunkProgress struct {
+	// Chunk is the zero-based index of the chunk committed, of Chunks in all.
+	Chunk  int
+	Chunks int
+	// Committed is the number of operations committed so far, of Total.
+	Committed int
+	Total     int
+}
+
+// DefaultCompensationTimeout bounds the Undo writes RunChunked runs after a chunk fails.
+const DefaultCompensationTimeout = 30 * time.Second
+
+// ChunkOption configures RunChunked.
+type ChunkOption func(*chunkConfig)
+
+type chunkConfig struct {
+	progress            func(ChunkProgress)
+	compensationTimeout time.Duration
+}
+
+// WithChunkProgress calls fn after each chunk commits.
+func WithChunkProgress(fn func(ChunkProgress)) ChunkOption {
+	return func(c *chunkConfig) {
+		c.progress = fn
+	}
+}
+
+// WithCompensationTimeout sets how long the Undo writes may take after a chunk fails
+// (DefaultCompensationTimeout when not set). Values of zero or less are ignored.
+func WithCompensationTimeout(timeout time.Duration) ChunkOption {
+	return func(c *chunkConfig) {
+		if timeout > 0 {
+			c.compensationTimeout = timeout
+		}
+	}
+}
+
+// ChunkError is returned by RunChunked when a chunk fails. The chunks before it had
+// committed and were compensated; Err is the failure and CompensationErr, when set, is
+// why compensation stopped, leaving their writes in place.
+type ChunkError struct {
+	Err             error
+	CompensationErr error
+	// Chunk is the zero-based index of the chunk that failed.
+	Chunk int
+	// Committed is the number of operations committed before the failure. Compensated
+	// is the number of those whose Undo committed; operations without an Undo are not
+	// counted.
+	Committed   int
+	Compensated int
+}
+
+func (e *ChunkError) Error() string {
+	msg := fmt.Sprintf("chunk %d failed after %d operations committed: %v", e.Chunk, e.Committed, e.Err)
+	if e.CompensationErr != nil {
+		msg += fmt.Sprintf("; compensation failed after undoing %d: %v", e.Compensated, e.CompensationErr)
+	}
+	return msg
+}
+
+func (e *ChunkError) Unwrap() []error {
+	if e.CompensationErr != nil {
+		return []error{e.Err, e.CompensationErr}
+	}
+	return []error{e.Err}
+}
+
+// RunChunked commits ops, a change-set too large for one transaction, as consecutive
+// TransactWriteItems calls of up to chunkSize operations (at most 25, the most a
+// Builder accepts; zero means 25), each started with begin:
+//
+//	err := transaction.RunChunked(ctx, db.Transact, ops, 0,
+//		transaction.WithChunkProgress(func(p transaction.ChunkProgress) {
+//			log.Printf("committed %d/%d", p.Committed, p.Total)
+//		}))
+//
+// Chunks commit in order, each atomically, and a chunk starts only after the one before
+// it committed. An item must not appear twice in one chunk. Condition checks guard only
+// the chunk they are in. When a chunk fails, the Undo writes of the operations already
+// committed run in reverse order, chunked the same way, and RunChunked returns a
+// *ChunkError.
+//
+// Compensation runs even when ctx was canceled or timed out, since that is often why the
+// chunk failed: the Undo writes keep ctx's values but not its cancellation, and get a
+// timeout of their own (see WithCompensationTimeout).
+func RunChunked(ctx context.Context, begin func() core.TransactionBuilder, ops []Op, chunkSize int, opts ...ChunkOption) error {
+	if begin == nil {
+		return errors.New("transaction: RunChunked needs a begin function")
+	}
+	if chunkSize <= 0 || chunkSize > maxTransactOperations {
+		chunkSize = maxTransactOperations
+	}
+	for i, op := range ops {
+		if op.Apply == nil {
+			return fmt.Errorf("transaction: operation %d has no Apply function", i)
+		}
+	}
+	cfg := chunkConfig{compensationTimeout: DefaultCompensationTimeout}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	chunks := (len(ops) + chunkSize - 1) / chunkSize
+	for chunk := 0; chunk < chunks; chunk++ {
+		start := chunk * chunkSize
+		end := min(start+chunkSize, len(ops))
+
+		tx := begin()
+		for _, op := range ops[start:end] {
+			op.Apply(tx)
+		}
+		if err := tx.ExecuteWithContext(ctx); err != nil {
+			chunkErr := &ChunkError{Err: err, Chunk: chunk, Committed: start}
+			undoCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.compensationTimeout)
+			chunkErr.Compensated, chunkErr.CompensationErr = compensate(undoCtx, begin, ops[:start], chunkSize)
+			cancel()
+			return chunkErr
+		}
+		if cfg.progress != nil {
+			cfg.progress(ChunkProgress{Chunk: chunk, Chunks: chunks, Committed: end, Total: len(ops)})
+		}
+	}
+	return nil
+}
+
+// compensate commits the Undo writes of committed in reverse order, returning how many
+// committed.
+func compensate(ctx context.Context, begin func() core.TransactionBuilder, committed []Op, chunkSize int) (int, error) {
+	var undo []Op
+	for i := len(committed) - 1; i >= 0; i-- {
+		if committed[i].Undo != nil {
+			undo = append(undo, Op{Apply: committed[i].Undo})
+		}
+	}
+
+	done := 0
+	for start := 0; start < len(undo); start += chunkSize {
+		end := min(start+chunkSize, len(undo))
+		tx := begin()
+		for _, op := range undo[start:end] {
+			op.Apply(tx)
+		}
+		if err := tx.ExecuteWithContext(ctx); err != nil {
+			return done, err
+		}
+		done = end
+	}
+	return done, nil
+}
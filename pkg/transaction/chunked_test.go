@@ -0,0 +1,215 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+type chunkItem struct {
+	ID string `dynamorm:"pk"`
+}
+
+// recordingTx records the operations queued on it; fail makes Execute fail.
+type recordingTx struct {
+	log  *[][]string
+	fail func(ops []string) error
+	ops  []string
+}
+
+func (r *recordingTx) add(kind string, model any) core.TransactionBuilder {
+	r.ops = append(r.ops, fmt.Sprintf("%s %s", kind, model.(*chunkItem).ID))
+	return r
+}
+
+func (r *recordingTx) Put(model any, _ ...core.TransactCondition) core.TransactionBuilder {
+	return r.add("put", model)
+}
+
+func (r *recordingTx) Create(model any, _ ...core.TransactCondition) core.TransactionBuilder {
+	return r.add("create", model)
+}
+
+func (r *recordingTx) Update(model any, _ []string, _ ...core.TransactCondition) core.TransactionBuilder {
+	return r.add("update", model)
+}
+
+func (r *recordingTx) UpdateWithBuilder(model any, _ func(core.UpdateBuilder) error, _ ...core.TransactCondition) core.TransactionBuilder {
+	return r.add("update", model)
+}
+
+func (r *recordingTx) Delete(model any, _ ...core.TransactCondition) core.TransactionBuilder {
+	return r.add("delete", model)
+}
+
+func (r *recordingTx) ConditionCheck(model any, _ ...core.TransactCondition) core.TransactionBuilder {
+	return r.add("check", model)
+}
+
+func (r *recordingTx) WithContext(context.Context) core.TransactionBuilder   { return r }
+func (r *recordingTx) WithClientRequestToken(string) core.TransactionBuilder { return r }
+func (r *recordingTx) Execute() error                                        { return r.ExecuteWithContext(context.Background()) }
+func (r *recordingTx) ExecuteWithContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if r.fail != nil {
+		if err := r.fail(r.ops); err != nil {
+			return err
+		}
+	}
+	*r.log = append(*r.log, r.ops)
+	return nil
+}
+
+func recordingBegin(log *[][]string, fail func(ops []string) error) func() core.TransactionBuilder {
+	return func() core.TransactionBuilder {
+		return &recordingTx{log: log, fail: fail}
+	}
+}
+
+func createOps(n int) []Op {
+	ops := make([]Op, n)
+	for i := range ops {
+		ops[i] = CreateOp(&chunkItem{ID: fmt.Sprintf("i%d", i)})
+	}
+	return ops
+}
+
+func TestRunChunkedCommitsInOrder(t *testing.T) {
+	var log [][]string
+	var progress []ChunkProgress
+	err := RunChunked(context.Background(), recordingBegin(&log, nil), createOps(5), 2,
+		WithChunkProgress(func(p ChunkProgress) { progress = append(progress, p) }))
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"create i0", "create i1"},
+		{"create i2", "create i3"},
+		{"create i4"},
+	}, log)
+	assert.Equal(t, []ChunkProgress{
+		{Chunk: 0, Chunks: 3, Committed: 2, Total: 5},
+		{Chunk: 1, Chunks: 3, Committed: 4, Total: 5},
+		{Chunk: 2, Chunks: 3, Committed: 5, Total: 5},
+	}, progress)
+}
+
+func TestRunChunkedDefaultsToTransactionLimit(t *testing.T) {
+	var log [][]string
+	require.NoError(t, RunChunked(context.Background(), recordingBegin(&log, nil), createOps(60), 0))
+
+	require.Len(t, log, 3)
+	assert.Len(t, log[0], maxTransactOperations)
+	assert.Len(t, log[2], 10)
+}
+
+func TestRunChunkedCompensatesCommittedChunks(t *testing.T) {
+	boom := errors.New("conditional check failed")
+	var log [][]string
+	fail := func(ops []string) error {
+		if ops[0] == "create i4" {
+			return boom
+		}
+		return nil
+	}
+
+	ops := createOps(5)
+	ops[1] = PutOp(&chunkItem{ID: "i1"}) // no undo
+	err := RunChunked(context.Background(), recordingBegin(&log, fail), ops, 2)
+
+	var chunkErr *ChunkError
+	require.ErrorAs(t, err, &chunkErr)
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, 2, chunkErr.Chunk)
+	assert.Equal(t, 4, chunkErr.Committed)
+	assert.Equal(t, 3, chunkErr.Compensated)
+	assert.NoError(t, chunkErr.CompensationErr)
+
+	assert.Equal(t, [][]string{
+		{"create i0", "put i1"},
+		{"create i2", "create i3"},
+		{"delete i3", "delete i2"},
+		{"delete i0"},
+	}, log)
+}
+
+func TestRunChunkedReportsCompensationFailure(t *testing.T) {
+	boom := errors.New("chunk failed")
+	undoFailed := errors.New("undo failed")
+	var log [][]string
+	fail := func(ops []string) error {
+		switch ops[0] {
+		case "create i2":
+			return boom
+		case "delete i0":
+			return undoFailed
+		}
+		return nil
+	}
+
+	err := RunChunked(context.Background(), recordingBegin(&log, fail), createOps(3), 1)
+
+	var chunkErr *ChunkError
+	require.ErrorAs(t, err, &chunkErr)
+	assert.ErrorIs(t, err, boom)
+	assert.ErrorIs(t, err, undoFailed)
+	assert.Equal(t, 1, chunkErr.Compensated)
+	assert.Contains(t, err.Error(), "compensation failed after undoing 1")
+}
+
+func TestRunChunkedCompensatesAfterCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var log [][]string
+	fail := func(ops []string) error {
+		if ops[0] == "create i2" {
+			cancel()
+			return context.Canceled
+		}
+		return nil
+	}
+
+	err := RunChunked(ctx, recordingBegin(&log, fail), createOps(3), 1)
+
+	var chunkErr *ChunkError
+	require.ErrorAs(t, err, &chunkErr)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoError(t, chunkErr.CompensationErr)
+	assert.Equal(t, 2, chunkErr.Compensated)
+	assert.Equal(t, [][]string{{"create i0"}, {"create i1"}, {"delete i1"}, {"delete i0"}}, log)
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	err = RunChunked(ctx, recordingBegin(&log, func(ops []string) error {
+		switch ops[0] {
+		case "create i2":
+			return errors.New("chunk failed")
+		case "delete i1":
+			time.Sleep(5 * time.Millisecond)
+		}
+		return nil
+	}), createOps(3), 1, WithCompensationTimeout(time.Millisecond))
+	require.ErrorAs(t, err, &chunkErr)
+	assert.ErrorIs(t, chunkErr.CompensationErr, context.DeadlineExceeded)
+	assert.Equal(t, 1, chunkErr.Compensated)
+}
+
+func TestRunChunkedValidates(t *testing.T) {
+	assert.Error(t, RunChunked(context.Background(), nil, createOps(1), 1))
+
+	var log [][]string
+	err := RunChunked(context.Background(), recordingBegin(&log, nil), []Op{{}}, 1)
+	assert.Error(t, err)
+	assert.Empty(t, log)
+
+	assert.NoError(t, RunChunked(context.Background(), recordingBegin(&log, nil), nil, 1))
+	assert.Empty(t, log)
+}
@@ -0,0 +1,145 @@
+package transaction
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+)
+
+const (
+	// IdempotencyWindow is how long DynamoDB honors a transaction's ClientRequestToken.
+	IdempotencyWindow = 10 * time.Minute
+
+	maxClientTokenLength = 36
+)
+
+// TokenCache remembers the client request tokens of committed transactions for the
+// idempotency window, so replaying a committed transaction returns without calling
+// DynamoDB. A replay is only skipped when its operations match the committed ones; a
+// token reused for different operations is sent so DynamoDB reports the mismatch.
+type TokenCache struct {
+	now       func() time.Time
+	committed map[string]committedToken
+	lastPrune time.Time
+	window    time.Duration
+	mu        sync.Mutex
+}
+
+type committedToken struct {
+	at     time.Time
+	digest string
+}
+
+// NewTokenCache creates a cache covering DynamoDB's idempotency window.
+func NewTokenCache() *TokenCache {
+	return &TokenCache{
+		now:       time.Now,
+		window:    IdempotencyWindow,
+		committed: make(map[string]committedToken),
+	}
+}
+
+// Committed reports whether a transaction with token committed within the window.
+func (c *TokenCache) Committed(token string) bool {
+	_, ok := c.lookup(token)
+	return ok
+}
+
+// Len returns the number of committed tokens currently remembered.
+func (c *TokenCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.committed)
+}
+
+// replayed reports whether token committed within the window with the same operations.
+func (c *TokenCache) replayed(token, digest string) bool {
+	entry, ok := c.lookup(token)
+	return ok && entry.digest == digest
+}
+
+func (c *TokenCache) lookup(token string) (committedToken, bool) {
+	if c == nil || token == "" {
+		return committedToken{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.committed[token]
+	if !ok || c.now().Sub(entry.at) >= c.window {
+		return committedToken{}, false
+	}
+	return entry, true
+}
+
+func (c *TokenCache) remember(token, digest string) {
+	if c == nil || token == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.pruneLocked(now)
+	c.committed[token] = committedToken{at: now, digest: digest}
+}
+
+func (c *TokenCache) pruneLocked(now time.Time) {
+	if now.Sub(c.lastPrune) < time.Minute {
+		return
+	}
+	c.lastPrune = now
+	for token, entry := range c.committed {
+		if now.Sub(entry.at) >= c.window {
+			delete(c.committed, token)
+		}
+	}
+}
+
+func validateClientToken(token string) error {
+	if token == "" || len(token) > maxClientTokenLength {
+		return fmt.Errorf("client request token must be 1-%d characters", maxClientTokenLength)
+	}
+	return nil
+}
+
+// operationsDigest hashes a transaction's write items. It identifies the transaction
+// when deriving tokens and when checking a replay against the committed operations.
+func operationsDigest(items []types.TransactWriteItem) (string, error) {
+	encoded, err := json.Marshal(items)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash transaction operations: %w", err)
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// resolveClientToken picks the token for a transaction: the explicit token when set,
+// otherwise one derived from the Lambda request ID and the transaction's operations,
+// otherwise a random token. The derived token only uses inputs that are the same when
+// the invocation is retried, on a fresh container or the same warm one. Two identical
+// transactions in one invocation therefore share a token; set an explicit token when
+// both must apply.
+func resolveClientToken(ctx context.Context, explicit, digest string, cache *TokenCache) string {
+	if explicit != "" {
+		return explicit
+	}
+	if cache != nil {
+		if lc, ok := lambdacontext.FromContext(ctx); ok && lc.AwsRequestID != "" {
+			sum := sha256.Sum256([]byte(lc.AwsRequestID + "#" + digest))
+			return hex.EncodeToString(sum[:])[:32]
+		}
+	}
+	return uuid.NewString()
+}
@@ -0,0 +1,195 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+	"github.com/pay-theory/dynamorm/pkg/session"
+	pkgTypes "github.com/pay-theory/dynamorm/pkg/types"
+)
+
+func newTokenTestBuilder(t *testing.T, cache *TokenCache, responses ...error) (*Builder, *mockTransactClient) {
+	t.Helper()
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&User{}))
+
+	client := newMockTransactClient(t, responses...)
+	builder := NewBuilder(nil, registry, pkgTypes.NewConverter()).WithTokenCache(cache)
+	builder.client = client
+	return builder, client
+}
+
+func TestTransactionBuilderClientRequestToken(t *testing.T) {
+	t.Run("explicit token is sent and then cleared", func(t *testing.T) {
+		builder, client := newTokenTestBuilder(t, nil)
+
+		require.NoError(t, builder.WithClientRequestToken("order-42").Put(&User{ID: "u1"}).Execute())
+		require.NoError(t, builder.Put(&User{ID: "u2"}).Execute())
+
+		require.Len(t, client.inputs, 2)
+		assert.Equal(t, "order-42", aws.ToString(client.inputs[0].ClientRequestToken))
+		assert.NotEqual(t, "order-42", aws.ToString(client.inputs[1].ClientRequestToken))
+	})
+
+	t.Run("committed token is not replayed", func(t *testing.T) {
+		cache := NewTokenCache()
+		builder, client := newTokenTestBuilder(t, cache)
+
+		require.NoError(t, builder.WithClientRequestToken("order-42").Put(&User{ID: "u1"}).Execute())
+		require.NoError(t, builder.WithClientRequestToken("order-42").Put(&User{ID: "u1"}).Execute())
+
+		assert.Equal(t, 1, client.callCount)
+		assert.True(t, cache.Committed("order-42"))
+	})
+
+	t.Run("committed token reused for different operations is sent", func(t *testing.T) {
+		cache := NewTokenCache()
+		builder, client := newTokenTestBuilder(t, cache, nil, &types.IdempotentParameterMismatchException{Message: aws.String("mismatch")})
+
+		require.NoError(t, builder.WithClientRequestToken("order-42").Put(&User{ID: "u1"}).Execute())
+		err := builder.WithClientRequestToken("order-42").Put(&User{ID: "u2"}).Execute()
+
+		require.ErrorIs(t, err, customerrors.ErrTransactionFailed)
+		assert.Equal(t, 2, client.callCount)
+	})
+
+	t.Run("lambda request id derives stable tokens", func(t *testing.T) {
+		ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "req-1"})
+
+		first, firstClient := newTokenTestBuilder(t, NewTokenCache())
+		require.NoError(t, first.Put(&User{ID: "u1"}).ExecuteWithContext(ctx))
+		require.NoError(t, first.Put(&User{ID: "u2"}).ExecuteWithContext(ctx))
+
+		// A retried invocation on a fresh container derives the same tokens.
+		retry, retryClient := newTokenTestBuilder(t, NewTokenCache())
+		require.NoError(t, retry.Put(&User{ID: "u1"}).ExecuteWithContext(ctx))
+
+		firstToken := aws.ToString(firstClient.inputs[0].ClientRequestToken)
+		assert.Len(t, firstToken, 32)
+		assert.NotEqual(t, firstToken, aws.ToString(firstClient.inputs[1].ClientRequestToken))
+		assert.Equal(t, firstToken, aws.ToString(retryClient.inputs[0].ClientRequestToken))
+
+		other := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "req-2"})
+		require.NoError(t, retry.Put(&User{ID: "u1"}).ExecuteWithContext(other))
+		assert.NotEqual(t, firstToken, aws.ToString(retryClient.inputs[1].ClientRequestToken))
+	})
+
+	t.Run("lambda retry on the same container reuses the token", func(t *testing.T) {
+		ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "req-1"})
+
+		// The first attempt's response is lost, so the commit is not remembered. The
+		// retried invocation builds the transaction again on the same warm container.
+		cache := NewTokenCache()
+		first, client := newTokenTestBuilder(t, cache, errors.New("connection reset"))
+		require.Error(t, first.Put(&User{ID: "u1"}).ExecuteWithContext(ctx))
+
+		retry := NewBuilder(nil, first.registry, pkgTypes.NewConverter()).WithTokenCache(cache)
+		retry.client = client
+		require.NoError(t, retry.Put(&User{ID: "u1"}).ExecuteWithContext(ctx))
+
+		require.Len(t, client.inputs, 2)
+		assert.Equal(t, aws.ToString(client.inputs[0].ClientRequestToken), aws.ToString(client.inputs[1].ClientRequestToken))
+	})
+
+	t.Run("mismatched parameters are reported", func(t *testing.T) {
+		builder, _ := newTokenTestBuilder(t, nil, &types.IdempotentParameterMismatchException{Message: aws.String("mismatch")})
+
+		err := builder.WithClientRequestToken("order-42").Put(&User{ID: "u1"}).Execute()
+		require.ErrorIs(t, err, customerrors.ErrTransactionFailed)
+		assert.Contains(t, err.Error(), "already used for a different transaction")
+	})
+
+	t.Run("token length is validated", func(t *testing.T) {
+		builder, _ := newTokenTestBuilder(t, nil)
+
+		err := builder.WithClientRequestToken("").Put(&User{ID: "u1"}).Execute()
+		assert.ErrorContains(t, err, "client request token must be 1-36 characters")
+	})
+}
+
+func TestTokenCacheExpiresAfterWindow(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := NewTokenCache()
+	cache.now = func() time.Time { return now }
+
+	cache.remember("tok", "ops")
+	assert.True(t, cache.Committed("tok"))
+	assert.True(t, cache.replayed("tok", "ops"))
+	assert.False(t, cache.replayed("tok", "other-ops"))
+
+	now = now.Add(IdempotencyWindow)
+	assert.False(t, cache.Committed("tok"))
+
+	cache.remember("other", "ops")
+	assert.NotContains(t, cache.committed, "tok", "expired tokens are pruned")
+
+	var nilCache *TokenCache
+	assert.False(t, nilCache.Committed("tok"))
+}
+
+type tokenRecordingHTTPClient struct {
+	tokens []string
+}
+
+func (c *tokenRecordingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	var payload struct{ ClientRequestToken string }
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+	c.tokens = append(c.tokens, payload.ClientRequestToken)
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+		Body:       io.NopCloser(bytes.NewReader([]byte(`{}`))),
+		Request:    req,
+	}, nil
+}
+
+func TestTransactionCommitClientRequestToken(t *testing.T) {
+	httpClient := &tokenRecordingHTTPClient{}
+	stubSessionConfigLoad(t, func(context.Context, ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return minimalAWSConfig(httpClient), nil
+	})
+	sess, err := session.NewSession(&session.Config{Region: "us-east-1"})
+	require.NoError(t, err)
+
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&unitUser{}))
+	cache := NewTokenCache()
+
+	commit := func() error {
+		tx := NewTransaction(sess, registry, pkgTypes.NewConverter()).
+			WithTokenCache(cache).
+			WithClientRequestToken("payment-7")
+		require.NoError(t, tx.Create(&unitUser{ID: "user-1"}))
+		return tx.Commit()
+	}
+
+	require.NoError(t, commit())
+	require.NoError(t, commit(), "a replay within the window is a no-op")
+	assert.Equal(t, []string{"payment-7"}, httpClient.tokens)
+
+	tx := NewTransaction(sess, registry, pkgTypes.NewConverter()).WithClientRequestToken("this-token-is-far-too-long-for-dynamodb")
+	require.NoError(t, tx.Create(&unitUser{ID: "user-2"}))
+	assert.ErrorContains(t, tx.Commit(), "client request token must be 1-36 characters")
+}
@@ -4,8 +4,8 @@ package transaction
 import (
 	"context"
 	"fmt"
-	"io"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -26,7 +26,12 @@ type Transaction struct {
 	session   *session.Session
 	registry  *model.Registry
 	converter *pkgTypes.Converter
+	tokens    *TokenCache
+	onWrite   func(context.Context, []types.TransactWriteItem)
+	guard     func(table string, write bool) error
+	itemGuard func(item *types.TransactWriteItem, metadata *model.Metadata) error
 	results   map[string]map[string]types.AttributeValue
+	token     string
 	writes    []types.TransactWriteItem
 	reads     []types.TransactGetItem
 }
@@ -50,12 +55,62 @@ func (tx *Transaction) WithContext(ctx context.Context) *Transaction {
 	return tx
 }
 
+// WithClientRequestToken sets the ClientRequestToken sent with the writes on Commit,
+// making a retried Commit idempotent for DynamoDB's ten-minute window
+func (tx *Transaction) WithClientRequestToken(token string) *Transaction {
+	tx.token = token
+	return tx
+}
+
+// WithTokenCache skips a Commit whose token already committed with the same writes
+// within the idempotency window and enables tokens derived from the Lambda request ID
+func (tx *Transaction) WithTokenCache(cache *TokenCache) *Transaction {
+	tx.tokens = cache
+	return tx
+}
+
+// WithWriteObserver calls fn with the writes Commit sends, after DynamoDB responds and
+// whether or not they committed
+func (tx *Transaction) WithWriteObserver(fn func(ctx context.Context, items []types.TransactWriteItem)) *Transaction {
+	tx.onWrite = fn
+	return tx
+}
+
+// WithTableGuard calls fn with the table of every write and read before Commit sends
+// them, and returns its first error instead of sending them.
+func (tx *Transaction) WithTableGuard(fn func(table string, write bool) error) *Transaction {
+	tx.guard = fn
+	return tx
+}
+
+// WithItemGuard calls fn with every write, and the metadata of the model it writes, as
+// Create, Update, or Delete adds it, and returns its error instead of adding it. fn may
+// change the item, as by adding to its condition.
+func (tx *Transaction) WithItemGuard(fn func(item *types.TransactWriteItem, metadata *model.Metadata) error) *Transaction {
+	tx.itemGuard = fn
+	return tx
+}
+
+// addWrite adds item, a write of the model of metadata, once the item guard allows it.
+func (tx *Transaction) addWrite(item types.TransactWriteItem, metadata *model.Metadata) error {
+	if tx.itemGuard != nil {
+		if err := tx.itemGuard(&item, metadata); err != nil {
+			return err
+		}
+	}
+	tx.writes = append(tx.writes, item)
+	return nil
+}
+
 // Create adds a create operation to the transaction
 func (tx *Transaction) Create(model any) error {
 	metadata, err := tx.registry.GetMetadata(model)
 	if err != nil {
 		return fmt.Errorf("failed to get model metadata: %w", err)
 	}
+	if err := rejectUniqueFields(metadata); err != nil {
+		return err
+	}
 
 	// Marshal item
 	item, err := tx.marshalItem(model, metadata)
@@ -70,16 +125,14 @@ func (tx *Transaction) Create(model any) error {
 	}
 
 	// Add to transaction
-	tx.writes = append(tx.writes, types.TransactWriteItem{
+	return tx.addWrite(types.TransactWriteItem{
 		Put: &types.Put{
 			TableName:                aws.String(metadata.TableName),
 			Item:                     item,
 			ConditionExpression:      aws.String(conditionExpression),
 			ExpressionAttributeNames: expressionAttributeNames,
 		},
-	})
-
-	return nil
+	}, metadata)
 }
 
 // Update adds an update operation to the transaction
@@ -88,6 +141,9 @@ func (tx *Transaction) Update(model any) error {
 	if err != nil {
 		return fmt.Errorf("failed to get model metadata: %w", err)
 	}
+	if err := rejectUniqueFields(metadata); err != nil {
+		return err
+	}
 
 	if encryptionErr := encryption.FailClosedIfEncryptedWithoutKMSKeyARN(tx.session, metadata); encryptionErr != nil {
 		return encryptionErr
@@ -103,7 +159,7 @@ func (tx *Transaction) Update(model any) error {
 		modelValue = modelValue.Elem()
 	}
 
-	updateExpression, expressionAttributeNames, expressionAttributeValues, err := tx.buildUpdateExpression(modelValue, metadata)
+	updateExpression, removeExpression, expressionAttributeNames, expressionAttributeValues, err := tx.buildUpdateExpression(modelValue, metadata)
 	if err != nil {
 		return err
 	}
@@ -118,20 +174,12 @@ func (tx *Transaction) Update(model any) error {
 	if err := tx.applyUpdatedAtUpdate(modelValue, metadata, &updateExpression, expressionAttributeNames, expressionAttributeValues); err != nil {
 		return err
 	}
+	updateExpression += removeExpression
 
 	if encryption.MetadataHasEncryptedFields(metadata) && len(expressionAttributeValues) > 0 {
-		cfg := tx.session.Config()
-		keyARN := ""
-		var rng io.Reader
-		if cfg != nil {
-			keyARN = cfg.KMSKeyARN
-			rng = cfg.EncryptionRand
-		}
-		var svc *encryption.Service
-		if cfg != nil && cfg.KMSClient != nil {
-			svc = encryption.NewServiceWithRand(keyARN, cfg.KMSClient, rng)
-		} else {
-			svc = encryption.NewServiceFromAWSConfigWithRand(keyARN, tx.session.AWSConfig(), rng)
+		svc, err := encryption.NewServiceForSession(tx.session)
+		if err != nil {
+			return err
 		}
 		if err := encryption.EncryptUpdateExpressionValues(tx.ctx, svc, metadata, updateExpression, expressionAttributeNames, expressionAttributeValues); err != nil {
 			return err
@@ -151,17 +199,18 @@ func (tx *Transaction) Update(model any) error {
 	}
 
 	// Add to transaction
-	tx.writes = append(tx.writes, types.TransactWriteItem{
+	return tx.addWrite(types.TransactWriteItem{
 		Update: updateItem,
-	})
-
-	return nil
+	}, metadata)
 }
 
-func (tx *Transaction) buildUpdateExpression(modelValue reflect.Value, metadata *model.Metadata) (string, map[string]string, map[string]types.AttributeValue, error) {
+// buildUpdateExpression returns the SET clause for the model's fields, and a REMOVE
+// clause, empty or starting with a space, for its nil allownull fields.
+func (tx *Transaction) buildUpdateExpression(modelValue reflect.Value, metadata *model.Metadata) (string, string, map[string]string, map[string]types.AttributeValue, error) {
 	updateExpression := "SET "
 	expressionAttributeNames := make(map[string]string)
 	expressionAttributeValues := make(map[string]types.AttributeValue)
+	var removes []string
 
 	updateCount := 0
 	for fieldName, fieldMeta := range metadata.Fields {
@@ -170,6 +219,21 @@ func (tx *Transaction) buildUpdateExpression(modelValue reflect.Value, metadata
 		}
 
 		fieldValue := modelValue.FieldByIndex(fieldMeta.IndexPath)
+		if fieldMeta.HasKeyTemplate() {
+			value, err := metadata.KeyValue(fieldMeta, modelValue)
+			if err != nil {
+				return "", "", nil, nil, err
+			}
+			if value != "" {
+				fieldValue = reflect.ValueOf(fieldMeta.StoredKey(value))
+			}
+		}
+		if fieldValue.IsValid() && fieldMeta.AllowNull && fieldValue.IsNil() {
+			attrName := fmt.Sprintf("#r%d", len(removes))
+			expressionAttributeNames[attrName] = fieldMeta.DBName
+			removes = append(removes, attrName)
+			continue
+		}
 		if !fieldValue.IsValid() || (fieldMeta.OmitEmpty && reflectutil.IsEmpty(fieldValue)) {
 			continue
 		}
@@ -184,7 +248,7 @@ func (tx *Transaction) buildUpdateExpression(modelValue reflect.Value, metadata
 		expressionAttributeNames[attrName] = fieldMeta.DBName
 		av, err := tx.converter.ToAttributeValue(fieldValue.Interface())
 		if err != nil {
-			return "", nil, nil, fmt.Errorf("failed to convert field %s: %w", fieldName, err)
+			return "", "", nil, nil, fmt.Errorf("failed to convert field %s: %w", fieldName, err)
 		}
 		expressionAttributeValues[attrValue] = av
 
@@ -192,7 +256,11 @@ func (tx *Transaction) buildUpdateExpression(modelValue reflect.Value, metadata
 		updateCount++
 	}
 
-	return updateExpression, expressionAttributeNames, expressionAttributeValues, nil
+	removeExpression := ""
+	if len(removes) > 0 {
+		removeExpression = " REMOVE " + strings.Join(removes, ", ")
+	}
+	return updateExpression, removeExpression, expressionAttributeNames, expressionAttributeValues, nil
 }
 
 func (tx *Transaction) applyVersionUpdate(
@@ -270,6 +338,9 @@ func (tx *Transaction) Delete(model any) error {
 	if err != nil {
 		return fmt.Errorf("failed to get model metadata: %w", err)
 	}
+	if err := rejectUniqueFields(metadata); err != nil {
+		return err
+	}
 
 	// Extract primary key
 	key, err := tx.extractPrimaryKey(model, metadata)
@@ -308,11 +379,9 @@ func (tx *Transaction) Delete(model any) error {
 	}
 
 	// Add to transaction
-	tx.writes = append(tx.writes, types.TransactWriteItem{
+	return tx.addWrite(types.TransactWriteItem{
 		Delete: deleteItem,
-	})
-
-	return nil
+	}, metadata)
 }
 
 // Get adds a get operation to the transaction
@@ -347,20 +416,42 @@ func (tx *Transaction) Get(model any, dest any) error {
 
 // Commit executes the transaction
 func (tx *Transaction) Commit() error {
+	if err := tx.checkGuard(); err != nil {
+		return err
+	}
+
 	// Execute writes if any
 	if len(tx.writes) > 0 {
-		input := &dynamodb.TransactWriteItemsInput{
-			TransactItems: tx.writes,
+		if tx.token != "" {
+			if err := validateClientToken(tx.token); err != nil {
+				return err
+			}
 		}
-
-		client, err := tx.session.Client()
+		digest, err := operationsDigest(tx.writes)
 		if err != nil {
-			return fmt.Errorf("failed to get client for transaction commit: %w", err)
+			return err
 		}
+		token := resolveClientToken(tx.ctx, tx.token, digest, tx.tokens)
 
-		_, err = client.TransactWriteItems(tx.ctx, input)
-		if err != nil {
-			return tx.handleTransactionError(err)
+		if !tx.tokens.replayed(token, digest) {
+			input := &dynamodb.TransactWriteItemsInput{
+				TransactItems:      tx.writes,
+				ClientRequestToken: aws.String(token),
+			}
+
+			client, err := tx.session.Client()
+			if err != nil {
+				return fmt.Errorf("failed to get client for transaction commit: %w", err)
+			}
+
+			_, err = client.TransactWriteItems(tx.ctx, input)
+			if tx.onWrite != nil {
+				tx.onWrite(tx.ctx, tx.writes)
+			}
+			if err != nil {
+				return tx.handleTransactionError(err)
+			}
+			tx.tokens.remember(token, digest)
 		}
 	}
 
@@ -419,6 +510,8 @@ func (tx *Transaction) handleTransactionError(err error) error {
 		return fmt.Errorf("transaction canceled: %w", err)
 	case contains(errStr, "ValidationException"):
 		return fmt.Errorf("validation error: %w", err)
+	case contains(errStr, "IdempotentParameterMismatch"):
+		return fmt.Errorf("%w: client request token was already used for a different transaction: %w", errors.ErrTransactionFailed, err)
 	default:
 		return err
 	}
@@ -453,8 +546,8 @@ func (tx *Transaction) marshalPlainItem(model any, metadata *model.Metadata) (ma
 	for fieldName, fieldMeta := range metadata.Fields {
 		fieldValue := modelValue.Field(fieldMeta.Index)
 
-		// Skip zero values if omitempty
-		if fieldMeta.OmitEmpty && fieldValue.IsZero() {
+		// Skip zero values if omitempty; a nil allownull field is written as NULL
+		if fieldMeta.OmitEmpty && !(fieldMeta.AllowNull && fieldValue.IsNil()) && fieldValue.IsZero() {
 			continue
 		}
 
@@ -472,6 +565,16 @@ func (tx *Transaction) marshalPlainItem(model any, metadata *model.Metadata) (ma
 		item[fieldMeta.DBName] = av
 	}
 
+	if metadata.EntityType != "" {
+		item[metadata.EntityAttribute] = &types.AttributeValueMemberS{Value: metadata.EntityType}
+	}
+	if err := metadata.ApplyKeyTemplates(modelValue, item); err != nil {
+		return nil, err
+	}
+	if err := metadata.ApplyEnums(item); err != nil {
+		return nil, err
+	}
+
 	return item, nil
 }
 
@@ -480,41 +583,23 @@ func (tx *Transaction) encryptItemIfNeeded(metadata *model.Metadata, item map[st
 		return nil
 	}
 
-	cfg := tx.session.Config()
-	keyARN := ""
-	var rng io.Reader
-	if cfg != nil {
-		keyARN = cfg.KMSKeyARN
-		rng = cfg.EncryptionRand
+	blindIndexKey, err := encryption.BlindIndexKey(tx.session, metadata)
+	if err != nil {
+		return err
+	}
+	if err := encryption.ApplyBlindIndexes(blindIndexKey, metadata, item); err != nil {
+		return err
 	}
-	var svc *encryption.Service
-	if cfg != nil && cfg.KMSClient != nil {
-		svc = encryption.NewServiceWithRand(keyARN, cfg.KMSClient, rng)
-	} else {
-		svc = encryption.NewServiceFromAWSConfigWithRand(keyARN, tx.session.AWSConfig(), rng)
+
+	svc, err := encryption.NewServiceForSession(tx.session)
+	if err != nil {
+		return err
 	}
 	ctx := tx.ctx
 	if ctx == nil {
 		ctx = context.Background()
 	}
-
-	for _, fieldMeta := range metadata.Fields {
-		if fieldMeta == nil || !fieldMeta.IsEncrypted {
-			continue
-		}
-		av, ok := item[fieldMeta.DBName]
-		if !ok {
-			continue
-		}
-
-		encryptedAV, err := svc.EncryptAttributeValue(ctx, fieldMeta.DBName, av)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt field %s: %w", fieldMeta.DBName, err)
-		}
-		item[fieldMeta.DBName] = encryptedAV
-	}
-
-	return nil
+	return svc.EncryptItem(ctx, metadata, item)
 }
 
 // extractPrimaryKey extracts the primary key from a model
@@ -528,7 +613,10 @@ func (tx *Transaction) extractPrimaryKey(model any, metadata *model.Metadata) (m
 
 	// Extract partition key
 	pkField := metadata.PrimaryKey.PartitionKey
-	pkValue := modelValue.Field(pkField.Index)
+	pkValue, err := keyFieldValue(metadata, pkField, modelValue)
+	if err != nil {
+		return nil, err
+	}
 	if pkValue.IsZero() {
 		return nil, fmt.Errorf("partition key %s is empty", pkField.Name)
 	}
@@ -542,7 +630,10 @@ func (tx *Transaction) extractPrimaryKey(model any, metadata *model.Metadata) (m
 	// Extract sort key if present
 	if metadata.PrimaryKey.SortKey != nil {
 		skField := metadata.PrimaryKey.SortKey
-		skValue := modelValue.Field(skField.Index)
+		skValue, err := keyFieldValue(metadata, skField, modelValue)
+		if err != nil {
+			return nil, err
+		}
 		if skValue.IsZero() {
 			return nil, fmt.Errorf("sort key %s is empty", skField.Name)
 		}
@@ -557,7 +648,42 @@ func (tx *Transaction) extractPrimaryKey(model any, metadata *model.Metadata) (m
 	return key, nil
 }
 
+// keyFieldValue returns the value of a key field as it is stored, with the prefix and
+// components of a key template applied.
+func keyFieldValue(metadata *model.Metadata, field *model.FieldMetadata, modelValue reflect.Value) (reflect.Value, error) {
+	if !field.HasKeyTemplate() {
+		return modelValue.Field(field.Index), nil
+	}
+	value, err := metadata.KeyValue(field, modelValue)
+	if err != nil || value == "" {
+		return reflect.ValueOf(""), err
+	}
+	return reflect.ValueOf(field.StoredKey(value)), nil
+}
+
 // contains checks if a string contains a substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && (s[0:len(substr)] == substr || contains(s[1:], substr)))
 }
+
+// checkGuard runs the table guard over the transaction's writes and reads.
+func (tx *Transaction) checkGuard() error {
+	if tx.guard == nil {
+		return nil
+	}
+	for _, item := range tx.writes {
+		table, _ := transactItemTarget(item)
+		if err := tx.guard(table, true); err != nil {
+			return err
+		}
+	}
+	for _, item := range tx.reads {
+		if item.Get == nil {
+			continue
+		}
+		if err := tx.guard(aws.ToString(item.Get.TableName), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/pay-theory/dynamorm/pkg/contention"
 	"github.com/pay-theory/dynamorm/pkg/core"
 	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
 	"github.com/pay-theory/dynamorm/pkg/model"
@@ -497,6 +498,31 @@ func TestTransactionBuilderRetriesOnConflict(t *testing.T) {
 	assert.Equal(t, 2, mockClient.callCount)
 }
 
+func TestTransactionBuilderRecordsContention(t *testing.T) {
+	registry := model.NewRegistry()
+	require.NoError(t, registry.Register(&User{}))
+	converter := pkgTypes.NewConverter()
+
+	conflict := &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{
+			{},
+			{Code: aws.String("TransactionConflict")},
+		},
+	}
+
+	tracker := contention.NewTracker()
+	builder := NewBuilder(nil, registry, converter).WithContentionTracker(tracker)
+	builder.client = newMockTransactClient(t, conflict, nil)
+
+	err := builder.Put(&User{ID: "calm"}).Put(&User{ID: "hot"}).Execute()
+	require.NoError(t, err)
+
+	report := tracker.Report(0)
+	require.Len(t, report, 1)
+	assert.Equal(t, "hot", report[0].KeyPrefix)
+	assert.Equal(t, int64(1), report[0].TransactionConflicts)
+}
+
 func TestTransactionBuilderOperationLimit(t *testing.T) {
 	registry := model.NewRegistry()
 	require.NoError(t, registry.Register(&User{}))
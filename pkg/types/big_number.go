@@ -0,0 +1,193 @@
+package types
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDB numbers hold up to 38 significant digits, more than float64 keeps. Fields of
+// these types are stored as exact N values instead of passing through float64:
+//
+//	Total    decimal.Decimal // github.com/shopspring/decimal
+//	Balance  *big.Int
+//	Rate     *big.Rat        // must be a terminating decimal, such as 1/8
+//	Estimate *big.Float
+//
+// Decimal types are recognized by name, so DynamORM does not depend on their packages.
+// Other number types can implement Marshaler and Unmarshaler.
+var (
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+	bigRatType   = reflect.TypeOf(big.Rat{})
+
+	// decimalTypes are the decimal types, by package path and name, encoded through
+	// their MarshalText and UnmarshalText methods.
+	decimalTypes = map[string]bool{
+		"github.com/shopspring/decimal.Decimal": true,
+	}
+)
+
+// IsBigNumber reports whether typ, or the type it points to, is a big or decimal number
+// type stored as an exact DynamoDB number.
+func IsBigNumber(typ reflect.Type) bool {
+	if typ == nil {
+		return false
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	switch typ {
+	case bigIntType, bigFloatType, bigRatType:
+		return true
+	}
+	return isDecimalType(typ)
+}
+
+func isDecimalType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && decimalTypes[typ.PkgPath()+"."+typ.Name()]
+}
+
+// MarshalBigNumber returns value as an exact N value when it is a big or decimal
+// number, reporting false for other types.
+func MarshalBigNumber(value any) (types.AttributeValue, bool, error) {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || !IsBigNumber(v.Type()) {
+		return nil, false, nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return &types.AttributeValueMemberNULL{Value: true}, true, nil
+		}
+		v = v.Elem()
+	}
+	av, _, err := marshalBigNumber(v)
+	return av, true, err
+}
+
+// UnmarshalBigNumber decodes av into target, a pointer to a big or decimal number,
+// reporting false for other targets. Non-integers stored in a big.Int are truncated.
+func UnmarshalBigNumber(av types.AttributeValue, target any) (bool, error) {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || !IsBigNumber(v.Type().Elem()) {
+		return false, nil
+	}
+	if _, ok := av.(*types.AttributeValueMemberNULL); ok {
+		return true, nil
+	}
+	var c Converter
+	return true, c.setBigNumber(av, ensureSettableConcreteTarget(v.Elem()))
+}
+
+// marshalBigNumber formats v, which must not be a pointer, without losing digits.
+func marshalBigNumber(v reflect.Value) (types.AttributeValue, bool, error) {
+	if !IsBigNumber(v.Type()) {
+		return nil, false, nil
+	}
+	if !v.CanAddr() {
+		addressable := reflect.New(v.Type()).Elem()
+		addressable.Set(v)
+		v = addressable
+	}
+
+	var text string
+	switch n := v.Addr().Interface().(type) {
+	case *big.Int:
+		text = n.String()
+	case *big.Float:
+		if n.IsInf() {
+			return nil, true, fmt.Errorf("cannot store infinite %s as a number", v.Type())
+		}
+		text = n.Text('f', -1)
+	case *big.Rat:
+		digits, ok := terminatingDigits(n)
+		if !ok {
+			return nil, true, fmt.Errorf("cannot store %s exactly: it is not a terminating decimal", n.RatString())
+		}
+		text = n.FloatString(digits)
+	default:
+		marshaler, ok := v.Interface().(encoding.TextMarshaler)
+		if !ok {
+			return nil, true, fmt.Errorf("decimal type %s does not implement encoding.TextMarshaler", v.Type())
+		}
+		b, err := marshaler.MarshalText()
+		if err != nil {
+			return nil, true, err
+		}
+		text = string(b)
+	}
+	return &types.AttributeValueMemberN{Value: text}, true, nil
+}
+
+// terminatingDigits returns how many decimal places r needs, or false when its decimal
+// expansion repeats forever.
+func terminatingDigits(r *big.Rat) (int, bool) {
+	denom := new(big.Int).Set(r.Denom())
+	two, five, rem := big.NewInt(2), big.NewInt(5), new(big.Int)
+	twos, fives := 0, 0
+	for denom.Cmp(big.NewInt(1)) != 0 {
+		switch {
+		case rem.Mod(denom, two).Sign() == 0:
+			denom.Quo(denom, two)
+			twos++
+		case rem.Mod(denom, five).Sign() == 0:
+			denom.Quo(denom, five)
+			fives++
+		default:
+			return 0, false
+		}
+	}
+	return max(twos, fives), true
+}
+
+// setBigNumber decodes an N value, or a numeric S value written before the field became
+// a number type, into target.
+func (c *Converter) setBigNumber(av types.AttributeValue, target reflect.Value) error {
+	var n string
+	switch v := av.(type) {
+	case *types.AttributeValueMemberN:
+		n = v.Value
+	case *types.AttributeValueMemberS:
+		n = v.Value
+	default:
+		return fmt.Errorf("cannot convert %T to %s", av, target.Type())
+	}
+	return c.setBigNumberString(n, target)
+}
+
+func (c *Converter) setBigNumberString(n string, target reflect.Value) error {
+	switch dst := target.Addr().Interface().(type) {
+	case *big.Int:
+		r, ok := new(big.Rat).SetString(n)
+		if !ok {
+			return fmt.Errorf("invalid number: %q", n)
+		}
+		if !r.IsInt() && c.NumberPolicy() == NumberStrict {
+			return numberRangeError(n, target, "is not an integer")
+		}
+		dst.Quo(r.Num(), r.Denom())
+	case *big.Float:
+		// Keep a precision the field already has; otherwise allow about four bits per
+		// digit so no digit of the stored value is lost.
+		if dst.Prec() == 0 {
+			dst.SetPrec(max(64, uint(len(n))*4))
+		}
+		if _, ok := dst.SetString(n); !ok {
+			return fmt.Errorf("invalid number: %q", n)
+		}
+	case *big.Rat:
+		if _, ok := dst.SetString(n); !ok {
+			return fmt.Errorf("invalid number: %q", n)
+		}
+	case encoding.TextUnmarshaler:
+		if err := dst.UnmarshalText([]byte(n)); err != nil {
+			return fmt.Errorf("invalid number: %w", err)
+		}
+	default:
+		return fmt.Errorf("decimal type %s does not implement encoding.TextUnmarshaler", target.Type())
+	}
+	return nil
+}
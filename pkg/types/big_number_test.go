@@ -0,0 +1,98 @@
+package types
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// testDecimal stands in for shopspring's decimal.Decimal, which encodes through text.
+type testDecimal struct {
+	text string
+}
+
+func (d testDecimal) MarshalText() ([]byte, error) { return []byte(d.text), nil }
+
+func (d *testDecimal) UnmarshalText(b []byte) error {
+	d.text = string(b)
+	return nil
+}
+
+func TestConverterBigNumbers(t *testing.T) {
+	c := NewConverter()
+	huge, _ := new(big.Int).SetString("-123456789012345678901234567890123456", 10)
+
+	t.Run("big.Int round trips every digit", func(t *testing.T) {
+		require.True(t, c.HasCustomConverter(reflect.TypeOf(huge)))
+		av, err := c.ToAttributeValue(huge)
+		require.NoError(t, err)
+		assert.Equal(t, &types.AttributeValueMemberN{Value: "-123456789012345678901234567890123456"}, av)
+
+		var out *big.Int
+		require.NoError(t, c.FromAttributeValue(av, &out))
+		assert.Zero(t, huge.Cmp(out))
+	})
+
+	t.Run("big.Rat stores terminating decimals", func(t *testing.T) {
+		av, err := c.ToAttributeValue(*big.NewRat(-1234567, 800))
+		require.NoError(t, err)
+		assert.Equal(t, &types.AttributeValueMemberN{Value: "-1543.20875"}, av)
+
+		var out big.Rat
+		require.NoError(t, c.FromAttributeValue(av, &out))
+		assert.Equal(t, "-1234567/800", out.RatString())
+
+		_, err = c.ToAttributeValue(big.NewRat(1, 3))
+		assert.ErrorContains(t, err, "not a terminating decimal")
+	})
+
+	t.Run("big.Float keeps digits beyond float64", func(t *testing.T) {
+		var out big.Float
+		require.NoError(t, c.FromAttributeValue(&types.AttributeValueMemberN{Value: "0.1000000000000000000000000001"}, &out))
+		av, err := c.ToAttributeValue(&out)
+		require.NoError(t, err)
+		assert.Equal(t, &types.AttributeValueMemberN{Value: "0.1000000000000000000000000001"}, av)
+	})
+
+	t.Run("decimal types encode through text", func(t *testing.T) {
+		decimalTypes["github.com/pay-theory/dynamorm/pkg/types.testDecimal"] = true
+		t.Cleanup(func() { delete(decimalTypes, "github.com/pay-theory/dynamorm/pkg/types.testDecimal") })
+
+		av, err := c.ToAttributeValue(testDecimal{text: "19.99"})
+		require.NoError(t, err)
+		assert.Equal(t, &types.AttributeValueMemberN{Value: "19.99"}, av)
+
+		var out testDecimal
+		require.NoError(t, c.FromAttributeValue(&types.AttributeValueMemberS{Value: "0.30"}, &out))
+		assert.Equal(t, "0.30", out.text)
+	})
+
+	t.Run("number sets", func(t *testing.T) {
+		av, err := c.ConvertToSet([]*big.Int{big.NewInt(1), huge}, true)
+		require.NoError(t, err)
+		assert.Equal(t, &types.AttributeValueMemberNS{Value: []string{"1", huge.String()}}, av)
+
+		var out []*big.Int
+		require.NoError(t, c.FromAttributeValue(av, &out))
+		require.Len(t, out, 2)
+		assert.Zero(t, huge.Cmp(out[1]))
+	})
+
+	t.Run("strict policy rejects fractions in big.Int", func(t *testing.T) {
+		strict := NewConverter()
+		strict.SetNumberPolicy(NumberStrict)
+		var out big.Int
+		err := strict.FromAttributeValue(&types.AttributeValueMemberN{Value: "2.5"}, &out)
+		var rangeErr *customerrors.NumberRangeError
+		require.ErrorAs(t, err, &rangeErr)
+
+		require.NoError(t, c.FromAttributeValue(&types.AttributeValueMemberN{Value: "-2.5"}, &out))
+		assert.Equal(t, "-2", out.String())
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,6 +19,8 @@ import (
 type Converter struct {
 	// customConverters allows registration of custom type converters
 	customConverters map[reflect.Type]CustomConverter
+	numberPolicy     NumberPolicy
+	timePolicy       TimePolicy
 	mu               sync.RWMutex
 }
 
@@ -32,6 +35,26 @@ type CustomConverter interface {
 	FromAttributeValue(av types.AttributeValue, target any) error
 }
 
+// Marshaler is implemented by field types that choose their own AttributeValue, such
+// as money or decimal types stored as exact numbers. Its method matches the AWS SDK's
+// attributevalue.Marshaler, so types written for the SDK work unchanged. Registered
+// custom converters take precedence.
+type Marshaler interface {
+	MarshalDynamoDBAttributeValue() (types.AttributeValue, error)
+}
+
+// Unmarshaler is implemented, usually on a pointer receiver, by field types that decode
+// their own AttributeValue. Its method matches the AWS SDK's attributevalue.Unmarshaler.
+// NULL values leave the field at its zero value without calling it.
+type Unmarshaler interface {
+	UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error
+}
+
+var (
+	marshalerType   = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+)
+
 // NewConverter creates a new type converter
 func NewConverter() *Converter {
 	return &Converter{
@@ -49,10 +72,45 @@ func (c *Converter) RegisterConverter(typ reflect.Type, converter CustomConverte
 	c.customConverters[typ] = converter
 }
 
-// HasCustomConverter returns true if a custom converter exists for the given type.
+// HasCustomConverter returns true if a custom converter exists for the given type, the
+// type implements Marshaler, or it is a big or decimal number, meaning ToAttributeValue
+// decides its representation rather than the default rules.
 func (c *Converter) HasCustomConverter(typ reflect.Type) bool {
-	_, ok := c.lookupConverter(typ)
-	return ok
+	if _, ok := c.lookupConverter(typ); ok {
+		return true
+	}
+	return implementsMarshaler(typ) || IsBigNumber(typ)
+}
+
+// implementsMarshaler reports whether typ, its pointer, or the type it points to
+// implements Marshaler.
+func implementsMarshaler(typ reflect.Type) bool {
+	if typ == nil {
+		return false
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Implements(marshalerType) || reflect.PointerTo(typ).Implements(marshalerType)
+}
+
+// marshalWithMarshaler returns v's own AttributeValue when its type implements
+// Marshaler, reporting false otherwise. v must not be a pointer.
+func marshalWithMarshaler(v reflect.Value) (types.AttributeValue, bool, error) {
+	if v.Type().Implements(marshalerType) {
+		av, err := v.Interface().(Marshaler).MarshalDynamoDBAttributeValue()
+		return av, true, err
+	}
+	if !reflect.PointerTo(v.Type()).Implements(marshalerType) {
+		return nil, false, nil
+	}
+	if !v.CanAddr() {
+		addressable := reflect.New(v.Type()).Elem()
+		addressable.Set(v)
+		v = addressable
+	}
+	av, err := v.Addr().Interface().(Marshaler).MarshalDynamoDBAttributeValue()
+	return av, true, err
 }
 
 // lookupConverter returns a registered converter for the provided type, walking pointer
@@ -102,6 +160,12 @@ func (c *Converter) toAttributeValue(v reflect.Value) (types.AttributeValue, err
 	if converter, exists := c.lookupConverter(v.Type()); exists {
 		return converter.ToAttributeValue(v.Interface())
 	}
+	if av, ok, err := marshalWithMarshaler(v); ok {
+		return av, err
+	}
+	if av, ok, err := marshalBigNumber(v); ok {
+		return av, err
+	}
 
 	// Handle time.Time specially
 	if v.Type() == reflect.TypeOf(time.Time{}) {
@@ -109,7 +173,7 @@ func (c *Converter) toAttributeValue(v reflect.Value) (types.AttributeValue, err
 		if !ok {
 			return nil, fmt.Errorf("expected time.Time, got %T", v.Interface())
 		}
-		return &types.AttributeValueMemberS{Value: t.Format(time.RFC3339Nano)}, nil
+		return &types.AttributeValueMemberS{Value: c.FormatTime(t)}, nil
 	}
 
 	// Handle basic types
@@ -228,6 +292,11 @@ func (c *Converter) FromAttributeValue(av types.AttributeValue, target any) erro
 // fromAttributeValue handles the actual conversion from AttributeValue
 func (c *Converter) fromAttributeValue(av types.AttributeValue, target reflect.Value) error {
 	if _, ok := av.(*types.AttributeValueMemberNULL); ok {
+		// NULL clears a pointer, so an explicit NULL reads back as nil while a missing
+		// attribute leaves the field alone.
+		if target.Kind() == reflect.Ptr && target.CanSet() {
+			target.Set(reflect.Zero(target.Type()))
+		}
 		return nil
 	}
 
@@ -240,6 +309,15 @@ func (c *Converter) fromAttributeValue(av types.AttributeValue, target reflect.V
 	if converter, exists := c.lookupConverter(target.Type()); exists {
 		return converter.FromAttributeValue(av, target.Addr().Interface())
 	}
+	if reflect.PointerTo(target.Type()).Implements(unmarshalerType) {
+		return target.Addr().Interface().(Unmarshaler).UnmarshalDynamoDBAttributeValue(av)
+	}
+	if IsBigNumber(target.Type()) {
+		return c.setBigNumber(av, target)
+	}
+	if target.Kind() == reflect.Interface {
+		return c.fromAttributeValueInterface(av, target)
+	}
 
 	if target.Type() == timeType {
 		return c.fromAttributeValueTime(av, target)
@@ -275,6 +353,96 @@ func (c *Converter) fromAttributeValueTime(av types.AttributeValue, target refle
 	return nil
 }
 
+// fromAttributeValueInterface decodes av into an interface target. An empty interface
+// receives plain Go values; a non-empty one must already hold a pointer to decode into,
+// since nothing in the item names a concrete type.
+func (c *Converter) fromAttributeValueInterface(av types.AttributeValue, target reflect.Value) error {
+	if target.Type().NumMethod() == 0 {
+		value, err := interfaceValue(av)
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			target.Set(reflect.Zero(target.Type()))
+			return nil
+		}
+		target.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	held := target.Elem()
+	if held.Kind() != reflect.Ptr || held.IsNil() {
+		return fmt.Errorf("%w: cannot decode into %s without a concrete value; set the field to a pointer first, or use a Marshaler type or custom converter",
+			errors.ErrUnsupportedType, target.Type())
+	}
+	return c.fromAttributeValue(av, held.Elem())
+}
+
+// interfaceValue returns av as the Go value an empty interface receives: string,
+// int64 or float64, bool, []byte, []any, map[string]any, []string, []float64, or
+// [][]byte.
+func interfaceValue(av types.AttributeValue) (any, error) {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value, nil
+	case *types.AttributeValueMemberN:
+		return interfaceNumber(v.Value)
+	case *types.AttributeValueMemberBOOL:
+		return v.Value, nil
+	case *types.AttributeValueMemberB:
+		return v.Value, nil
+	case *types.AttributeValueMemberNULL:
+		return nil, nil
+	case *types.AttributeValueMemberL:
+		list := make([]any, len(v.Value))
+		for i, item := range v.Value {
+			value, err := interfaceValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("list item %d: %w", i, err)
+			}
+			list[i] = value
+		}
+		return list, nil
+	case *types.AttributeValueMemberM:
+		m := make(map[string]any, len(v.Value))
+		for key, item := range v.Value {
+			value, err := interfaceValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("map key %s: %w", key, err)
+			}
+			m[key] = value
+		}
+		return m, nil
+	case *types.AttributeValueMemberSS:
+		return v.Value, nil
+	case *types.AttributeValueMemberNS:
+		nums := make([]float64, len(v.Value))
+		for i, n := range v.Value {
+			f, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number in set: %q", n)
+			}
+			nums[i] = f
+		}
+		return nums, nil
+	case *types.AttributeValueMemberBS:
+		return v.Value, nil
+	default:
+		return nil, fmt.Errorf("unsupported AttributeValue type: %T", av)
+	}
+}
+
+func interfaceNumber(n string) (any, error) {
+	if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(n, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number: %q", n)
+	}
+	return f, nil
+}
+
 func (c *Converter) fromAttributeValueByType(av types.AttributeValue, target reflect.Value) error {
 	switch v := av.(type) {
 	case *types.AttributeValueMemberS:
@@ -334,28 +502,13 @@ func (c *Converter) stringToValue(s string, target reflect.Value) error {
 func (c *Converter) numberToValue(n string, target reflect.Value) error {
 	switch target.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		i, err := strconv.ParseInt(n, 10, 64)
-		if err != nil {
-			return fmt.Errorf("invalid number: %w", err)
-		}
-		target.SetInt(i)
-		return nil
+		return c.setInt(n, target)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		u, err := strconv.ParseUint(n, 10, 64)
-		if err != nil {
-			return fmt.Errorf("invalid number: %w", err)
-		}
-		target.SetUint(u)
-		return nil
+		return c.setUint(n, target)
 
 	case reflect.Float32, reflect.Float64:
-		f, err := strconv.ParseFloat(n, 64)
-		if err != nil {
-			return fmt.Errorf("invalid number: %w", err)
-		}
-		target.SetFloat(f)
-		return nil
+		return c.setFloat(n, target)
 
 	default:
 		return fmt.Errorf("cannot convert number to %s", target.Type())
@@ -430,7 +583,7 @@ func (c *Converter) mapToStruct(m map[string]types.AttributeValue, target reflec
 			return fmt.Errorf("field %s: %w", field.Name, err)
 		}
 
-		av, exists := m[attrName]
+		av, exists := nestedAttribute(m, field, attrName)
 		if !exists {
 			continue
 		}
@@ -443,6 +596,24 @@ func (c *Converter) mapToStruct(m map[string]types.AttributeValue, target reflec
 	return nil
 }
 
+// nestedAttribute looks up a struct field in a stored map. Besides attrName it accepts
+// the names the marshalers write nested fields under: the json tag name (unsafe
+// marshaler) and the Go field name (safe marshaler and this converter).
+func nestedAttribute(m map[string]types.AttributeValue, field reflect.StructField, attrName string) (types.AttributeValue, bool) {
+	if av, ok := m[attrName]; ok {
+		return av, true
+	}
+	if tag := field.Tag.Get("json"); tag != "" && tag != "-" {
+		if name, _, _ := strings.Cut(tag, ","); name != "" {
+			if av, ok := m[name]; ok {
+				return av, true
+			}
+		}
+	}
+	av, ok := m[field.Name]
+	return av, ok
+}
+
 // stringSetToSlice converts string set to slice
 func (c *Converter) stringSetToSlice(set []string, target reflect.Value) error {
 	if target.Kind() != reflect.Slice || target.Type().Elem().Kind() != reflect.String {
@@ -467,7 +638,14 @@ func (c *Converter) numberSetToSlice(set []string, target reflect.Value) error {
 	slice := reflect.MakeSlice(target.Type(), len(set), len(set))
 
 	for i, n := range set {
-		if err := c.numberToValue(n, slice.Index(i)); err != nil {
+		elem := ensureSettableConcreteTarget(slice.Index(i))
+		if IsBigNumber(elem.Type()) {
+			if err := c.setBigNumberString(n, elem); err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+			continue
+		}
+		if err := c.numberToValue(n, elem); err != nil {
 			return fmt.Errorf("index %d: %w", i, err)
 		}
 	}
@@ -509,6 +687,21 @@ func (c *Converter) ConvertToSet(slice any, isSet bool) (types.AttributeValue, e
 	}
 
 	elemType := v.Type().Elem()
+	if IsBigNumber(elemType) {
+		set := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			av, err := c.toAttributeValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			n, ok := av.(*types.AttributeValueMemberN)
+			if !ok {
+				return nil, fmt.Errorf("index %d: number sets cannot contain nil", i)
+			}
+			set[i] = n.Value
+		}
+		return &types.AttributeValueMemberNS{Value: set}, nil
+	}
 
 	switch elemType.Kind() {
 	case reflect.String:
@@ -1,13 +1,18 @@
 package types
 
 import (
+	"fmt"
+	"math"
 	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/errors"
 )
 
 type fakeCustomConverter struct {
@@ -108,3 +113,91 @@ func TestConverterHasCustomConverterAndPointerLookup(t *testing.T) {
 		assert.True(t, ok)
 	})
 }
+
+// cents marshals itself as a decimal number of currency units.
+type cents int64
+
+func (c cents) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return &types.AttributeValueMemberN{Value: strconv.FormatFloat(float64(c)/100, 'f', 2, 64)}, nil
+}
+
+func (c *cents) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	n, ok := av.(*types.AttributeValueMemberN)
+	if !ok {
+		return fmt.Errorf("cents must be a number, got %T", av)
+	}
+	f, err := strconv.ParseFloat(n.Value, 64)
+	*c = cents(math.Round(f * 100))
+	return err
+}
+
+// tag marshals itself through a pointer receiver.
+type tag struct{ name string }
+
+func (t *tag) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return &types.AttributeValueMemberS{Value: "#" + t.name}, nil
+}
+
+func TestConverterUsesMarshalerInterfaces(t *testing.T) {
+	converter := NewConverter()
+	require.True(t, converter.HasCustomConverter(reflect.TypeOf(cents(0))))
+	require.True(t, converter.HasCustomConverter(reflect.TypeOf(&tag{})))
+	require.True(t, converter.HasCustomConverter(reflect.TypeOf(tag{})))
+	require.False(t, converter.HasCustomConverter(reflect.TypeOf(customPayload{})))
+
+	av, err := converter.ToAttributeValue(cents(1234))
+	require.NoError(t, err)
+	require.Equal(t, &types.AttributeValueMemberN{Value: "12.34"}, av)
+
+	av, err = converter.ToAttributeValue(tag{name: "vip"})
+	require.NoError(t, err)
+	require.Equal(t, &types.AttributeValueMemberS{Value: "#vip"}, av)
+
+	av, err = converter.ToAttributeValue([]cents{5})
+	require.NoError(t, err)
+	require.Equal(t, &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberN{Value: "0.05"}}}, av)
+
+	var price cents
+	require.NoError(t, converter.FromAttributeValue(&types.AttributeValueMemberN{Value: "9.99"}, &price))
+	require.Equal(t, cents(999), price)
+	require.Error(t, converter.FromAttributeValue(&types.AttributeValueMemberS{Value: "x"}, &price))
+
+	var optional *cents
+	require.NoError(t, converter.FromAttributeValue(&types.AttributeValueMemberN{Value: "1"}, &optional))
+	require.Equal(t, cents(100), *optional)
+
+	converter.RegisterConverter(reflect.TypeOf(cents(0)), fakeCustomConverter{
+		to: func(any) (types.AttributeValue, error) { return &types.AttributeValueMemberS{Value: "registered"}, nil },
+	})
+	av, err = converter.ToAttributeValue(cents(1))
+	require.NoError(t, err)
+	require.Equal(t, &types.AttributeValueMemberS{Value: "registered"}, av, "registered converters take precedence")
+}
+
+type labeled struct {
+	Label string
+}
+
+func (l *labeled) String() string { return l.Label }
+
+func TestConverter_FromAttributeValueInterfaceTargets(t *testing.T) {
+	c := NewConverter()
+
+	var generic any
+	require.NoError(t, c.FromAttributeValue(&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"n":    &types.AttributeValueMemberN{Value: "1.5"},
+		"ok":   &types.AttributeValueMemberBOOL{Value: true},
+		"tags": &types.AttributeValueMemberSS{Value: []string{"a"}},
+	}}, &generic))
+	require.Equal(t, map[string]any{"n": 1.5, "ok": true, "tags": []string{"a"}}, generic)
+
+	var stringer fmt.Stringer = &labeled{}
+	require.NoError(t, c.FromAttributeValue(&types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"Label": &types.AttributeValueMemberS{Value: "x"},
+	}}, &stringer))
+	require.Equal(t, "x", stringer.String())
+
+	var empty fmt.Stringer
+	err := c.FromAttributeValue(&types.AttributeValueMemberS{Value: "x"}, &empty)
+	require.ErrorIs(t, err, errors.ErrUnsupportedType)
+}
@@ -920,3 +920,27 @@ func BenchmarkFromAttributeValue(b *testing.B) {
 		}
 	}
 }
+
+func TestConverter_NestedStructAcceptsMarshaledNames(t *testing.T) {
+	type address struct {
+		City string
+		Zip  string `json:"postalCode"`
+	}
+	type order struct {
+		Address address
+	}
+
+	converter := NewConverter()
+	for _, stored := range []map[string]types.AttributeValue{
+		{"city": &types.AttributeValueMemberS{Value: "Austin"}, "zip": &types.AttributeValueMemberS{Value: "78701"}},
+		{"City": &types.AttributeValueMemberS{Value: "Austin"}, "Zip": &types.AttributeValueMemberS{Value: "78701"}},
+		{"city": &types.AttributeValueMemberS{Value: "Austin"}, "postalCode": &types.AttributeValueMemberS{Value: "78701"}},
+	} {
+		var decoded order
+		av := &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"Address": &types.AttributeValueMemberM{Value: stored},
+		}}
+		require.NoError(t, converter.FromAttributeValue(av, &decoded))
+		assert.Equal(t, address{City: "Austin", Zip: "78701"}, decoded.Address)
+	}
+}
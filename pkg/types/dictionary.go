@@ -0,0 +1,120 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DictionaryConverter is a CustomConverter that stores string constants as short codes.
+// Wide tables that repeat long status or category strings on every item can shrink
+// noticeably by writing "PP" instead of "PENDING_PAYMENT_CONFIRMATION".
+//
+// Register it for the constant's named type:
+//
+//	conv, err := types.NewDictionaryConverter(map[OrderStatus]string{
+//		OrderStatusPendingPayment: "PP",
+//		OrderStatusFulfilled:      "F",
+//	})
+//	err = db.RegisterTypeConverter(reflect.TypeOf(OrderStatus("")), conv)
+//
+// Writes fail for values missing from the mapping, so a new constant cannot reach the
+// table unencoded. Reads accept both codes and the full values, which keeps items
+// written before the converter was registered readable.
+type DictionaryConverter[T ~string] struct {
+	toCode   map[T]string
+	fromCode map[string]T
+}
+
+// NewDictionaryConverter builds a converter from a value-to-code mapping. Codes must be
+// non-empty and unique, and a code may not equal a different value's full spelling.
+func NewDictionaryConverter[T ~string](mapping map[T]string) (*DictionaryConverter[T], error) {
+	if len(mapping) == 0 {
+		return nil, fmt.Errorf("dictionary mapping cannot be empty")
+	}
+
+	c := &DictionaryConverter[T]{
+		toCode:   make(map[T]string, len(mapping)),
+		fromCode: make(map[string]T, len(mapping)),
+	}
+	for value, code := range mapping {
+		if code == "" {
+			return nil, fmt.Errorf("dictionary code for %q cannot be empty", string(value))
+		}
+		if existing, dup := c.fromCode[code]; dup {
+			return nil, fmt.Errorf("dictionary code %q is used by both %q and %q", code, string(existing), string(value))
+		}
+		c.toCode[value] = code
+		c.fromCode[code] = value
+	}
+	for code, value := range c.fromCode {
+		if _, ok := c.toCode[T(code)]; ok && T(code) != value {
+			return nil, fmt.Errorf("dictionary code %q for %q collides with the value %q", code, string(value), code)
+		}
+	}
+
+	return c, nil
+}
+
+// Code returns the stored code for value.
+func (c *DictionaryConverter[T]) Code(value T) (string, bool) {
+	code, ok := c.toCode[value]
+	return code, ok
+}
+
+// ToAttributeValue encodes a T (or *T) as its dictionary code.
+func (c *DictionaryConverter[T]) ToAttributeValue(value any) (types.AttributeValue, error) {
+	var v T
+	switch typed := value.(type) {
+	case T:
+		v = typed
+	case *T:
+		if typed == nil {
+			return &types.AttributeValueMemberNULL{Value: true}, nil
+		}
+		v = *typed
+	default:
+		return nil, fmt.Errorf("dictionary converter cannot encode %T", value)
+	}
+
+	if v == "" {
+		return &types.AttributeValueMemberS{Value: ""}, nil
+	}
+	code, ok := c.toCode[v]
+	if !ok {
+		return nil, fmt.Errorf("value %q has no dictionary code", string(v))
+	}
+	return &types.AttributeValueMemberS{Value: code}, nil
+}
+
+// FromAttributeValue decodes a dictionary code (or a full value) into target, which must be *T.
+func (c *DictionaryConverter[T]) FromAttributeValue(av types.AttributeValue, target any) error {
+	out, ok := target.(*T)
+	if !ok || out == nil {
+		return fmt.Errorf("dictionary converter target must be a non-nil pointer, got %T", target)
+	}
+
+	switch typed := av.(type) {
+	case *types.AttributeValueMemberNULL:
+		var zero T
+		*out = zero
+		return nil
+	case *types.AttributeValueMemberS:
+		if typed.Value == "" {
+			var zero T
+			*out = zero
+			return nil
+		}
+		if value, ok := c.fromCode[typed.Value]; ok {
+			*out = value
+			return nil
+		}
+		if _, ok := c.toCode[T(typed.Value)]; ok {
+			*out = T(typed.Value)
+			return nil
+		}
+		return fmt.Errorf("unknown dictionary code %q", typed.Value)
+	default:
+		return fmt.Errorf("dictionary converter expects a string attribute, got %T", av)
+	}
+}
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+)
+
+type dictionaryStatus string
+
+const (
+	statusPendingPayment dictionaryStatus = "PENDING_PAYMENT_CONFIRMATION"
+	statusFulfilled      dictionaryStatus = "FULFILLED"
+	statusCancelled      dictionaryStatus = "CANCELLED"
+)
+
+type dictionaryOrder struct {
+	Status dictionaryStatus
+}
+
+func newStatusDictionary(t *testing.T) *DictionaryConverter[dictionaryStatus] {
+	t.Helper()
+	conv, err := NewDictionaryConverter(map[dictionaryStatus]string{
+		statusPendingPayment: "PP",
+		statusFulfilled:      "F",
+	})
+	require.NoError(t, err)
+	return conv
+}
+
+func TestNewDictionaryConverter_ValidatesMapping(t *testing.T) {
+	_, err := NewDictionaryConverter(map[dictionaryStatus]string{})
+	require.ErrorContains(t, err, "cannot be empty")
+
+	_, err = NewDictionaryConverter(map[dictionaryStatus]string{statusFulfilled: ""})
+	require.ErrorContains(t, err, "cannot be empty")
+
+	_, err = NewDictionaryConverter(map[dictionaryStatus]string{statusFulfilled: "X", statusCancelled: "X"})
+	require.ErrorContains(t, err, "is used by both")
+
+	_, err = NewDictionaryConverter(map[dictionaryStatus]string{statusFulfilled: "CANCELLED", statusCancelled: "C"})
+	require.ErrorContains(t, err, "collides")
+}
+
+func TestDictionaryConverter_RoundTripsThroughConverter(t *testing.T) {
+	conv := newStatusDictionary(t)
+	converter := NewConverter()
+	converter.RegisterConverter(reflect.TypeOf(dictionaryStatus("")), conv)
+
+	av, err := converter.ToAttributeValue(statusPendingPayment)
+	require.NoError(t, err)
+	require.Equal(t, &types.AttributeValueMemberS{Value: "PP"}, av)
+
+	var order dictionaryOrder
+	require.NoError(t, converter.FromAttributeValue(&types.AttributeValueMemberS{Value: "F"}, &order.Status))
+	require.Equal(t, statusFulfilled, order.Status)
+
+	code, ok := conv.Code(statusFulfilled)
+	require.True(t, ok)
+	require.Equal(t, "F", code)
+}
+
+func TestDictionaryConverter_ReadsLegacyValuesAndRejectsUnknown(t *testing.T) {
+	conv := newStatusDictionary(t)
+
+	var status dictionaryStatus
+	require.NoError(t, conv.FromAttributeValue(&types.AttributeValueMemberS{Value: string(statusPendingPayment)}, &status))
+	require.Equal(t, statusPendingPayment, status)
+
+	require.ErrorContains(t, conv.FromAttributeValue(&types.AttributeValueMemberS{Value: "ZZ"}, &status), "unknown dictionary code")
+	require.ErrorContains(t, conv.FromAttributeValue(&types.AttributeValueMemberN{Value: "1"}, &status), "expects a string")
+	require.ErrorContains(t, conv.FromAttributeValue(&types.AttributeValueMemberS{Value: "F"}, status), "non-nil pointer")
+
+	require.NoError(t, conv.FromAttributeValue(&types.AttributeValueMemberNULL{Value: true}, &status))
+	require.Equal(t, dictionaryStatus(""), status)
+
+	_, err := conv.ToAttributeValue(statusCancelled)
+	require.ErrorContains(t, err, "has no dictionary code")
+
+	_, err = conv.ToAttributeValue(42)
+	require.ErrorContains(t, err, "cannot encode")
+
+	av, err := conv.ToAttributeValue((*dictionaryStatus)(nil))
+	require.NoError(t, err)
+	require.Equal(t, &types.AttributeValueMemberNULL{Value: true}, av)
+}
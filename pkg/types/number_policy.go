@@ -0,0 +1,161 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// NumberPolicy controls what happens when a stored number does not fit the Go field it is
+// unmarshaled into, such as 3000000000 into an int32 or 16777217 into a float32.
+type NumberPolicy int
+
+const (
+	// NumberBestEffort keeps the historical behavior: integers that overflow the field wrap
+	// around and floats are rounded to the nearest representable value. This is the default.
+	NumberBestEffort NumberPolicy = iota
+	// NumberStrict returns a *errors.NumberRangeError for overflow, negative values in
+	// unsigned fields, and floats that cannot represent the stored value exactly.
+	NumberStrict
+	// NumberSaturate clamps out-of-range values to the field's minimum or maximum and rounds
+	// floats.
+	NumberSaturate
+)
+
+// SetNumberPolicy changes how numbers that do not fit their destination are handled.
+func (c *Converter) SetNumberPolicy(policy NumberPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.numberPolicy = policy
+}
+
+// NumberPolicy returns the current number policy.
+func (c *Converter) NumberPolicy() NumberPolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.numberPolicy
+}
+
+func (c *Converter) setInt(n string, target reflect.Value) error {
+	policy := c.NumberPolicy()
+	i, err := strconv.ParseInt(n, 10, 64)
+	if err != nil {
+		if policy == NumberBestEffort || !errors.Is(err, strconv.ErrRange) {
+			return fmt.Errorf("invalid number: %w", err)
+		}
+		if policy == NumberStrict {
+			return numberRangeError(n, target, "overflows int64")
+		}
+		i = math.MaxInt64
+		if n[0] == '-' {
+			i = math.MinInt64
+		}
+	}
+
+	if target.OverflowInt(i) && policy != NumberBestEffort {
+		if policy == NumberStrict {
+			return numberRangeError(n, target, "overflows "+target.Type().String())
+		}
+		bits := target.Type().Bits()
+		if i < 0 {
+			i = -1 << (bits - 1)
+		} else {
+			i = 1<<(bits-1) - 1
+		}
+	}
+	target.SetInt(i)
+	return nil
+}
+
+func (c *Converter) setUint(n string, target reflect.Value) error {
+	policy := c.NumberPolicy()
+	u, err := strconv.ParseUint(n, 10, 64)
+	if err != nil {
+		negative := len(n) > 1 && n[0] == '-'
+		if _, intErr := strconv.ParseInt(n, 10, 64); intErr != nil && !errors.Is(intErr, strconv.ErrRange) {
+			negative = false
+		}
+		switch {
+		case policy == NumberBestEffort || (!negative && !errors.Is(err, strconv.ErrRange)):
+			return fmt.Errorf("invalid number: %w", err)
+		case policy == NumberStrict && negative:
+			return numberRangeError(n, target, "is negative")
+		case policy == NumberStrict:
+			return numberRangeError(n, target, "overflows uint64")
+		case negative:
+			u = 0
+		default:
+			u = math.MaxUint64
+		}
+	}
+
+	if target.OverflowUint(u) && policy != NumberBestEffort {
+		if policy == NumberStrict {
+			return numberRangeError(n, target, "overflows "+target.Type().String())
+		}
+		u = 1<<target.Type().Bits() - 1
+	}
+	target.SetUint(u)
+	return nil
+}
+
+func (c *Converter) setFloat(n string, target reflect.Value) error {
+	policy := c.NumberPolicy()
+	if policy == NumberBestEffort {
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number: %w", err)
+		}
+		target.SetFloat(f)
+		return nil
+	}
+
+	bits := target.Type().Bits()
+	f, err := strconv.ParseFloat(n, bits)
+	if err != nil {
+		if !errors.Is(err, strconv.ErrRange) {
+			return fmt.Errorf("invalid number: %w", err)
+		}
+		if policy == NumberStrict {
+			return numberRangeError(n, target, "overflows "+target.Type().String())
+		}
+		// ParseFloat returns ±Inf for overflow and a rounded value for underflow.
+		if math.IsInf(f, 1) {
+			f = maxFloat(bits)
+		} else if math.IsInf(f, -1) {
+			f = -maxFloat(bits)
+		}
+	}
+
+	if policy == NumberStrict && !exactFloat(n, f, bits) {
+		return numberRangeError(n, target, "loses precision in "+target.Type().String())
+	}
+	target.SetFloat(f)
+	return nil
+}
+
+// exactFloat reports whether f, parsed from n, prints back to the same decimal value.
+func exactFloat(n string, f float64, bits int) bool {
+	want, ok := new(big.Rat).SetString(n)
+	if !ok {
+		return false
+	}
+	got, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, bits))
+	return ok && want.Cmp(got) == 0
+}
+
+func maxFloat(bits int) float64 {
+	if bits == 32 {
+		return math.MaxFloat32
+	}
+	return math.MaxFloat64
+}
+
+func numberRangeError(n string, target reflect.Value, reason string) error {
+	return &customerrors.NumberRangeError{Value: n, Target: target.Type().String(), Reason: reason}
+}
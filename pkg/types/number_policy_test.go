@@ -0,0 +1,91 @@
+package types
+
+import (
+	"math"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+func TestNumberPolicy_DefaultIsBestEffort(t *testing.T) {
+	converter := NewConverter()
+	assert.Equal(t, NumberBestEffort, converter.NumberPolicy())
+
+	var small int8
+	require.NoError(t, converter.FromAttributeValue(&types.AttributeValueMemberN{Value: "300"}, &small))
+	assert.Equal(t, int8(44), small, "best effort keeps the historical wrap-around")
+}
+
+func TestNumberPolicy_Strict(t *testing.T) {
+	converter := NewConverter()
+	converter.SetNumberPolicy(NumberStrict)
+
+	tests := []struct {
+		target any
+		name   string
+		value  string
+	}{
+		{name: "int16 overflow", value: "70000", target: new(int16)},
+		{name: "int32 underflow", value: "-3000000000", target: new(int32)},
+		{name: "int64 overflow", value: "9223372036854775808", target: new(int64)},
+		{name: "negative uint", value: "-1", target: new(uint32)},
+		{name: "uint8 overflow", value: "256", target: new(uint8)},
+		{name: "float32 overflow", value: "1e39", target: new(float32)},
+		{name: "float32 precision", value: "16777217", target: new(float32)},
+		{name: "float64 precision", value: "0.12345678901234567890123", target: new(float64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := converter.FromAttributeValue(&types.AttributeValueMemberN{Value: tt.value}, tt.target)
+			require.ErrorIs(t, err, customerrors.ErrNumberOutOfRange)
+
+			var rangeErr *customerrors.NumberRangeError
+			require.ErrorAs(t, err, &rangeErr)
+			assert.Equal(t, tt.value, rangeErr.Value)
+		})
+	}
+
+	var fits int16
+	require.NoError(t, converter.FromAttributeValue(&types.AttributeValueMemberN{Value: "-32768"}, &fits))
+	assert.Equal(t, int16(math.MinInt16), fits)
+
+	var exact float32
+	require.NoError(t, converter.FromAttributeValue(&types.AttributeValueMemberN{Value: "0.1"}, &exact))
+	assert.Equal(t, float32(0.1), exact)
+
+	err := converter.FromAttributeValue(&types.AttributeValueMemberN{Value: "abc"}, new(int))
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, customerrors.ErrNumberOutOfRange)
+}
+
+func TestNumberPolicy_Saturate(t *testing.T) {
+	converter := NewConverter()
+	converter.SetNumberPolicy(NumberSaturate)
+
+	var i16 int16
+	require.NoError(t, converter.FromAttributeValue(&types.AttributeValueMemberN{Value: "70000"}, &i16))
+	assert.Equal(t, int16(math.MaxInt16), i16)
+
+	var i32 int32
+	require.NoError(t, converter.FromAttributeValue(&types.AttributeValueMemberN{Value: "-99999999999999999999"}, &i32))
+	assert.Equal(t, int32(math.MinInt32), i32)
+
+	var u8 uint8
+	require.NoError(t, converter.FromAttributeValue(&types.AttributeValueMemberN{Value: "-5"}, &u8))
+	assert.Equal(t, uint8(0), u8)
+	require.NoError(t, converter.FromAttributeValue(&types.AttributeValueMemberN{Value: "1000"}, &u8))
+	assert.Equal(t, uint8(math.MaxUint8), u8)
+
+	var f32 float32
+	require.NoError(t, converter.FromAttributeValue(&types.AttributeValueMemberN{Value: "-1e39"}, &f32))
+	assert.Equal(t, float32(-math.MaxFloat32), f32)
+
+	var set []int8
+	require.NoError(t, converter.FromAttributeValue(&types.AttributeValueMemberNS{Value: []string{"1", "200"}}, &set))
+	assert.Equal(t, []int8{1, math.MaxInt8}, set)
+}
@@ -0,0 +1,42 @@
+package types
+
+import "time"
+
+// TimePolicy controls how time.Time values are written as strings.
+type TimePolicy int
+
+const (
+	// TimeAsGiven keeps the historical behavior: values are formatted as RFC3339Nano in
+	// whatever zone they carry, so the same instant in two zones is stored as two
+	// different strings. This is the default.
+	TimeAsGiven TimePolicy = iota
+	// TimeUTC converts values to UTC before formatting them as RFC3339Nano, so every
+	// instant has one stored form and string comparisons follow time order.
+	TimeUTC
+)
+
+// SetTimePolicy changes how time.Time values are formatted.
+func (c *Converter) SetTimePolicy(policy TimePolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timePolicy = policy
+}
+
+// TimePolicy returns the current time policy.
+func (c *Converter) TimePolicy() TimePolicy {
+	if c == nil {
+		return TimeAsGiven
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.timePolicy
+}
+
+// FormatTime formats t as RFC3339Nano according to the time policy. A nil converter uses
+// TimeAsGiven.
+func (c *Converter) FormatTime(t time.Time) string {
+	if c.TimePolicy() == TimeUTC {
+		t = t.UTC()
+	}
+	return t.Format(time.RFC3339Nano)
+}
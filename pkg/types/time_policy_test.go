@@ -0,0 +1,37 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimePolicy_DefaultKeepsZone(t *testing.T) {
+	converter := NewConverter()
+	assert.Equal(t, TimeAsGiven, converter.TimePolicy())
+
+	local := time.Date(2024, 3, 1, 10, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+	av, err := converter.ToAttributeValue(local)
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "2024-03-01T10:30:00-05:00"}, av)
+
+	var nilConverter *Converter
+	assert.Equal(t, "2024-03-01T10:30:00-05:00", nilConverter.FormatTime(local))
+}
+
+func TestTimePolicy_UTC(t *testing.T) {
+	converter := NewConverter()
+	converter.SetTimePolicy(TimeUTC)
+
+	local := time.Date(2024, 3, 1, 10, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+	av, err := converter.ToAttributeValue(local)
+	require.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "2024-03-01T15:30:00Z"}, av)
+
+	var decoded time.Time
+	require.NoError(t, converter.FromAttributeValue(av, &decoded))
+	assert.True(t, decoded.Equal(local))
+}
@@ -191,8 +191,12 @@ func validateNestedFieldPath(field string) error {
 		}
 	}
 
-	for _, part := range parts {
-		if err := validateFieldPart(part); err != nil {
+	for i, part := range parts {
+		err := validateFieldPart(part)
+		if err != nil && i > 0 {
+			err = validateMapKeyPart(part)
+		}
+		if err != nil {
 			return &SecurityError{
 				Type:   "InvalidField",
 				Field:  "",
@@ -204,6 +208,20 @@ func validateNestedFieldPath(field string) error {
 	return nil
 }
 
+// mapKeyPartPattern matches map keys such as "gift-wrap" or "2024" inside a nested
+// path, optionally followed by list indexes. Every segment gets its own expression
+// attribute name placeholder, so these never reach the expression text.
+var mapKeyPartPattern = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_-]*(\[[0-9]+\])*$`)
+
+// validateMapKeyPart validates a non-leading segment of a nested path that is not a valid
+// attribute name, such as the key of a map[string]T field.
+func validateMapKeyPart(part string) error {
+	if !mapKeyPartPattern.MatchString(part) {
+		return fmt.Errorf("map key part must contain only alphanumeric characters, underscores, and hyphens")
+	}
+	return nil
+}
+
 // isStandaloneOrSuspiciousKeyword checks if a SQL keyword appears in a suspicious way
 func isStandaloneOrSuspiciousKeyword(fieldLower, keyword string) bool {
 	// Exact match (standalone keyword)
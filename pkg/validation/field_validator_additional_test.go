@@ -56,6 +56,15 @@ func TestValidateFieldPartListSyntax(t *testing.T) {
 	}
 }
 
+func TestValidateFieldNameNestedMapKeys(t *testing.T) {
+	for _, field := range []string{"labels.gift-wrap", "metadata.tier", "counts.2024", "labels.gift-wrap[0]"} {
+		assert.NoError(t, ValidateFieldName(field), field)
+	}
+	for _, field := range []string{"gift-wrap.labels", "labels.gift wrap", "labels.-x", "labels.a--b", "labels.x-drop"} {
+		assert.Error(t, ValidateFieldName(field), field)
+	}
+}
+
 func TestValidateValueCollectionErrors(t *testing.T) {
 	t.Run("slice reports invalid member", func(t *testing.T) {
 		err := ValidateValue([]any{"safe", "javascript:alert(1)"})
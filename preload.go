@@ -0,0 +1,208 @@
+package dynamorm
+
+import (
+	"fmt"
+	"reflect"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// ExecutePreload populates relationship fields on the structs held by dest, which is a
+// pointer to a struct or to a slice of structs (or struct pointers). hasmany relationships
+// issue one Query per distinct owner key, against the related table when the foreign key is
+// its partition key or against the GSI keyed on it otherwise. belongsto relationships issue
+// a single BatchGet for all distinct foreign key values.
+func (qe *queryExecutor) ExecutePreload(dest any, relations []string) error {
+	owners := preloadOwners(reflect.ValueOf(dest))
+	if len(owners) == 0 {
+		return nil
+	}
+
+	for _, name := range relations {
+		rel, ok := qe.metadata.Relation(name)
+		if !ok {
+			return fmt.Errorf("%w: relationship %s not found on %s", customerrors.ErrInvalidModel, name, qe.metadata.Type.Name())
+		}
+
+		var err error
+		switch rel.Kind {
+		case model.HasMany:
+			err = qe.preloadHasMany(rel, owners)
+		case model.BelongsTo:
+			err = qe.preloadBelongsTo(rel, owners)
+		default:
+			err = fmt.Errorf("unsupported relationship kind %q", rel.Kind)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to preload %s: %w", rel.Name, err)
+		}
+	}
+	return nil
+}
+
+// preloadOwners returns the addressable owner structs held by dest.
+func preloadOwners(dest reflect.Value) []reflect.Value {
+	if dest.Kind() != reflect.Ptr || dest.IsNil() {
+		return nil
+	}
+	dest = dest.Elem()
+
+	switch dest.Kind() {
+	case reflect.Struct:
+		return []reflect.Value{dest}
+	case reflect.Slice:
+		owners := make([]reflect.Value, 0, dest.Len())
+		for i := 0; i < dest.Len(); i++ {
+			elem := dest.Index(i)
+			if elem.Kind() == reflect.Ptr {
+				if elem.IsNil() {
+					continue
+				}
+				elem = elem.Elem()
+			}
+			if elem.Kind() == reflect.Struct {
+				owners = append(owners, elem)
+			}
+		}
+		return owners
+	default:
+		return nil
+	}
+}
+
+func (qe *queryExecutor) preloadHasMany(rel *model.RelationMetadata, owners []reflect.Value) error {
+	targetMeta, err := qe.db.metadataFor(reflect.New(rel.Target).Interface())
+	if err != nil {
+		return err
+	}
+	fk := lookupField(targetMeta, rel.ForeignKey)
+	if fk == nil {
+		return fmt.Errorf("%w: foreign key %s not found on %s", customerrors.ErrInvalidModel, rel.ForeignKey, rel.Target.Name())
+	}
+	indexName, ok := partitionIndexFor(targetMeta, fk)
+	if !ok {
+		return fmt.Errorf("%w: %s must be the partition key of %s or one of its GSIs", customerrors.ErrInvalidModel, rel.ForeignKey, targetMeta.TableName)
+	}
+
+	pkPath := qe.metadata.PrimaryKey.PartitionKey.IndexPath
+	groups := make(map[string][]reflect.Value)
+	order := make([]reflect.Value, 0, len(owners))
+	for _, owner := range owners {
+		keyValue := owner.FieldByIndex(pkPath)
+		groupKey := fmt.Sprint(keyValue.Interface())
+		if _, seen := groups[groupKey]; !seen {
+			order = append(order, keyValue)
+		}
+		groups[groupKey] = append(groups[groupKey], owner)
+	}
+
+	for _, keyValue := range order {
+		children := reflect.New(reflect.SliceOf(rel.Target))
+		q := qe.db.Model(reflect.New(rel.Target).Interface()).WithContext(qe.ctx)
+		if indexName != "" {
+			q = q.Index(indexName)
+		}
+		if err := q.Where(fk.Name, "=", keyValue.Interface()).All(children.Interface()); err != nil {
+			return err
+		}
+
+		for _, owner := range groups[fmt.Sprint(keyValue.Interface())] {
+			owner.FieldByIndex(rel.IndexPath).Set(relationSlice(rel.Type, children.Elem()))
+		}
+	}
+	return nil
+}
+
+func (qe *queryExecutor) preloadBelongsTo(rel *model.RelationMetadata, owners []reflect.Value) error {
+	fk := lookupField(qe.metadata, rel.ForeignKey)
+	if fk == nil {
+		return fmt.Errorf("%w: foreign key %s not found on %s", customerrors.ErrInvalidModel, rel.ForeignKey, qe.metadata.Type.Name())
+	}
+	targetMeta, err := qe.db.metadataFor(reflect.New(rel.Target).Interface())
+	if err != nil {
+		return err
+	}
+	if targetMeta.PrimaryKey.SortKey != nil {
+		return fmt.Errorf("%w: belongsto target %s must have a partition-key-only primary key", customerrors.ErrInvalidModel, rel.Target.Name())
+	}
+
+	seen := make(map[string]bool)
+	keys := make([]any, 0, len(owners))
+	for _, owner := range owners {
+		keyValue := owner.FieldByIndex(fk.IndexPath)
+		if keyValue.IsZero() {
+			continue
+		}
+		groupKey := fmt.Sprint(keyValue.Interface())
+		if !seen[groupKey] {
+			seen[groupKey] = true
+			keys = append(keys, keyValue.Interface())
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	related := reflect.New(reflect.SliceOf(rel.Target))
+	if err := qe.db.Model(reflect.New(rel.Target).Interface()).WithContext(qe.ctx).BatchGet(keys, related.Interface()); err != nil {
+		return err
+	}
+
+	byKey := make(map[string]reflect.Value, related.Elem().Len())
+	for i := 0; i < related.Elem().Len(); i++ {
+		item := related.Elem().Index(i)
+		byKey[fmt.Sprint(item.FieldByIndex(targetMeta.PrimaryKey.PartitionKey.IndexPath).Interface())] = item
+	}
+
+	for _, owner := range owners {
+		item, ok := byKey[fmt.Sprint(owner.FieldByIndex(fk.IndexPath).Interface())]
+		if !ok {
+			continue
+		}
+		field := owner.FieldByIndex(rel.IndexPath)
+		if field.Kind() == reflect.Ptr {
+			ptr := reflect.New(rel.Target)
+			ptr.Elem().Set(item)
+			field.Set(ptr)
+		} else {
+			field.Set(item)
+		}
+	}
+	return nil
+}
+
+func lookupField(meta *model.Metadata, name string) *model.FieldMetadata {
+	if field := meta.Fields[name]; field != nil {
+		return field
+	}
+	return meta.FieldsByDBName[name]
+}
+
+// partitionIndexFor returns the index to query when field is the partition key of the table
+// ("") or of a GSI.
+func partitionIndexFor(meta *model.Metadata, field *model.FieldMetadata) (string, bool) {
+	if meta.PrimaryKey.PartitionKey == field {
+		return "", true
+	}
+	for _, index := range meta.Indexes {
+		if index.Type == model.GlobalSecondaryIndex && index.PartitionKey == field {
+			return index.Name, true
+		}
+	}
+	return "", false
+}
+
+// relationSlice converts loaded items ([]T) to the relationship field's type ([]T or []*T).
+func relationSlice(fieldType reflect.Type, items reflect.Value) reflect.Value {
+	if fieldType.Elem().Kind() != reflect.Ptr {
+		out := reflect.MakeSlice(fieldType, items.Len(), items.Len())
+		reflect.Copy(out, items)
+		return out
+	}
+	out := reflect.MakeSlice(fieldType, items.Len(), items.Len())
+	for i := 0; i < items.Len(); i++ {
+		out.Index(i).Set(items.Index(i).Addr())
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+type preloadCustomer struct {
+	ID   string `dynamorm:"pk,attr:id"`
+	Name string `dynamorm:"attr:name"`
+}
+
+func (preloadCustomer) TableName() string { return "customers" }
+
+type preloadLine struct {
+	ID      string `dynamorm:"pk,attr:id"`
+	OrderID string `dynamorm:"index:gsi-order,pk,attr:orderId"`
+	SKU     string `dynamorm:"attr:sku"`
+}
+
+func (preloadLine) TableName() string { return "lines" }
+
+type preloadOrder struct {
+	ID         string           `dynamorm:"pk,attr:id"`
+	CustomerID string           `dynamorm:"attr:customerId"`
+	Lines      []*preloadLine   `dynamorm:"hasmany:OrderLines,fk:OrderID"`
+	Customer   *preloadCustomer `dynamorm:"belongsto:Customer,fk:CustomerID"`
+}
+
+func (preloadOrder) TableName() string { return "orders" }
+
+func TestPreload_HasManyQueriesGSIPerOwner(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Scan": `{"Items":[{"id":{"S":"o1"}},{"id":{"S":"o2"}}],"Count":2}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.Query", []stubbedResponse{
+		{body: `{"Items":[{"id":{"S":"l1"},"orderId":{"S":"o1"},"sku":{"S":"A"}},{"id":{"S":"l2"},"orderId":{"S":"o1"},"sku":{"S":"B"}}],"Count":2}`},
+		{body: `{"Items":[],"Count":0}`},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var orders []preloadOrder
+	require.NoError(t, db.Model(&preloadOrder{}).Preload("OrderLines").All(&orders))
+
+	require.Len(t, orders, 2)
+	require.Len(t, orders[0].Lines, 2)
+	require.Equal(t, "B", orders[0].Lines[1].SKU)
+	require.NotNil(t, orders[1].Lines)
+	require.Empty(t, orders[1].Lines)
+
+	reqs := httpClient.Requests()
+	require.Equal(t, 2, countRequestsByTarget(reqs, "DynamoDB_20120810.Query"))
+	query := findRequestByTarget(reqs, "DynamoDB_20120810.Query")
+	require.Equal(t, "lines", query.Payload["TableName"])
+	require.Equal(t, "gsi-order", query.Payload["IndexName"])
+}
+
+func TestPreload_BelongsToBatchGetsDistinctKeys(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Scan":         `{"Items":[{"id":{"S":"o1"},"customerId":{"S":"c1"}},{"id":{"S":"o2"},"customerId":{"S":"c1"}},{"id":{"S":"o3"}}],"Count":3}`,
+		"DynamoDB_20120810.BatchGetItem": `{"Responses":{"customers":[{"id":{"S":"c1"},"name":{"S":"alice"}}]},"UnprocessedKeys":{}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var orders []preloadOrder
+	require.NoError(t, db.Model(&preloadOrder{}).Preload("Customer").All(&orders))
+
+	require.Equal(t, "alice", orders[0].Customer.Name)
+	require.Equal(t, "alice", orders[1].Customer.Name)
+	require.Nil(t, orders[2].Customer)
+
+	batch := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.BatchGetItem")
+	require.NotNil(t, batch)
+	keys := batch.Payload["RequestItems"].(map[string]any)["customers"].(map[string]any)["Keys"].([]any)
+	require.Len(t, keys, 1)
+}
+
+func TestPreload_First(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"o1"}}}`,
+		"DynamoDB_20120810.Query":   `{"Items":[{"id":{"S":"l1"},"orderId":{"S":"o1"},"sku":{"S":"A"}}],"Count":1}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var order preloadOrder
+	require.NoError(t, db.Model(&preloadOrder{}).Where("ID", "=", "o1").Preload("Lines").First(&order))
+	require.Len(t, order.Lines, 1)
+	require.Equal(t, "o1", order.Lines[0].OrderID)
+}
+
+func TestPreload_UnknownRelationship(t *testing.T) {
+	db := newBareDB()
+
+	var orders []preloadOrder
+	err := db.Model(&preloadOrder{}).Preload("Invoices").All(&orders)
+	require.ErrorIs(t, err, customerrors.ErrInvalidModel)
+	require.ErrorContains(t, err, "relationship Invoices not found")
+}
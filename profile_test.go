@@ -0,0 +1,56 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+type profileMember struct {
+	ID    string `dynamorm:"pk,attr:id"`
+	Email string `dynamorm:"attr:email"`
+	SSN   string `dynamorm:"attr:ssn,exclude:public"`
+}
+
+func TestQueryProfile_ProjectsProfileFields(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Scan": `{"Items":[{"id":{"S":"m1"},"email":{"S":"a@example.com"}}],"Count":1}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var members []profileMember
+	require.NoError(t, db.Model(&profileMember{}).Profile("public").All(&members))
+	require.Equal(t, "a@example.com", members[0].Email)
+	require.Empty(t, members[0].SSN)
+
+	scan := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan").Payload
+	require.NotEmpty(t, scan["ProjectionExpression"])
+	names := scan["ExpressionAttributeNames"].(map[string]any)
+	projected := make([]any, 0, len(names))
+	for _, name := range names {
+		projected = append(projected, name)
+	}
+	require.ElementsMatch(t, []any{"email", "id"}, projected)
+}
+
+func TestQueryProfile_StorageReadsEveryField(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Scan": `{"Items":[],"Count":0}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var members []profileMember
+	require.NoError(t, db.Model(&profileMember{}).Profile("public").Profile(model.StorageProfile).All(&members))
+	require.Nil(t, findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan").Payload["ProjectionExpression"])
+}
+
+func TestQueryProfile_UnknownProfile(t *testing.T) {
+	db := newBareDB()
+
+	var members []profileMember
+	err := db.Model(&profileMember{}).Profile("partner").All(&members)
+	require.ErrorIs(t, err, customerrors.ErrInvalidModel)
+}
@@ -0,0 +1,57 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type projectionAddress struct {
+	City string
+	Zip  string `json:"postalCode"`
+}
+
+type projectionLine struct {
+	SKU      string
+	Quantity int
+}
+
+type projectionOrder struct {
+	ID      string            `dynamorm:"pk"`
+	Address projectionAddress `dynamorm:"attr:shipTo"`
+	Lines   []projectionLine
+	Labels  map[string]string
+}
+
+func TestSelect_NestedDocumentPaths(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{` +
+			`"shipTo":{"M":{"City":{"S":"Austin"}}},` +
+			`"lines":{"L":[{"M":{"SKU":{"S":"sku-1"}}}]}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var order projectionOrder
+	err := db.Model(&projectionOrder{}).
+		Where("ID", "=", "o1").
+		Select("Address.City", "Lines[0].SKU", "Address.Zip", "Labels.gift").
+		First(&order)
+	require.NoError(t, err)
+
+	get := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.GetItem")
+	require.NotNil(t, get)
+	require.Equal(t, "#n1.#n2, #LINES[0].#n4, #n1.#n5, #n6.#n7", get.Payload["ProjectionExpression"])
+	require.Equal(t, map[string]any{
+		"#n1":    "shipTo",
+		"#n2":    "City",
+		"#LINES": "lines",
+		"#n4":    "SKU",
+		"#n5":    "Zip",
+		"#n6":    "labels",
+		"#n7":    "gift",
+	}, get.Payload["ExpressionAttributeNames"])
+
+	require.Equal(t, "Austin", order.Address.City)
+	require.Empty(t, order.Address.Zip)
+	require.Equal(t, []projectionLine{{SKU: "sku-1"}}, order.Lines)
+}
@@ -27,6 +27,7 @@ type queryExecutor struct {
 	db       *DB
 	metadata *model.Metadata
 	ctx      context.Context
+	op       *Operation
 }
 
 func (qe *queryExecutor) SetContext(ctx context.Context) {
@@ -36,6 +37,13 @@ func (qe *queryExecutor) SetContext(ctx context.Context) {
 	qe.ctx = ctx
 }
 
+// WithExecutorContext returns a copy of the executor bound to ctx, leaving qe unchanged.
+func (qe *queryExecutor) WithExecutorContext(ctx context.Context) query.QueryExecutor {
+	bound := *qe
+	bound.SetContext(ctx)
+	return &bound
+}
+
 func (qe *queryExecutor) ctxOrBackground() context.Context {
 	if qe.ctx != nil {
 		return qe.ctx
@@ -67,25 +75,20 @@ func (qe *queryExecutor) checkLambdaTimeout() error {
 	return nil
 }
 
+// EffectiveDeadline exposes the Lambda deadline so multi-call operations can budget
+// their remaining calls against it.
+func (qe *queryExecutor) EffectiveDeadline() (time.Time, bool) {
+	if qe == nil || qe.db == nil || qe.db.lambdaDeadline.IsZero() {
+		return time.Time{}, false
+	}
+	return qe.db.lambdaDeadline, true
+}
+
 func (qe *queryExecutor) encryptionService() (*encryption.Service, error) {
 	if qe == nil {
 		return nil, fmt.Errorf("%w: query executor is nil", customerrors.ErrEncryptionNotConfigured)
 	}
-	if qe.db == nil || qe.db.session == nil || qe.db.session.Config() == nil {
-		return nil, fmt.Errorf("%w: session is nil", customerrors.ErrEncryptionNotConfigured)
-	}
-
-	cfg := qe.db.session.Config()
-	keyARN := cfg.KMSKeyARN
-	if keyARN == "" {
-		return nil, fmt.Errorf("%w: session.Config.KMSKeyARN is empty", customerrors.ErrEncryptionNotConfigured)
-	}
-
-	if cfg.KMSClient != nil {
-		return encryption.NewServiceWithRand(keyARN, cfg.KMSClient, cfg.EncryptionRand), nil
-	}
-
-	return encryption.NewServiceFromAWSConfigWithRand(keyARN, qe.db.session.AWSConfig(), cfg.EncryptionRand), nil
+	return encryption.NewServiceForSession(qe.session())
 }
 
 func (qe *queryExecutor) failClosedIfEncrypted() error {
@@ -114,25 +117,7 @@ func (qe *queryExecutor) decryptItem(item map[string]types.AttributeValue) error
 	if err != nil {
 		return err
 	}
-
-	for attrName, attrValue := range item {
-		fieldMeta, ok := qe.metadata.FieldsByDBName[attrName]
-		if !ok || fieldMeta == nil || !fieldMeta.IsEncrypted {
-			continue
-		}
-
-		decrypted, err := svc.DecryptAttributeValue(qe.ctxOrBackground(), fieldMeta.DBName, attrValue)
-		if err != nil {
-			return &customerrors.EncryptedFieldError{
-				Operation: "decrypt",
-				Field:     fieldMeta.Name,
-				Err:       err,
-			}
-		}
-		item[attrName] = decrypted
-	}
-
-	return nil
+	return svc.DecryptItem(qe.ctxOrBackground(), qe.metadata, item)
 }
 
 func (qe *queryExecutor) encryptItem(item map[string]types.AttributeValue) error {
@@ -143,29 +128,19 @@ func (qe *queryExecutor) encryptItem(item map[string]types.AttributeValue) error
 		return err
 	}
 
-	svc, err := qe.encryptionService()
+	blindIndexKey, err := encryption.BlindIndexKey(qe.session(), qe.metadata)
 	if err != nil {
 		return err
 	}
-
-	for _, fieldMeta := range qe.metadata.Fields {
-		if fieldMeta == nil || !fieldMeta.IsEncrypted {
-			continue
-		}
-
-		av, ok := item[fieldMeta.DBName]
-		if !ok {
-			continue
-		}
-
-		encryptedAV, err := svc.EncryptAttributeValue(qe.ctxOrBackground(), fieldMeta.DBName, av)
-		if err != nil {
-			return fmt.Errorf("failed to encrypt field %s: %w", fieldMeta.DBName, err)
-		}
-		item[fieldMeta.DBName] = encryptedAV
+	if err := encryption.ApplyBlindIndexes(blindIndexKey, qe.metadata, item); err != nil {
+		return err
 	}
 
-	return nil
+	svc, err := qe.encryptionService()
+	if err != nil {
+		return err
+	}
+	return svc.EncryptItem(qe.ctxOrBackground(), qe.metadata, item)
 }
 
 func (qe *queryExecutor) unmarshalItem(item map[string]types.AttributeValue, dest any) error {
@@ -333,13 +308,20 @@ func (qe *queryExecutor) unmarshalItemToStruct(item map[string]types.AttributeVa
 		if !structField.CanSet() {
 			continue
 		}
+		if fieldMeta.IsEnum() {
+			decoded, err := fieldMeta.DecodeEnum(attrValue)
+			if err != nil {
+				return err
+			}
+			attrValue = decoded
+		}
 
 		if err := qe.db.converter.FromAttributeValue(attrValue, structField.Addr().Interface()); err != nil {
 			return fmt.Errorf("failed to unmarshal field %s: %w", fieldMeta.Name, err)
 		}
 	}
 
-	return nil
+	return qe.metadata.LoadKeyTemplates(destValue)
 }
 
 func (qe *queryExecutor) unmarshalItems(items []map[string]types.AttributeValue, dest any) error {
@@ -377,7 +359,7 @@ func (qe *queryExecutor) unmarshalItems(items []map[string]types.AttributeValue,
 
 func (qe *queryExecutor) writeItemsToDest(items []map[string]types.AttributeValue, dest any) error {
 	for _, item := range items {
-		if err := qe.decryptItem(item); err != nil {
+		if err := qe.loadItem(item); err != nil {
 			return err
 		}
 	}
@@ -399,8 +381,8 @@ type pagePaginator[Output any] interface {
 }
 
 type readPagerSpec struct {
-	buildCountPager func(*dynamodb.Client, *core.CompiledQuery) (func() bool, countPageFunc)
-	buildItemPager  func(*dynamodb.Client, *core.CompiledQuery) (func() bool, itemPageFunc)
+	buildCountPager func(session.ReadClient, *core.CompiledQuery) (func() bool, countPageFunc)
+	buildItemPager  func(session.ReadClient, *core.CompiledQuery) (func() bool, itemPageFunc)
 	nilErr          string
 	operation       string
 }
@@ -410,14 +392,14 @@ func newReadPagerSpec[Input any, Output any, P pagePaginator[Output]](
 	operation string,
 	buildInput func(*core.CompiledQuery) *Input,
 	configureCountInput func(*Input),
-	newPaginator func(*dynamodb.Client, *Input) P,
+	newPaginator func(session.ReadClient, *Input) P,
 	extractCounts func(*Output) (int32, int32),
 	extractItems func(*Output) []map[string]types.AttributeValue,
 ) readPagerSpec {
 	return readPagerSpec{
 		nilErr:    nilErr,
 		operation: operation,
-		buildCountPager: func(client *dynamodb.Client, input *core.CompiledQuery) (func() bool, countPageFunc) {
+		buildCountPager: func(client session.ReadClient, input *core.CompiledQuery) (func() bool, countPageFunc) {
 			countInput := buildInput(input)
 			configureCountInput(countInput)
 
@@ -432,7 +414,7 @@ func newReadPagerSpec[Input any, Output any, P pagePaginator[Output]](
 				return count, scannedCount, nil
 			}
 		},
-		buildItemPager: func(client *dynamodb.Client, input *core.CompiledQuery) (func() bool, itemPageFunc) {
+		buildItemPager: func(client session.ReadClient, input *core.CompiledQuery) (func() bool, itemPageFunc) {
 			itemInput := buildInput(input)
 
 			paginator := newPaginator(client, itemInput)
@@ -453,12 +435,8 @@ func (qe *queryExecutor) executeReadSpec(input *core.CompiledQuery, dest any, sp
 		dest,
 		spec.nilErr,
 		spec.operation,
-		func(client *dynamodb.Client) (func() bool, countPageFunc) {
-			return spec.buildCountPager(client, input)
-		},
-		func(client *dynamodb.Client) (func() bool, itemPageFunc) {
-			return spec.buildItemPager(client, input)
-		},
+		spec.buildCountPager,
+		spec.buildItemPager,
 	)
 }
 
@@ -470,7 +448,7 @@ type singlePageResult struct {
 }
 
 type singlePageSpec struct {
-	execute   func(context.Context, *dynamodb.Client, *core.CompiledQuery) (singlePageResult, error)
+	execute   func(context.Context, session.ReadClient, *core.CompiledQuery) (singlePageResult, error)
 	nilErr    string
 	operation string
 }
@@ -481,7 +459,7 @@ func (qe *queryExecutor) executeReadWithPaginationSpec(input *core.CompiledQuery
 		dest,
 		spec.nilErr,
 		spec.operation,
-		func(client *dynamodb.Client, ctx context.Context) (singlePageResult, error) {
+		func(client session.ReadClient, ctx context.Context, input *core.CompiledQuery) (singlePageResult, error) {
 			return spec.execute(ctx, client, input)
 		},
 	)
@@ -513,11 +491,11 @@ func configureScanCountInput(scanInput *dynamodb.ScanInput) {
 	scanInput.Limit = nil
 }
 
-func newQueryPaginator(client *dynamodb.Client, queryInput *dynamodb.QueryInput) *dynamodb.QueryPaginator {
+func newQueryPaginator(client session.ReadClient, queryInput *dynamodb.QueryInput) *dynamodb.QueryPaginator {
 	return dynamodb.NewQueryPaginator(client, queryInput)
 }
 
-func newScanPaginator(client *dynamodb.Client, scanInput *dynamodb.ScanInput) *dynamodb.ScanPaginator {
+func newScanPaginator(client session.ReadClient, scanInput *dynamodb.ScanInput) *dynamodb.ScanPaginator {
 	return dynamodb.NewScanPaginator(client, scanInput)
 }
 
@@ -551,7 +529,7 @@ func newSinglePageResult(
 	}
 }
 
-func executeQuerySinglePage(ctx context.Context, client *dynamodb.Client, input *core.CompiledQuery) (singlePageResult, error) {
+func executeQuerySinglePage(ctx context.Context, client session.ReadClient, input *core.CompiledQuery) (singlePageResult, error) {
 	out, err := client.Query(ctx, buildDynamoQueryInput(input))
 	if err != nil {
 		return singlePageResult{}, fmt.Errorf("failed to execute query: %w", err)
@@ -559,7 +537,7 @@ func executeQuerySinglePage(ctx context.Context, client *dynamodb.Client, input
 	return newSinglePageResult(out.Items, out.Count, out.ScannedCount, out.LastEvaluatedKey), nil
 }
 
-func executeScanSinglePage(ctx context.Context, client *dynamodb.Client, input *core.CompiledQuery) (singlePageResult, error) {
+func executeScanSinglePage(ctx context.Context, client session.ReadClient, input *core.CompiledQuery) (singlePageResult, error) {
 	out, err := client.Scan(ctx, buildDynamoScanInput(input))
 	if err != nil {
 		return singlePageResult{}, fmt.Errorf("failed to execute scan: %w", err)
@@ -599,22 +577,34 @@ var scanSinglePageSpec = singlePageSpec{
 	execute:   executeScanSinglePage,
 }
 
-func (qe *queryExecutor) readClient(input *core.CompiledQuery, nilErr string, operation string) (*dynamodb.Client, error) {
+// readClient checks a Query or Scan and returns the client to send it with and the
+// request to send, confined to the DB's tenant.
+func (qe *queryExecutor) readClient(input *core.CompiledQuery, nilErr string, operation string) (session.ReadClient, *core.CompiledQuery, error) {
 	if input == nil {
-		return nil, errors.New(nilErr)
+		return nil, nil, errors.New(nilErr)
 	}
 	if err := qe.checkLambdaTimeout(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if err := qe.failClosedIfEncrypted(); err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if err := qe.checkAccessPattern(input); err != nil {
+		return nil, nil, err
+	}
+	if err := qe.checkQueryLeadingKeys(input); err != nil {
+		return nil, nil, err
+	}
+	scoped, err := qe.scopeTenantRead(input)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	client, err := qe.session().Client()
+	client, err := qe.sessionReadClient()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get client for %s: %w", operation, err)
+		return nil, nil, fmt.Errorf("failed to get client for %s: %w", operation, err)
 	}
-	return client, nil
+	return client, scoped, nil
 }
 
 func (qe *queryExecutor) executeRead(
@@ -622,16 +612,16 @@ func (qe *queryExecutor) executeRead(
 	dest any,
 	nilErr string,
 	operation string,
-	buildCountPager func(*dynamodb.Client) (func() bool, countPageFunc),
-	buildItemPager func(*dynamodb.Client) (func() bool, itemPageFunc),
+	buildCountPager func(session.ReadClient, *core.CompiledQuery) (func() bool, countPageFunc),
+	buildItemPager func(session.ReadClient, *core.CompiledQuery) (func() bool, itemPageFunc),
 ) error {
-	client, err := qe.readClient(input, nilErr, operation)
+	client, input, err := qe.readClient(input, nilErr, operation)
 	if err != nil {
 		return err
 	}
 
 	if isCountSelect(input.Select) {
-		hasMorePages, nextPage := buildCountPager(client)
+		hasMorePages, nextPage := buildCountPager(client, input)
 		totalCount, scannedCount, countErr := collectPaginatedCounts(qe.ctxOrBackground(), hasMorePages, nextPage)
 		if countErr != nil {
 			return countErr
@@ -639,12 +629,17 @@ func (qe *queryExecutor) executeRead(
 		return writeCountResult(dest, totalCount, scannedCount)
 	}
 
-	hasMorePages, nextPage := buildItemPager(client)
+	hasMorePages, nextPage := buildItemPager(client, input)
 	limit, hasLimit := compiledQueryLimit(input)
-	items, itemsErr := collectPaginatedItems(qe.ctxOrBackground(), hasMorePages, nextPage, limit, hasLimit, true)
+	items, itemsErr := qe.dedupRead(operation, input, nil, func() ([]map[string]types.AttributeValue, error) {
+		return collectPaginatedItems(qe.ctxOrBackground(), hasMorePages, nextPage, limit, hasLimit, true)
+	})
 	if itemsErr != nil {
 		return itemsErr
 	}
+	if err := qe.checkRequiredFields(input.TableName, input.IndexName, input.ProjectionExpression, items); err != nil {
+		return err
+	}
 
 	return qe.writeItemsToDest(items, dest)
 }
@@ -654,17 +649,20 @@ func (qe *queryExecutor) executeReadWithPagination(
 	dest any,
 	nilErr string,
 	operation string,
-	execute func(*dynamodb.Client, context.Context) (singlePageResult, error),
+	execute func(session.ReadClient, context.Context, *core.CompiledQuery) (singlePageResult, error),
 ) (singlePageResult, error) {
-	client, err := qe.readClient(input, nilErr, operation)
+	client, input, err := qe.readClient(input, nilErr, operation)
 	if err != nil {
 		return singlePageResult{}, err
 	}
 
-	result, execErr := execute(client, qe.ctxOrBackground())
+	result, execErr := execute(client, qe.ctxOrBackground(), input)
 	if execErr != nil {
 		return singlePageResult{}, execErr
 	}
+	if err := qe.checkRequiredFields(input.TableName, input.IndexName, input.ProjectionExpression, result.items); err != nil {
+		return singlePageResult{}, err
+	}
 
 	if err := qe.writeItemsToDest(result.items, dest); err != nil {
 		return singlePageResult{}, err
@@ -674,14 +672,60 @@ func (qe *queryExecutor) executeReadWithPagination(
 }
 
 func (qe *queryExecutor) ExecuteQuery(input *core.CompiledQuery, dest any) error {
+	op := compiledOperation(OperationQuery, input)
+	op.Dest = dest
+	return qe.intercept(op, func(qe *queryExecutor, op *Operation) error {
+		return qe.executeQuery(op.Input, op.Dest)
+	})
+}
+
+func (qe *queryExecutor) executeQuery(input *core.CompiledQuery, dest any) error {
+	if err := qe.beginOperation(); err != nil {
+		return err
+	}
+	defer qe.endOperation()
+	defer qe.observeCompiled("Query", input, time.Now())
 	return qe.executeReadSpec(input, dest, queryReadPagerSpec)
 }
 
 func (qe *queryExecutor) ExecuteScan(input *core.CompiledQuery, dest any) error {
+	op := compiledOperation(OperationScan, input)
+	op.Dest = dest
+	return qe.intercept(op, func(qe *queryExecutor, op *Operation) error {
+		return qe.executeScan(op.Input, op.Dest)
+	})
+}
+
+func (qe *queryExecutor) executeScan(input *core.CompiledQuery, dest any) error {
+	if err := qe.beginOperation(); err != nil {
+		return err
+	}
+	defer qe.endOperation()
+	defer qe.observeCompiled("Scan", input, time.Now())
 	return qe.executeReadSpec(input, dest, scanReadPagerSpec)
 }
 
 func (qe *queryExecutor) ExecuteQueryWithPagination(input *core.CompiledQuery, dest any) (*query.QueryResult, error) {
+	var result *query.QueryResult
+	op := compiledOperation(OperationQuery, input)
+	op.Dest = dest
+	err := qe.intercept(op, func(qe *queryExecutor, op *Operation) error {
+		var err error
+		result, err = qe.executeQueryWithPagination(op.Input, op.Dest)
+		if result != nil {
+			result.ConsumedCapacity = op.ConsumedCapacity
+		}
+		return err
+	})
+	return result, err
+}
+
+func (qe *queryExecutor) executeQueryWithPagination(input *core.CompiledQuery, dest any) (*query.QueryResult, error) {
+	if err := qe.beginOperation(); err != nil {
+		return nil, err
+	}
+	defer qe.endOperation()
+	defer qe.observeCompiled("Query", input, time.Now())
 	return executeReadWithPaginationConverted(
 		qe,
 		input,
@@ -699,6 +743,26 @@ func (qe *queryExecutor) ExecuteQueryWithPagination(input *core.CompiledQuery, d
 }
 
 func (qe *queryExecutor) ExecuteScanWithPagination(input *core.CompiledQuery, dest any) (*query.ScanResult, error) {
+	var result *query.ScanResult
+	op := compiledOperation(OperationScan, input)
+	op.Dest = dest
+	err := qe.intercept(op, func(qe *queryExecutor, op *Operation) error {
+		var err error
+		result, err = qe.executeScanWithPagination(op.Input, op.Dest)
+		if result != nil {
+			result.ConsumedCapacity = op.ConsumedCapacity
+		}
+		return err
+	})
+	return result, err
+}
+
+func (qe *queryExecutor) executeScanWithPagination(input *core.CompiledQuery, dest any) (*query.ScanResult, error) {
+	if err := qe.beginOperation(); err != nil {
+		return nil, err
+	}
+	defer qe.endOperation()
+	defer qe.observeCompiled("Scan", input, time.Now())
 	return executeReadWithPaginationConverted(
 		qe,
 		input,
@@ -716,6 +780,20 @@ func (qe *queryExecutor) ExecuteScanWithPagination(input *core.CompiledQuery, de
 }
 
 func (qe *queryExecutor) ExecuteGetItem(input *core.CompiledQuery, key map[string]types.AttributeValue, dest any) error {
+	op := compiledOperation(OperationGetItem, input)
+	op.Key = key
+	op.Dest = dest
+	return qe.intercept(op, func(qe *queryExecutor, op *Operation) error {
+		return qe.executeGetItem(op.Input, op.Key, op.Dest)
+	})
+}
+
+func (qe *queryExecutor) executeGetItem(input *core.CompiledQuery, key map[string]types.AttributeValue, dest any) error {
+	if err := qe.beginOperation(); err != nil {
+		return err
+	}
+	defer qe.endOperation()
+	defer qe.observeCompiled("GetItem", input, time.Now())
 	if input == nil {
 		return fmt.Errorf("compiled query cannot be nil")
 	}
@@ -728,48 +806,99 @@ func (qe *queryExecutor) ExecuteGetItem(input *core.CompiledQuery, key map[strin
 	if err := qe.failClosedIfEncrypted(); err != nil {
 		return err
 	}
-
-	client, err := qe.session().Client()
-	if err != nil {
-		return fmt.Errorf("failed to get client for get item: %w", err)
-	}
-
-	getInput := &dynamodb.GetItemInput{
-		TableName: aws.String(input.TableName),
-		Key:       key,
+	if err := qe.checkGetItemAccessPattern(input.TableName, key); err != nil {
+		return err
 	}
-
-	if input.ProjectionExpression != "" {
-		getInput.ProjectionExpression = aws.String(input.ProjectionExpression)
+	if err := qe.checkItemLeadingKeys("GetItem", input.TableName, key); err != nil {
+		return err
 	}
-	if len(input.ExpressionAttributeNames) > 0 {
-		getInput.ExpressionAttributeNames = input.ExpressionAttributeNames
+	scope, err := qe.tenantScope()
+	if err != nil {
+		return err
 	}
-	if input.ConsistentRead != nil {
-		getInput.ConsistentRead = input.ConsistentRead
+	projection, names := input.ProjectionExpression, input.ExpressionAttributeNames
+	if scope != nil {
+		if err := scope.checkKey(key); err != nil {
+			return err
+		}
+		projection, names = scope.project(projection, names)
 	}
 
-	out, err := client.GetItem(qe.ctxOrBackground(), getInput)
+	fetch := func() (map[string]types.AttributeValue, error) {
+		client, err := qe.sessionReadClient()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client for get item: %w", err)
+		}
+
+		getInput := &dynamodb.GetItemInput{
+			TableName: aws.String(input.TableName),
+			Key:       key,
+		}
+
+		if projection != "" {
+			getInput.ProjectionExpression = aws.String(projection)
+		}
+		if len(names) > 0 {
+			getInput.ExpressionAttributeNames = names
+		}
+		if input.ConsistentRead != nil {
+			getInput.ConsistentRead = input.ConsistentRead
+		}
+
+		out, err := client.GetItem(qe.ctxOrBackground(), getInput)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get item: %w", err)
+		}
+		return out.Item, nil
+	}
+	items, err := qe.dedupRead(OperationGetItem, input, key, func() ([]map[string]types.AttributeValue, error) {
+		item, err := qe.cachedGetItem(input, key, fetch)
+		if err != nil || item == nil {
+			return nil, err
+		}
+		return []map[string]types.AttributeValue{item}, nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get item: %w", err)
+		return err
 	}
-	if out.Item == nil {
+	if len(items) == 0 {
 		return customerrors.ErrItemNotFound
 	}
+	item := items[0]
 
-	if err := qe.decryptItem(out.Item); err != nil {
+	// Check the tenant first, so another tenant's item is never decrypted or fetched from S3
+	if scope != nil && !scope.owns(item) {
+		return customerrors.ErrItemNotFound
+	}
+	if err := qe.loadItem(item); err != nil {
+		return err
+	}
+	if err := qe.checkRequiredFields(input.TableName, "", input.ProjectionExpression, []map[string]types.AttributeValue{item}); err != nil {
 		return err
 	}
 
 	if rawDest, ok := dest.(*map[string]types.AttributeValue); ok && rawDest != nil {
-		*rawDest = out.Item
+		*rawDest = item
 		return nil
 	}
 
-	return qe.unmarshalItem(out.Item, dest)
+	return qe.unmarshalItem(item, dest)
 }
 
 func (qe *queryExecutor) ExecutePutItem(input *core.CompiledQuery, item map[string]types.AttributeValue) error {
+	op := compiledOperation(OperationPutItem, input)
+	op.Item = item
+	return qe.intercept(op, func(qe *queryExecutor, op *Operation) error {
+		return qe.executePutItem(op.Input, op.Item)
+	})
+}
+
+func (qe *queryExecutor) executePutItem(input *core.CompiledQuery, item map[string]types.AttributeValue) error {
+	if err := qe.beginOperation(); err != nil {
+		return err
+	}
+	defer qe.endOperation()
+	defer qe.observeCompiled("PutItem", input, time.Now())
 	if input == nil {
 		return fmt.Errorf("compiled query cannot be nil")
 	}
@@ -782,12 +911,34 @@ func (qe *queryExecutor) ExecutePutItem(input *core.CompiledQuery, item map[stri
 	if err := qe.failClosedIfEncrypted(); err != nil {
 		return err
 	}
+	scope, err := qe.tenantScope()
+	if err != nil {
+		return err
+	}
+	if scope != nil {
+		if err := scope.stamp(item); err != nil {
+			return err
+		}
+		input = scope.absentOrOwnedCondition().scopeCondition(input)
+	}
 
+	if err := qe.offloadItem(input.TableName, item); err != nil {
+		return err
+	}
 	if err := qe.encryptItem(item); err != nil {
 		return err
 	}
+	if err := qe.checkItemSize("PutItem", item); err != nil {
+		return err
+	}
+	if err := qe.checkItemLeadingKeys("PutItem", input.TableName, item); err != nil {
+		return err
+	}
+	if qe.hasUniqueFields() {
+		return qe.putUnique(input, item)
+	}
 
-	client, err := qe.session().Client()
+	client, err := qe.sessionWriteClient()
 	if err != nil {
 		return fmt.Errorf("failed to get client for put item: %w", err)
 	}
@@ -806,14 +957,21 @@ func (qe *queryExecutor) ExecutePutItem(input *core.CompiledQuery, item map[stri
 	if len(input.ExpressionAttributeValues) > 0 {
 		putInput.ExpressionAttributeValues = input.ExpressionAttributeValues
 	}
+	if qe.tracksOverflow() {
+		putInput.ReturnValues = types.ReturnValueAllOld
+	}
 
-	_, err = client.PutItem(qe.ctxOrBackground(), putInput)
+	output, err := client.PutItem(qe.ctxOrBackground(), putInput)
 	if err != nil {
 		if isConditionalCheckFailedException(err) {
+			qe.recordConditionFailure("PutItem", input.TableName, item)
 			return customerrors.ErrConditionFailed
 		}
 		return fmt.Errorf("failed to put item: %w", err)
 	}
+	if output != nil {
+		qe.cleanupOverflow(output.Attributes, item)
+	}
 
 	return nil
 }
@@ -856,11 +1014,27 @@ func (qe *queryExecutor) buildUpdateItemInput(input *core.CompiledQuery, key map
 	if len(exprAttrValues) > 0 {
 		updateInput.ExpressionAttributeValues = exprAttrValues
 	}
+	if err := qe.offloadUpdateValues(updateInput); err != nil {
+		return nil, err
+	}
 
 	return updateInput, nil
 }
 
 func (qe *queryExecutor) ExecuteUpdateItem(input *core.CompiledQuery, key map[string]types.AttributeValue) error {
+	op := compiledOperation(OperationUpdateItem, input)
+	op.Key = key
+	return qe.intercept(op, func(qe *queryExecutor, op *Operation) error {
+		return qe.executeUpdateItem(op.Input, op.Key)
+	})
+}
+
+func (qe *queryExecutor) executeUpdateItem(input *core.CompiledQuery, key map[string]types.AttributeValue) error {
+	if err := qe.beginOperation(); err != nil {
+		return err
+	}
+	defer qe.endOperation()
+	defer qe.observeCompiled("UpdateItem", input, time.Now())
 	if input == nil {
 		return fmt.Errorf("compiled query cannot be nil")
 	}
@@ -874,7 +1048,7 @@ func (qe *queryExecutor) ExecuteUpdateItem(input *core.CompiledQuery, key map[st
 		return err
 	}
 
-	client, err := qe.session().Client()
+	client, err := qe.sessionWriteClient()
 	if err != nil {
 		return fmt.Errorf("failed to get client for update item: %w", err)
 	}
@@ -883,19 +1057,63 @@ func (qe *queryExecutor) ExecuteUpdateItem(input *core.CompiledQuery, key map[st
 	if err != nil {
 		return err
 	}
+	if err := qe.checkItemSize("UpdateItem", updateSizeItem(key, updateInput)); err != nil {
+		return err
+	}
+	if err := qe.checkItemLeadingKeys("UpdateItem", input.TableName, key); err != nil {
+		return err
+	}
+	if err := qe.scopeTenantUpdate(updateInput); err != nil {
+		return err
+	}
+	if (qe.hasUniqueFields() && qe.updatesUniqueFields(updateInput)) || qe.auditsWrites(input.TableName) {
+		return qe.updateUnique(updateInput)
+	}
+
+	assigned := qe.assignedOverflowValues(updateInput)
+	if len(assigned) > 0 && (updateInput.ReturnValues == "" || updateInput.ReturnValues == types.ReturnValueNone) {
+		updateInput.ReturnValues = types.ReturnValueUpdatedOld
+	}
 
-	_, err = client.UpdateItem(qe.ctxOrBackground(), updateInput)
+	output, err := client.UpdateItem(qe.ctxOrBackground(), updateInput)
 	if err != nil {
 		if isConditionalCheckFailedException(err) {
+			qe.recordConditionFailure("UpdateItem", input.TableName, key)
 			return customerrors.ErrConditionFailed
 		}
 		return fmt.Errorf("failed to update item: %w", err)
 	}
+	if len(assigned) > 0 && updateInput.ReturnValues == types.ReturnValueUpdatedOld && output != nil {
+		old := make(map[string]types.AttributeValue, len(assigned))
+		for name := range assigned {
+			if av, ok := output.Attributes[name]; ok {
+				old[name] = av
+			}
+		}
+		qe.cleanupOverflow(old, assigned)
+	}
 
 	return nil
 }
 
 func (qe *queryExecutor) ExecuteUpdateItemWithResult(input *core.CompiledQuery, key map[string]types.AttributeValue) (*core.UpdateResult, error) {
+	var result *core.UpdateResult
+	op := compiledOperation(OperationUpdateItem, input)
+	op.Key = key
+	err := qe.intercept(op, func(qe *queryExecutor, op *Operation) error {
+		var err error
+		result, err = qe.executeUpdateItemWithResult(op.Input, op.Key)
+		return err
+	})
+	return result, err
+}
+
+func (qe *queryExecutor) executeUpdateItemWithResult(input *core.CompiledQuery, key map[string]types.AttributeValue) (*core.UpdateResult, error) {
+	if err := qe.beginOperation(); err != nil {
+		return nil, err
+	}
+	defer qe.endOperation()
+	defer qe.observeCompiled("UpdateItem", input, time.Now())
 	if input == nil {
 		return nil, fmt.Errorf("compiled query cannot be nil")
 	}
@@ -909,7 +1127,7 @@ func (qe *queryExecutor) ExecuteUpdateItemWithResult(input *core.CompiledQuery,
 		return nil, err
 	}
 
-	client, err := qe.session().Client()
+	client, err := qe.sessionWriteClient()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client for update item: %w", err)
 	}
@@ -918,16 +1136,32 @@ func (qe *queryExecutor) ExecuteUpdateItemWithResult(input *core.CompiledQuery,
 	if err != nil {
 		return nil, err
 	}
+	if err := qe.checkItemSize("UpdateItem", updateSizeItem(key, updateInput)); err != nil {
+		return nil, err
+	}
+	if err := qe.checkItemLeadingKeys("UpdateItem", input.TableName, key); err != nil {
+		return nil, err
+	}
+	if err := qe.scopeTenantUpdate(updateInput); err != nil {
+		return nil, err
+	}
+	if qe.hasUniqueFields() && qe.updatesUniqueFields(updateInput) {
+		return nil, fmt.Errorf("%w: updates that return values cannot change unique fields", customerrors.ErrInvalidOperator)
+	}
+	if qe.auditsWrites(input.TableName) {
+		return qe.updateAuditedWithResult(updateInput)
+	}
 
 	output, err := client.UpdateItem(qe.ctxOrBackground(), updateInput)
 	if err != nil {
 		if isConditionalCheckFailedException(err) {
+			qe.recordConditionFailure("UpdateItem", input.TableName, key)
 			return nil, customerrors.ErrConditionFailed
 		}
 		return nil, fmt.Errorf("failed to update item: %w", err)
 	}
 
-	if err := qe.decryptItem(output.Attributes); err != nil {
+	if err := qe.loadItem(output.Attributes); err != nil {
 		return nil, err
 	}
 
@@ -937,6 +1171,19 @@ func (qe *queryExecutor) ExecuteUpdateItemWithResult(input *core.CompiledQuery,
 }
 
 func (qe *queryExecutor) ExecuteDeleteItem(input *core.CompiledQuery, key map[string]types.AttributeValue) error {
+	op := compiledOperation(OperationDeleteItem, input)
+	op.Key = key
+	return qe.intercept(op, func(qe *queryExecutor, op *Operation) error {
+		return qe.executeDeleteItem(op.Input, op.Key)
+	})
+}
+
+func (qe *queryExecutor) executeDeleteItem(input *core.CompiledQuery, key map[string]types.AttributeValue) error {
+	if err := qe.beginOperation(); err != nil {
+		return err
+	}
+	defer qe.endOperation()
+	defer qe.observeCompiled("DeleteItem", input, time.Now())
 	if input == nil {
 		return fmt.Errorf("compiled query cannot be nil")
 	}
@@ -949,8 +1196,24 @@ func (qe *queryExecutor) ExecuteDeleteItem(input *core.CompiledQuery, key map[st
 	if err := qe.failClosedIfEncrypted(); err != nil {
 		return err
 	}
+	if err := qe.checkItemLeadingKeys("DeleteItem", input.TableName, key); err != nil {
+		return err
+	}
+	scope, err := qe.tenantScope()
+	if err != nil {
+		return err
+	}
+	if scope != nil {
+		if err := scope.checkKey(key); err != nil {
+			return err
+		}
+		input = scope.absentOrOwnedCondition().scopeCondition(input)
+	}
+	if qe.hasUniqueFields() || qe.auditsWrites(input.TableName) {
+		return qe.deleteUnique(input, key)
+	}
 
-	client, err := qe.session().Client()
+	client, err := qe.sessionWriteClient()
 	if err != nil {
 		return fmt.Errorf("failed to get client for delete item: %w", err)
 	}
@@ -970,32 +1233,79 @@ func (qe *queryExecutor) ExecuteDeleteItem(input *core.CompiledQuery, key map[st
 		deleteInput.ExpressionAttributeValues = input.ExpressionAttributeValues
 	}
 
-	_, err = client.DeleteItem(qe.ctxOrBackground(), deleteInput)
+	if qe.tracksOverflow() {
+		deleteInput.ReturnValues = types.ReturnValueAllOld
+	}
+
+	output, err := client.DeleteItem(qe.ctxOrBackground(), deleteInput)
 	if err != nil {
 		if isConditionalCheckFailedException(err) {
+			qe.recordConditionFailure("DeleteItem", input.TableName, key)
 			return customerrors.ErrConditionFailed
 		}
 		return fmt.Errorf("failed to delete item: %w", err)
 	}
+	if output != nil {
+		qe.cleanupOverflow(output.Attributes, nil)
+	}
 
 	return nil
 }
 
 func (qe *queryExecutor) ExecuteBatchGet(input *query.CompiledBatchGet, opts *core.BatchGetOptions) ([]map[string]types.AttributeValue, error) {
+	if input == nil {
+		return nil, fmt.Errorf("compiled batch get cannot be nil")
+	}
+	var items []map[string]types.AttributeValue
+	op := &Operation{Type: OperationBatchGetItem, Table: input.TableName, Keys: input.Keys}
+	err := qe.intercept(op, func(qe *queryExecutor, op *Operation) error {
+		compiled := *input
+		compiled.TableName = op.Table
+		compiled.Keys = op.Keys
+		var err error
+		items, err = qe.executeBatchGet(&compiled, opts)
+		return err
+	})
+	return items, err
+}
+
+func (qe *queryExecutor) executeBatchGet(input *query.CompiledBatchGet, opts *core.BatchGetOptions) ([]map[string]types.AttributeValue, error) {
 	if input == nil {
 		return nil, fmt.Errorf("compiled batch get cannot be nil")
 	}
 	if len(input.Keys) == 0 {
 		return nil, nil
 	}
+	if err := qe.beginOperation(); err != nil {
+		return nil, err
+	}
+	defer qe.endOperation()
+	defer qe.observeLatency("BatchGetItem", input.TableName, "", time.Now())
 	if err := qe.checkLambdaTimeout(); err != nil {
 		return nil, err
 	}
 	if err := qe.failClosedIfEncrypted(); err != nil {
 		return nil, err
 	}
+	if err := qe.checkItemLeadingKeys("BatchGetItem", input.TableName, input.Keys...); err != nil {
+		return nil, err
+	}
+	scope, err := qe.tenantScope()
+	if err != nil {
+		return nil, err
+	}
+	if scope != nil {
+		for _, key := range input.Keys {
+			if err := scope.checkKey(key); err != nil {
+				return nil, err
+			}
+		}
+		scoped := *input
+		scoped.ProjectionExpression, scoped.ExpressionAttributeNames = scope.project(input.ProjectionExpression, input.ExpressionAttributeNames)
+		input = &scoped
+	}
 
-	client, err := qe.session().Client()
+	client, err := qe.sessionReadClient()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client for batch get: %w", err)
 	}
@@ -1006,7 +1316,18 @@ func (qe *queryExecutor) ExecuteBatchGet(input *query.CompiledBatchGet, opts *co
 		input.TableName: buildKeysAndAttributes(input),
 	}
 
-	return qe.executeBatchGetWithRetry(client, requestItems, input.TableName, normalizedOpts)
+	var keep func(map[string]types.AttributeValue) bool
+	if scope != nil {
+		keep = scope.owns
+	}
+	items, err := qe.executeBatchGetWithRetry(client, requestItems, input.TableName, normalizedOpts, keep)
+	if err != nil {
+		return items, err
+	}
+	if err := qe.checkRequiredFields(input.TableName, "", input.ProjectionExpression, items); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 func normalizeBatchGetOptions(opts *core.BatchGetOptions) *core.BatchGetOptions {
@@ -1016,11 +1337,14 @@ func normalizeBatchGetOptions(opts *core.BatchGetOptions) *core.BatchGetOptions
 	return opts.Clone()
 }
 
+// executeBatchGetWithRetry reads requestItems, retrying unprocessed keys under opts. Items
+// keep rejects, when it is set, are dropped before they are loaded.
 func (qe *queryExecutor) executeBatchGetWithRetry(
-	client *dynamodb.Client,
+	client session.ReadClient,
 	requestItems map[string]types.KeysAndAttributes,
 	tableName string,
 	opts *core.BatchGetOptions,
+	keep func(map[string]types.AttributeValue) bool,
 ) ([]map[string]types.AttributeValue, error) {
 	var collected []map[string]types.AttributeValue
 	retryAttempt := 0
@@ -1034,7 +1358,10 @@ func (qe *queryExecutor) executeBatchGetWithRetry(
 		}
 
 		for _, item := range output.Responses[tableName] {
-			if err := qe.decryptItem(item); err != nil {
+			if keep != nil && !keep(item) {
+				continue
+			}
+			if err := qe.loadItem(item); err != nil {
 				return collected, err
 			}
 			collected = append(collected, item)
@@ -1109,6 +1436,22 @@ func (qe *queryExecutor) ExecuteBatchWrite(input *query.CompiledBatchWrite) erro
 }
 
 func (qe *queryExecutor) ExecuteBatchWriteItem(tableName string, writeRequests []types.WriteRequest) (*core.BatchWriteResult, error) {
+	var result *core.BatchWriteResult
+	op := &Operation{Type: OperationBatchWriteItem, Table: tableName, Writes: writeRequests}
+	err := qe.intercept(op, func(qe *queryExecutor, op *Operation) error {
+		var err error
+		result, err = qe.executeBatchWriteItem(op.Table, op.Writes)
+		return err
+	})
+	return result, err
+}
+
+func (qe *queryExecutor) executeBatchWriteItem(tableName string, writeRequests []types.WriteRequest) (*core.BatchWriteResult, error) {
+	if err := qe.beginOperation(); err != nil {
+		return nil, err
+	}
+	defer qe.endOperation()
+	defer qe.observeLatency("BatchWriteItem", tableName, "", time.Now())
 	if err := qe.checkLambdaTimeout(); err != nil {
 		return nil, err
 	}
@@ -1121,6 +1464,32 @@ func (qe *queryExecutor) ExecuteBatchWriteItem(tableName string, writeRequests [
 	if err := qe.failClosedIfEncrypted(); err != nil {
 		return nil, err
 	}
+	if err := qe.checkBatchWriteLeadingKeys(tableName, writeRequests); err != nil {
+		return nil, err
+	}
+	if qe.hasUniqueFields() {
+		return nil, fmt.Errorf("%w: batch writes cannot maintain unique fields; write the items one at a time", customerrors.ErrInvalidOperator)
+	}
+	if qe.auditsWrites(tableName) && hasDeleteRequests(writeRequests) {
+		return nil, fmt.Errorf("%w: batch deletes cannot write audit records; delete the items one at a time", customerrors.ErrInvalidOperator)
+	}
+	if scope, err := qe.tenantScope(); err != nil {
+		return nil, err
+	} else if scope != nil {
+		if err := scope.scopeBatchWrite(qe.keyAttributes(""), writeRequests); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range writeRequests {
+		put := writeRequests[i].PutRequest
+		if put == nil || len(put.Item) == 0 {
+			continue
+		}
+		if err := qe.offloadItem(tableName, put.Item); err != nil {
+			return nil, err
+		}
+	}
 
 	if qe.metadata != nil && encryption.MetadataHasEncryptedFields(qe.metadata) {
 		for i := range writeRequests {
@@ -1134,7 +1503,7 @@ func (qe *queryExecutor) ExecuteBatchWriteItem(tableName string, writeRequests [
 		}
 	}
 
-	client, err := qe.session().Client()
+	client, err := qe.sessionWriteClient()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client for batch write: %w", err)
 	}
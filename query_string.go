@@ -0,0 +1,79 @@
+package dynamorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+	"github.com/pay-theory/dynamorm/pkg/querystring"
+)
+
+// QueryString parses an SQL-like query and returns the equivalent fluent query, so
+// admin tooling can accept ad-hoc queries without exposing raw expressions:
+//
+//	var orders []Order
+//	err := db.QueryString("from Order where CustomerID = ? and CreatedAt > ? limit 50", customerID, since).All(&orders)
+//
+// The model is named by its Go type or table name and must already be registered (via
+// Model, AutoMigrate, or RegisterModel). Every referenced field must exist on the model
+// and a named index must be declared on it. Conditions go through Where, so the builder
+// chooses between Query and Scan and selects an index exactly as it would for code.
+// See package querystring for the full grammar.
+func (db *DB) QueryString(query string, args ...any) core.Query {
+	stmt, err := querystring.Parse(query, args...)
+	if err != nil {
+		return &errorQuery{err: err}
+	}
+
+	meta, err := db.registry.GetMetadataByName(stmt.Model)
+	if err != nil {
+		return &errorQuery{err: err}
+	}
+	if err := validateQueryStringStatement(meta, stmt); err != nil {
+		return &errorQuery{err: err}
+	}
+
+	q := db.Model(reflect.New(meta.Type).Interface())
+	if stmt.Index != "" {
+		q = q.Index(stmt.Index)
+	}
+	for _, cond := range stmt.Conditions {
+		q = q.Where(cond.Field, cond.Operator, cond.Value)
+	}
+	if stmt.OrderBy != "" {
+		q = q.OrderBy(stmt.OrderBy, stmt.Order)
+	}
+	if stmt.Limit > 0 {
+		q = q.Limit(stmt.Limit)
+	}
+	if len(stmt.Select) > 0 {
+		q = q.Select(stmt.Select...)
+	}
+	if stmt.ConsistentRead {
+		q = q.ConsistentRead()
+	}
+	return q
+}
+
+func validateQueryStringStatement(meta *model.Metadata, stmt *querystring.Statement) error {
+	for _, field := range stmt.Fields() {
+		root, _, _ := strings.Cut(field, ".")
+		if _, ok := meta.Fields[root]; ok {
+			continue
+		}
+		if _, ok := meta.FieldsByDBName[root]; ok {
+			continue
+		}
+		return fmt.Errorf("%w: unknown field %q on %s", customerrors.ErrInvalidModel, field, meta.Type.Name())
+	}
+
+	if stmt.Index != "" {
+		if findIndexSchema(meta, stmt.Index) == nil {
+			return fmt.Errorf("%w: %s on %s", customerrors.ErrIndexNotFound, stmt.Index, meta.Type.Name())
+		}
+	}
+	return nil
+}
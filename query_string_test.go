@@ -0,0 +1,81 @@
+package dynamorm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/querystring"
+)
+
+type queryStringOrder struct {
+	TenantID   string `dynamorm:"pk,attr:tenantId"`
+	OrderID    string `dynamorm:"sk,attr:orderId"`
+	CustomerID string `dynamorm:"index:gsi-customer,pk,attr:customerId"`
+	CreatedAt  string `dynamorm:"index:gsi-customer,sk,attr:createdAt"`
+	Status     string `dynamorm:"attr:status"`
+}
+
+func (queryStringOrder) TableName() string {
+	return "query_string_orders"
+}
+
+func newQueryStringTestDB(t *testing.T, httpClient *capturingHTTPClient) *DB {
+	t.Helper()
+	db := newStubbedDB(t, httpClient)
+	require.NoError(t, db.registry.Register(&queryStringOrder{}))
+	return db
+}
+
+func TestQueryString_UsesIndexQuery(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[{"tenantId":{"S":"t1"},"orderId":{"S":"o1"},"customerId":{"S":"c1"},"createdAt":{"S":"2024-02-01"}}],"Count":1,"ScannedCount":1}`,
+	})
+	db := newQueryStringTestDB(t, httpClient)
+
+	var out []queryStringOrder
+	err := db.QueryString("from QueryStringOrder where CustomerID = ? and CreatedAt > ? limit 50", "c1", "2024-01-01").All(&out)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	require.Equal(t, "o1", out[0].OrderID)
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, req)
+	require.Equal(t, "query_string_orders", req.Payload["TableName"])
+	require.Equal(t, "gsi-customer", req.Payload["IndexName"])
+	require.EqualValues(t, 50, req.Payload["Limit"])
+	require.Equal(t, 0, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan"))
+}
+
+func TestQueryString_FallsBackToScanByTableName(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newQueryStringTestDB(t, httpClient)
+
+	var out []queryStringOrder
+	require.NoError(t, db.QueryString("from query_string_orders where status = 'open'").All(&out))
+
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan"))
+	require.Equal(t, 0, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Query"))
+}
+
+func TestQueryString_ValidationErrors(t *testing.T) {
+	db := newBareDB()
+	require.NoError(t, db.registry.Register(&queryStringOrder{}))
+
+	var out []queryStringOrder
+
+	err := db.QueryString("from QueryStringOrder where Missing = ?", "x").All(&out)
+	require.True(t, errors.Is(err, customerrors.ErrInvalidModel))
+	require.ErrorContains(t, err, `unknown field "Missing"`)
+
+	err = db.QueryString("from QueryStringOrder index gsi-nope where CustomerID = ?", "c1").All(&out)
+	require.ErrorIs(t, err, customerrors.ErrIndexNotFound)
+
+	err = db.QueryString("from Unregistered where ID = ?", "x").All(&out)
+	require.ErrorIs(t, err, customerrors.ErrInvalidModel)
+
+	err = db.QueryString("from QueryStringOrder where Status = ?").All(&out)
+	require.ErrorIs(t, err, querystring.ErrSyntax)
+}
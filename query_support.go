@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/pay-theory/dynamorm/pkg/cond"
 	"github.com/pay-theory/dynamorm/pkg/core"
 	"github.com/pay-theory/dynamorm/pkg/model"
 	"github.com/pay-theory/dynamorm/pkg/schema"
@@ -18,7 +19,11 @@ type errorQuery struct {
 	err error
 }
 
-func (e *errorQuery) Where(_ string, _ string, _ any) core.Query  { return e }
+func (e *errorQuery) Where(_ string, _ string, _ any) core.Query { return e }
+func (e *errorQuery) WhereBeginsWith(_ string, _ string) core.Query {
+	return e
+}
+func (e *errorQuery) WhereBetween(_ string, _, _ any) core.Query  { return e }
 func (e *errorQuery) Index(_ string) core.Query                   { return e }
 func (e *errorQuery) Filter(_ string, _ string, _ any) core.Query { return e }
 func (e *errorQuery) OrFilter(_ string, _ string, _ any) core.Query {
@@ -28,36 +33,59 @@ func (e *errorQuery) FilterGroup(_ func(q core.Query)) core.Query { return e }
 func (e *errorQuery) OrFilterGroup(_ func(core.Query)) core.Query {
 	return e
 }
-func (e *errorQuery) IfNotExists() core.Query { return e }
-func (e *errorQuery) IfExists() core.Query    { return e }
+func (e *errorQuery) FilterFunc(_ func(*cond.Builder)) core.Query { return e }
+func (e *errorQuery) IfNotExists() core.Query                     { return e }
+func (e *errorQuery) IfExists() core.Query                        { return e }
 func (e *errorQuery) WithCondition(_ string, _ string, _ any) core.Query {
 	return e
 }
 func (e *errorQuery) WithConditionExpression(_ string, _ map[string]any) core.Query {
 	return e
 }
-func (e *errorQuery) OrderBy(_ string, _ string) core.Query       { return e }
-func (e *errorQuery) Limit(_ int) core.Query                      { return e }
-func (e *errorQuery) Offset(_ int) core.Query                     { return e }
-func (e *errorQuery) Select(_ ...string) core.Query               { return e }
-func (e *errorQuery) ConsistentRead() core.Query                  { return e }
-func (e *errorQuery) WithRetry(_ int, _ time.Duration) core.Query { return e }
-func (e *errorQuery) First(_ any) error                           { return e.err }
-func (e *errorQuery) All(_ any) error                             { return e.err }
-func (e *errorQuery) Count() (int64, error)                       { return 0, e.err }
-func (e *errorQuery) Create() error                               { return e.err }
-func (e *errorQuery) CreateOrUpdate() error                       { return e.err }
-func (e *errorQuery) Update(_ ...string) error                    { return e.err }
-func (e *errorQuery) Delete() error                               { return e.err }
-func (e *errorQuery) Scan(_ any) error                            { return e.err }
-func (e *errorQuery) BatchGet(_ []any, _ any) error               { return e.err }
+func (e *errorQuery) WithClientToken(_ string, _ string) core.Query {
+	return e
+}
+func (e *errorQuery) Profile(_ string) core.Query {
+	return e
+}
+func (e *errorQuery) Preload(_ ...string) core.Query {
+	return e
+}
+func (e *errorQuery) Pages(_ any, _ func(*core.PaginatedResult) bool) error {
+	return e.err
+}
+func (e *errorQuery) OrderBy(_ string, _ string) core.Query            { return e }
+func (e *errorQuery) Limit(_ int) core.Query                           { return e }
+func (e *errorQuery) Offset(_ int) core.Query                          { return e }
+func (e *errorQuery) Select(_ ...string) core.Query                    { return e }
+func (e *errorQuery) ConsistentRead() core.Query                       { return e }
+func (e *errorQuery) Refresh() core.Query                              { return e }
+func (e *errorQuery) ReturnConsumedCapacity(_ string) core.Query       { return e }
+func (e *errorQuery) WithRetry(_ int, _ time.Duration) core.Query      { return e }
+func (e *errorQuery) First(_ any) error                                { return e.err }
+func (e *errorQuery) All(_ any) error                                  { return e.err }
+func (e *errorQuery) Count() (int64, error)                            { return 0, e.err }
+func (e *errorQuery) CountWithResult() (*core.CountResult, error)      { return nil, e.err }
+func (e *errorQuery) Create() error                                    { return e.err }
+func (e *errorQuery) CreateOrUpdate() error                            { return e.err }
+func (e *errorQuery) Update(_ ...string) error                         { return e.err }
+func (e *errorQuery) Delete() error                                    { return e.err }
+func (e *errorQuery) AtomicIncrement(_ string, _ int64) (int64, error) { return 0, e.err }
+func (e *errorQuery) AtomicDecrement(_ string, _ int64) (int64, error) { return 0, e.err }
+func (e *errorQuery) Scan(_ any) error                                 { return e.err }
+func (e *errorQuery) BatchGet(_ []any, _ any) error                    { return e.err }
 func (e *errorQuery) BatchGetWithOptions(_ []any, _ any, _ *core.BatchGetOptions) error {
 	return e.err
 }
+func (e *errorQuery) BatchGetOrdered(_ []any, _ any) error  { return e.err }
+func (e *errorQuery) BatchGetMap(_ []any, _ any) error      { return e.err }
 func (e *errorQuery) BatchGetBuilder() core.BatchGetBuilder { return &errorBatchGetBuilder{err: e.err} }
 func (e *errorQuery) BatchCreate(_ any) error               { return e.err }
-func (e *errorQuery) BatchDelete(_ []any) error             { return e.err }
-func (e *errorQuery) BatchWrite(_ []any, _ []any) error     { return e.err }
+func (e *errorQuery) BatchCreateWithOptions(_ any, _ *core.BatchCreateOptions) (*core.BatchCreateResult, error) {
+	return nil, e.err
+}
+func (e *errorQuery) BatchDelete(_ []any) error         { return e.err }
+func (e *errorQuery) BatchWrite(_ []any, _ []any) error { return e.err }
 func (e *errorQuery) BatchUpdateWithOptions(_ []any, _ []string, _ ...any) error {
 	return e.err
 }
@@ -66,8 +94,14 @@ func (e *errorQuery) AllPaginated(_ any) (*core.PaginatedResult, error) { return
 func (e *errorQuery) UpdateBuilder() core.UpdateBuilder                 { return &errorUpdateBuilder{err: e.err} }
 func (e *errorQuery) ParallelScan(_ int32, _ int32) core.Query          { return e }
 func (e *errorQuery) ScanAllSegments(_ any, _ int32) error              { return e.err }
-func (e *errorQuery) Cursor(_ string) core.Query                        { return e }
-func (e *errorQuery) SetCursor(_ string) error                          { return e.err }
+func (e *errorQuery) ScanAllSegmentsWithOptions(_ any, _ int32, _ *core.ScanOptions) error {
+	return e.err
+}
+func (e *errorQuery) Cursor(_ string) core.Query { return e }
+func (e *errorQuery) SetCursor(_ string) error   { return e.err }
+func (e *errorQuery) UpdateWithOptimisticRetry(_ int, _ func(any) error, _ ...string) error {
+	return e.err
+}
 
 type errorBatchGetBuilder struct {
 	err error
@@ -85,6 +119,10 @@ func (b *errorBatchGetBuilder) OnProgress(_ core.BatchProgressCallback) core.Bat
 func (b *errorBatchGetBuilder) OnError(_ core.BatchChunkErrorHandler) core.BatchGetBuilder {
 	return b
 }
+
+func (b *errorBatchGetBuilder) OnMissing(_ core.BatchMissingKeysHandler) core.BatchGetBuilder {
+	return b
+}
 func (b *errorBatchGetBuilder) Execute(_ any) error { return b.err }
 
 type errorUpdateBuilder struct {
@@ -108,6 +146,15 @@ func (e *errorUpdateBuilder) RemoveFromListAt(_ string, _ int) core.UpdateBuilde
 func (e *errorUpdateBuilder) SetListElement(_ string, _ int, _ any) core.UpdateBuilder {
 	return e
 }
+func (e *errorUpdateBuilder) AppendToListWithLimit(_ string, _ any, _ int) core.UpdateBuilder {
+	return e
+}
+func (e *errorUpdateBuilder) SetMapKey(_ string, _ string, _ any) core.UpdateBuilder {
+	return e
+}
+func (e *errorUpdateBuilder) RemoveMapKey(_ string, _ string) core.UpdateBuilder {
+	return e
+}
 func (e *errorUpdateBuilder) Condition(_ string, _ string, _ any) core.UpdateBuilder {
 	return e
 }
@@ -150,8 +197,16 @@ func DefaultBatchGetOptions() *core.BatchGetOptions {
 
 // TransactionFunc executes a function within a database transaction.
 func (db *DB) TransactionFunc(fn func(tx any) error) error {
+	l := db.lifecycleState()
+	if err := l.begin(); err != nil {
+		return err
+	}
+	defer l.end()
+
 	tx := transaction.NewTransaction(db.session, db.registry, db.converter)
-	tx = tx.WithContext(db.ctx)
+	tx = tx.WithContext(db.ctx).WithTokenCache(db.transactionTokens())
+	tx = tx.WithWriteObserver(db.observeTransactWrite).WithTableGuard(db.checkMaintenance)
+	tx = tx.WithItemGuard(db.transactItemGuard())
 
 	if err := fn(tx); err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
@@ -0,0 +1,235 @@
+package dynamorm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/query"
+)
+
+type readDedupKey struct{}
+
+// readDedup remembers the results of the reads made in one scope, such as one Lambda
+// invocation, keyed by a hash of the compiled request.
+type readDedup struct {
+	entries map[string]*dedupEntry
+	stats   ReadDedupStats
+	mu      sync.Mutex
+}
+
+// dedupEntry is one read's result. done closes once items and err are set, so identical
+// reads made concurrently wait for the first instead of sending their own.
+type dedupEntry struct {
+	done  chan struct{}
+	err   error
+	items []map[string]types.AttributeValue
+}
+
+// ReadDedupStats counts the reads made in a de-duplication scope.
+type ReadDedupStats struct {
+	// Reads is the number of GetItem, Query, and Scan calls made in the scope.
+	Reads int
+	// Deduplicated is how many of them reused an earlier identical read's result instead
+	// of calling DynamoDB.
+	Deduplicated int
+}
+
+// WithReadDedup returns a context that de-duplicates identical reads made with it, for
+// the life of one request or Lambda invocation:
+//
+//	func handler(ctx context.Context, event Event) error {
+//		ctx = dynamorm.WithReadDedup(ctx)
+//		// Middleware and handler both load the user; DynamoDB sees one GetItem.
+//		...
+//	}
+//
+// GetItem, Query, and Scan calls through db.WithContext(ctx) whose compiled requests
+// match an earlier one reuse its items, unmarshaled afresh into each destination.
+// Failed reads are not remembered. A write made with the context, including a
+// transaction, forgets every remembered read, so later reads see it. Writes made
+// elsewhere are not seen, so the scope should be no longer than one invocation.
+func WithReadDedup(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, readDedupKey{}, &readDedup{entries: make(map[string]*dedupEntry)})
+}
+
+// ReadDedupStatsFromContext returns the counts of the scope started by WithReadDedup,
+// reporting false when ctx has none.
+func ReadDedupStatsFromContext(ctx context.Context) (ReadDedupStats, bool) {
+	d := readDedupFromContext(ctx)
+	if d == nil {
+		return ReadDedupStats{}, false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stats, true
+}
+
+func readDedupFromContext(ctx context.Context) *readDedup {
+	if ctx == nil {
+		return nil
+	}
+	d, _ := ctx.Value(readDedupKey{}).(*readDedup)
+	return d
+}
+
+// read returns the items of the read identified by key, calling fetch only when no
+// identical read has succeeded, or is running, in the scope.
+func (d *readDedup) read(ctx context.Context, key string, fetch func() ([]map[string]types.AttributeValue, error)) ([]map[string]types.AttributeValue, error) {
+	d.mu.Lock()
+	d.stats.Reads++
+	for {
+		entry, ok := d.entries[key]
+		if !ok {
+			break
+		}
+		d.mu.Unlock()
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		d.mu.Lock()
+		if entry.err == nil {
+			d.stats.Deduplicated++
+			d.mu.Unlock()
+			return cloneItems(entry.items), nil
+		}
+		// The read waited on failed and is forgotten; look again.
+	}
+	entry := &dedupEntry{done: make(chan struct{})}
+	d.entries[key] = entry
+	d.mu.Unlock()
+
+	items, err := fetch()
+	entry.items, entry.err = cloneItems(items), err
+	if err != nil {
+		d.mu.Lock()
+		if d.entries[key] == entry {
+			delete(d.entries, key)
+		}
+		d.mu.Unlock()
+	}
+	close(entry.done)
+	return items, err
+}
+
+// forget drops every remembered read after a write.
+func (d *readDedup) forget() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries = make(map[string]*dedupEntry)
+}
+
+// dedupRead runs fetch through the context's de-duplication scope, if it has one.
+// Items are copied so callers may change them, as decryption and S3 overflow loading do.
+func (qe *queryExecutor) dedupRead(operation string, input *core.CompiledQuery, key map[string]types.AttributeValue, fetch func() ([]map[string]types.AttributeValue, error)) ([]map[string]types.AttributeValue, error) {
+	ctx := qe.ctxOrBackground()
+	d := readDedupFromContext(ctx)
+	if d == nil || qe.db.unknownPrincipal {
+		return fetch()
+	}
+	dedupKey, ok := readDedupFingerprint(operation, qe.db.principal, input, key)
+	if !ok {
+		return fetch()
+	}
+	return d.read(ctx, dedupKey, fetch)
+}
+
+// forgetDedupedReads drops the reads remembered in ctx's scope after a write.
+func forgetDedupedReads(ctx context.Context) {
+	if d := readDedupFromContext(ctx); d != nil {
+		d.forget()
+	}
+}
+
+// observeTransactWrite is the write observer of the DB's transactions.
+func (db *DB) observeTransactWrite(ctx context.Context, items []types.TransactWriteItem) {
+	db.invalidateTransactItems(ctx, items)
+	forgetDedupedReads(ctx)
+}
+
+// readDedupFingerprint hashes everything about a read that decides its result, including
+// the principal it is signed as.
+func readDedupFingerprint(operation, principal string, input *core.CompiledQuery, key map[string]types.AttributeValue) (string, bool) {
+	if input == nil {
+		return "", false
+	}
+	values, err := query.MarshalItemJSON(input.ExpressionAttributeValues)
+	if err != nil {
+		return "", false
+	}
+	keyJSON, err := query.MarshalItemJSON(key)
+	if err != nil {
+		return "", false
+	}
+	startKey, err := query.MarshalItemJSON(input.ExclusiveStartKey)
+	if err != nil {
+		return "", false
+	}
+
+	encoded, err := json.Marshal(struct {
+		Limit            *int32
+		ScanIndexForward *bool
+		ConsistentRead   *bool
+		Segment          *int32
+		TotalSegments    *int32
+		Offset           *int
+		Names            map[string]string
+		Values           json.RawMessage
+		Key              json.RawMessage
+		StartKey         json.RawMessage
+		Operation        string
+		Principal        string
+		Table            string
+		Index            string
+		KeyCondition     string
+		Filter           string
+		Projection       string
+		Select           string
+	}{
+		Limit:            input.Limit,
+		ScanIndexForward: input.ScanIndexForward,
+		ConsistentRead:   input.ConsistentRead,
+		Segment:          input.Segment,
+		TotalSegments:    input.TotalSegments,
+		Offset:           input.Offset,
+		Names:            input.ExpressionAttributeNames,
+		Values:           values,
+		Key:              keyJSON,
+		StartKey:         startKey,
+		Operation:        operation,
+		Principal:        principal,
+		Table:            input.TableName,
+		Index:            input.IndexName,
+		KeyCondition:     input.KeyConditionExpression,
+		Filter:           input.FilterExpression,
+		Projection:       input.ProjectionExpression,
+		Select:           input.Select,
+	})
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), true
+}
+
+func cloneItems(items []map[string]types.AttributeValue) []map[string]types.AttributeValue {
+	if items == nil {
+		return nil
+	}
+	cloned := make([]map[string]types.AttributeValue, len(items))
+	for i, item := range items {
+		cloned[i] = maps.Clone(item)
+	}
+	return cloned
+}
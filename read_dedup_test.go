@@ -0,0 +1,77 @@
+package dynamorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+func TestReadDedup_ReusesIdenticalReadsWithinScope(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": cachedAccountResponse,
+		"DynamoDB_20120810.Query":   `{"Items":[{"tenantId":{"S":"t1"},"orderId":{"S":"o1"}}],"Count":1,"ScannedCount":1}`,
+	})
+	db := newStubbedDB(t, httpClient)
+	ctx := WithReadDedup(context.Background())
+	scoped := db.WithContext(ctx)
+
+	for i := 0; i < 3; i++ {
+		var account testAccount
+		require.NoError(t, scoped.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+		require.Equal(t, int64(10), account.Balance)
+		account.Balance = 99
+	}
+	require.Equal(t, 1, getItemCalls(httpClient))
+
+	var other testAccount
+	require.NoError(t, scoped.Model(&testAccount{}).Where("ID", "=", "a2").First(&other))
+	require.Equal(t, 2, getItemCalls(httpClient), "a different key is a different read")
+
+	for i := 0; i < 2; i++ {
+		var orders []accessPatternOrder
+		require.NoError(t, scoped.Model(&accessPatternOrder{}).Where("TenantID", "=", "t1").All(&orders))
+		require.Len(t, orders, 1)
+	}
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Query"))
+
+	var unscoped testAccount
+	require.NoError(t, db.Model(&testAccount{}).Where("ID", "=", "a1").First(&unscoped))
+	require.Equal(t, 3, getItemCalls(httpClient), "reads without the scope are not de-duplicated")
+
+	stats, ok := ReadDedupStatsFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, ReadDedupStats{Reads: 6, Deduplicated: 3}, stats)
+}
+
+func TestReadDedup_WritesForgetRememberedReads(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": cachedAccountResponse,
+	})
+	db := newStubbedDB(t, httpClient)
+	ctx := WithReadDedup(context.Background())
+	scoped := mustDB(t, db.WithContext(ctx))
+	read := func() {
+		t.Helper()
+		var account testAccount
+		require.NoError(t, scoped.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+	}
+
+	read()
+	read()
+	require.NoError(t, scoped.Model(&testAccount{ID: "a1", Balance: 20}).CreateOrUpdate())
+	read()
+	require.Equal(t, 2, getItemCalls(httpClient), "PutItem forgets the read")
+
+	require.NoError(t, scoped.TransactWrite(ctx, func(tx core.TransactionBuilder) error {
+		tx.Delete(&testAccount{ID: "a1", Version: 1})
+		return nil
+	}))
+	read()
+	require.Equal(t, 3, getItemCalls(httpClient), "transactions forget the read")
+
+	_, ok := ReadDedupStatsFromContext(context.Background())
+	require.False(t, ok)
+}
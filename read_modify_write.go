@@ -0,0 +1,195 @@
+package dynamorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/pay-theory/dynamorm/internal/deadline"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// ErrReadModifyWriteConflict is returned when every ReadModifyWrite attempt lost the race
+// to a concurrent writer. It wraps ErrConditionFailed.
+var ErrReadModifyWriteConflict = fmt.Errorf("read-modify-write conflict: %w", customerrors.ErrConditionFailed)
+
+const (
+	defaultReadModifyWriteAttempts = 3
+	defaultReadModifyWriteBackoff  = 20 * time.Millisecond
+)
+
+type readModifyWriteConfig struct {
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// ReadModifyWriteOption configures ReadModifyWrite.
+type ReadModifyWriteOption func(*readModifyWriteConfig)
+
+// WithReadModifyWriteAttempts sets how many load/mutate/write cycles are attempted
+// before giving up with ErrReadModifyWriteConflict. Values below 1 are ignored.
+func WithReadModifyWriteAttempts(attempts int) ReadModifyWriteOption {
+	return func(cfg *readModifyWriteConfig) {
+		if attempts > 0 {
+			cfg.maxAttempts = attempts
+		}
+	}
+}
+
+// WithReadModifyWriteBackoff sets the base delay between attempts. The delay doubles
+// after every conflict; zero disables waiting.
+func WithReadModifyWriteBackoff(backoff time.Duration) ReadModifyWriteOption {
+	return func(cfg *readModifyWriteConfig) {
+		if backoff >= 0 {
+			cfg.backoff = backoff
+		}
+	}
+}
+
+// ReadModifyWrite loads item by its primary key (which must already be set), applies
+// mutate, and writes the result back guarded by an optimistic concurrency condition.
+// When another writer wins the race the item is reloaded and mutate runs again, up to
+// the configured number of attempts.
+//
+// Models with a `dynamorm:"version"` field are guarded by the version number, which is
+// incremented on success. Otherwise the model must have a `dynamorm:"updated_at"` field,
+// and the write is conditioned on it still holding the value that was read.
+//
+// mutate may be called more than once and should be free of side effects. Returning an
+// error from mutate aborts without writing. On success item holds the written state.
+func ReadModifyWrite[T any](db core.DB, item *T, mutate func(*T) error, opts ...ReadModifyWriteOption) error {
+	if db == nil {
+		return fmt.Errorf("db cannot be nil")
+	}
+	if item == nil {
+		return fmt.Errorf("item cannot be nil")
+	}
+	if mutate == nil {
+		return fmt.Errorf("mutate function cannot be nil")
+	}
+
+	cfg := readModifyWriteConfig{
+		maxAttempts: defaultReadModifyWriteAttempts,
+		backoff:     defaultReadModifyWriteBackoff,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	meta, err := metadataForDB(db, item)
+	if err != nil {
+		return err
+	}
+	if meta.VersionField == nil && meta.UpdatedAtField == nil {
+		return fmt.Errorf("%w: ReadModifyWrite requires a version or updated_at field on %s",
+			customerrors.ErrInvalidModel, meta.Type.Name())
+	}
+
+	itemValue := reflect.ValueOf(item).Elem()
+	keyConditions := primaryKeyConditions(meta, itemValue)
+
+	ctx := contextForDB(db)
+	delay := cfg.backoff
+	for attempt := 1; attempt <= cfg.maxAttempts; attempt++ {
+		current := new(T)
+		load := db.Model(current).ConsistentRead()
+		for _, cond := range keyConditions {
+			load = load.Where(cond.field, "=", cond.value)
+		}
+		if err := load.First(current); err != nil {
+			return fmt.Errorf("failed to load item: %w", err)
+		}
+
+		if err := mutate(current); err != nil {
+			return err
+		}
+
+		currentValue := reflect.ValueOf(current).Elem()
+		write := db.Model(current)
+		if meta.VersionField == nil {
+			readAt := currentValue.FieldByIndex(meta.UpdatedAtField.IndexPath).Interface()
+			write = write.WithCondition(meta.UpdatedAtField.Name, "=", readAt)
+		}
+
+		err := write.Update()
+		if err == nil {
+			if meta.VersionField != nil {
+				versionField := currentValue.FieldByIndex(meta.VersionField.IndexPath)
+				versionField.SetInt(versionField.Int() + 1)
+			}
+			*item = *current
+			return nil
+		}
+		if !errors.Is(err, customerrors.ErrConditionFailed) {
+			return fmt.Errorf("failed to write item: %w", err)
+		}
+
+		if attempt < cfg.maxAttempts && delay > 0 {
+			if err := deadline.Sleep(ctx, delay); err != nil {
+				return err
+			}
+			delay *= 2
+		}
+	}
+
+	return ErrReadModifyWriteConflict
+}
+
+type keyCondition struct {
+	value any
+	field string
+}
+
+func primaryKeyConditions(meta *model.Metadata, itemValue reflect.Value) []keyCondition {
+	conditions := []keyCondition{{
+		field: meta.PrimaryKey.PartitionKey.Name,
+		value: itemValue.FieldByIndex(meta.PrimaryKey.PartitionKey.IndexPath).Interface(),
+	}}
+	if sk := meta.PrimaryKey.SortKey; sk != nil {
+		conditions = append(conditions, keyCondition{
+			field: sk.Name,
+			value: itemValue.FieldByIndex(sk.IndexPath).Interface(),
+		})
+	}
+	return conditions
+}
+
+// contextForDB returns the context of db's requests, which ends waits between attempts.
+func contextForDB(db core.DB) context.Context {
+	switch typed := db.(type) {
+	case *DB:
+		if typed.ctx != nil {
+			return typed.ctx
+		}
+	case *LambdaDB:
+		if typed.db != nil && typed.db.ctx != nil {
+			return typed.db.ctx
+		}
+	}
+	return context.Background()
+}
+
+// metadataForDB resolves model metadata from db when it is backed by a *DB, and parses
+// the model directly otherwise (for example when db is a mock).
+func metadataForDB(db core.DB, modelValue any) (*model.Metadata, error) {
+	switch typed := db.(type) {
+	case *DB:
+		return typed.metadataFor(modelValue)
+	case *LambdaDB:
+		if typed.db != nil {
+			return typed.db.metadataFor(modelValue)
+		}
+	}
+
+	registry := model.NewRegistry()
+	if err := registry.Register(modelValue); err != nil {
+		return nil, fmt.Errorf("failed to register model %T: %w", modelValue, err)
+	}
+	return registry.GetMetadata(modelValue)
+}
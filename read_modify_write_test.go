@@ -0,0 +1,108 @@
+package dynamorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+func TestReadModifyWrite_RetriesOnVersionConflict(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.GetItem", []stubbedResponse{
+		{body: `{"Item":{"id":{"S":"a1"},"balance":{"N":"10"},"version":{"N":"1"}}}`},
+		{body: `{"Item":{"id":{"S":"a1"},"balance":{"N":"15"},"version":{"N":"2"}}}`},
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.UpdateItem", []stubbedResponse{
+		conditionalCheckFailedResponse,
+		{body: `{}`},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	calls := 0
+	account := &testAccount{ID: "a1"}
+	err := ReadModifyWrite(db, account, func(a *testAccount) error {
+		calls++
+		a.Balance += 5
+		return nil
+	}, WithReadModifyWriteBackoff(0))
+	require.NoError(t, err)
+
+	require.Equal(t, 2, calls)
+	require.Equal(t, int64(20), account.Balance)
+	require.Equal(t, int64(3), account.Version)
+
+	reqs := httpClient.Requests()
+	require.Equal(t, 2, countRequestsByTarget(reqs, "DynamoDB_20120810.GetItem"))
+	getReq := findRequestByTarget(reqs, "DynamoDB_20120810.GetItem")
+	require.Equal(t, true, getReq.Payload["ConsistentRead"])
+
+	updateReq := findRequestByTarget(reqs, "DynamoDB_20120810.UpdateItem")
+	require.NotNil(t, updateReq)
+	require.Contains(t, updateReq.Payload["ConditionExpression"], "=")
+}
+
+func TestReadModifyWrite_GivesUpAfterMaxAttempts(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"a1"},"balance":{"N":"10"},"version":{"N":"1"}}}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.UpdateItem", []stubbedResponse{conditionalCheckFailedResponse})
+	db := newStubbedDB(t, httpClient)
+
+	account := &testAccount{ID: "a1"}
+	err := ReadModifyWrite(db, account, func(a *testAccount) error {
+		a.Balance++
+		return nil
+	}, WithReadModifyWriteAttempts(2), WithReadModifyWriteBackoff(time.Millisecond))
+	require.ErrorIs(t, err, ErrReadModifyWriteConflict)
+	require.ErrorIs(t, err, customerrors.ErrConditionFailed)
+	require.Equal(t, 2, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem"))
+	require.Equal(t, int64(0), account.Balance, "item is left untouched on failure")
+}
+
+func TestReadModifyWrite_StopsWaitingWhenContextEnds(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"a1"},"balance":{"N":"10"},"version":{"N":"1"}}}`,
+	})
+	httpClient.SetResponseSequence("DynamoDB_20120810.UpdateItem", []stubbedResponse{conditionalCheckFailedResponse})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	db := newStubbedDB(t, httpClient).WithContext(ctx)
+
+	start := time.Now()
+	err := ReadModifyWrite(db, &testAccount{ID: "a1"}, func(a *testAccount) error {
+		a.Balance++
+		return nil
+	}, WithReadModifyWriteBackoff(time.Hour))
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), time.Minute)
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem"))
+}
+
+func TestReadModifyWrite_MutateErrorAbortsWithoutWrite(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"a1"},"balance":{"N":"10"},"version":{"N":"1"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	boom := errors.New("insufficient funds")
+	err := ReadModifyWrite(db, &testAccount{ID: "a1"}, func(*testAccount) error { return boom })
+	require.ErrorIs(t, err, boom)
+	require.Equal(t, 0, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem"))
+}
+
+func TestReadModifyWrite_ValidatesInputs(t *testing.T) {
+	db := newBareDB()
+	noop := func(*testAccount) error { return nil }
+
+	require.ErrorContains(t, ReadModifyWrite[testAccount](nil, &testAccount{}, noop), "db cannot be nil")
+	require.ErrorContains(t, ReadModifyWrite(db, (*testAccount)(nil), noop), "item cannot be nil")
+	require.ErrorContains(t, ReadModifyWrite[testAccount](db, &testAccount{}, nil), "mutate function")
+
+	err := ReadModifyWrite(db, &unversionedAccount{ID: "x"}, func(*unversionedAccount) error { return nil })
+	require.ErrorIs(t, err, customerrors.ErrInvalidModel)
+}
@@ -0,0 +1,61 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefresh_CreateReloadsWithConsistentRead(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"a1"},"balance":{"N":"12"},"version":{"N":"1"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	account := &testAccount{ID: "a1", Balance: 10}
+	require.NoError(t, db.Model(account).Refresh().Create())
+	require.Equal(t, int64(12), account.Balance)
+	require.Equal(t, int64(1), account.Version)
+
+	reqs := httpClient.Requests()
+	require.Equal(t, 1, countRequestsByTarget(reqs, "DynamoDB_20120810.PutItem"))
+	get := findRequestByTarget(reqs, "DynamoDB_20120810.GetItem")
+	require.NotNil(t, get)
+	require.Equal(t, true, get.Payload["ConsistentRead"])
+	require.Equal(t, map[string]any{"id": map[string]any{"S": "a1"}}, get.Payload["Key"])
+}
+
+func TestRefresh_UpdateUsesAllNewReturnValues(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.UpdateItem": `{"Attributes":{"id":{"S":"a1"},"balance":{"N":"25"},"version":{"N":"4"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	account := &testAccount{ID: "a1", Balance: 20, Version: 3}
+	require.NoError(t, db.Model(account).Refresh().Update("Balance"))
+
+	require.Equal(t, int64(4), account.Version)
+	require.Equal(t, int64(25), account.Balance)
+
+	reqs := httpClient.Requests()
+	update := findRequestByTarget(reqs, "DynamoDB_20120810.UpdateItem")
+	require.NotNil(t, update)
+	require.Equal(t, "ALL_NEW", update.Payload["ReturnValues"])
+	require.Zero(t, countRequestsByTarget(reqs, "DynamoDB_20120810.GetItem"))
+}
+
+func TestRefresh_WithoutRefreshSkipsReads(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+
+	account := &testAccount{ID: "a1", Balance: 20, Version: 3}
+	require.NoError(t, db.Model(account).Create())
+	require.NoError(t, db.Model(account).Update("Balance"))
+
+	reqs := httpClient.Requests()
+	require.Zero(t, countRequestsByTarget(reqs, "DynamoDB_20120810.GetItem"))
+	update := findRequestByTarget(reqs, "DynamoDB_20120810.UpdateItem")
+	require.NotNil(t, update)
+	require.Nil(t, update.Payload["ReturnValues"])
+	require.Equal(t, int64(3), account.Version)
+}
@@ -0,0 +1,50 @@
+package dynamorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+func TestValidationErrorsCarryRemediationThroughMiddleware(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.Query", []stubbedResponse{{
+		status:  400,
+		headers: map[string]string{"X-Amzn-ErrorType": "ValidationException"},
+		body:    `{"__type":"com.amazon.coral.validate#ValidationException","message":"Consistent reads are not supported on global secondary indexes"}`,
+	}})
+	db := newStubbedDB(t, httpClient)
+
+	var seen []string
+	db.Use(func(next core.Handler) core.Handler {
+		return func(ctx context.Context, op *core.Operation) error {
+			err := next(ctx, op)
+			if r, ok := customerrors.GetRemediation(err); ok {
+				seen = append(seen, r.Code)
+			}
+			return err
+		}
+	})
+
+	var out []accessPatternOrder
+	err := db.Model(&accessPatternOrder{}).Where("TenantID", "=", "t1").All(&out)
+	require.Error(t, err)
+	r, ok := customerrors.GetRemediation(err)
+	require.True(t, ok, err.Error())
+	require.Equal(t, customerrors.RemediationGSIConsistentRead, r.Code)
+	require.Equal(t, []string{customerrors.RemediationGSIConsistentRead}, seen)
+}
+
+func TestBuilderErrorsCarryRemediation(t *testing.T) {
+	db := newStubbedDB(t, newCapturingHTTPClient(nil))
+
+	err := db.Model(&accessPatternOrder{}).Where("TenantID", "=", "t1").Delete()
+	r, ok := customerrors.GetRemediation(err)
+	require.True(t, ok, "%v", err)
+	require.Equal(t, customerrors.RemediationMissingSortKey, r.Code)
+	require.Equal(t, "OrderID", r.Details["field"])
+}
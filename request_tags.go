@@ -0,0 +1,76 @@
+package dynamorm
+
+import (
+	"context"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+type requestTagsKey struct{}
+
+// WithRequestTags returns a context whose operations carry tags, such as the endpoint,
+// feature flag, or tenant, for cost attribution. Tags reach middleware as Operation.Tags
+// and are recorded with slow query log entries. Tags already in ctx are kept unless
+// overridden.
+func WithRequestTags(ctx context.Context, tags map[string]string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, requestTagsKey{}, mergeTags(RequestTagsFromContext(ctx), tags))
+}
+
+// RequestTagsFromContext returns the tags stored by WithRequestTags.
+func RequestTagsFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	tags, _ := ctx.Value(requestTagsKey{}).(map[string]string)
+	return tags
+}
+
+// WithRequestTags returns a DB whose operations carry tags, merged under any tags in the
+// operation's context. Use it to label every call made through one handle:
+//
+//	checkout := db.WithRequestTags(map[string]string{"feature": "checkout"})
+func (db *DB) WithRequestTags(tags map[string]string) core.ExtendedDB {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	newDB := db.derive()
+	newDB.requestTags = mergeTags(db.requestTags, tags)
+	return newDB
+}
+
+// requestTags returns the tags for the executor's current operation: the DB's tags
+// overridden by those in the context.
+func (qe *queryExecutor) requestTags() map[string]string {
+	if qe == nil {
+		return nil
+	}
+	if qe.op != nil {
+		return qe.op.Tags
+	}
+	var dbTags map[string]string
+	if qe.db != nil {
+		qe.db.mu.RLock()
+		dbTags = qe.db.requestTags
+		qe.db.mu.RUnlock()
+	}
+	return mergeTags(dbTags, RequestTagsFromContext(qe.ctxOrBackground()))
+}
+
+// mergeTags returns a new map holding base overridden by overrides, or nil when both are
+// empty.
+func mergeTags(base, overrides map[string]string) map[string]string {
+	if len(base) == 0 && len(overrides) == 0 {
+		return nil
+	}
+	merged := make(map[string]string, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,69 @@
+package dynamorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+func TestWithRequestTags_ReachMiddlewareAndSlowQueryLog(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query":   `{"Items":[],"Count":0}`,
+		"DynamoDB_20120810.PutItem": `{}`,
+	})
+	db := newStubbedDB(t, httpClient)
+	db.SlowQueries().SetThreshold(time.Nanosecond)
+
+	var seen []map[string]string
+	db.Use(func(next Handler) Handler {
+		return func(ctx context.Context, op *Operation) error {
+			seen = append(seen, op.Tags)
+			if op.Type == OperationPutItem {
+				op.Tags["write"] = "true"
+			}
+			return next(ctx, op)
+		}
+	})
+
+	checkout := db.WithRequestTags(map[string]string{"feature": "checkout", "tenant": "default"})
+	ctx := WithRequestTags(context.Background(), map[string]string{"endpoint": "GET /orders"})
+	ctx = WithRequestTags(ctx, map[string]string{"tenant": "t1"})
+
+	var orders []tenantOrder
+	require.NoError(t, checkout.WithContext(ctx).Model(&tenantOrder{}).Where("TenantKey", "=", "TENANT#t1").All(&orders))
+	require.NoError(t, checkout.Model(&tenantOrder{TenantKey: "TENANT#t1", ID: "o1"}).Create())
+	require.NoError(t, db.Model(&tenantOrder{}).Where("TenantKey", "=", "TENANT#t1").All(&orders))
+
+	require.Equal(t, []map[string]string{
+		{"feature": "checkout", "tenant": "t1", "endpoint": "GET /orders"},
+		{"feature": "checkout", "tenant": "default", "write": "true"},
+		nil,
+	}, seen)
+
+	recent := db.SlowQueries().Recent()
+	require.Len(t, recent, 3)
+	require.Nil(t, recent[0].Tags)
+	require.Equal(t, map[string]string{"feature": "checkout", "tenant": "default", "write": "true"}, recent[1].Tags)
+	require.Equal(t, "GET /orders", recent[2].Tags["endpoint"])
+}
+
+func TestWithRequestTags_AvailableThroughExtendedDB(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{"DynamoDB_20120810.PutItem": `{}`})
+	var ext core.ExtendedDB = newStubbedDB(t, httpClient)
+
+	var seen map[string]string
+	ext.Use(func(next core.Handler) core.Handler {
+		return func(ctx context.Context, op *core.Operation) error {
+			seen = op.Tags
+			return next(ctx, op)
+		}
+	})
+
+	tagged := ext.WithRequestTags(map[string]string{"feature": "checkout"}).WithItemSizeValidation(true)
+	require.NoError(t, tagged.Model(&tenantOrder{TenantKey: "TENANT#t1", ID: "o1"}).Create())
+	require.Equal(t, map[string]string{"feature": "checkout"}, seen)
+}
@@ -0,0 +1,183 @@
+package dynamorm
+
+import (
+	"context"
+	"sync"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+// lifecycle tracks in-flight operations so Shutdown can drain them. It is shared by every
+// DB derived from the same New call.
+type lifecycle struct {
+	idle     chan struct{}
+	hooks    []shutdownHook
+	inflight int
+	closed   bool
+	mu       sync.Mutex
+}
+
+type shutdownHook struct {
+	drain func(context.Context) error
+	name  string
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{idle: make(chan struct{})}
+}
+
+func (l *lifecycle) begin() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return customerrors.ErrShuttingDown
+	}
+	l.inflight++
+	return nil
+}
+
+func (l *lifecycle) end() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inflight--
+	if l.closed && l.inflight == 0 {
+		l.signalIdleLocked()
+	}
+}
+
+func (l *lifecycle) signalIdleLocked() {
+	select {
+	case <-l.idle:
+	default:
+		close(l.idle)
+	}
+}
+
+// circuit reports whether operations are accepted and how many are in flight.
+func (l *lifecycle) circuit() CircuitStats {
+	if l == nil {
+		return CircuitStats{State: CircuitClosed}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := CircuitStats{State: CircuitClosed, InFlight: l.inflight}
+	if l.closed {
+		stats.State = CircuitOpen
+		if l.inflight > 0 {
+			stats.State = CircuitDraining
+		}
+	}
+	return stats
+}
+
+// OnShutdown registers drain, such as a buffered writer's flush, to run during Shutdown
+// after in-flight operations have finished. Hooks run in registration order and receive
+// Shutdown's context. Hooks registered after Shutdown starts are ignored.
+func (db *DB) OnShutdown(name string, drain func(ctx context.Context) error) {
+	if drain == nil {
+		return
+	}
+	l := db.lifecycleState()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	l.hooks = append(l.hooks, shutdownHook{name: name, drain: drain})
+}
+
+// Shutdown stops the DB accepting new operations, which then fail with ErrShuttingDown,
+// waits for in-flight operations to finish, and runs the OnShutdown hooks. If ctx ends
+// first, or a hook fails, it returns a *errors.ShutdownError describing what was left.
+// Shutdown applies to every DB derived from the same New call and is safe to call again.
+func (db *DB) Shutdown(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	l := db.lifecycleState()
+	l.mu.Lock()
+	firstCall := !l.closed
+	l.closed = true
+	if l.inflight == 0 {
+		l.signalIdleLocked()
+	}
+	hooks := l.hooks
+	if firstCall {
+		l.hooks = nil
+	} else {
+		hooks = nil
+	}
+	l.mu.Unlock()
+
+	report := &customerrors.ShutdownError{}
+	select {
+	case <-l.idle:
+	case <-ctx.Done():
+		l.mu.Lock()
+		report.InFlight = l.inflight
+		l.mu.Unlock()
+		report.Err = ctx.Err()
+		for _, hook := range hooks {
+			report.Undrained = append(report.Undrained, hook.name)
+		}
+		return report
+	}
+
+	for i, hook := range hooks {
+		if err := ctx.Err(); err != nil {
+			report.Err = err
+			for _, pending := range hooks[i:] {
+				report.Undrained = append(report.Undrained, pending.name)
+			}
+			break
+		}
+		if err := hook.drain(ctx); err != nil {
+			if report.DrainErrors == nil {
+				report.DrainErrors = make(map[string]error)
+			}
+			report.DrainErrors[hook.name] = err
+		}
+	}
+
+	if report.Err != nil || len(report.DrainErrors) > 0 {
+		return report
+	}
+	return nil
+}
+
+func (db *DB) lifecycleState() *lifecycle {
+	db.mu.RLock()
+	l := db.lifecycle
+	db.mu.RUnlock()
+	if l != nil {
+		return l
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.lifecycle == nil {
+		db.lifecycle = newLifecycle()
+	}
+	return db.lifecycle
+}
+
+func (qe *queryExecutor) beginOperation() error {
+	if qe == nil || qe.db == nil {
+		return nil
+	}
+	return qe.db.lifecycleState().begin()
+}
+
+func (qe *queryExecutor) endOperation() {
+	if qe == nil || qe.db == nil {
+		return
+	}
+	qe.db.lifecycleState().end()
+}
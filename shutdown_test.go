@@ -0,0 +1,88 @@
+package dynamorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+func TestShutdown_RejectsNewOperations(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{"DynamoDB_20120810.PutItem": `{}`})
+	db := newStubbedDB(t, httpClient)
+	derived := db.WithItemSizeValidation(true)
+
+	require.NoError(t, db.Shutdown(context.Background()))
+
+	err := derived.Model(&testAccount{ID: "a1"}).Create()
+	require.ErrorIs(t, err, customerrors.ErrShuttingDown)
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem"))
+
+	err = db.TransactWrite(context.Background(), func(tx core.TransactionBuilder) error { return nil })
+	require.ErrorIs(t, err, customerrors.ErrShuttingDown)
+
+	require.NoError(t, db.Shutdown(context.Background()), "Shutdown is idempotent")
+}
+
+func TestShutdown_WaitsForInFlightOperationsThenRunsHooks(t *testing.T) {
+	db := newBareDB()
+	l := db.lifecycleState()
+	require.NoError(t, l.begin())
+
+	var order []string
+	db.OnShutdown("flush", func(context.Context) error {
+		order = append(order, "flush")
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- db.Shutdown(context.Background()) }()
+
+	select {
+	case <-done:
+		t.Fatal("Shutdown returned before the in-flight operation finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	order = append(order, "operation")
+	l.end()
+	require.NoError(t, <-done)
+	require.Equal(t, []string{"operation", "flush"}, order)
+}
+
+func TestShutdown_ReportsWorkLeftAtDeadline(t *testing.T) {
+	db := newBareDB()
+	require.NoError(t, db.lifecycleState().begin())
+	db.OnShutdown("outbox", func(context.Context) error { return nil })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := db.Shutdown(ctx)
+	require.ErrorIs(t, err, customerrors.ErrShutdownIncomplete)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var shutdownErr *customerrors.ShutdownError
+	require.True(t, errors.As(err, &shutdownErr))
+	require.Equal(t, 1, shutdownErr.InFlight)
+	require.Equal(t, []string{"outbox"}, shutdownErr.Undrained)
+}
+
+func TestShutdown_ReportsFailedHooks(t *testing.T) {
+	db := newBareDB()
+	flushErr := errors.New("3 records dropped")
+	db.OnShutdown("buffer", func(context.Context) error { return flushErr })
+
+	err := db.Shutdown(context.Background())
+	require.ErrorIs(t, err, customerrors.ErrShutdownIncomplete)
+
+	var shutdownErr *customerrors.ShutdownError
+	require.True(t, errors.As(err, &shutdownErr))
+	require.Equal(t, map[string]error{"buffer": flushErr}, shutdownErr.DrainErrors)
+	require.Contains(t, err.Error(), "buffer: 3 records dropped")
+}
@@ -0,0 +1,204 @@
+package dynamorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// maxSnapshotItems is the DynamoDB limit for TransactGetItems.
+const maxSnapshotItems = 100
+
+// ConsistentSnapshot reads the items identified by the primary keys set on models in one
+// TransactGetItems call, and fills each model in place. The items are read as of a single
+// point in time, so checks across them, such as an invariant over several accounts, see
+// no write half applied:
+//
+//	from := &Account{ID: "acct-1"}
+//	to := &Account{ID: "acct-2"}
+//	if err := db.ConsistentSnapshot(from, to); err != nil {
+//		return err
+//	}
+//	// from and to reflect the same moment; queue the follow-up transfer with
+//	// AtVersion conditions so it fails if either changed since.
+//
+// Each model must be a pointer to a registered struct with its key fields set, and at most
+// 100 items may be read. Items that do not exist leave their models untouched, and the
+// returned error wraps ErrItemNotFound and names them; the other models are still filled.
+// A snapshot that conflicts with an in-flight transaction fails with a
+// *errors.TransactionError whose reasons carry TransactionConflict, and can be retried.
+// Snapshot reads bypass the item cache, read de-duplication, and middleware, as
+// transactions do.
+func (db *DB) ConsistentSnapshot(models ...any) error {
+	if len(models) == 0 {
+		return errors.New("consistent snapshot requires at least one model")
+	}
+	if len(models) > maxSnapshotItems {
+		return fmt.Errorf("consistent snapshot supports at most %d items, got %d", maxSnapshotItems, len(models))
+	}
+
+	l := db.lifecycleState()
+	if err := l.begin(); err != nil {
+		return err
+	}
+	defer l.end()
+
+	executors := make([]*queryExecutor, len(models))
+	reads := make([]types.TransactGetItem, len(models))
+	scopes := make([]*tenantScope, len(models))
+	for i, modelValue := range models {
+		if v := reflect.ValueOf(modelValue); v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+			return fmt.Errorf("consistent snapshot item %d: model must be a non-nil pointer to a struct, got %T", i, modelValue)
+		}
+		meta, err := db.metadataFor(modelValue)
+		if err != nil {
+			return fmt.Errorf("consistent snapshot item %d: %w", i, err)
+		}
+		key, err := db.snapshotKey(meta, reflect.ValueOf(modelValue).Elem())
+		if err != nil {
+			return fmt.Errorf("consistent snapshot item %d (%T): %w", i, modelValue, err)
+		}
+
+		qe := &queryExecutor{db: db, metadata: meta, ctx: db.ctx}
+		if err := qe.failClosedIfEncrypted(); err != nil {
+			return err
+		}
+		if err := qe.checkItemLeadingKeys("TransactGetItems", meta.TableName, key); err != nil {
+			return err
+		}
+		if scope, err := qe.tenantScope(); err != nil {
+			return err
+		} else if scope != nil {
+			if err := scope.checkKey(key); err != nil {
+				return err
+			}
+			scopes[i] = scope
+		}
+		if err := db.checkMaintenance(meta.TableName, false); err != nil {
+			return err
+		}
+		executors[i] = qe
+		reads[i] = types.TransactGetItem{Get: &types.Get{TableName: aws.String(meta.TableName), Key: key}}
+	}
+
+	qe := &queryExecutor{db: db, ctx: db.ctx}
+	if err := qe.checkLambdaTimeout(); err != nil {
+		return err
+	}
+	client, err := qe.session().Client()
+	if err != nil {
+		return fmt.Errorf("failed to get client for consistent snapshot: %w", err)
+	}
+
+	out, err := client.TransactGetItems(qe.ctxOrBackground(), &dynamodb.TransactGetItemsInput{TransactItems: reads})
+	if err != nil {
+		return customerrors.Remediate(snapshotError(err, models))
+	}
+
+	var missing []string
+	for i, modelValue := range models {
+		var item map[string]types.AttributeValue
+		if i < len(out.Responses) {
+			item = out.Responses[i].Item
+		}
+		if len(item) == 0 || (scopes[i] != nil && !scopes[i].owns(item)) {
+			missing = append(missing, fmt.Sprintf("%d (%T)", i, modelValue))
+			continue
+		}
+
+		executor := executors[i]
+		if err := executor.loadItem(item); err != nil {
+			return fmt.Errorf("consistent snapshot item %d: %w", i, err)
+		}
+		if err := executor.checkRequiredFields(executor.metadata.TableName, "", "", []map[string]types.AttributeValue{item}); err != nil {
+			return err
+		}
+		if err := executor.unmarshalItem(item, modelValue); err != nil {
+			return fmt.Errorf("consistent snapshot item %d: %w", i, err)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: consistent snapshot items %s", customerrors.ErrItemNotFound, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// snapshotKey returns the primary key the model's key fields hold.
+func (db *DB) snapshotKey(meta *model.Metadata, modelValue reflect.Value) (map[string]types.AttributeValue, error) {
+	keyFields := []*model.FieldMetadata{meta.PrimaryKey.PartitionKey}
+	if meta.PrimaryKey.SortKey != nil {
+		keyFields = append(keyFields, meta.PrimaryKey.SortKey)
+	}
+
+	key := make(map[string]types.AttributeValue, len(keyFields))
+	for _, field := range keyFields {
+		value := modelValue.FieldByIndex(field.IndexPath)
+		if field.HasKeyTemplate() {
+			keyValue, err := meta.KeyValue(field, modelValue)
+			if err != nil {
+				return nil, err
+			}
+			value = reflect.ValueOf(field.StoredKey(keyValue))
+			if keyValue == "" {
+				value = reflect.ValueOf("")
+			}
+		}
+		if value.IsZero() {
+			return nil, fmt.Errorf("%w: key field %s is not set", customerrors.ErrInvalidPrimaryKey, field.Name)
+		}
+		av, err := db.converter.ToAttributeValue(value.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert key field %s: %w", field.Name, err)
+		}
+		key[field.DBName] = av
+	}
+	return key, nil
+}
+
+// snapshotError reports a canceled snapshot as a TransactionError naming the items
+// DynamoDB gave reasons for.
+func snapshotError(err error, models []any) error {
+	var canceled *types.TransactionCanceledException
+	if !errors.As(err, &canceled) {
+		return fmt.Errorf("failed to read consistent snapshot: %w", err)
+	}
+
+	var reasons []customerrors.CancellationReason
+	for i, reason := range canceled.CancellationReasons {
+		if reason.Code == nil || *reason.Code == "None" {
+			continue
+		}
+		modelName := "unknown"
+		if i < len(models) {
+			modelName = reflect.TypeOf(models[i]).String()
+		}
+		reasons = append(reasons, customerrors.CancellationReason{
+			Code:           *reason.Code,
+			Message:        aws.ToString(reason.Message),
+			Operation:      "Get",
+			Model:          modelName,
+			OperationIndex: i,
+		})
+	}
+	if len(reasons) == 0 {
+		return fmt.Errorf("consistent snapshot canceled: %w", err)
+	}
+
+	first := reasons[0]
+	return &customerrors.TransactionError{
+		OperationIndex: first.OperationIndex,
+		Operation:      first.Operation,
+		Model:          first.Model,
+		Reason:         first.Message,
+		Reasons:        reasons,
+		Err:            customerrors.ErrTransactionFailed,
+	}
+}
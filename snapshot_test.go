@@ -0,0 +1,86 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+type snapshotAccount struct {
+	ID      string `dynamorm:"pk,attr:id"`
+	Balance int64  `dynamorm:"attr:balance"`
+	Version int64  `dynamorm:"version,attr:version"`
+}
+
+func (snapshotAccount) TableName() string { return "snapshot_accounts" }
+
+type snapshotLimit struct {
+	Tenant string `dynamorm:"pk,attr:tenant"`
+	Kind   string `dynamorm:"sk,attr:kind"`
+	Max    int64  `dynamorm:"attr:max"`
+}
+
+func (snapshotLimit) TableName() string { return "snapshot_limits" }
+
+func TestConsistentSnapshot_ReadsAllItemsInOneTransaction(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.TransactGetItems": `{"Responses":[
+			{"Item":{"id":{"S":"a1"},"balance":{"N":"70"},"version":{"N":"3"}}},
+			{"Item":{"id":{"S":"a2"},"balance":{"N":"30"},"version":{"N":"5"}}},
+			{"Item":{"tenant":{"S":"t1"},"kind":{"S":"daily"},"max":{"N":"100"}}}
+		]}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	from, to := &snapshotAccount{ID: "a1"}, &snapshotAccount{ID: "a2"}
+	limit := &snapshotLimit{Tenant: "t1", Kind: "daily"}
+	require.NoError(t, db.ConsistentSnapshot(from, to, limit))
+
+	require.Equal(t, &snapshotAccount{ID: "a1", Balance: 70, Version: 3}, from)
+	require.Equal(t, &snapshotAccount{ID: "a2", Balance: 30, Version: 5}, to)
+	require.Equal(t, int64(100), limit.Max)
+
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.TransactGetItems"))
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.TransactGetItems")
+	require.NotNil(t, req)
+	require.Equal(t, []any{
+		map[string]any{"Get": map[string]any{"TableName": "snapshot_accounts", "Key": map[string]any{"id": map[string]any{"S": "a1"}}}},
+		map[string]any{"Get": map[string]any{"TableName": "snapshot_accounts", "Key": map[string]any{"id": map[string]any{"S": "a2"}}}},
+		map[string]any{"Get": map[string]any{"TableName": "snapshot_limits", "Key": map[string]any{
+			"tenant": map[string]any{"S": "t1"},
+			"kind":   map[string]any{"S": "daily"},
+		}}},
+	}, req.Payload["TransactItems"])
+}
+
+func TestConsistentSnapshot_ReportsMissingItems(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.TransactGetItems": `{"Responses":[{},{"Item":{"id":{"S":"a2"},"balance":{"N":"30"}}}]}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	missing, found := &snapshotAccount{ID: "a1", Balance: 9}, &snapshotAccount{ID: "a2"}
+	err := db.ConsistentSnapshot(missing, found)
+	require.ErrorIs(t, err, customerrors.ErrItemNotFound)
+	require.Contains(t, err.Error(), "0 (*dynamorm.snapshotAccount)")
+	require.Equal(t, int64(9), missing.Balance)
+	require.Equal(t, int64(30), found.Balance)
+}
+
+func TestConsistentSnapshot_ValidatesModels(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+
+	require.Error(t, db.ConsistentSnapshot())
+	require.Error(t, db.ConsistentSnapshot(snapshotAccount{ID: "a1"}))
+	require.ErrorIs(t, db.ConsistentSnapshot(&snapshotAccount{}), customerrors.ErrInvalidPrimaryKey)
+
+	tooMany := make([]any, maxSnapshotItems+1)
+	for i := range tooMany {
+		tooMany[i] = &snapshotAccount{ID: "a"}
+	}
+	require.Error(t, db.ConsistentSnapshot(tooMany...))
+	require.Empty(t, httpClient.Requests())
+}
@@ -0,0 +1,59 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+func TestWhereBetweenBuildsIndexKeyCondition(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[],"Count":0}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var users []inferredIndexUser
+	require.NoError(t, db.Model(&inferredIndexUser{}).
+		Index("tenant-index").
+		Where("Tenant", "=", "t1").
+		WhereBetween("Joined", 10, 20).
+		All(&users))
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, req)
+	require.Equal(t, "#n1 = :v1 AND #n2 BETWEEN :v2 AND :v3", req.Payload["KeyConditionExpression"])
+	require.Equal(t, map[string]any{"#n1": "tenant", "#n2": "joined"}, req.Payload["ExpressionAttributeNames"])
+}
+
+func TestWhereBeginsWithRequiresSortKey(t *testing.T) {
+	db := newStubbedDB(t, newCapturingHTTPClient(nil))
+
+	var users []inferredIndexUser
+	err := db.Model(&inferredIndexUser{}).
+		Index("tenant-index").
+		Where("Tenant", "=", "t1").
+		WhereBeginsWith("Email", "a").
+		All(&users)
+	require.ErrorIs(t, err, customerrors.ErrInvalidOperator)
+	require.ErrorContains(t, err, "Email is not a sort key of inferred_index_users index tenant-index; sort keys are Joined (tenant-index)")
+
+	err = db.Model(&inferredIndexUser{}).Index("missing-index").WhereBeginsWith("Joined", "1").All(&users)
+	require.ErrorIs(t, err, customerrors.ErrIndexNotFound)
+
+	err = db.Model(&inferredIndexUser{}).WhereBeginsWith("Joined", "").All(&users)
+	require.ErrorIs(t, err, customerrors.ErrEmptyValue)
+}
+
+func TestWhereBetweenRejectsBadBounds(t *testing.T) {
+	db := newStubbedDB(t, newCapturingHTTPClient(nil))
+
+	var users []inferredIndexUser
+	err := db.Model(&inferredIndexUser{}).Index("tenant-index").Where("Tenant", "=", "t1").WhereBetween("Joined", 20, 10).All(&users)
+	require.ErrorIs(t, err, customerrors.ErrInvalidOperator)
+	require.ErrorContains(t, err, "lower bound 20 is above upper bound 10")
+
+	err = db.Model(&inferredIndexUser{}).Index("tenant-index").Where("Tenant", "=", "t1").WhereBetween("Joined", 10, "20").All(&users)
+	require.ErrorIs(t, err, customerrors.ErrInvalidOperator)
+}
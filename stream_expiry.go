@@ -0,0 +1,143 @@
+package dynamorm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// ttlPrincipal is the stream userIdentity principal of items deleted by Time to Live.
+const ttlPrincipal = "dynamodb.amazonaws.com"
+
+// StreamConsumer dispatches the records of DynamoDB streams delivered to a Lambda
+// function to the handlers registered for each model's table.
+//
+//	consumer := dynamorm.NewStreamConsumer(db)
+//	err := consumer.OnExpired(&Session{}, func(ctx context.Context, item any) error {
+//		return revokeTokens(ctx, item.(*Session))
+//	})
+//	lambda.Start(consumer.Handle)
+type StreamConsumer struct {
+	db core.DB
+	// base is db's *DB, when it has one, for decoding encrypted and overflow fields.
+	base    *DB
+	expired map[string][]expiredHandler
+	mu      sync.RWMutex
+}
+
+type expiredHandler struct {
+	meta *model.Metadata
+	fn   func(ctx context.Context, item any) error
+}
+
+// NewStreamConsumer creates a consumer that decodes stream images with db's models.
+func NewStreamConsumer(db core.DB) *StreamConsumer {
+	c := &StreamConsumer{db: db, expired: make(map[string][]expiredHandler)}
+	switch typed := db.(type) {
+	case *DB:
+		c.base = typed
+	case *LambdaDB:
+		c.base = typed.db
+	}
+	return c
+}
+
+// OnExpired registers fn for the items of the model's table that Time to Live deletes.
+// fn receives a pointer to a new value of the model's type decoded from the deleted
+// item. The stream needs the OLD_IMAGE or NEW_AND_OLD_IMAGES view type for the item's
+// attributes to be included; with KEYS_ONLY only the key fields are set. Deletes made by
+// the application are not passed to fn. The model must have a dynamorm:"ttl" field.
+func (c *StreamConsumer) OnExpired(modelValue any, fn func(ctx context.Context, item any) error) error {
+	if fn == nil {
+		return fmt.Errorf("expiry handler for %T cannot be nil", modelValue)
+	}
+	meta, err := metadataForDB(c.db, modelValue)
+	if err != nil {
+		return err
+	}
+	if meta.TTLField == nil {
+		return fmt.Errorf("model %T has no dynamorm:\"ttl\" field", modelValue)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expired[meta.TableName] = append(c.expired[meta.TableName], expiredHandler{meta: meta, fn: fn})
+	return nil
+}
+
+// Handle processes a batch of stream records, in order, and is meant to be the Lambda
+// handler. It stops at the first handler error and returns it, so Lambda retries the
+// batch from the failed record; handlers should therefore be idempotent.
+func (c *StreamConsumer) Handle(ctx context.Context, event events.DynamoDBEvent) error {
+	for _, record := range event.Records {
+		if !IsTTLExpiry(record) {
+			continue
+		}
+
+		c.mu.RLock()
+		handlers := c.expired[streamTableName(record.EventSourceArn)]
+		c.mu.RUnlock()
+		for _, handler := range handlers {
+			item, err := c.decodeExpired(handler.meta, record)
+			if err != nil {
+				return fmt.Errorf("failed to decode expired item in stream record %s: %w", record.EventID, err)
+			}
+			if err := handler.fn(ctx, item); err != nil {
+				return fmt.Errorf("expiry handler failed for stream record %s: %w", record.EventID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// IsTTLExpiry reports whether record is the removal of an item by Time to Live rather
+// than by a DeleteItem call.
+func IsTTLExpiry(record events.DynamoDBEventRecord) bool {
+	return record.EventName == string(events.DynamoDBOperationTypeRemove) &&
+		record.UserIdentity != nil &&
+		record.UserIdentity.Type == "Service" &&
+		record.UserIdentity.PrincipalID == ttlPrincipal
+}
+
+// decodeExpired decodes the deleted item of record, or its keys without an old image,
+// into a new value of the model's type.
+func (c *StreamConsumer) decodeExpired(meta *model.Metadata, record events.DynamoDBEventRecord) (any, error) {
+	image := record.Change.OldImage
+	if len(image) == 0 {
+		image = record.Change.Keys
+	}
+	item := make(map[string]types.AttributeValue, len(image))
+	for name, value := range image {
+		item[name] = convertLambdaAttributeValue(value)
+	}
+
+	dest := reflect.New(meta.Type).Interface()
+	if c.base == nil {
+		return dest, UnmarshalItem(item, dest)
+	}
+
+	qe := &queryExecutor{db: c.base, metadata: meta, ctx: c.base.ctx}
+	if err := qe.loadItem(item); err != nil {
+		return nil, err
+	}
+	return dest, qe.unmarshalItem(item, dest)
+}
+
+// streamTableName returns the table of a stream ARN such as
+// arn:aws:dynamodb:us-east-1:123456789012:table/sessions/stream/2026-01-01T00:00:00.000.
+func streamTableName(arn string) string {
+	_, rest, found := strings.Cut(arn, ":table/")
+	if !found {
+		return ""
+	}
+	table, _, _ := strings.Cut(rest, "/")
+	return table
+}
@@ -0,0 +1,94 @@
+package dynamorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/require"
+)
+
+type expiringSession struct {
+	ID        string `dynamorm:"pk,attr:id"`
+	UserID    string `dynamorm:"attr:userId"`
+	ExpiresAt int64  `dynamorm:"ttl,attr:expiresAt"`
+}
+
+func sessionStreamRecord(t *testing.T, db *DB, id string, identity *events.DynamoDBUserIdentity) events.DynamoDBEventRecord {
+	t.Helper()
+	table, err := db.TableName(&expiringSession{})
+	require.NoError(t, err)
+	return events.DynamoDBEventRecord{
+		EventID:        "event-" + id,
+		EventName:      string(events.DynamoDBOperationTypeRemove),
+		EventSourceArn: "arn:aws:dynamodb:us-east-1:123456789012:table/" + table + "/stream/2026-01-01T00:00:00.000",
+		UserIdentity:   identity,
+		Change: events.DynamoDBStreamRecord{
+			Keys: map[string]events.DynamoDBAttributeValue{"id": events.NewStringAttribute(id)},
+			OldImage: map[string]events.DynamoDBAttributeValue{
+				"id":        events.NewStringAttribute(id),
+				"userId":    events.NewStringAttribute("user-" + id),
+				"expiresAt": events.NewNumberAttribute("1767225600"),
+			},
+		},
+	}
+}
+
+var ttlIdentity = &events.DynamoDBUserIdentity{Type: "Service", PrincipalID: "dynamodb.amazonaws.com"}
+
+func TestStreamConsumer_OnExpiredReceivesOnlyTTLDeletes(t *testing.T) {
+	db := newStubbedDB(t, newCapturingHTTPClient(nil))
+	consumer := NewStreamConsumer(db)
+
+	var expired []*expiringSession
+	require.NoError(t, consumer.OnExpired(&expiringSession{}, func(_ context.Context, item any) error {
+		expired = append(expired, item.(*expiringSession))
+		return nil
+	}))
+
+	userDelete := sessionStreamRecord(t, db, "s2", nil)
+	otherTable := sessionStreamRecord(t, db, "s3", ttlIdentity)
+	otherTable.EventSourceArn = "arn:aws:dynamodb:us-east-1:123456789012:table/other/stream/2026-01-01T00:00:00.000"
+	keysOnly := sessionStreamRecord(t, db, "s4", ttlIdentity)
+	keysOnly.Change.OldImage = nil
+
+	err := consumer.Handle(context.Background(), events.DynamoDBEvent{Records: []events.DynamoDBEventRecord{
+		sessionStreamRecord(t, db, "s1", ttlIdentity),
+		userDelete,
+		otherTable,
+		keysOnly,
+	}})
+	require.NoError(t, err)
+
+	require.Len(t, expired, 2)
+	require.Equal(t, &expiringSession{ID: "s1", UserID: "user-s1", ExpiresAt: 1767225600}, expired[0])
+	require.Equal(t, &expiringSession{ID: "s4"}, expired[1])
+}
+
+func TestStreamConsumer_HandlerErrorStopsBatch(t *testing.T) {
+	db := newStubbedDB(t, newCapturingHTTPClient(nil))
+	consumer := NewStreamConsumer(db)
+
+	failure := errors.New("revoke failed")
+	calls := 0
+	require.NoError(t, consumer.OnExpired(&expiringSession{}, func(context.Context, any) error {
+		calls++
+		return failure
+	}))
+
+	err := consumer.Handle(context.Background(), events.DynamoDBEvent{Records: []events.DynamoDBEventRecord{
+		sessionStreamRecord(t, db, "s1", ttlIdentity),
+		sessionStreamRecord(t, db, "s2", ttlIdentity),
+	}})
+	require.ErrorIs(t, err, failure)
+	require.Contains(t, err.Error(), "event-s1")
+	require.Equal(t, 1, calls)
+}
+
+func TestStreamConsumer_OnExpiredRequiresTTLField(t *testing.T) {
+	consumer := NewStreamConsumer(newStubbedDB(t, newCapturingHTTPClient(nil)))
+
+	require.Error(t, consumer.OnExpired(&testAccount{}, func(context.Context, any) error { return nil }))
+	require.Error(t, consumer.OnExpired(&expiringSession{}, nil))
+}
@@ -0,0 +1,135 @@
+package dynamorm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// strictReads holds the hook configured by WithStrictReads.
+type strictReads struct {
+	onMissing func(ctx context.Context, missing *customerrors.MissingFieldsError) error
+}
+
+// WithStrictReads returns a DB whose reads check items for fields tagged
+// dynamorm:"required" instead of leaving absent ones zero. For each item missing one,
+// onMissing is called with a *errors.MissingFieldsError; returning nil keeps the item,
+// for example after logging it, and returning an error fails the read with it. A nil
+// onMissing fails the read with the MissingFieldsError, which wraps
+// errors.ErrMissingRequiredField.
+//
+// GetItem, Query, Scan, and BatchGet are checked. Reads that select fields, and queries
+// on indexes that do not project every attribute, return partial items by design and
+// are not checked.
+func (db *DB) WithStrictReads(onMissing func(ctx context.Context, missing *customerrors.MissingFieldsError) error) core.ExtendedDB {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	newDB := db.derive()
+	newDB.strictReads = &strictReads{onMissing: onMissing}
+	return newDB
+}
+
+func (db *DB) strictReadConfig() *strictReads {
+	if db == nil {
+		return nil
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.strictReads
+}
+
+// checkRequiredFields reports items read from table that lack required fields. index and
+// projection are those of the read; partial reads are not checked.
+func (qe *queryExecutor) checkRequiredFields(table, index, projection string, items []map[string]types.AttributeValue) error {
+	strict := qe.db.strictReadConfig()
+	if strict == nil || qe.metadata == nil || projection != "" || !projectsAllAttributes(qe.metadata, index) {
+		return nil
+	}
+	required := requiredAttributes(qe.metadata)
+	if len(required) == 0 {
+		return nil
+	}
+
+	for _, item := range items {
+		var missing []string
+		for _, name := range required {
+			if _, ok := item[name]; !ok {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		err := &customerrors.MissingFieldsError{
+			Table:  table,
+			Key:    describeItemKey(qe.metadata, item),
+			Fields: missing,
+		}
+		if strict.onMissing == nil {
+			return err
+		}
+		if hookErr := strict.onMissing(qe.ctxOrBackground(), err); hookErr != nil {
+			return hookErr
+		}
+	}
+	return nil
+}
+
+// projectsAllAttributes reports whether reads from index return whole items. The table
+// does; an index does unless the model declares a narrower projection for it.
+func projectsAllAttributes(metadata *model.Metadata, index string) bool {
+	if index == "" {
+		return true
+	}
+	for _, schema := range metadata.Indexes {
+		if schema.Name == index {
+			return schema.ProjectionType == "" || schema.ProjectionType == string(types.ProjectionTypeAll)
+		}
+	}
+	return false
+}
+
+// requiredAttributes returns the sorted attribute names of the model's required fields.
+func requiredAttributes(metadata *model.Metadata) []string {
+	var names []string
+	for _, field := range metadata.Fields {
+		if field != nil && field.IsRequired() {
+			names = append(names, field.DBName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// describeItemKey formats the item's primary key as name=value pairs.
+func describeItemKey(metadata *model.Metadata, item map[string]types.AttributeValue) string {
+	if metadata.PrimaryKey == nil {
+		return ""
+	}
+	var parts []string
+	for _, field := range []*model.FieldMetadata{metadata.PrimaryKey.PartitionKey, metadata.PrimaryKey.SortKey} {
+		if field == nil {
+			continue
+		}
+		var value any
+		switch v := item[field.DBName].(type) {
+		case *types.AttributeValueMemberS:
+			value = v.Value
+		case *types.AttributeValueMemberN:
+			value = v.Value
+		case *types.AttributeValueMemberB:
+			value = v.Value
+		}
+		parts = append(parts, fmt.Sprintf("%s=%v", field.DBName, value))
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,66 @@
+package dynamorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+type strictAccount struct {
+	ID      string `dynamorm:"pk,attr:id"`
+	Status  string `dynamorm:"required,attr:status"`
+	Balance int64  `dynamorm:"attr:balance"`
+}
+
+func TestStrictReads_FailsOnMissingRequiredField(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"a1"},"balance":{"N":"10"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var lenient strictAccount
+	require.NoError(t, db.Model(&strictAccount{}).Where("ID", "=", "a1").First(&lenient))
+	require.Empty(t, lenient.Status)
+
+	var account strictAccount
+	err := db.WithStrictReads(nil).Model(&strictAccount{}).Where("ID", "=", "a1").First(&account)
+	require.ErrorIs(t, err, customerrors.ErrMissingRequiredField)
+	var missing *customerrors.MissingFieldsError
+	require.ErrorAs(t, err, &missing)
+	require.Equal(t, "strictAccounts", missing.Table)
+	require.Equal(t, "id=a1", missing.Key)
+	require.Equal(t, []string{"status"}, missing.Fields)
+
+	var projected strictAccount
+	require.NoError(t, db.WithStrictReads(nil).Model(&strictAccount{}).Where("ID", "=", "a1").Select("ID", "Balance").First(&projected))
+}
+
+func TestStrictReads_HookDecidesPerItem(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[` +
+			`{"id":{"S":"a1"},"status":{"S":"open"}},` +
+			`{"id":{"S":"a2"}}` +
+			`],"Count":2,"ScannedCount":2}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	var reported []string
+	logging := db.WithStrictReads(func(_ context.Context, missing *customerrors.MissingFieldsError) error {
+		reported = append(reported, missing.Key)
+		return nil
+	})
+	var accounts []strictAccount
+	require.NoError(t, logging.Model(&strictAccount{}).Where("ID", "=", "a1").All(&accounts))
+	require.Len(t, accounts, 2)
+	require.Equal(t, []string{"id=a2"}, reported)
+
+	quarantine := errors.New("quarantined")
+	rejecting := db.WithStrictReads(func(context.Context, *customerrors.MissingFieldsError) error {
+		return quarantine
+	})
+	require.ErrorIs(t, rejecting.Model(&strictAccount{}).Where("ID", "=", "a1").All(&accounts), quarantine)
+}
@@ -0,0 +1,61 @@
+package dynamorm
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+// testAccount is a versioned model shared by tests that exercise conditional writes.
+type testAccount struct {
+	ID      string `dynamorm:"pk,attr:id"`
+	Balance int64  `dynamorm:"attr:balance"`
+	Version int64  `dynamorm:"version,attr:version"`
+}
+
+// unversionedAccount is testAccount without optimistic locking.
+type unversionedAccount struct {
+	ID      string `dynamorm:"pk,attr:id"`
+	Balance int64  `dynamorm:"attr:balance"`
+}
+
+// testPayment carries a client token attribute for retry-safe write tests.
+type testPayment struct {
+	ID          string `dynamorm:"pk,attr:id"`
+	ClientToken string `dynamorm:"attr:clientToken"`
+	Amount      int64  `dynamorm:"attr:amount"`
+}
+
+var conditionalCheckFailedResponse = stubbedResponse{
+	status: http.StatusBadRequest,
+	body:   `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"Conditional request failed"}`,
+	headers: map[string]string{
+		"x-amzn-errortype": "ConditionalCheckFailedException",
+	},
+}
+
+// newStubbedDB returns a DB whose DynamoDB calls are answered by httpClient.
+func newStubbedDB(t *testing.T, httpClient *capturingHTTPClient) *DB {
+	t.Helper()
+	return newStubbedDBWithConfig(t, httpClient, session.Config{})
+}
+
+// newStubbedDBWithConfig is newStubbedDB with extra session settings.
+func newStubbedDBWithConfig(t *testing.T, httpClient *capturingHTTPClient, cfg session.Config) *DB {
+	t.Helper()
+	stubSessionConfigLoad(t, func(context.Context, ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return minimalAWSConfig(httpClient), nil
+	})
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	dbAny, err := New(cfg)
+	require.NoError(t, err)
+	return mustDB(t, dbAny)
+}
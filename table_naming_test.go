@@ -0,0 +1,31 @@
+package dynamorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/session"
+)
+
+func TestTableNamePrefixAppliesToRequests(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"a1"},"balance":{"N":"10"},"version":{"N":"0"}}}`,
+	})
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{TableNamePrefix: "dev_"})
+
+	require.NoError(t, db.Model(&testAccount{ID: "a1", Balance: 10}).Create())
+	var account testAccount
+	require.NoError(t, db.Model(&testAccount{}).Where("ID", "=", "a1").First(&account))
+
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	require.Equal(t, "dev_testAccounts", put.Payload["TableName"])
+	get := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.GetItem")
+	require.NotNil(t, get)
+	require.Equal(t, "dev_testAccounts", get.Payload["TableName"])
+
+	metadata, err := db.registry.GetMetadataByTable("dev_testAccounts")
+	require.NoError(t, err)
+	require.Equal(t, "testAccount", metadata.Type.Name())
+}
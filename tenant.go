@@ -0,0 +1,461 @@
+package dynamorm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/cache"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/leadingkeys"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+// tenancy holds the tenant a DB is scoped to by WithTenant.
+type tenancy struct {
+	id string
+}
+
+// WithTenant returns a DB bound to ctx whose reads and writes of models with a field
+// tagged `dynamorm:"tenant"` only see and change items of tenantID:
+//
+//	scoped := db.WithTenant(ctx, claims.TenantID)
+//	err := scoped.Model(&Invoice{}).Where("ID", "=", id).First(&invoice)
+//
+// Creates store tenantID in the tenant field when it is empty, and Query and Scan
+// requests filter on it. Gets and batch gets treat items of other tenants as missing.
+// Puts and deletes are conditioned on the item being absent or of tenantID, updates on
+// it being of tenantID, so they fail with errors.ErrConditionFailed on other tenants'
+// items. Requests that name another tenant, by key, condition, or item, fail with
+// errors.ErrCrossTenant before they are sent.
+//
+// Models with a tenant field cannot be read or written through a DB without a tenant;
+// such requests, and those made with an empty tenantID, fail with
+// errors.ErrTenantRequired. The tenant is also stored in the context for the item cache
+// and leading key checks, as by cache.WithTenant and leadingkeys.WithTenant.
+func (db *DB) WithTenant(ctx context.Context, tenantID string) core.ExtendedDB {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	newDB := db.derive()
+	newDB.tenant = &tenancy{id: tenantID}
+	newDB.ctx = newDB.tenant.context(ctx)
+	return newDB
+}
+
+// tenantConfig returns the tenant the DB is scoped to, or nil when it has none.
+func (db *DB) tenantConfig() *tenancy {
+	if db == nil {
+		return nil
+	}
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.tenant
+}
+
+// context returns ctx carrying the tenant for the item cache and leading key checks.
+func (t *tenancy) context(ctx context.Context) context.Context {
+	if t == nil || t.id == "" {
+		return ctx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return leadingkeys.WithTenant(cache.WithTenant(ctx, t.id), t.id)
+}
+
+// scopeFor returns the scope of requests for the model of metadata, or nil when the
+// model has no tenant field.
+func (t *tenancy) scopeFor(metadata *model.Metadata) (*tenantScope, error) {
+	if metadata == nil || metadata.TenantField == nil {
+		return nil, nil
+	}
+	if t == nil || t.id == "" {
+		return nil, fmt.Errorf("%w: %s is scoped to tenants; use DB.WithTenant", customerrors.ErrTenantRequired, metadata.TableName)
+	}
+	scope := &tenantScope{attribute: metadata.TenantField.DBName, tenant: t.id}
+	if metadata.PrimaryKey != nil && metadata.PrimaryKey.PartitionKey != nil {
+		scope.partitionKey = metadata.PrimaryKey.PartitionKey.DBName
+	}
+	return scope, nil
+}
+
+// scopeTransactItem confines a transaction item to the DB's tenant the way the single
+// item request of the same kind is.
+func (t *tenancy) scopeTransactItem(item *types.TransactWriteItem, metadata *model.Metadata) error {
+	scope, err := t.scopeFor(metadata)
+	if err != nil || scope == nil {
+		return err
+	}
+
+	switch {
+	case item.Put != nil:
+		if err := scope.stamp(item.Put.Item); err != nil {
+			return err
+		}
+		scope.absentOrOwnedCondition().addTo(item)
+	case item.Update != nil:
+		if err := scope.checkKey(item.Update.Key); err != nil {
+			return err
+		}
+		values, err := scope.checkUpdate(aws.ToString(item.Update.UpdateExpression), item.Update.ExpressionAttributeNames, item.Update.ExpressionAttributeValues)
+		if err != nil {
+			return err
+		}
+		item.Update.ExpressionAttributeValues = values
+		scope.ownedCondition().addTo(item)
+	case item.Delete != nil:
+		if err := scope.checkKey(item.Delete.Key); err != nil {
+			return err
+		}
+		scope.absentOrOwnedCondition().addTo(item)
+	case item.ConditionCheck != nil:
+		check := item.ConditionCheck
+		if err := scope.checkKey(check.Key); err != nil {
+			return err
+		}
+		condition, names, values := scope.ownedCondition().and(aws.ToString(check.ConditionExpression), check.ExpressionAttributeNames, check.ExpressionAttributeValues)
+		check.ConditionExpression, check.ExpressionAttributeNames, check.ExpressionAttributeValues = aws.String(condition), names, values
+	}
+	return nil
+}
+
+// tenantScope confines requests for one model to the items of one tenant.
+type tenantScope struct {
+	attribute    string
+	tenant       string
+	partitionKey string
+}
+
+// Placeholders tenant conditions, filters, and projections use.
+const (
+	tenantAttributeName = "#tenantScope"
+	tenantKeyName       = "#tenantKey"
+	tenantValueName     = ":tenantScope"
+)
+
+// tenantScope returns the scope of the executor's requests, or nil when its model has no
+// tenant field.
+func (qe *queryExecutor) tenantScope() (*tenantScope, error) {
+	if qe == nil || qe.metadata == nil || qe.metadata.TenantField == nil {
+		return nil, nil
+	}
+	return qe.db.tenantConfig().scopeFor(qe.metadata)
+}
+
+// scopeTenantRead returns a compiled Query or Scan confined to the DB's tenant.
+func (qe *queryExecutor) scopeTenantRead(input *core.CompiledQuery) (*core.CompiledQuery, error) {
+	scope, err := qe.tenantScope()
+	if err != nil || scope == nil {
+		return input, err
+	}
+	return scope.scopeRead(input, qe.keyAttributes(input.IndexName)...)
+}
+
+// scopeTenantUpdate confines an UpdateItem request to an item of the DB's tenant.
+func (qe *queryExecutor) scopeTenantUpdate(update *dynamodb.UpdateItemInput) error {
+	scope, err := qe.tenantScope()
+	if err != nil || scope == nil {
+		return err
+	}
+	return scope.scopeUpdate(update)
+}
+
+// owns reports whether item belongs to the scope's tenant.
+func (s *tenantScope) owns(item map[string]types.AttributeValue) bool {
+	v, ok := item[s.attribute].(*types.AttributeValueMemberS)
+	return ok && v.Value == s.tenant
+}
+
+// stamp stores the tenant in an item about to be written when its tenant field is empty.
+func (s *tenantScope) stamp(item map[string]types.AttributeValue) error {
+	switch v := item[s.attribute].(type) {
+	case nil, *types.AttributeValueMemberNULL:
+	case *types.AttributeValueMemberS:
+		if v.Value == s.tenant {
+			return nil
+		}
+		if v.Value != "" {
+			return s.crossTenant("item", v.Value)
+		}
+	default:
+		return fmt.Errorf("%w: item has a non-string %s", customerrors.ErrCrossTenant, s.attribute)
+	}
+	item[s.attribute] = &types.AttributeValueMemberS{Value: s.tenant}
+	return nil
+}
+
+// checkKey fails when key holds the tenant attribute with another tenant.
+func (s *tenantScope) checkKey(key map[string]types.AttributeValue) error {
+	av, ok := key[s.attribute]
+	if !ok {
+		return nil
+	}
+	if v, ok := av.(*types.AttributeValueMemberS); !ok || v.Value != s.tenant {
+		return s.crossTenant("key", tenantValueString(av))
+	}
+	return nil
+}
+
+// checkEqualities fails when expression compares the tenant attribute for equality with
+// another tenant, and reports whether it compares it with the scope's tenant.
+func (s *tenantScope) checkEqualities(expression string, names map[string]string, values map[string]types.AttributeValue) (bool, error) {
+	pinned := false
+	for _, match := range keyEqualityPattern.FindAllStringSubmatch(expression, -1) {
+		if names[match[1]] != s.attribute {
+			continue
+		}
+		av, ok := values[match[2]]
+		if !ok {
+			continue
+		}
+		if v, ok := av.(*types.AttributeValueMemberS); !ok || v.Value != s.tenant {
+			return false, s.crossTenant("condition", tenantValueString(av))
+		}
+		pinned = true
+	}
+	return pinned, nil
+}
+
+// checkUpdate fails when an update expression changes the tenant attribute other than by
+// setting it to the scope's tenant. It returns values with empty assignments to the
+// tenant attribute replaced by the tenant.
+func (s *tenantScope) checkUpdate(expression string, names map[string]string, values map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+	for action, body := range updateClauses(expression) {
+		for _, part := range splitTopLevel(body) {
+			path, operand, _ := strings.Cut(part, "=")
+			if action != "SET" {
+				path = strings.Fields(part)[0]
+			}
+			if tenantPathName(path, names) != s.attribute {
+				continue
+			}
+			operand = strings.TrimSpace(operand)
+			av, ok := values[operand]
+			if action != "SET" || !ok {
+				return nil, fmt.Errorf("%w: updates may only set %s to the tenant", customerrors.ErrCrossTenant, s.attribute)
+			}
+			switch v := av.(type) {
+			case *types.AttributeValueMemberS:
+				if v.Value == s.tenant {
+					continue
+				}
+				if v.Value != "" {
+					return nil, s.crossTenant("update", v.Value)
+				}
+			case *types.AttributeValueMemberNULL:
+			default:
+				return nil, s.crossTenant("update", tenantValueString(av))
+			}
+			values = cloneAttributeValues(values)
+			values[operand] = &types.AttributeValueMemberS{Value: s.tenant}
+		}
+	}
+	return values, nil
+}
+
+// scopeRead returns a copy of a Query or Scan confined to the tenant: a Query of an index
+// keyed on the tenant attribute must select the tenant in its key condition, others are
+// filtered on it.
+func (s *tenantScope) scopeRead(input *core.CompiledQuery, indexKeys ...string) (*core.CompiledQuery, error) {
+	for _, expression := range []string{input.KeyConditionExpression, input.FilterExpression} {
+		if _, err := s.checkEqualities(expression, input.ExpressionAttributeNames, input.ExpressionAttributeValues); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.Operation == "Query" {
+		for _, key := range indexKeys {
+			if key != s.attribute {
+				continue
+			}
+			pinned, _ := s.checkEqualities(input.KeyConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+			if !pinned {
+				return nil, fmt.Errorf("%w: queries of %s must select the tenant with %s = tenant", customerrors.ErrCrossTenant, input.TableName, s.attribute)
+			}
+			return input, nil
+		}
+	}
+
+	scoped := *input
+	scoped.FilterExpression, scoped.ExpressionAttributeNames, scoped.ExpressionAttributeValues =
+		s.ownedCondition().and(input.FilterExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	return &scoped, nil
+}
+
+// project adds the tenant attribute to a non-empty projection, so the tenant of the
+// items read can be checked.
+func (s *tenantScope) project(projection string, names map[string]string) (string, map[string]string) {
+	if projection == "" {
+		return projection, names
+	}
+	merged := make(map[string]string, len(names)+1)
+	for k, v := range names {
+		merged[k] = v
+	}
+	merged[tenantAttributeName] = s.attribute
+	return projection + ", " + tenantAttributeName, merged
+}
+
+// tenantCondition is a condition on the tenant of an item with its placeholders.
+type tenantCondition struct {
+	names      map[string]string
+	values     map[string]types.AttributeValue
+	expression string
+}
+
+// ownedCondition requires the item to belong to the tenant.
+func (s *tenantScope) ownedCondition() tenantCondition {
+	return tenantCondition{
+		expression: tenantAttributeName + " = " + tenantValueName,
+		names:      map[string]string{tenantAttributeName: s.attribute},
+		values:     map[string]types.AttributeValue{tenantValueName: &types.AttributeValueMemberS{Value: s.tenant}},
+	}
+}
+
+// absentOrOwnedCondition requires the item to be absent or to belong to the tenant.
+func (s *tenantScope) absentOrOwnedCondition() tenantCondition {
+	c := s.ownedCondition()
+	c.names[tenantKeyName] = s.partitionKey
+	c.expression = "(attribute_not_exists(" + tenantKeyName + ") OR " + c.expression + ")"
+	return c
+}
+
+// addTo ANDs c onto the condition of write.
+func (c tenantCondition) addTo(write *types.TransactWriteItem) {
+	addUniqueCondition(write, c.expression, c.names, c.values)
+}
+
+// and returns expression ANDed with c, and names and values merged with c's into new
+// maps.
+func (c tenantCondition) and(expression string, names map[string]string, values map[string]types.AttributeValue) (string, map[string]string, map[string]types.AttributeValue) {
+	write := &types.TransactWriteItem{Put: &types.Put{
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}}
+	if expression != "" {
+		write.Put.ConditionExpression = aws.String(expression)
+	}
+	c.addTo(write)
+	return aws.ToString(write.Put.ConditionExpression), write.Put.ExpressionAttributeNames, write.Put.ExpressionAttributeValues
+}
+
+// scopeCondition returns a copy of input with c ANDed onto its condition.
+func (c tenantCondition) scopeCondition(input *core.CompiledQuery) *core.CompiledQuery {
+	scoped := *input
+	scoped.ConditionExpression, scoped.ExpressionAttributeNames, scoped.ExpressionAttributeValues =
+		c.and(input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	return &scoped
+}
+
+// scopeUpdate confines an UpdateItem request to an item of the tenant.
+func (s *tenantScope) scopeUpdate(update *dynamodb.UpdateItemInput) error {
+	if err := s.checkKey(update.Key); err != nil {
+		return err
+	}
+	values, err := s.checkUpdate(aws.ToString(update.UpdateExpression), update.ExpressionAttributeNames, update.ExpressionAttributeValues)
+	if err != nil {
+		return err
+	}
+	condition, names, values := s.ownedCondition().and(aws.ToString(update.ConditionExpression), update.ExpressionAttributeNames, values)
+	update.ConditionExpression, update.ExpressionAttributeNames, update.ExpressionAttributeValues = aws.String(condition), names, values
+	return nil
+}
+
+// scopeBatchWrite confines a BatchWriteItem request to the tenant. Batch writes cannot
+// carry conditions, so only models keyed on the tenant attribute, whose keys name the
+// tenant, can be written in batches.
+func (s *tenantScope) scopeBatchWrite(keyAttributes []string, writeRequests []types.WriteRequest) error {
+	keyed := false
+	for _, name := range keyAttributes {
+		keyed = keyed || name == s.attribute
+	}
+	if !keyed {
+		return fmt.Errorf("%w: batch writes cannot check the tenant of the items they replace; write the items one at a time", customerrors.ErrInvalidOperator)
+	}
+	for _, write := range writeRequests {
+		switch {
+		case write.PutRequest != nil:
+			if err := s.stamp(write.PutRequest.Item); err != nil {
+				return err
+			}
+		case write.DeleteRequest != nil:
+			if _, ok := write.DeleteRequest.Key[s.attribute]; !ok {
+				return fmt.Errorf("%w: delete key has no %s", customerrors.ErrCrossTenant, s.attribute)
+			}
+			if err := s.checkKey(write.DeleteRequest.Key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *tenantScope) crossTenant(what, tenant string) error {
+	return fmt.Errorf("%w: %s names tenant %q, DB is scoped to %q", customerrors.ErrCrossTenant, what, tenant, s.tenant)
+}
+
+// keyAttributes returns the key attributes of the base table or the named index.
+func (qe *queryExecutor) keyAttributes(index string) []string {
+	var pk, sk *model.FieldMetadata
+	if idx := findIndexSchema(qe.metadata, index); index != "" && idx != nil {
+		pk, sk = idx.PartitionKey, idx.SortKey
+	} else if qe.metadata.PrimaryKey != nil {
+		pk, sk = qe.metadata.PrimaryKey.PartitionKey, qe.metadata.PrimaryKey.SortKey
+	}
+	var names []string
+	for _, field := range []*model.FieldMetadata{pk, sk} {
+		if field != nil {
+			names = append(names, field.DBName)
+		}
+	}
+	return names
+}
+
+// checkPartiQLTenant rejects PartiQL statements on a DB scoped to a tenant or on the
+// table of a model with a tenant field, which cannot be confined to a tenant.
+func (db *DB) checkPartiQLTenant(statement string) error {
+	if db.tenantConfig() != nil {
+		return fmt.Errorf("%w: PartiQL statements cannot be scoped to a tenant", customerrors.ErrInvalidOperator)
+	}
+	_, table := partiQLTarget(statement)
+	if table == "" {
+		return nil
+	}
+	if meta, err := db.registry.GetMetadataByTable(table); err == nil && meta.TenantField != nil {
+		return fmt.Errorf("%w: PartiQL statements cannot be scoped to a tenant, and %s is scoped to tenants", customerrors.ErrInvalidOperator, table)
+	}
+	return nil
+}
+
+// tenantPathName returns the attribute at the root of a document path.
+func tenantPathName(path string, names map[string]string) string {
+	path = strings.TrimSpace(path)
+	if i := strings.IndexAny(path, ".["); i >= 0 {
+		path = path[:i]
+	}
+	if name, ok := names[path]; ok {
+		return name
+	}
+	return path
+}
+
+func tenantValueString(av types.AttributeValue) string {
+	if value, ok := leadingKeyValue(av); ok {
+		return value
+	}
+	return fmt.Sprintf("%T", av)
+}
+
+func cloneAttributeValues(values map[string]types.AttributeValue) map[string]types.AttributeValue {
+	cloned := make(map[string]types.AttributeValue, len(values)+1)
+	for k, v := range values {
+		cloned[k] = v
+	}
+	return cloned
+}
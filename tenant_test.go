@@ -0,0 +1,241 @@
+package dynamorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/cache"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/session"
+	"github.com/pay-theory/dynamorm/pkg/transaction"
+)
+
+type tenantInvoice struct {
+	ID       string `dynamorm:"pk,attr:id"`
+	TenantID string `dynamorm:"tenant,attr:tenantId"`
+	Status   string `dynamorm:"attr:status"`
+}
+
+func (tenantInvoice) TableName() string { return "invoices" }
+
+type tenantShipment struct {
+	TenantID string `dynamorm:"pk,tenant,attr:tenantId"`
+	ID       string `dynamorm:"sk,attr:id"`
+	Status   string `dynamorm:"attr:status"`
+}
+
+func (tenantShipment) TableName() string { return "shipments" }
+
+func TestTenantRequiresScope(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+
+	var invoice tenantInvoice
+	err := db.Model(&tenantInvoice{ID: "i1"}).First(&invoice)
+	require.ErrorIs(t, err, customerrors.ErrTenantRequired)
+	err = db.WithTenant(context.Background(), "").Model(&tenantInvoice{ID: "i1", Status: "paid"}).Create()
+	require.ErrorIs(t, err, customerrors.ErrTenantRequired)
+	require.Empty(t, httpClient.Requests())
+}
+
+func TestTenantScopesWrites(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient).WithTenant(context.Background(), "t1")
+	require.Equal(t, "t1", cache.TenantFromContext(db.(*DB).ctx))
+
+	require.NoError(t, db.Model(&tenantInvoice{ID: "i1", Status: "open"}).Create())
+	put := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, put)
+	require.Equal(t, map[string]any{"S": "t1"}, put.Payload["Item"].(map[string]any)["tenantId"])
+	require.Contains(t, put.Payload["ConditionExpression"], "(attribute_not_exists(#tenantKey) OR #tenantScope = :tenantScope)")
+	require.Equal(t, "id", put.Payload["ExpressionAttributeNames"].(map[string]any)["#tenantKey"])
+
+	err := db.Model(&tenantInvoice{ID: "i2", TenantID: "t2"}).Create()
+	require.ErrorIs(t, err, customerrors.ErrCrossTenant)
+
+	require.NoError(t, db.Model(&tenantInvoice{ID: "i1", Status: "paid"}).Update("Status"))
+	update := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem")
+	require.NotNil(t, update)
+	require.Contains(t, update.Payload["ConditionExpression"], "#tenantScope = :tenantScope")
+	require.Equal(t, map[string]any{"S": "t1"}, update.Payload["ExpressionAttributeValues"].(map[string]any)[":tenantScope"])
+
+	err = db.Model(&tenantInvoice{ID: "i1", TenantID: "t2"}).Update("TenantID")
+	require.ErrorIs(t, err, customerrors.ErrCrossTenant)
+
+	require.NoError(t, db.Model(&tenantInvoice{ID: "i1"}).Delete())
+	del := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.DeleteItem")
+	require.NotNil(t, del)
+	require.Contains(t, del.Payload["ConditionExpression"], "attribute_not_exists(#tenantKey) OR #tenantScope = :tenantScope")
+
+	err = db.Model(&tenantInvoice{}).BatchCreate([]tenantInvoice{{ID: "i3"}})
+	require.ErrorIs(t, err, customerrors.ErrInvalidOperator)
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.BatchWriteItem"))
+}
+
+func TestTenantScopesReads(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"i1"},"tenantId":{"S":"t2"},"status":{"S":"open"}}}`,
+		"DynamoDB_20120810.Scan":    `{"Items":[],"Count":0,"ScannedCount":0}`,
+	})
+	db := newStubbedDB(t, httpClient).WithTenant(context.Background(), "t1")
+
+	var invoice tenantInvoice
+	err := db.Model(&tenantInvoice{ID: "i1"}).First(&invoice)
+	require.ErrorIs(t, err, customerrors.ErrItemNotFound)
+	require.Empty(t, invoice)
+
+	var invoices []tenantInvoice
+	require.NoError(t, db.Model(&tenantInvoice{}).Where("Status", "=", "open").Scan(&invoices))
+	scan := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan")
+	require.NotNil(t, scan)
+	require.Contains(t, scan.Payload["FilterExpression"], "#tenantScope = :tenantScope")
+	require.Equal(t, "tenantId", scan.Payload["ExpressionAttributeNames"].(map[string]any)["#tenantScope"])
+
+	err = db.Model(&tenantInvoice{}).Where("TenantID", "=", "t2").Scan(&invoices)
+	require.ErrorIs(t, err, customerrors.ErrCrossTenant)
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan"))
+
+	_, err = db.(*DB).PartiQL(`SELECT * FROM "invoices"`).Page(&invoices)
+	require.ErrorIs(t, err, customerrors.ErrInvalidOperator)
+}
+
+func TestTenantScopesACopyOfReads(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Scan": `{"Items":[],"Count":0,"ScannedCount":0}`,
+	})
+	db := newStubbedDB(t, httpClient).WithTenant(context.Background(), "t1").(*DB)
+	require.NoError(t, db.registry.Register(&tenantInvoice{}))
+	metadata, err := db.registry.GetMetadata(&tenantInvoice{})
+	require.NoError(t, err)
+
+	input := &core.CompiledQuery{
+		Operation:                "Scan",
+		TableName:                "invoices",
+		FilterExpression:         "#tenantScope = :status",
+		ExpressionAttributeNames: map[string]string{"#tenantScope": "status"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":status": &types.AttributeValueMemberS{Value: "open"},
+		},
+	}
+	executor := &queryExecutor{db: db, metadata: metadata, ctx: db.ctx}
+	var invoices []tenantInvoice
+	require.NoError(t, executor.ExecuteScan(input, &invoices))
+
+	scan := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan")
+	require.NotNil(t, scan)
+	require.Contains(t, scan.Payload["FilterExpression"], "#tenantScope = :tenantScope")
+	require.Equal(t, "tenantId", scan.Payload["ExpressionAttributeNames"].(map[string]any)["#tenantScope"])
+	require.Equal(t, "#tenantScope = :status", input.FilterExpression, "the caller's query is not modified")
+	require.Equal(t, map[string]string{"#tenantScope": "status"}, input.ExpressionAttributeNames)
+	require.Len(t, input.ExpressionAttributeValues, 1)
+}
+
+func TestTenantKeyedModels(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[],"Count":0,"ScannedCount":0}`,
+	})
+	db := newStubbedDB(t, httpClient).WithTenant(context.Background(), "t1")
+
+	var shipments []tenantShipment
+	require.NoError(t, db.Model(&tenantShipment{}).Where("TenantID", "=", "t1").All(&shipments))
+	q := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, q)
+	require.NotContains(t, q.Payload, "FilterExpression")
+
+	err := db.Model(&tenantShipment{}).Where("TenantID", "=", "t2").All(&shipments)
+	require.ErrorIs(t, err, customerrors.ErrCrossTenant)
+
+	require.NoError(t, db.Model(&tenantShipment{}).BatchCreate([]tenantShipment{{ID: "o1"}}))
+	batch := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.BatchWriteItem")
+	require.NotNil(t, batch)
+	require.Contains(t, batch.Payload["RequestItems"].(map[string]any)["shipments"].([]any)[0].(map[string]any)["PutRequest"].(map[string]any)["Item"], "tenantId")
+
+	err = db.(*DB).TransactWrite(context.Background(), func(tx core.TransactionBuilder) error {
+		tx.Put(&tenantShipment{ID: "o2", Status: "open"})
+		tx.Delete(&tenantShipment{TenantID: "t1", ID: "o3"})
+		return nil
+	})
+	require.NoError(t, err)
+	items := transactItems(t, httpClient)
+	require.Len(t, items, 2)
+	put := items[0]["Put"].(map[string]any)
+	require.Equal(t, map[string]any{"S": "t1"}, put["Item"].(map[string]any)["tenantId"])
+	require.Contains(t, put["ConditionExpression"], "#tenantScope = :tenantScope")
+	require.Contains(t, items[1]["Delete"].(map[string]any)["ConditionExpression"], "#tenantScope = :tenantScope")
+
+	err = db.(*DB).TransactWrite(context.Background(), func(tx core.TransactionBuilder) error {
+		tx.Delete(&tenantShipment{TenantID: "t2", ID: "o3"})
+		return nil
+	})
+	require.ErrorIs(t, err, customerrors.ErrCrossTenant)
+
+	err = newStubbedDB(t, httpClient).TransactWrite(context.Background(), func(tx core.TransactionBuilder) error {
+		tx.Delete(&tenantShipment{TenantID: "t1", ID: "o3"})
+		return nil
+	})
+	require.ErrorIs(t, err, customerrors.ErrTenantRequired)
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.TransactWriteItems"))
+}
+
+func TestTenantScopesTransactionFunc(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+	require.NoError(t, db.registry.Register(&tenantInvoice{}))
+	scoped := db.WithTenant(context.Background(), "t1").(*DB)
+
+	require.NoError(t, scoped.TransactionFunc(func(tx any) error {
+		txn := tx.(*transaction.Transaction)
+		if err := txn.Create(&tenantInvoice{ID: "i1", Status: "open"}); err != nil {
+			return err
+		}
+		return txn.Delete(&tenantInvoice{ID: "i2"})
+	}))
+	items := transactItems(t, httpClient)
+	require.Len(t, items, 2)
+	put := items[0]["Put"].(map[string]any)
+	require.Equal(t, map[string]any{"S": "t1"}, put["Item"].(map[string]any)["tenantId"])
+	require.Contains(t, put["ConditionExpression"], "#tenantScope = :tenantScope")
+	require.Contains(t, items[1]["Delete"].(map[string]any)["ConditionExpression"], "#tenantScope = :tenantScope")
+
+	err := scoped.TransactionFunc(func(tx any) error {
+		return tx.(*transaction.Transaction).Create(&tenantInvoice{ID: "i3", TenantID: "t2"})
+	})
+	require.ErrorIs(t, err, customerrors.ErrCrossTenant)
+	err = db.TransactionFunc(func(tx any) error {
+		return tx.(*transaction.Transaction).Delete(&tenantInvoice{ID: "i2"})
+	})
+	require.ErrorIs(t, err, customerrors.ErrTenantRequired)
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.TransactWriteItems"))
+}
+
+type tenantCard struct {
+	ID       string `dynamorm:"pk,attr:id"`
+	TenantID string `dynamorm:"tenant,attr:tenantId"`
+	Number   string `dynamorm:"encrypted,attr:number"`
+}
+
+func (tenantCard) TableName() string { return "cards" }
+
+func TestTenantChecksOwnerBeforeLoadingItems(t *testing.T) {
+	const other = `{"id":{"S":"c1"},"tenantId":{"S":"t2"},"number":{"B":"MTExNDQ="},"aws_dbe_head":{"B":"aGVhZGVy"}}`
+	const own = `{"id":{"S":"c2"},"tenantId":{"S":"t1"},"number":{"B":"MTExNDQ="},"aws_dbe_head":{"B":"aGVhZGVy"}}`
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem":      `{"Item":` + other + `}`,
+		"DynamoDB_20120810.BatchGetItem": `{"Responses":{"cards":[` + other + `,` + own + `]}}`,
+	})
+	encryptor := &reversingItemEncryptor{}
+	db := newStubbedDBWithConfig(t, httpClient, session.Config{ItemEncryptor: encryptor}).WithTenant(context.Background(), "t1")
+
+	var card tenantCard
+	require.ErrorIs(t, db.Model(&tenantCard{ID: "c1"}).First(&card), customerrors.ErrItemNotFound)
+	require.Empty(t, encryptor.tables, "another tenant's item is not decrypted")
+
+	var cards []tenantCard
+	require.NoError(t, db.Model(&tenantCard{}).BatchGet([]any{"c1", "c2"}, &cards))
+	require.Equal(t, []tenantCard{{ID: "c2", TenantID: "t1", Number: "44111"}}, cards)
+	require.Equal(t, []string{"cards"}, encryptor.tables)
+}
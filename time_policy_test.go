@@ -0,0 +1,56 @@
+package dynamorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	pkgTypes "github.com/pay-theory/dynamorm/pkg/types"
+)
+
+type timedEvent struct {
+	OccurredAt time.Time `dynamorm:"attr:occurredAt"`
+	ID         string    `dynamorm:"pk,attr:id"`
+}
+
+func TestSetTimePolicy_StoresAndFiltersInUTC(t *testing.T) {
+	eastern := time.Date(2024, 3, 1, 10, 30, 0, 0, time.FixedZone("EST", -5*60*60))
+
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.PutItem": `{}`,
+		"DynamoDB_20120810.Scan":    `{"Items":[],"Count":0}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	require.NoError(t, db.Model(&timedEvent{ID: "e1", OccurredAt: eastern}).Create())
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, req)
+	require.Equal(t, map[string]any{"S": "2024-03-01T10:30:00-05:00"}, req.Payload["Item"].(map[string]any)["occurredAt"],
+		"the default keeps the value's own zone")
+
+	httpClient = newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.PutItem": `{}`,
+		"DynamoDB_20120810.Scan":    `{"Items":[],"Count":0}`,
+	})
+	db = newStubbedDB(t, httpClient)
+	db.SetTimePolicy(pkgTypes.TimeUTC)
+
+	require.NoError(t, db.Model(&timedEvent{ID: "e1", OccurredAt: eastern}).Create())
+	req = findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem")
+	require.NotNil(t, req)
+	require.Equal(t, map[string]any{"S": "2024-03-01T15:30:00Z"}, req.Payload["Item"].(map[string]any)["occurredAt"])
+
+	var events []timedEvent
+	require.NoError(t, db.Model(&timedEvent{}).Filter("OccurredAt", "=", eastern).Scan(&events))
+	req = findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Scan")
+	require.NotNil(t, req)
+	values := req.Payload["ExpressionAttributeValues"].(map[string]any)
+	var found bool
+	for _, v := range values {
+		if m, ok := v.(map[string]any); ok && m["S"] == "2024-03-01T15:30:00Z" {
+			found = true
+		}
+	}
+	require.True(t, found, "filter value should be normalized to UTC: %v", values)
+}
@@ -0,0 +1,102 @@
+package dynamorm
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+func TestAtomicTransaction_CommitsQueuedWritesAcrossModels(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.TransactWriteItems": `{}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	err := db.AtomicTransaction(func(tx *core.Tx) error {
+		require.NoError(t, tx.Create(&testPayment{ID: "p1", Amount: 100}))
+		require.NoError(t, tx.UpdateWithConditions(&testAccount{ID: "a1", Balance: 50, Version: 3}, []string{"Balance"},
+			core.TransactCondition{Kind: core.TransactConditionKindField, Field: "Balance", Operator: ">=", Value: 100}))
+		require.NoError(t, tx.ConditionCheck(&testAccount{ID: "a2"}, core.TransactCondition{Kind: core.TransactConditionKindPrimaryKeyExists}))
+		return nil
+	})
+	require.NoError(t, err)
+
+	reqs := httpClient.Requests()
+	require.Equal(t, 1, countRequestsByTarget(reqs, "DynamoDB_20120810.TransactWriteItems"))
+	require.Zero(t, countRequestsByTarget(reqs, "DynamoDB_20120810.PutItem"))
+
+	items := findRequestByTarget(reqs, "DynamoDB_20120810.TransactWriteItems").Payload["TransactItems"].([]any)
+	require.Len(t, items, 3)
+	require.Contains(t, items[0], "Put")
+	require.Contains(t, items[1].(map[string]any)["Update"].(map[string]any)["ConditionExpression"], ">=")
+	require.Contains(t, items[2], "ConditionCheck")
+}
+
+func TestAtomicTransaction_ErrorDiscardsQueuedWrites(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+
+	err := db.AtomicTransaction(func(tx *core.Tx) error {
+		require.NoError(t, tx.Create(&testPayment{ID: "p1"}))
+		return errors.New("abort")
+	})
+	require.EqualError(t, err, "abort")
+	require.Empty(t, httpClient.Requests())
+
+	require.NoError(t, db.AtomicTransaction(func(*core.Tx) error { return nil }), "an empty transaction is a no-op")
+	require.Empty(t, httpClient.Requests())
+}
+
+func TestAtomicTransaction_ReturnsTypedCancellationReasons(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.TransactWriteItems", []stubbedResponse{{
+		status: http.StatusBadRequest,
+		body: `{"__type":"com.amazonaws.dynamodb.v20120810#TransactionCanceledException","message":"Transaction cancelled",` +
+			`"CancellationReasons":[{"Code":"None"},{"Code":"ConditionalCheckFailed","Message":"The conditional request failed"}]}`,
+		headers: map[string]string{"x-amzn-errortype": "TransactionCanceledException"},
+	}})
+	db := newStubbedDB(t, httpClient)
+
+	err := db.AtomicTransaction(func(tx *core.Tx) error {
+		require.NoError(t, tx.Create(&testPayment{ID: "p1"}))
+		require.NoError(t, tx.Delete(&testAccount{ID: "a1"}, core.TransactCondition{Kind: core.TransactConditionKindPrimaryKeyExists}))
+		return nil
+	})
+	require.ErrorIs(t, err, customerrors.ErrConditionFailed)
+
+	var txErr *customerrors.TransactionError
+	require.ErrorAs(t, err, &txErr)
+	require.Len(t, txErr.Reasons, 1)
+	reason := txErr.Reasons[0]
+	require.True(t, reason.ConditionFailed())
+	require.Equal(t, 1, reason.OperationIndex)
+	require.Equal(t, "Delete", reason.Operation)
+	require.Equal(t, "*dynamorm.testAccount", reason.Model)
+}
+
+func TestTransaction_AppliesWritesImmediately(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.PutItem":    `{}`,
+		"DynamoDB_20120810.UpdateItem": `{}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	err := db.Transaction(func(tx *core.Tx) error {
+		payment := &testPayment{ID: "p1", Amount: 100}
+		require.NoError(t, tx.Create(payment))
+		require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem"))
+
+		payment.Amount = 120
+		return tx.Update(payment, "Amount")
+	})
+	require.NoError(t, err)
+
+	reqs := httpClient.Requests()
+	require.Equal(t, 1, countRequestsByTarget(reqs, "DynamoDB_20120810.UpdateItem"))
+	require.Zero(t, countRequestsByTarget(reqs, "DynamoDB_20120810.TransactWriteItems"))
+}
@@ -0,0 +1,285 @@
+package dynamorm
+
+import (
+	"context"
+	"time"
+
+	"github.com/pay-theory/dynamorm/pkg/cond"
+	"github.com/pay-theory/dynamorm/pkg/core"
+)
+
+// Query is a type-safe wrapper around core.Query for model type T. Results are returned
+// as T values instead of being written through an any destination:
+//
+//	order, err := dynamorm.ModelOf[Order](db).Where("ID", "=", id).First()
+//	orders, err := dynamorm.ModelOf[Order](db).Index("gsi-customer").Where("CustomerID", "=", cid).All()
+//
+// Builder methods modify and return the same Query, like core.Query. Use Raw for
+// operations without a typed equivalent.
+type Query[T any] struct {
+	q core.Query
+}
+
+// ModelOf starts a typed query for model type T.
+func ModelOf[T any](db core.DB) *Query[T] {
+	return &Query[T]{q: db.Model(new(T))}
+}
+
+// Raw returns the underlying core.Query.
+func (q *Query[T]) Raw() core.Query {
+	return q.q
+}
+
+// Where adds a key condition.
+func (q *Query[T]) Where(field string, op string, value any) *Query[T] {
+	q.q = q.q.Where(field, op, value)
+	return q
+}
+
+// WhereBeginsWith adds a begins_with condition on the sort key.
+func (q *Query[T]) WhereBeginsWith(field string, prefix string) *Query[T] {
+	q.q = q.q.WhereBeginsWith(field, prefix)
+	return q
+}
+
+// WhereBetween adds an inclusive BETWEEN condition on the sort key.
+func (q *Query[T]) WhereBetween(field string, lo, hi any) *Query[T] {
+	q.q = q.q.WhereBetween(field, lo, hi)
+	return q
+}
+
+// Index queries the named secondary index.
+func (q *Query[T]) Index(indexName string) *Query[T] {
+	q.q = q.q.Index(indexName)
+	return q
+}
+
+// Filter adds a filter condition joined with AND.
+func (q *Query[T]) Filter(field string, op string, value any) *Query[T] {
+	q.q = q.q.Filter(field, op, value)
+	return q
+}
+
+// OrFilter adds a filter condition joined with OR.
+func (q *Query[T]) OrFilter(field string, op string, value any) *Query[T] {
+	q.q = q.q.OrFilter(field, op, value)
+	return q
+}
+
+// FilterFunc adds the filter conditions built by fn, joined to existing filters with AND.
+func (q *Query[T]) FilterFunc(fn func(b *cond.Builder)) *Query[T] {
+	q.q = q.q.FilterFunc(fn)
+	return q
+}
+
+// OrderBy sets the sort key order ("asc" or "desc").
+func (q *Query[T]) OrderBy(field string, order string) *Query[T] {
+	q.q = q.q.OrderBy(field, order)
+	return q
+}
+
+// Limit caps the number of items returned.
+func (q *Query[T]) Limit(limit int) *Query[T] {
+	q.q = q.q.Limit(limit)
+	return q
+}
+
+// Offset sets the starting position for the query.
+func (q *Query[T]) Offset(offset int) *Query[T] {
+	q.q = q.q.Offset(offset)
+	return q
+}
+
+// Select retrieves only the named fields.
+func (q *Query[T]) Select(fields ...string) *Query[T] {
+	q.q = q.q.Select(fields...)
+	return q
+}
+
+// Profile retrieves only the fields included in the named serialization profile.
+func (q *Query[T]) Profile(name string) *Query[T] {
+	q.q = q.q.Profile(name)
+	return q
+}
+
+// ConsistentRead enables strongly consistent reads.
+func (q *Query[T]) ConsistentRead() *Query[T] {
+	q.q = q.q.ConsistentRead()
+	return q
+}
+
+// WithRetry retries eventually consistent reads that find nothing.
+func (q *Query[T]) WithRetry(maxRetries int, initialDelay time.Duration) *Query[T] {
+	q.q = q.q.WithRetry(maxRetries, initialDelay)
+	return q
+}
+
+// Preload loads the named relationships into the results of First and All.
+func (q *Query[T]) Preload(relations ...string) *Query[T] {
+	q.q = q.q.Preload(relations...)
+	return q
+}
+
+// Cursor resumes from a cursor returned by AllPaginated.
+func (q *Query[T]) Cursor(cursor string) *Query[T] {
+	q.q = q.q.Cursor(cursor)
+	return q
+}
+
+// WithContext sets the context for the query.
+func (q *Query[T]) WithContext(ctx context.Context) *Query[T] {
+	q.q = q.q.WithContext(ctx)
+	return q
+}
+
+// First returns the first matching item.
+func (q *Query[T]) First() (T, error) {
+	var item T
+	err := q.q.First(&item)
+	return item, err
+}
+
+// All returns every matching item.
+func (q *Query[T]) All() ([]T, error) {
+	var items []T
+	if err := q.q.All(&items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// AllPaginated returns one page of matching items with its pagination metadata.
+func (q *Query[T]) AllPaginated() ([]T, *core.PaginatedResult, error) {
+	var items []T
+	result, err := q.q.AllPaginated(&items)
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, result, nil
+}
+
+// Pages calls fn with the items of each page; returning false stops early.
+func (q *Query[T]) Pages(fn func(items []T, page *core.PaginatedResult) bool) error {
+	var items []T
+	return q.q.Pages(&items, func(page *core.PaginatedResult) bool {
+		return fn(items, page)
+	})
+}
+
+// Stream pages through the matching items in the background and sends each on the
+// returned channel, so a large query or scan is never held in memory whole; only the
+// page being sent is. Once paging ends the error channel receives the error, if any,
+// and both channels close:
+//
+//	items, errs := dynamorm.ModelOf[Order](db).Where("CustomerID", "=", cid).Stream(ctx)
+//	for order := range items {
+//		process(order)
+//	}
+//	if err := <-errs; err != nil {
+//		return err
+//	}
+//
+// Canceling ctx stops paging and reports ctx.Err(). A caller that stops reading items
+// early must cancel ctx, or the background goroutine never exits.
+func (q *Query[T]) Stream(ctx context.Context) (<-chan T, <-chan error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	items := make(chan T)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(items)
+
+		var page []T
+		err := q.q.WithContext(ctx).Pages(&page, func(*core.PaginatedResult) bool {
+			for _, item := range page {
+				select {
+				case items <- item:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return ctx.Err() == nil
+		})
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return items, errs
+}
+
+// Scan returns every item in the table (or index) that passes the filters.
+func (q *Query[T]) Scan() ([]T, error) {
+	var items []T
+	if err := q.q.Scan(&items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// BatchGet returns the items with the given primary keys. Missing keys are skipped.
+func (q *Query[T]) BatchGet(keys ...any) ([]T, error) {
+	var items []T
+	if err := q.q.BatchGet(keys, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// BatchGetOrdered returns the items with the given primary keys, with result[i] holding
+// the item for keys[i] or nil when it does not exist.
+func (q *Query[T]) BatchGetOrdered(keys ...any) ([]*T, error) {
+	var items []*T
+	if err := q.q.BatchGetOrdered(keys, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Count returns the number of matching items.
+func (q *Query[T]) Count() (int64, error) {
+	return q.q.Count()
+}
+
+// CountWithResult returns the number of matching items and the number DynamoDB evaluated.
+func (q *Query[T]) CountWithResult() (*core.CountResult, error) {
+	return q.q.CountWithResult()
+}
+
+// AtomicIncrement adds delta to a numeric field and returns its new value.
+func (q *Query[T]) AtomicIncrement(field string, delta int64) (int64, error) {
+	return q.q.AtomicIncrement(field, delta)
+}
+
+// AtomicDecrement subtracts delta from a numeric field and returns its new value.
+func (q *Query[T]) AtomicDecrement(field string, delta int64) (int64, error) {
+	return q.q.AtomicDecrement(field, delta)
+}
+
+// Delete deletes the item identified by the key conditions.
+func (q *Query[T]) Delete() error {
+	return q.q.Delete()
+}
+
+// BatchGetMap returns the items with the given primary keys, keyed by those keys. Keys
+// without an item are left out. Methods cannot take type parameters, so the key type is
+// given here:
+//
+//	byID, err := dynamorm.BatchGetMap(dynamorm.ModelOf[Order](db), "o1", "o2")
+func BatchGetMap[K comparable, T any](q *Query[T], keys ...K) (map[K]T, error) {
+	anyKeys := make([]any, len(keys))
+	for i, key := range keys {
+		anyKeys[i] = key
+	}
+	items := make(map[K]T, len(keys))
+	if err := q.q.BatchGetMap(anyKeys, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
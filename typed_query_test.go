@@ -0,0 +1,120 @@
+package dynamorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pay-theory/dynamorm/pkg/cond"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+func TestModelOf_FirstReturnsTypedValue(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"id":{"S":"a1"},"balance":{"N":"10"},"version":{"N":"2"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	account, err := ModelOf[testAccount](db).Where("ID", "=", "a1").ConsistentRead().First()
+	require.NoError(t, err)
+	require.Equal(t, testAccount{ID: "a1", Balance: 10, Version: 2}, account)
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.GetItem")
+	require.NotNil(t, req)
+	require.Equal(t, true, req.Payload["ConsistentRead"])
+}
+
+func TestModelOf_FirstNotFound(t *testing.T) {
+	db := newStubbedDB(t, newCapturingHTTPClient(nil))
+
+	account, err := ModelOf[testAccount](db).Where("ID", "=", "missing").First()
+	require.ErrorIs(t, err, customerrors.ErrItemNotFound)
+	require.Equal(t, testAccount{}, account)
+}
+
+func TestModelOf_AllAndPages(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.Query", []stubbedResponse{
+		{body: pagesFirstPage},
+		{body: pagesSecondPage},
+		{body: pagesFirstPage},
+		{body: pagesSecondPage},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	accounts, err := ModelOf[testAccount](db).Where("ID", "=", "a1").All()
+	require.NoError(t, err)
+	require.Len(t, accounts, 3)
+	require.Equal(t, int64(3), accounts[2].Balance)
+
+	var balances []int64
+	err = ModelOf[testAccount](db).Where("ID", "=", "a1").Pages(func(items []testAccount, page *core.PaginatedResult) bool {
+		for _, account := range items {
+			balances = append(balances, account.Balance)
+		}
+		return true
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2, 3}, balances)
+}
+
+func TestModelOf_StreamSendsItemsAcrossPages(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.Query", []stubbedResponse{
+		{body: pagesFirstPage},
+		{body: pagesSecondPage},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	items, errs := ModelOf[testAccount](db).Where("ID", "=", "a1").Stream(context.Background())
+	var balances []int64
+	for account := range items {
+		balances = append(balances, account.Balance)
+	}
+	require.NoError(t, <-errs)
+	require.Equal(t, []int64{1, 2, 3}, balances)
+	require.Equal(t, 2, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Query"))
+}
+
+func TestModelOf_StreamStopsWhenCanceled(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.Query", []stubbedResponse{
+		{body: pagesFirstPage},
+		{body: pagesSecondPage},
+	})
+	db := newStubbedDB(t, httpClient)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	items, errs := ModelOf[testAccount](db).Where("ID", "=", "a1").Stream(ctx)
+	first := <-items
+	require.Equal(t, int64(1), first.Balance)
+	cancel()
+
+	require.ErrorIs(t, <-errs, context.Canceled)
+	for range items {
+	}
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.Query"))
+}
+
+func TestModelOf_FilterFuncSendsGroupedFilter(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.Query": `{"Items":[{"id":{"S":"a1"},"balance":{"N":"150"}}],"Count":1}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	accounts, err := ModelOf[testAccount](db).
+		Where("ID", "=", "a1").
+		FilterFunc(func(b *cond.Builder) {
+			b.Or(cond.Or(cond.Gte("Balance", 100), cond.And(cond.Exists("Version"), cond.Lt("Balance", 0))))
+		}).
+		All()
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+
+	req := findRequestByTarget(httpClient.Requests(), "DynamoDB_20120810.Query")
+	require.NotNil(t, req)
+	require.Equal(t, "(#n1 >= :v1 OR (attribute_exists(#n2) AND #n1 < :v2))", req.Payload["FilterExpression"])
+	require.Equal(t, "#n3 = :v3", req.Payload["KeyConditionExpression"])
+}
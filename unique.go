@@ -0,0 +1,452 @@
+package dynamorm
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+
+	"github.com/pay-theory/dynamorm/pkg/audit"
+	"github.com/pay-theory/dynamorm/pkg/core"
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/model"
+)
+
+const (
+	// uniqueKeyPrefix starts both key attributes of a uniqueness item, which reserves
+	// one value of a dynamorm:"unique" field: UNIQUE#[entity#]attribute#value.
+	uniqueKeyPrefix = "UNIQUE#"
+	// uniqueOwnerAttribute holds, on a uniqueness item, the key of the item that has
+	// the value.
+	uniqueOwnerAttribute = "uniqueOwner"
+)
+
+// placeholderPattern matches the attribute name placeholders of an expression.
+var placeholderPattern = regexp.MustCompile(`#\w+`)
+
+// hasUniqueFields reports whether writes of the executor's model maintain uniqueness items.
+func (qe *queryExecutor) hasUniqueFields() bool {
+	return qe.metadata != nil && len(qe.metadata.UniqueFields) > 0
+}
+
+// checkPartiQLUnique rejects PartiQL writes to the table of a model with unique fields,
+// which cannot maintain its uniqueness items.
+func (db *DB) checkPartiQLUnique(statement string) error {
+	verb, table := partiQLTarget(statement)
+	if verb == "SELECT" || table == "" {
+		return nil
+	}
+	if meta, err := db.registry.GetMetadataByTable(table); err == nil && len(meta.UniqueFields) > 0 {
+		return fmt.Errorf("%w: PartiQL statements cannot maintain the unique fields of %s; write it with Model",
+			customerrors.ErrInvalidOperator, table)
+	}
+	return nil
+}
+
+// uniqueGuard is one uniqueness item a write adds or removes.
+type uniqueGuard struct {
+	field *model.FieldMetadata
+	put   bool
+}
+
+// writeUnique performs write, the Put, Update, or Delete of the item with key, in a
+// TransactWriteItems call that also moves the item's uniqueness items from the values
+// of its unique fields before the write to the values after, and writes the audit record
+// of an audited Update or Delete. after returns those values given the ones read before.
+// The write is conditioned on the unique fields not having changed since they were read,
+// so a concurrent write makes it fail with ErrConditionFailed rather than leave a
+// uniqueness item behind. It returns the item as read before the write.
+func (qe *queryExecutor) writeUnique(
+	operation string,
+	table string,
+	key map[string]types.AttributeValue,
+	write types.TransactWriteItem,
+	after func(before map[string]types.AttributeValue) (map[string]types.AttributeValue, error),
+) (map[string]types.AttributeValue, error) {
+	client, err := qe.session().Client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client for %s: %w", operation, err)
+	}
+
+	audited := operation != "PutItem" && qe.auditsWrites(table)
+	getInput := &dynamodb.GetItemInput{
+		TableName:      aws.String(table),
+		Key:            key,
+		ConsistentRead: aws.Bool(true),
+	}
+	if !qe.tracksOverflow() && !audited {
+		names := make(map[string]string, len(qe.metadata.UniqueFields))
+		projection := make([]string, len(qe.metadata.UniqueFields))
+		for i, field := range qe.metadata.UniqueFields {
+			placeholder := fmt.Sprintf("#unique%d", i)
+			names[placeholder] = field.DBName
+			projection[i] = placeholder
+		}
+		getInput.ProjectionExpression = aws.String(strings.Join(projection, ", "))
+		getInput.ExpressionAttributeNames = names
+	}
+	current, err := client.GetItem(qe.ctxOrBackground(), getInput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read unique fields for %s: %w", operation, err)
+	}
+	before := current.Item
+
+	values, err := after(before)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions := make([]string, 0, len(qe.metadata.UniqueFields))
+	names := make(map[string]string, len(qe.metadata.UniqueFields))
+	conditionValues := make(map[string]types.AttributeValue)
+	var guards []uniqueGuard
+	var items []types.TransactWriteItem
+	owner := describeItemKey(qe.metadata, key)
+	for i, field := range qe.metadata.UniqueFields {
+		name := fmt.Sprintf("#unique%d", i)
+		names[name] = field.DBName
+		old, hadValue := uniqueValue(before[field.DBName])
+		if hadValue {
+			value := fmt.Sprintf(":unique%d", i)
+			conditions = append(conditions, name+" = "+value)
+			conditionValues[value] = before[field.DBName]
+		} else {
+			conditions = append(conditions, "attribute_not_exists("+name+")")
+		}
+
+		updated, hasValue := uniqueValue(values[field.DBName])
+		if hadValue == hasValue && old == updated {
+			continue
+		}
+		if hasValue {
+			guard := qe.uniqueItemKey(field, updated)
+			guard[uniqueOwnerAttribute] = &types.AttributeValueMemberS{Value: owner}
+			items = append(items, types.TransactWriteItem{Put: &types.Put{
+				TableName:                 aws.String(table),
+				Item:                      guard,
+				ConditionExpression:       aws.String(uniqueOwnerCondition),
+				ExpressionAttributeNames:  qe.uniqueOwnerNames(),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":owner": &types.AttributeValueMemberS{Value: owner}},
+			}})
+			guards = append(guards, uniqueGuard{field: field, put: true})
+		}
+		if hadValue {
+			items = append(items, types.TransactWriteItem{Delete: &types.Delete{
+				TableName:                 aws.String(table),
+				Key:                       qe.uniqueItemKey(field, old),
+				ConditionExpression:       aws.String(uniqueOwnerCondition),
+				ExpressionAttributeNames:  qe.uniqueOwnerNames(),
+				ExpressionAttributeValues: map[string]types.AttributeValue{":owner": &types.AttributeValueMemberS{Value: owner}},
+			}})
+			guards = append(guards, uniqueGuard{field: field})
+		}
+	}
+	if len(conditions) > 0 {
+		addUniqueCondition(&write, strings.Join(conditions, " AND "), names, conditionValues)
+	}
+	var record *audit.Record
+	if audited {
+		if record, err = qe.auditWrite(operation, table, key, before, &write); err != nil {
+			return nil, err
+		}
+	}
+	items = append([]types.TransactWriteItem{write}, items...)
+	if record != nil {
+		put, ok, err := qe.auditPut(record)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			items = append(items, put)
+		}
+	}
+
+	input := &dynamodb.TransactWriteItemsInput{TransactItems: items}
+	if qe.op != nil && qe.op.ReturnConsumedCapacity != "" {
+		input.ReturnConsumedCapacity = qe.op.ReturnConsumedCapacity
+	}
+	out, err := client.TransactWriteItems(qe.ctxOrBackground(), input)
+	qe.db.observeTransactWrite(qe.ctxOrBackground(), items)
+	if qe.op != nil {
+		recordFailure(qe.op, err)
+	}
+	if err != nil {
+		return nil, qe.uniqueWriteError(operation, table, key, guards, err)
+	}
+	if qe.op != nil && out != nil {
+		recordAttempts(qe.op, out.ResultMetadata)
+		capacity := make([]*types.ConsumedCapacity, len(out.ConsumedCapacity))
+		for i := range out.ConsumedCapacity {
+			capacity[i] = &out.ConsumedCapacity[i]
+		}
+		recordOperation(qe.op, int32(len(items)), capacity...)
+	}
+	if record != nil {
+		if err := qe.publishAudit(record); err != nil {
+			return nil, err
+		}
+	}
+	return before, nil
+}
+
+// uniqueOwnerCondition lets a write of a uniqueness item through when the item does
+// not exist or already belongs to the item being written.
+const uniqueOwnerCondition = "attribute_not_exists(#pk) OR #owner = :owner"
+
+func (qe *queryExecutor) uniqueOwnerNames() map[string]string {
+	return map[string]string{
+		"#pk":    qe.metadata.PrimaryKey.PartitionKey.DBName,
+		"#owner": uniqueOwnerAttribute,
+	}
+}
+
+// uniqueItemKey returns the key of the uniqueness item that reserves value of field.
+func (qe *queryExecutor) uniqueItemKey(field *model.FieldMetadata, value string) map[string]types.AttributeValue {
+	id := uniqueKeyPrefix
+	if qe.metadata.EntityType != "" {
+		id += qe.metadata.EntityType + "#"
+	}
+	id += field.DBName + "#" + value
+
+	key := map[string]types.AttributeValue{
+		qe.metadata.PrimaryKey.PartitionKey.DBName: &types.AttributeValueMemberS{Value: id},
+	}
+	if sk := qe.metadata.PrimaryKey.SortKey; sk != nil {
+		key[sk.DBName] = &types.AttributeValueMemberS{Value: id}
+	}
+	return key
+}
+
+// uniqueValue returns the value of a unique attribute as it appears in uniqueness item
+// keys, or false when the attribute is missing or an empty string and so reserves nothing.
+func uniqueValue(av types.AttributeValue) (string, bool) {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value, v.Value != ""
+	case *types.AttributeValueMemberN:
+		return v.Value, true
+	default:
+		return "", false
+	}
+}
+
+// addUniqueCondition ANDs condition onto the condition of write.
+func addUniqueCondition(write *types.TransactWriteItem, condition string, names map[string]string, values map[string]types.AttributeValue) {
+	var expression **string
+	var exprNames *map[string]string
+	var exprValues *map[string]types.AttributeValue
+	switch {
+	case write.Put != nil:
+		expression, exprNames, exprValues = &write.Put.ConditionExpression, &write.Put.ExpressionAttributeNames, &write.Put.ExpressionAttributeValues
+	case write.Update != nil:
+		expression, exprNames, exprValues = &write.Update.ConditionExpression, &write.Update.ExpressionAttributeNames, &write.Update.ExpressionAttributeValues
+	case write.Delete != nil:
+		expression, exprNames, exprValues = &write.Delete.ConditionExpression, &write.Delete.ExpressionAttributeNames, &write.Delete.ExpressionAttributeValues
+	default:
+		return
+	}
+
+	if existing := aws.ToString(*expression); existing != "" {
+		condition = "(" + existing + ") AND " + condition
+	}
+	*expression = aws.String(condition)
+
+	mergedNames := make(map[string]string, len(*exprNames)+len(names))
+	for k, v := range *exprNames {
+		mergedNames[k] = v
+	}
+	for k, v := range names {
+		mergedNames[k] = v
+	}
+	*exprNames = mergedNames
+
+	if len(*exprValues)+len(values) == 0 {
+		return
+	}
+	mergedValues := make(map[string]types.AttributeValue, len(*exprValues)+len(values))
+	for k, v := range *exprValues {
+		mergedValues[k] = v
+	}
+	for k, v := range values {
+		mergedValues[k] = v
+	}
+	*exprValues = mergedValues
+}
+
+// uniqueWriteError maps a failed uniqueness transaction to the error of the write. A
+// failed uniqueness item write becomes ErrUniqueConstraint naming the field but not the
+// value, which may be personal data.
+func (qe *queryExecutor) uniqueWriteError(operation, table string, key map[string]types.AttributeValue, guards []uniqueGuard, err error) error {
+	var canceled *types.TransactionCanceledException
+	if !errors.As(err, &canceled) {
+		return fmt.Errorf("failed to %s item: %w", strings.ToLower(strings.TrimSuffix(operation, "Item")), err)
+	}
+	for i, reason := range canceled.CancellationReasons {
+		if aws.ToString(reason.Code) != "ConditionalCheckFailed" {
+			continue
+		}
+		if i == 0 || i > len(guards) {
+			qe.recordConditionFailure(operation, table, key)
+			return customerrors.ErrConditionFailed
+		}
+		guard := guards[i-1]
+		if guard.put {
+			return fmt.Errorf("%w: another item already has this %s", customerrors.ErrUniqueConstraint, guard.field.Name)
+		}
+		return fmt.Errorf("%w: the %s this item had is reserved by another item", customerrors.ErrUniqueConstraint, guard.field.Name)
+	}
+	return fmt.Errorf("failed to %s item: %w", strings.ToLower(strings.TrimSuffix(operation, "Item")), err)
+}
+
+// uniqueUpdateValues returns the unique attribute values an update expression leaves,
+// starting from before. Unique attributes may only be assigned a value (SET #a = :v) or
+// removed.
+func (qe *queryExecutor) uniqueUpdateValues(input *dynamodb.UpdateItemInput, before map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+	expression := aws.ToString(input.UpdateExpression)
+	names := input.ExpressionAttributeNames
+
+	unique := make(map[string]*model.FieldMetadata, len(qe.metadata.UniqueFields))
+	for _, field := range qe.metadata.UniqueFields {
+		unique[field.DBName] = field
+	}
+
+	values := make(map[string]types.AttributeValue, len(before))
+	for name, av := range before {
+		values[name] = av
+	}
+	handled := make(map[string]int)
+	for _, match := range assignmentPattern.FindAllStringSubmatch(expression, -1) {
+		if _, ok := unique[names[match[1]]]; !ok {
+			continue
+		}
+		values[names[match[1]]] = input.ExpressionAttributeValues[match[2]]
+		handled[names[match[1]]]++
+	}
+	if idx := strings.Index(expression, "REMOVE "); idx >= 0 {
+		section := expression[idx+len("REMOVE "):]
+		for _, keyword := range []string{" SET ", " ADD ", " DELETE "} {
+			if i := strings.Index(section, keyword); i >= 0 {
+				section = section[:i]
+			}
+		}
+		for _, part := range strings.Split(section, ",") {
+			name := names[strings.TrimSpace(part)]
+			if _, ok := unique[name]; ok {
+				delete(values, name)
+				handled[name]++
+			}
+		}
+	}
+
+	uses := make(map[string]int)
+	for _, placeholder := range placeholderPattern.FindAllString(expression, -1) {
+		if _, ok := unique[names[placeholder]]; ok {
+			uses[names[placeholder]]++
+		}
+	}
+	for name, count := range uses {
+		if handled[name] != count {
+			return nil, fmt.Errorf("%w: unique field %s can only be set to a value or removed", customerrors.ErrInvalidOperator, unique[name].Name)
+		}
+	}
+	return values, nil
+}
+
+// putUnique writes item, which has unique fields, and its uniqueness items.
+func (qe *queryExecutor) putUnique(input *core.CompiledQuery, item map[string]types.AttributeValue) error {
+	key := make(map[string]types.AttributeValue, 2)
+	for _, field := range []*model.FieldMetadata{qe.metadata.PrimaryKey.PartitionKey, qe.metadata.PrimaryKey.SortKey} {
+		if field != nil {
+			key[field.DBName] = item[field.DBName]
+		}
+	}
+	put := &types.Put{
+		TableName:                 aws.String(input.TableName),
+		Item:                      item,
+		ExpressionAttributeNames:  input.ExpressionAttributeNames,
+		ExpressionAttributeValues: input.ExpressionAttributeValues,
+	}
+	if input.ConditionExpression != "" {
+		put.ConditionExpression = aws.String(input.ConditionExpression)
+	}
+
+	old, err := qe.writeUnique("PutItem", input.TableName, key, types.TransactWriteItem{Put: put},
+		func(map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+			return item, nil
+		})
+	if err != nil {
+		return err
+	}
+	qe.cleanupOverflow(old, item)
+	return nil
+}
+
+// updateUnique applies updateInput, the update of an item with unique fields or of an
+// audited item, and moves its uniqueness items to the values the update assigns.
+func (qe *queryExecutor) updateUnique(updateInput *dynamodb.UpdateItemInput) error {
+	update := &types.Update{
+		TableName:                 updateInput.TableName,
+		Key:                       updateInput.Key,
+		UpdateExpression:          updateInput.UpdateExpression,
+		ConditionExpression:       updateInput.ConditionExpression,
+		ExpressionAttributeNames:  updateInput.ExpressionAttributeNames,
+		ExpressionAttributeValues: updateInput.ExpressionAttributeValues,
+	}
+	assigned := qe.assignedOverflowValues(updateInput)
+
+	old, err := qe.writeUnique("UpdateItem", aws.ToString(updateInput.TableName), updateInput.Key, types.TransactWriteItem{Update: update},
+		func(before map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+			return qe.uniqueUpdateValues(updateInput, before)
+		})
+	if err != nil {
+		return err
+	}
+	if len(assigned) > 0 {
+		previous := make(map[string]types.AttributeValue, len(assigned))
+		for name := range assigned {
+			if av, ok := old[name]; ok {
+				previous[name] = av
+			}
+		}
+		qe.cleanupOverflow(previous, assigned)
+	}
+	return nil
+}
+
+// deleteUnique deletes the item with key, which has unique fields or is audited, and its
+// uniqueness items.
+func (qe *queryExecutor) deleteUnique(input *core.CompiledQuery, key map[string]types.AttributeValue) error {
+	del := &types.Delete{
+		TableName:                 aws.String(input.TableName),
+		Key:                       key,
+		ExpressionAttributeNames:  input.ExpressionAttributeNames,
+		ExpressionAttributeValues: input.ExpressionAttributeValues,
+	}
+	if input.ConditionExpression != "" {
+		del.ConditionExpression = aws.String(input.ConditionExpression)
+	}
+
+	old, err := qe.writeUnique("DeleteItem", input.TableName, key, types.TransactWriteItem{Delete: del},
+		func(map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+			return nil, nil
+		})
+	if err != nil {
+		return err
+	}
+	qe.cleanupOverflow(old, nil)
+	return nil
+}
+
+// updatesUniqueFields reports whether input's update expression names a unique field.
+func (qe *queryExecutor) updatesUniqueFields(input *dynamodb.UpdateItemInput) bool {
+	for _, placeholder := range placeholderPattern.FindAllString(aws.ToString(input.UpdateExpression), -1) {
+		if field := qe.metadata.FieldsByDBName[input.ExpressionAttributeNames[placeholder]]; field != nil && field.IsUnique() {
+			return true
+		}
+	}
+	return false
+}
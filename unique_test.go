@@ -0,0 +1,124 @@
+package dynamorm
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+	"github.com/pay-theory/dynamorm/pkg/transaction"
+)
+
+type uniqueAccount struct {
+	ID    string `dynamorm:"pk,attr:id"`
+	Email string `dynamorm:"unique,attr:email"`
+	Name  string `dynamorm:"attr:name"`
+}
+
+func (uniqueAccount) TableName() string { return "unique_accounts" }
+
+func TestUniqueCreateReservesValue(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+
+	require.NoError(t, db.Model(&uniqueAccount{ID: "a1", Email: "a@example.com"}).Create())
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem"))
+
+	items := transactItems(t, httpClient)
+	require.Len(t, items, 2)
+
+	put := items[0]["Put"].(map[string]any)
+	require.Contains(t, put["ConditionExpression"], "attribute_not_exists(#unique0)")
+	require.Equal(t, "email", put["ExpressionAttributeNames"].(map[string]any)["#unique0"])
+
+	guard := items[1]["Put"].(map[string]any)
+	require.Equal(t, map[string]any{
+		"id":          map[string]any{"S": "UNIQUE#email#a@example.com"},
+		"uniqueOwner": map[string]any{"S": "id=a1"},
+	}, guard["Item"])
+	require.Equal(t, uniqueOwnerCondition, guard["ConditionExpression"])
+}
+
+func TestUniqueUpdateMovesReservation(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"email":{"S":"old@example.com"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	require.NoError(t, db.Model(&uniqueAccount{ID: "a1", Email: "new@example.com"}).Update("Email"))
+
+	items := transactItems(t, httpClient)
+	require.Len(t, items, 3)
+	update := items[0]["Update"].(map[string]any)
+	require.Contains(t, update["ConditionExpression"], "#unique0 = :unique0")
+	require.Equal(t, map[string]any{"S": "old@example.com"}, update["ExpressionAttributeValues"].(map[string]any)[":unique0"])
+	require.Equal(t, map[string]any{"S": "UNIQUE#email#new@example.com"}, items[1]["Put"].(map[string]any)["Item"].(map[string]any)["id"])
+	require.Equal(t, map[string]any{"id": map[string]any{"S": "UNIQUE#email#old@example.com"}}, items[2]["Delete"].(map[string]any)["Key"])
+
+	// Updates of other fields are plain UpdateItem calls.
+	require.NoError(t, db.Model(&uniqueAccount{ID: "a1", Name: "A"}).Update("Name"))
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.TransactWriteItems"))
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem"))
+}
+
+func TestUniqueDeleteReleasesValue(t *testing.T) {
+	httpClient := newCapturingHTTPClient(map[string]string{
+		"DynamoDB_20120810.GetItem": `{"Item":{"email":{"S":"a@example.com"}}}`,
+	})
+	db := newStubbedDB(t, httpClient)
+
+	require.NoError(t, db.Model(&uniqueAccount{ID: "a1"}).Delete())
+
+	items := transactItems(t, httpClient)
+	require.Len(t, items, 2)
+	require.Equal(t, map[string]any{"id": map[string]any{"S": "a1"}}, items[0]["Delete"].(map[string]any)["Key"])
+	require.Equal(t, map[string]any{"id": map[string]any{"S": "UNIQUE#email#a@example.com"}}, items[1]["Delete"].(map[string]any)["Key"])
+}
+
+func TestUniqueConflictReturnsUniqueConstraint(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	httpClient.SetResponseSequence("DynamoDB_20120810.TransactWriteItems", []stubbedResponse{{
+		status: http.StatusBadRequest,
+		body: `{"__type":"com.amazonaws.dynamodb.v20120810#TransactionCanceledException","message":"Transaction cancelled",` +
+			`"CancellationReasons":[{"Code":"None"},{"Code":"ConditionalCheckFailed"}]}`,
+		headers: map[string]string{"x-amzn-errortype": "TransactionCanceledException"},
+	}})
+	db := newStubbedDB(t, httpClient)
+
+	err := db.Model(&uniqueAccount{ID: "a2", Email: "a@example.com"}).Create()
+	require.ErrorIs(t, err, customerrors.ErrUniqueConstraint)
+	require.NotContains(t, err.Error(), "a@example.com")
+}
+
+func TestUniqueRejectsBatchWrites(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+
+	err := db.Model(&uniqueAccount{}).BatchCreate([]uniqueAccount{{ID: "a1", Email: "a@example.com"}})
+	require.ErrorIs(t, err, customerrors.ErrInvalidOperator)
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.BatchWriteItem"))
+}
+
+func TestUniqueRejectsTransactionFuncAndPartiQLWrites(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+	require.NoError(t, db.registry.Register(&uniqueAccount{}))
+
+	account := &uniqueAccount{ID: "a1", Email: "a@example.com"}
+	for _, op := range []string{"create", "update", "delete"} {
+		err := db.TransactionFunc(func(tx any) error {
+			txn := tx.(*transaction.Transaction)
+			return map[string]func(any) error{"create": txn.Create, "update": txn.Update, "delete": txn.Delete}[op](account)
+		})
+		require.ErrorIs(t, err, customerrors.ErrInvalidOperator, op)
+	}
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.TransactWriteItems"))
+
+	err := db.PartiQL(`UPDATE "unique_accounts" SET email = ? WHERE id = ?`, "b@example.com", "a1").Exec()
+	require.ErrorIs(t, err, customerrors.ErrInvalidOperator)
+	_, err = db.BatchPartiQL(PartiQLStatement{Statement: `DELETE FROM unique_accounts WHERE id = ?`, Params: []any{"a1"}})
+	require.ErrorIs(t, err, customerrors.ErrInvalidOperator)
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.ExecuteStatement"))
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.BatchExecuteStatement"))
+}
@@ -0,0 +1,85 @@
+package dynamorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	customerrors "github.com/pay-theory/dynamorm/pkg/errors"
+)
+
+type validatedInvoice struct {
+	ID       string   `dynamorm:"pk,attr:id"`
+	Customer string   `dynamorm:"required,max:8,attr:customer"`
+	Currency string   `dynamorm:"pattern:^[A-Z]{3}$,attr:currency"`
+	Status   string   `dynamorm:"enum:open|paid|void,attr:status"`
+	Lines    []string `dynamorm:"min:1,attr:lines"`
+	Amount   int64    `dynamorm:"min:0,max:1000,attr:amount"`
+	Due      dueDate  `dynamorm:"attr:due"`
+}
+
+func (validatedInvoice) TableName() string { return "invoices" }
+
+func (i *validatedInvoice) Validate(context.Context) error {
+	if i.Status == "paid" && i.Amount <= 0 {
+		return errors.New("paid invoices need an amount")
+	}
+	return nil
+}
+
+type dueDate string
+
+func (d dueDate) Validate(context.Context) error {
+	if d == "yesterday" {
+		return errors.New("is in the past")
+	}
+	return nil
+}
+
+func TestValidation_CreateReportsEveryFailure(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+
+	invoice := &validatedInvoice{
+		ID:       "inv-1",
+		Customer: "customer-with-a-long-name",
+		Currency: "usd",
+		Status:   "paid",
+		Amount:   -5,
+		Due:      "yesterday",
+	}
+	err := db.Model(invoice).Create()
+	require.ErrorIs(t, err, customerrors.ErrValidationFailed)
+	require.Zero(t, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem"))
+
+	var validationErr *customerrors.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	require.Equal(t, "validatedInvoice", validationErr.Model)
+	rules := make([]string, len(validationErr.Fields))
+	for i, failure := range validationErr.Fields {
+		rules[i] = failure.Field + ":" + failure.Rule
+	}
+	require.Equal(t, []string{"Customer:max", "Currency:pattern", "Lines:min", "Amount:min", "Due:custom", ":custom"}, rules)
+	require.ErrorContains(t, err, "Customer must be at most 8 characters")
+	require.ErrorContains(t, err, "paid invoices need an amount")
+
+	valid := &validatedInvoice{ID: "inv-1", Customer: "acme", Currency: "USD", Status: "open", Lines: []string{"widget"}}
+	require.NoError(t, db.Model(valid).Create())
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.PutItem"))
+}
+
+func TestValidation_UpdateChecksNamedFields(t *testing.T) {
+	httpClient := newCapturingHTTPClient(nil)
+	db := newStubbedDB(t, httpClient)
+
+	invoice := &validatedInvoice{ID: "inv-1", Status: "draft"}
+	err := db.Model(invoice).Update("Status")
+	require.ErrorIs(t, err, customerrors.ErrValidationFailed)
+	require.ErrorContains(t, err, "Status must be one of open, paid, void")
+
+	invoice.Status = "void"
+	require.NoError(t, db.Model(invoice).Update("Status"), "unnamed fields such as Customer are not checked")
+	require.Equal(t, 1, countRequestsByTarget(httpClient.Requests(), "DynamoDB_20120810.UpdateItem"))
+}